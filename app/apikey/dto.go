@@ -8,6 +8,8 @@ import (
 type CreateRequest struct {
 	Name        string    `json:"name" binding:"required,max=100"`
 	Permissions []string  `json:"permissions" binding:"omitempty"`
+	Scopes      []string  `json:"scopes" binding:"omitempty,max=50,dive,required"` // Permission names, e.g. "tts.generate"
+	RateLimit   int       `json:"rate_limit" binding:"omitempty,min=0"`            // Requests per minute; 0 or omitted means unlimited
 	ExpiresAt   time.Time `json:"expires_at" binding:"omitempty"`
 	NeverExpire bool      `json:"never_expire" binding:"omitempty"`
 }
@@ -16,6 +18,8 @@ type CreateRequest struct {
 type UpdateRequest struct {
 	Name        string    `json:"name" binding:"omitempty,max=100"`
 	Permissions []string  `json:"permissions" binding:"omitempty"`
+	Scopes      []string  `json:"scopes" binding:"omitempty,max=50,dive,required"` // Replaces the key's scopes
+	RateLimit   *int      `json:"rate_limit" binding:"omitempty,min=0"`            // Requests per minute; omitted keeps the current quota, 0 removes it
 	ExpiresAt   time.Time `json:"expires_at" binding:"omitempty"`
 	NeverExpire bool      `json:"never_expire" binding:"omitempty"`
 }
@@ -30,9 +34,16 @@ type Response struct {
 	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
 	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
 	Permissions []string   `json:"permissions,omitempty"`
+	Scopes      []string   `json:"scopes"`
+	RateLimit   int        `json:"rate_limit"` // Requests per minute; 0 means unlimited
 	CreatedAt   time.Time  `json:"created_at"`
 }
 
+// RevokeResponse reports how many API keys a batch revoke removed
+type RevokeResponse struct {
+	Revoked int64 `json:"revoked"`
+}
+
 // ListResponse represents the paginated response for listing API keys
 type ListResponse struct {
 	Total   int64      `json:"total"`
@@ -57,6 +68,8 @@ func ToResponse(apiKey *APIKey, includeKey string) Response {
 		ExpiresAt:   apiKey.ExpiresAt,
 		LastUsedAt:  apiKey.LastUsedAt,
 		Permissions: permissions,
+		Scopes:      apiKey.ScopeList(),
+		RateLimit:   apiKey.RateLimit,
 		CreatedAt:   apiKey.CreatedAt,
 	}
 }
@@ -1,11 +1,15 @@
 package apikey
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/pkg/authctx"
+	"github.com/llamacto/llama-gin-kit/pkg/pagination"
+	"github.com/llamacto/llama-gin-kit/pkg/params"
 	"github.com/llamacto/llama-gin-kit/pkg/response"
 )
 
@@ -25,6 +29,12 @@ type Handler interface {
 	
 	// Delete revokes (deletes) an API key
 	Delete(c *gin.Context)
+	
+	// RevokeForUser revokes every API key owned by a user
+	RevokeForUser(c *gin.Context)
+	
+	// RevokeForOrganization revokes every API key owned by an organization's members
+	RevokeForOrganization(c *gin.Context)
 }
 
 // handler implements the Handler interface
@@ -39,7 +49,7 @@ func NewAPIKeyHandler(service Service) Handler {
 
 // Create creates a new API key
 // @Summary Create a new API key
-// @Description Creates a new API key for the authenticated user
+// @Description Creates a new API key for the authenticated user. Scopes must be known permission names and limit what requests made with the key can do. rate_limit caps requests per minute made with the key (0 = unlimited)
 // @Tags API Keys
 // @Accept json
 // @Produce json
@@ -58,7 +68,7 @@ func (h *handler) Create(c *gin.Context) {
 	}
 
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := authctx.UserID(c)
 	if !exists {
 		response.Unauthorized(c, "User not authenticated")
 		return
@@ -77,8 +87,13 @@ func (h *handler) Create(c *gin.Context) {
 	}
 
 	// Generate API key
-	key, apiKey, err := h.service.GenerateAPIKey(userID.(uint), req.Name, expiry, req.Permissions)
+	key, apiKey, err := h.service.GenerateAPIKey(userID, req.Name, expiry, req.Permissions, req.Scopes, req.RateLimit)
 	if err != nil {
+		var scopesErr *InvalidScopesError
+		if errors.As(err, &scopesErr) {
+			response.BadRequest(c, "Invalid API key scopes", err)
+			return
+		}
 		response.InternalServerError(c, "Failed to create API key", err)
 		return
 	}
@@ -106,29 +121,28 @@ func (h *handler) Create(c *gin.Context) {
 // @Security BearerAuth
 func (h *handler) Get(c *gin.Context) {
 	// Parse API key ID from URL
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
+	id, err := params.PathID(c, "id")
 	if err != nil {
 		response.BadRequest(c, "Invalid API key ID", err)
 		return
 	}
 
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := authctx.UserID(c)
 	if !exists {
 		response.Unauthorized(c, "User not authenticated")
 		return
 	}
 
 	// Get API key
-	apiKey, err := h.service.GetAPIKey(uint(id))
+	apiKey, err := h.service.GetAPIKey(id)
 	if err != nil {
 		response.NotFound(c, "API key not found", err)
 		return
 	}
 
 	// Security check: ensure the key belongs to the user
-	if apiKey.UserID != userID.(uint) {
+	if apiKey.UserID != userID {
 		response.Unauthorized(c, "You do not have permission to access this API key")
 		return
 	}
@@ -159,14 +173,14 @@ func (h *handler) List(c *gin.Context) {
 	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
 
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := authctx.UserID(c)
 	if !exists {
 		response.Unauthorized(c, "User not authenticated")
 		return
 	}
 
 	// Get API keys
-	apiKeys, total, err := h.service.ListAPIKeys(userID.(uint), page, perPage)
+	apiKeys, total, err := h.service.ListAPIKeys(userID, page, perPage)
 	if err != nil {
 		response.InternalServerError(c, "Failed to retrieve API keys", err)
 		return
@@ -181,6 +195,7 @@ func (h *handler) List(c *gin.Context) {
 	}
 
 	// Return response
+	pagination.SetHeaders(c, total, page, perPage)
 	c.JSON(http.StatusOK, resp)
 }
 
@@ -201,8 +216,7 @@ func (h *handler) List(c *gin.Context) {
 // @Security BearerAuth
 func (h *handler) Update(c *gin.Context) {
 	// Parse API key ID from URL
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
+	id, err := params.PathID(c, "id")
 	if err != nil {
 		response.BadRequest(c, "Invalid API key ID", err)
 		return
@@ -215,7 +229,7 @@ func (h *handler) Update(c *gin.Context) {
 	}
 
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := authctx.UserID(c)
 	if !exists {
 		response.Unauthorized(c, "User not authenticated")
 		return
@@ -230,8 +244,13 @@ func (h *handler) Update(c *gin.Context) {
 	}
 
 	// Update API key
-	apiKey, err := h.service.UpdateAPIKey(uint(id), userID.(uint), req.Name, expiry, req.Permissions)
+	apiKey, err := h.service.UpdateAPIKey(id, userID, req.Name, expiry, req.Permissions, req.Scopes, req.RateLimit)
 	if err != nil {
+		var scopesErr *InvalidScopesError
+		if errors.As(err, &scopesErr) {
+			response.BadRequest(c, "Invalid API key scopes", err)
+			return
+		}
 		response.HandleError(c, "Failed to update API key", err)
 		return
 	}
@@ -259,22 +278,21 @@ func (h *handler) Update(c *gin.Context) {
 // @Security BearerAuth
 func (h *handler) Delete(c *gin.Context) {
 	// Parse API key ID from URL
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
+	id, err := params.PathID(c, "id")
 	if err != nil {
 		response.BadRequest(c, "Invalid API key ID", err)
 		return
 	}
 
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := authctx.UserID(c)
 	if !exists {
 		response.Unauthorized(c, "User not authenticated")
 		return
 	}
 
 	// Delete API key
-	if err := h.service.RevokeAPIKey(uint(id), userID.(uint)); err != nil {
+	if err := h.service.RevokeAPIKey(id, userID); err != nil {
 		response.HandleError(c, "Failed to delete API key", err)
 		return
 	}
@@ -282,3 +300,59 @@ func (h *handler) Delete(c *gin.Context) {
 	// Return response
 	c.Status(http.StatusNoContent)
 }
+
+// RevokeForUser revokes every API key owned by a user
+// @Summary Revoke all API keys of a user
+// @Description Revokes every API key owned by the user at once, e.g. when offboarding. Requests made with those keys fail authentication afterwards. Admin only
+// @Tags API Keys
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Success 200 {object} RevokeResponse "Number of keys revoked"
+// @Failure 400 {object} response.ErrorResponse "Bad request"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /api/v1/apikeys/revoke/user/{user_id} [post]
+// @Security BearerAuth
+func (h *handler) RevokeForUser(c *gin.Context) {
+	userID, err := params.PathID(c, "user_id")
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID", err)
+		return
+	}
+
+	revoked, err := h.service.RevokeKeysForUser(userID)
+	if err != nil {
+		response.InternalServerError(c, "Failed to revoke API keys", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, RevokeResponse{Revoked: revoked})
+}
+
+// RevokeForOrganization revokes every API key owned by an organization's members
+// @Summary Revoke all API keys of an organization's members
+// @Description Revokes every API key owned by a current member of the organization at once. Keys are owned by users, so this includes keys the members use outside the organization. Admin only
+// @Tags API Keys
+// @Produce json
+// @Param organization_id path int true "Organization ID"
+// @Success 200 {object} RevokeResponse "Number of keys revoked"
+// @Failure 400 {object} response.ErrorResponse "Bad request"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /api/v1/apikeys/revoke/organization/{organization_id} [post]
+// @Security BearerAuth
+func (h *handler) RevokeForOrganization(c *gin.Context) {
+	organizationID, err := params.PathID(c, "organization_id")
+	if err != nil {
+		response.BadRequest(c, "Invalid organization ID", err)
+		return
+	}
+
+	revoked, err := h.service.RevokeKeysForOrganization(organizationID)
+	if err != nil {
+		response.InternalServerError(c, "Failed to revoke API keys", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, RevokeResponse{Revoked: revoked})
+}
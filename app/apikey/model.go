@@ -16,11 +16,18 @@ type APIKey struct {
 	LastUsedAt  *time.Time     `json:"last_used_at"`                                     // Track when the key was last used
 	ExpiresAt   *time.Time     `json:"expires_at"`                                       // Optional expiration date
 	Permissions string         `json:"permissions" gorm:"type:text"`                      // JSON string of permissions
+	Scopes      string         `json:"scopes" gorm:"type:text"`                           // Comma-separated permission names the key may use, e.g. "organizations.read"
+	RateLimit   int            `json:"rate_limit" gorm:"not null;default:0"`              // Max requests per minute; 0 means unlimited
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 }
 
+// ScopeList returns the key's scopes as a slice
+func (k *APIKey) ScopeList() []string {
+	return splitCSV(k.Scopes)
+}
+
 // TableName specifies the table name for the APIKey model
 func (APIKey) TableName() string {
 	return "api_keys"
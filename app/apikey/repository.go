@@ -15,6 +15,8 @@ type Repository interface {
 	FindByUserID(userID uint, page, pageSize int) ([]*APIKey, int64, error)
 	Update(apiKey *APIKey) error
 	Delete(id uint) error
+	DeleteByUserID(userID uint) (int64, error)
+	DeleteByOrganizationID(organizationID uint) (int64, error)
 	UpdateLastUsed(id uint) error
 }
 
@@ -96,6 +98,23 @@ func (r *repository) Delete(id uint) error {
 	return r.db.Delete(&APIKey{}, id).Error
 }
 
+// DeleteByUserID soft deletes every API key owned by the user in a single statement,
+// so either all of them are revoked or none are. It returns the number revoked.
+func (r *repository) DeleteByUserID(userID uint) (int64, error) {
+	result := r.db.Where("user_id = ?", userID).Delete(&APIKey{})
+	return result.RowsAffected, result.Error
+}
+
+// DeleteByOrganizationID soft deletes every API key owned by a current member of the
+// organization in a single statement. It returns the number revoked.
+func (r *repository) DeleteByOrganizationID(organizationID uint) (int64, error) {
+	members := r.db.Table("organization_members").
+		Select("user_id").
+		Where("organization_id = ? AND deleted_at IS NULL", organizationID)
+	result := r.db.Where("user_id IN (?)", members).Delete(&APIKey{})
+	return result.RowsAffected, result.Error
+}
+
 // UpdateLastUsed updates the last used timestamp for an API key
 func (r *repository) UpdateLastUsed(id uint) error {
 	now := time.Now()
@@ -0,0 +1,92 @@
+package apikey
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/llamacto/llama-gin-kit/pkg/dbtest"
+)
+
+func TestBatchRevoke(t *testing.T) {
+	tests := []struct {
+		name     string
+		revoke   func(Repository) (int64, error)
+		affected int64
+		want     []string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "user",
+			revoke:   func(r Repository) (int64, error) { return r.DeleteByUserID(7) },
+			affected: 3,
+			want:     []string{`UPDATE "api_keys" SET "deleted_at"=`, `user_id = $2`, `"api_keys"."deleted_at" IS NULL`},
+			wantArgs: []interface{}{int64(7)},
+		},
+		{
+			name:     "organization",
+			revoke:   func(r Repository) (int64, error) { return r.DeleteByOrganizationID(5) },
+			affected: 4,
+			want: []string{
+				`UPDATE "api_keys" SET "deleted_at"=`,
+				`user_id IN (SELECT user_id FROM "organization_members" WHERE organization_id = $2 AND deleted_at IS NULL)`,
+				`"api_keys"."deleted_at" IS NULL`,
+			},
+			wantArgs: []interface{}{int64(5)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, db := dbtest.Open(t)
+			db.Affects(`UPDATE "api_keys"`, tt.affected)
+
+			n, err := tt.revoke(NewAPIKeyRepository(gormDB))
+			if err != nil {
+				t.Fatalf("revoke error = %v", err)
+			}
+			if n != tt.affected {
+				t.Fatalf("revoked = %d, want %d", n, tt.affected)
+			}
+
+			var updates []dbtest.Statement
+			for _, stmt := range db.Statements() {
+				if strings.HasPrefix(stmt.SQL, "UPDATE") {
+					updates = append(updates, stmt)
+				}
+			}
+			if len(updates) != 1 {
+				t.Fatalf("sent %d UPDATE statements, want one so the revoke is atomic: %v", len(updates), db.Statements())
+			}
+			for _, fragment := range tt.want {
+				if !strings.Contains(updates[0].SQL, fragment) {
+					t.Fatalf("UPDATE = %s, want it to contain %s", updates[0].SQL, fragment)
+				}
+			}
+			for i, want := range tt.wantArgs {
+				if got := updates[0].Args[i+1]; got != want {
+					t.Fatalf("arg %d = %v (%T), want %v", i+1, got, got, want)
+				}
+			}
+			for _, stmt := range db.Statements() {
+				if strings.HasPrefix(stmt.SQL, "DELETE") {
+					t.Fatalf("revoke hard-deleted keys: %s", stmt.SQL)
+				}
+			}
+		})
+	}
+}
+
+func TestFindByPrefixSkipsRevokedKeys(t *testing.T) {
+	gormDB, db := dbtest.Open(t)
+
+	if _, err := NewAPIKeyRepository(gormDB).FindByPrefix("abcd1234"); err == nil {
+		t.Fatal("FindByPrefix() found a key although none was returned")
+	}
+	stmt, ok := db.Find(`FROM "api_keys"`)
+	if !ok {
+		t.Fatalf("no api_keys lookup: %v", db.Statements())
+	}
+	if !strings.Contains(stmt.SQL, `"api_keys"."deleted_at" IS NULL`) {
+		t.Fatalf("lookup does not skip revoked keys: %s", stmt.SQL)
+	}
+}
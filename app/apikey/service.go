@@ -7,13 +7,23 @@ import (
 	"strings"
 	"time"
 
+	"github.com/llamacto/llama-gin-kit/app/authorization"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// InvalidScopesError lists requested scopes that are not known permissions
+type InvalidScopesError struct {
+	Scopes []string
+}
+
+func (e *InvalidScopesError) Error() string {
+	return "unknown scopes: " + strings.Join(e.Scopes, ", ")
+}
+
 // Service interface for API key operations
 type Service interface {
 	// GenerateAPIKey creates a new API key for a user
-	GenerateAPIKey(userID uint, name string, expiry *time.Time, permissions []string) (string, *APIKey, error)
+	GenerateAPIKey(userID uint, name string, expiry *time.Time, permissions, scopes []string, rateLimit int) (string, *APIKey, error)
 	
 	// ValidateAPIKey checks if an API key is valid
 	ValidateAPIKey(apiKey string) (*APIKey, error)
@@ -27,8 +37,14 @@ type Service interface {
 	// RevokeAPIKey revokes (deletes) an API key
 	RevokeAPIKey(id uint, userID uint) error
 	
-	// UpdateAPIKey updates an API key's name, permissions or expiry
-	UpdateAPIKey(id uint, userID uint, name string, expiry *time.Time, permissions []string) (*APIKey, error)
+	// RevokeKeysForUser revokes every API key owned by a user and returns how many were revoked
+	RevokeKeysForUser(userID uint) (int64, error)
+	
+	// RevokeKeysForOrganization revokes every API key owned by the organization's members
+	RevokeKeysForOrganization(organizationID uint) (int64, error)
+	
+	// UpdateAPIKey updates an API key's name, permissions, scopes or expiry, and its rate limit when rateLimit is set
+	UpdateAPIKey(id uint, userID uint, name string, expiry *time.Time, permissions, scopes []string, rateLimit *int) (*APIKey, error)
 }
 
 // service is the implementation of Service interface
@@ -42,7 +58,12 @@ func NewAPIKeyService(repository Repository) Service {
 }
 
 // GenerateAPIKey creates a new API key for a user
-func (s *service) GenerateAPIKey(userID uint, name string, expiry *time.Time, permissions []string) (string, *APIKey, error) {
+func (s *service) GenerateAPIKey(userID uint, name string, expiry *time.Time, permissions, scopes []string, rateLimit int) (string, *APIKey, error) {
+	scopes, err := normalizeScopes(scopes)
+	if err != nil {
+		return "", nil, err
+	}
+	
 	// Generate a random API key (32 bytes, 64 hex chars)
 	b := make([]byte, 32)
 	if _, err := rand.Read(b); err != nil {
@@ -70,6 +91,8 @@ func (s *service) GenerateAPIKey(userID uint, name string, expiry *time.Time, pe
 		UserID:      userID,
 		ExpiresAt:   expiry,
 		Permissions: permissionsStr,
+		Scopes:      strings.Join(scopes, ","),
+		RateLimit:   rateLimit,
 	}
 	
 	// Save to database
@@ -140,8 +163,26 @@ func (s *service) RevokeAPIKey(id uint, userID uint) error {
 	return s.repository.Delete(id)
 }
 
-// UpdateAPIKey updates an API key's name, permissions or expiry
-func (s *service) UpdateAPIKey(id uint, userID uint, name string, expiry *time.Time, permissions []string) (*APIKey, error) {
+// RevokeKeysForUser revokes every API key owned by a user, e.g. when offboarding them.
+// Callers are responsible for checking the actor may do this.
+func (s *service) RevokeKeysForUser(userID uint) (int64, error) {
+	return s.repository.DeleteByUserID(userID)
+}
+
+// RevokeKeysForOrganization revokes every API key owned by a current member of the
+// organization. Keys are not bound to organizations, so this includes keys the
+// members use elsewhere.
+func (s *service) RevokeKeysForOrganization(organizationID uint) (int64, error) {
+	return s.repository.DeleteByOrganizationID(organizationID)
+}
+
+// UpdateAPIKey updates an API key's name, permissions, scopes or expiry, and its rate limit when rateLimit is set
+func (s *service) UpdateAPIKey(id uint, userID uint, name string, expiry *time.Time, permissions, scopes []string, rateLimit *int) (*APIKey, error) {
+	scopes, err := normalizeScopes(scopes)
+	if err != nil {
+		return nil, err
+	}
+	
 	apiKey, err := s.repository.FindByID(id)
 	if err != nil {
 		return nil, err
@@ -156,6 +197,10 @@ func (s *service) UpdateAPIKey(id uint, userID uint, name string, expiry *time.T
 	apiKey.Name = name
 	apiKey.ExpiresAt = expiry
 	apiKey.Permissions = strings.Join(permissions, ",")
+	apiKey.Scopes = strings.Join(scopes, ",")
+	if rateLimit != nil {
+		apiKey.RateLimit = *rateLimit
+	}
 	
 	if err := s.repository.Update(apiKey); err != nil {
 		return nil, err
@@ -163,3 +208,29 @@ func (s *service) UpdateAPIKey(id uint, userID uint, name string, expiry *time.T
 	
 	return apiKey, nil
 }
+
+// normalizeScopes trims and de-duplicates scopes and rejects any that are not known permissions
+func normalizeScopes(scopes []string) ([]string, error) {
+	seen := make(map[string]bool, len(scopes))
+	normalized := make([]string, 0, len(scopes))
+	var unknown []string
+	
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if seen[scope] {
+			continue
+		}
+		seen[scope] = true
+	
+		if !authorization.IsSystemPermission(scope) {
+			unknown = append(unknown, scope)
+			continue
+		}
+		normalized = append(normalized, scope)
+	}
+	
+	if len(unknown) > 0 {
+		return nil, &InvalidScopesError{Scopes: unknown}
+	}
+	return normalized, nil
+}
@@ -0,0 +1,130 @@
+package apikey
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// keyStore is an in-memory Repository that soft-deletes keys like the database does;
+// methods the tests do not reach are left to the embedded nil interface and panic if called
+type keyStore struct {
+	Repository
+	keys    []*APIKey
+	revoked map[uint]bool
+	members map[uint][]uint // organization ID -> member user IDs
+}
+
+func (s *keyStore) Create(k *APIKey) error {
+	k.ID = uint(len(s.keys) + 1)
+	s.keys = append(s.keys, k)
+	return nil
+}
+
+func (s *keyStore) FindByPrefix(prefix string) (*APIKey, error) {
+	for _, k := range s.keys {
+		if k.Prefix == prefix && !s.revoked[k.ID] {
+			return k, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (s *keyStore) UpdateLastUsed(id uint) error { return nil }
+
+func (s *keyStore) DeleteByUserID(userID uint) (int64, error) {
+	return s.revokeWhere(func(k *APIKey) bool { return k.UserID == userID }), nil
+}
+
+func (s *keyStore) DeleteByOrganizationID(organizationID uint) (int64, error) {
+	return s.revokeWhere(func(k *APIKey) bool {
+		for _, id := range s.members[organizationID] {
+			if k.UserID == id {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+func (s *keyStore) revokeWhere(match func(*APIKey) bool) int64 {
+	var n int64
+	for _, k := range s.keys {
+		if match(k) && !s.revoked[k.ID] {
+			s.revoked[k.ID] = true
+			n++
+		}
+	}
+	return n
+}
+
+func TestRevokedKeysFailAuthentication(t *testing.T) {
+	const (
+		alice = 1
+		bob   = 2
+		carol = 3
+		orgID = 9
+	)
+
+	tests := []struct {
+		name        string
+		revoke      func(Service) (int64, error)
+		wantRevoked int64
+		wantValid   map[uint]bool
+	}{
+		{
+			name:        "user",
+			revoke:      func(s Service) (int64, error) { return s.RevokeKeysForUser(alice) },
+			wantRevoked: 2,
+			wantValid:   map[uint]bool{alice: false, bob: true, carol: true},
+		},
+		{
+			name:        "organization",
+			revoke:      func(s Service) (int64, error) { return s.RevokeKeysForOrganization(orgID) },
+			wantRevoked: 3,
+			wantValid:   map[uint]bool{alice: false, bob: false, carol: true},
+		},
+		{
+			name:        "user without keys",
+			revoke:      func(s Service) (int64, error) { return s.RevokeKeysForUser(42) },
+			wantRevoked: 0,
+			wantValid:   map[uint]bool{alice: true, bob: true, carol: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &keyStore{revoked: map[uint]bool{}, members: map[uint][]uint{orgID: {alice, bob}}}
+			svc := NewAPIKeyService(repo)
+
+			secrets := map[string]uint{}
+			for _, owner := range []uint{alice, alice, bob, carol} {
+				secret, _, err := svc.GenerateAPIKey(owner, "key", nil, nil, nil, 0)
+				if err != nil {
+					t.Fatalf("GenerateAPIKey() error = %v", err)
+				}
+				secrets[secret] = owner
+			}
+			for secret := range secrets {
+				if _, err := svc.ValidateAPIKey(secret); err != nil {
+					t.Fatalf("ValidateAPIKey() before revoke error = %v", err)
+				}
+			}
+
+			n, err := tt.revoke(svc)
+			if err != nil {
+				t.Fatalf("revoke error = %v", err)
+			}
+			if n != tt.wantRevoked {
+				t.Fatalf("revoked = %d, want %d", n, tt.wantRevoked)
+			}
+
+			for secret, owner := range secrets {
+				_, err := svc.ValidateAPIKey(secret)
+				if valid := err == nil; valid != tt.wantValid[owner] {
+					t.Fatalf("key of user %d valid = %v, want %v (err = %v)", owner, valid, tt.wantValid[owner], err)
+				}
+			}
+		})
+	}
+}
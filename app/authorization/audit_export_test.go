@@ -0,0 +1,170 @@
+package authorization
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/pkg/dbtest"
+)
+
+// auditLogStore is an in-memory Repository serving audit log pages the way the database
+// query does; other methods are left to the embedded nil interface and panic if called
+type auditLogStore struct {
+	Repository
+	logs    []*AuthAuditLog // In ID order
+	batches int
+}
+
+func (s *auditLogStore) ListOrganizationAuditLogsAfter(ctx context.Context, organizationID uint, from, to time.Time, afterID uint, limit int) ([]*AuthAuditLog, error) {
+	s.batches++
+	var page []*AuthAuditLog
+	for _, log := range s.logs {
+		if log.OrganizationID == nil || *log.OrganizationID != organizationID || log.ID <= afterID ||
+			log.CreatedAt.Before(from) || log.CreatedAt.After(to) {
+			continue
+		}
+		page = append(page, log)
+		if len(page) == limit {
+			break
+		}
+	}
+	return page, nil
+}
+
+func TestExportOrganizationAuditLogs(t *testing.T) {
+	const (
+		orgID   = 5
+		otherID = 6
+	)
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	// One entry per hour for the target organization, interleaved with another
+	// organization's and global entries at the same times
+	store := &auditLogStore{}
+	for i := 0; i < 1500; i++ {
+		at := start.Add(time.Duration(i) * time.Hour)
+		for _, org := range []*uint{uintPtr(orgID), uintPtr(otherID), nil} {
+			store.logs = append(store.logs, &AuthAuditLog{
+				ID:             uint(len(store.logs) + 1),
+				CreatedAt:      at,
+				ActorID:        1,
+				Action:         "role.assign",
+				TargetType:     "user",
+				TargetID:       uint(i),
+				Diff:           `{"i":` + strconv.Itoa(i) + `}`,
+				OrganizationID: org,
+			})
+		}
+	}
+	from := start.Add(100 * time.Hour)
+	to := start.Add(1300 * time.Hour) // Inclusive, so 1201 entries spanning three batches
+
+	tests := []struct {
+		format string
+		parse  func(t *testing.T, body []byte) []AuditLogResponse
+	}{
+		{format: AuditExportFormatCSV, parse: parseAuditCSV},
+		{format: AuditExportFormatJSON, parse: func(t *testing.T, body []byte) []AuditLogResponse {
+			var logs []AuditLogResponse
+			if err := json.Unmarshal(body, &logs); err != nil {
+				t.Fatalf("decode JSON export: %v", err)
+			}
+			return logs
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			store.batches = 0
+			var buf bytes.Buffer
+			err := newTestService(store).ExportOrganizationAuditLogs(context.Background(), orgID, &AuditExportQuery{From: from, To: to, Format: tt.format}, &buf)
+			if err != nil {
+				t.Fatalf("ExportOrganizationAuditLogs() error = %v", err)
+			}
+
+			logs := tt.parse(t, buf.Bytes())
+			if len(logs) != 1201 {
+				t.Fatalf("exported %d entries, want 1201", len(logs))
+			}
+			if !logs[0].CreatedAt.Equal(from) || !logs[len(logs)-1].CreatedAt.Equal(to) {
+				t.Fatalf("export covers %s to %s, want %s to %s", logs[0].CreatedAt, logs[len(logs)-1].CreatedAt, from, to)
+			}
+			for i, log := range logs {
+				stored := store.logs[log.ID-1]
+				if stored.OrganizationID == nil || *stored.OrganizationID != orgID {
+					t.Fatalf("entry %d belongs to organization %v, want %d", log.ID, stored.OrganizationID, orgID)
+				}
+				if i > 0 && log.ID <= logs[i-1].ID {
+					t.Fatalf("entries out of order: %d after %d", log.ID, logs[i-1].ID)
+				}
+			}
+			if store.batches != 3 {
+				t.Fatalf("loaded %d batches, want 3 of at most %d entries", store.batches, auditExportBatchSize)
+			}
+		})
+	}
+
+	t.Run("unsupported format", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := newTestService(store).ExportOrganizationAuditLogs(context.Background(), orgID, &AuditExportQuery{From: from, To: to, Format: "xml"}, &buf); err == nil {
+			t.Fatal("ExportOrganizationAuditLogs() accepted an unsupported format")
+		}
+	})
+}
+
+func TestListOrganizationAuditLogsAfterQuery(t *testing.T) {
+	gormDB, db := dbtest.Open(t)
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	if _, err := NewRepository(gormDB).ListOrganizationAuditLogsAfter(context.Background(), 5, from, to, 42, 500); err != nil {
+		t.Fatalf("ListOrganizationAuditLogsAfter() error = %v", err)
+	}
+	stmt, ok := db.Find(`FROM "auth_audit_logs"`)
+	if !ok {
+		t.Fatalf("no audit log query: %v", db.Statements())
+	}
+	for _, fragment := range []string{
+		"organization_id = $1 AND created_at >= $2 AND created_at <= $3 AND id > $4",
+		"ORDER BY id ASC",
+		"LIMIT $5",
+	} {
+		if !strings.Contains(stmt.SQL, fragment) {
+			t.Fatalf("query = %s, want it to contain %s", stmt.SQL, fragment)
+		}
+	}
+	if stmt.Args[0] != int64(5) || stmt.Args[3] != int64(42) || stmt.Args[4] != int64(500) {
+		t.Fatalf("args = %v, want organization 5, after ID 42 and limit 500", stmt.Args)
+	}
+}
+
+// parseAuditCSV reads a CSV export back into audit log responses
+func parseAuditCSV(t *testing.T, body []byte) []AuditLogResponse {
+	t.Helper()
+	records, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+	if err != nil {
+		t.Fatalf("read CSV export: %v", err)
+	}
+	if len(records) == 0 || strings.Join(records[0], ",") != "id,created_at,actor_id,action,target_type,target_id,diff" {
+		t.Fatalf("CSV header = %v", records)
+	}
+
+	logs := make([]AuditLogResponse, 0, len(records)-1)
+	for _, record := range records[1:] {
+		id, _ := strconv.ParseUint(record[0], 10, 64)
+		createdAt, err := time.Parse(time.RFC3339, record[1])
+		if err != nil {
+			t.Fatalf("parse created_at %q: %v", record[1], err)
+		}
+		logs = append(logs, AuditLogResponse{ID: uint(id), CreatedAt: createdAt, Action: record[3], TargetType: record[4], Diff: record[6]})
+	}
+	return logs
+}
+
+func uintPtr(v uint) *uint { return &v }
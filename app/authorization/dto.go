@@ -0,0 +1,410 @@
+package authorization
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/pkg/batch"
+	"github.com/llamacto/llama-gin-kit/pkg/pagination"
+)
+
+// ListQuery represents common query parameters for authorization list endpoints
+type ListQuery struct {
+	Page          int    `form:"page,default=1"`
+	PageSize      int    `form:"page_size,default=20"`
+	Search        string `form:"search"`
+	Status        *int   `form:"status"`
+	IncludeSystem bool   `form:"include_system"`              // System entries are excluded unless requested
+	OrderBy       string `form:"order_by,default=created_at"` // id, name, display_name, status, created_at or updated_at
+	Order         string `form:"order,default=desc"`
+}
+
+// listSort lists the columns roles and permissions may be sorted by
+var listSort = pagination.Sort{
+	Columns: []string{"id", "name", "display_name", "status", "created_at", "updated_at"},
+	Default: "created_at",
+}
+
+// Normalize replaces a disallowed order_by or order with the default sort
+func (q *ListQuery) Normalize() {
+	q.OrderBy, q.Order = listSort.Normalize(q.OrderBy, q.Order)
+}
+
+// RoleResponse represents the role data in responses
+type RoleResponse struct {
+	ID          uint                 `json:"id"`
+	Name        string               `json:"name"`
+	DisplayName string               `json:"display_name"`
+	Description string               `json:"description"`
+	Level       int                  `json:"level"`
+	IsSystem    bool                 `json:"is_system"`
+	Status      int                  `json:"status"`
+	Permissions []PermissionResponse `json:"permissions,omitempty"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+}
+
+// PermissionResponse represents the permission data in responses
+type PermissionResponse struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	DisplayName string    `json:"display_name"`
+	Description string    `json:"description"`
+	Resource    string    `json:"resource"`
+	Action      string    `json:"action"`
+	Category    string    `json:"category"`
+	IsSystem    bool      `json:"is_system"`
+	Status      int       `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// RoleListResponse represents a paginated list of roles
+type RoleListResponse struct {
+	Roles      []RoleResponse `json:"roles"`
+	Total      int64          `json:"total"`
+	Page       int            `json:"page"`
+	PageSize   int            `json:"page_size"`
+	TotalPages int            `json:"total_pages"`
+}
+
+// PermissionListResponse represents a paginated list of permissions
+type PermissionListResponse struct {
+	Permissions []PermissionResponse `json:"permissions"`
+	Total       int64                `json:"total"`
+	Page        int                  `json:"page"`
+	PageSize    int                  `json:"page_size"`
+	TotalPages  int                  `json:"total_pages"`
+}
+
+// ToRoleResponse converts a Role model to a RoleResponse
+func ToRoleResponse(role *Role) RoleResponse {
+	resp := RoleResponse{
+		ID:          role.ID,
+		Name:        role.Name,
+		DisplayName: role.DisplayName,
+		Description: role.Description,
+		Level:       role.Level,
+		IsSystem:    role.IsSystem,
+		Status:      role.Status,
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+	for _, permission := range role.Permissions {
+		resp.Permissions = append(resp.Permissions, ToPermissionResponse(permission))
+	}
+	return resp
+}
+
+// ToPermissionResponse converts a Permission model to a PermissionResponse
+func ToPermissionResponse(permission *Permission) PermissionResponse {
+	return PermissionResponse{
+		ID:          permission.ID,
+		Name:        permission.Name,
+		DisplayName: permission.DisplayName,
+		Description: permission.Description,
+		Resource:    permission.Resource,
+		Action:      permission.Action,
+		Category:    permission.Category,
+		IsSystem:    permission.IsSystem,
+		Status:      permission.Status,
+		CreatedAt:   permission.CreatedAt,
+		UpdatedAt:   permission.UpdatedAt,
+	}
+}
+
+// PermissionsSummaryQuery represents query parameters for a user's permissions summary
+type PermissionsSummaryQuery struct {
+	OrganizationID *uint `form:"organization_id"` // Scope org and team roles to one organization
+}
+
+// UserRoleResponse represents a global role assignment in responses
+type UserRoleResponse struct {
+	RoleID      uint       `json:"role_id"`
+	RoleName    string     `json:"role_name"`
+	DisplayName string     `json:"display_name"`
+	AssignedBy  uint       `json:"assigned_by"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// OrganizationRoleResponse represents an organization role assignment in responses
+type OrganizationRoleResponse struct {
+	OrganizationID uint      `json:"organization_id"`
+	RoleID         uint      `json:"role_id"`
+	RoleName       string    `json:"role_name"`
+	DisplayName    string    `json:"display_name"`
+	AssignedBy     uint      `json:"assigned_by"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TeamRoleResponse represents a team role assignment in responses
+type TeamRoleResponse struct {
+	TeamID      uint      `json:"team_id"`
+	RoleID      uint      `json:"role_id"`
+	RoleName    string    `json:"role_name"`
+	DisplayName string    `json:"display_name"`
+	AssignedBy  uint      `json:"assigned_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// UserPermissionsSummary lists a user's active role assignments at every scope
+// and the union of the permissions they grant
+type UserPermissionsSummary struct {
+	UserID               uint                       `json:"user_id"`
+	GlobalRoles          []UserRoleResponse         `json:"global_roles"`
+	OrganizationRoles    []OrganizationRoleResponse `json:"organization_roles"`
+	TeamRoles            []TeamRoleResponse         `json:"team_roles"`
+	EffectivePermissions []string                   `json:"effective_permissions"`
+}
+
+// SwitchOrganizationRequest represents the request to change the token's active organization
+type SwitchOrganizationRequest struct {
+	OrganizationID uint `json:"organization_id" binding:"required"`
+}
+
+// SwitchOrganizationResponse carries a token scoped to the new active organization
+type SwitchOrganizationResponse struct {
+	Token                string `json:"token"`
+	ActiveOrganizationID uint   `json:"active_organization_id"`
+}
+
+// InitializeSystemResult reports which built-in roles and permissions were created and which already existed
+type InitializeSystemResult struct {
+	RolesCreated        []string `json:"roles_created"`
+	RolesExisting       []string `json:"roles_existing"`
+	PermissionsCreated  []string `json:"permissions_created"`
+	PermissionsExisting []string `json:"permissions_existing"`
+	PermissionsGranted  int      `json:"permissions_granted"` // Permissions newly linked to super_admin
+}
+
+// AssignRoleRequest represents the request to assign a role to a user
+type AssignRoleRequest struct {
+	RoleID    uint       `json:"role_id" binding:"required"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// AssignRolesRequest represents the request to assign several roles to a user
+type AssignRolesRequest struct {
+	RoleIDs   []uint     `json:"role_ids" binding:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Atomic    bool       `json:"atomic"` // Assign all roles or none
+}
+
+// BulkAssignOrganizationRoleRequest represents the request to grant one organization role to many users
+type BulkAssignOrganizationRoleRequest struct {
+	OrganizationID uint   `json:"-"` // Taken from the path
+	UserIDs        []uint `json:"user_ids" binding:"required,min=1,max=500"`
+	RoleID         uint   `json:"role_id" binding:"required"`
+}
+
+// Role assignment codes reported in batch item results
+const (
+	AssignFailureNotFound        = batch.CodeNotFound
+	AssignFailureAlreadyAssigned = "already_assigned"
+	AssignFailureNotMember       = "not_member"
+	AssignFailureAborted         = batch.CodeAborted // Valid role not assigned because an atomic request failed
+	AssignFailureInternal        = batch.CodeInternal
+)
+
+// PermissionCategory is one category of the permission catalog
+type PermissionCategory struct {
+	Name        string               `json:"name"`
+	Permissions []PermissionResponse `json:"permissions"`
+}
+
+// PermissionCatalog lists every active permission grouped by category, for building
+// permission configuration screens
+type PermissionCatalog struct {
+	Categories []PermissionCategory `json:"categories"`
+	Total      int                  `json:"total"`
+}
+
+// ResolvePermissionsRequest lists permission names to map to IDs and IDs to map to names.
+// At least one of the lists must be non-empty.
+type ResolvePermissionsRequest struct {
+	Names []string `json:"names" binding:"omitempty,max=500,dive,required"`
+	IDs   []uint   `json:"ids" binding:"omitempty,max=500,dive,required"`
+}
+
+// ResolvePermissionsResponse maps names to IDs and IDs to names, and lists the entries that
+// matched no permission
+type ResolvePermissionsResponse struct {
+	IDs             map[string]uint `json:"ids"`   // Permission ID by requested name
+	Names           map[uint]string `json:"names"` // Permission name by requested ID
+	UnresolvedNames []string        `json:"unresolved_names"`
+	UnresolvedIDs   []uint          `json:"unresolved_ids"`
+}
+
+// AssignPermissionsRequest represents the request to assign or remove role permissions
+type AssignPermissionsRequest struct {
+	PermissionIDs []uint `json:"permission_ids" binding:"required,min=1"`
+}
+
+// Role permission change operations previewed by PreviewRolePermissionChange
+const (
+	PermissionChangeAssign = "assign"
+	PermissionChangeRemove = "remove"
+)
+
+// RolePermissionChangeRequest describes a role permission edit to preview
+type RolePermissionChangeRequest struct {
+	Operation     string `json:"operation" binding:"required,oneof=assign remove"`
+	PermissionIDs []uint `json:"permission_ids" binding:"required,min=1"`
+}
+
+// AffectedUser is a role holder whose effective permissions the change would alter
+type AffectedUser struct {
+	UserID      uint     `json:"user_id"`
+	Permissions []string `json:"permissions"` // Permissions the user would gain or lose
+	Cached      bool     `json:"cached"`      // Whether the user currently has cached permissions
+}
+
+// RolePermissionChangePreview lists the users a role permission edit would affect.
+// Users who hold a changed permission through another role are not affected by it.
+type RolePermissionChangePreview struct {
+	RoleID        uint           `json:"role_id"`
+	Operation     string         `json:"operation"`
+	Permissions   []string       `json:"permissions"` // Permissions the role would actually gain or lose
+	AffectedUsers []AffectedUser `json:"affected_users"`
+}
+
+// AuditLogQuery represents query parameters for listing audit logs.
+// When Cursor is set, keyset pagination is used and Page is ignored.
+type AuditLogQuery struct {
+	Page       int        `form:"page,default=1"`
+	PageSize   int        `form:"page_size,default=20"`
+	Cursor     uint       `form:"cursor"` // Return entries with an ID below this value
+	ActorID    *uint      `form:"actor_id"`
+	TargetType string     `form:"target_type"` // Resource type, e.g. "user" or "role"
+	TargetID   *uint      `form:"target_id"`
+	Action     string     `form:"action"`
+	From       *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// AuditLogResponse represents an audit log entry in responses
+type AuditLogResponse struct {
+	ID         uint      `json:"id"`
+	ActorID    uint      `json:"actor_id"`
+	Action     string    `json:"action"`
+	TargetType string    `json:"target_type"`
+	TargetID   uint      `json:"target_id"`
+	Diff       string    `json:"diff"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	OrganizationID *uint `json:"organization_id,omitempty"`
+}
+
+// AuditLogListResponse represents a paginated list of audit logs
+type AuditLogListResponse struct {
+	Logs       []AuditLogResponse `json:"logs"`
+	Total      int64              `json:"total"`
+	Page       int                `json:"page"`
+	PageSize   int                `json:"page_size"`
+	TotalPages int                `json:"total_pages"`
+	NextCursor *uint              `json:"next_cursor,omitempty"` // Pass as cursor to fetch the next page
+}
+
+// ToAuditLogResponse converts an AuthAuditLog model to an AuditLogResponse
+func ToAuditLogResponse(log *AuthAuditLog) AuditLogResponse {
+	return AuditLogResponse{
+		ID:         log.ID,
+		ActorID:    log.ActorID,
+		Action:     log.Action,
+		TargetType: log.TargetType,
+		TargetID:   log.TargetID,
+		Diff:       log.Diff,
+		CreatedAt:  log.CreatedAt,
+
+		OrganizationID: log.OrganizationID,
+	}
+}
+
+// Supported audit export formats
+const (
+	AuditExportFormatCSV  = "csv"
+	AuditExportFormatJSON = "json"
+)
+
+// AuditExportQuery selects the audit events of an organization to export. Both ends of the
+// range are inclusive.
+type AuditExportQuery struct {
+	From   time.Time `form:"from" binding:"required" time_format:"2006-01-02T15:04:05Z07:00"`
+	To     time.Time `form:"to" binding:"required" time_format:"2006-01-02T15:04:05Z07:00"`
+	Format string    `form:"format"` // csv (default) or json
+}
+
+// PolicyQuery represents filter and pagination parameters for listing policies
+type PolicyQuery struct {
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"page_size,default=20"`
+	Resource string `form:"resource"` // Exact policy object, e.g. "dataset:*"
+	Effect   string `form:"effect" binding:"omitempty,oneof=allow deny"`
+	Subject  string `form:"subject"`
+	Status   *int   `form:"status"`
+}
+
+// CreatePolicyRequest represents the request to create a policy
+type CreatePolicyRequest struct {
+	Subject    string          `json:"subject" binding:"required,max=100"`  // e.g. "role:1", "user:2", "*"
+	Action     string          `json:"action" binding:"required,max=100"`   // e.g. "read", "*"
+	Resource   string          `json:"resource" binding:"required,max=100"` // e.g. "article:1", "dataset:*"
+	Effect     string          `json:"effect" binding:"required,oneof=allow deny"`
+	Conditions json.RawMessage `json:"conditions,omitempty" swaggertype:"object"`
+	Priority   int             `json:"priority"`
+	Status     *int            `json:"status,omitempty" binding:"omitempty,oneof=0 1"` // Defaults to 1 (active)
+}
+
+// UpdatePolicyRequest represents the request to update a policy. Omitted fields are unchanged.
+type UpdatePolicyRequest struct {
+	Subject    *string         `json:"subject,omitempty" binding:"omitempty,min=1,max=100"`
+	Action     *string         `json:"action,omitempty" binding:"omitempty,min=1,max=100"`
+	Resource   *string         `json:"resource,omitempty" binding:"omitempty,min=1,max=100"`
+	Effect     *string         `json:"effect,omitempty" binding:"omitempty,oneof=allow deny"`
+	Conditions json.RawMessage `json:"conditions,omitempty" swaggertype:"object"` // null clears the conditions
+	Priority   *int            `json:"priority,omitempty"`
+	Status     *int            `json:"status,omitempty" binding:"omitempty,oneof=0 1"`
+}
+
+// PolicyResponse represents the policy data in responses
+type PolicyResponse struct {
+	ID         uint            `json:"id"`
+	Subject    string          `json:"subject"`
+	Action     string          `json:"action"`
+	Resource   string          `json:"resource"`
+	Effect     string          `json:"effect"`
+	Conditions json.RawMessage `json:"conditions,omitempty" swaggertype:"object"`
+	Priority   int             `json:"priority"`
+	Status     int             `json:"status"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+// PolicyListResponse represents a paginated list of policies
+type PolicyListResponse struct {
+	Policies   []PolicyResponse `json:"policies"`
+	Total      int64            `json:"total"`
+	Page       int              `json:"page"`
+	PageSize   int              `json:"page_size"`
+	TotalPages int              `json:"total_pages"`
+}
+
+// ToPolicyResponse converts a Policy model to a PolicyResponse
+func ToPolicyResponse(policy *Policy) PolicyResponse {
+	resp := PolicyResponse{
+		ID:        policy.ID,
+		Subject:   policy.Subject,
+		Action:    policy.Action,
+		Resource:  policy.Object,
+		Effect:    policy.Effect,
+		Priority:  policy.Priority,
+		Status:    policy.Status,
+		CreatedAt: policy.CreatedAt,
+		UpdatedAt: policy.UpdatedAt,
+	}
+	if policy.Conditions != nil {
+		resp.Conditions = json.RawMessage(*policy.Conditions)
+	}
+	return resp
+}
@@ -0,0 +1,1122 @@
+package authorization
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/pkg/authctx"
+	"github.com/llamacto/llama-gin-kit/pkg/i18n"
+	"github.com/llamacto/llama-gin-kit/pkg/jwt"
+	"github.com/llamacto/llama-gin-kit/pkg/logger"
+	"github.com/llamacto/llama-gin-kit/pkg/pagination"
+	"github.com/llamacto/llama-gin-kit/pkg/params"
+	"github.com/llamacto/llama-gin-kit/pkg/response"
+)
+
+// Handler defines the interface for authorization HTTP handlers
+type Handler interface {
+	ListRoles(c *gin.Context)
+	ListSystemRoles(c *gin.Context)
+	ListPermissions(c *gin.Context)
+	ListSystemPermissions(c *gin.Context)
+	GetPermissionCatalog(c *gin.Context)
+	ResolvePermissions(c *gin.Context)
+	SwitchOrganization(c *gin.Context)
+	GetUserPermissionsSummary(c *gin.Context)
+	AssignRoleToUser(c *gin.Context)
+	AssignRolesToUser(c *gin.Context)
+	RemoveRoleFromUser(c *gin.Context)
+	AssignPermissionsToRole(c *gin.Context)
+	PreviewRolePermissionChange(c *gin.Context)
+	RemovePermissionsFromRole(c *gin.Context)
+	DeactivateOrganizationRole(c *gin.Context)
+	BulkAssignOrganizationRole(c *gin.Context)
+	ReactivateOrganizationRole(c *gin.Context)
+	DeactivateTeamRole(c *gin.Context)
+	ReactivateTeamRole(c *gin.Context)
+	ListAuditLogs(c *gin.Context)
+	ExportOrganizationAuditLogs(c *gin.Context)
+	InitializeSystem(c *gin.Context)
+	DeleteRole(c *gin.Context)
+	ListDeletedRoles(c *gin.Context)
+	RestoreRole(c *gin.Context)
+	ListPolicies(c *gin.Context)
+	CreatePolicy(c *gin.Context)
+	GetPolicy(c *gin.Context)
+	UpdatePolicy(c *gin.Context)
+	DeletePolicy(c *gin.Context)
+}
+
+// handler implements the Handler interface
+type handler struct {
+	service Service
+}
+
+// NewHandler creates a new authorization handler instance
+func NewHandler(service Service) Handler {
+	return &handler{service: service}
+}
+
+// ListRoles lists roles, excluding system roles unless include_system=true
+// @Summary List roles
+// @Description List roles with pagination. System roles are excluded unless include_system=true
+// @Tags authorization
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 20, max: 100)"
+// @Param search query string false "Search by name or display name"
+// @Param status query int false "Filter by status"
+// @Param include_system query bool false "Include system roles"
+// @Success 200 {object} response.Response{data=RoleListResponse}
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /v1/auth/roles [get]
+func (h *handler) ListRoles(c *gin.Context) {
+	var query ListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidQueryParams)
+		return
+	}
+	query.Normalize()
+
+	roles, err := h.service.ListRoles(c.Request.Context(), &query)
+	if err != nil {
+		if response.Canceled(c, err) {
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to retrieve roles")
+		return
+	}
+
+	pagination.SetHeaders(c, roles.Total, roles.Page, roles.PageSize)
+	response.Success(c, roles)
+}
+
+// ListSystemRoles lists the built-in system roles
+// @Summary List system roles
+// @Description List built-in system roles (read-only)
+// @Tags authorization
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=[]RoleResponse}
+// @Failure 500 {object} response.Response
+// @Router /v1/auth/roles/system [get]
+func (h *handler) ListSystemRoles(c *gin.Context) {
+	roles, err := h.service.ListSystemRoles(c.Request.Context())
+	if err != nil {
+		if response.Canceled(c, err) {
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to retrieve system roles")
+		return
+	}
+
+	response.Success(c, roles)
+}
+
+// ListPermissions lists permissions, excluding system permissions unless include_system=true
+// @Summary List permissions
+// @Description List permissions with pagination. System permissions are excluded unless include_system=true
+// @Tags authorization
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 20, max: 100)"
+// @Param search query string false "Search by name or display name"
+// @Param status query int false "Filter by status"
+// @Param include_system query bool false "Include system permissions"
+// @Success 200 {object} response.Response{data=PermissionListResponse}
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /v1/auth/permissions [get]
+func (h *handler) ListPermissions(c *gin.Context) {
+	var query ListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidQueryParams)
+		return
+	}
+	query.Normalize()
+
+	permissions, err := h.service.ListPermissions(c.Request.Context(), &query)
+	if err != nil {
+		if response.Canceled(c, err) {
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to retrieve permissions")
+		return
+	}
+
+	pagination.SetHeaders(c, permissions.Total, permissions.Page, permissions.PageSize)
+	response.Success(c, permissions)
+}
+
+// ListSystemPermissions lists the built-in system permissions
+// @Summary List system permissions
+// @Description List built-in system permissions (read-only)
+// @Tags authorization
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=[]PermissionResponse}
+// @Failure 500 {object} response.Response
+// @Router /v1/auth/permissions/system [get]
+func (h *handler) ListSystemPermissions(c *gin.Context) {
+	permissions, err := h.service.ListSystemPermissions(c.Request.Context())
+	if err != nil {
+		if response.Canceled(c, err) {
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to retrieve system permissions")
+		return
+	}
+
+	response.Success(c, permissions)
+}
+
+// GetPermissionCatalog returns every active permission grouped by category
+// @Summary Get permission catalog
+// @Description List every active permission with its display name, description, resource, action and category, grouped by category, for building permission configuration screens. Cached for up to a minute
+// @Tags authorization
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=PermissionCatalog}
+// @Failure 500 {object} response.Response
+// @Router /v1/auth/permissions/catalog [get]
+func (h *handler) GetPermissionCatalog(c *gin.Context) {
+	catalog, err := h.service.GetPermissionCatalog(c.Request.Context())
+	if err != nil {
+		if response.Canceled(c, err) {
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to retrieve permission catalog")
+		return
+	}
+
+	response.Success(c, catalog)
+}
+
+// ResolvePermissions maps permission names to IDs and IDs to names
+// @Summary Resolve permissions
+// @Description Map permission names to IDs and permission IDs to names in bulk, for UIs that toggle permissions by name. Entries that match no permission are listed in unresolved_names and unresolved_ids
+// @Tags authorization
+// @Accept json
+// @Produce json
+// @Param request body ResolvePermissionsRequest true "Permission names and IDs (up to 500 each)"
+// @Success 200 {object} response.Response{data=ResolvePermissionsResponse}
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /v1/auth/permissions/resolve [post]
+func (h *handler) ResolvePermissions(c *gin.Context) {
+	var req ResolvePermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil || (len(req.Names) == 0 && len(req.IDs) == 0) {
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidRequestPayload)
+		return
+	}
+
+	ids, err := h.service.ResolvePermissions(c.Request.Context(), req.Names)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to resolve permissions")
+		return
+	}
+	names, err := h.service.ResolvePermissionNames(c.Request.Context(), req.IDs)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to resolve permissions")
+		return
+	}
+
+	response.Success(c, ResolvePermissionsResponse{
+		IDs:             ids,
+		Names:           names,
+		UnresolvedNames: unresolvedNames(req.Names, ids),
+		UnresolvedIDs:   unresolvedIDs(req.IDs, names),
+	})
+}
+
+// SwitchOrganization issues a new token scoped to another organization
+// @Summary Switch active organization
+// @Description Issue a token whose active organization is the requested one. The caller must be an active member
+// @Tags authorization
+// @Accept json
+// @Produce json
+// @Param request body SwitchOrganizationRequest true "Organization to switch to"
+// @Success 200 {object} response.Response{data=SwitchOrganizationResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /v1/auth/switch-org [post]
+func (h *handler) SwitchOrganization(c *gin.Context) {
+	var req SwitchOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidRequestPayload)
+		return
+	}
+
+	userID, ok := authctx.RequireUserID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.SwitchOrganization(c.Request.Context(), userID, authctx.Username(c), req.OrganizationID)
+	if err != nil {
+		if errors.Is(err, jwt.ErrNotInitialized) {
+			logger.Error("Token requested before jwt.Init", err)
+		}
+		respondAuthorizationError(c, err, "Failed to switch organization")
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// InitializeSystem creates the built-in roles and permissions and grants every permission to super_admin
+// @Summary Initialize system roles and permissions
+// @Description Bootstrap the built-in roles and permissions. Open to any authenticated user until system roles exist; afterwards only super_admin may re-run it. Repeated calls only create what is missing
+// @Tags authorization
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=InitializeSystemResult}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /v1/auth/initialize [post]
+func (h *handler) InitializeSystem(c *gin.Context) {
+	userID, ok := authctx.RequireUserID(c)
+	if !ok {
+		return
+	}
+
+	initialized, err := h.service.HasSystemRoles(c.Request.Context())
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to check system roles")
+		return
+	}
+	if initialized {
+		isSuperAdmin, err := h.service.HasRole(c.Request.Context(), userID, superAdminRole)
+		if err != nil {
+			response.Error(c, http.StatusInternalServerError, "Failed to check permissions")
+			return
+		}
+		if !isSuperAdmin {
+			response.Error(c, http.StatusForbidden, "System roles are already initialized")
+			return
+		}
+	}
+
+	result, err := h.service.InitializeSystem(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to initialize system roles")
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// GetUserPermissionsSummary returns a user's roles at every scope and their effective permissions
+// @Summary Get user permissions summary
+// @Description List a user's global, organization and team roles and the union of permissions they grant
+// @Tags authorization
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param organization_id query int false "Limit organization and team roles to one organization"
+// @Success 200 {object} response.Response{data=UserPermissionsSummary}
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /v1/auth/users/{id}/permissions [get]
+func (h *handler) GetUserPermissionsSummary(c *gin.Context) {
+	userID, err := params.PathID(c, "id")
+	if err != nil {
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidUserID)
+		return
+	}
+
+	var query PermissionsSummaryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidQueryParams)
+		return
+	}
+
+	summary, err := h.service.GetUserPermissionsSummary(c.Request.Context(), userID, &query)
+	if err != nil {
+		respondAuthorizationError(c, err, "Failed to retrieve permissions summary")
+		return
+	}
+
+	response.Success(c, summary)
+}
+
+// AssignRoleToUser assigns a global role to a user
+// @Summary Assign role to user
+// @Description Assign a global role to a user. The change is recorded in the audit log
+// @Tags authorization
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body AssignRoleRequest true "Role assignment"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /v1/auth/users/{id}/roles [post]
+func (h *handler) AssignRoleToUser(c *gin.Context) {
+	userID, err := params.PathID(c, "id")
+	if err != nil {
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidUserID)
+		return
+	}
+
+	var req AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidRequestPayload)
+		return
+	}
+
+	actorID, ok := authctx.RequireUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.AssignRoleToUser(c.Request.Context(), userID, req.RoleID, actorID, req.ExpiresAt); err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Role assigned successfully"})
+}
+
+// AssignRolesToUser assigns several roles to a user in one request
+// @Summary Assign roles to user
+// @Description Assign several global roles to a user. Returns 207 with per-role results when only some roles were assigned. With atomic=true either every role is assigned or none are
+// @Tags authorization
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body AssignRolesRequest true "Role assignments"
+// @Success 200 {object} response.Response{data=batch.Result}
+// @Success 207 {object} response.Response{data=batch.Result}
+// @Failure 400 {object} response.Response{data=batch.Result}
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /v1/auth/users/{id}/roles/batch [post]
+func (h *handler) AssignRolesToUser(c *gin.Context) {
+	userID, err := params.PathID(c, "id")
+	if err != nil {
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidUserID)
+		return
+	}
+
+	var req AssignRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidRequestPayload)
+		return
+	}
+
+	actorID, ok := authctx.RequireUserID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.AssignRolesToUser(c.Request.Context(), userID, &req, actorID)
+	if err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.Batch(c, result)
+}
+
+// RemoveRoleFromUser removes a global role from a user
+// @Summary Remove role from user
+// @Description Remove a global role from a user. The change is recorded in the audit log
+// @Tags authorization
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param roleId path int true "Role ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /v1/auth/users/{id}/roles/{roleId} [delete]
+func (h *handler) RemoveRoleFromUser(c *gin.Context) {
+	userID, err := params.PathID(c, "id")
+	if err != nil {
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidUserID)
+		return
+	}
+
+	roleID, err := params.PathID(c, "roleId")
+	if err != nil {
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidRoleID)
+		return
+	}
+
+	actorID, ok := authctx.RequireUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveRoleFromUser(c.Request.Context(), userID, roleID, actorID); err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Role removed successfully"})
+}
+
+// DeleteRole soft-deletes a custom role
+// @Summary Delete role
+// @Description Soft-delete a custom role. It stops granting permissions but can be restored. System roles, and roles still held by organization members, cannot be deleted
+// @Tags authorization
+// @Accept json
+// @Produce json
+// @Param id path int true "Role ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /v1/auth/roles/{id} [delete]
+func (h *handler) DeleteRole(c *gin.Context) {
+	roleID, err := params.PathID(c, "id")
+	if err != nil {
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidRoleID)
+		return
+	}
+
+	actorID, ok := authctx.RequireUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteRole(c.Request.Context(), roleID, actorID); err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Role deleted successfully"})
+}
+
+// ListDeletedRoles lists soft-deleted roles
+// @Summary List deleted roles
+// @Description List soft-deleted roles that can be restored, most recently deleted first
+// @Tags authorization
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 20, max: 100)"
+// @Param search query string false "Search by name or display name"
+// @Success 200 {object} response.Response{data=RoleListResponse}
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /v1/auth/roles/deleted [get]
+func (h *handler) ListDeletedRoles(c *gin.Context) {
+	var query ListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidQueryParams)
+		return
+	}
+	query.Normalize()
+
+	roles, err := h.service.ListDeletedRoles(c.Request.Context(), &query)
+	if err != nil {
+		if response.Canceled(c, err) {
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to retrieve deleted roles")
+		return
+	}
+
+	pagination.SetHeaders(c, roles.Total, roles.Page, roles.PageSize)
+	response.Success(c, roles)
+}
+
+// RestoreRole restores a soft-deleted role
+// @Summary Restore role
+// @Description Restore a soft-deleted role. Its existing assignments and permissions take effect again
+// @Tags authorization
+// @Accept json
+// @Produce json
+// @Param id path int true "Role ID"
+// @Success 200 {object} response.Response{data=RoleResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /v1/auth/roles/{id}/restore [post]
+func (h *handler) RestoreRole(c *gin.Context) {
+	roleID, err := params.PathID(c, "id")
+	if err != nil {
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidRoleID)
+		return
+	}
+
+	actorID, ok := authctx.RequireUserID(c)
+	if !ok {
+		return
+	}
+
+	role, err := h.service.RestoreRole(c.Request.Context(), roleID, actorID)
+	if err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.Success(c, role)
+}
+
+// AssignPermissionsToRole grants permissions to a role
+// @Summary Assign permissions to role
+// @Description Grant permissions to a role. The change is recorded in the audit log
+// @Tags authorization
+// @Accept json
+// @Produce json
+// @Param id path int true "Role ID"
+// @Param request body AssignPermissionsRequest true "Permission IDs"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /v1/auth/roles/{id}/permissions [post]
+func (h *handler) AssignPermissionsToRole(c *gin.Context) {
+	roleID, err := params.PathID(c, "id")
+	if err != nil {
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidRoleID)
+		return
+	}
+
+	var req AssignPermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidRequestPayload)
+		return
+	}
+
+	actorID, ok := authctx.RequireUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.AssignPermissionsToRole(c.Request.Context(), roleID, req.PermissionIDs, actorID); err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Permissions assigned successfully"})
+}
+
+// RemovePermissionsFromRole revokes permissions from a role
+// @Summary Remove permissions from role
+// @Description Revoke permissions from a role. The change is recorded in the audit log
+// @Tags authorization
+// @Accept json
+// @Produce json
+// @Param id path int true "Role ID"
+// @Param request body AssignPermissionsRequest true "Permission IDs"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /v1/auth/roles/{id}/permissions [delete]
+func (h *handler) RemovePermissionsFromRole(c *gin.Context) {
+	roleID, err := params.PathID(c, "id")
+	if err != nil {
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidRoleID)
+		return
+	}
+
+	var req AssignPermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidRequestPayload)
+		return
+	}
+
+	actorID, ok := authctx.RequireUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemovePermissionsFromRole(c.Request.Context(), roleID, req.PermissionIDs, actorID); err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Permissions removed successfully"})
+}
+
+// PreviewRolePermissionChange lists the users a role permission edit would affect
+// @Summary Preview role permission change
+// @Description List holders of a role whose effective permissions would change if the permissions were assigned or removed, and whether they currently have cached permissions. Nothing is changed; applying the edit invalidates those caches
+// @Tags authorization
+// @Accept json
+// @Produce json
+// @Param id path int true "Role ID"
+// @Param request body RolePermissionChangeRequest true "Operation and permission IDs"
+// @Success 200 {object} response.Response{data=RolePermissionChangePreview}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /v1/auth/roles/{id}/permissions/preview [post]
+func (h *handler) PreviewRolePermissionChange(c *gin.Context) {
+	roleID, err := params.PathID(c, "id")
+	if err != nil {
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidRoleID)
+		return
+	}
+
+	var req RolePermissionChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidRequestPayload)
+		return
+	}
+
+	preview, err := h.service.PreviewRolePermissionChange(c.Request.Context(), roleID, &req)
+	if err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.Success(c, preview)
+}
+
+// DeactivateOrganizationRole deactivates a user's organization role, keeping the assignment
+// @Summary Deactivate organization role
+// @Description Mark a user's organization role assignment inactive without deleting it
+// @Tags authorization
+// @Accept json
+// @Produce json
+// @Param orgId path int true "Organization ID"
+// @Param userId path int true "User ID"
+// @Param roleId path int true "Role ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /v1/auth/organizations/{orgId}/users/{userId}/roles/{roleId}/deactivate [put]
+func (h *handler) DeactivateOrganizationRole(c *gin.Context) {
+	h.setOrganizationRoleActive(c, false)
+}
+
+// ReactivateOrganizationRole reactivates a previously deactivated organization role
+// @Summary Reactivate organization role
+// @Description Mark a user's organization role assignment active again
+// @Tags authorization
+// @Accept json
+// @Produce json
+// @Param orgId path int true "Organization ID"
+// @Param userId path int true "User ID"
+// @Param roleId path int true "Role ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /v1/auth/organizations/{orgId}/users/{userId}/roles/{roleId}/reactivate [put]
+func (h *handler) ReactivateOrganizationRole(c *gin.Context) {
+	h.setOrganizationRoleActive(c, true)
+}
+
+// DeactivateTeamRole deactivates a user's team role, keeping the assignment
+// @Summary Deactivate team role
+// @Description Mark a user's team role assignment inactive without deleting it
+// @Tags authorization
+// @Accept json
+// @Produce json
+// @Param teamId path int true "Team ID"
+// @Param userId path int true "User ID"
+// @Param roleId path int true "Role ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /v1/auth/teams/{teamId}/users/{userId}/roles/{roleId}/deactivate [put]
+func (h *handler) DeactivateTeamRole(c *gin.Context) {
+	h.setTeamRoleActive(c, false)
+}
+
+// ReactivateTeamRole reactivates a previously deactivated team role
+// @Summary Reactivate team role
+// @Description Mark a user's team role assignment active again
+// @Tags authorization
+// @Accept json
+// @Produce json
+// @Param teamId path int true "Team ID"
+// @Param userId path int true "User ID"
+// @Param roleId path int true "Role ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /v1/auth/teams/{teamId}/users/{userId}/roles/{roleId}/reactivate [put]
+func (h *handler) ReactivateTeamRole(c *gin.Context) {
+	h.setTeamRoleActive(c, true)
+}
+
+// BulkAssignOrganizationRole grants one organization role to many users
+// @Summary Bulk assign organization role
+// @Description Grant one organization role to up to 500 users in a single transaction. Users who already have the role are skipped and non-members are reported as failed, with 207 when only some users failed. Unknown user IDs reject the whole request
+// @Tags authorization
+// @Accept json
+// @Produce json
+// @Param orgId path int true "Organization ID"
+// @Param request body BulkAssignOrganizationRoleRequest true "User IDs and role"
+// @Success 200 {object} response.Response{data=batch.Result}
+// @Success 207 {object} response.Response{data=batch.Result}
+// @Failure 400 {object} response.Response{data=batch.Result}
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /v1/auth/organizations/{orgId}/bulk-assign-role [post]
+func (h *handler) BulkAssignOrganizationRole(c *gin.Context) {
+	ids, ok := parseIDParams(c, "orgId")
+	if !ok {
+		return
+	}
+
+	var req BulkAssignOrganizationRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidRequestPayload)
+		return
+	}
+	req.OrganizationID = ids[0]
+
+	actorID, ok := authctx.RequireUserID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.BulkAssignOrganizationRole(c.Request.Context(), &req, actorID)
+	if err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.Batch(c, result)
+}
+
+// setOrganizationRoleActive handles both organization role activation endpoints
+func (h *handler) setOrganizationRoleActive(c *gin.Context, active bool) {
+	ids, ok := parseIDParams(c, "orgId", "userId", "roleId")
+	if !ok {
+		return
+	}
+
+	actorID, ok := authctx.RequireUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.SetOrganizationRoleActive(c.Request.Context(), ids[0], ids[1], ids[2], active, actorID); err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"is_active": active})
+}
+
+// setTeamRoleActive handles both team role activation endpoints
+func (h *handler) setTeamRoleActive(c *gin.Context, active bool) {
+	ids, ok := parseIDParams(c, "teamId", "userId", "roleId")
+	if !ok {
+		return
+	}
+
+	actorID, ok := authctx.RequireUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.SetTeamRoleActive(c.Request.Context(), ids[0], ids[1], ids[2], active, actorID); err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"is_active": active})
+}
+
+// ListAuditLogs lists authorization audit log entries
+// @Summary List authorization audit logs
+// @Description List audit log entries for role and permission changes, filterable by actor, target, action and date range
+// @Tags authorization
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 20, max: 100)"
+// @Param cursor query int false "Keyset cursor from a previous next_cursor; overrides page"
+// @Param actor_id query int false "Filter by actor user ID"
+// @Param target_type query string false "Filter by resource type (user, role, organization, policy)"
+// @Param target_id query int false "Filter by target ID, e.g. the affected user"
+// @Param action query string false "Filter by action (e.g. role.assign)"
+// @Param from query string false "Start of date range (RFC3339)"
+// @Param to query string false "End of date range (RFC3339)"
+// @Success 200 {object} response.Response{data=AuditLogListResponse}
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /v1/auth/audit-logs [get]
+func (h *handler) ListAuditLogs(c *gin.Context) {
+	var query AuditLogQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidQueryParams)
+		return
+	}
+
+	logs, err := h.service.ListAuditLogs(c.Request.Context(), &query)
+	if err != nil {
+		if response.Canceled(c, err) {
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to retrieve audit logs")
+		return
+	}
+
+	pagination.SetHeaders(c, logs.Total, logs.Page, logs.PageSize)
+	response.Success(c, logs)
+}
+
+// ExportOrganizationAuditLogs streams an organization's audit events for a date range as CSV or JSON
+// @Summary Export organization audit events
+// @Description Download every audit event recorded for the organization between from and to (inclusive), oldest first. Requires audit_logs.read in the organization. Errors use the shared response envelope.
+// @Tags authorization
+// @Produce text/csv,json
+// @Param id path int true "Organization ID"
+// @Param from query string true "Start of date range (RFC3339)"
+// @Param to query string true "End of date range (RFC3339)"
+// @Param format query string false "Export format (csv or json)" default(csv)
+// @Success 200 {file} file
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/v1/organizations/{id}/audit/export [get]
+func (h *handler) ExportOrganizationAuditLogs(c *gin.Context) {
+	ids, ok := parseIDParams(c, "id")
+	if !ok {
+		return
+	}
+	organizationID := ids[0]
+
+	var query AuditExportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidQueryParams)
+		return
+	}
+	if query.To.Before(query.From) {
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidDateRange)
+		return
+	}
+
+	contentType := "text/csv"
+	switch query.Format {
+	case "":
+		query.Format = AuditExportFormatCSV
+	case AuditExportFormatCSV:
+	case AuditExportFormatJSON:
+		contentType = "application/json"
+	default:
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidExportFormat)
+		return
+	}
+
+	filename := fmt.Sprintf("organization-%d-audit-%s-%s.%s", organizationID,
+		query.From.UTC().Format("20060102"), query.To.UTC().Format("20060102"), query.Format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	// Headers are already sent, so a failure part-way can only be logged
+	if err := h.service.ExportOrganizationAuditLogs(c.Request.Context(), organizationID, &query, c.Writer); err != nil {
+		logger.Error("Failed to export organization audit logs", err)
+	}
+}
+
+// ListPolicies lists authorization policies
+// @Summary List policies
+// @Description List policies with pagination, highest priority first
+// @Tags authorization
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 20, max: 100)"
+// @Param resource query string false "Filter by resource (exact match)"
+// @Param effect query string false "Filter by effect (allow, deny)"
+// @Param subject query string false "Filter by subject, e.g. role:1"
+// @Param status query int false "Filter by status"
+// @Success 200 {object} response.Response{data=PolicyListResponse}
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /v1/auth/policies [get]
+func (h *handler) ListPolicies(c *gin.Context) {
+	var query PolicyQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidQueryParams)
+		return
+	}
+
+	policies, err := h.service.ListPolicies(c.Request.Context(), &query)
+	if err != nil {
+		if response.Canceled(c, err) {
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to retrieve policies")
+		return
+	}
+
+	pagination.SetHeaders(c, policies.Total, policies.Page, policies.PageSize)
+	response.Success(c, policies)
+}
+
+// CreatePolicy creates an authorization policy
+// @Summary Create policy
+// @Description Create an allow or deny policy. Conditions must be a JSON object keyed by condition type (time_window, ip_range)
+// @Tags authorization
+// @Accept json
+// @Produce json
+// @Param request body CreatePolicyRequest true "Policy"
+// @Success 201 {object} response.Response{data=PolicyResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /v1/auth/policies [post]
+func (h *handler) CreatePolicy(c *gin.Context) {
+	var req CreatePolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidRequestPayload)
+		return
+	}
+
+	actorID, ok := authctx.RequireUserID(c)
+	if !ok {
+		return
+	}
+
+	policy, err := h.service.CreatePolicy(c.Request.Context(), &req, actorID)
+	if err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.Created(c, policy)
+}
+
+// GetPolicy retrieves an authorization policy
+// @Summary Get policy
+// @Description Get a policy by ID
+// @Tags authorization
+// @Accept json
+// @Produce json
+// @Param id path int true "Policy ID"
+// @Success 200 {object} response.Response{data=PolicyResponse}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /v1/auth/policies/{id} [get]
+func (h *handler) GetPolicy(c *gin.Context) {
+	ids, ok := parseIDParams(c, "id")
+	if !ok {
+		return
+	}
+
+	policy, err := h.service.GetPolicy(c.Request.Context(), ids[0])
+	if err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.Success(c, policy)
+}
+
+// UpdatePolicy updates an authorization policy
+// @Summary Update policy
+// @Description Update the provided fields of a policy. Sending conditions as null removes them
+// @Tags authorization
+// @Accept json
+// @Produce json
+// @Param id path int true "Policy ID"
+// @Param request body UpdatePolicyRequest true "Policy fields"
+// @Success 200 {object} response.Response{data=PolicyResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /v1/auth/policies/{id} [put]
+func (h *handler) UpdatePolicy(c *gin.Context) {
+	ids, ok := parseIDParams(c, "id")
+	if !ok {
+		return
+	}
+
+	var req UpdatePolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidRequestPayload)
+		return
+	}
+
+	actorID, ok := authctx.RequireUserID(c)
+	if !ok {
+		return
+	}
+
+	policy, err := h.service.UpdatePolicy(c.Request.Context(), ids[0], &req, actorID)
+	if err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.Success(c, policy)
+}
+
+// DeletePolicy deletes an authorization policy
+// @Summary Delete policy
+// @Description Soft-delete a policy so it no longer takes part in permission checks
+// @Tags authorization
+// @Accept json
+// @Produce json
+// @Param id path int true "Policy ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /v1/auth/policies/{id} [delete]
+func (h *handler) DeletePolicy(c *gin.Context) {
+	ids, ok := parseIDParams(c, "id")
+	if !ok {
+		return
+	}
+
+	actorID, ok := authctx.RequireUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeletePolicy(c.Request.Context(), ids[0], actorID); err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Policy deleted successfully"})
+}
+
+// parseIDParams parses the named numeric path parameters in order,
+// writing an error response on the first invalid one
+func parseIDParams(c *gin.Context, names ...string) ([]uint, bool) {
+	ids := make([]uint, 0, len(names))
+	for _, name := range names {
+		id, err := params.PathID(c, name)
+		if err != nil {
+			response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidParam, name)
+			return nil, false
+		}
+		ids = append(ids, id)
+	}
+	return ids, true
+}
+
+// respondAuthorizationError writes the status and code of typed errors, and 500 with message
+// for anything else
+func respondAuthorizationError(c *gin.Context, err error, message string) {
+	if status := response.StatusFromError(err, http.StatusInternalServerError); status != http.StatusInternalServerError {
+		response.ErrorFrom(c, status, err)
+		return
+	}
+	response.Error(c, http.StatusInternalServerError, message)
+}
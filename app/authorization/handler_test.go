@@ -0,0 +1,212 @@
+package authorization
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/pkg/dbtest"
+	"github.com/llamacto/llama-gin-kit/pkg/response"
+)
+
+func TestListAbortsWhenRequestIsCanceled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name  string
+		path  string
+		table string
+		list  func(Handler) gin.HandlerFunc
+	}{
+		{name: "roles", path: "/roles", table: "roles", list: func(h Handler) gin.HandlerFunc { return h.ListRoles }},
+		{name: "system roles", path: "/roles/system", table: "roles", list: func(h Handler) gin.HandlerFunc { return h.ListSystemRoles }},
+		{name: "permissions", path: "/permissions", table: "permissions", list: func(h Handler) gin.HandlerFunc { return h.ListPermissions }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, db := dbtest.Open(t)
+			started := db.Blocks(`FROM "` + tt.table + `"`)
+
+			router := gin.New()
+			router.GET(tt.path, tt.list(NewHandler(newTestService(NewRepository(gormDB)))))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			w := httptest.NewRecorder()
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil).WithContext(ctx))
+			}()
+
+			select {
+			case <-started:
+			case <-time.After(time.Second):
+				t.Fatal("list query did not start")
+			}
+			cancel()
+
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("handler did not return after the request was canceled")
+			}
+			if w.Code != response.StatusClientClosedRequest {
+				t.Fatalf("status = %d, want %d", w.Code, response.StatusClientClosedRequest)
+			}
+			if n := len(selects(db, tt.table)); n != 1 {
+				t.Fatalf("%d queries ran on %s, want only the aborted one", n, tt.table)
+			}
+		})
+	}
+}
+
+func TestInvalidPathIDsAreBadRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// The handler has no service, so a request that gets past ID validation panics and
+	// the recovery middleware answers 500
+	h := NewHandler(nil)
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.GET("/users/:id/permissions", h.GetUserPermissionsSummary)
+	router.DELETE("/users/:id/roles/:roleId", h.RemoveRoleFromUser)
+	router.DELETE("/roles/:id", h.DeleteRole)
+	router.POST("/roles/:id/restore", h.RestoreRole)
+	router.PUT("/organizations/:orgId/users/:userId/roles/:roleId/deactivate", h.DeactivateOrganizationRole)
+	router.PUT("/teams/:teamId/users/:userId/roles/:roleId/reactivate", h.ReactivateTeamRole)
+
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{method: http.MethodGet, path: "/users/0/permissions"},
+		{method: http.MethodGet, path: "/users/-1/permissions"},
+		{method: http.MethodDelete, path: "/users/1/roles/0"},
+		{method: http.MethodDelete, path: "/users/-5/roles/1"},
+		{method: http.MethodDelete, path: "/roles/0"},
+		{method: http.MethodDelete, path: "/roles/-1"},
+		{method: http.MethodDelete, path: "/roles/abc"},
+		{method: http.MethodPost, path: "/roles/0/restore"},
+		{method: http.MethodPut, path: "/organizations/0/users/1/roles/1/deactivate"},
+		{method: http.MethodPut, path: "/organizations/1/users/1/roles/-1/deactivate"},
+		{method: http.MethodPut, path: "/teams/1/users/0/roles/1/reactivate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusBadRequest, w.Body)
+			}
+		})
+	}
+}
+
+func TestResolvePermissions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		body           string
+		nameRows       [][]driver.Value // Rows the database returns for the name lookup
+		idRows         [][]driver.Value // Rows the database returns for the ID lookup
+		want           int
+		wantResponse   ResolvePermissionsResponse
+		wantNameLookup bool
+		wantIDLookup   bool
+	}{
+		{
+			name:     "mixed names and IDs",
+			body:     `{"names":["roles.read","nope","roles.update","nope"],"ids":[3,99,1,99]}`,
+			nameRows: [][]driver.Value{{int64(1), "roles.read"}, {int64(2), "roles.update"}},
+			idRows:   [][]driver.Value{{int64(1), "roles.read"}, {int64(3), "roles.delete"}},
+			want:     http.StatusOK,
+			wantResponse: ResolvePermissionsResponse{
+				IDs:             map[string]uint{"roles.read": 1, "roles.update": 2},
+				Names:           map[uint]string{1: "roles.read", 3: "roles.delete"},
+				UnresolvedNames: []string{"nope"},
+				UnresolvedIDs:   []uint{99},
+			},
+			wantNameLookup: true,
+			wantIDLookup:   true,
+		},
+		{
+			name:     "names only",
+			body:     `{"names":["ghost","roles.read"]}`,
+			nameRows: [][]driver.Value{{int64(1), "roles.read"}},
+			want:     http.StatusOK,
+			wantResponse: ResolvePermissionsResponse{
+				IDs:             map[string]uint{"roles.read": 1},
+				Names:           map[uint]string{},
+				UnresolvedNames: []string{"ghost"},
+				UnresolvedIDs:   []uint{},
+			},
+			wantNameLookup: true,
+		},
+		{
+			name: "nothing resolves",
+			body: `{"ids":[42]}`,
+			want: http.StatusOK,
+			wantResponse: ResolvePermissionsResponse{
+				IDs:             map[string]uint{},
+				Names:           map[uint]string{},
+				UnresolvedNames: []string{},
+				UnresolvedIDs:   []uint{42},
+			},
+			wantIDLookup: true,
+		},
+		{name: "neither list", body: `{}`, want: http.StatusBadRequest},
+		{name: "empty name", body: `{"names":[""]}`, want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, db := dbtest.Open(t)
+			db.Returns(`WHERE name IN`, []string{"id", "name"}, tt.nameRows...)
+			db.Returns(`WHERE id IN`, []string{"id", "name"}, tt.idRows...)
+
+			router := gin.New()
+			router.POST("/permissions/resolve", NewHandler(newTestService(NewRepository(gormDB))).ResolvePermissions)
+			req := httptest.NewRequest(http.MethodPost, "/permissions/resolve", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if _, ok := db.Find(`WHERE name IN`); ok != tt.wantNameLookup {
+				t.Fatalf("looked up names = %v, want %v", ok, tt.wantNameLookup)
+			}
+			if _, ok := db.Find(`WHERE id IN`); ok != tt.wantIDLookup {
+				t.Fatalf("looked up IDs = %v, want %v", ok, tt.wantIDLookup)
+			}
+			if n := len(selects(db, "permissions")); n > 2 {
+				t.Fatalf("sent %d permission queries, want at most one per list", n)
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+
+			var body struct {
+				Data ResolvePermissionsResponse `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if !reflect.DeepEqual(body.Data, tt.wantResponse) {
+				t.Fatalf("response = %+v, want %+v", body.Data, tt.wantResponse)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package authorization
 import (
 	"time"
 
+	"github.com/llamacto/llama-gin-kit/pkg/invariant"
 	"gorm.io/gorm"
 )
 
@@ -25,6 +26,14 @@ type Role struct {
 	Users       []UserRole    `gorm:"foreignKey:RoleID" json:"users,omitempty"`
 }
 
+// BeforeSave rejects role rows without a name or display name
+func (r *Role) BeforeSave(tx *gorm.DB) error {
+	return invariant.Required(tx, "role",
+		invariant.Field{Column: "name", Value: r.Name},
+		invariant.Field{Column: "display_name", Value: r.DisplayName},
+	)
+}
+
 // Permission represents a specific permission in the system
 type Permission struct {
 	ID        uint           `gorm:"primaryKey" json:"id"`
@@ -45,6 +54,15 @@ type Permission struct {
 	Roles []*Role `gorm:"many2many:role_permissions;" json:"roles,omitempty"`
 }
 
+// BeforeSave rejects permission rows without a name, resource or action
+func (p *Permission) BeforeSave(tx *gorm.DB) error {
+	return invariant.Required(tx, "permission",
+		invariant.Field{Column: "name", Value: p.Name},
+		invariant.Field{Column: "resource", Value: p.Resource},
+		invariant.Field{Column: "action", Value: p.Action},
+	)
+}
+
 // UserRole represents the relationship between users and roles
 type UserRole struct {
 	ID        uint           `gorm:"primaryKey" json:"id"`
@@ -62,6 +80,14 @@ type UserRole struct {
 	Role Role `gorm:"foreignKey:RoleID" json:"role,omitempty"`
 }
 
+// BeforeSave rejects user role rows without a user or role
+func (ur *UserRole) BeforeSave(tx *gorm.DB) error {
+	return invariant.Required(tx, "user role",
+		invariant.Field{Column: "user_id", Value: ur.UserID},
+		invariant.Field{Column: "role_id", Value: ur.RoleID},
+	)
+}
+
 // OrganizationRole represents organization-specific roles
 type OrganizationRole struct {
 	ID        uint           `gorm:"primaryKey" json:"id"`
@@ -79,6 +105,15 @@ type OrganizationRole struct {
 	Role Role `gorm:"foreignKey:RoleID" json:"role,omitempty"`
 }
 
+// BeforeSave rejects organization role rows without a user, organization or role
+func (or *OrganizationRole) BeforeSave(tx *gorm.DB) error {
+	return invariant.Required(tx, "organization role",
+		invariant.Field{Column: "user_id", Value: or.UserID},
+		invariant.Field{Column: "organization_id", Value: or.OrganizationID},
+		invariant.Field{Column: "role_id", Value: or.RoleID},
+	)
+}
+
 // TeamRole represents team-specific roles
 type TeamRole struct {
 	ID        uint           `gorm:"primaryKey" json:"id"`
@@ -96,6 +131,22 @@ type TeamRole struct {
 	Role Role `gorm:"foreignKey:RoleID" json:"role,omitempty"`
 }
 
+// TeamNode is a team's place in the hierarchy as seen by team permission checks
+type TeamNode struct {
+	ID                     uint
+	ParentTeamID           *uint
+	InheritTeamPermissions bool
+}
+
+// BeforeSave rejects team role rows without a user, team or role
+func (tr *TeamRole) BeforeSave(tx *gorm.DB) error {
+	return invariant.Required(tx, "team role",
+		invariant.Field{Column: "user_id", Value: tr.UserID},
+		invariant.Field{Column: "team_id", Value: tr.TeamID},
+		invariant.Field{Column: "role_id", Value: tr.RoleID},
+	)
+}
+
 // TableName methods for custom table names
 func (Role) TableName() string {
 	return "roles"
@@ -124,12 +175,21 @@ type Policy struct {
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 
-	Subject string `gorm:"size:100;not null" json:"subject"` // e.g., "role:1", "user:2"
-	Action  string `gorm:"size:100;not null" json:"action"`  // e.g., "read", "write"
-	Object  string `gorm:"size:100;not null" json:"object"`  // e.g., "article:1", "dataset:2"
-	Effect  string `gorm:"size:10;not null" json:"effect"`   // "allow" or "deny"
+	Subject    string  `gorm:"size:100;not null" json:"subject"`       // e.g., "role:1", "user:2", "*"
+	Action     string  `gorm:"size:100;not null" json:"action"`        // e.g., "read", "write", "*"
+	Object     string  `gorm:"size:100;not null" json:"object"`        // e.g., "article:1", "dataset:*"
+	Effect     string  `gorm:"size:10;not null" json:"effect"`         // "allow" or "deny"
+	Conditions *string `gorm:"type:jsonb" json:"conditions,omitempty"` // JSON object keyed by condition type, all must hold
+	Priority   int     `gorm:"default:0;index" json:"priority"`        // Higher priority policies are evaluated first
+	Status     int     `gorm:"default:1" json:"status"`                // 1: active, 0: inactive
 }
 
+// Policy effects
+const (
+	PolicyEffectAllow = "allow"
+	PolicyEffectDeny  = "deny"
+)
+
 // RolePermission is the explicit join table for the many-to-many relationship
 // between Role and Permission.
 type RolePermission struct {
@@ -145,3 +205,38 @@ func (Policy) TableName() string {
 func (RolePermission) TableName() string {
 	return "role_permissions"
 }
+
+// Audit actions recorded for authorization changes
+const (
+	AuditActionRoleAssign       = "role.assign"
+	AuditActionRoleRemove       = "role.remove"
+	AuditActionPermissionAssign = "permission.assign"
+	AuditActionPermissionRemove = "permission.remove"
+	AuditActionRoleDeactivate   = "role.deactivate"
+	AuditActionRoleReactivate   = "role.reactivate"
+	AuditActionRoleDelete       = "role.delete"
+	AuditActionRoleRestore      = "role.restore"
+	AuditActionPolicyCreate     = "policy.create"
+	AuditActionPolicyUpdate     = "policy.update"
+	AuditActionPolicyDelete     = "policy.delete"
+)
+
+// AuthAuditLog records who changed an authorization assignment and when.
+// Rows are append-only and written in the same transaction as the change.
+type AuthAuditLog struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	ActorID    uint   `gorm:"not null;index" json:"actor_id"`                                                  // User ID who performed the change
+	Action     string `gorm:"size:50;not null;index" json:"action"`                                            // e.g. "role.assign"
+	TargetType string `gorm:"size:50;not null;index:idx_auth_audit_logs_target,priority:1" json:"target_type"` // e.g. "user", "role"
+	TargetID   uint   `gorm:"not null;index:idx_auth_audit_logs_target,priority:2" json:"target_id"`
+	Diff       string `gorm:"type:jsonb" json:"diff"` // JSON description of the change
+
+	// OrganizationID is the organization the change belongs to, nil for global changes
+	OrganizationID *uint `gorm:"index" json:"organization_id,omitempty"`
+}
+
+func (AuthAuditLog) TableName() string {
+	return "auth_audit_logs"
+}
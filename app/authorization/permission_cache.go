@@ -0,0 +1,403 @@
+package authorization
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/pkg/logger"
+	"github.com/llamacto/llama-gin-kit/pkg/redis"
+)
+
+// PermissionCacheTTL bounds how long a user's role-derived permissions are reused across
+// requests. Role and permission changes made through the service invalidate entries early.
+const PermissionCacheTTL = 5 * time.Minute
+
+// userPermissions is what CheckPermission needs about a user's global roles
+type userPermissions struct {
+	roleIDs    []uint
+	superAdmin bool
+	names      map[string]bool
+	expiresAt  time.Time
+}
+
+// permissionStore caches userPermissions by user ID. The service reads the version before
+// loading an entry from the database and passes it to set, which refuses the entry when an
+// invalidation of that user happened in between, since it may have been loaded from stale data.
+// Stores never fail the permission check: a backend error counts as a miss.
+type permissionStore interface {
+	get(ctx context.Context, userID uint) (*userPermissions, bool)
+	currentVersion(ctx context.Context, userID uint) permissionVersion
+	set(ctx context.Context, userID uint, entry *userPermissions, version permissionVersion)
+	invalidate(ctx context.Context, userIDs ...uint)
+	invalidateAll(ctx context.Context)
+}
+
+// permissionVersion identifies the invalidations a cached entry was loaded after
+type permissionVersion struct {
+	generation uint64 // Bumped by invalidateAll, and by every invalidation in memory
+	user       uint64 // Bumped by invalidations of the user, where tracked per user
+}
+
+// Permission cache backends
+const (
+	PermissionCacheMemory = "memory"
+	PermissionCacheRedis  = "redis"
+	PermissionCacheOff    = "off"
+)
+
+// sharedPermissionStore is used by every service instance in the process so an invalidation
+// through one instance is seen by all of them. ConfigurePermissionCache replaces it at startup.
+var sharedPermissionStore permissionStore = newPermissionCache()
+
+// ConfigurePermissionCache selects where user permissions are cached: "memory" keeps them in
+// this process, "redis" shares them, and their invalidations, between instances, and "off"
+// loads them from the database on every check. Without a Redis client "redis" falls back to
+// memory. Users' global and organization permissions share the backend; roles' permissions are
+// only cached in memory, since they are not invalidated across instances. Call it before
+// creating services.
+func ConfigurePermissionCache(backend string, client *redis.Client) {
+	switch backend {
+	case PermissionCacheOff:
+		sharedPermissionStore = noPermissionCache{}
+		sharedOrgPermissionCache = noOrgPermissionCache{}
+		sharedRolePermissionCache.disable()
+	case PermissionCacheRedis:
+		if client == nil {
+			logger.Warn("Redis unavailable, caching permissions in memory")
+			return
+		}
+		sharedPermissionStore = newRedisPermissionCache(client, PermissionCacheTTL)
+		sharedOrgPermissionCache = newRedisOrgPermissionCache(client, PermissionCacheTTL)
+		sharedRolePermissionCache.disable()
+	}
+}
+
+// permissionCache is the in-process permissionStore
+type permissionCache struct {
+	mu      sync.RWMutex
+	entries map[uint]*userPermissions
+	version uint64 // Bumped by every invalidation
+}
+
+// newPermissionCache creates an empty in-process cache
+func newPermissionCache() *permissionCache {
+	return &permissionCache{entries: make(map[uint]*userPermissions)}
+}
+
+// get returns the user's unexpired entry
+func (c *permissionCache) get(ctx context.Context, userID uint) (*userPermissions, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[userID]
+	if !ok || !time.Now().Before(entry.expiresAt) {
+		return nil, false
+	}
+	return entry, true
+}
+
+// currentVersion returns the invalidation counter, read before loading an entry
+func (c *permissionCache) currentVersion(ctx context.Context, userID uint) permissionVersion {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return permissionVersion{generation: c.version}
+}
+
+// set stores the user's entry unless an invalidation happened since version was read
+func (c *permissionCache) set(ctx context.Context, userID uint, entry *userPermissions, version permissionVersion) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.version == version.generation {
+		c.entries[userID] = entry
+	}
+}
+
+// invalidate drops the entries of the given users
+func (c *permissionCache) invalidate(ctx context.Context, userIDs ...uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version++
+	for _, userID := range userIDs {
+		delete(c.entries, userID)
+	}
+}
+
+// invalidateAll drops every entry
+func (c *permissionCache) invalidateAll(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version++
+	c.entries = make(map[uint]*userPermissions)
+}
+
+// noPermissionCache is the permissionStore used when caching is off
+type noPermissionCache struct{}
+
+func (noPermissionCache) get(context.Context, uint) (*userPermissions, bool) { return nil, false }
+func (noPermissionCache) currentVersion(context.Context, uint) permissionVersion {
+	return permissionVersion{}
+}
+func (noPermissionCache) set(context.Context, uint, *userPermissions, permissionVersion) {}
+func (noPermissionCache) invalidate(context.Context, ...uint)                            {}
+func (noPermissionCache) invalidateAll(context.Context)                                  {}
+
+// InvalidateUserPermissions drops the cached permissions of the given users so their next
+// permission check reloads roles from the database. Modules that change role assignments
+// outside this package should call it with the request context, which bounds the call when
+// the cache is shared through Redis.
+func InvalidateUserPermissions(ctx context.Context, userIDs ...uint) {
+	sharedPermissionStore.invalidate(ctx, userIDs...)
+}
+
+// orgPermissionKey identifies one user's permissions within one organization
+type orgPermissionKey struct {
+	userID         uint
+	organizationID uint
+}
+
+// orgPermissions is the permission set a user's membership and organization roles grant
+type orgPermissions struct {
+	names     map[string]bool
+	expiresAt time.Time
+}
+
+// orgPermissionStore caches orgPermissions by user and organization. Like permissionStore it
+// refuses entries loaded before an invalidation that covers them, and never fails the check.
+type orgPermissionStore interface {
+	get(ctx context.Context, userID, organizationID uint) (*orgPermissions, bool)
+	currentVersion(ctx context.Context, userID, organizationID uint) orgPermissionVersion
+	set(ctx context.Context, userID, organizationID uint, entry *orgPermissions, version orgPermissionVersion)
+	invalidate(ctx context.Context, organizationID uint, userIDs ...uint)
+	invalidateAll(ctx context.Context)
+}
+
+// orgPermissionVersion identifies the invalidations a cached organization entry was loaded after
+type orgPermissionVersion struct {
+	generation   uint64 // Bumped by invalidateAll, and by every invalidation in memory
+	organization uint64 // Bumped by invalidations of the whole organization, where tracked
+	user         uint64 // Bumped by invalidations of the user in the organization, where tracked
+}
+
+// orgPermissionCache is the in-process orgPermissionStore
+type orgPermissionCache struct {
+	mu      sync.RWMutex
+	entries map[orgPermissionKey]*orgPermissions
+	version uint64 // Bumped by every invalidation
+}
+
+// newOrgPermissionCache creates an empty in-process cache
+func newOrgPermissionCache() *orgPermissionCache {
+	return &orgPermissionCache{entries: make(map[orgPermissionKey]*orgPermissions)}
+}
+
+var sharedOrgPermissionCache orgPermissionStore = newOrgPermissionCache()
+
+// get returns the unexpired entry for the user in the organization
+func (c *orgPermissionCache) get(ctx context.Context, userID, organizationID uint) (*orgPermissions, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[orgPermissionKey{userID, organizationID}]
+	if !ok || !time.Now().Before(entry.expiresAt) {
+		return nil, false
+	}
+	return entry, true
+}
+
+// currentVersion returns the invalidation counter, read before loading an entry
+func (c *orgPermissionCache) currentVersion(ctx context.Context, userID, organizationID uint) orgPermissionVersion {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return orgPermissionVersion{generation: c.version}
+}
+
+// set stores the entry unless an invalidation happened since version was read
+func (c *orgPermissionCache) set(ctx context.Context, userID, organizationID uint, entry *orgPermissions, version orgPermissionVersion) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.version == version.generation {
+		c.entries[orgPermissionKey{userID, organizationID}] = entry
+	}
+}
+
+// invalidate drops the given users' entries in the organization, or every entry in the
+// organization when no users are given
+func (c *orgPermissionCache) invalidate(ctx context.Context, organizationID uint, userIDs ...uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version++
+	if len(userIDs) == 0 {
+		for key := range c.entries {
+			if key.organizationID == organizationID {
+				delete(c.entries, key)
+			}
+		}
+		return
+	}
+	for _, userID := range userIDs {
+		delete(c.entries, orgPermissionKey{userID, organizationID})
+	}
+}
+
+// invalidateAll drops every entry
+func (c *orgPermissionCache) invalidateAll(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version++
+	c.entries = make(map[orgPermissionKey]*orgPermissions)
+}
+
+// noOrgPermissionCache is the orgPermissionStore used when caching is off
+type noOrgPermissionCache struct{}
+
+func (noOrgPermissionCache) get(context.Context, uint, uint) (*orgPermissions, bool) {
+	return nil, false
+}
+func (noOrgPermissionCache) currentVersion(context.Context, uint, uint) orgPermissionVersion {
+	return orgPermissionVersion{}
+}
+func (noOrgPermissionCache) set(context.Context, uint, uint, *orgPermissions, orgPermissionVersion) {}
+func (noOrgPermissionCache) invalidate(context.Context, uint, ...uint)                              {}
+func (noOrgPermissionCache) invalidateAll(context.Context)                                          {}
+
+// InvalidateOrganizationPermissions drops the cached organization permissions of the given
+// users, or of everyone in the organization when no users are given. Modules that change
+// memberships or member roles should call it with the request context.
+func InvalidateOrganizationPermissions(ctx context.Context, organizationID uint, userIDs ...uint) {
+	sharedOrgPermissionCache.invalidate(ctx, organizationID, userIDs...)
+}
+
+// rolePermissions is the permission names one role grants
+type rolePermissions struct {
+	names     []string
+	expiresAt time.Time
+}
+
+// rolePermissionCache holds rolePermissions by role ID, so turning roles into permissions
+// needs no join once the roles are cached. Like the other caches it is shared process-wide
+// and refuses entries loaded before the latest invalidation.
+type rolePermissionCache struct {
+	mu       sync.RWMutex
+	entries  map[uint]*rolePermissions
+	version  uint64 // Bumped by every invalidation
+	disabled bool   // Set when permissions are not cached in memory; nothing is stored
+}
+
+var sharedRolePermissionCache = &rolePermissionCache{entries: make(map[uint]*rolePermissions)}
+
+// get returns the names granted by each role with an unexpired entry, and the roles without one
+func (c *rolePermissionCache) get(roleIDs []uint) (map[uint][]string, []uint) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	found := make(map[uint][]string, len(roleIDs))
+	var missing []uint
+	for _, roleID := range roleIDs {
+		entry, ok := c.entries[roleID]
+		if !ok || !now.Before(entry.expiresAt) {
+			missing = append(missing, roleID)
+			continue
+		}
+		found[roleID] = entry.names
+	}
+	return found, missing
+}
+
+// currentVersion returns the invalidation counter, read before loading entries
+func (c *rolePermissionCache) currentVersion() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.version
+}
+
+// set stores the names of each of roleIDs, which grant nothing when absent from names, unless
+// an invalidation happened since version was read. Entries expire after PermissionCacheTTL.
+func (c *rolePermissionCache) set(roleIDs []uint, names map[uint][]string, version uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.version != version || c.disabled {
+		return
+	}
+	expiresAt := time.Now().Add(PermissionCacheTTL)
+	for _, roleID := range roleIDs {
+		c.entries[roleID] = &rolePermissions{names: names[roleID], expiresAt: expiresAt}
+	}
+}
+
+// invalidate drops the entries of the given roles
+func (c *rolePermissionCache) invalidate(roleIDs ...uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version++
+	for _, roleID := range roleIDs {
+		delete(c.entries, roleID)
+	}
+}
+
+// enabled reports whether the cache stores entries
+func (c *rolePermissionCache) enabled() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return !c.disabled
+}
+
+// disable stops the cache storing entries and drops the ones it holds
+func (c *rolePermissionCache) disable() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version++
+	c.disabled = true
+	c.entries = make(map[uint]*rolePermissions)
+}
+
+// PermissionCatalogTTL bounds how long the permission catalog is reused. Permissions change
+// rarely, and creating them through the service invalidates the catalog early.
+const PermissionCatalogTTL = time.Minute
+
+// catalogCache holds the permission catalog shared by every service instance in the process
+type catalogCache struct {
+	mu        sync.RWMutex
+	catalog   *PermissionCatalog
+	expiresAt time.Time
+	version   uint64 // Bumped by every invalidation
+}
+
+var sharedCatalogCache = &catalogCache{}
+
+// get returns the unexpired catalog
+func (c *catalogCache) get() (*PermissionCatalog, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.catalog == nil || !time.Now().Before(c.expiresAt) {
+		return nil, false
+	}
+	return c.catalog, true
+}
+
+// currentVersion returns the invalidation counter, read before loading the catalog
+func (c *catalogCache) currentVersion() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.version
+}
+
+// set stores the catalog unless an invalidation happened since version was read
+func (c *catalogCache) set(catalog *PermissionCatalog, version uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.version == version {
+		c.catalog = catalog
+		c.expiresAt = time.Now().Add(PermissionCatalogTTL)
+	}
+}
+
+// invalidate drops the cached catalog
+func (c *catalogCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version++
+	c.catalog = nil
+}
@@ -0,0 +1,397 @@
+package authorization
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/pkg/logger"
+	"github.com/llamacto/llama-gin-kit/pkg/redis"
+)
+
+// Redis keys of the shared permission cache. Entries are stored as "<generation>:<json>" and
+// only count while the generation matches, so invalidateAll is a single INCR rather than a
+// scan over every user.
+const (
+	redisPermissionGenerationKey = "authz:perm:generation"
+	redisPermissionEntryKey      = "authz:perm:user:%d"
+	redisPermissionVersionKey    = "authz:perm:version:%d"
+)
+
+// invalidationTimeout bounds an invalidation, which runs even if the request that caused it
+// was cancelled
+const invalidationTimeout = 3 * time.Second
+
+// getPermissionsScript returns the entry's JSON when it belongs to the current generation
+const getPermissionsScript = `
+local generation = redis.call('GET', KEYS[1]) or '0'
+local value = redis.call('GET', KEYS[2])
+if not value then return false end
+local sep = string.find(value, ':', 1, true)
+if not sep or string.sub(value, 1, sep - 1) ~= generation then return false end
+return string.sub(value, sep + 1)
+`
+
+// versionScript returns the current generation and the user's version
+const versionScript = `
+return {redis.call('GET', KEYS[1]) or '0', redis.call('GET', KEYS[2]) or '0'}
+`
+
+// setPermissionsScript stores the entry only when neither the generation nor the user's
+// version changed since they were read
+const setPermissionsScript = `
+if (redis.call('GET', KEYS[1]) or '0') ~= ARGV[1] then return 0 end
+if (redis.call('GET', KEYS[2]) or '0') ~= ARGV[2] then return 0 end
+redis.call('SET', KEYS[3], ARGV[1] .. ':' .. ARGV[3], 'PX', ARGV[4])
+return 1
+`
+
+// invalidateScript bumps each user's version and drops their entry. A version outlives any
+// entry loaded before it changed, after which a missing version reads as 0 again.
+const invalidateScript = `
+for i = 1, #KEYS, 2 do
+	redis.call('INCR', KEYS[i])
+	redis.call('PEXPIRE', KEYS[i], ARGV[1])
+	redis.call('DEL', KEYS[i + 1])
+end
+return 1
+`
+
+// storedPermissions is the JSON form of userPermissions
+type storedPermissions struct {
+	RoleIDs    []uint    `json:"role_ids"`
+	SuperAdmin bool      `json:"super_admin"`
+	Names      []string  `json:"names"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// redisPermissionCache is the permissionStore shared by every instance through Redis
+type redisPermissionCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// newRedisPermissionCache creates a Redis backed cache keeping entries for at most ttl
+func newRedisPermissionCache(client *redis.Client, ttl time.Duration) *redisPermissionCache {
+	return &redisPermissionCache{client: client, ttl: ttl}
+}
+
+// get returns the user's unexpired entry
+func (c *redisPermissionCache) get(ctx context.Context, userID uint) (*userPermissions, bool) {
+	reply, err := c.client.Do(ctx, "EVAL", getPermissionsScript, 2,
+		redisPermissionGenerationKey, fmt.Sprintf(redisPermissionEntryKey, userID))
+	if err != nil {
+		if !errors.Is(err, redis.ErrNil) {
+			logger.Warn("Permission cache unavailable, loading permissions from the database: %v", err)
+		}
+		return nil, false
+	}
+	value, ok := reply.(string)
+	if !ok {
+		return nil, false
+	}
+
+	var stored storedPermissions
+	if err := json.Unmarshal([]byte(value), &stored); err != nil {
+		logger.Warn("Discarding unreadable cached permissions of user %d: %v", userID, err)
+		return nil, false
+	}
+	if !time.Now().Before(stored.ExpiresAt) {
+		return nil, false
+	}
+
+	entry := &userPermissions{
+		roleIDs:    stored.RoleIDs,
+		superAdmin: stored.SuperAdmin,
+		names:      make(map[string]bool, len(stored.Names)),
+		expiresAt:  stored.ExpiresAt,
+	}
+	for _, name := range stored.Names {
+		entry.names[name] = true
+	}
+	return entry, true
+}
+
+// currentVersion returns the generation and the user's version, read before loading an entry.
+// When Redis is unavailable the zero version is returned, which only matches users that were
+// never invalidated.
+func (c *redisPermissionCache) currentVersion(ctx context.Context, userID uint) permissionVersion {
+	reply, err := c.client.Do(ctx, "EVAL", versionScript, 2,
+		redisPermissionGenerationKey, fmt.Sprintf(redisPermissionVersionKey, userID))
+	if err != nil {
+		return permissionVersion{}
+	}
+	items, ok := reply.([]interface{})
+	if !ok || len(items) != 2 {
+		return permissionVersion{}
+	}
+	generation, _ := items[0].(string)
+	user, _ := items[1].(string)
+
+	var version permissionVersion
+	version.generation, _ = strconv.ParseUint(generation, 10, 64)
+	version.user, _ = strconv.ParseUint(user, 10, 64)
+	return version
+}
+
+// set stores the user's entry unless an invalidation happened since version was read. The entry
+// expires with its earliest role expiry or after ttl, whichever comes first.
+func (c *redisPermissionCache) set(ctx context.Context, userID uint, entry *userPermissions, version permissionVersion) {
+	ttl := time.Until(entry.expiresAt)
+	if ttl > c.ttl {
+		ttl = c.ttl
+	}
+	if ttl < time.Millisecond {
+		return
+	}
+
+	stored := storedPermissions{
+		RoleIDs:    entry.roleIDs,
+		SuperAdmin: entry.superAdmin,
+		Names:      make([]string, 0, len(entry.names)),
+		ExpiresAt:  entry.expiresAt,
+	}
+	for name := range entry.names {
+		stored.Names = append(stored.Names, name)
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return
+	}
+
+	if _, err := c.client.Do(ctx, "EVAL", setPermissionsScript, 3,
+		redisPermissionGenerationKey,
+		fmt.Sprintf(redisPermissionVersionKey, userID),
+		fmt.Sprintf(redisPermissionEntryKey, userID),
+		strconv.FormatUint(version.generation, 10),
+		strconv.FormatUint(version.user, 10),
+		string(data),
+		ttl.Milliseconds(),
+	); err != nil {
+		logger.Warn("Failed to cache permissions of user %d: %v", userID, err)
+	}
+}
+
+// invalidate drops the entries of the given users on every instance
+func (c *redisPermissionCache) invalidate(ctx context.Context, userIDs ...uint) {
+	if len(userIDs) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), invalidationTimeout)
+	defer cancel()
+
+	args := []interface{}{"EVAL", invalidateScript, len(userIDs) * 2}
+	for _, userID := range userIDs {
+		args = append(args,
+			fmt.Sprintf(redisPermissionVersionKey, userID),
+			fmt.Sprintf(redisPermissionEntryKey, userID))
+	}
+	// Versions must outlive any load in progress when they were bumped
+	args = append(args, (2 * c.ttl).Milliseconds())
+
+	if _, err := c.client.Do(ctx, args...); err != nil {
+		logger.Error(fmt.Sprintf("Failed to invalidate cached permissions of users %s, they expire within %s",
+			joinIDs(userIDs), c.ttl), err)
+	}
+}
+
+// invalidateAll drops every entry on every instance
+func (c *redisPermissionCache) invalidateAll(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), invalidationTimeout)
+	defer cancel()
+
+	if _, err := c.client.Do(ctx, "INCR", redisPermissionGenerationKey); err != nil {
+		logger.Error(fmt.Sprintf("Failed to invalidate the permission cache, entries expire within %s", c.ttl), err)
+	}
+}
+
+// Redis keys of the shared organization permission cache. Entries are stored as
+// "<generation>:<organization version>:<json>" and only count while both match, so dropping an
+// organization or everything is a single INCR.
+const (
+	redisOrgPermissionGenerationKey  = "authz:orgperm:generation"
+	redisOrgPermissionOrgVersionKey  = "authz:orgperm:org:%d:version"
+	redisOrgPermissionEntryKey       = "authz:orgperm:org:%d:user:%d"
+	redisOrgPermissionUserVersionKey = "authz:orgperm:org:%d:user:%d:version"
+)
+
+// getOrgPermissionsScript returns the entry's JSON when it belongs to the current generation
+// and organization version
+const getOrgPermissionsScript = `
+local prefix = (redis.call('GET', KEYS[1]) or '0') .. ':' .. (redis.call('GET', KEYS[2]) or '0') .. ':'
+local value = redis.call('GET', KEYS[3])
+if not value or string.sub(value, 1, #prefix) ~= prefix then return false end
+return string.sub(value, #prefix + 1)
+`
+
+// orgVersionScript returns the current generation, organization version and user version
+const orgVersionScript = `
+return {redis.call('GET', KEYS[1]) or '0', redis.call('GET', KEYS[2]) or '0', redis.call('GET', KEYS[3]) or '0'}
+`
+
+// setOrgPermissionsScript stores the entry only when none of the versions changed since they
+// were read
+const setOrgPermissionsScript = `
+for i = 1, 3 do
+	if (redis.call('GET', KEYS[i]) or '0') ~= ARGV[i] then return 0 end
+end
+redis.call('SET', KEYS[4], ARGV[1] .. ':' .. ARGV[2] .. ':' .. ARGV[4], 'PX', ARGV[5])
+return 1
+`
+
+// bumpVersionScript increments a version that must outlive the entries loaded before it changed
+const bumpVersionScript = `
+redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[1])
+return 1
+`
+
+// storedOrgPermissions is the JSON form of orgPermissions
+type storedOrgPermissions struct {
+	Names     []string  `json:"names"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// redisOrgPermissionCache is the orgPermissionStore shared by every instance through Redis
+type redisOrgPermissionCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// newRedisOrgPermissionCache creates a Redis backed cache keeping entries for at most ttl
+func newRedisOrgPermissionCache(client *redis.Client, ttl time.Duration) *redisOrgPermissionCache {
+	return &redisOrgPermissionCache{client: client, ttl: ttl}
+}
+
+// get returns the unexpired entry for the user in the organization
+func (c *redisOrgPermissionCache) get(ctx context.Context, userID, organizationID uint) (*orgPermissions, bool) {
+	reply, err := c.client.Do(ctx, "EVAL", getOrgPermissionsScript, 3,
+		redisOrgPermissionGenerationKey,
+		fmt.Sprintf(redisOrgPermissionOrgVersionKey, organizationID),
+		fmt.Sprintf(redisOrgPermissionEntryKey, organizationID, userID))
+	if err != nil {
+		if !errors.Is(err, redis.ErrNil) {
+			logger.Warn("Permission cache unavailable, loading organization permissions from the database: %v", err)
+		}
+		return nil, false
+	}
+	value, ok := reply.(string)
+	if !ok {
+		return nil, false
+	}
+
+	var stored storedOrgPermissions
+	if err := json.Unmarshal([]byte(value), &stored); err != nil {
+		logger.Warn("Discarding unreadable cached permissions of user %d in organization %d: %v", userID, organizationID, err)
+		return nil, false
+	}
+	if !time.Now().Before(stored.ExpiresAt) {
+		return nil, false
+	}
+
+	entry := &orgPermissions{names: make(map[string]bool, len(stored.Names)), expiresAt: stored.ExpiresAt}
+	for _, name := range stored.Names {
+		entry.names[name] = true
+	}
+	return entry, true
+}
+
+// currentVersion returns the versions covering the user's entry, read before loading it. When
+// Redis is unavailable the zero version is returned, which only matches entries never invalidated.
+func (c *redisOrgPermissionCache) currentVersion(ctx context.Context, userID, organizationID uint) orgPermissionVersion {
+	reply, err := c.client.Do(ctx, "EVAL", orgVersionScript, 3,
+		redisOrgPermissionGenerationKey,
+		fmt.Sprintf(redisOrgPermissionOrgVersionKey, organizationID),
+		fmt.Sprintf(redisOrgPermissionUserVersionKey, organizationID, userID))
+	if err != nil {
+		return orgPermissionVersion{}
+	}
+	items, ok := reply.([]interface{})
+	if !ok || len(items) != 3 {
+		return orgPermissionVersion{}
+	}
+	generation, _ := items[0].(string)
+	organization, _ := items[1].(string)
+	user, _ := items[2].(string)
+
+	var version orgPermissionVersion
+	version.generation, _ = strconv.ParseUint(generation, 10, 64)
+	version.organization, _ = strconv.ParseUint(organization, 10, 64)
+	version.user, _ = strconv.ParseUint(user, 10, 64)
+	return version
+}
+
+// set stores the entry unless an invalidation covering it happened since version was read
+func (c *redisOrgPermissionCache) set(ctx context.Context, userID, organizationID uint, entry *orgPermissions, version orgPermissionVersion) {
+	ttl := time.Until(entry.expiresAt)
+	if ttl > c.ttl {
+		ttl = c.ttl
+	}
+	if ttl < time.Millisecond {
+		return
+	}
+
+	stored := storedOrgPermissions{Names: make([]string, 0, len(entry.names)), ExpiresAt: entry.expiresAt}
+	for name := range entry.names {
+		stored.Names = append(stored.Names, name)
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return
+	}
+
+	if _, err := c.client.Do(ctx, "EVAL", setOrgPermissionsScript, 4,
+		redisOrgPermissionGenerationKey,
+		fmt.Sprintf(redisOrgPermissionOrgVersionKey, organizationID),
+		fmt.Sprintf(redisOrgPermissionUserVersionKey, organizationID, userID),
+		fmt.Sprintf(redisOrgPermissionEntryKey, organizationID, userID),
+		strconv.FormatUint(version.generation, 10),
+		strconv.FormatUint(version.organization, 10),
+		strconv.FormatUint(version.user, 10),
+		string(data),
+		ttl.Milliseconds(),
+	); err != nil {
+		logger.Warn("Failed to cache permissions of user %d in organization %d: %v", userID, organizationID, err)
+	}
+}
+
+// invalidate drops the given users' entries in the organization, or every entry in the
+// organization when no users are given, on every instance
+func (c *redisOrgPermissionCache) invalidate(ctx context.Context, organizationID uint, userIDs ...uint) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), invalidationTimeout)
+	defer cancel()
+
+	// Versions must outlive any load in progress when they were bumped
+	versionTTL := (2 * c.ttl).Milliseconds()
+
+	var err error
+	if len(userIDs) == 0 {
+		_, err = c.client.Do(ctx, "EVAL", bumpVersionScript, 1,
+			fmt.Sprintf(redisOrgPermissionOrgVersionKey, organizationID), versionTTL)
+	} else {
+		args := []interface{}{"EVAL", invalidateScript, len(userIDs) * 2}
+		for _, userID := range userIDs {
+			args = append(args,
+				fmt.Sprintf(redisOrgPermissionUserVersionKey, organizationID, userID),
+				fmt.Sprintf(redisOrgPermissionEntryKey, organizationID, userID))
+		}
+		_, err = c.client.Do(ctx, append(args, versionTTL)...)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to invalidate cached permissions in organization %d, they expire within %s",
+			organizationID, c.ttl), err)
+	}
+}
+
+// invalidateAll drops every entry on every instance
+func (c *redisOrgPermissionCache) invalidateAll(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), invalidationTimeout)
+	defer cancel()
+
+	if _, err := c.client.Do(ctx, "INCR", redisOrgPermissionGenerationKey); err != nil {
+		logger.Error(fmt.Sprintf("Failed to invalidate the organization permission cache, entries expire within %s", c.ttl), err)
+	}
+}
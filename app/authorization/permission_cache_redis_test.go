@@ -0,0 +1,154 @@
+package authorization
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/config"
+	"github.com/llamacto/llama-gin-kit/pkg/redis"
+)
+
+// newTestRedisClient connects to the Redis server at REDIS_TEST_ADDR, skipping the test when unset
+func newTestRedisClient(t *testing.T) *redis.Client {
+	t.Helper()
+	addr := os.Getenv("REDIS_TEST_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_TEST_ADDR not set")
+	}
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatalf("invalid REDIS_TEST_ADDR: %v", err)
+	}
+	port, _ := strconv.Atoi(portStr)
+
+	client := redis.NewClient(config.RedisConfig{Host: host, Port: port})
+	t.Cleanup(func() { client.Close() })
+	if err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("ping redis: %v", err)
+	}
+	return client
+}
+
+// testID returns an ID unlikely to collide with other runs against the same server
+func testID() uint {
+	return uint(1_000_000_000 + rand.Intn(1_000_000_000))
+}
+
+func TestRedisPermissionCacheAcrossInstances(t *testing.T) {
+	tests := []struct {
+		name       string
+		invalidate func(ctx context.Context, other *redisPermissionCache, userID uint)
+		beforeFill bool // Invalidate between reading the version and storing the entry
+		wantCached bool
+	}{
+		{"fill without invalidation", nil, false, true},
+		{"invalidate after fill", func(ctx context.Context, c *redisPermissionCache, userID uint) { c.invalidate(ctx, userID) }, false, false},
+		{"invalidate racing fill", func(ctx context.Context, c *redisPermissionCache, userID uint) { c.invalidate(ctx, userID) }, true, false},
+		{"invalidate all racing fill", func(ctx context.Context, c *redisPermissionCache, userID uint) { c.invalidateAll(ctx) }, true, false},
+		{"invalidate another user racing fill", func(ctx context.Context, c *redisPermissionCache, userID uint) { c.invalidate(ctx, userID+1) }, true, true},
+	}
+
+	client := newTestRedisClient(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			// Two instances of the service, each with its own store over the shared Redis
+			filler := newRedisPermissionCache(client, time.Minute)
+			other := newRedisPermissionCache(client, time.Minute)
+			userID := testID()
+			entry := &userPermissions{
+				roleIDs:   []uint{1},
+				names:     map[string]bool{"dataset.read": true},
+				expiresAt: time.Now().Add(time.Minute),
+			}
+
+			version := filler.currentVersion(ctx, userID)
+			if tt.invalidate != nil && tt.beforeFill {
+				tt.invalidate(ctx, other, userID)
+			}
+			filler.set(ctx, userID, entry, version)
+			if tt.invalidate != nil && !tt.beforeFill {
+				tt.invalidate(ctx, other, userID)
+			}
+
+			for name, store := range map[string]*redisPermissionCache{"filler": filler, "other": other} {
+				got, ok := store.get(ctx, userID)
+				if ok != tt.wantCached {
+					t.Fatalf("%s: cached = %v, want %v", name, ok, tt.wantCached)
+				}
+				if ok && !got.names["dataset.read"] {
+					t.Errorf("%s: cached names = %v", name, got.names)
+				}
+			}
+		})
+	}
+}
+
+func TestRedisOrgPermissionCacheAcrossInstances(t *testing.T) {
+	type invalidation func(ctx context.Context, other *redisOrgPermissionCache, organizationID, userID uint)
+
+	tests := []struct {
+		name       string
+		invalidate invalidation
+		beforeFill bool // Invalidate between reading the version and storing the entry
+		wantCached bool
+	}{
+		{"fill without invalidation", nil, false, true},
+		{"invalidate user after fill", func(ctx context.Context, c *redisOrgPermissionCache, orgID, userID uint) {
+			c.invalidate(ctx, orgID, userID)
+		}, false, false},
+		{"invalidate user racing fill", func(ctx context.Context, c *redisOrgPermissionCache, orgID, userID uint) {
+			c.invalidate(ctx, orgID, userID)
+		}, true, false},
+		{"invalidate organization after fill", func(ctx context.Context, c *redisOrgPermissionCache, orgID, userID uint) {
+			c.invalidate(ctx, orgID)
+		}, false, false},
+		{"invalidate organization racing fill", func(ctx context.Context, c *redisOrgPermissionCache, orgID, userID uint) {
+			c.invalidate(ctx, orgID)
+		}, true, false},
+		{"invalidate all racing fill", func(ctx context.Context, c *redisOrgPermissionCache, orgID, userID uint) {
+			c.invalidateAll(ctx)
+		}, true, false},
+		{"invalidate another organization racing fill", func(ctx context.Context, c *redisOrgPermissionCache, orgID, userID uint) {
+			c.invalidate(ctx, orgID+1)
+		}, true, true},
+	}
+
+	client := newTestRedisClient(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			filler := newRedisOrgPermissionCache(client, time.Minute)
+			other := newRedisOrgPermissionCache(client, time.Minute)
+			organizationID, userID := testID(), testID()
+			entry := &orgPermissions{
+				names:     map[string]bool{"member.invite": true},
+				expiresAt: time.Now().Add(time.Minute),
+			}
+
+			version := filler.currentVersion(ctx, userID, organizationID)
+			if tt.invalidate != nil && tt.beforeFill {
+				tt.invalidate(ctx, other, organizationID, userID)
+			}
+			filler.set(ctx, userID, organizationID, entry, version)
+			if tt.invalidate != nil && !tt.beforeFill {
+				tt.invalidate(ctx, other, organizationID, userID)
+			}
+
+			for name, store := range map[string]*redisOrgPermissionCache{"filler": filler, "other": other} {
+				got, ok := store.get(ctx, userID, organizationID)
+				if ok != tt.wantCached {
+					t.Fatalf("%s: cached = %v, want %v", name, ok, tt.wantCached)
+				}
+				if ok && !got.names["member.invite"] {
+					t.Errorf("%s: cached names = %v", name, got.names)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,489 @@
+package authorization
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/pkg/dbtest"
+)
+
+func TestRolePermissionCache(t *testing.T) {
+	tests := []struct {
+		name        string
+		prepare     func(c *rolePermissionCache)
+		lookup      []uint
+		wantFound   map[uint][]string
+		wantMissing []uint
+	}{
+		{
+			name:        "empty",
+			lookup:      []uint{1},
+			wantFound:   map[uint][]string{},
+			wantMissing: []uint{1},
+		},
+		{
+			name: "stored roles",
+			prepare: func(c *rolePermissionCache) {
+				c.set([]uint{1, 2}, map[uint][]string{1: {"docs.read"}, 2: {"docs.write"}}, c.currentVersion())
+			},
+			lookup:      []uint{1, 2, 3},
+			wantFound:   map[uint][]string{1: {"docs.read"}, 2: {"docs.write"}},
+			wantMissing: []uint{3},
+		},
+		{
+			name: "role granting nothing is cached too",
+			prepare: func(c *rolePermissionCache) {
+				c.set([]uint{1}, map[uint][]string{}, c.currentVersion())
+			},
+			lookup:    []uint{1},
+			wantFound: map[uint][]string{1: nil},
+		},
+		{
+			name: "expired entry",
+			prepare: func(c *rolePermissionCache) {
+				c.entries[1] = &rolePermissions{names: []string{"docs.read"}, expiresAt: time.Now().Add(-time.Second)}
+			},
+			lookup:      []uint{1},
+			wantFound:   map[uint][]string{},
+			wantMissing: []uint{1},
+		},
+		{
+			name: "invalidated role",
+			prepare: func(c *rolePermissionCache) {
+				c.set([]uint{1, 2}, map[uint][]string{1: {"docs.read"}, 2: {"docs.write"}}, c.currentVersion())
+				c.invalidate(1)
+			},
+			lookup:      []uint{1, 2},
+			wantFound:   map[uint][]string{2: {"docs.write"}},
+			wantMissing: []uint{1},
+		},
+		{
+			name: "load racing an invalidation is not stored",
+			prepare: func(c *rolePermissionCache) {
+				version := c.currentVersion()
+				c.invalidate(2)
+				c.set([]uint{1}, map[uint][]string{1: {"docs.read"}}, version)
+			},
+			lookup:      []uint{1},
+			wantFound:   map[uint][]string{},
+			wantMissing: []uint{1},
+		},
+		{
+			name: "disabled cache stores nothing",
+			prepare: func(c *rolePermissionCache) {
+				c.set([]uint{1}, map[uint][]string{1: {"docs.read"}}, c.currentVersion())
+				c.disable()
+				c.set([]uint{2}, map[uint][]string{2: {"docs.write"}}, c.currentVersion())
+			},
+			lookup:      []uint{1, 2},
+			wantFound:   map[uint][]string{},
+			wantMissing: []uint{1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &rolePermissionCache{entries: make(map[uint]*rolePermissions)}
+			if tt.prepare != nil {
+				tt.prepare(c)
+			}
+
+			found, missing := c.get(tt.lookup)
+			if len(found) != len(tt.wantFound) {
+				t.Fatalf("found = %v, want %v", found, tt.wantFound)
+			}
+			for roleID, want := range tt.wantFound {
+				got, ok := found[roleID]
+				if !ok || strings.Join(got, ",") != strings.Join(want, ",") {
+					t.Fatalf("found[%d] = %v, want %v", roleID, got, want)
+				}
+			}
+			if joinIDs(missing) != joinIDs(tt.wantMissing) {
+				t.Fatalf("missing = %v, want %v", missing, tt.wantMissing)
+			}
+		})
+	}
+}
+
+// rolePermissionRepository is an in-memory Repository covering global permission checks and
+// role permission changes, counting how often role permissions are loaded
+type rolePermissionRepository struct {
+	Repository
+	userRoles       map[uint][]uint // Role IDs by user ID
+	rolePermissions map[uint][]string
+	permissionNames map[uint]string // Permission names by ID
+	loads           int             // GetPermissionNamesGroupedByRoleIDs calls
+	warmLoads       int             // ListRolePermissionNames calls
+}
+
+func (r *rolePermissionRepository) GetUserRoles(ctx context.Context, userID uint) ([]*UserRole, error) {
+	var userRoles []*UserRole
+	for _, roleID := range r.userRoles[userID] {
+		userRoles = append(userRoles, &UserRole{UserID: userID, RoleID: roleID, IsActive: true, Role: Role{ID: roleID}})
+	}
+	return userRoles, nil
+}
+
+func (r *rolePermissionRepository) ListActivePolicies(ctx context.Context, subjects []string, action string) ([]*Policy, error) {
+	return nil, nil
+}
+
+func (r *rolePermissionRepository) GetPermissionNamesGroupedByRoleIDs(ctx context.Context, roleIDs []uint) (map[uint][]string, error) {
+	r.loads++
+	names := make(map[uint][]string, len(roleIDs))
+	for _, roleID := range roleIDs {
+		if granted, ok := r.rolePermissions[roleID]; ok {
+			names[roleID] = append([]string(nil), granted...)
+		}
+	}
+	return names, nil
+}
+
+func (r *rolePermissionRepository) ListRolePermissionNames(ctx context.Context) (map[uint][]string, error) {
+	r.warmLoads++
+	names := make(map[uint][]string, len(r.rolePermissions))
+	for roleID, granted := range r.rolePermissions {
+		names[roleID] = append([]string(nil), granted...)
+	}
+	return names, nil
+}
+
+func (r *rolePermissionRepository) GetRoleByID(ctx context.Context, id uint) (*Role, error) {
+	return &Role{ID: id}, nil
+}
+
+func (r *rolePermissionRepository) AssignPermissionsToRole(ctx context.Context, roleID uint, permissionIDs []uint, assignedBy uint) error {
+	for _, id := range permissionIDs {
+		r.rolePermissions[roleID] = append(r.rolePermissions[roleID], r.permissionNames[id])
+	}
+	return nil
+}
+
+func (r *rolePermissionRepository) RemovePermissionsFromRole(ctx context.Context, roleID uint, permissionIDs []uint, removedBy uint) error {
+	removed := make(map[string]bool)
+	for _, id := range permissionIDs {
+		removed[r.permissionNames[id]] = true
+	}
+	var kept []string
+	for _, name := range r.rolePermissions[roleID] {
+		if !removed[name] {
+			kept = append(kept, name)
+		}
+	}
+	r.rolePermissions[roleID] = kept
+	return nil
+}
+
+func (r *rolePermissionRepository) ListRoleHolderIDs(ctx context.Context, roleID uint) ([]uint, error) {
+	var holders []uint
+	for userID, roleIDs := range r.userRoles {
+		for _, id := range roleIDs {
+			if id == roleID {
+				holders = append(holders, userID)
+			}
+		}
+	}
+	return holders, nil
+}
+
+func (r *rolePermissionRepository) GetPermissionsByIDs(ctx context.Context, ids []uint) ([]*Permission, error) {
+	var permissions []*Permission
+	for _, id := range ids {
+		if name, ok := r.permissionNames[id]; ok {
+			permissions = append(permissions, &Permission{ID: id, Name: name, Status: 1})
+		}
+	}
+	return permissions, nil
+}
+
+func (r *rolePermissionRepository) GetPermissionIDsByRoleID(ctx context.Context, roleID uint) ([]uint, error) {
+	var ids []uint
+	for id, name := range r.permissionNames {
+		for _, granted := range r.rolePermissions[roleID] {
+			if granted == name {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids, nil
+}
+
+func (r *rolePermissionRepository) ListPermissionsGrantedByOtherRoles(ctx context.Context, roleID uint, permissionIDs []uint) (map[uint][]uint, error) {
+	return map[uint][]uint{}, nil
+}
+
+// newRoleCacheOnlyService returns a service whose only cache is the role permission cache, so
+// every check resolves the user's roles and only role permissions can come from memory
+func newRoleCacheOnlyService(repo Repository) *service {
+	return &service{
+		repo:      repo,
+		cache:     noPermissionCache{},
+		orgCache:  noOrgPermissionCache{},
+		roleCache: &rolePermissionCache{entries: make(map[uint]*rolePermissions)},
+	}
+}
+
+func TestRolePermissionChangesInvalidateCache(t *testing.T) {
+	const (
+		userID = 1
+		roleID = 7
+		write  = 70
+	)
+	repo := &rolePermissionRepository{
+		userRoles:       map[uint][]uint{userID: {roleID}},
+		rolePermissions: map[uint][]string{roleID: {"docs.read"}},
+		permissionNames: map[uint]string{write: "docs.write"},
+	}
+	s := newRoleCacheOnlyService(repo)
+	ctx := context.Background()
+
+	check := func(step string, want bool, wantLoads int) {
+		t.Helper()
+		allowed, err := s.CheckPermission(ctx, userID, "docs", "write", nil)
+		if err != nil {
+			t.Fatalf("%s: CheckPermission() error = %v", step, err)
+		}
+		if allowed != want || repo.loads != wantLoads {
+			t.Fatalf("%s: allowed = %v after %d loads, want %v after %d", step, allowed, repo.loads, want, wantLoads)
+		}
+	}
+
+	check("first check", false, 1)
+	check("repeated check", false, 1)
+
+	if err := s.AssignPermissionsToRole(ctx, roleID, []uint{write}, 99); err != nil {
+		t.Fatalf("AssignPermissionsToRole() error = %v", err)
+	}
+	check("after assigning", true, 2)
+	check("repeated check after assigning", true, 2)
+
+	if err := s.RemovePermissionsFromRole(ctx, roleID, []uint{write}, 99); err != nil {
+		t.Fatalf("RemovePermissionsFromRole() error = %v", err)
+	}
+	check("after removing", false, 3)
+}
+
+func TestRemovingRolePermissionInvalidatesHolderCaches(t *testing.T) {
+	const (
+		holderID = 1
+		otherID  = 2
+		roleID   = 7
+		write    = 70
+	)
+	repo := &rolePermissionRepository{
+		userRoles:       map[uint][]uint{holderID: {roleID}, otherID: {8}},
+		rolePermissions: map[uint][]string{roleID: {"docs.read", "docs.write"}, 8: {"docs.read"}},
+		permissionNames: map[uint]string{write: "docs.write"},
+	}
+	s := newTestService(repo)
+	ctx := context.Background()
+
+	for _, userID := range []uint{holderID, otherID} {
+		if _, err := s.CheckPermission(ctx, userID, "docs", "read", nil); err != nil {
+			t.Fatalf("CheckPermission(%d) error = %v", userID, err)
+		}
+		if _, cached := s.cache.get(ctx, userID); !cached {
+			t.Fatalf("user %d permissions not cached after a check", userID)
+		}
+	}
+
+	preview, err := s.PreviewRolePermissionChange(ctx, roleID, &RolePermissionChangeRequest{
+		Operation:     PermissionChangeRemove,
+		PermissionIDs: []uint{write},
+	})
+	if err != nil {
+		t.Fatalf("PreviewRolePermissionChange() error = %v", err)
+	}
+	if len(preview.AffectedUsers) != 1 {
+		t.Fatalf("AffectedUsers = %+v, want only the role holder", preview.AffectedUsers)
+	}
+	if got := preview.AffectedUsers[0]; got.UserID != holderID || !got.Cached || strings.Join(got.Permissions, ",") != "docs.write" {
+		t.Fatalf("AffectedUsers[0] = %+v, want user %d losing docs.write from a cached set", got, holderID)
+	}
+
+	if err := s.RemovePermissionsFromRole(ctx, roleID, []uint{write}, 99); err != nil {
+		t.Fatalf("RemovePermissionsFromRole() error = %v", err)
+	}
+	if _, cached := s.cache.get(ctx, holderID); cached {
+		t.Fatal("role holder's cached permissions survived the removal")
+	}
+	if _, cached := s.cache.get(ctx, otherID); !cached {
+		t.Fatal("cached permissions of a user without the role were dropped")
+	}
+
+	loads := repo.loads
+	allowed, err := s.CheckPermission(ctx, holderID, "docs", "write", nil)
+	if err != nil {
+		t.Fatalf("CheckPermission() error = %v", err)
+	}
+	if allowed {
+		t.Fatal("removed permission still granted")
+	}
+	if repo.loads != loads+1 {
+		t.Fatalf("check after the removal loaded role permissions %d times, want a cache miss", repo.loads-loads)
+	}
+}
+
+func TestSharedOrganizationPermissionCache(t *testing.T) {
+	const (
+		userID  = 1
+		otherID = 2
+		orgID   = 5
+	)
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		between     func()
+		wantQueries int
+	}{
+		{name: "second check hits the cache", between: func() {}, wantQueries: 1},
+		{
+			name:        "member role change",
+			between:     func() { InvalidateOrganizationPermissions(ctx, orgID, userID) },
+			wantQueries: 2,
+		},
+		{
+			name:        "organization-wide change",
+			between:     func() { InvalidateOrganizationPermissions(ctx, orgID) },
+			wantQueries: 2,
+		},
+		{
+			name:        "another member's role change",
+			between:     func() { InvalidateOrganizationPermissions(ctx, orgID, otherID) },
+			wantQueries: 1,
+		},
+		{
+			name:        "role change in another organization",
+			between:     func() { InvalidateOrganizationPermissions(ctx, orgID+1, userID) },
+			wantQueries: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sharedOrgPermissionCache.invalidateAll(ctx)
+			t.Cleanup(func() { sharedOrgPermissionCache.invalidateAll(ctx) })
+
+			gormDB, db := dbtest.Open(t)
+			db.Returns("FROM organization_members om", []string{"id"}, []driver.Value{int64(7)})
+			db.Returns("FROM role_permissions rp", []string{"role_id", "name"}, []driver.Value{int64(7), "members.read"})
+			s := newTestService(NewRepository(gormDB))
+			s.orgCache = sharedOrgPermissionCache
+
+			check := func() {
+				t.Helper()
+				allowed, err := s.CheckOrganizationPermission(ctx, userID, orgID, "members.read")
+				if err != nil || !allowed {
+					t.Fatalf("CheckOrganizationPermission() = %v, %v; want true", allowed, err)
+				}
+			}
+			check()
+			tt.between()
+			check()
+
+			queries := 0
+			for _, stmt := range db.Statements() {
+				if strings.HasPrefix(strings.TrimSpace(stmt.SQL), "SELECT") && strings.Contains(stmt.SQL, "FROM organization_members om") {
+					queries++
+				}
+			}
+			if queries != tt.wantQueries {
+				t.Fatalf("organization role SELECTs = %d, want %d", queries, tt.wantQueries)
+			}
+		})
+	}
+}
+
+func TestWarmCache(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		disabled      bool
+		wantWarmLoads int
+		wantLoads     int
+	}{
+		{name: "preloads every role", wantWarmLoads: 1, wantLoads: 0},
+		{name: "disabled cache", disabled: true, wantWarmLoads: 0, wantLoads: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &rolePermissionRepository{
+				userRoles:       map[uint][]uint{1: {7}, 2: {8}},
+				rolePermissions: map[uint][]string{7: {"docs.read"}, 8: {"docs.write"}},
+			}
+			s := newRoleCacheOnlyService(repo)
+			if tt.disabled {
+				s.roleCache.disable()
+			}
+
+			if err := s.WarmCache(ctx); err != nil {
+				t.Fatalf("WarmCache() error = %v", err)
+			}
+			if ok, err := s.CheckPermission(ctx, 1, "docs", "read", nil); err != nil || !ok {
+				t.Fatalf("user 1 docs.read = %v, %v; want true", ok, err)
+			}
+			if ok, err := s.CheckPermission(ctx, 2, "docs", "write", nil); err != nil || !ok {
+				t.Fatalf("user 2 docs.write = %v, %v; want true", ok, err)
+			}
+			if repo.warmLoads != tt.wantWarmLoads || repo.loads != tt.wantLoads {
+				t.Fatalf("warm loads = %d, loads = %d; want %d and %d", repo.warmLoads, repo.loads, tt.wantWarmLoads, tt.wantLoads)
+			}
+		})
+	}
+}
+
+// BenchmarkCheckPermission compares permission checks with no cache, with only the role
+// permission cache, and with the role and user permission caches. Queries go through GORM
+// to an in-process driver, so the figures include statement building but no network.
+func BenchmarkCheckPermission(b *testing.B) {
+	benchmarks := []struct {
+		name       string
+		newService func(Repository) *service
+	}{
+		{
+			name: "uncached",
+			newService: func(repo Repository) *service {
+				s := newRoleCacheOnlyService(repo)
+				s.roleCache.disable()
+				return s
+			},
+		},
+		{name: "role cache", newService: newRoleCacheOnlyService},
+		{name: "role and user cache", newService: newTestService},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			gormDB, db := dbtest.Open(b)
+			db.Returns(`FROM "user_roles"`, []string{"id", "user_id", "role_id", "is_active"},
+				[]driver.Value{int64(1), int64(1), int64(7), true})
+			db.Returns(`FROM "roles"`, []string{"id", "name"}, []driver.Value{int64(7), "editor"})
+			db.Returns("FROM role_permissions rp", []string{"role_id", "name"},
+				[]driver.Value{int64(7), "docs.read"}, []driver.Value{int64(7), "docs.write"})
+
+			s := bm.newService(NewRepository(gormDB))
+			ctx := context.Background()
+			if ok, err := s.CheckPermission(ctx, 1, "docs", "write", nil); err != nil || !ok {
+				b.Fatalf("CheckPermission() = %v, %v; want true", ok, err)
+			}
+
+			warmup := len(db.Statements())
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := s.CheckPermission(ctx, 1, "docs", "write", nil); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.StopTimer()
+			b.ReportMetric(float64(len(db.Statements())-warmup)/float64(b.N), "queries/op")
+		})
+	}
+}
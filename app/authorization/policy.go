@@ -0,0 +1,213 @@
+package authorization
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PolicyEnvironment carries the request attributes that policy conditions are checked against
+type PolicyEnvironment struct {
+	Time time.Time
+	IP   net.IP
+}
+
+// PolicyDecision is the outcome of evaluating policies for a request.
+// Effect is empty when no policy applied, leaving the decision to role-based checks.
+type PolicyDecision struct {
+	Effect   string `json:"effect,omitempty"`
+	PolicyID uint   `json:"policy_id,omitempty"` // Policy that decided the outcome
+}
+
+// Allowed reports whether a policy explicitly allowed the request
+func (d *PolicyDecision) Allowed() bool { return d.Effect == PolicyEffectAllow }
+
+// Denied reports whether a policy explicitly denied the request
+func (d *PolicyDecision) Denied() bool { return d.Effect == PolicyEffectDeny }
+
+// ConditionEvaluator decides whether one condition type holds for a request.
+// params is the JSON value stored under the condition's key in Policy.Conditions.
+type ConditionEvaluator interface {
+	Evaluate(params json.RawMessage, env *PolicyEnvironment) (bool, error)
+}
+
+// ConditionFunc adapts a plain function to ConditionEvaluator
+type ConditionFunc func(params json.RawMessage, env *PolicyEnvironment) (bool, error)
+
+// Evaluate calls f
+func (f ConditionFunc) Evaluate(params json.RawMessage, env *PolicyEnvironment) (bool, error) {
+	return f(params, env)
+}
+
+var (
+	conditionsMu        sync.RWMutex
+	conditionEvaluators = map[string]ConditionEvaluator{
+		"time_window": ConditionFunc(evaluateTimeWindow),
+		"ip_range":    ConditionFunc(evaluateIPRange),
+	}
+)
+
+// RegisterCondition adds or replaces the evaluator for a condition type
+func RegisterCondition(name string, evaluator ConditionEvaluator) {
+	conditionsMu.Lock()
+	defer conditionsMu.Unlock()
+	conditionEvaluators[name] = evaluator
+}
+
+// validateConditions checks that conditions is empty, null or a JSON object
+// whose keys are all registered condition types
+func validateConditions(conditions json.RawMessage) error {
+	trimmed := strings.TrimSpace(string(conditions))
+	if trimmed == "" || trimmed == "null" {
+		return nil
+	}
+
+	var parsed map[string]json.RawMessage
+	if err := json.Unmarshal(conditions, &parsed); err != nil {
+		return ErrInvalidPolicyConditions
+	}
+
+	conditionsMu.RLock()
+	defer conditionsMu.RUnlock()
+	for name := range parsed {
+		if _, ok := conditionEvaluators[name]; !ok {
+			return ErrInvalidPolicyConditions
+		}
+	}
+	return nil
+}
+
+// evaluateConditions reports whether every condition in the JSON object holds.
+// Missing conditions always hold.
+func evaluateConditions(conditions *string, env *PolicyEnvironment) (bool, error) {
+	if conditions == nil || strings.TrimSpace(*conditions) == "" {
+		return true, nil
+	}
+
+	var parsed map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(*conditions), &parsed); err != nil {
+		return false, fmt.Errorf("invalid conditions: %w", err)
+	}
+
+	conditionsMu.RLock()
+	defer conditionsMu.RUnlock()
+	for name, params := range parsed {
+		evaluator, ok := conditionEvaluators[name]
+		if !ok {
+			return false, fmt.Errorf("unknown condition type %q", name)
+		}
+		holds, err := evaluator.Evaluate(params, env)
+		if err != nil {
+			return false, fmt.Errorf("condition %s: %w", name, err)
+		}
+		if !holds {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// timeWindow is the time_window condition, e.g.
+// {"start": "09:00", "end": "18:00", "timezone": "Asia/Shanghai", "days": ["mon", "fri"]}.
+// A window whose end is before its start spans midnight.
+type timeWindow struct {
+	Start    string   `json:"start"`
+	End      string   `json:"end"`
+	Timezone string   `json:"timezone"` // IANA name, defaults to UTC
+	Days     []string `json:"days"`     // Three-letter weekday names, any day when empty
+}
+
+func evaluateTimeWindow(params json.RawMessage, env *PolicyEnvironment) (bool, error) {
+	var window timeWindow
+	if err := json.Unmarshal(params, &window); err != nil {
+		return false, err
+	}
+
+	location := time.UTC
+	if window.Timezone != "" {
+		loc, err := time.LoadLocation(window.Timezone)
+		if err != nil {
+			return false, err
+		}
+		location = loc
+	}
+	now := env.Time.In(location)
+
+	if len(window.Days) > 0 {
+		today := strings.ToLower(now.Weekday().String()[:3])
+		matched := false
+		for _, day := range window.Days {
+			if strings.EqualFold(day, today) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+
+	start, err := minuteOfDay(window.Start)
+	if err != nil {
+		return false, err
+	}
+	end, err := minuteOfDay(window.End)
+	if err != nil {
+		return false, err
+	}
+	current := now.Hour()*60 + now.Minute()
+	if start <= end {
+		return current >= start && current < end, nil
+	}
+	return current >= start || current < end, nil
+}
+
+// minuteOfDay parses an "HH:MM" clock time
+func minuteOfDay(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", clock)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// ipRange is the ip_range condition, e.g. {"cidrs": ["10.0.0.0/8", "192.168.1.5/32"]}
+type ipRange struct {
+	CIDRs []string `json:"cidrs"`
+}
+
+func evaluateIPRange(params json.RawMessage, env *PolicyEnvironment) (bool, error) {
+	var r ipRange
+	if err := json.Unmarshal(params, &r); err != nil {
+		return false, err
+	}
+	if env.IP == nil {
+		return false, nil
+	}
+
+	for _, cidr := range r.CIDRs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return false, err
+		}
+		if network.Contains(env.IP) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// policyObjectMatches reports whether a policy object covers the resource.
+// "*" matches everything and a trailing "*" matches by prefix, e.g. "dataset:*".
+func policyObjectMatches(object, resource string) bool {
+	if object == "*" || object == resource {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(object, "*"); ok {
+		return strings.HasPrefix(resource, prefix)
+	}
+	return false
+}
@@ -0,0 +1,1063 @@
+package authorization
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/pkg/batch"
+	"github.com/llamacto/llama-gin-kit/pkg/softdelete"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository defines the interface for authorization data operations
+type Repository interface {
+	ListRoles(ctx context.Context, query *ListQuery) ([]*Role, int64, error)
+	ListSystemRoles(ctx context.Context) ([]*Role, error)
+	ListPermissions(ctx context.Context, query *ListQuery) ([]*Permission, int64, error)
+	ListSystemPermissions(ctx context.Context) ([]*Permission, error)
+	ListActivePermissions(ctx context.Context) ([]*Permission, error)
+	GetRoleByID(ctx context.Context, id uint) (*Role, error)
+	GetRoleByName(ctx context.Context, name string) (*Role, error)
+	DeleteRole(ctx context.Context, id, deletedBy uint) error
+	CountRoleMembers(ctx context.Context, roleID uint) (int64, error)
+	ListDeletedRoles(ctx context.Context, query *ListQuery) ([]*Role, int64, error)
+	RestoreRole(ctx context.Context, id, restoredBy uint) error
+	CountSystemRoles(ctx context.Context) (int64, error)
+	EnsureRoles(ctx context.Context, roles []*Role) ([]string, error)
+	EnsurePermissions(ctx context.Context, permissions []*Permission) ([]string, error)
+	ListPermissionIDs(ctx context.Context) ([]uint, error)
+	GetPermissionIDsByRoleID(ctx context.Context, roleID uint) ([]uint, error)
+	GetPermissionsByIDs(ctx context.Context, ids []uint) ([]*Permission, error)
+	GetPermissionIDsByNames(ctx context.Context, names []string) (map[string]uint, error)
+	GetPermissionNamesByIDs(ctx context.Context, ids []uint) (map[uint]string, error)
+	ListRoleHolderIDs(ctx context.Context, roleID uint) ([]uint, error)
+	ListPermissionsGrantedByOtherRoles(ctx context.Context, roleID uint, permissionIDs []uint) (map[uint][]uint, error)
+	UserHasRole(ctx context.Context, userID uint, roleName string) (bool, error)
+	UserHasAnyRole(ctx context.Context, userID uint, roleNames []string) (bool, error)
+	GetUserRoles(ctx context.Context, userID uint) ([]*UserRole, error)
+	GetUserOrganizationRoles(ctx context.Context, userID uint, organizationID *uint) ([]*OrganizationRole, error)
+	GetUserTeamRoles(ctx context.Context, userID uint, organizationID *uint) ([]*TeamRole, error)
+	GetPermissionNamesByRoleIDs(ctx context.Context, roleIDs []uint) ([]string, error)
+	GetPermissionNamesGroupedByRoleIDs(ctx context.Context, roleIDs []uint) (map[uint][]string, error)
+	ListRolePermissionNames(ctx context.Context) (map[uint][]string, error)
+	GetUserMaxRoleLevelInOrganization(ctx context.Context, userID, organizationID uint) (int, bool, error)
+	GetUserOrganizationRoleIDs(ctx context.Context, userID, organizationID uint) ([]uint, error)
+	GetTeamNode(ctx context.Context, teamID uint) (*TeamNode, error)
+	GetUserTeamRoleIDs(ctx context.Context, userID uint, teamIDs []uint) ([]uint, error)
+	IsOrganizationMember(ctx context.Context, userID, organizationID uint) (bool, error)
+	OrganizationExists(ctx context.Context, organizationID uint) (bool, error)
+	UserRoleExists(ctx context.Context, userID, roleID uint) (bool, error)
+	AssignRoleToUser(ctx context.Context, userRole *UserRole) error
+	AssignRolesToUser(ctx context.Context, userRoles []*UserRole) error
+	RemoveRoleFromUser(ctx context.Context, userID, roleID, removedBy uint) error
+	AssignPermissionsToRole(ctx context.Context, roleID uint, permissionIDs []uint, assignedBy uint) error
+	RemovePermissionsFromRole(ctx context.Context, roleID uint, permissionIDs []uint, removedBy uint) error
+	SetOrganizationRoleActive(ctx context.Context, organizationID, userID, roleID uint, active bool, actorID uint) error
+	BulkAssignOrganizationRole(ctx context.Context, organizationID, roleID uint, userIDs []uint, assignedBy uint) (*batch.Result, error)
+	SetTeamRoleActive(ctx context.Context, teamID, userID, roleID uint, active bool, actorID uint) error
+	ListAuditLogs(ctx context.Context, query *AuditLogQuery) ([]*AuthAuditLog, int64, error)
+	ListOrganizationAuditLogsAfter(ctx context.Context, organizationID uint, from, to time.Time, afterID uint, limit int) ([]*AuthAuditLog, error)
+	ListActivePolicies(ctx context.Context, subjects []string, action string) ([]*Policy, error)
+	CreatePolicy(ctx context.Context, policy *Policy, createdBy uint) error
+	GetPolicyByID(ctx context.Context, id uint) (*Policy, error)
+	UpdatePolicy(ctx context.Context, policy *Policy, updatedBy uint) error
+	DeletePolicy(ctx context.Context, id, deletedBy uint) error
+	ListPolicies(ctx context.Context, query *PolicyQuery) ([]*Policy, int64, error)
+}
+
+// repositoryImpl implements the Repository interface
+type repositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new authorization repository instance
+func NewRepository(db *gorm.DB) Repository {
+	return &repositoryImpl{db: db}
+}
+
+// ListRoles retrieves roles with filtering and pagination
+func (r *repositoryImpl) ListRoles(ctx context.Context, query *ListQuery) ([]*Role, int64, error) {
+	var roles []*Role
+	var total int64
+
+	db := r.db.WithContext(ctx).Model(&Role{})
+	if !query.IncludeSystem {
+		db = db.Where("is_system = ?", false)
+	}
+	if query.Search != "" {
+		search := "%" + query.Search + "%"
+		db = db.Where("name ILIKE ? OR display_name ILIKE ?", search, search)
+	}
+	if query.Status != nil {
+		db = db.Where("status = ?", *query.Status)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (query.Page - 1) * query.PageSize
+	err := db.Order(orderClause(query)).
+		Offset(offset).
+		Limit(query.PageSize).
+		Find(&roles).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return roles, total, nil
+}
+
+// ListSystemRoles retrieves all built-in system roles
+func (r *repositoryImpl) ListSystemRoles(ctx context.Context) ([]*Role, error) {
+	var roles []*Role
+	err := r.db.WithContext(ctx).Where("is_system = ?", true).Order("level DESC, name ASC").Find(&roles).Error
+	return roles, err
+}
+
+// ListPermissions retrieves permissions with filtering and pagination
+func (r *repositoryImpl) ListPermissions(ctx context.Context, query *ListQuery) ([]*Permission, int64, error) {
+	var permissions []*Permission
+	var total int64
+
+	db := r.db.WithContext(ctx).Model(&Permission{})
+	if !query.IncludeSystem {
+		db = db.Where("is_system = ?", false)
+	}
+	if query.Search != "" {
+		search := "%" + query.Search + "%"
+		db = db.Where("name ILIKE ? OR display_name ILIKE ?", search, search)
+	}
+	if query.Status != nil {
+		db = db.Where("status = ?", *query.Status)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (query.Page - 1) * query.PageSize
+	err := db.Order(orderClause(query)).
+		Offset(offset).
+		Limit(query.PageSize).
+		Find(&permissions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return permissions, total, nil
+}
+
+// ListSystemPermissions retrieves all built-in system permissions
+func (r *repositoryImpl) ListSystemPermissions(ctx context.Context) ([]*Permission, error) {
+	var permissions []*Permission
+	err := r.db.WithContext(ctx).Where("is_system = ?", true).Order("category ASC, name ASC").Find(&permissions).Error
+	return permissions, err
+}
+
+// ListActivePermissions retrieves every active permission ordered by category and name
+func (r *repositoryImpl) ListActivePermissions(ctx context.Context) ([]*Permission, error) {
+	var permissions []*Permission
+	err := r.db.WithContext(ctx).Where("status = ?", 1).Order("category ASC, name ASC").Find(&permissions).Error
+	return permissions, err
+}
+
+// GetRoleByID retrieves a role by its ID
+func (r *repositoryImpl) GetRoleByID(ctx context.Context, id uint) (*Role, error) {
+	var role Role
+	if err := r.db.WithContext(ctx).First(&role, id).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// GetRoleByName retrieves a role by its unique name
+func (r *repositoryImpl) GetRoleByName(ctx context.Context, name string) (*Role, error) {
+	var role Role
+	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&role).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// DeleteRole soft-deletes a role and records an audit log entry. Assignments are kept so
+// a restored role takes effect again; deleted roles are ignored by every permission check.
+func (r *repositoryImpl) DeleteRole(ctx context.Context, id, deletedBy uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&Role{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return writeAuditLog(tx, deletedBy, AuditActionRoleDelete, "role", id, nil)
+	})
+}
+
+// CountRoleMembers counts organization members holding a role, excluding removed members
+func (r *repositoryImpl) CountRoleMembers(ctx context.Context, roleID uint) (int64, error) {
+	return softdelete.Count(r.db.WithContext(ctx), "organization_members", "role_id = ?", roleID)
+}
+
+// ListDeletedRoles retrieves soft-deleted roles with filtering and pagination
+func (r *repositoryImpl) ListDeletedRoles(ctx context.Context, query *ListQuery) ([]*Role, int64, error) {
+	var roles []*Role
+	var total int64
+
+	db := r.db.WithContext(ctx).Unscoped().Model(&Role{}).Where("deleted_at IS NOT NULL")
+	if query.Search != "" {
+		search := "%" + query.Search + "%"
+		db = db.Where("name ILIKE ? OR display_name ILIKE ?", search, search)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (query.Page - 1) * query.PageSize
+	err := db.Order("deleted_at DESC").
+		Offset(offset).
+		Limit(query.PageSize).
+		Find(&roles).Error
+
+	return roles, total, err
+}
+
+// RestoreRole clears a role's soft delete and records an audit log entry
+func (r *repositoryImpl) RestoreRole(ctx context.Context, id, restoredBy uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Model(&Role{}).
+			Where("id = ? AND deleted_at IS NOT NULL", id).
+			Update("deleted_at", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return writeAuditLog(tx, restoredBy, AuditActionRoleRestore, "role", id, nil)
+	})
+}
+
+// CountSystemRoles counts the built-in system roles
+func (r *repositoryImpl) CountSystemRoles(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&Role{}).Where("is_system = ?", true).Count(&count).Error
+	return count, err
+}
+
+// EnsureRoles creates the roles whose names do not exist yet and returns the names it created.
+// The unique name index also covers soft-deleted rows, so a deleted role with the same name
+// is restored instead and reported as created. Active roles are left untouched.
+func (r *repositoryImpl) EnsureRoles(ctx context.Context, roles []*Role) ([]string, error) {
+	var created []string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, role := range roles {
+			restored := tx.Unscoped().Model(&Role{}).
+				Where("name = ? AND deleted_at IS NOT NULL", role.Name).
+				Update("deleted_at", nil)
+			if restored.Error != nil {
+				return restored.Error
+			}
+			if restored.RowsAffected > 0 {
+				created = append(created, role.Name)
+				continue
+			}
+
+			result := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}).Create(role)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected > 0 {
+				created = append(created, role.Name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// EnsurePermissions creates the permissions whose names do not exist yet and returns the names it created
+func (r *repositoryImpl) EnsurePermissions(ctx context.Context, permissions []*Permission) ([]string, error) {
+	var created []string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, permission := range permissions {
+			result := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}).Create(permission)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected > 0 {
+				created = append(created, permission.Name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// ListPermissionIDs returns the IDs of all permissions
+func (r *repositoryImpl) ListPermissionIDs(ctx context.Context) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).Model(&Permission{}).Order("id ASC").Pluck("id", &ids).Error
+	return ids, err
+}
+
+// GetPermissionIDsByRoleID returns the IDs of the permissions linked to a role
+func (r *repositoryImpl) GetPermissionIDsByRoleID(ctx context.Context, roleID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).Model(&RolePermission{}).Where("role_id = ?", roleID).Pluck("permission_id", &ids).Error
+	return ids, err
+}
+
+// GetPermissionsByIDs retrieves the permissions with the given IDs, ignoring unknown IDs
+func (r *repositoryImpl) GetPermissionsByIDs(ctx context.Context, ids []uint) ([]*Permission, error) {
+	var permissions []*Permission
+	if len(ids) == 0 {
+		return permissions, nil
+	}
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Order("name ASC").Find(&permissions).Error
+	return permissions, err
+}
+
+// GetPermissionIDsByNames maps the given permission names to their IDs in one query.
+// Unknown names are left out of the map.
+func (r *repositoryImpl) GetPermissionIDsByNames(ctx context.Context, names []string) (map[string]uint, error) {
+	resolved := make(map[string]uint, len(names))
+	if len(names) == 0 {
+		return resolved, nil
+	}
+	var rows []struct {
+		ID   uint
+		Name string
+	}
+	if err := r.db.WithContext(ctx).Model(&Permission{}).Select("id, name").Where("name IN ?", names).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		resolved[row.Name] = row.ID
+	}
+	return resolved, nil
+}
+
+// GetPermissionNamesByIDs maps the given permission IDs to their names in one query.
+// Unknown IDs are left out of the map.
+func (r *repositoryImpl) GetPermissionNamesByIDs(ctx context.Context, ids []uint) (map[uint]string, error) {
+	resolved := make(map[uint]string, len(ids))
+	if len(ids) == 0 {
+		return resolved, nil
+	}
+	var rows []struct {
+		ID   uint
+		Name string
+	}
+	if err := r.db.WithContext(ctx).Model(&Permission{}).Select("id, name").Where("id IN ?", ids).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		resolved[row.ID] = row.Name
+	}
+	return resolved, nil
+}
+
+// ListRoleHolderIDs returns the users holding the role as an active, unexpired global role
+func (r *repositoryImpl) ListRoleHolderIDs(ctx context.Context, roleID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).Model(&UserRole{}).
+		Where("role_id = ? AND is_active = ?", roleID, true).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Distinct("user_id").
+		Order("user_id ASC").
+		Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+// ListPermissionsGrantedByOtherRoles returns, for each holder of the role, which of the
+// permissions they also receive through another active, unexpired global role
+func (r *repositoryImpl) ListPermissionsGrantedByOtherRoles(ctx context.Context, roleID uint, permissionIDs []uint) (map[uint][]uint, error) {
+	granted := make(map[uint][]uint)
+	if len(permissionIDs) == 0 {
+		return granted, nil
+	}
+
+	var rows []struct {
+		UserID       uint
+		PermissionID uint
+	}
+	now := time.Now()
+	err := r.db.WithContext(ctx).Table("user_roles holder").
+		Select("DISTINCT holder.user_id, rp.permission_id").
+		Joins("JOIN user_roles other ON other.user_id = holder.user_id AND other.role_id <> holder.role_id"+
+			" AND other.is_active = ? AND other.deleted_at IS NULL AND (other.expires_at IS NULL OR other.expires_at > ?)", true, now).
+		Joins("JOIN roles ON roles.id = other.role_id AND roles.deleted_at IS NULL").
+		Joins("JOIN role_permissions rp ON rp.role_id = other.role_id").
+		Where("holder.role_id = ? AND holder.is_active = ? AND holder.deleted_at IS NULL", roleID, true).
+		Where("holder.expires_at IS NULL OR holder.expires_at > ?", now).
+		Where("rp.permission_id IN ?", permissionIDs).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		granted[row.UserID] = append(granted[row.UserID], row.PermissionID)
+	}
+	return granted, nil
+}
+
+// UserHasRole checks whether a user holds an active, unexpired global role
+func (r *repositoryImpl) UserHasRole(ctx context.Context, userID uint, roleName string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Table("user_roles ur").
+		Joins("JOIN roles ro ON ro.id = ur.role_id AND ro.deleted_at IS NULL").
+		Scopes(softdelete.NotDeleted("ur")).
+		Where("ur.user_id = ? AND ro.name = ? AND ur.is_active = ?", userID, roleName, true).
+		Where("ur.expires_at IS NULL OR ur.expires_at > ?", time.Now()).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// UserHasAnyRole checks in one query whether the user holds any of the active, unexpired global roles
+func (r *repositoryImpl) UserHasAnyRole(ctx context.Context, userID uint, roleNames []string) (bool, error) {
+	if len(roleNames) == 0 {
+		return false, nil
+	}
+	var count int64
+	err := r.db.WithContext(ctx).Table("user_roles ur").
+		Joins("JOIN roles ro ON ro.id = ur.role_id AND ro.deleted_at IS NULL").
+		Scopes(softdelete.NotDeleted("ur")).
+		Where("ur.user_id = ? AND ro.name IN ? AND ur.is_active = ?", userID, roleNames, true).
+		Where("ur.expires_at IS NULL OR ur.expires_at > ?", time.Now()).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetUserRoles retrieves a user's active, unexpired global role assignments
+func (r *repositoryImpl) GetUserRoles(ctx context.Context, userID uint) ([]*UserRole, error) {
+	var userRoles []*UserRole
+	err := r.db.WithContext(ctx).Preload("Role").
+		Joins("JOIN roles ON roles.id = user_roles.role_id AND roles.deleted_at IS NULL").
+		Where("user_roles.user_id = ? AND user_roles.is_active = ?", userID, true).
+		Where("user_roles.expires_at IS NULL OR user_roles.expires_at > ?", time.Now()).
+		Order("user_roles.created_at ASC").
+		Find(&userRoles).Error
+	return userRoles, err
+}
+
+// GetUserOrganizationRoles retrieves a user's active organization role assignments,
+// optionally limited to one organization
+func (r *repositoryImpl) GetUserOrganizationRoles(ctx context.Context, userID uint, organizationID *uint) ([]*OrganizationRole, error) {
+	var orgRoles []*OrganizationRole
+	db := r.db.WithContext(ctx).Preload("Role").
+		Joins("JOIN roles ON roles.id = organization_roles.role_id AND roles.deleted_at IS NULL").
+		Where("organization_roles.user_id = ? AND organization_roles.is_active = ?", userID, true)
+	if organizationID != nil {
+		db = db.Where("organization_roles.organization_id = ?", *organizationID)
+	}
+	err := db.Order("organization_roles.organization_id ASC, organization_roles.created_at ASC").Find(&orgRoles).Error
+	return orgRoles, err
+}
+
+// GetUserTeamRoles retrieves a user's active team role assignments,
+// optionally limited to teams in one organization
+func (r *repositoryImpl) GetUserTeamRoles(ctx context.Context, userID uint, organizationID *uint) ([]*TeamRole, error) {
+	var teamRoles []*TeamRole
+	db := r.db.WithContext(ctx).Preload("Role").
+		Joins("JOIN roles ON roles.id = team_roles.role_id AND roles.deleted_at IS NULL").
+		Where("team_roles.user_id = ? AND team_roles.is_active = ?", userID, true)
+	if organizationID != nil {
+		db = db.Joins("JOIN teams ON teams.id = team_roles.team_id AND teams.deleted_at IS NULL").
+			Where("teams.organization_id = ?", *organizationID)
+	}
+	err := db.Order("team_roles.team_id ASC, team_roles.created_at ASC").Find(&teamRoles).Error
+	return teamRoles, err
+}
+
+// GetPermissionNamesByRoleIDs retrieves the distinct active permission names granted by the roles
+func (r *repositoryImpl) GetPermissionNamesByRoleIDs(ctx context.Context, roleIDs []uint) ([]string, error) {
+	var names []string
+	if len(roleIDs) == 0 {
+		return names, nil
+	}
+	err := r.db.WithContext(ctx).Model(&Permission{}).
+		Distinct("permissions.name").
+		Joins("JOIN role_permissions rp ON rp.permission_id = permissions.id").
+		Joins("JOIN roles ON roles.id = rp.role_id AND roles.deleted_at IS NULL").
+		Where("rp.role_id IN ? AND permissions.status = ?", roleIDs, 1).
+		Order("permissions.name ASC").
+		Pluck("permissions.name", &names).Error
+	return names, err
+}
+
+// GetPermissionNamesGroupedByRoleIDs retrieves the active permission names each role grants.
+// Deleted roles and roles granting nothing are absent from the result.
+func (r *repositoryImpl) GetPermissionNamesGroupedByRoleIDs(ctx context.Context, roleIDs []uint) (map[uint][]string, error) {
+	if len(roleIDs) == 0 {
+		return map[uint][]string{}, nil
+	}
+	return r.rolePermissionNames(r.db.WithContext(ctx).Where("rp.role_id IN ?", roleIDs))
+}
+
+// ListRolePermissionNames retrieves the active permission names granted by every role that
+// is not deleted, keyed by role ID
+func (r *repositoryImpl) ListRolePermissionNames(ctx context.Context) (map[uint][]string, error) {
+	return r.rolePermissionNames(r.db.WithContext(ctx))
+}
+
+// rolePermissionNames groups the active permission names of the role links db selects by role
+func (r *repositoryImpl) rolePermissionNames(db *gorm.DB) (map[uint][]string, error) {
+	var rows []struct {
+		RoleID uint
+		Name   string
+	}
+	err := db.Table("role_permissions rp").
+		Select("rp.role_id, permissions.name").
+		Joins("JOIN permissions ON permissions.id = rp.permission_id AND permissions.deleted_at IS NULL").
+		Joins("JOIN roles ON roles.id = rp.role_id AND roles.deleted_at IS NULL").
+		Where("permissions.status = ?", 1).
+		Order("rp.role_id ASC, permissions.name ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[uint][]string)
+	for _, row := range rows {
+		names[row.RoleID] = append(names[row.RoleID], row.Name)
+	}
+	return names, nil
+}
+
+// GetUserMaxRoleLevelInOrganization returns the highest role level a user holds in an
+// organization, through either their membership role or an active organization role.
+// The boolean is false when the user holds no role in the organization.
+func (r *repositoryImpl) GetUserMaxRoleLevelInOrganization(ctx context.Context, userID, organizationID uint) (int, bool, error) {
+	var levels []int
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT ro.level FROM organization_members om
+		JOIN roles ro ON ro.id = om.role_id AND ro.deleted_at IS NULL
+		WHERE om.user_id = ? AND om.organization_id = ? AND om.status = 1 AND om.deleted_at IS NULL
+		UNION ALL
+		SELECT ro.level FROM organization_roles orr
+		JOIN roles ro ON ro.id = orr.role_id AND ro.deleted_at IS NULL
+		WHERE orr.user_id = ? AND orr.organization_id = ? AND orr.is_active = ? AND orr.deleted_at IS NULL
+	`, userID, organizationID, userID, organizationID, true).Scan(&levels).Error
+	if err != nil {
+		return 0, false, err
+	}
+	if len(levels) == 0 {
+		return 0, false, nil
+	}
+
+	max := levels[0]
+	for _, level := range levels[1:] {
+		if level > max {
+			max = level
+		}
+	}
+	return max, true, nil
+}
+
+// GetUserOrganizationRoleIDs returns the IDs of the roles a user holds in an organization,
+// through either their membership role or an active organization role
+func (r *repositoryImpl) GetUserOrganizationRoleIDs(ctx context.Context, userID, organizationID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT ro.id FROM organization_members om
+		JOIN roles ro ON ro.id = om.role_id AND ro.deleted_at IS NULL
+		WHERE om.user_id = ? AND om.organization_id = ? AND om.status = 1 AND om.deleted_at IS NULL
+		UNION
+		SELECT ro.id FROM organization_roles orr
+		JOIN roles ro ON ro.id = orr.role_id AND ro.deleted_at IS NULL
+		WHERE orr.user_id = ? AND orr.organization_id = ? AND orr.is_active = ? AND orr.deleted_at IS NULL
+	`, userID, organizationID, userID, organizationID, true).Scan(&ids).Error
+	return ids, err
+}
+
+// GetTeamNode returns the team's parent and whether its organization lets team roles
+// apply to descendant teams. It returns gorm.ErrRecordNotFound for a missing team.
+func (r *repositoryImpl) GetTeamNode(ctx context.Context, teamID uint) (*TeamNode, error) {
+	var node TeamNode
+	result := r.db.WithContext(ctx).Raw(`
+		SELECT t.id, t.parent_team_id, o.inherit_team_permissions FROM teams t
+		JOIN organizations o ON o.id = t.organization_id AND o.deleted_at IS NULL
+		WHERE t.id = ? AND t.deleted_at IS NULL
+	`, teamID).Scan(&node)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &node, nil
+}
+
+// GetUserTeamRoleIDs returns the IDs of the roles a user actively holds on any of the teams
+func (r *repositoryImpl) GetUserTeamRoleIDs(ctx context.Context, userID uint, teamIDs []uint) ([]uint, error) {
+	var ids []uint
+	if len(teamIDs) == 0 {
+		return ids, nil
+	}
+	err := r.db.WithContext(ctx).Model(&TeamRole{}).
+		Distinct("team_roles.role_id").
+		Joins("JOIN roles ro ON ro.id = team_roles.role_id AND ro.deleted_at IS NULL").
+		Where("team_roles.user_id = ? AND team_roles.team_id IN ? AND team_roles.is_active = ?", userID, teamIDs, true).
+		Pluck("team_roles.role_id", &ids).Error
+	return ids, err
+}
+
+// IsOrganizationMember reports whether the user is an active member of the organization
+func (r *repositoryImpl) IsOrganizationMember(ctx context.Context, userID, organizationID uint) (bool, error) {
+	return softdelete.Exists(r.db.WithContext(ctx), "organization_members", "user_id = ? AND organization_id = ? AND status = 1", userID, organizationID)
+}
+
+// OrganizationExists reports whether the organization exists and is not deleted
+func (r *repositoryImpl) OrganizationExists(ctx context.Context, organizationID uint) (bool, error) {
+	return softdelete.Exists(r.db.WithContext(ctx), "organizations", "id = ?", organizationID)
+}
+
+// AssignRoleToUser creates a user role assignment and its audit log entry
+func (r *repositoryImpl) AssignRoleToUser(ctx context.Context, userRole *UserRole) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(userRole).Error; err != nil {
+			return err
+		}
+		return writeAuditLog(tx, userRole.AssignedBy, AuditActionRoleAssign, "user", userRole.UserID, map[string]interface{}{
+			"role_id":    userRole.RoleID,
+			"expires_at": userRole.ExpiresAt,
+		})
+	})
+}
+
+// UserRoleExists checks whether a user already has an assignment for the role
+func (r *repositoryImpl) UserRoleExists(ctx context.Context, userID, roleID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&UserRole{}).
+		Where("user_id = ? AND role_id = ?", userID, roleID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// AssignRolesToUser creates several user role assignments in one transaction,
+// writing an audit log entry for each. Either all assignments are stored or none are.
+func (r *repositoryImpl) AssignRolesToUser(ctx context.Context, userRoles []*UserRole) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, userRole := range userRoles {
+			if err := tx.Create(userRole).Error; err != nil {
+				return err
+			}
+			err := writeAuditLog(tx, userRole.AssignedBy, AuditActionRoleAssign, "user", userRole.UserID, map[string]interface{}{
+				"role_id":    userRole.RoleID,
+				"expires_at": userRole.ExpiresAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RemoveRoleFromUser deletes a user role assignment and records an audit log entry
+func (r *repositoryImpl) RemoveRoleFromUser(ctx context.Context, userID, roleID, removedBy uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("user_id = ? AND role_id = ?", userID, roleID).Delete(&UserRole{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return writeAuditLog(tx, removedBy, AuditActionRoleRemove, "user", userID, map[string]interface{}{
+			"role_id": roleID,
+		})
+	})
+}
+
+// AssignPermissionsToRole links permissions to a role and records an audit log entry
+func (r *repositoryImpl) AssignPermissionsToRole(ctx context.Context, roleID uint, permissionIDs []uint, assignedBy uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existingIDs []uint
+		if err := tx.Model(&Permission{}).Where("id IN ?", permissionIDs).Pluck("id", &existingIDs).Error; err != nil {
+			return err
+		}
+		if missing := missingIDs(permissionIDs, existingIDs); len(missing) > 0 {
+			return &PermissionsNotFoundError{IDs: missing}
+		}
+
+		now := time.Now()
+		rows := make([]RolePermission, 0, len(permissionIDs))
+		for _, permissionID := range permissionIDs {
+			rows = append(rows, RolePermission{RoleID: roleID, PermissionID: permissionID, CreatedAt: now})
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error; err != nil {
+			return err
+		}
+
+		return writeAuditLog(tx, assignedBy, AuditActionPermissionAssign, "role", roleID, map[string]interface{}{
+			"permission_ids": permissionIDs,
+		})
+	})
+}
+
+// RemovePermissionsFromRole unlinks permissions from a role and records an audit log entry
+func (r *repositoryImpl) RemovePermissionsFromRole(ctx context.Context, roleID uint, permissionIDs []uint, removedBy uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("role_id = ? AND permission_id IN ?", roleID, permissionIDs).
+			Delete(&RolePermission{}).Error
+		if err != nil {
+			return err
+		}
+		return writeAuditLog(tx, removedBy, AuditActionPermissionRemove, "role", roleID, map[string]interface{}{
+			"permission_ids": permissionIDs,
+		})
+	})
+}
+
+// SetOrganizationRoleActive flips is_active on an organization role assignment,
+// keeping the row so the assignment history is preserved
+func (r *repositoryImpl) SetOrganizationRoleActive(ctx context.Context, organizationID, userID, roleID uint, active bool, actorID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&OrganizationRole{}).
+			Where("organization_id = ? AND user_id = ? AND role_id = ?", organizationID, userID, roleID).
+			Update("is_active", active)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return writeOrganizationAuditLog(tx, organizationID, actorID, activationAction(active), "user", userID, map[string]interface{}{
+			"organization_id": organizationID,
+			"role_id":         roleID,
+		})
+	})
+}
+
+// BulkAssignOrganizationRole inserts organization role assignments for the users in one transaction.
+// Every user must exist or nothing is written. Users who already hold the role are skipped and
+// non-members are reported as failed; the rest are inserted in batches with a single audit log entry.
+func (r *repositoryImpl) BulkAssignOrganizationRole(ctx context.Context, organizationID, roleID uint, userIDs []uint, assignedBy uint) (*batch.Result, error) {
+	result := batch.New(len(userIDs))
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existingIDs []uint
+		if err := tx.Table("users").Where("id IN ? AND deleted_at IS NULL", userIDs).Pluck("id", &existingIDs).Error; err != nil {
+			return err
+		}
+		if missing := missingIDs(userIDs, existingIDs); len(missing) > 0 {
+			return &UsersNotFoundError{IDs: missing}
+		}
+
+		var assignedIDs []uint
+		err := tx.Model(&OrganizationRole{}).
+			Where("organization_id = ? AND role_id = ? AND user_id IN ?", organizationID, roleID, userIDs).
+			Pluck("user_id", &assignedIDs).Error
+		if err != nil {
+			return err
+		}
+		var memberIDs []uint
+		err = tx.Table("organization_members").
+			Where("organization_id = ? AND user_id IN ? AND status = 1 AND deleted_at IS NULL", organizationID, userIDs).
+			Pluck("user_id", &memberIDs).Error
+		if err != nil {
+			return err
+		}
+
+		assigned := make(map[uint]bool, len(assignedIDs))
+		for _, id := range assignedIDs {
+			assigned[id] = true
+		}
+		members := make(map[uint]bool, len(memberIDs))
+		for _, id := range memberIDs {
+			members[id] = true
+		}
+
+		rows := make([]OrganizationRole, 0, len(userIDs))
+		newIDs := make([]uint, 0, len(userIDs))
+		for _, userID := range userIDs {
+			switch {
+			case assigned[userID]:
+				result.Skip(userID, AssignFailureAlreadyAssigned, "user already has the role")
+			case !members[userID]:
+				result.Fail(userID, AssignFailureNotMember, "user is not an active member of the organization")
+			default:
+				rows = append(rows, OrganizationRole{
+					UserID:         userID,
+					OrganizationID: organizationID,
+					RoleID:         roleID,
+					AssignedBy:     assignedBy,
+					IsActive:       true,
+				})
+				newIDs = append(newIDs, userID)
+				result.Succeed(userID)
+			}
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		if err := tx.CreateInBatches(&rows, 100).Error; err != nil {
+			return err
+		}
+
+		return writeOrganizationAuditLog(tx, organizationID, assignedBy, AuditActionRoleAssign, "organization", organizationID, map[string]interface{}{
+			"role_id":  roleID,
+			"user_ids": newIDs,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SetTeamRoleActive flips is_active on a team role assignment,
+// keeping the row so the assignment history is preserved
+func (r *repositoryImpl) SetTeamRoleActive(ctx context.Context, teamID, userID, roleID uint, active bool, actorID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&TeamRole{}).
+			Where("team_id = ? AND user_id = ? AND role_id = ?", teamID, userID, roleID).
+			Update("is_active", active)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		var organizationID uint
+		if err := tx.Table("teams").Select("organization_id").Where("id = ?", teamID).Scan(&organizationID).Error; err != nil {
+			return err
+		}
+		return writeOrganizationAuditLog(tx, organizationID, actorID, activationAction(active), "user", userID, map[string]interface{}{
+			"team_id": teamID,
+			"role_id": roleID,
+		})
+	})
+}
+
+// ListActivePolicies retrieves active policies for any of the subjects that cover the action,
+// highest priority first. Object matching is left to the caller because it supports wildcards.
+func (r *repositoryImpl) ListActivePolicies(ctx context.Context, subjects []string, action string) ([]*Policy, error) {
+	var policies []*Policy
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND subject IN ? AND action IN ?", 1, subjects, []string{action, "*"}).
+		Order("priority DESC, id ASC").
+		Find(&policies).Error
+	return policies, err
+}
+
+// CreatePolicy creates a policy and records an audit log entry
+func (r *repositoryImpl) CreatePolicy(ctx context.Context, policy *Policy, createdBy uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// GORM leaves a zero status to the column default, so inactive policies are set afterwards
+		status := policy.Status
+		if err := tx.Create(policy).Error; err != nil {
+			return err
+		}
+		if policy.Status != status {
+			if err := tx.Model(policy).Update("status", status).Error; err != nil {
+				return err
+			}
+		}
+		return writeAuditLog(tx, createdBy, AuditActionPolicyCreate, "policy", policy.ID, policy)
+	})
+}
+
+// GetPolicyByID retrieves a policy by its ID
+func (r *repositoryImpl) GetPolicyByID(ctx context.Context, id uint) (*Policy, error) {
+	var policy Policy
+	if err := r.db.WithContext(ctx).First(&policy, id).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// UpdatePolicy saves every field of the policy and records an audit log entry
+func (r *repositoryImpl) UpdatePolicy(ctx context.Context, policy *Policy, updatedBy uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(policy).Error; err != nil {
+			return err
+		}
+		return writeAuditLog(tx, updatedBy, AuditActionPolicyUpdate, "policy", policy.ID, policy)
+	})
+}
+
+// DeletePolicy soft-deletes a policy and records an audit log entry
+func (r *repositoryImpl) DeletePolicy(ctx context.Context, id, deletedBy uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&Policy{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return writeAuditLog(tx, deletedBy, AuditActionPolicyDelete, "policy", id, nil)
+	})
+}
+
+// ListPolicies retrieves policies with filtering and pagination, highest priority first
+func (r *repositoryImpl) ListPolicies(ctx context.Context, query *PolicyQuery) ([]*Policy, int64, error) {
+	var policies []*Policy
+	var total int64
+
+	db := r.db.WithContext(ctx).Model(&Policy{})
+	if query.Resource != "" {
+		db = db.Where("object = ?", query.Resource)
+	}
+	if query.Effect != "" {
+		db = db.Where("effect = ?", query.Effect)
+	}
+	if query.Subject != "" {
+		db = db.Where("subject = ?", query.Subject)
+	}
+	if query.Status != nil {
+		db = db.Where("status = ?", *query.Status)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (query.Page - 1) * query.PageSize
+	err := db.Order("priority DESC, id ASC").
+		Offset(offset).
+		Limit(query.PageSize).
+		Find(&policies).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return policies, total, nil
+}
+
+// ListAuditLogs retrieves audit logs matching the query filters, newest first.
+// IDs are assigned in insertion order, so ordering by ID keeps pages stable as new entries arrive.
+func (r *repositoryImpl) ListAuditLogs(ctx context.Context, query *AuditLogQuery) ([]*AuthAuditLog, int64, error) {
+	var logs []*AuthAuditLog
+	var total int64
+
+	db := r.db.WithContext(ctx).Model(&AuthAuditLog{})
+	if query.ActorID != nil {
+		db = db.Where("actor_id = ?", *query.ActorID)
+	}
+	if query.TargetType != "" {
+		db = db.Where("target_type = ?", query.TargetType)
+	}
+	if query.TargetID != nil {
+		db = db.Where("target_id = ?", *query.TargetID)
+	}
+	if query.Action != "" {
+		db = db.Where("action = ?", query.Action)
+	}
+	if query.From != nil {
+		db = db.Where("created_at >= ?", *query.From)
+	}
+	if query.To != nil {
+		db = db.Where("created_at <= ?", *query.To)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if query.Cursor > 0 {
+		db = db.Where("id < ?", query.Cursor)
+	} else {
+		db = db.Offset((query.Page - 1) * query.PageSize)
+	}
+
+	err := db.Order("id DESC").
+		Limit(query.PageSize).
+		Find(&logs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return logs, total, nil
+}
+
+// ListOrganizationAuditLogsAfter retrieves up to limit audit logs of an organization created
+// within [from, to], oldest first, continuing after the entry with ID afterID (0 to start)
+func (r *repositoryImpl) ListOrganizationAuditLogsAfter(ctx context.Context, organizationID uint, from, to time.Time, afterID uint, limit int) ([]*AuthAuditLog, error) {
+	var logs []*AuthAuditLog
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND created_at >= ? AND created_at <= ? AND id > ?", organizationID, from, to, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&logs).Error
+	return logs, err
+}
+
+// writeAuditLog persists a global audit log entry using the caller's transaction.
+// Errors are returned so the surrounding change is rolled back rather than left unlogged.
+func writeAuditLog(tx *gorm.DB, actorID uint, action, targetType string, targetID uint, diff interface{}) error {
+	return writeOrganizationAuditLog(tx, 0, actorID, action, targetType, targetID, diff)
+}
+
+// writeOrganizationAuditLog persists an audit log entry belonging to an organization, so it
+// is included in that organization's audit export. An organizationID of 0 records a global entry.
+func writeOrganizationAuditLog(tx *gorm.DB, organizationID, actorID uint, action, targetType string, targetID uint, diff interface{}) error {
+	data, err := json.Marshal(diff)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit diff: %w", err)
+	}
+
+	log := &AuthAuditLog{
+		ActorID:    actorID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Diff:       string(data),
+	}
+	if organizationID != 0 {
+		log.OrganizationID = &organizationID
+	}
+	if err := tx.Create(log).Error; err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// activationAction returns the audit action for an is_active change
+func activationAction(active bool) string {
+	if active {
+		return AuditActionRoleReactivate
+	}
+	return AuditActionRoleDeactivate
+}
+
+// missingIDs returns the requested IDs absent from found, in request order without duplicates
+func missingIDs(requested, found []uint) []uint {
+	seen := make(map[uint]bool, len(found)+len(requested))
+	for _, id := range found {
+		seen[id] = true
+	}
+
+	var missing []uint
+	for _, id := range requested {
+		if !seen[id] {
+			missing = append(missing, id)
+			seen[id] = true
+		}
+	}
+	return missing
+}
+
+// orderClause builds a safe ORDER BY clause from the list query
+func orderClause(query *ListQuery) string {
+	return listSort.Clause("", query.OrderBy, query.Order)
+}
@@ -0,0 +1,475 @@
+package authorization
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/pkg/dbtest"
+	"github.com/llamacto/llama-gin-kit/pkg/invariant"
+	"github.com/llamacto/llama-gin-kit/pkg/response"
+	"gorm.io/gorm"
+)
+
+// selects returns the SELECT statements sent to table
+func selects(db *dbtest.DB, table string) []dbtest.Statement {
+	var found []dbtest.Statement
+	for _, stmt := range db.Statements() {
+		if strings.HasPrefix(stmt.SQL, "SELECT") && strings.Contains(stmt.SQL, `FROM "`+table+`"`) {
+			found = append(found, stmt)
+		}
+	}
+	return found
+}
+
+// systemFilter reports whether stmt filters on is_system and the value it filters for
+func systemFilter(stmt dbtest.Statement) (bool, bool) {
+	before, _, ok := strings.Cut(stmt.SQL, "is_system = $")
+	if !ok {
+		return false, false
+	}
+	value, _ := stmt.Args[strings.Count(before, "$")].(bool)
+	return value, true
+}
+
+func TestSystemEntriesAreListedSeparately(t *testing.T) {
+	listRoles := func(r Repository, includeSystem bool) error {
+		_, _, err := r.ListRoles(context.Background(), &ListQuery{Page: 1, PageSize: 20, IncludeSystem: includeSystem})
+		return err
+	}
+	listPermissions := func(r Repository, includeSystem bool) error {
+		_, _, err := r.ListPermissions(context.Background(), &ListQuery{Page: 1, PageSize: 20, IncludeSystem: includeSystem})
+		return err
+	}
+
+	tests := []struct {
+		name       string
+		table      string
+		list       func(Repository) error
+		wantFilter bool
+		wantSystem bool
+	}{
+		{
+			name:       "role list excludes system roles by default",
+			table:      "roles",
+			list:       func(r Repository) error { return listRoles(r, false) },
+			wantFilter: true,
+		},
+		{
+			name:  "role list includes system roles on request",
+			table: "roles",
+			list:  func(r Repository) error { return listRoles(r, true) },
+		},
+		{
+			name:  "system role list",
+			table: "roles",
+			list: func(r Repository) error {
+				_, err := r.ListSystemRoles(context.Background())
+				return err
+			},
+			wantFilter: true,
+			wantSystem: true,
+		},
+		{
+			name:       "permission list excludes system permissions by default",
+			table:      "permissions",
+			list:       func(r Repository) error { return listPermissions(r, false) },
+			wantFilter: true,
+		},
+		{
+			name:  "permission list includes system permissions on request",
+			table: "permissions",
+			list:  func(r Repository) error { return listPermissions(r, true) },
+		},
+		{
+			name:  "system permission list",
+			table: "permissions",
+			list: func(r Repository) error {
+				_, err := r.ListSystemPermissions(context.Background())
+				return err
+			},
+			wantFilter: true,
+			wantSystem: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, db := dbtest.Open(t)
+			if err := tt.list(NewRepository(gormDB)); err != nil {
+				t.Fatalf("list error = %v", err)
+			}
+
+			stmts := selects(db, tt.table)
+			if len(stmts) == 0 {
+				t.Fatalf("no query on %s", tt.table)
+			}
+			for _, stmt := range stmts {
+				system, filtered := systemFilter(stmt)
+				if filtered != tt.wantFilter || system != tt.wantSystem {
+					t.Fatalf("query %q filters is_system = %v (filtered %v), want %v (filtered %v)",
+						stmt.SQL, system, filtered, tt.wantSystem, tt.wantFilter)
+				}
+			}
+		})
+	}
+}
+
+func TestListSystemRolesReturnsRows(t *testing.T) {
+	gormDB, db := dbtest.Open(t)
+	db.Returns(`FROM "roles"`, []string{"id", "name", "is_system"},
+		[]driver.Value{int64(1), "admin", true},
+		[]driver.Value{int64(2), "user", true},
+	)
+
+	roles, err := NewRepository(gormDB).ListSystemRoles(context.Background())
+	if err != nil {
+		t.Fatalf("ListSystemRoles() error = %v", err)
+	}
+	if len(roles) != 2 || roles[0].Name != "admin" || !roles[1].IsSystem {
+		t.Fatalf("ListSystemRoles() = %+v, want the two scripted system roles", roles)
+	}
+}
+
+func TestSetRoleActiveUpdatesInsteadOfDeleting(t *testing.T) {
+	tests := []struct {
+		name     string
+		table    string
+		affected int64
+		set      func(Repository) error
+		wantErr  error
+	}{
+		{
+			name:     "organization role",
+			table:    "organization_roles",
+			affected: 1,
+			set: func(r Repository) error {
+				return r.SetOrganizationRoleActive(context.Background(), 1, 2, 3, false, 9)
+			},
+		},
+		{
+			name:     "team role",
+			table:    "team_roles",
+			affected: 1,
+			set: func(r Repository) error {
+				return r.SetTeamRoleActive(context.Background(), 1, 2, 3, false, 9)
+			},
+		},
+		{
+			name:  "missing organization role",
+			table: "organization_roles",
+			set: func(r Repository) error {
+				return r.SetOrganizationRoleActive(context.Background(), 1, 2, 3, false, 9)
+			},
+			wantErr: gorm.ErrRecordNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, db := dbtest.Open(t)
+			db.Affects(`UPDATE "`+tt.table+`"`, tt.affected)
+
+			if err := tt.set(NewRepository(gormDB)); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("set active error = %v, want %v", err, tt.wantErr)
+			}
+
+			update, ok := db.Find(`UPDATE "` + tt.table + `" SET "is_active"=$1`)
+			if !ok || update.Args[0] != false {
+				t.Fatalf("no update of %s.is_active to false in %v", tt.table, db.Statements())
+			}
+			if stmt, ok := db.Find("DELETE"); ok {
+				t.Fatalf("deactivation deleted rows: %q", stmt.SQL)
+			}
+			if _, rolledBack := db.Find("ROLLBACK"); rolledBack != (tt.wantErr != nil) {
+				t.Fatalf("rolled back = %v, want %v", rolledBack, tt.wantErr != nil)
+			}
+		})
+	}
+}
+
+func TestRoleChecksIgnoreInactiveAssignments(t *testing.T) {
+	tests := []struct {
+		name     string
+		fragment string
+		load     func(Repository) error
+	}{
+		{
+			name:     "organization roles",
+			fragment: "orr.is_active = $",
+			load: func(r Repository) error {
+				_, err := r.GetUserOrganizationRoleIDs(context.Background(), 1, 2)
+				return err
+			},
+		},
+		{
+			name:     "team roles",
+			fragment: "team_roles.is_active = $",
+			load: func(r Repository) error {
+				_, err := r.GetUserTeamRoleIDs(context.Background(), 1, []uint{2})
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, db := dbtest.Open(t)
+			if err := tt.load(NewRepository(gormDB)); err != nil {
+				t.Fatalf("load error = %v", err)
+			}
+
+			stmt, ok := db.Find(tt.fragment)
+			if !ok {
+				t.Fatalf("no query filters %q", tt.fragment)
+			}
+			before, _, _ := strings.Cut(stmt.SQL, tt.fragment)
+			if stmt.Args[strings.Count(before, "$")] != true {
+				t.Fatalf("query %q does not require active assignments", stmt.SQL)
+			}
+		})
+	}
+}
+
+func TestAssignPermissionsToRoleListsUnknownIDs(t *testing.T) {
+	gormDB, db := dbtest.Open(t)
+	db.Returns(`FROM "permissions"`, []string{"id"}, []driver.Value{int64(1)}, []driver.Value{int64(3)})
+
+	err := NewRepository(gormDB).AssignPermissionsToRole(context.Background(), 7, []uint{1, 2, 3, 4, 2}, 9)
+
+	var notFound *PermissionsNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("AssignPermissionsToRole() error = %v, want *PermissionsNotFoundError", err)
+	}
+	if got := joinIDs(notFound.IDs); got != "2, 4" {
+		t.Fatalf("unknown IDs = %s, want 2, 4", got)
+	}
+	if err.Error() != "permissions not found: 2, 4" {
+		t.Fatalf("error message = %q", err.Error())
+	}
+	if response.StatusFromError(err, http.StatusInternalServerError) != http.StatusBadRequest {
+		t.Fatalf("unknown permissions should be a 400, got %d", response.StatusFromError(err, http.StatusInternalServerError))
+	}
+	if stmt, ok := db.Find(`INSERT INTO "role_permissions"`); ok {
+		t.Fatalf("permissions were assigned despite unknown IDs: %q", stmt.SQL)
+	}
+	if _, ok := db.Find("ROLLBACK"); !ok {
+		t.Fatal("transaction was not rolled back")
+	}
+}
+
+func TestMissingIDs(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested []uint
+		found     []uint
+		want      string
+	}{
+		{name: "all found", requested: []uint{1, 2}, found: []uint{2, 1}, want: ""},
+		{name: "some missing", requested: []uint{1, 2, 3, 4}, found: []uint{1, 3}, want: "2, 4"},
+		{name: "duplicates reported once", requested: []uint{5, 5, 6}, found: nil, want: "5, 6"},
+		{name: "nothing requested", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := joinIDs(missingIDs(tt.requested, tt.found)); got != tt.want {
+				t.Fatalf("missingIDs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListQuerySort(t *testing.T) {
+	tests := []struct {
+		name    string
+		orderBy string
+		order   string
+		want    string
+	}{
+		{name: "allowed sort", orderBy: "name", order: "ASC", want: "ORDER BY name ASC"},
+		{name: "invalid direction", orderBy: "name", order: "up", want: "ORDER BY name DESC"},
+		{name: "disallowed column", orderBy: "deleted_by", order: "asc", want: "ORDER BY created_at ASC"},
+		{name: "injection-shaped column", orderBy: "(SELECT 1)", order: "desc", want: "ORDER BY created_at DESC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := &ListQuery{Page: 1, PageSize: 20, OrderBy: tt.orderBy, Order: tt.order}
+			query.Normalize()
+
+			gormDB, db := dbtest.Open(t)
+			if _, _, err := NewRepository(gormDB).ListRoles(context.Background(), query); err != nil {
+				t.Fatalf("ListRoles() error = %v", err)
+			}
+			stmt, ok := db.Find("ORDER BY")
+			if !ok || !strings.Contains(stmt.SQL, tt.want) {
+				t.Fatalf("role list query %q does not contain %q", stmt.SQL, tt.want)
+			}
+		})
+	}
+}
+
+func TestListAuditLogsFilters(t *testing.T) {
+	actorID, targetID := uint(7), uint(42)
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	tests := []struct {
+		name  string
+		query AuditLogQuery
+		want  string
+		arg   driver.Value
+	}{
+		{name: "actor", query: AuditLogQuery{ActorID: &actorID}, want: "actor_id = $1", arg: int64(actorID)},
+		{name: "target user", query: AuditLogQuery{TargetType: "user", TargetID: &targetID}, want: "target_id = $2", arg: int64(targetID)},
+		{name: "resource", query: AuditLogQuery{TargetType: "role"}, want: "target_type = $1", arg: "role"},
+		{name: "action", query: AuditLogQuery{Action: AuditActionRoleAssign}, want: "action = $1", arg: AuditActionRoleAssign},
+		{name: "from", query: AuditLogQuery{From: &from}, want: "created_at >= $1", arg: from},
+		{name: "to", query: AuditLogQuery{To: &to}, want: "created_at <= $1", arg: to},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.query
+			query.Page, query.PageSize = 1, 20
+
+			gormDB, db := dbtest.Open(t)
+			if _, _, err := NewRepository(gormDB).ListAuditLogs(context.Background(), &query); err != nil {
+				t.Fatalf("ListAuditLogs() error = %v", err)
+			}
+
+			stmts := selects(db, "auth_audit_logs")
+			if len(stmts) != 2 {
+				t.Fatalf("sent %d audit log queries, want a count and a page", len(stmts))
+			}
+			for _, stmt := range stmts {
+				before, _, ok := strings.Cut(stmt.SQL, tt.want)
+				if !ok {
+					t.Fatalf("query %q does not filter on %q", stmt.SQL, tt.want)
+				}
+				if got := stmt.Args[strings.Count(before, "$")]; got != tt.arg {
+					t.Fatalf("%q bound to %v, want %v", tt.want, got, tt.arg)
+				}
+			}
+		})
+	}
+}
+
+func TestListAuditLogsPagination(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      AuditLogQuery
+		rows       int
+		wantKeyset bool
+		wantNext   uint // Zero when there is no next page
+	}{
+		{name: "first page", query: AuditLogQuery{PageSize: 2}, rows: 2, wantNext: 9},
+		{name: "page after cursor", query: AuditLogQuery{PageSize: 2, Cursor: 9}, rows: 2, wantKeyset: true, wantNext: 9},
+		{name: "last page", query: AuditLogQuery{PageSize: 2, Cursor: 9}, rows: 1, wantKeyset: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, db := dbtest.Open(t)
+			db.Returns(`SELECT count(*) FROM "auth_audit_logs"`, []string{"count"}, []driver.Value{int64(5)})
+			rows := [][]driver.Value{{int64(10), "role.assign"}, {int64(9), "role.assign"}}[:tt.rows]
+			db.Returns(`FROM "auth_audit_logs"`, []string{"id", "action"}, rows...)
+
+			query := tt.query
+			got, err := newTestService(NewRepository(gormDB)).ListAuditLogs(context.Background(), &query)
+			if err != nil {
+				t.Fatalf("ListAuditLogs() error = %v", err)
+			}
+
+			count, _ := db.Find("count(*)")
+			if strings.Contains(count.SQL, "id <") {
+				t.Fatalf("count query %q depends on the cursor; totals would shift between pages", count.SQL)
+			}
+			if got.Total != 5 {
+				t.Fatalf("Total = %d, want 5", got.Total)
+			}
+
+			page, _ := db.Find("ORDER BY id DESC")
+			if keyset := strings.Contains(page.SQL, "id < $"); keyset != tt.wantKeyset {
+				t.Fatalf("page query %q uses the cursor = %v, want %v", page.SQL, keyset, tt.wantKeyset)
+			}
+			if tt.wantKeyset && strings.Contains(page.SQL, "OFFSET") {
+				t.Fatalf("keyset page query %q also skips rows with OFFSET", page.SQL)
+			}
+
+			var next uint
+			if got.NextCursor != nil {
+				next = *got.NextCursor
+			}
+			if next != tt.wantNext {
+				t.Fatalf("NextCursor = %d, want %d", next, tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestWritesRejectInvalidModels(t *testing.T) {
+	tests := []struct {
+		name    string
+		table   string
+		write   func(Repository) error
+		wantErr error
+	}{
+		{
+			name:  "role",
+			table: "roles",
+			write: func(r Repository) error {
+				_, err := r.EnsureRoles(context.Background(), []*Role{{Name: "editor", DisplayName: "Editor"}})
+				return err
+			},
+		},
+		{
+			name:  "role without a name",
+			table: "roles",
+			write: func(r Repository) error {
+				_, err := r.EnsureRoles(context.Background(), []*Role{{DisplayName: "Editor"}})
+				return err
+			},
+			wantErr: invariant.ErrViolation,
+		},
+		{
+			name:  "permission without an action",
+			table: "permissions",
+			write: func(r Repository) error {
+				_, err := r.EnsurePermissions(context.Background(), []*Permission{{Name: "reports.read", DisplayName: "Read reports", Resource: "reports"}})
+				return err
+			},
+			wantErr: invariant.ErrViolation,
+		},
+		{
+			name:  "user role without a role",
+			table: "user_roles",
+			write: func(r Repository) error {
+				return r.AssignRoleToUser(context.Background(), &UserRole{UserID: 1, AssignedBy: 2})
+			},
+			wantErr: invariant.ErrViolation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, db := dbtest.Open(t)
+			db.Affects(`UPDATE "roles" SET "deleted_at"`, 0) // No soft-deleted role to restore
+
+			if err := tt.write(NewRepository(gormDB)); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("write error = %v, want %v", err, tt.wantErr)
+			}
+			if _, inserted := db.Find(`INSERT INTO "` + tt.table + `"`); inserted != (tt.wantErr == nil) {
+				t.Fatalf("%s row inserted = %v, want %v", tt.table, inserted, tt.wantErr == nil)
+			}
+			if _, rolledBack := db.Find("ROLLBACK"); rolledBack != (tt.wantErr != nil) {
+				t.Fatalf("rolled back = %v, want %v", rolledBack, tt.wantErr != nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,1495 @@
+package authorization
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/pkg/batch"
+	apperrors "github.com/llamacto/llama-gin-kit/pkg/errors"
+	"github.com/llamacto/llama-gin-kit/pkg/i18n"
+	"github.com/llamacto/llama-gin-kit/pkg/jwt"
+	"github.com/llamacto/llama-gin-kit/pkg/logger"
+	"github.com/llamacto/llama-gin-kit/pkg/pagination"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrRoleNotFound is returned when the requested role does not exist
+	ErrRoleNotFound = apperrors.NotFound(i18n.KeyRoleNotFound, "role not found")
+	// ErrRoleAlreadyAssigned is returned when the user already has the role
+	ErrRoleAlreadyAssigned = apperrors.AlreadyExists(i18n.KeyRoleAlreadyAssigned, "role already assigned")
+	// ErrRoleNotGrantable is returned when a user tries to grant a role above their own level
+	ErrRoleNotGrantable = apperrors.Forbidden(i18n.KeyRoleNotGrantable, "insufficient privileges to grant this role")
+	// ErrNotOrganizationMember is returned when switching to an organization the user doesn't belong to
+	ErrNotOrganizationMember = apperrors.Forbidden(i18n.KeyNotOrganizationMember, "user is not a member of this organization")
+	// ErrSystemRoleProtected is returned when deleting a built-in system role
+	ErrSystemRoleProtected = apperrors.Forbidden(i18n.KeySystemRoleProtected, "system roles cannot be deleted")
+	// ErrRoleInUse is returned when deleting a role that organization members still hold
+	ErrRoleInUse = apperrors.Conflict(i18n.KeyRoleInUse, "role is still held by organization members")
+	// ErrPolicyNotFound is returned when the requested policy does not exist
+	ErrPolicyNotFound = apperrors.NotFound(i18n.KeyPolicyNotFound, "policy not found")
+	// ErrInvalidPolicyConditions is returned when policy conditions are not a JSON object of known condition types
+	ErrInvalidPolicyConditions = apperrors.Validation(i18n.KeyInvalidPolicyConditions, "conditions must be a JSON object keyed by a known condition type")
+
+	// ErrDeletedRoleNotFound is returned when restoring a role that is not soft-deleted
+	ErrDeletedRoleNotFound = apperrors.NotFound(i18n.KeyDeletedRoleNotFound, "deleted role not found")
+	// ErrRoleAssignmentNotFound is returned when the user does not hold the global role
+	ErrRoleAssignmentNotFound = apperrors.NotFound(i18n.KeyRoleAssignmentNotFound, "role assignment not found")
+	// ErrOrganizationRoleAssignmentNotFound is returned when the user does not hold the organization role
+	ErrOrganizationRoleAssignmentNotFound = apperrors.NotFound(i18n.KeyOrganizationRoleAssignmentNotFound, "organization role assignment not found")
+	// ErrTeamRoleAssignmentNotFound is returned when the user does not hold the team role
+	ErrTeamRoleAssignmentNotFound = apperrors.NotFound(i18n.KeyTeamRoleAssignmentNotFound, "team role assignment not found")
+	// ErrOrganizationNotFound is returned when the organization does not exist
+	ErrOrganizationNotFound = apperrors.NotFound(i18n.KeyOrganizationNotFound, "organization not found")
+	// ErrExpirationInPast is returned when a role assignment would expire before it is made
+	ErrExpirationInPast = apperrors.Validation(i18n.KeyExpirationInPast, "expiration must be in the future")
+
+	// errPermissionsNotFound and errUsersNotFound classify the not-found errors carrying IDs
+	errPermissionsNotFound = apperrors.Validation(i18n.KeyPermissionsNotFound, "permissions not found")
+	errUsersNotFound       = apperrors.Validation(i18n.KeyUsersNotFound, "users not found")
+)
+
+// PermissionsNotFoundError lists the requested permission IDs that do not exist
+type PermissionsNotFoundError struct {
+	IDs []uint
+}
+
+func (e *PermissionsNotFoundError) Error() string {
+	return "permissions not found: " + joinIDs(e.IDs)
+}
+
+// MessageKey implements i18n.Localizable
+func (e *PermissionsNotFoundError) MessageKey() string { return i18n.KeyPermissionsNotFound }
+
+// MessageArgs supplies the missing IDs to the translated message
+func (e *PermissionsNotFoundError) MessageArgs() []interface{} { return []interface{}{joinIDs(e.IDs)} }
+
+// Unwrap classifies the error as a validation error with its code
+func (e *PermissionsNotFoundError) Unwrap() error { return errPermissionsNotFound }
+
+// UsersNotFoundError lists the requested user IDs that do not exist
+type UsersNotFoundError struct {
+	IDs []uint
+}
+
+func (e *UsersNotFoundError) Error() string {
+	return "users not found: " + joinIDs(e.IDs)
+}
+
+// MessageKey implements i18n.Localizable
+func (e *UsersNotFoundError) MessageKey() string { return i18n.KeyUsersNotFound }
+
+// MessageArgs supplies the missing IDs to the translated message
+func (e *UsersNotFoundError) MessageArgs() []interface{} { return []interface{}{joinIDs(e.IDs)} }
+
+// Unwrap classifies the error as a validation error with its code
+func (e *UsersNotFoundError) Unwrap() error { return errUsersNotFound }
+
+// joinIDs formats IDs as a comma separated list
+func joinIDs(ids []uint) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatUint(uint64(id), 10)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// adminRoles are global roles allowed to grant any organization role
+var adminRoles = []string{"super_admin", "admin"}
+
+// Service defines the interface for authorization business logic
+type Service interface {
+	ListRoles(ctx context.Context, query *ListQuery) (*RoleListResponse, error)
+	ListSystemRoles(ctx context.Context) ([]RoleResponse, error)
+	ListPermissions(ctx context.Context, query *ListQuery) (*PermissionListResponse, error)
+	ListSystemPermissions(ctx context.Context) ([]PermissionResponse, error)
+	GetPermissionCatalog(ctx context.Context) (*PermissionCatalog, error)
+	ResolvePermissions(ctx context.Context, names []string) (map[string]uint, error)
+	ResolvePermissionNames(ctx context.Context, ids []uint) (map[uint]string, error)
+	HasRole(ctx context.Context, userID uint, roleName string) (bool, error)
+	HasAnyRole(ctx context.Context, userID uint, roleNames ...string) (bool, error)
+	EvaluatePolicies(ctx context.Context, userID uint, resource, action string, env *PolicyEnvironment) (*PolicyDecision, error)
+	CheckPermission(ctx context.Context, userID uint, resource, action string, env *PolicyEnvironment) (bool, error)
+	CheckOrganizationPermission(ctx context.Context, userID, organizationID uint, permission string) (bool, error)
+	CheckUserTeamPermission(ctx context.Context, userID, teamID uint, permission string) (bool, error)
+	CreatePolicy(ctx context.Context, req *CreatePolicyRequest, createdBy uint) (*PolicyResponse, error)
+	GetPolicy(ctx context.Context, id uint) (*PolicyResponse, error)
+	UpdatePolicy(ctx context.Context, id uint, req *UpdatePolicyRequest, updatedBy uint) (*PolicyResponse, error)
+	DeletePolicy(ctx context.Context, id, deletedBy uint) error
+	ListPolicies(ctx context.Context, query *PolicyQuery) (*PolicyListResponse, error)
+	CheckCanGrantRole(ctx context.Context, userID, organizationID, roleID uint) error
+	SwitchOrganization(ctx context.Context, userID uint, username string, organizationID uint) (*SwitchOrganizationResponse, error)
+	GetUserPermissionsSummary(ctx context.Context, userID uint, query *PermissionsSummaryQuery) (*UserPermissionsSummary, error)
+	AssignRoleToUser(ctx context.Context, userID, roleID, assignedBy uint, expiresAt *time.Time) error
+	AssignRolesToUser(ctx context.Context, userID uint, req *AssignRolesRequest, assignedBy uint) (*batch.Result, error)
+	RemoveRoleFromUser(ctx context.Context, userID, roleID, removedBy uint) error
+	AssignPermissionsToRole(ctx context.Context, roleID uint, permissionIDs []uint, assignedBy uint) error
+	RemovePermissionsFromRole(ctx context.Context, roleID uint, permissionIDs []uint, removedBy uint) error
+	PreviewRolePermissionChange(ctx context.Context, roleID uint, req *RolePermissionChangeRequest) (*RolePermissionChangePreview, error)
+	SetOrganizationRoleActive(ctx context.Context, organizationID, userID, roleID uint, active bool, actorID uint) error
+	BulkAssignOrganizationRole(ctx context.Context, req *BulkAssignOrganizationRoleRequest, assignedBy uint) (*batch.Result, error)
+	SetTeamRoleActive(ctx context.Context, teamID, userID, roleID uint, active bool, actorID uint) error
+	ListAuditLogs(ctx context.Context, query *AuditLogQuery) (*AuditLogListResponse, error)
+	ExportOrganizationAuditLogs(ctx context.Context, organizationID uint, query *AuditExportQuery, w io.Writer) error
+	HasSystemRoles(ctx context.Context) (bool, error)
+	InitializeSystemRoles(ctx context.Context) (created, existing []string, err error)
+	InitializeSystemPermissions(ctx context.Context) (created, existing []string, err error)
+	InitializeSystem(ctx context.Context, actorID uint) (*InitializeSystemResult, error)
+	SyncSuperAdminPermissions(ctx context.Context) (int, error)
+	WarmCache(ctx context.Context) error
+	DeleteRole(ctx context.Context, id, deletedBy uint) error
+	ListDeletedRoles(ctx context.Context, query *ListQuery) (*RoleListResponse, error)
+	RestoreRole(ctx context.Context, id, restoredBy uint) (*RoleResponse, error)
+}
+
+// service implements the Service interface
+type service struct {
+	repo      Repository
+	cache     permissionStore
+	orgCache  orgPermissionStore
+	roleCache *rolePermissionCache
+}
+
+// NewService creates a new authorization service instance
+func NewService(repo Repository) Service {
+	return &service{
+		repo:      repo,
+		cache:     sharedPermissionStore,
+		orgCache:  sharedOrgPermissionCache,
+		roleCache: sharedRolePermissionCache,
+	}
+}
+
+// ListRoles retrieves roles with pagination, excluding system roles unless requested
+func (s *service) ListRoles(ctx context.Context, query *ListQuery) (*RoleListResponse, error) {
+	normalizeListQuery(query)
+
+	roles, total, err := s.repo.ListRoles(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+
+	responses := make([]RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		responses = append(responses, ToRoleResponse(role))
+	}
+
+	return &RoleListResponse{
+		Roles:      responses,
+		Total:      total,
+		Page:       query.Page,
+		PageSize:   query.PageSize,
+		TotalPages: int((total + int64(query.PageSize) - 1) / int64(query.PageSize)),
+	}, nil
+}
+
+// ListSystemRoles retrieves all built-in system roles
+func (s *service) ListSystemRoles(ctx context.Context) ([]RoleResponse, error) {
+	roles, err := s.repo.ListSystemRoles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list system roles: %w", err)
+	}
+
+	responses := make([]RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		responses = append(responses, ToRoleResponse(role))
+	}
+	return responses, nil
+}
+
+// DeleteRole soft-deletes a custom role so it can be restored later. System roles and roles
+// still held by organization members cannot be deleted; removed members do not count.
+func (s *service) DeleteRole(ctx context.Context, id, deletedBy uint) error {
+	role, err := s.repo.GetRoleByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRoleNotFound
+		}
+		return fmt.Errorf("failed to get role: %w", err)
+	}
+	if role.IsSystem {
+		return ErrSystemRoleProtected
+	}
+	members, err := s.repo.CountRoleMembers(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to count role members: %w", err)
+	}
+	if members > 0 {
+		return ErrRoleInUse
+	}
+
+	if err := s.repo.DeleteRole(ctx, id, deletedBy); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRoleNotFound
+		}
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+	s.invalidateRoleHolders(ctx, id)
+	return nil
+}
+
+// ListDeletedRoles retrieves soft-deleted roles with pagination, most recently deleted first
+func (s *service) ListDeletedRoles(ctx context.Context, query *ListQuery) (*RoleListResponse, error) {
+	normalizeListQuery(query)
+
+	roles, total, err := s.repo.ListDeletedRoles(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deleted roles: %w", err)
+	}
+
+	responses := make([]RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		responses = append(responses, ToRoleResponse(role))
+	}
+
+	return &RoleListResponse{
+		Roles:      responses,
+		Total:      total,
+		Page:       query.Page,
+		PageSize:   query.PageSize,
+		TotalPages: int((total + int64(query.PageSize) - 1) / int64(query.PageSize)),
+	}, nil
+}
+
+// RestoreRole undoes a role's soft delete, bringing its assignments and permissions back into effect
+func (s *service) RestoreRole(ctx context.Context, id, restoredBy uint) (*RoleResponse, error) {
+	if err := s.repo.RestoreRole(ctx, id, restoredBy); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDeletedRoleNotFound
+		}
+		return nil, fmt.Errorf("failed to restore role: %w", err)
+	}
+	s.invalidateRoleHolders(ctx, id)
+
+	role, err := s.repo.GetRoleByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	resp := ToRoleResponse(role)
+	return &resp, nil
+}
+
+// ListPermissions retrieves permissions with pagination, excluding system permissions unless requested
+func (s *service) ListPermissions(ctx context.Context, query *ListQuery) (*PermissionListResponse, error) {
+	normalizeListQuery(query)
+
+	permissions, total, err := s.repo.ListPermissions(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list permissions: %w", err)
+	}
+
+	responses := make([]PermissionResponse, 0, len(permissions))
+	for _, permission := range permissions {
+		responses = append(responses, ToPermissionResponse(permission))
+	}
+
+	return &PermissionListResponse{
+		Permissions: responses,
+		Total:       total,
+		Page:        query.Page,
+		PageSize:    query.PageSize,
+		TotalPages:  int((total + int64(query.PageSize) - 1) / int64(query.PageSize)),
+	}, nil
+}
+
+// ListSystemPermissions retrieves all built-in system permissions
+func (s *service) ListSystemPermissions(ctx context.Context) ([]PermissionResponse, error) {
+	permissions, err := s.repo.ListSystemPermissions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list system permissions: %w", err)
+	}
+
+	responses := make([]PermissionResponse, 0, len(permissions))
+	for _, permission := range permissions {
+		responses = append(responses, ToPermissionResponse(permission))
+	}
+	return responses, nil
+}
+
+// GetPermissionCatalog returns every active permission grouped by category. The catalog is
+// cached for up to PermissionCatalogTTL.
+func (s *service) GetPermissionCatalog(ctx context.Context) (*PermissionCatalog, error) {
+	if catalog, ok := sharedCatalogCache.get(); ok {
+		return catalog, nil
+	}
+
+	version := sharedCatalogCache.currentVersion()
+	permissions, err := s.repo.ListActivePermissions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list permissions: %w", err)
+	}
+
+	catalog := buildPermissionCatalog(permissions)
+	sharedCatalogCache.set(catalog, version)
+	return catalog, nil
+}
+
+// buildPermissionCatalog groups permissions by category, keeping their order within each
+// category. Categories are sorted by name; permissions without one fall under "general".
+func buildPermissionCatalog(permissions []*Permission) *PermissionCatalog {
+	byCategory := make(map[string][]PermissionResponse)
+	for _, permission := range permissions {
+		category := permission.Category
+		if category == "" {
+			category = "general"
+		}
+		byCategory[category] = append(byCategory[category], ToPermissionResponse(permission))
+	}
+
+	names := make([]string, 0, len(byCategory))
+	for name := range byCategory {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	catalog := &PermissionCatalog{Categories: make([]PermissionCategory, 0, len(names)), Total: len(permissions)}
+	for _, name := range names {
+		catalog.Categories = append(catalog.Categories, PermissionCategory{Name: name, Permissions: byCategory[name]})
+	}
+	return catalog
+}
+
+// ResolvePermissions maps permission names to their IDs. Names that do not exist are left
+// out of the map; see unresolvedNames.
+func (s *service) ResolvePermissions(ctx context.Context, names []string) (map[string]uint, error) {
+	resolved, err := s.repo.GetPermissionIDsByNames(ctx, names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve permission names: %w", err)
+	}
+	return resolved, nil
+}
+
+// ResolvePermissionNames maps permission IDs to their names. IDs that do not exist are left
+// out of the map; see unresolvedIDs.
+func (s *service) ResolvePermissionNames(ctx context.Context, ids []uint) (map[uint]string, error) {
+	resolved, err := s.repo.GetPermissionNamesByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve permission IDs: %w", err)
+	}
+	return resolved, nil
+}
+
+// unresolvedNames returns the names missing from resolved, in request order without duplicates
+func unresolvedNames(names []string, resolved map[string]uint) []string {
+	missing := []string{}
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if _, ok := resolved[name]; ok || seen[name] {
+			continue
+		}
+		seen[name] = true
+		missing = append(missing, name)
+	}
+	return missing
+}
+
+// unresolvedIDs returns the IDs missing from resolved, in request order without duplicates
+func unresolvedIDs(ids []uint, resolved map[uint]string) []uint {
+	missing := []uint{}
+	seen := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		if _, ok := resolved[id]; ok || seen[id] {
+			continue
+		}
+		seen[id] = true
+		missing = append(missing, id)
+	}
+	return missing
+}
+
+// HasSystemRoles reports whether any built-in system role exists yet
+func (s *service) HasSystemRoles(ctx context.Context) (bool, error) {
+	count, err := s.repo.CountSystemRoles(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to count system roles: %w", err)
+	}
+	return count > 0, nil
+}
+
+// InitializeSystemRoles creates the built-in roles that are missing and, when it creates any,
+// grants super_admin every existing permission. It is safe to call repeatedly.
+func (s *service) InitializeSystemRoles(ctx context.Context) (created, existing []string, err error) {
+	created, existing, err = s.ensureSystemRoles(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(created) > 0 {
+		if _, err := s.SyncSuperAdminPermissions(ctx); err != nil {
+			return nil, nil, err
+		}
+	}
+	return created, existing, nil
+}
+
+// ensureSystemRoles creates the built-in roles that are missing
+func (s *service) ensureSystemRoles(ctx context.Context) (created, existing []string, err error) {
+	roles := systemRoles()
+	created, err = s.repo.EnsureRoles(ctx, roles)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create system roles: %w", err)
+	}
+
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	return created, difference(names, created), nil
+}
+
+// InitializeSystemPermissions creates the built-in permissions that are missing and grants
+// them to super_admin. It is safe to call repeatedly.
+func (s *service) InitializeSystemPermissions(ctx context.Context) (created, existing []string, err error) {
+	created, existing, err = s.ensureSystemPermissions(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(created) > 0 {
+		if _, err := s.SyncSuperAdminPermissions(ctx); err != nil {
+			return nil, nil, err
+		}
+	}
+	return created, existing, nil
+}
+
+// ensureSystemPermissions creates the built-in permissions that are missing
+func (s *service) ensureSystemPermissions(ctx context.Context) (created, existing []string, err error) {
+	permissions := systemPermissions()
+	created, err = s.repo.EnsurePermissions(ctx, permissions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create system permissions: %w", err)
+	}
+	if len(created) > 0 {
+		sharedCatalogCache.invalidate()
+	}
+
+	names := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		names = append(names, permission.Name)
+	}
+	return created, difference(names, created), nil
+}
+
+// InitializeSystem creates the built-in roles and permissions and grants every permission
+// to super_admin. Running it again only fills in what is missing.
+func (s *service) InitializeSystem(ctx context.Context, actorID uint) (*InitializeSystemResult, error) {
+	result := &InitializeSystemResult{}
+
+	var err error
+	result.RolesCreated, result.RolesExisting, err = s.ensureSystemRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result.PermissionsCreated, result.PermissionsExisting, err = s.ensureSystemPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result.PermissionsGranted, err = s.syncSuperAdminPermissions(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// SyncSuperAdminPermissions links every existing permission that super_admin lacks to the role,
+// so its effective permissions match the override in RequireRole. The grant is audited as
+// made by the system (actor 0). It returns how many permissions were added, and 0 when the
+// super_admin role has not been created yet.
+func (s *service) SyncSuperAdminPermissions(ctx context.Context) (int, error) {
+	return s.syncSuperAdminPermissions(ctx, 0)
+}
+
+// syncSuperAdminPermissions grants super_admin every permission it lacks on behalf of actorID
+func (s *service) syncSuperAdminPermissions(ctx context.Context, actorID uint) (int, error) {
+	superAdmin, err := s.repo.GetRoleByName(ctx, superAdminRole)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get %s role: %w", superAdminRole, err)
+	}
+
+	allIDs, err := s.repo.ListPermissionIDs(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list permissions: %w", err)
+	}
+	grantedIDs, err := s.repo.GetPermissionIDsByRoleID(ctx, superAdmin.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get %s permissions: %w", superAdminRole, err)
+	}
+
+	toGrant := missingIDs(allIDs, grantedIDs)
+	if len(toGrant) == 0 {
+		return 0, nil
+	}
+	if err := s.repo.AssignPermissionsToRole(ctx, superAdmin.ID, toGrant, actorID); err != nil {
+		return 0, fmt.Errorf("failed to grant permissions to %s: %w", superAdminRole, err)
+	}
+	s.roleCache.invalidate(superAdmin.ID)
+	return len(toGrant), nil
+}
+
+// difference returns the names in all that are not in exclude, keeping their order
+func difference(all, exclude []string) []string {
+	skip := make(map[string]bool, len(exclude))
+	for _, name := range exclude {
+		skip[name] = true
+	}
+	result := make([]string, 0, len(all))
+	for _, name := range all {
+		if !skip[name] {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// HasRole checks whether a user holds the named global role
+func (s *service) HasRole(ctx context.Context, userID uint, roleName string) (bool, error) {
+	ok, err := s.repo.UserHasRole(ctx, userID, roleName)
+	if err != nil {
+		return false, fmt.Errorf("failed to check user role: %w", err)
+	}
+	return ok, nil
+}
+
+// HasAnyRole reports whether the user holds at least one of the global roles
+func (s *service) HasAnyRole(ctx context.Context, userID uint, roleNames ...string) (bool, error) {
+	ok, err := s.repo.UserHasAnyRole(ctx, userID, roleNames)
+	if err != nil {
+		return false, fmt.Errorf("failed to check user roles: %w", err)
+	}
+	return ok, nil
+}
+
+// CheckCanGrantRole returns ErrRoleNotGrantable unless the user may grant the role within
+// the organization: global admins may grant any role, everyone else only roles at or
+// below the highest level they hold in that organization
+func (s *service) CheckCanGrantRole(ctx context.Context, userID, organizationID, roleID uint) error {
+	role, err := s.repo.GetRoleByID(ctx, roleID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRoleNotFound
+		}
+		return fmt.Errorf("failed to get role: %w", err)
+	}
+
+	for _, name := range adminRoles {
+		ok, err := s.repo.UserHasRole(ctx, userID, name)
+		if err != nil {
+			return fmt.Errorf("failed to check user role: %w", err)
+		}
+		if ok {
+			return nil
+		}
+	}
+
+	level, ok, err := s.repo.GetUserMaxRoleLevelInOrganization(ctx, userID, organizationID)
+	if err != nil {
+		return fmt.Errorf("failed to get user role level: %w", err)
+	}
+	if !ok || level < role.Level {
+		return ErrRoleNotGrantable
+	}
+	return nil
+}
+
+// EvaluatePolicies decides whether policies allow or deny the user's action on a resource.
+// Policies apply to the user ("user:<id>"), any of their global roles ("role:<id>") or everyone ("*").
+// An applicable deny always wins; otherwise the highest-priority applicable allow decides.
+// A policy whose conditions cannot be evaluated is treated as applying when it denies and
+// as not applying when it allows, so broken conditions never grant access.
+func (s *service) EvaluatePolicies(ctx context.Context, userID uint, resource, action string, env *PolicyEnvironment) (*PolicyDecision, error) {
+	perms, err := s.loadUserPermissions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.evaluatePolicies(ctx, userID, perms.roleIDs, resource, action, env)
+}
+
+// evaluatePolicies implements EvaluatePolicies for global role IDs the caller has already loaded
+func (s *service) evaluatePolicies(ctx context.Context, userID uint, roleIDs []uint, resource, action string, env *PolicyEnvironment) (*PolicyDecision, error) {
+	if env == nil {
+		env = &PolicyEnvironment{}
+	}
+	if env.Time.IsZero() {
+		env.Time = time.Now()
+	}
+
+	subjects := []string{"*", "user:" + strconv.FormatUint(uint64(userID), 10)}
+	for _, roleID := range roleIDs {
+		subjects = append(subjects, "role:"+strconv.FormatUint(uint64(roleID), 10))
+	}
+
+	policies, err := s.repo.ListActivePolicies(ctx, subjects, action)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list policies: %w", err)
+	}
+
+	decision := &PolicyDecision{}
+	for _, policy := range policies {
+		if !policyObjectMatches(policy.Object, resource) {
+			continue
+		}
+
+		applies, err := evaluateConditions(policy.Conditions, env)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to evaluate conditions of policy %d", policy.ID), err)
+			applies = policy.Effect == PolicyEffectDeny
+		}
+		if !applies {
+			continue
+		}
+
+		switch policy.Effect {
+		case PolicyEffectDeny:
+			return &PolicyDecision{Effect: PolicyEffectDeny, PolicyID: policy.ID}, nil
+		case PolicyEffectAllow:
+			if decision.Effect == "" {
+				decision = &PolicyDecision{Effect: PolicyEffectAllow, PolicyID: policy.ID}
+			}
+		}
+	}
+	return decision, nil
+}
+
+// CheckPermission reports whether the user may perform the action on the resource.
+// Policies are consulted first and an explicit allow or deny is final; when no policy applies,
+// the user needs super_admin or a global role granting the "<resource>.<action>" permission.
+// Role-derived permissions are cached for up to PermissionCacheTTL; policies are always evaluated.
+func (s *service) CheckPermission(ctx context.Context, userID uint, resource, action string, env *PolicyEnvironment) (bool, error) {
+	perms, err := s.loadUserPermissions(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	decision, err := s.evaluatePolicies(ctx, userID, perms.roleIDs, resource, action, env)
+	if err != nil {
+		return false, err
+	}
+	if decision.Effect != "" {
+		return decision.Allowed(), nil
+	}
+
+	return perms.superAdmin || perms.names[resource+"."+action], nil
+}
+
+// loadUserPermissions returns the user's global roles and the permissions they grant,
+// from the cache when possible. An entry never outlives the earliest role expiry.
+func (s *service) loadUserPermissions(ctx context.Context, userID uint) (*userPermissions, error) {
+	if entry, ok := s.cache.get(ctx, userID); ok {
+		return entry, nil
+	}
+	version := s.cache.currentVersion(ctx, userID)
+
+	userRoles, err := s.repo.GetUserRoles(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+
+	entry := &userPermissions{
+		roleIDs:   make([]uint, 0, len(userRoles)),
+		names:     make(map[string]bool),
+		expiresAt: time.Now().Add(PermissionCacheTTL),
+	}
+	for _, userRole := range userRoles {
+		entry.roleIDs = append(entry.roleIDs, userRole.RoleID)
+		if userRole.Role.Name == superAdminRole {
+			entry.superAdmin = true
+		}
+		if userRole.ExpiresAt != nil && userRole.ExpiresAt.Before(entry.expiresAt) {
+			entry.expiresAt = *userRole.ExpiresAt
+		}
+	}
+
+	if !entry.superAdmin {
+		names, err := s.permissionNamesByRoleIDs(ctx, entry.roleIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get permissions: %w", err)
+		}
+		for _, name := range names {
+			entry.names[name] = true
+		}
+	}
+
+	s.cache.set(ctx, userID, entry, version)
+	return entry, nil
+}
+
+// CheckOrganizationPermission reports whether the user holds the named permission, e.g.
+// "members.update", in the organization through their membership role or an active
+// organization role. Global super_admins always pass. The resolved permission set is cached
+// per user and organization for up to PermissionCacheTTL.
+func (s *service) CheckOrganizationPermission(ctx context.Context, userID, organizationID uint, permission string) (bool, error) {
+	global, err := s.loadUserPermissions(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if global.superAdmin {
+		return true, nil
+	}
+
+	perms, err := s.loadOrganizationPermissions(ctx, userID, organizationID)
+	if err != nil {
+		return false, err
+	}
+	return perms.names[permission], nil
+}
+
+// loadOrganizationPermissions returns the permissions the user's roles in the organization
+// grant, from the cache when possible and from the database otherwise
+func (s *service) loadOrganizationPermissions(ctx context.Context, userID, organizationID uint) (*orgPermissions, error) {
+	if entry, ok := s.orgCache.get(ctx, userID, organizationID); ok {
+		return entry, nil
+	}
+	version := s.orgCache.currentVersion(ctx, userID, organizationID)
+
+	roleIDs, err := s.repo.GetUserOrganizationRoleIDs(ctx, userID, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization roles: %w", err)
+	}
+
+	entry := &orgPermissions{
+		names:     make(map[string]bool),
+		expiresAt: time.Now().Add(PermissionCacheTTL),
+	}
+	if len(roleIDs) > 0 {
+		names, err := s.permissionNamesByRoleIDs(ctx, roleIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get permissions: %w", err)
+		}
+		for _, name := range names {
+			entry.names[name] = true
+		}
+	}
+
+	s.orgCache.set(ctx, userID, organizationID, entry, version)
+	return entry, nil
+}
+
+// CheckUserTeamPermission reports whether the user holds the named permission on the team
+// through an active team role. When the team's organization has inherit_team_permissions set,
+// roles held on any ancestor team count as well, so a grant on a parent team reaches its
+// children. Global super_admins always pass; unknown teams grant nothing.
+func (s *service) CheckUserTeamPermission(ctx context.Context, userID, teamID uint, permission string) (bool, error) {
+	global, err := s.loadUserPermissions(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if global.superAdmin {
+		return true, nil
+	}
+
+	teamIDs, err := s.teamPermissionScope(ctx, teamID)
+	if err != nil {
+		return false, err
+	}
+	if len(teamIDs) == 0 {
+		return false, nil
+	}
+
+	roleIDs, err := s.repo.GetUserTeamRoleIDs(ctx, userID, teamIDs)
+	if err != nil {
+		return false, fmt.Errorf("failed to get team roles: %w", err)
+	}
+	if len(roleIDs) == 0 {
+		return false, nil
+	}
+
+	names, err := s.permissionNamesByRoleIDs(ctx, roleIDs)
+	if err != nil {
+		return false, fmt.Errorf("failed to get permissions: %w", err)
+	}
+	for _, name := range names {
+		if name == permission {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// teamPermissionScope returns the teams whose roles apply to teamID: the team itself and,
+// when its organization enables inheritance, its ancestors up to the root. The walk stops
+// at a missing ancestor or a repeated team, so a corrupt hierarchy cannot loop forever.
+func (s *service) teamPermissionScope(ctx context.Context, teamID uint) ([]uint, error) {
+	node, err := s.repo.GetTeamNode(ctx, teamID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team: %w", err)
+	}
+
+	teamIDs := []uint{node.ID}
+	if !node.InheritTeamPermissions {
+		return teamIDs, nil
+	}
+
+	visited := map[uint]bool{node.ID: true}
+	for node.ParentTeamID != nil && !visited[*node.ParentTeamID] {
+		parent, err := s.repo.GetTeamNode(ctx, *node.ParentTeamID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get parent team: %w", err)
+		}
+		visited[parent.ID] = true
+		teamIDs = append(teamIDs, parent.ID)
+		node = parent
+	}
+	return teamIDs, nil
+}
+
+// permissionNamesByRoleIDs returns the distinct permission names the roles grant, sorted.
+// Roles are looked up in the role permission cache first and only the rest are loaded.
+func (s *service) permissionNamesByRoleIDs(ctx context.Context, roleIDs []uint) ([]string, error) {
+	if len(roleIDs) == 0 {
+		return nil, nil
+	}
+
+	granted, missing := s.roleCache.get(roleIDs)
+	if len(missing) > 0 {
+		version := s.roleCache.currentVersion()
+		loaded, err := s.repo.GetPermissionNamesGroupedByRoleIDs(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		s.roleCache.set(missing, loaded, version)
+		for _, roleID := range missing {
+			granted[roleID] = loaded[roleID]
+		}
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, roleNames := range granted {
+		for _, name := range roleNames {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// WarmCache loads every role's permissions into the role permission cache, so permission
+// checks after startup need no join. It does nothing when permissions are not cached in memory.
+func (s *service) WarmCache(ctx context.Context) error {
+	if !s.roleCache.enabled() {
+		return nil
+	}
+
+	version := s.roleCache.currentVersion()
+	names, err := s.repo.ListRolePermissionNames(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list role permissions: %w", err)
+	}
+	roleIDs := make([]uint, 0, len(names))
+	for roleID := range names {
+		roleIDs = append(roleIDs, roleID)
+	}
+	s.roleCache.set(roleIDs, names, version)
+	return nil
+}
+
+// invalidateRoleHolders drops the role's cached permissions and those of everyone holding it.
+// If the holders cannot be listed the whole cache is dropped, so no stale grant survives.
+// Organization holders are not tracked, so every cached organization permission set is dropped.
+func (s *service) invalidateRoleHolders(ctx context.Context, roleID uint) {
+	s.roleCache.invalidate(roleID)
+	s.orgCache.invalidateAll(ctx)
+
+	userIDs, err := s.repo.ListRoleHolderIDs(ctx, roleID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to list holders of role %d, clearing the permission cache", roleID), err)
+		s.cache.invalidateAll(ctx)
+		return
+	}
+	s.cache.invalidate(ctx, userIDs...)
+}
+
+// CreatePolicy validates the conditions and creates a policy
+func (s *service) CreatePolicy(ctx context.Context, req *CreatePolicyRequest, createdBy uint) (*PolicyResponse, error) {
+	if err := validateConditions(req.Conditions); err != nil {
+		return nil, err
+	}
+
+	policy := &Policy{
+		Subject:    req.Subject,
+		Action:     req.Action,
+		Object:     req.Resource,
+		Effect:     req.Effect,
+		Conditions: conditionsColumn(req.Conditions),
+		Priority:   req.Priority,
+		Status:     1,
+	}
+	if req.Status != nil {
+		policy.Status = *req.Status
+	}
+
+	if err := s.repo.CreatePolicy(ctx, policy, createdBy); err != nil {
+		return nil, fmt.Errorf("failed to create policy: %w", err)
+	}
+	resp := ToPolicyResponse(policy)
+	return &resp, nil
+}
+
+// GetPolicy retrieves a policy by ID
+func (s *service) GetPolicy(ctx context.Context, id uint) (*PolicyResponse, error) {
+	policy, err := s.getPolicy(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	resp := ToPolicyResponse(policy)
+	return &resp, nil
+}
+
+// UpdatePolicy applies the provided fields to a policy
+func (s *service) UpdatePolicy(ctx context.Context, id uint, req *UpdatePolicyRequest, updatedBy uint) (*PolicyResponse, error) {
+	if err := validateConditions(req.Conditions); err != nil {
+		return nil, err
+	}
+
+	policy, err := s.getPolicy(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Subject != nil {
+		policy.Subject = *req.Subject
+	}
+	if req.Action != nil {
+		policy.Action = *req.Action
+	}
+	if req.Resource != nil {
+		policy.Object = *req.Resource
+	}
+	if req.Effect != nil {
+		policy.Effect = *req.Effect
+	}
+	if req.Conditions != nil {
+		policy.Conditions = conditionsColumn(req.Conditions)
+	}
+	if req.Priority != nil {
+		policy.Priority = *req.Priority
+	}
+	if req.Status != nil {
+		policy.Status = *req.Status
+	}
+
+	if err := s.repo.UpdatePolicy(ctx, policy, updatedBy); err != nil {
+		return nil, fmt.Errorf("failed to update policy: %w", err)
+	}
+	resp := ToPolicyResponse(policy)
+	return &resp, nil
+}
+
+// DeletePolicy soft-deletes a policy
+func (s *service) DeletePolicy(ctx context.Context, id, deletedBy uint) error {
+	if err := s.repo.DeletePolicy(ctx, id, deletedBy); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrPolicyNotFound
+		}
+		return fmt.Errorf("failed to delete policy: %w", err)
+	}
+	return nil
+}
+
+// ListPolicies retrieves policies with pagination, highest priority first
+func (s *service) ListPolicies(ctx context.Context, query *PolicyQuery) (*PolicyListResponse, error) {
+	query.Page, query.PageSize = pagination.Normalize(query.Page, query.PageSize, pagination.DefaultPageSize)
+
+	policies, total, err := s.repo.ListPolicies(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list policies: %w", err)
+	}
+
+	responses := make([]PolicyResponse, 0, len(policies))
+	for _, policy := range policies {
+		responses = append(responses, ToPolicyResponse(policy))
+	}
+
+	return &PolicyListResponse{
+		Policies:   responses,
+		Total:      total,
+		Page:       query.Page,
+		PageSize:   query.PageSize,
+		TotalPages: pagination.TotalPages(total, query.PageSize),
+	}, nil
+}
+
+// getPolicy loads a policy, mapping a missing record to ErrPolicyNotFound
+func (s *service) getPolicy(ctx context.Context, id uint) (*Policy, error) {
+	policy, err := s.repo.GetPolicyByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPolicyNotFound
+		}
+		return nil, fmt.Errorf("failed to get policy: %w", err)
+	}
+	return policy, nil
+}
+
+// conditionsColumn converts validated conditions to the stored value, mapping absent or null conditions to NULL
+func conditionsColumn(conditions json.RawMessage) *string {
+	trimmed := strings.TrimSpace(string(conditions))
+	if trimmed == "" || trimmed == "null" {
+		return nil
+	}
+	return &trimmed
+}
+
+// SwitchOrganization issues a token scoped to the organization after checking membership
+func (s *service) SwitchOrganization(ctx context.Context, userID uint, username string, organizationID uint) (*SwitchOrganizationResponse, error) {
+	isMember, err := s.repo.IsOrganizationMember(ctx, userID, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check membership: %w", err)
+	}
+	if !isMember {
+		return nil, ErrNotOrganizationMember
+	}
+
+	token, err := jwt.GenerateTokenForOrg(userID, username, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return &SwitchOrganizationResponse{
+		Token:                token,
+		ActiveOrganizationID: organizationID,
+	}, nil
+}
+
+// GetUserPermissionsSummary collects a user's global, organization and team roles and
+// the union of permissions they grant. Inactive roles contribute no permissions.
+func (s *service) GetUserPermissionsSummary(ctx context.Context, userID uint, query *PermissionsSummaryQuery) (*UserPermissionsSummary, error) {
+	userRoles, err := s.repo.GetUserRoles(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+	orgRoles, err := s.repo.GetUserOrganizationRoles(ctx, userID, query.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization roles: %w", err)
+	}
+	teamRoles, err := s.repo.GetUserTeamRoles(ctx, userID, query.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team roles: %w", err)
+	}
+
+	summary := &UserPermissionsSummary{
+		UserID:            userID,
+		GlobalRoles:       make([]UserRoleResponse, 0, len(userRoles)),
+		OrganizationRoles: make([]OrganizationRoleResponse, 0, len(orgRoles)),
+		TeamRoles:         make([]TeamRoleResponse, 0, len(teamRoles)),
+	}
+
+	var roleIDs []uint
+	addRole := func(role *Role) {
+		if role.Status == 1 {
+			roleIDs = append(roleIDs, role.ID)
+		}
+	}
+
+	for _, ur := range userRoles {
+		summary.GlobalRoles = append(summary.GlobalRoles, UserRoleResponse{
+			RoleID:      ur.RoleID,
+			RoleName:    ur.Role.Name,
+			DisplayName: ur.Role.DisplayName,
+			AssignedBy:  ur.AssignedBy,
+			ExpiresAt:   ur.ExpiresAt,
+			CreatedAt:   ur.CreatedAt,
+		})
+		addRole(&ur.Role)
+	}
+	for _, orgRole := range orgRoles {
+		summary.OrganizationRoles = append(summary.OrganizationRoles, OrganizationRoleResponse{
+			OrganizationID: orgRole.OrganizationID,
+			RoleID:         orgRole.RoleID,
+			RoleName:       orgRole.Role.Name,
+			DisplayName:    orgRole.Role.DisplayName,
+			AssignedBy:     orgRole.AssignedBy,
+			CreatedAt:      orgRole.CreatedAt,
+		})
+		addRole(&orgRole.Role)
+	}
+	for _, tr := range teamRoles {
+		summary.TeamRoles = append(summary.TeamRoles, TeamRoleResponse{
+			TeamID:      tr.TeamID,
+			RoleID:      tr.RoleID,
+			RoleName:    tr.Role.Name,
+			DisplayName: tr.Role.DisplayName,
+			AssignedBy:  tr.AssignedBy,
+			CreatedAt:   tr.CreatedAt,
+		})
+		addRole(&tr.Role)
+	}
+
+	permissions, err := s.permissionNamesByRoleIDs(ctx, roleIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get permissions: %w", err)
+	}
+	if permissions == nil {
+		permissions = []string{}
+	}
+	summary.EffectivePermissions = permissions
+
+	return summary, nil
+}
+
+// AssignRoleToUser assigns a global role to a user and records the change in the audit log
+func (s *service) AssignRoleToUser(ctx context.Context, userID, roleID, assignedBy uint, expiresAt *time.Time) error {
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return ErrExpirationInPast
+	}
+
+	if err := s.checkAssignable(ctx, userID, roleID); err != nil {
+		return err
+	}
+
+	userRole := &UserRole{
+		UserID:     userID,
+		RoleID:     roleID,
+		AssignedBy: assignedBy,
+		ExpiresAt:  expiresAt,
+		IsActive:   true,
+	}
+	if err := s.repo.AssignRoleToUser(ctx, userRole); err != nil {
+		return fmt.Errorf("failed to assign role: %w", err)
+	}
+	s.cache.invalidate(ctx, userID)
+	return nil
+}
+
+// AssignRolesToUser assigns several roles to a user and reports the outcome per role.
+// In atomic mode no role is assigned unless every role can be.
+func (s *service) AssignRolesToUser(ctx context.Context, userID uint, req *AssignRolesRequest, assignedBy uint) (*batch.Result, error) {
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, ErrExpirationInPast
+	}
+
+	result := batch.New(len(req.RoleIDs))
+
+	var valid []*UserRole
+	seen := make(map[uint]bool, len(req.RoleIDs))
+	for _, roleID := range req.RoleIDs {
+		if seen[roleID] {
+			continue
+		}
+		seen[roleID] = true
+
+		if err := s.checkAssignable(ctx, userID, roleID); err != nil {
+			failAssignment(result, roleID, err)
+			continue
+		}
+		valid = append(valid, &UserRole{
+			UserID:     userID,
+			RoleID:     roleID,
+			AssignedBy: assignedBy,
+			ExpiresAt:  req.ExpiresAt,
+			IsActive:   true,
+		})
+	}
+
+	if req.Atomic {
+		if result.Summary.Failed > 0 {
+			for _, userRole := range valid {
+				result.Fail(userRole.RoleID, AssignFailureAborted, "not assigned because another role in the request failed")
+			}
+			return result, nil
+		}
+		if err := s.repo.AssignRolesToUser(ctx, valid); err != nil {
+			return nil, fmt.Errorf("failed to assign roles: %w", err)
+		}
+		s.cache.invalidate(ctx, userID)
+		for _, userRole := range valid {
+			result.Succeed(userRole.RoleID)
+		}
+		return result, nil
+	}
+
+	for _, userRole := range valid {
+		if err := s.repo.AssignRoleToUser(ctx, userRole); err != nil {
+			failAssignment(result, userRole.RoleID, err)
+			continue
+		}
+		result.Succeed(userRole.RoleID)
+	}
+	if result.Summary.Succeeded > 0 {
+		s.cache.invalidate(ctx, userID)
+	}
+	return result, nil
+}
+
+// checkAssignable verifies the role exists and is not already assigned to the user
+func (s *service) checkAssignable(ctx context.Context, userID, roleID uint) error {
+	if _, err := s.repo.GetRoleByID(ctx, roleID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRoleNotFound
+		}
+		return fmt.Errorf("failed to get role: %w", err)
+	}
+
+	exists, err := s.repo.UserRoleExists(ctx, userID, roleID)
+	if err != nil {
+		return fmt.Errorf("failed to check existing role: %w", err)
+	}
+	if exists {
+		return ErrRoleAlreadyAssigned
+	}
+	return nil
+}
+
+// failAssignment classifies an assignment error and records it as the role's failed outcome
+func failAssignment(result *batch.Result, roleID uint, err error) {
+	code := AssignFailureInternal
+	switch {
+	case errors.Is(err, ErrRoleNotFound):
+		code = AssignFailureNotFound
+	case errors.Is(err, ErrRoleAlreadyAssigned):
+		code = AssignFailureAlreadyAssigned
+	}
+	result.Fail(roleID, code, err.Error())
+}
+
+// RemoveRoleFromUser removes a global role from a user and records the change in the audit log
+func (s *service) RemoveRoleFromUser(ctx context.Context, userID, roleID, removedBy uint) error {
+	if err := s.repo.RemoveRoleFromUser(ctx, userID, roleID, removedBy); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRoleAssignmentNotFound
+		}
+		return fmt.Errorf("failed to remove role: %w", err)
+	}
+	s.cache.invalidate(ctx, userID)
+	return nil
+}
+
+// AssignPermissionsToRole grants permissions to a role and records the change in the audit log
+func (s *service) AssignPermissionsToRole(ctx context.Context, roleID uint, permissionIDs []uint, assignedBy uint) error {
+	if _, err := s.repo.GetRoleByID(ctx, roleID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRoleNotFound
+		}
+		return fmt.Errorf("failed to get role: %w", err)
+	}
+
+	if err := s.repo.AssignPermissionsToRole(ctx, roleID, permissionIDs, assignedBy); err != nil {
+		var notFound *PermissionsNotFoundError
+		if errors.As(err, &notFound) {
+			return notFound
+		}
+		return fmt.Errorf("failed to assign permissions: %w", err)
+	}
+	s.invalidateRoleHolders(ctx, roleID)
+	return nil
+}
+
+// RemovePermissionsFromRole revokes permissions from a role and records the change in the audit log
+func (s *service) RemovePermissionsFromRole(ctx context.Context, roleID uint, permissionIDs []uint, removedBy uint) error {
+	if _, err := s.repo.GetRoleByID(ctx, roleID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRoleNotFound
+		}
+		return fmt.Errorf("failed to get role: %w", err)
+	}
+
+	if err := s.repo.RemovePermissionsFromRole(ctx, roleID, permissionIDs, removedBy); err != nil {
+		return fmt.Errorf("failed to remove permissions: %w", err)
+	}
+	s.invalidateRoleHolders(ctx, roleID)
+	return nil
+}
+
+// PreviewRolePermissionChange reports which holders of the role would gain or lose effective
+// permissions if the permissions were assigned to or removed from it, without changing anything.
+// Users who keep a permission through another global role are not affected by it.
+func (s *service) PreviewRolePermissionChange(ctx context.Context, roleID uint, req *RolePermissionChangeRequest) (*RolePermissionChangePreview, error) {
+	if _, err := s.repo.GetRoleByID(ctx, roleID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+
+	permissions, err := s.repo.GetPermissionsByIDs(ctx, req.PermissionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get permissions: %w", err)
+	}
+	found := make([]uint, 0, len(permissions))
+	for _, permission := range permissions {
+		found = append(found, permission.ID)
+	}
+	if missing := missingIDs(req.PermissionIDs, found); len(missing) > 0 {
+		return nil, &PermissionsNotFoundError{IDs: missing}
+	}
+
+	grantedIDs, err := s.repo.GetPermissionIDsByRoleID(ctx, roleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role permissions: %w", err)
+	}
+	granted := make(map[uint]bool, len(grantedIDs))
+	for _, id := range grantedIDs {
+		granted[id] = true
+	}
+
+	// Only active permissions the role would actually gain or lose change anything
+	preview := &RolePermissionChangePreview{
+		RoleID:        roleID,
+		Operation:     req.Operation,
+		Permissions:   []string{},
+		AffectedUsers: []AffectedUser{},
+	}
+	changed := make(map[uint]string)
+	var changedIDs []uint
+	for _, permission := range permissions {
+		if permission.Status != 1 || granted[permission.ID] == (req.Operation == PermissionChangeAssign) {
+			continue
+		}
+		changed[permission.ID] = permission.Name
+		changedIDs = append(changedIDs, permission.ID)
+		preview.Permissions = append(preview.Permissions, permission.Name)
+	}
+	if len(changedIDs) == 0 {
+		return preview, nil
+	}
+
+	holderIDs, err := s.repo.ListRoleHolderIDs(ctx, roleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role holders: %w", err)
+	}
+	elsewhere, err := s.repo.ListPermissionsGrantedByOtherRoles(ctx, roleID, changedIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get permissions from other roles: %w", err)
+	}
+
+	for _, userID := range holderIDs {
+		kept := make(map[uint]bool, len(elsewhere[userID]))
+		for _, id := range elsewhere[userID] {
+			kept[id] = true
+		}
+
+		var names []string
+		for _, id := range changedIDs {
+			if !kept[id] {
+				names = append(names, changed[id])
+			}
+		}
+		if len(names) == 0 {
+			continue
+		}
+
+		_, cached := s.cache.get(ctx, userID)
+		preview.AffectedUsers = append(preview.AffectedUsers, AffectedUser{
+			UserID:      userID,
+			Permissions: names,
+			Cached:      cached,
+		})
+	}
+	return preview, nil
+}
+
+// SetOrganizationRoleActive deactivates or reactivates a user's organization role without removing it
+func (s *service) SetOrganizationRoleActive(ctx context.Context, organizationID, userID, roleID uint, active bool, actorID uint) error {
+	if err := s.repo.SetOrganizationRoleActive(ctx, organizationID, userID, roleID, active, actorID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrOrganizationRoleAssignmentNotFound
+		}
+		return fmt.Errorf("failed to update organization role: %w", err)
+	}
+	s.orgCache.invalidate(ctx, organizationID, userID)
+	return nil
+}
+
+// BulkAssignOrganizationRole grants one organization role to many users in a single transaction.
+// Users who already hold the role are skipped and users who are not active members of the
+// organization are reported as failed. Unknown user IDs reject the whole request before anything is written.
+func (s *service) BulkAssignOrganizationRole(ctx context.Context, req *BulkAssignOrganizationRoleRequest, assignedBy uint) (*batch.Result, error) {
+	if _, err := s.repo.GetRoleByID(ctx, req.RoleID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+
+	exists, err := s.repo.OrganizationExists(ctx, req.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+	if !exists {
+		return nil, ErrOrganizationNotFound
+	}
+
+	userIDs := make([]uint, 0, len(req.UserIDs))
+	seen := make(map[uint]bool, len(req.UserIDs))
+	for _, userID := range req.UserIDs {
+		if !seen[userID] {
+			seen[userID] = true
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	result, err := s.repo.BulkAssignOrganizationRole(ctx, req.OrganizationID, req.RoleID, userIDs, assignedBy)
+	if err != nil {
+		var notFound *UsersNotFoundError
+		if errors.As(err, &notFound) {
+			return nil, notFound
+		}
+		return nil, fmt.Errorf("failed to assign organization role: %w", err)
+	}
+	s.orgCache.invalidate(ctx, req.OrganizationID, userIDs...)
+	return result, nil
+}
+
+// SetTeamRoleActive deactivates or reactivates a user's team role without removing it
+func (s *service) SetTeamRoleActive(ctx context.Context, teamID, userID, roleID uint, active bool, actorID uint) error {
+	if err := s.repo.SetTeamRoleActive(ctx, teamID, userID, roleID, active, actorID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTeamRoleAssignmentNotFound
+		}
+		return fmt.Errorf("failed to update team role: %w", err)
+	}
+	return nil
+}
+
+// ListAuditLogs retrieves authorization audit logs with pagination
+func (s *service) ListAuditLogs(ctx context.Context, query *AuditLogQuery) (*AuditLogListResponse, error) {
+	query.Page, query.PageSize = pagination.Normalize(query.Page, query.PageSize, pagination.DefaultPageSize)
+
+	logs, total, err := s.repo.ListAuditLogs(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+
+	responses := make([]AuditLogResponse, 0, len(logs))
+	for _, log := range logs {
+		responses = append(responses, ToAuditLogResponse(log))
+	}
+
+	result := &AuditLogListResponse{
+		Logs:       responses,
+		Total:      total,
+		Page:       query.Page,
+		PageSize:   query.PageSize,
+		TotalPages: int((total + int64(query.PageSize) - 1) / int64(query.PageSize)),
+	}
+	if len(logs) == query.PageSize {
+		nextCursor := logs[len(logs)-1].ID
+		result.NextCursor = &nextCursor
+	}
+	return result, nil
+}
+
+// normalizeListQuery applies pagination defaults and limits
+func normalizeListQuery(query *ListQuery) {
+	query.Page, query.PageSize = pagination.Normalize(query.Page, query.PageSize, pagination.DefaultPageSize)
+}
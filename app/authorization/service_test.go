@@ -0,0 +1,414 @@
+package authorization
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/llamacto/llama-gin-kit/pkg/dbtest"
+	"gorm.io/gorm"
+)
+
+// roleMember is an organization member row as seen by the role deletion check
+type roleMember struct {
+	roleID  uint
+	removed bool
+}
+
+// roleRepository is an in-memory Repository covering role deletion; other methods are
+// left to the embedded nil interface and panic if called
+type roleRepository struct {
+	Repository
+	roles   map[uint]*Role
+	members []roleMember
+	deleted []uint
+}
+
+func (r *roleRepository) GetRoleByID(ctx context.Context, id uint) (*Role, error) {
+	role, ok := r.roles[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return role, nil
+}
+
+func (r *roleRepository) CountRoleMembers(ctx context.Context, roleID uint) (int64, error) {
+	var count int64
+	for _, m := range r.members {
+		if m.roleID == roleID && !m.removed {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *roleRepository) DeleteRole(ctx context.Context, id, deletedBy uint) error {
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+func (r *roleRepository) ListRoleHolderIDs(ctx context.Context, roleID uint) ([]uint, error) {
+	return nil, nil
+}
+
+func TestDeleteRole(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    *Role
+		members []roleMember
+		wantErr error
+	}{
+		{name: "unused role", role: &Role{ID: 1}},
+		{
+			name:    "role held by an active member",
+			role:    &Role{ID: 1},
+			members: []roleMember{{roleID: 1}, {roleID: 1, removed: true}},
+			wantErr: ErrRoleInUse,
+		},
+		{
+			name:    "role whose only members were removed",
+			role:    &Role{ID: 1},
+			members: []roleMember{{roleID: 1, removed: true}, {roleID: 1, removed: true}},
+		},
+		{
+			name:    "members of other roles",
+			role:    &Role{ID: 1},
+			members: []roleMember{{roleID: 2}},
+		},
+		{name: "system role", role: &Role{ID: 1, IsSystem: true}, wantErr: ErrSystemRoleProtected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &roleRepository{roles: map[uint]*Role{tt.role.ID: tt.role}, members: tt.members}
+			err := NewService(repo).DeleteRole(context.Background(), tt.role.ID, 99)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DeleteRole() error = %v, want %v", err, tt.wantErr)
+			}
+			if deleted := len(repo.deleted) == 1; deleted != (tt.wantErr == nil) {
+				t.Fatalf("role deleted = %v, want %v", deleted, tt.wantErr == nil)
+			}
+		})
+	}
+}
+
+func TestDeleteRoleNotFound(t *testing.T) {
+	repo := &roleRepository{roles: map[uint]*Role{}}
+	if err := NewService(repo).DeleteRole(context.Background(), 1, 99); !errors.Is(err, ErrRoleNotFound) {
+		t.Fatalf("DeleteRole() error = %v, want %v", err, ErrRoleNotFound)
+	}
+}
+
+// newTestService returns a service over repo with caches of its own, so tests do not share
+// cached permissions through the package-level stores
+func newTestService(repo Repository) *service {
+	return &service{
+		repo:      repo,
+		cache:     newPermissionCache(),
+		orgCache:  newOrgPermissionCache(),
+		roleCache: &rolePermissionCache{entries: make(map[uint]*rolePermissions)},
+	}
+}
+
+// assignment is an organization or team role assignment row
+type assignment struct {
+	scopeID uint // Organization or team ID
+	userID  uint
+	roleID  uint
+	active  bool
+}
+
+// assignmentRepository is an in-memory Repository covering organization and team role
+// assignments and the permission checks that read them
+type assignmentRepository struct {
+	Repository
+	orgRoles        []*assignment
+	teamRoles       []*assignment
+	rolePermissions map[uint][]string
+	teams           map[uint]*TeamNode // When nil, every team is a root without inheritance
+}
+
+func (r *assignmentRepository) GetUserRoles(ctx context.Context, userID uint) ([]*UserRole, error) {
+	return nil, nil
+}
+
+func (r *assignmentRepository) GetPermissionNamesGroupedByRoleIDs(ctx context.Context, roleIDs []uint) (map[uint][]string, error) {
+	names := make(map[uint][]string, len(roleIDs))
+	for _, roleID := range roleIDs {
+		names[roleID] = r.rolePermissions[roleID]
+	}
+	return names, nil
+}
+
+func (r *assignmentRepository) GetUserOrganizationRoleIDs(ctx context.Context, userID, organizationID uint) ([]uint, error) {
+	return activeRoleIDs(r.orgRoles, userID, organizationID), nil
+}
+
+func (r *assignmentRepository) GetTeamNode(ctx context.Context, teamID uint) (*TeamNode, error) {
+	if r.teams == nil {
+		return &TeamNode{ID: teamID}, nil
+	}
+	node, ok := r.teams[teamID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return node, nil
+}
+
+func (r *assignmentRepository) GetUserTeamRoleIDs(ctx context.Context, userID uint, teamIDs []uint) ([]uint, error) {
+	var ids []uint
+	for _, teamID := range teamIDs {
+		ids = append(ids, activeRoleIDs(r.teamRoles, userID, teamID)...)
+	}
+	return ids, nil
+}
+
+func (r *assignmentRepository) SetOrganizationRoleActive(ctx context.Context, organizationID, userID, roleID uint, active bool, actorID uint) error {
+	return setActive(r.orgRoles, organizationID, userID, roleID, active)
+}
+
+func (r *assignmentRepository) SetTeamRoleActive(ctx context.Context, teamID, userID, roleID uint, active bool, actorID uint) error {
+	return setActive(r.teamRoles, teamID, userID, roleID, active)
+}
+
+func activeRoleIDs(rows []*assignment, userID, scopeID uint) []uint {
+	var ids []uint
+	for _, row := range rows {
+		if row.userID == userID && row.scopeID == scopeID && row.active {
+			ids = append(ids, row.roleID)
+		}
+	}
+	return ids
+}
+
+func setActive(rows []*assignment, scopeID, userID, roleID uint, active bool) error {
+	for _, row := range rows {
+		if row.scopeID == scopeID && row.userID == userID && row.roleID == roleID {
+			row.active = active
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func TestDeactivatedRoleAssignmentGrantsNothing(t *testing.T) {
+	const (
+		scopeID = 10
+		userID  = 20
+		roleID  = 30
+	)
+
+	tests := []struct {
+		name      string
+		orgScoped bool
+	}{
+		{name: "organization role", orgScoped: true},
+		{name: "team role"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := &assignment{scopeID: scopeID, userID: userID, roleID: roleID, active: true}
+			repo := &assignmentRepository{rolePermissions: map[uint][]string{roleID: {"members.update"}}}
+			if tt.orgScoped {
+				repo.orgRoles = []*assignment{row}
+			} else {
+				repo.teamRoles = []*assignment{row}
+			}
+			s := newTestService(repo)
+			ctx := context.Background()
+
+			setActive := func(active bool) {
+				t.Helper()
+				var err error
+				if tt.orgScoped {
+					err = s.SetOrganizationRoleActive(ctx, scopeID, userID, roleID, active, 1)
+				} else {
+					err = s.SetTeamRoleActive(ctx, scopeID, userID, roleID, active, 1)
+				}
+				if err != nil {
+					t.Fatalf("set active %v: %v", active, err)
+				}
+			}
+			allowed := func() bool {
+				t.Helper()
+				var ok bool
+				var err error
+				if tt.orgScoped {
+					ok, err = s.CheckOrganizationPermission(ctx, userID, scopeID, "members.update")
+				} else {
+					ok, err = s.CheckUserTeamPermission(ctx, userID, scopeID, "members.update")
+				}
+				if err != nil {
+					t.Fatalf("check permission: %v", err)
+				}
+				return ok
+			}
+
+			if !allowed() {
+				t.Fatal("active assignment does not grant its permission")
+			}
+
+			setActive(false)
+			if allowed() {
+				t.Fatal("deactivated assignment still grants its permission")
+			}
+			if len(repo.orgRoles)+len(repo.teamRoles) != 1 || row.active {
+				t.Fatalf("deactivation should keep the row inactive, got %+v", row)
+			}
+
+			setActive(true)
+			if !allowed() {
+				t.Fatal("reactivated assignment does not grant its permission")
+			}
+		})
+	}
+}
+
+func TestTeamPermissionsInheritFromParentTeams(t *testing.T) {
+	const (
+		userID     = 20
+		roleID     = 30
+		root       = 1 // Team IDs: root > child > grandchild
+		child      = 2
+		grandchild = 3
+	)
+	parent := func(id uint) *uint { return &id }
+	hierarchy := func(inherit bool) map[uint]*TeamNode {
+		return map[uint]*TeamNode{
+			root:       {ID: root, InheritTeamPermissions: inherit},
+			child:      {ID: child, ParentTeamID: parent(root), InheritTeamPermissions: inherit},
+			grandchild: {ID: grandchild, ParentTeamID: parent(child), InheritTeamPermissions: inherit},
+			// A corrupt cycle that the walk must not follow forever
+			4: {ID: 4, ParentTeamID: parent(5), InheritTeamPermissions: inherit},
+			5: {ID: 5, ParentTeamID: parent(4), InheritTeamPermissions: inherit},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		inherit   bool
+		grantedOn uint
+		checkOn   uint
+		want      bool
+	}{
+		{name: "parent grant reaches a child", inherit: true, grantedOn: root, checkOn: child, want: true},
+		{name: "parent grant reaches a grandchild", inherit: true, grantedOn: root, checkOn: grandchild, want: true},
+		{name: "child grant does not reach the parent", inherit: true, grantedOn: child, checkOn: root},
+		{name: "inheritance disabled", grantedOn: root, checkOn: child},
+		{name: "inheritance disabled keeps direct grants", grantedOn: child, checkOn: child, want: true},
+		{name: "cyclic hierarchy", inherit: true, grantedOn: root, checkOn: 4},
+		{name: "unknown team", inherit: true, grantedOn: root, checkOn: 99},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &assignmentRepository{
+				teamRoles:       []*assignment{{scopeID: tt.grantedOn, userID: userID, roleID: roleID, active: true}},
+				rolePermissions: map[uint][]string{roleID: {"members.update"}},
+				teams:           hierarchy(tt.inherit),
+			}
+
+			allowed, err := newTestService(repo).CheckUserTeamPermission(context.Background(), userID, tt.checkOn, "members.update")
+			if err != nil {
+				t.Fatalf("CheckUserTeamPermission() error = %v", err)
+			}
+			if allowed != tt.want {
+				t.Fatalf("CheckUserTeamPermission() = %v, want %v", allowed, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetRoleActiveUnknownAssignment(t *testing.T) {
+	s := newTestService(&assignmentRepository{})
+	ctx := context.Background()
+
+	if err := s.SetOrganizationRoleActive(ctx, 1, 2, 3, false, 1); !errors.Is(err, ErrOrganizationRoleAssignmentNotFound) {
+		t.Fatalf("SetOrganizationRoleActive() error = %v, want %v", err, ErrOrganizationRoleAssignmentNotFound)
+	}
+	if err := s.SetTeamRoleActive(ctx, 1, 2, 3, false, 1); !errors.Is(err, ErrTeamRoleAssignmentNotFound) {
+		t.Fatalf("SetTeamRoleActive() error = %v, want %v", err, ErrTeamRoleAssignmentNotFound)
+	}
+}
+
+func TestGetPermissionCatalog(t *testing.T) {
+	sharedCatalogCache.invalidate()
+	t.Cleanup(sharedCatalogCache.invalidate)
+
+	gormDB, db := dbtest.Open(t)
+	// The database returns rows ordered by category and name, as the query asks
+	db.Returns(`FROM "permissions"`,
+		[]string{"id", "name", "display_name", "description", "resource", "action", "category", "is_system", "status"},
+		[]driver.Value{int64(4), "users.create", "Create users", "", "users", "create", "", false, int64(1)},
+		[]driver.Value{int64(1), "roles.read", "Read roles", "View roles", "roles", "read", "authorization", true, int64(1)},
+		[]driver.Value{int64(2), "roles.update", "Update roles", "Edit roles", "roles", "update", "authorization", true, int64(1)},
+		[]driver.Value{int64(3), "teams.read", "Read teams", "", "teams", "read", "organization", false, int64(1)},
+	)
+	svc := newTestService(NewRepository(gormDB))
+
+	catalog, err := svc.GetPermissionCatalog(context.Background())
+	if err != nil {
+		t.Fatalf("GetPermissionCatalog() error = %v", err)
+	}
+
+	// Inactive and deleted permissions are filtered out by the query
+	stmt, ok := db.Find(`FROM "permissions"`)
+	if !ok {
+		t.Fatalf("no permissions query: %v", db.Statements())
+	}
+	if !strings.Contains(stmt.SQL, "status = $1") || stmt.Args[0] != int64(1) {
+		t.Fatalf("catalog query = %s %v, want it limited to status 1", stmt.SQL, stmt.Args)
+	}
+	if !strings.Contains(stmt.SQL, `"permissions"."deleted_at" IS NULL`) {
+		t.Fatalf("catalog query = %s, want it to skip deleted permissions", stmt.SQL)
+	}
+
+	type entry struct {
+		category string
+		names    []string
+	}
+	want := []entry{
+		{category: "authorization", names: []string{"roles.read", "roles.update"}},
+		{category: "general", names: []string{"users.create"}},
+		{category: "organization", names: []string{"teams.read"}},
+	}
+	if catalog.Total != 4 || len(catalog.Categories) != len(want) {
+		t.Fatalf("catalog = %+v, want %d categories and 4 permissions", catalog, len(want))
+	}
+	for i, w := range want {
+		got := catalog.Categories[i]
+		if got.Name != w.category || len(got.Permissions) != len(w.names) {
+			t.Fatalf("category %d = %+v, want %s with %v", i, got, w.category, w.names)
+		}
+		for j, name := range w.names {
+			if got.Permissions[j].Name != name {
+				t.Fatalf("category %s permission %d = %s, want %s", w.category, j, got.Permissions[j].Name, name)
+			}
+		}
+	}
+	if p := catalog.Categories[0].Permissions[0]; p.DisplayName != "Read roles" || p.Description != "View roles" ||
+		p.Resource != "roles" || p.Action != "read" || !p.IsSystem {
+		t.Fatalf("permission metadata = %+v, want the row's display name, description, resource, action and system flag", p)
+	}
+
+	// A second call within the TTL is served from the cache
+	if _, err := svc.GetPermissionCatalog(context.Background()); err != nil {
+		t.Fatalf("second GetPermissionCatalog() error = %v", err)
+	}
+	if n := len(selects(db, "permissions")); n != 1 {
+		t.Fatalf("%d catalog queries, want 1 while cached", n)
+	}
+
+	// Invalidation forces a reload
+	sharedCatalogCache.invalidate()
+	if _, err := svc.GetPermissionCatalog(context.Background()); err != nil {
+		t.Fatalf("GetPermissionCatalog() after invalidation error = %v", err)
+	}
+	if n := len(selects(db, "permissions")); n != 2 {
+		t.Fatalf("%d catalog queries, want 2 after invalidation", n)
+	}
+}
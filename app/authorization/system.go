@@ -0,0 +1,68 @@
+package authorization
+
+// superAdminRole is the built-in role that is granted every permission
+const superAdminRole = "super_admin"
+
+// systemRoles returns the built-in roles created by InitializeSystemRoles
+func systemRoles() []*Role {
+	return []*Role{
+		{Name: superAdminRole, DisplayName: "Super Admin", Description: "Full access to every resource", Level: 100, IsSystem: true, Status: 1},
+		{Name: "admin", DisplayName: "Admin", Description: "Manages users, roles and organizations", Level: 80, IsSystem: true, Status: 1},
+		{Name: "member", DisplayName: "Member", Description: "Default role for organization members", Level: 10, IsSystem: true, Status: 1},
+	}
+}
+
+// systemPermissions returns the built-in permissions created by InitializeSystemPermissions
+func systemPermissions() []*Permission {
+	crud := []string{"create", "read", "update", "delete"}
+	resources := []struct {
+		name     string
+		label    string
+		category string
+		actions  []string
+	}{
+		{"users", "Users", "users", crud},
+		{"roles", "Roles", "authorization", append(crud, "assign")},
+		{"permissions", "Permissions", "authorization", []string{"read", "assign"}},
+		{"audit_logs", "Audit Logs", "authorization", []string{"read"}},
+		{"organizations", "Organizations", "organizations", crud},
+		{"teams", "Teams", "organizations", crud},
+		{"members", "Members", "organizations", crud},
+		{"invitations", "Invitations", "organizations", crud},
+		{"tts", "Text to Speech", "tts", []string{"generate", "translate"}},
+	}
+
+	var permissions []*Permission
+	for _, resource := range resources {
+		for _, action := range resource.actions {
+			permissions = append(permissions, &Permission{
+				Name:        resource.name + "." + action,
+				DisplayName: capitalize(action) + " " + resource.label,
+				Resource:    resource.name,
+				Action:      action,
+				Category:    resource.category,
+				IsSystem:    true,
+				Status:      1,
+			})
+		}
+	}
+	return permissions
+}
+
+// IsSystemPermission reports whether name is a built-in permission, e.g. "users.read"
+func IsSystemPermission(name string) bool {
+	for _, permission := range systemPermissions() {
+		if permission.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// capitalize upper-cases the first letter of an ASCII word
+func capitalize(word string) string {
+	if word == "" {
+		return word
+	}
+	return string(word[0]-'a'+'A') + word[1:]
+}
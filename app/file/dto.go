@@ -0,0 +1,9 @@
+package file
+
+// UploadResponse describes a stored file
+type UploadResponse struct {
+	Key         string `json:"key"`          // Object key in the bucket, for later retrieval
+	URL         string `json:"url"`          // Public URL of the object
+	ContentType string `json:"content_type"` // Sniffed content type the object was stored with
+	Size        int64  `json:"size"`
+}
@@ -0,0 +1,83 @@
+package file
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/pkg/authctx"
+	"github.com/llamacto/llama-gin-kit/pkg/logger"
+	"github.com/llamacto/llama-gin-kit/pkg/response"
+	"github.com/llamacto/llama-gin-kit/pkg/storage"
+)
+
+// Handler defines the interface for file HTTP handlers
+type Handler interface {
+	Upload(c *gin.Context)
+}
+
+// handler implements the Handler interface
+type handler struct {
+	service Service
+	maxSize int64
+}
+
+// NewHandler creates a new file handler instance; maxSize caps the uploaded file in bytes
+func NewHandler(service Service, maxSize int64) Handler {
+	return &handler{service: service, maxSize: maxSize}
+}
+
+// Upload stores a file in object storage
+// @Summary Upload file
+// @Description Upload a file as multipart form field "file". The content type is detected from the file content and must be in UPLOAD_ALLOWED_MIME_TYPES; the size is capped by UPLOAD_MAX_SIZE. Returns the object key and its public URL
+// @Tags files
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "File to upload"
+// @Success 201 {object} response.Response{data=UploadResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 413 {object} response.Response
+// @Failure 415 {object} response.Response
+// @Failure 503 {object} response.Response
+// @Router /v1/files/upload [post]
+func (h *handler) Upload(c *gin.Context) {
+	userID, exists := authctx.UserID(c)
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxSize+storage.MultipartOverhead)
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			response.Error(c, http.StatusRequestEntityTooLarge, storage.ErrFileTooLarge.Error())
+			return
+		}
+		response.Error(c, http.StatusBadRequest, "A file is required in the \"file\" form field")
+		return
+	}
+	defer file.Close()
+
+	data, err := storage.ReadUpload(file, h.maxSize)
+	if err != nil {
+		response.Error(c, storage.UploadErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+
+	uploaded, err := h.service.Upload(c.Request.Context(), userID, data, header.Header.Get("Content-Type"))
+	if err != nil {
+		status := storage.UploadErrorStatus(err, http.StatusInternalServerError)
+		if status == http.StatusInternalServerError {
+			logger.Error("Failed to upload file", err)
+			response.Error(c, status, "Failed to upload file")
+			return
+		}
+		response.Error(c, status, err.Error())
+		return
+	}
+
+	response.Created(c, uploaded)
+}
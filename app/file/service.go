@@ -0,0 +1,61 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+
+	"github.com/google/uuid"
+	"github.com/llamacto/llama-gin-kit/config"
+	"github.com/llamacto/llama-gin-kit/pkg/storage"
+)
+
+// Uploader stores objects and builds their public URLs; storage.R2Client implements it
+type Uploader interface {
+	UploadFile(ctx context.Context, key string, reader io.Reader, contentType string) error
+	PublicURL(key string) string
+}
+
+// Service defines the interface for file business logic
+type Service interface {
+	Upload(ctx context.Context, userID uint, data []byte, declaredType string) (*UploadResponse, error)
+}
+
+// service implements the Service interface
+type service struct {
+	uploader Uploader
+	limits   config.UploadConfig
+}
+
+// NewService creates a new file service instance
+func NewService(uploader Uploader, limits config.UploadConfig) Service {
+	return &service{uploader: uploader, limits: limits}
+}
+
+// Upload validates data against the upload limits and stores it under a key scoped to the user.
+// The object is stored with its sniffed content type; the declared one must agree with it.
+func (s *service) Upload(ctx context.Context, userID uint, data []byte, declaredType string) (*UploadResponse, error) {
+	// Clients that don't know a file's type declare application/octet-stream; leave it to sniffing
+	if mediaType, _, err := mime.ParseMediaType(declaredType); err == nil && mediaType == "application/octet-stream" {
+		declaredType = ""
+	}
+
+	contentType, err := storage.ValidateUpload(data, declaredType, s.limits)
+	if err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("uploads/%d/%s%s", userID, uuid.New().String(), storage.ExtensionForType(contentType))
+	if err := s.uploader.UploadFile(ctx, key, bytes.NewReader(data), contentType); err != nil {
+		return nil, err
+	}
+
+	return &UploadResponse{
+		Key:         key,
+		URL:         s.uploader.PublicURL(key),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+	}, nil
+}
@@ -0,0 +1,171 @@
+package invitation
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/config"
+	apperrors "github.com/llamacto/llama-gin-kit/pkg/errors"
+	"github.com/llamacto/llama-gin-kit/pkg/logger"
+)
+
+const (
+	// EventInvitationAccepted is the event type sent to callback URLs when an invitation is accepted
+	EventInvitationAccepted = "invitation.accepted"
+	// CallbackSignatureHeader carries "sha256=<hex HMAC of the body>" when a callback secret is configured
+	CallbackSignatureHeader = "X-Invitation-Signature"
+
+	defaultCallbackTimeout = 10 * time.Second
+)
+
+var (
+	// ErrRedirectNotAllowed is returned when a redirect URL's host is not in the allowlist
+	ErrRedirectNotAllowed = apperrors.Validation("redirect_not_allowed", "redirect_url host is not allowed")
+	// ErrCallbackNotAllowed is returned when a callback URL's host is not in the allowlist
+	ErrCallbackNotAllowed = apperrors.Validation("callback_not_allowed", "callback_url host is not allowed")
+)
+
+// CallbackEvent is the JSON body posted to an invitation's callback URL
+type CallbackEvent struct {
+	Event          string    `json:"event"`
+	InvitationID   uint      `json:"invitation_id"`
+	OrganizationID uint      `json:"organization_id"`
+	TeamID         *uint     `json:"team_id"`
+	RoleID         uint      `json:"role_id"`
+	UserID         uint      `json:"user_id"`
+	Status         string    `json:"status"`
+	AcceptedAt     time.Time `json:"accepted_at"`
+}
+
+// checkURLAllowed reports whether rawURL is an absolute http(s) URL whose host matches the
+// allowlist. Entries match the host exactly or, as "*.example.com", any of its subdomains.
+func checkURLAllowed(rawURL string, allowedHosts []string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.User != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return false
+	}
+
+	for _, allowed := range allowedHosts {
+		allowed = strings.ToLower(allowed)
+		if suffix, ok := strings.CutPrefix(allowed, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+			continue
+		}
+		if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// validateURLs checks the request's redirect and callback URLs against the configured allowlists
+func validateURLs(req *CreateInvitationRequest, cfg config.InvitationConfig) error {
+	if req.RedirectURL != "" && !checkURLAllowed(req.RedirectURL, cfg.RedirectAllowedHosts) {
+		return ErrRedirectNotAllowed
+	}
+	if req.CallbackURL != "" && !checkURLAllowed(req.CallbackURL, cfg.CallbackAllowedHosts) {
+		return ErrCallbackNotAllowed
+	}
+	return nil
+}
+
+// invitationConfig returns the loaded invitation config, or an empty one that rejects all URLs
+func invitationConfig() config.InvitationConfig {
+	if config.GlobalConfig == nil {
+		return config.InvitationConfig{}
+	}
+	return config.GlobalConfig.Invitation
+}
+
+// notifyAccepted posts an invitation.accepted event to the invitation's callback URL in the
+// background. Failures are logged; they never affect the accept itself.
+func notifyAccepted(invitation *Invitation, userID uint, acceptedAt time.Time) {
+	if invitation.CallbackURL == "" {
+		return
+	}
+
+	event := CallbackEvent{
+		Event:          EventInvitationAccepted,
+		InvitationID:   invitation.ID,
+		OrganizationID: invitation.OrganizationID,
+		TeamID:         invitation.TeamID,
+		RoleID:         invitation.RoleID,
+		UserID:         userID,
+		Status:         statusText(StatusAccepted),
+		AcceptedAt:     acceptedAt,
+	}
+	cfg := invitationConfig()
+	callbackURL := invitation.CallbackURL
+
+	go func() {
+		if err := sendCallback(context.Background(), callbackURL, event, cfg); err != nil {
+			logger.Error("Failed to deliver invitation callback", err)
+		}
+	}()
+}
+
+// sendCallback posts event to callbackURL, signing the body when a secret is configured
+func sendCallback(ctx context.Context, callbackURL string, event CallbackEvent, cfg config.InvitationConfig) error {
+	// The allowlist may have changed since the invitation was created
+	if !checkURLAllowed(callbackURL, cfg.CallbackAllowedHosts) {
+		return ErrCallbackNotAllowed
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal callback event: %w", err)
+	}
+
+	timeout := cfg.CallbackTimeout
+	if timeout <= 0 {
+		timeout = defaultCallbackTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create callback request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if cfg.CallbackSecret != "" {
+		req.Header.Set(CallbackSignatureHeader, "sha256="+signCallback(body, cfg.CallbackSecret))
+	}
+
+	// Redirects are not followed so a callback cannot be bounced to a host outside the allowlist
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send callback: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("callback returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// signCallback returns the hex-encoded HMAC-SHA256 of body
+func signCallback(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,138 @@
+package invitation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/app/user"
+	"github.com/llamacto/llama-gin-kit/config"
+)
+
+func TestCheckURLAllowed(t *testing.T) {
+	allowed := []string{"app.example.com", "*.example.org"}
+
+	tests := []struct {
+		name string
+		url  string
+		want bool
+	}{
+		{name: "allowed host", url: "https://app.example.com/welcome", want: true},
+		{name: "allowed host with port", url: "http://app.example.com:8080/welcome", want: true},
+		{name: "host is case-insensitive", url: "https://APP.example.com/", want: true},
+		{name: "subdomain wildcard", url: "https://team.example.org/", want: true},
+		{name: "wildcard does not match the bare domain", url: "https://example.org/"},
+		{name: "unlisted host", url: "https://evil.com/"},
+		{name: "allowed name as a suffix of another host", url: "https://app.example.com.evil.com/"},
+		{name: "credentials before an allowed host", url: "https://app.example.com@evil.com/"},
+		{name: "user info on an allowed host", url: "https://user@app.example.com/"},
+		{name: "relative URL", url: "/welcome"},
+		{name: "scheme-relative URL", url: "//evil.com/"},
+		{name: "javascript URL", url: "javascript:alert(1)"},
+		{name: "malformed URL", url: "https://%zz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkURLAllowed(tt.url, allowed); got != tt.want {
+				t.Fatalf("checkURLAllowed(%q) = %v, want %v", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateURLs(t *testing.T) {
+	cfg := config.InvitationConfig{
+		RedirectAllowedHosts: []string{"app.example.com"},
+		CallbackAllowedHosts: []string{"hooks.example.com"},
+	}
+
+	tests := []struct {
+		name    string
+		req     CreateInvitationRequest
+		wantErr error
+	}{
+		{name: "no URLs"},
+		{name: "allowed URLs", req: CreateInvitationRequest{RedirectURL: "https://app.example.com/", CallbackURL: "https://hooks.example.com/invites"}},
+		{name: "open redirect", req: CreateInvitationRequest{RedirectURL: "https://evil.com/"}, wantErr: ErrRedirectNotAllowed},
+		{name: "callback on the redirect allowlist only", req: CreateInvitationRequest{CallbackURL: "https://app.example.com/hook"}, wantErr: ErrCallbackNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateURLs(&tt.req, cfg); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("validateURLs() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// receivedCallback is a callback request captured by a test server
+type receivedCallback struct {
+	event     CallbackEvent
+	signature string
+	body      []byte
+}
+
+func TestAcceptFiresCallback(t *testing.T) {
+	received := make(chan receivedCallback, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var event CallbackEvent
+		json.Unmarshal(body, &event)
+		received <- receivedCallback{event: event, signature: r.Header.Get(CallbackSignatureHeader), body: body}
+	}))
+	defer server.Close()
+
+	saved := config.GlobalConfig
+	t.Cleanup(func() { config.GlobalConfig = saved })
+	config.GlobalConfig = &config.Config{Invitation: config.InvitationConfig{
+		CallbackAllowedHosts: []string{"127.0.0.1"},
+		CallbackSecret:       "callback-secret",
+	}}
+
+	const invitee = uint(1)
+	repo := &invitationRepository{invitation: &Invitation{
+		ID: 5, Email: "dev@example.com", OrganizationID: 3, RoleID: 4, Token: "token", Status: StatusPending,
+		CallbackURL: server.URL + "/invites",
+	}}
+	users := &userRepository{users: map[uint]*user.User{invitee: {ID: invitee, Email: "dev@example.com"}}}
+
+	if _, err := NewService(repo, users, nil).ProcessInvitation(context.Background(), "token", invitee); err != nil {
+		t.Fatalf("ProcessInvitation() error = %v", err)
+	}
+
+	select {
+	case got := <-received:
+		want := CallbackEvent{Event: EventInvitationAccepted, InvitationID: 5, OrganizationID: 3, RoleID: 4, UserID: invitee, Status: "accepted"}
+		got.event.AcceptedAt = time.Time{}
+		if got.event != want {
+			t.Fatalf("callback event = %+v, want %+v", got.event, want)
+		}
+		if wantSig := "sha256=" + signCallback(got.body, "callback-secret"); got.signature != wantSig {
+			t.Fatalf("signature = %q, want %q", got.signature, wantSig)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no callback received after accept")
+	}
+}
+
+func TestSendCallbackRechecksAllowlist(t *testing.T) {
+	hit := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { hit = true }))
+	defer server.Close()
+
+	cfg := config.InvitationConfig{CallbackAllowedHosts: []string{"hooks.example.com"}}
+	err := sendCallback(context.Background(), server.URL, CallbackEvent{Event: EventInvitationAccepted}, cfg)
+	if !errors.Is(err, ErrCallbackNotAllowed) {
+		t.Fatalf("sendCallback() error = %v, want %v", err, ErrCallbackNotAllowed)
+	}
+	if hit {
+		t.Fatal("callback sent to a host no longer on the allowlist")
+	}
+}
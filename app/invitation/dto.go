@@ -1,11 +1,29 @@
 package invitation
 
+import (
+	"fmt"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/pkg/pagination"
+)
+
+const (
+	// DefaultExpiresInHours is the invitation lifetime used when none is requested (7 days)
+	DefaultExpiresInHours = 168
+	// MaxExpiresInHours is the longest invitation lifetime a caller may request (30 days)
+	MaxExpiresInHours = 720
+)
+
 // CreateInvitationRequest represents the request payload for creating an invitation
 type CreateInvitationRequest struct {
 	Email          string `json:"email" binding:"required,email"`
 	OrganizationID uint   `json:"organization_id" binding:"required"`
 	TeamID         *uint  `json:"team_id"`
 	RoleID         uint   `json:"role_id" binding:"required"`
+	ExpiresInHours int    `json:"expires_in_hours" binding:"omitempty,max=720"` // Optional, defaults to 168
+	RedirectURL    string `json:"redirect_url" binding:"omitempty,url,max=500"` // Optional, host must be allowlisted
+	CallbackURL    string `json:"callback_url" binding:"omitempty,url,max=500"` // Optional, notified when the invitation is accepted
+	Resend         bool   `json:"resend"`                                       // Resend the pending invitation for this email instead of failing
 }
 
 // BatchInvitationRequest represents the request payload for batch invitations
@@ -14,6 +32,19 @@ type BatchInvitationRequest struct {
 	OrganizationID uint     `json:"organization_id" binding:"required"`
 	TeamID         *uint    `json:"team_id"`
 	RoleID         uint     `json:"role_id" binding:"required"`
+	ExpiresInHours int      `json:"expires_in_hours" binding:"omitempty,max=720"` // Optional, defaults to 168
+}
+
+// ExpiresAt calculates the invitation expiry relative to now.
+// Zero or negative hours fall back to DefaultExpiresInHours.
+func ExpiresAt(now time.Time, expiresInHours int) (time.Time, error) {
+	if expiresInHours <= 0 {
+		expiresInHours = DefaultExpiresInHours
+	}
+	if expiresInHours > MaxExpiresInHours {
+		return time.Time{}, fmt.Errorf("expires_in_hours must not exceed %d", MaxExpiresInHours)
+	}
+	return now.Add(time.Duration(expiresInHours) * time.Hour), nil
 }
 
 // AcceptInvitationRequest represents the request payload for accepting an invitation
@@ -40,14 +71,38 @@ type InvitationResponse struct {
 	InvitedBy        uint   `json:"invited_by"`
 	InviterName      string `json:"inviter_name"`
 	InviterEmail     string `json:"inviter_email"`
-	Token            string `json:"token"`
+	Token            string `json:"token,omitempty"` // Only in the response to the inviter who created the invitation
 	ExpiresAt        string `json:"expires_at"`
 	Status           int    `json:"status"`
 	StatusText       string `json:"status_text"`
+	RedirectURL      string `json:"redirect_url,omitempty"`
 	CreatedAt        string `json:"created_at"`
 	UpdatedAt        string `json:"updated_at"`
 }
 
+// InvitationQuery represents filter, sort and pagination parameters for listing invitations
+type InvitationQuery struct {
+	Page            int    `form:"page,default=1"`
+	PageSize        int    `form:"page_size,default=20"`
+	Status          *int   `form:"status"`           // Exact status; when unset only live invitations are listed
+	IncludeInactive bool   `form:"include_inactive"` // Also list rejected, expired and lapsed invitations
+	Email           string `form:"email"`            // Case-insensitive substring match
+	RoleID          *uint  `form:"role_id"`
+	OrderBy         string `form:"order_by,default=created_at"` // created_at or expires_at
+	Order           string `form:"order,default=desc"`
+}
+
+// invitationSort lists the columns invitations may be sorted by
+var invitationSort = pagination.Sort{
+	Columns: []string{"created_at", "expires_at"},
+	Default: "created_at",
+}
+
+// Normalize replaces a disallowed order_by or order with the default sort
+func (q *InvitationQuery) Normalize() {
+	q.OrderBy, q.Order = invitationSort.Normalize(q.OrderBy, q.Order)
+}
+
 // InvitationListResponse represents the response structure for invitation list
 type InvitationListResponse struct {
 	Invitations []InvitationResponse `json:"invitations"`
@@ -66,22 +121,45 @@ type InvitationStatsResponse struct {
 	Expired  int64 `json:"expired"`
 }
 
-// BatchInvitationResponse represents the response structure for batch invitation results
-type BatchInvitationResponse struct {
-	Success []InvitationResponse `json:"success"`
-	Failed  []BatchFailedResult  `json:"failed"`
-	Summary BatchSummary         `json:"summary"`
-}
-
-// BatchFailedResult represents a failed invitation in batch operation
-type BatchFailedResult struct {
-	Email  string `json:"email"`
-	Reason string `json:"reason"`
+// statusText returns a human readable name for an invitation status
+func statusText(status int) string {
+	switch status {
+	case StatusPending:
+		return "pending"
+	case StatusAccepted:
+		return "accepted"
+	case StatusRejected:
+		return "rejected"
+	case StatusExpired:
+		return "expired"
+	default:
+		return "unknown"
+	}
 }
 
-// BatchSummary represents summary of batch invitation operation
-type BatchSummary struct {
-	Total     int `json:"total"`
-	Succeeded int `json:"succeeded"`
-	Failed    int `json:"failed"`
+// toInvitationResponse converts an InvitationWithDetails query row to an InvitationResponse
+func toInvitationResponse(inv *InvitationWithDetails) InvitationResponse {
+	resp := InvitationResponse{
+		ID:               inv.ID,
+		Email:            inv.Email,
+		OrganizationID:   inv.OrganizationID,
+		OrganizationName: inv.OrganizationName,
+		TeamID:           inv.TeamID,
+		RoleID:           inv.RoleID,
+		RoleName:         inv.RoleName,
+		RoleDisplayName:  inv.RoleDisplayName,
+		InvitedBy:        inv.InvitedBy,
+		InviterName:      inv.InviterName,
+		InviterEmail:     inv.InviterEmail,
+		ExpiresAt:        inv.ExpiresAt.Format(time.RFC3339),
+		Status:           inv.Status,
+		StatusText:       statusText(inv.Status),
+		RedirectURL:      inv.RedirectURL,
+		CreatedAt:        inv.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:        inv.UpdatedAt.Format(time.RFC3339),
+	}
+	if inv.TeamName != nil {
+		resp.TeamName = *inv.TeamName
+	}
+	return resp
 }
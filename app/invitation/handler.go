@@ -0,0 +1,198 @@
+package invitation
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/pkg/authctx"
+	"github.com/llamacto/llama-gin-kit/pkg/pagination"
+	"github.com/llamacto/llama-gin-kit/pkg/params"
+	"github.com/llamacto/llama-gin-kit/pkg/response"
+)
+
+// Handler defines the interface for invitation HTTP handlers
+type Handler interface {
+	InviteMember(c *gin.Context)
+	AcceptInvitation(c *gin.Context)
+	CancelInvitation(c *gin.Context)
+	ListInvitations(c *gin.Context)
+}
+
+// handler implements the Handler interface
+type handler struct {
+	service Service
+}
+
+// NewHandler creates a new invitation handler instance
+func NewHandler(service Service) Handler {
+	return &handler{service: service}
+}
+
+// InviteMember invites a user to an organization by email
+// @Summary Invite member
+// @Description Create an invitation for an email address to join an organization. redirect_url and callback_url are optional and their hosts must be in INVITATION_REDIRECT_ALLOWED_HOSTS / INVITATION_CALLBACK_ALLOWED_HOSTS; callback_url receives a signed invitation.accepted POST when the invitation is accepted.
+// @Description Returns 409 when the email belongs to an active member, or already has a pending invitation and resend is false; with resend=true the pending invitation's email is sent again
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param request body CreateInvitationRequest true "Invitation request"
+// @Success 200 {object} response.Response{data=InvitationResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /v1/invitations [post]
+func (h *handler) InviteMember(c *gin.Context) {
+	var req CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	userID, ok := authctx.RequireUserID(c)
+	if !ok {
+		return
+	}
+
+	invitation, err := h.service.InviteMember(c.Request.Context(), &req, userID)
+	if err != nil {
+		response.ErrorFrom(c, response.StatusFromError(err, http.StatusBadRequest), err)
+		return
+	}
+
+	response.Success(c, invitation)
+}
+
+// AcceptInvitation accepts an invitation for the current user
+// @Summary Accept invitation
+// @Description Accept an invitation by token. The response includes the invitation's redirect_url, if any. Repeating the request after a successful accept returns success
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param request body AcceptInvitationRequest true "Invitation token"
+// @Success 200 {object} response.Response{data=InvitationResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 410 {object} response.Response
+// @Router /v1/invitations/accept [post]
+func (h *handler) AcceptInvitation(c *gin.Context) {
+	var req AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	userID, ok := authctx.RequireUserID(c)
+	if !ok {
+		return
+	}
+
+	invitation, err := h.service.ProcessInvitation(c.Request.Context(), req.Token, userID)
+	if err != nil {
+		response.ErrorFrom(c, invitationErrorStatus(err), err)
+		return
+	}
+
+	response.Success(c, invitation)
+}
+
+// CancelInvitation cancels a pending invitation
+// @Summary Cancel invitation
+// @Description Cancel a pending invitation. Requires invitations.delete in the invitation's organization
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param id path int true "Invitation ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /v1/invitations/{id} [delete]
+func (h *handler) CancelInvitation(c *gin.Context) {
+	id, err := params.PathID(c, "id")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid invitation ID")
+		return
+	}
+
+	actorID, ok := authctx.RequireUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.CancelInvitation(c.Request.Context(), id, actorID); err != nil {
+		response.ErrorFrom(c, invitationErrorStatus(err), err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Invitation cancelled successfully"})
+}
+
+// ListInvitations lists invitations for an organization
+// @Summary List invitations
+// @Description List invitations for an organization, filtered by status, email or role and sorted by creation or expiry. By default only accepted invitations and pending ones that have not expired are listed. Requires invitations.read in the organization; tokens are never listed
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param organization_id path int true "Organization ID"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 20, max: 100)"
+// @Param status query int false "Status (0: pending, 1: accepted, 2: rejected, 3: expired)"
+// @Param include_inactive query bool false "Include rejected, expired and lapsed invitations when no status is given (default: false)"
+// @Param email query string false "Email substring, case-insensitive"
+// @Param role_id query int false "Role ID"
+// @Param order_by query string false "Sort field: created_at (default) or expires_at"
+// @Param order query string false "Sort direction: asc or desc (default)"
+// @Success 200 {object} response.Response{data=InvitationListResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /v1/org-invitations/{organization_id} [get]
+func (h *handler) ListInvitations(c *gin.Context) {
+	organizationID, err := params.PathID(c, "organization_id")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid organization ID")
+		return
+	}
+
+	var query InvitationQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query parameters")
+		return
+	}
+	query.Normalize()
+
+	actorID, ok := authctx.RequireUserID(c)
+	if !ok {
+		return
+	}
+
+	invitations, err := h.service.ListInvitations(c.Request.Context(), organizationID, actorID, &query)
+	if err != nil {
+		if response.Canceled(c, err) {
+			return
+		}
+		if errors.Is(err, ErrInvitationAccessDenied) {
+			response.ErrorFrom(c, http.StatusForbidden, err)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to retrieve invitations")
+		return
+	}
+
+	pagination.SetHeaders(c, invitations.Total, invitations.Page, invitations.PageSize)
+	response.Success(c, invitations)
+}
+
+// invitationErrorStatus maps invitation errors to HTTP status codes: 410 for an expired
+// invitation and the typed error's status otherwise
+func invitationErrorStatus(err error) int {
+	if errors.Is(err, ErrInvitationExpired) {
+		return http.StatusGone
+	}
+	return response.StatusFromError(err, http.StatusInternalServerError)
+}
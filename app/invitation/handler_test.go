@@ -0,0 +1,174 @@
+package invitation
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/app/authorization"
+	"github.com/llamacto/llama-gin-kit/pkg/authctx"
+	"gorm.io/gorm"
+)
+
+// roleLevels is an authorization.Repository covering CheckCanGrantRole: the listed roles have
+// fixed levels, others do not exist, and nobody is a global admin; other methods are left to
+// the embedded nil interface and panic if called
+type roleLevels struct {
+	authorization.Repository
+	roles  map[uint]int // Level by role ID
+	actors map[uint]int // Highest level held in the organization by user ID
+}
+
+func (r *roleLevels) GetRoleByID(ctx context.Context, id uint) (*authorization.Role, error) {
+	level, ok := r.roles[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &authorization.Role{ID: id, Level: level}, nil
+}
+
+func (r *roleLevels) UserHasRole(ctx context.Context, userID uint, roleName string) (bool, error) {
+	return false, nil
+}
+
+func (r *roleLevels) GetUserMaxRoleLevelInOrganization(ctx context.Context, userID, organizationID uint) (int, bool, error) {
+	level, ok := r.actors[userID]
+	return level, ok, nil
+}
+
+func (r *invitationRepository) OrganizationExists(ctx context.Context, organizationID uint) (bool, error) {
+	_, ok := r.organizations[organizationID]
+	return ok, nil
+}
+
+func (r *invitationRepository) TeamInOrganization(ctx context.Context, teamID, organizationID uint) (bool, error) {
+	for _, id := range r.organizations[organizationID] {
+		if id == teamID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// IsActiveMemberByEmail reports every invitee as a member already, so an invitation that gets
+// past the role check stops with 409 before anything is written
+func (r *invitationRepository) IsActiveMemberByEmail(ctx context.Context, organizationID uint, email string) (bool, error) {
+	return true, nil
+}
+
+func TestInviteesCannotBeGrantedRolesAboveTheInviter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const (
+		inviterID = 9
+		editor    = 2 // Role IDs
+		owner     = 3
+	)
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{name: "role at the inviter's level", body: `{"email":"new@example.com","organization_id":3,"role_id":2}`, want: http.StatusConflict},
+		{name: "role above the inviter's level", body: `{"email":"new@example.com","organization_id":3,"role_id":3}`, want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authService := authorization.NewService(&roleLevels{
+				roles:  map[uint]int{editor: 50, owner: 100},
+				actors: map[uint]int{inviterID: 50},
+			})
+			repo := &invitationRepository{organizations: map[uint][]uint{3: nil}}
+			h := NewHandler(NewService(repo, nil, authService))
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) { authctx.SetUserID(c, inviterID) })
+			router.POST("/invitations", h.InviteMember)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/invitations", bytes.NewBufferString(tt.body)))
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+func TestInviteMemberMissingResources(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const inviterID = 9
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode string
+	}{
+		{name: "organization", body: `{"email":"new@example.com","organization_id":4,"role_id":2}`, wantCode: "organization_not_found"},
+		{name: "role", body: `{"email":"new@example.com","organization_id":3,"role_id":7}`, wantCode: "role_not_found"},
+		{name: "team outside the organization", body: `{"email":"new@example.com","organization_id":3,"team_id":6,"role_id":2}`, wantCode: "team_not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authService := authorization.NewService(&roleLevels{
+				roles:  map[uint]int{2: 50},
+				actors: map[uint]int{inviterID: 50},
+			})
+			repo := &invitationRepository{organizations: map[uint][]uint{3: {5}}}
+			h := NewHandler(NewService(repo, nil, authService))
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) { authctx.SetUserID(c, inviterID) })
+			router.POST("/invitations", h.InviteMember)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/invitations", bytes.NewBufferString(tt.body)))
+			if w.Code != http.StatusNotFound {
+				t.Fatalf("status = %d, want 404: %s", w.Code, w.Body)
+			}
+			if !strings.Contains(w.Body.String(), `"error_code":"`+tt.wantCode+`"`) {
+				t.Fatalf("body = %s, want error_code %s", w.Body, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestInvalidPathIDsAreBadRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// The handler has no service, so a request that gets past ID validation panics and
+	// the recovery middleware answers 500
+	h := NewHandler(nil)
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.DELETE("/invitations/:id", h.CancelInvitation)
+	router.GET("/org-invitations/:organization_id", h.ListInvitations)
+
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{method: http.MethodDelete, path: "/invitations/0"},
+		{method: http.MethodDelete, path: "/invitations/-1"},
+		{method: http.MethodDelete, path: "/invitations/abc"},
+		{method: http.MethodGet, path: "/org-invitations/0"},
+		{method: http.MethodGet, path: "/org-invitations/-4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusBadRequest, w.Body)
+			}
+		})
+	}
+}
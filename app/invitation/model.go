@@ -15,11 +15,23 @@ type Invitation struct {
 	TeamID         *uint      `json:"team_id"`
 	RoleID         uint       `gorm:"not null" json:"role_id"`
 	InvitedBy      uint       `json:"invited_by"`
-	Token          string     `gorm:"size:100;not null" json:"token"`
+	Token          string     `gorm:"size:100;not null;uniqueIndex" json:"token"`
 	ExpiresAt      time.Time  `json:"expires_at"`
 	Status         int        `gorm:"default:0" json:"status"` // 0: pending, 1: accepted, 2: rejected, 3: expired
+	AcceptedBy     *uint      `json:"accepted_by"`             // User ID who accepted the invitation
+	AcceptedAt     *time.Time `json:"accepted_at"`
+	RedirectURL    string     `gorm:"size:500" json:"redirect_url"` // Where the client sends the user after accepting
+	CallbackURL    string     `gorm:"size:500" json:"-"`            // Receives an invitation.accepted event on accept
 }
 
+// Invitation statuses
+const (
+	StatusPending  = 0
+	StatusAccepted = 1
+	StatusRejected = 2
+	StatusExpired  = 3
+)
+
 // TableName specifies the database table name
 func (Invitation) TableName() string {
 	return "organization_invitations"
@@ -39,9 +51,9 @@ type InvitationWithDetails struct {
 	InvitedBy        uint      `json:"invited_by"`
 	InviterName      string    `json:"inviter_name"`
 	InviterEmail     string    `json:"inviter_email"`
-	Token            string    `json:"token"`
 	ExpiresAt        time.Time `json:"expires_at"`
 	Status           int       `json:"status"`
+	RedirectURL      string    `json:"redirect_url"`
 	CreatedAt        time.Time `json:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
 }
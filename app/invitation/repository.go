@@ -0,0 +1,243 @@
+package invitation
+
+import (
+	"context"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/app/member"
+	"github.com/llamacto/llama-gin-kit/pkg/pagination"
+	"github.com/llamacto/llama-gin-kit/pkg/softdelete"
+	"gorm.io/gorm"
+)
+
+// Repository defines the interface for invitation data operations
+type Repository interface {
+	Create(ctx context.Context, invitation *Invitation) error
+	GetByID(ctx context.Context, id uint) (*Invitation, error)
+	GetByToken(ctx context.Context, token string) (*Invitation, error)
+	GetDetailsByID(ctx context.Context, id uint) (*InvitationWithDetails, error)
+	ListByOrganization(ctx context.Context, organizationID uint, query *InvitationQuery) ([]InvitationWithDetails, int64, error)
+	UpdateStatus(ctx context.Context, id uint, status int) error
+	Accept(ctx context.Context, invitation *Invitation, userID uint) (bool, error)
+	ExpireIfDue(ctx context.Context, id uint) (bool, error)
+	OrganizationExists(ctx context.Context, organizationID uint) (bool, error)
+	TeamInOrganization(ctx context.Context, teamID, organizationID uint) (bool, error)
+	GetPendingInvitationByEmail(ctx context.Context, organizationID uint, email string) (*Invitation, error)
+	IsActiveMemberByEmail(ctx context.Context, organizationID uint, email string) (bool, error)
+}
+
+// repository implements the Repository interface
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new invitation repository instance
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// Create creates a new invitation
+func (r *repository) Create(ctx context.Context, invitation *Invitation) error {
+	return r.db.WithContext(ctx).Create(invitation).Error
+}
+
+// GetByID retrieves a non-deleted invitation by its ID
+func (r *repository) GetByID(ctx context.Context, id uint) (*Invitation, error) {
+	var invitation Invitation
+	err := r.db.WithContext(ctx).Where("id = ? AND deleted_at IS NULL", id).First(&invitation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// GetByToken retrieves a non-deleted invitation by its token
+func (r *repository) GetByToken(ctx context.Context, token string) (*Invitation, error) {
+	var invitation Invitation
+	err := r.db.WithContext(ctx).Where("token = ? AND deleted_at IS NULL", token).First(&invitation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// GetDetailsByID retrieves an invitation with organization, team, role and inviter details
+func (r *repository) GetDetailsByID(ctx context.Context, id uint) (*InvitationWithDetails, error) {
+	var invitation InvitationWithDetails
+	result := r.detailsQuery(ctx).
+		Where("i.id = ? AND i.deleted_at IS NULL", id).
+		Limit(1).
+		Scan(&invitation)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &invitation, nil
+}
+
+// ListByOrganization retrieves non-deleted invitations for an organization matching the query.
+// Without a status filter or IncludeInactive, only accepted invitations and pending ones that
+// have not yet expired are returned.
+func (r *repository) ListByOrganization(ctx context.Context, organizationID uint, query *InvitationQuery) ([]InvitationWithDetails, int64, error) {
+	var invitations []InvitationWithDetails
+	var total int64
+
+	filter := func(db *gorm.DB) *gorm.DB {
+		db = db.Where("i.organization_id = ? AND i.deleted_at IS NULL", organizationID)
+		switch {
+		case query.Status != nil:
+			db = db.Where("i.status = ?", *query.Status)
+		case !query.IncludeInactive:
+			db = db.Where("i.status = ? OR (i.status = ? AND i.expires_at > NOW())", StatusAccepted, StatusPending)
+		}
+		if query.Email != "" {
+			db = db.Where("i.email ILIKE ?", "%"+query.Email+"%")
+		}
+		if query.RoleID != nil {
+			db = db.Where("i.role_id = ?", *query.RoleID)
+		}
+		return db
+	}
+
+	err := r.db.WithContext(ctx).Table("organization_invitations as i").
+		Scopes(filter).
+		Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = r.detailsQuery(ctx).
+		Scopes(filter, pagination.Paginate(query.Page, query.PageSize)).
+		Order(invitationOrderClause(query)).
+		Order("i.id DESC").
+		Scan(&invitations).Error
+
+	return invitations, total, err
+}
+
+// invitationOrderClause builds a safe ORDER BY clause from the query's sort parameters
+func invitationOrderClause(query *InvitationQuery) string {
+	return invitationSort.Clause("i.", query.OrderBy, query.Order)
+}
+
+// UpdateStatus sets the status of an invitation
+func (r *repository) UpdateStatus(ctx context.Context, id uint, status int) error {
+	return r.db.WithContext(ctx).Model(&Invitation{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"status": status, "updated_at": time.Now()}).Error
+}
+
+// Accept marks a pending invitation as accepted by the user and creates the membership
+// in one transaction. It returns false when the invitation was no longer pending, which
+// happens when a concurrent request accepted it first, or when it expired by the
+// database clock in the meantime.
+func (r *repository) Accept(ctx context.Context, invitation *Invitation, userID uint) (bool, error) {
+	accepted := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&Invitation{}).
+			Where("id = ? AND status = ? AND expires_at > NOW()", invitation.ID, StatusPending).
+			Updates(map[string]interface{}{
+				"status":      StatusAccepted,
+				"accepted_by": userID,
+				"accepted_at": now,
+				"updated_at":  now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		accepted = true
+
+		var count int64
+		err := tx.Model(&member.Member{}).
+			Where("user_id = ? AND organization_id = ?", userID, invitation.OrganizationID).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+
+		return tx.Create(&member.Member{
+			UserID:         userID,
+			OrganizationID: invitation.OrganizationID,
+			TeamID:         invitation.TeamID,
+			RoleID:         invitation.RoleID,
+			Status:         1,
+			JoinedAt:       now,
+			InvitedBy:      invitation.InvitedBy,
+		}).Error
+	})
+	return accepted, err
+}
+
+// ExpireIfDue marks a pending invitation expired when its expiry has passed. Expiry is
+// judged by the database clock rather than the app server's, so instances with drifting
+// clocks agree on when an invitation lapses. It reports whether the invitation was expired.
+func (r *repository) ExpireIfDue(ctx context.Context, id uint) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&Invitation{}).
+		Where("id = ? AND status = ? AND expires_at <= NOW()", id, StatusPending).
+		Updates(map[string]interface{}{"status": StatusExpired, "updated_at": gorm.Expr("NOW()")})
+	return result.RowsAffected > 0, result.Error
+}
+
+// OrganizationExists checks whether a non-deleted organization exists
+func (r *repository) OrganizationExists(ctx context.Context, organizationID uint) (bool, error) {
+	return softdelete.Exists(r.db.WithContext(ctx), "organizations", "id = ?", organizationID)
+}
+
+// GetPendingInvitationByEmail retrieves the newest pending, unexpired invitation for the email
+// in the organization. The email is compared case-insensitively; gorm.ErrRecordNotFound is
+// returned when there is none.
+func (r *repository) GetPendingInvitationByEmail(ctx context.Context, organizationID uint, email string) (*Invitation, error) {
+	var invitation Invitation
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND LOWER(email) = LOWER(?) AND status = ? AND expires_at > NOW() AND deleted_at IS NULL",
+			organizationID, email, StatusPending).
+		Order("created_at DESC").
+		First(&invitation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// IsActiveMemberByEmail checks whether the user registered with the email is an active
+// member of the organization. The email is compared case-insensitively.
+func (r *repository) IsActiveMemberByEmail(ctx context.Context, organizationID uint, email string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Table("organization_members om").
+		Joins("JOIN users u ON u.id = om.user_id AND u.deleted_at IS NULL").
+		Scopes(softdelete.NotDeleted("om")).
+		Where("om.organization_id = ? AND LOWER(u.email) = LOWER(?) AND om.status = 1", organizationID, email).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// TeamInOrganization checks whether a non-deleted team belongs to the organization
+func (r *repository) TeamInOrganization(ctx context.Context, teamID, organizationID uint) (bool, error) {
+	return softdelete.Exists(r.db.WithContext(ctx), "teams", "id = ? AND organization_id = ?", teamID, organizationID)
+}
+
+// detailsQuery builds the invitation query joined with its organization, team, role and inviter
+func (r *repository) detailsQuery(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Table("organization_invitations as i").
+		Select(`
+			i.id, i.email, i.organization_id, i.team_id, i.role_id, i.invited_by,
+			i.expires_at, i.status, i.redirect_url, i.created_at, i.updated_at,
+			o.name as organization_name,
+			t.name as team_name,
+			ro.name as role_name, ro.display_name as role_display_name,
+			u.username as inviter_name, u.email as inviter_email
+		`).
+		Joins("LEFT JOIN organizations o ON i.organization_id = o.id").
+		Joins("LEFT JOIN teams t ON i.team_id = t.id").
+		Joins("LEFT JOIN roles ro ON i.role_id = ro.id").
+		Joins("LEFT JOIN users u ON i.invited_by = u.id")
+}
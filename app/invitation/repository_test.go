@@ -0,0 +1,199 @@
+package invitation
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/llamacto/llama-gin-kit/pkg/dbtest"
+)
+
+func TestExpiryUsesDatabaseClock(t *testing.T) {
+	tests := []struct {
+		name     string
+		fragment string
+		run      func(Repository) error
+	}{
+		{
+			name:     "expire if due",
+			fragment: `UPDATE "organization_invitations"`,
+			run: func(r Repository) error {
+				_, err := r.ExpireIfDue(context.Background(), 5)
+				return err
+			},
+		},
+		{
+			name:     "accept",
+			fragment: `UPDATE "organization_invitations"`,
+			run: func(r Repository) error {
+				_, err := r.Accept(context.Background(), &Invitation{ID: 5, OrganizationID: 3}, 1)
+				return err
+			},
+		},
+		{
+			name:     "active invitation list",
+			fragment: "FROM organization_invitations as i",
+			run: func(r Repository) error {
+				_, _, err := r.ListByOrganization(context.Background(), 3, &InvitationQuery{Page: 1, PageSize: 20})
+				return err
+			},
+		},
+		{
+			name:     "pending invitation lookup",
+			fragment: `FROM "organization_invitations"`,
+			run: func(r Repository) error {
+				_, err := r.GetPendingInvitationByEmail(context.Background(), 3, "dev@example.com")
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, db := dbtest.Open(t)
+			tt.run(NewRepository(gormDB))
+
+			stmt, ok := db.Find(tt.fragment)
+			if !ok {
+				t.Fatalf("no statement contains %q in %v", tt.fragment, db.Statements())
+			}
+			if !strings.Contains(stmt.SQL, "expires_at > NOW()") && !strings.Contains(stmt.SQL, "expires_at <= NOW()") {
+				t.Fatalf("statement %q does not compare expires_at with NOW()", stmt.SQL)
+			}
+			if strings.Contains(stmt.SQL, "expires_at > $") || strings.Contains(stmt.SQL, "expires_at <= $") {
+				t.Fatalf("statement %q compares expires_at with an app-side time", stmt.SQL)
+			}
+		})
+	}
+}
+
+// filters returns the value bound to each "column = $n" or "column ILIKE $n" condition of stmt
+func filters(stmt dbtest.Statement) map[string]driver.Value {
+	found := map[string]driver.Value{}
+	for _, column := range []string{"i.status = ", "i.email ILIKE ", "i.role_id = "} {
+		before, _, ok := strings.Cut(stmt.SQL, column+"$")
+		if ok {
+			found[strings.TrimSpace(column)] = stmt.Args[strings.Count(before, "$")]
+		}
+	}
+	return found
+}
+
+func TestListByOrganizationFilters(t *testing.T) {
+	rejected, roleID := StatusRejected, uint(4)
+
+	tests := []struct {
+		name  string
+		query InvitationQuery
+		want  map[string]driver.Value
+	}{
+		{name: "no filters", query: InvitationQuery{IncludeInactive: true}, want: map[string]driver.Value{}},
+		{name: "status", query: InvitationQuery{Status: &rejected}, want: map[string]driver.Value{"i.status =": int64(StatusRejected)}},
+		{name: "email", query: InvitationQuery{IncludeInactive: true, Email: "dev"}, want: map[string]driver.Value{"i.email ILIKE": "%dev%"}},
+		{name: "role", query: InvitationQuery{IncludeInactive: true, RoleID: &roleID}, want: map[string]driver.Value{"i.role_id =": int64(roleID)}},
+		{
+			name:  "status, email and role",
+			query: InvitationQuery{Status: &rejected, Email: "dev", RoleID: &roleID},
+			want:  map[string]driver.Value{"i.status =": int64(StatusRejected), "i.email ILIKE": "%dev%", "i.role_id =": int64(roleID)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.query
+			query.Page, query.PageSize = 1, 20
+			query.Normalize()
+
+			gormDB, db := dbtest.Open(t)
+			if _, _, err := NewRepository(gormDB).ListByOrganization(context.Background(), 3, &query); err != nil {
+				t.Fatalf("ListByOrganization() error = %v", err)
+			}
+
+			stmts := db.Statements()
+			if len(stmts) != 2 {
+				t.Fatalf("sent %d statements, want a count and a page", len(stmts))
+			}
+			for _, stmt := range stmts {
+				if !strings.Contains(stmt.SQL, "i.organization_id = $1 AND i.deleted_at IS NULL") {
+					t.Fatalf("statement %q does not exclude soft-deleted invitations", stmt.SQL)
+				}
+				got := filters(stmt)
+				if len(got) != len(tt.want) {
+					t.Fatalf("statement %q filters on %v, want %v", stmt.SQL, got, tt.want)
+				}
+				for column, value := range tt.want {
+					if got[column] != value {
+						t.Fatalf("%s bound to %v, want %v", column, got[column], value)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestListByOrganizationSort(t *testing.T) {
+	tests := []struct {
+		name    string
+		orderBy string
+		order   string
+		want    string
+	}{
+		{name: "default", want: "ORDER BY i.created_at DESC,i.id DESC"},
+		{name: "soonest expiry first", orderBy: "expires_at", order: "asc", want: "ORDER BY i.expires_at ASC,i.id DESC"},
+		{name: "latest expiry first", orderBy: "expires_at", order: "desc", want: "ORDER BY i.expires_at DESC,i.id DESC"},
+		{name: "disallowed column", orderBy: "token", order: "asc", want: "ORDER BY i.created_at ASC,i.id DESC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := &InvitationQuery{Page: 1, PageSize: 20, OrderBy: tt.orderBy, Order: tt.order}
+			query.Normalize()
+
+			gormDB, db := dbtest.Open(t)
+			if _, _, err := NewRepository(gormDB).ListByOrganization(context.Background(), 3, query); err != nil {
+				t.Fatalf("ListByOrganization() error = %v", err)
+			}
+			stmt, ok := db.Find("ORDER BY")
+			if !ok || !strings.Contains(stmt.SQL, tt.want) {
+				t.Fatalf("invitation page query %q does not contain %q", stmt.SQL, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultInvitationListExcludesInactive(t *testing.T) {
+	rejected := StatusRejected
+	live := "(i.status = $2 OR (i.status = $3 AND i.expires_at > NOW()))"
+
+	tests := []struct {
+		name     string
+		query    InvitationQuery
+		wantLive bool
+	}{
+		{name: "default listing", wantLive: true},
+		{name: "inactive included on request", query: InvitationQuery{IncludeInactive: true}},
+		{name: "explicit rejected status", query: InvitationQuery{Status: &rejected}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.query
+			query.Page, query.PageSize = 1, 20
+
+			gormDB, db := dbtest.Open(t)
+			if _, _, err := NewRepository(gormDB).ListByOrganization(context.Background(), 3, &query); err != nil {
+				t.Fatalf("ListByOrganization() error = %v", err)
+			}
+
+			for _, stmt := range db.Statements() {
+				if got := strings.Contains(stmt.SQL, live); got != tt.wantLive {
+					t.Fatalf("statement %q keeps only accepted and unexpired pending invitations = %v, want %v", stmt.SQL, got, tt.wantLive)
+				}
+				if tt.wantLive && (stmt.Args[1] != int64(StatusAccepted) || stmt.Args[2] != int64(StatusPending)) {
+					t.Fatalf("live filter bound to %v, want accepted and pending", stmt.Args[1:3])
+				}
+			}
+		})
+	}
+}
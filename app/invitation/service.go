@@ -0,0 +1,347 @@
+package invitation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/app/authorization"
+	"github.com/llamacto/llama-gin-kit/app/organization"
+	"github.com/llamacto/llama-gin-kit/app/team"
+	"github.com/llamacto/llama-gin-kit/app/user"
+	"github.com/llamacto/llama-gin-kit/pkg/email"
+	apperrors "github.com/llamacto/llama-gin-kit/pkg/errors"
+	"github.com/llamacto/llama-gin-kit/pkg/events"
+	"github.com/llamacto/llama-gin-kit/pkg/logger"
+	"github.com/llamacto/llama-gin-kit/pkg/pagination"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvitationNotFound is returned when no invitation matches the token or ID
+	ErrInvitationNotFound = apperrors.NotFound("invitation_not_found", "invitation not found")
+	// ErrInvitationExpired is returned when accepting an invitation past its expiry
+	ErrInvitationExpired = apperrors.Conflict("invitation_expired", "invitation has expired")
+	// ErrInvitationNotPending is returned when the invitation was rejected or cancelled
+	ErrInvitationNotPending = apperrors.Conflict("invitation_not_pending", "invitation is no longer pending")
+	// ErrInvitationAcceptedByOther is returned when a different user already accepted the invitation
+	ErrInvitationAcceptedByOther = apperrors.Conflict("invitation_accepted_by_other", "invitation was accepted by another user")
+	// ErrInvitationEmailMismatch is returned when the accepting user's email differs from the invited one
+	ErrInvitationEmailMismatch = apperrors.Forbidden("invitation_email_mismatch", "invitation was sent to a different email address")
+	// ErrAlreadyMember is returned when inviting an email that belongs to an active member of the organization
+	ErrAlreadyMember = apperrors.AlreadyExists("already_member", "email already belongs to a member of this organization")
+	// ErrAlreadyInvited is returned when the email has a pending invitation to the organization and resend was not requested
+	ErrAlreadyInvited = apperrors.AlreadyExists("already_invited", "email already has a pending invitation to this organization")
+	// ErrInvitationAccessDenied is returned when the actor lacks the invitation permission in the organization
+	ErrInvitationAccessDenied = apperrors.Forbidden("invitation_access_denied", "insufficient permissions for this organization's invitations")
+)
+
+// Service defines the interface for invitation business logic
+type Service interface {
+	InviteMember(ctx context.Context, req *CreateInvitationRequest, inviterID uint) (*InvitationResponse, error)
+	ProcessInvitation(ctx context.Context, token string, userID uint) (*InvitationResponse, error)
+	CancelInvitation(ctx context.Context, id, actorID uint) error
+	ListInvitations(ctx context.Context, organizationID, actorID uint, query *InvitationQuery) (*InvitationListResponse, error)
+}
+
+// service implements the Service interface
+type service struct {
+	repo        Repository
+	userRepo    user.UserRepository
+	authService authorization.Service
+}
+
+// NewService creates a new invitation service instance
+func NewService(repo Repository, userRepo user.UserRepository, authService authorization.Service) Service {
+	return &service{
+		repo:        repo,
+		userRepo:    userRepo,
+		authService: authService,
+	}
+}
+
+// InviteMember creates an invitation after verifying the organization, role and team exist.
+// Emails of active members are rejected with ErrAlreadyMember. When the email already has a
+// pending invitation, it is rejected with ErrAlreadyInvited unless req.Resend is set, in which
+// case the existing invitation's email is sent again and that invitation is returned.
+func (s *service) InviteMember(ctx context.Context, req *CreateInvitationRequest, inviterID uint) (*InvitationResponse, error) {
+	exists, err := s.repo.OrganizationExists(ctx, req.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check organization: %w", err)
+	}
+	if !exists {
+		return nil, organization.ErrOrganizationNotFound
+	}
+
+	// Inviters may not hand out a role more powerful than their own
+	if err := s.authService.CheckCanGrantRole(ctx, inviterID, req.OrganizationID, req.RoleID); err != nil {
+		return nil, err
+	}
+
+	if err := validateURLs(req, invitationConfig()); err != nil {
+		return nil, err
+	}
+
+	if req.TeamID != nil {
+		exists, err = s.repo.TeamInOrganization(ctx, *req.TeamID, req.OrganizationID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check team: %w", err)
+		}
+		if !exists {
+			return nil, team.ErrTeamNotFound
+		}
+	}
+
+	inviteeEmail := strings.ToLower(strings.TrimSpace(req.Email))
+
+	isMember, err := s.repo.IsActiveMemberByEmail(ctx, req.OrganizationID, inviteeEmail)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check membership: %w", err)
+	}
+	if isMember {
+		return nil, ErrAlreadyMember
+	}
+
+	pending, err := s.repo.GetPendingInvitationByEmail(ctx, req.OrganizationID, inviteeEmail)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to check pending invitations: %w", err)
+	}
+	if pending != nil {
+		if !req.Resend {
+			return nil, ErrAlreadyInvited
+		}
+		return s.resendInvitation(ctx, pending)
+	}
+
+	expiresAt, err := ExpiresAt(time.Now(), req.ExpiresInHours)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invitation token: %w", err)
+	}
+
+	invitation := &Invitation{
+		Email:          inviteeEmail,
+		OrganizationID: req.OrganizationID,
+		TeamID:         req.TeamID,
+		RoleID:         req.RoleID,
+		InvitedBy:      inviterID,
+		Token:          token,
+		ExpiresAt:      expiresAt,
+		Status:         StatusPending,
+		RedirectURL:    req.RedirectURL,
+		CallbackURL:    req.CallbackURL,
+	}
+	if err := s.repo.Create(ctx, invitation); err != nil {
+		return nil, fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	details, err := s.repo.GetDetailsByID(ctx, invitation.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+
+	// A failed email should not undo the invitation; it can be resent
+	if err := email.SendInvitationEmail(invitation.Email, details.OrganizationName, token, expiresAt); err != nil {
+		logger.Error("Failed to send invitation email", err)
+	}
+
+	// Only the inviter sees the token, once; listings never include it
+	resp := toInvitationResponse(details)
+	resp.Token = token
+	return &resp, nil
+}
+
+// resendInvitation sends the email for an existing pending invitation again, keeping its
+// token and expiry so links from earlier emails keep working
+func (s *service) resendInvitation(ctx context.Context, invitation *Invitation) (*InvitationResponse, error) {
+	details, err := s.repo.GetDetailsByID(ctx, invitation.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+
+	if err := email.SendInvitationEmail(invitation.Email, details.OrganizationName, invitation.Token, invitation.ExpiresAt); err != nil {
+		return nil, fmt.Errorf("failed to resend invitation email: %w", err)
+	}
+
+	resp := toInvitationResponse(details)
+	return &resp, nil
+}
+
+// ProcessInvitation accepts an invitation on behalf of the user and creates their membership.
+// Accepting an invitation the same user already accepted succeeds again, so retries are safe;
+// the status callback only fires for the accept that changed the invitation.
+func (s *service) ProcessInvitation(ctx context.Context, token string, userID uint) (*InvitationResponse, error) {
+	invitation, err := s.repo.GetByToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+
+	if invitation.Status == StatusPending {
+		if err := s.checkAcceptable(ctx, invitation, userID); err != nil {
+			return nil, err
+		}
+
+		accepted, err := s.repo.Accept(ctx, invitation, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to accept invitation: %w", err)
+		}
+		if accepted {
+			now := time.Now()
+			invitation.Status = StatusAccepted
+			invitation.AcceptedBy = &userID
+			invitation.AcceptedAt = &now
+			authorization.InvalidateOrganizationPermissions(ctx, invitation.OrganizationID, userID)
+			notifyAccepted(invitation, userID, now)
+			publishInvitationEvent(invitation, events.TypeInvitationAccepted, userID)
+		} else {
+			// Lost a race with a concurrent accept, or the invitation lapsed since the check;
+			// record the expiry if so and re-read to see what happened
+			expired, err := s.repo.ExpireIfDue(ctx, invitation.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check invitation expiry: %w", err)
+			}
+			if expired {
+				publishInvitationEvent(invitation, events.TypeInvitationExpired, 0)
+			}
+			invitation, err = s.repo.GetByID(ctx, invitation.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get invitation: %w", err)
+			}
+		}
+	}
+
+	switch invitation.Status {
+	case StatusAccepted:
+		if invitation.AcceptedBy == nil || *invitation.AcceptedBy != userID {
+			return nil, ErrInvitationAcceptedByOther
+		}
+	case StatusExpired:
+		return nil, ErrInvitationExpired
+	default:
+		return nil, ErrInvitationNotPending
+	}
+
+	details, err := s.repo.GetDetailsByID(ctx, invitation.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	resp := toInvitationResponse(details)
+	return &resp, nil
+}
+
+// CancelInvitation marks a pending invitation as rejected. The actor needs invitations.delete
+// in the invitation's organization.
+func (s *service) CancelInvitation(ctx context.Context, id, actorID uint) error {
+	invitation, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvitationNotFound
+		}
+		return fmt.Errorf("failed to get invitation: %w", err)
+	}
+	if err := s.requirePermission(ctx, actorID, invitation.OrganizationID, "invitations.delete"); err != nil {
+		return err
+	}
+	if invitation.Status != StatusPending {
+		return ErrInvitationNotPending
+	}
+
+	if err := s.repo.UpdateStatus(ctx, id, StatusRejected); err != nil {
+		return fmt.Errorf("failed to cancel invitation: %w", err)
+	}
+	return nil
+}
+
+// ListInvitations retrieves invitations for an organization filtered, sorted and paginated by
+// query. The actor needs invitations.read in the organization.
+func (s *service) ListInvitations(ctx context.Context, organizationID, actorID uint, query *InvitationQuery) (*InvitationListResponse, error) {
+	if err := s.requirePermission(ctx, actorID, organizationID, "invitations.read"); err != nil {
+		return nil, err
+	}
+	query.Page, query.PageSize = pagination.Normalize(query.Page, query.PageSize, pagination.DefaultPageSize)
+
+	invitations, total, err := s.repo.ListByOrganization(ctx, organizationID, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invitations: %w", err)
+	}
+
+	responses := make([]InvitationResponse, 0, len(invitations))
+	for i := range invitations {
+		responses = append(responses, toInvitationResponse(&invitations[i]))
+	}
+
+	return &InvitationListResponse{
+		Invitations: responses,
+		Total:       total,
+		Page:        query.Page,
+		PageSize:    query.PageSize,
+		TotalPages:  pagination.TotalPages(total, query.PageSize),
+	}, nil
+}
+
+// requirePermission returns ErrInvitationAccessDenied unless the actor holds permission in
+// the organization
+func (s *service) requirePermission(ctx context.Context, actorID, organizationID uint, permission string) error {
+	allowed, err := s.authService.CheckOrganizationPermission(ctx, actorID, organizationID, permission)
+	if err != nil {
+		return fmt.Errorf("failed to check permissions: %w", err)
+	}
+	if !allowed {
+		return ErrInvitationAccessDenied
+	}
+	return nil
+}
+
+// checkAcceptable verifies a pending invitation has not expired and was sent to the user's email.
+// Expiry is checked against the database clock; Accept re-checks it atomically, so an
+// invitation that lapses between the two is still refused.
+func (s *service) checkAcceptable(ctx context.Context, invitation *Invitation, userID uint) error {
+	expired, err := s.repo.ExpireIfDue(ctx, invitation.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check invitation expiry: %w", err)
+	}
+	if expired {
+		publishInvitationEvent(invitation, events.TypeInvitationExpired, 0)
+		return ErrInvitationExpired
+	}
+
+	u, err := s.userRepo.Get(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if !strings.EqualFold(u.Email, invitation.Email) {
+		return ErrInvitationEmailMismatch
+	}
+	return nil
+}
+
+// publishInvitationEvent tells the organization's event subscribers about the invitation.
+// userID is the accepting user, or zero.
+func publishInvitationEvent(invitation *Invitation, eventType string, userID uint) {
+	events.Publish(invitation.OrganizationID, eventType, events.InvitationData{
+		InvitationID: invitation.ID,
+		Email:        invitation.Email,
+		RoleID:       invitation.RoleID,
+		UserID:       userID,
+	})
+}
+
+// generateToken returns a random hex token for invitation links
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
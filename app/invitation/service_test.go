@@ -0,0 +1,301 @@
+package invitation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/app/authorization"
+	"github.com/llamacto/llama-gin-kit/app/user"
+	"gorm.io/gorm"
+)
+
+// invitationRepository is an in-memory Repository covering acceptance; other methods are
+// left to the embedded nil interface and panic if called
+type invitationRepository struct {
+	Repository
+	invitation *Invitation
+	accepts    int
+	raceWinner *uint // When set, the next Accept loses to this user
+
+	// dbNow is the database clock, which expiry is judged by; zero means nothing expires.
+	// lapse advances it between the expiry check and the accept.
+	dbNow time.Time
+	lapse time.Duration
+
+	organizations map[uint][]uint // Team IDs by existing organization ID
+}
+
+// due reports whether the invitation has expired by the database clock
+func (r *invitationRepository) due() bool {
+	return !r.dbNow.IsZero() && !r.invitation.ExpiresAt.After(r.dbNow)
+}
+
+func (r *invitationRepository) GetByToken(ctx context.Context, token string) (*Invitation, error) {
+	if token != r.invitation.Token {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *r.invitation
+	return &copied, nil
+}
+
+func (r *invitationRepository) GetByID(ctx context.Context, id uint) (*Invitation, error) {
+	copied := *r.invitation
+	return &copied, nil
+}
+
+func (r *invitationRepository) ExpireIfDue(ctx context.Context, id uint) (bool, error) {
+	if r.invitation.Status != StatusPending || !r.due() {
+		return false, nil
+	}
+	r.invitation.Status = StatusExpired
+	return true, nil
+}
+
+func (r *invitationRepository) Accept(ctx context.Context, invitation *Invitation, userID uint) (bool, error) {
+	if r.raceWinner != nil {
+		userID, r.raceWinner = *r.raceWinner, nil
+		r.accept(userID)
+		return false, nil
+	}
+	r.dbNow = r.dbNow.Add(r.lapse)
+	if r.invitation.Status != StatusPending || r.due() {
+		return false, nil
+	}
+	r.accept(userID)
+	return true, nil
+}
+
+func (r *invitationRepository) accept(userID uint) {
+	r.accepts++
+	r.invitation.Status = StatusAccepted
+	r.invitation.AcceptedBy = &userID
+}
+
+func (r *invitationRepository) GetDetailsByID(ctx context.Context, id uint) (*InvitationWithDetails, error) {
+	return &InvitationWithDetails{ID: id, Email: r.invitation.Email, OrganizationID: r.invitation.OrganizationID}, nil
+}
+
+// userRepository is an in-memory user.UserRepository holding the users by ID
+type userRepository struct {
+	user.UserRepository
+	users map[uint]*user.User
+}
+
+func (r *userRepository) Get(ctx context.Context, id uint) (*user.User, error) {
+	u, ok := r.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return u, nil
+}
+
+func TestProcessInvitationIsIdempotent(t *testing.T) {
+	const (
+		invitee = uint(1)
+		other   = uint(2)
+	)
+	inviteeUser, otherUser := invitee, other
+
+	tests := []struct {
+		name        string
+		acceptedBy  []uint // Users accepting, in order; the last result is checked
+		raceWinner  *uint
+		wantErr     error
+		wantAccepts int
+	}{
+		{name: "first accept", acceptedBy: []uint{invitee}, wantAccepts: 1},
+		{name: "double accept by the same user", acceptedBy: []uint{invitee, invitee}, wantAccepts: 1},
+		{name: "accept after another user", acceptedBy: []uint{other, invitee}, wantErr: ErrInvitationAcceptedByOther, wantAccepts: 1},
+		{name: "concurrent accept by the same user", acceptedBy: []uint{invitee}, raceWinner: &inviteeUser, wantAccepts: 1},
+		{name: "concurrent accept by another user", acceptedBy: []uint{invitee}, raceWinner: &otherUser, wantErr: ErrInvitationAcceptedByOther, wantAccepts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &invitationRepository{
+				invitation: &Invitation{ID: 5, Email: "dev@example.com", OrganizationID: 3, Token: "token", Status: StatusPending},
+				raceWinner: tt.raceWinner,
+			}
+			users := &userRepository{users: map[uint]*user.User{
+				invitee: {ID: invitee, Email: "Dev@Example.com"},
+				other:   {ID: other, Email: "dev@example.com"},
+			}}
+			s := NewService(repo, users, nil)
+
+			var err error
+			for _, userID := range tt.acceptedBy {
+				_, err = s.ProcessInvitation(context.Background(), "token", userID)
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ProcessInvitation() error = %v, want %v", err, tt.wantErr)
+			}
+			if repo.accepts != tt.wantAccepts {
+				t.Fatalf("invitation accepted %d times, want %d", repo.accepts, tt.wantAccepts)
+			}
+		})
+	}
+}
+
+func TestProcessInvitationUnknownToken(t *testing.T) {
+	repo := &invitationRepository{invitation: &Invitation{Token: "token"}}
+	_, err := NewService(repo, &userRepository{}, nil).ProcessInvitation(context.Background(), "missing", 1)
+	if !errors.Is(err, ErrInvitationNotFound) {
+		t.Fatalf("ProcessInvitation() error = %v, want %v", err, ErrInvitationNotFound)
+	}
+}
+
+func TestProcessInvitationUsesDatabaseClock(t *testing.T) {
+	appNow := time.Now()
+
+	tests := []struct {
+		name       string
+		expiresAt  time.Time
+		dbNow      time.Time
+		lapse      time.Duration
+		wantErr    error
+		wantStatus int
+	}{
+		{
+			name:       "database clock ahead of the app",
+			expiresAt:  appNow.Add(time.Minute),
+			dbNow:      appNow.Add(time.Hour),
+			wantErr:    ErrInvitationExpired,
+			wantStatus: StatusExpired,
+		},
+		{
+			name:       "database clock behind the app",
+			expiresAt:  appNow.Add(-time.Minute),
+			dbNow:      appNow.Add(-time.Hour),
+			wantStatus: StatusAccepted,
+		},
+		{
+			name:       "lapses between the check and the accept",
+			expiresAt:  appNow.Add(time.Second),
+			dbNow:      appNow,
+			lapse:      time.Minute,
+			wantErr:    ErrInvitationExpired,
+			wantStatus: StatusExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &invitationRepository{
+				invitation: &Invitation{ID: 5, Email: "dev@example.com", OrganizationID: 3, Token: "token",
+					Status: StatusPending, ExpiresAt: tt.expiresAt},
+				dbNow: tt.dbNow,
+				lapse: tt.lapse,
+			}
+			users := &userRepository{users: map[uint]*user.User{1: {ID: 1, Email: "dev@example.com"}}}
+
+			_, err := NewService(repo, users, nil).ProcessInvitation(context.Background(), "token", 1)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ProcessInvitation() error = %v, want %v", err, tt.wantErr)
+			}
+			if repo.invitation.Status != tt.wantStatus {
+				t.Fatalf("invitation status = %d, want %d", repo.invitation.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func (r *invitationRepository) UpdateStatus(ctx context.Context, id uint, status int) error {
+	r.invitation.Status = status
+	return nil
+}
+
+func (r *invitationRepository) ListByOrganization(ctx context.Context, organizationID uint, query *InvitationQuery) ([]InvitationWithDetails, int64, error) {
+	return []InvitationWithDetails{{ID: r.invitation.ID, Email: r.invitation.Email, OrganizationID: organizationID}}, 1, nil
+}
+
+// grantedPermissions is an authorization.Service granting each user the listed permissions in
+// one organization; other methods are left to the embedded nil interface and panic if called
+type grantedPermissions struct {
+	authorization.Service
+	organizationID uint
+	granted        map[uint][]string // Permission names by user ID
+}
+
+func (s *grantedPermissions) CheckOrganizationPermission(ctx context.Context, userID, organizationID uint, permission string) (bool, error) {
+	if organizationID != s.organizationID {
+		return false, nil
+	}
+	for _, name := range s.granted[userID] {
+		if name == permission {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestInvitationManagementRequiresPermission(t *testing.T) {
+	const (
+		orgID    = 3
+		manager  = uint(1)
+		reader   = uint(2)
+		outsider = uint(3)
+	)
+	authService := &grantedPermissions{organizationID: orgID, granted: map[uint][]string{
+		manager: {"invitations.read", "invitations.delete"},
+		reader:  {"invitations.read"},
+	}}
+
+	tests := []struct {
+		name       string
+		actorID    uint
+		cancel     bool // Cancel the invitation instead of listing
+		wantErr    error
+		wantStatus int
+	}{
+		{name: "manager cancels", actorID: manager, cancel: true, wantStatus: StatusRejected},
+		{name: "reader cannot cancel", actorID: reader, cancel: true, wantErr: ErrInvitationAccessDenied, wantStatus: StatusPending},
+		{name: "outsider cannot cancel", actorID: outsider, cancel: true, wantErr: ErrInvitationAccessDenied, wantStatus: StatusPending},
+		{name: "reader lists", actorID: reader, wantStatus: StatusPending},
+		{name: "outsider cannot list", actorID: outsider, wantErr: ErrInvitationAccessDenied, wantStatus: StatusPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &invitationRepository{
+				invitation: &Invitation{ID: 5, Email: "dev@example.com", OrganizationID: orgID, Token: "secret-token", Status: StatusPending},
+			}
+			s := NewService(repo, &userRepository{}, authService)
+
+			var err error
+			if tt.cancel {
+				err = s.CancelInvitation(context.Background(), 5, tt.actorID)
+			} else {
+				_, err = s.ListInvitations(context.Background(), orgID, tt.actorID, &InvitationQuery{})
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if repo.invitation.Status != tt.wantStatus {
+				t.Fatalf("invitation status = %d, want %d", repo.invitation.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestListedInvitationsOmitTheToken(t *testing.T) {
+	repo := &invitationRepository{invitation: &Invitation{ID: 5, OrganizationID: 3, Token: "secret-token"}}
+	authService := &grantedPermissions{organizationID: 3, granted: map[uint][]string{1: {"invitations.read"}}}
+
+	list, err := NewService(repo, &userRepository{}, authService).ListInvitations(context.Background(), 3, 1, &InvitationQuery{})
+	if err != nil {
+		t.Fatalf("ListInvitations() error = %v", err)
+	}
+	body, err := json.Marshal(list)
+	if err != nil {
+		t.Fatalf("marshal list: %v", err)
+	}
+	if strings.Contains(string(body), `"token"`) {
+		t.Fatalf("listed invitations include a token: %s", body)
+	}
+}
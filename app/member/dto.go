@@ -1,5 +1,13 @@
 package member
 
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/app/authorization"
+)
+
 // AddMemberRequest represents the request payload for adding a member to organization/team
 type AddMemberRequest struct {
 	UserID         uint  `json:"user_id" binding:"required"`
@@ -35,6 +43,27 @@ type MemberResponse struct {
 	InvitedBy        uint   `json:"invited_by"`
 	CreatedAt        string `json:"created_at"`
 	UpdatedAt        string `json:"updated_at"`
+
+	// Populated only when requested through the expand parameter
+	User    *MemberUserResponse `json:"user,omitempty"`
+	Role    *MemberRoleResponse `json:"role,omitempty"`
+	Inviter *MemberUserResponse `json:"inviter,omitempty"`
+}
+
+// MemberUserResponse is the embedded user returned with expand=user
+type MemberUserResponse struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Nickname string `json:"nickname"`
+	Avatar   string `json:"avatar"`
+}
+
+// MemberRoleResponse is the embedded role returned with expand=role
+type MemberRoleResponse struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
 }
 
 // MemberListResponse represents the response structure for member list
@@ -46,6 +75,14 @@ type MemberListResponse struct {
 	TotalPages int              `json:"total_pages"`
 }
 
+// MemberCursorListResponse is a page of members in cursor pagination mode.
+// NextCursor is empty once the last member has been returned.
+type MemberCursorListResponse struct {
+	Members    []MemberResponse `json:"members"`
+	PageSize   int              `json:"page_size"`
+	NextCursor string           `json:"next_cursor"`
+}
+
 // MemberStatsResponse represents the response structure for member statistics
 type MemberStatsResponse struct {
 	TotalMembers    int64 `json:"total_members"`
@@ -53,3 +90,122 @@ type MemberStatsResponse struct {
 	PendingInvites  int64 `json:"pending_invites"`
 	DisabledMembers int64 `json:"disabled_members"`
 }
+
+// UserMembershipsResponse aggregates a user's organization memberships, team memberships
+// and role assignments across the whole system, for support staff investigating an account
+type UserMembershipsResponse struct {
+	UserID               uint                                     `json:"user_id"`
+	Organizations        []MemberResponse                         `json:"organizations"`
+	Teams                []UserTeamMembership                     `json:"teams"`
+	GlobalRoles          []authorization.UserRoleResponse         `json:"global_roles"`
+	OrganizationRoles    []authorization.OrganizationRoleResponse `json:"organization_roles"`
+	TeamRoles            []authorization.TeamRoleResponse         `json:"team_roles"`
+	EffectivePermissions []string                                 `json:"effective_permissions"`
+}
+
+// UserTeamMembership is a team a user belongs to through one of their organization memberships
+type UserTeamMembership struct {
+	TeamID           uint   `json:"team_id"`
+	TeamName         string `json:"team_name"`
+	OrganizationID   uint   `json:"organization_id"`
+	OrganizationName string `json:"organization_name"`
+	MemberID         uint   `json:"member_id"`
+}
+
+// ToMemberResponse converts a MemberWithDetails query row to a MemberResponse.
+// Optional team fields are copied defensively since members may not belong to a team.
+func ToMemberResponse(m *MemberWithDetails) MemberResponse {
+	resp := MemberResponse{
+		ID:               m.ID,
+		UserID:           m.UserID,
+		UserName:         m.UserName,
+		UserEmail:        m.UserEmail,
+		UserNickname:     m.UserNickname,
+		UserAvatar:       m.UserAvatar,
+		OrganizationID:   m.OrganizationID,
+		OrganizationName: m.OrganizationName,
+		RoleID:           m.RoleID,
+		RoleName:         m.RoleName,
+		RoleDisplayName:  m.RoleDisplayName,
+		Status:           m.Status,
+		JoinedAt:         m.JoinedAt.Format(time.RFC3339),
+		InvitedBy:        m.InvitedBy,
+		CreatedAt:        m.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:        m.UpdatedAt.Format(time.RFC3339),
+	}
+	if m.TeamID != nil {
+		teamID := *m.TeamID
+		resp.TeamID = &teamID
+	}
+	if m.TeamName != nil {
+		resp.TeamName = *m.TeamName
+	}
+	return resp
+}
+
+// Expandable related entities for member endpoints
+const (
+	ExpandUser    = "user"
+	ExpandRole    = "role"
+	ExpandInviter = "inviter"
+)
+
+// Expand holds the related entities requested through the expand query parameter
+type Expand struct {
+	User    bool
+	Role    bool
+	Inviter bool
+}
+
+// ParseExpand parses a comma-separated expand value such as "user,role".
+// Only allowlisted fields are accepted.
+func ParseExpand(raw string) (Expand, error) {
+	var expand Expand
+	if raw == "" {
+		return expand, nil
+	}
+	for _, field := range strings.Split(raw, ",") {
+		switch strings.TrimSpace(field) {
+		case ExpandUser:
+			expand.User = true
+		case ExpandRole:
+			expand.Role = true
+		case ExpandInviter:
+			expand.Inviter = true
+		case "":
+		default:
+			return expand, fmt.Errorf("unsupported expand field: %s", field)
+		}
+	}
+	return expand, nil
+}
+
+// applyExpand embeds the requested related entities into a member response
+func applyExpand(resp *MemberResponse, m *MemberWithDetails, expand Expand) {
+	if expand.User {
+		resp.User = &MemberUserResponse{
+			ID:       m.UserID,
+			Username: m.UserName,
+			Email:    m.UserEmail,
+			Nickname: m.UserNickname,
+			Avatar:   m.UserAvatar,
+		}
+	}
+	if expand.Role {
+		resp.Role = &MemberRoleResponse{
+			ID:          m.RoleID,
+			Name:        m.RoleName,
+			DisplayName: m.RoleDisplayName,
+		}
+	}
+}
+
+// MemberRoleHistoryResponse represents a member role change in responses
+type MemberRoleHistoryResponse struct {
+	ID        uint      `json:"id"`
+	MemberID  uint      `json:"member_id"`
+	OldRoleID uint      `json:"old_role_id"`
+	NewRoleID uint      `json:"new_role_id"`
+	ChangedBy uint      `json:"changed_by"`
+	ChangedAt time.Time `json:"changed_at"`
+}
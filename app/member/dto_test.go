@@ -0,0 +1,58 @@
+package member
+
+import (
+	"testing"
+	"time"
+)
+
+func TestToMemberResponse(t *testing.T) {
+	teamID := uint(7)
+	teamName := "platform"
+	joined := time.Date(2025, 7, 1, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		teamID       *uint
+		teamName     *string
+		wantTeamID   *uint
+		wantTeamName string
+	}{
+		{name: "member without a team"},
+		{name: "member of a team", teamID: &teamID, teamName: &teamName, wantTeamID: &teamID, wantTeamName: "platform"},
+		{name: "team without a name", teamID: &teamID, wantTeamID: &teamID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MemberWithDetails{
+				ID:             1,
+				UserID:         2,
+				OrganizationID: 3,
+				TeamID:         tt.teamID,
+				TeamName:       tt.teamName,
+				RoleID:         4,
+				JoinedAt:       joined,
+			}
+
+			resp := ToMemberResponse(m)
+
+			if resp.ID != 1 || resp.UserID != 2 || resp.OrganizationID != 3 || resp.RoleID != 4 {
+				t.Fatalf("ToMemberResponse() IDs = %+v", resp)
+			}
+			if resp.JoinedAt != "2025-07-01T09:30:00Z" {
+				t.Fatalf("JoinedAt = %q, want RFC 3339", resp.JoinedAt)
+			}
+			if resp.TeamName != tt.wantTeamName {
+				t.Fatalf("TeamName = %q, want %q", resp.TeamName, tt.wantTeamName)
+			}
+			switch {
+			case tt.wantTeamID == nil && resp.TeamID != nil:
+				t.Fatalf("TeamID = %d, want nil", *resp.TeamID)
+			case tt.wantTeamID != nil && (resp.TeamID == nil || *resp.TeamID != *tt.wantTeamID):
+				t.Fatalf("TeamID = %v, want %d", resp.TeamID, *tt.wantTeamID)
+			case resp.TeamID != nil && resp.TeamID == m.TeamID:
+				t.Fatal("TeamID aliases the query row")
+			}
+		})
+	}
+}
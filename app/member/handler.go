@@ -0,0 +1,335 @@
+package member
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/pkg/authctx"
+	"github.com/llamacto/llama-gin-kit/pkg/pagination"
+	"github.com/llamacto/llama-gin-kit/pkg/params"
+	"github.com/llamacto/llama-gin-kit/pkg/response"
+	"gorm.io/gorm"
+)
+
+// Handler defines the interface for member HTTP handlers
+type Handler interface {
+	AddMember(c *gin.Context)
+	UpdateMember(c *gin.Context)
+	RemoveMember(c *gin.Context)
+	GetMemberRoleHistory(c *gin.Context)
+	GetMember(c *gin.Context)
+	GetMembersByOrganization(c *gin.Context)
+	GetUserMemberships(c *gin.Context)
+}
+
+// handler implements the Handler interface
+type handler struct {
+	service Service
+}
+
+// NewHandler creates a new member handler instance
+func NewHandler(service Service) Handler {
+	return &handler{service: service}
+}
+
+// AddMember adds a user to an organization
+// @Summary Add member
+// @Description Add a user to an organization. The caller may not grant a role above their own
+// @Tags members
+// @Accept json
+// @Produce json
+// @Param request body AddMemberRequest true "Member creation request"
+// @Success 200 {object} response.Response{data=MemberResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /v1/members [post]
+func (h *handler) AddMember(c *gin.Context) {
+	var req AddMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	actorID, ok := authctx.RequireUserID(c)
+	if !ok {
+		return
+	}
+
+	member, err := h.service.AddMember(c.Request.Context(), &req, actorID)
+	if err != nil {
+		response.ErrorFrom(c, memberErrorStatus(err), err)
+		return
+	}
+
+	response.Success(c, member)
+}
+
+// UpdateMember updates a member's team, role or status
+// @Summary Update member
+// @Description Update a member. Role changes require the caller to outrank both the current and the new role
+// @Tags members
+// @Accept json
+// @Produce json
+// @Param id path int true "Member ID"
+// @Param request body UpdateMemberRequest true "Member update request"
+// @Success 200 {object} response.Response{data=MemberResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /v1/members/{id} [put]
+func (h *handler) UpdateMember(c *gin.Context) {
+	id, err := params.PathID(c, "id")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid member ID")
+		return
+	}
+
+	var req UpdateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	actorID, ok := authctx.RequireUserID(c)
+	if !ok {
+		return
+	}
+
+	member, err := h.service.UpdateMember(c.Request.Context(), id, &req, actorID)
+	if err != nil {
+		response.ErrorFrom(c, memberErrorStatus(err), err)
+		return
+	}
+
+	response.Success(c, member)
+}
+
+// RemoveMember removes a member from their organization
+// @Summary Remove member
+// @Description Remove a member from their organization. Requires the caller to outrank the member's role
+// @Tags members
+// @Produce json
+// @Param id path int true "Member ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /v1/members/{id} [delete]
+func (h *handler) RemoveMember(c *gin.Context) {
+	id, err := params.PathID(c, "id")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid member ID")
+		return
+	}
+
+	actorID, ok := authctx.RequireUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveMember(c.Request.Context(), id, actorID); err != nil {
+		response.ErrorFrom(c, memberErrorStatus(err), err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Member removed successfully"})
+}
+
+// GetMember retrieves a member by ID
+// @Summary Get member by ID
+// @Description Get member details by ID. Use expand=user,role,inviter to embed the related user, role and inviting user.
+// @Description Requires membership of the member's organization or members.read in it
+// @Tags members
+// @Accept json
+// @Produce json
+// @Param id path int true "Member ID"
+// @Param expand query string false "Comma-separated related entities to embed (user, role, inviter)"
+// @Success 200 {object} response.Response{data=MemberResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /v1/members/{id} [get]
+func (h *handler) GetMember(c *gin.Context) {
+	id, err := params.PathID(c, "id")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid member ID")
+		return
+	}
+
+	expand, err := ParseExpand(c.Query("expand"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	actorID, ok := authctx.RequireUserID(c)
+	if !ok {
+		return
+	}
+
+	member, err := h.service.GetMember(c.Request.Context(), id, actorID, expand)
+	if err != nil {
+		if response.Canceled(c, err) {
+			return
+		}
+		response.ErrorFrom(c, response.StatusFromError(err, http.StatusInternalServerError), err)
+		return
+	}
+
+	response.Success(c, member)
+}
+
+// GetMembersByOrganization retrieves members of an organization
+// @Summary Get members by organization
+// @Description Get all members of an organization with pagination. Use expand=user,role,inviter to embed the related user, role and inviting user.
+// @Description Passing cursor (empty for the first page) switches to cursor pagination, newest first: the response carries next_cursor instead of totals, and next_cursor is empty at the end of the list.
+// @Description Requires membership of the organization or members.read in it
+// @Tags members
+// @Accept json
+// @Produce json
+// @Param organization_id path int true "Organization ID"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 20, max: 100)"
+// @Param cursor query string false "Opaque cursor from next_cursor; empty to start cursor pagination"
+// @Param expand query string false "Comma-separated related entities to embed (user, role, inviter)"
+// @Success 200 {object} response.Response{data=MemberListResponse}
+// @Success 200 {object} response.Response{data=MemberCursorListResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /v1/org-members/{organization_id} [get]
+func (h *handler) GetMembersByOrganization(c *gin.Context) {
+	organizationID, err := params.PathID(c, "organization_id")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid organization ID")
+		return
+	}
+
+	expand, err := ParseExpand(c.Query("expand"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	actorID, ok := authctx.RequireUserID(c)
+	if !ok {
+		return
+	}
+
+	page, pageSize := pagination.Parse(c, pagination.DefaultPageSize)
+
+	cursor, cursorMode, err := pagination.ParseCursor(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+	if cursorMode {
+		members, err := h.service.GetMembersByOrganizationAfter(c.Request.Context(), organizationID, actorID, cursor, pageSize, expand)
+		if err != nil {
+			listMembersError(c, err)
+			return
+		}
+		response.Success(c, members)
+		return
+	}
+
+	members, err := h.service.GetMembersByOrganization(c.Request.Context(), organizationID, actorID, page, pageSize, expand)
+	if err != nil {
+		listMembersError(c, err)
+		return
+	}
+
+	pagination.SetHeaders(c, members.Total, members.Page, members.PageSize)
+	response.Success(c, members)
+}
+
+// listMembersError writes the response for a failed member listing
+func listMembersError(c *gin.Context, err error) {
+	switch {
+	case response.Canceled(c, err):
+	case errors.Is(err, ErrMemberAccessDenied):
+		response.ErrorFrom(c, http.StatusForbidden, err)
+	default:
+		response.Error(c, http.StatusInternalServerError, "Failed to retrieve members")
+	}
+}
+
+// GetMemberRoleHistory retrieves a member's role change history
+// @Summary Get member role history
+// @Description List every change to a member's role with the previous role, new role and who made the change
+// @Tags members
+// @Accept json
+// @Produce json
+// @Param id path int true "Member ID"
+// @Success 200 {object} response.Response{data=[]MemberRoleHistoryResponse}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /v1/members/{id}/history [get]
+func (h *handler) GetMemberRoleHistory(c *gin.Context) {
+	id, err := params.PathID(c, "id")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid member ID")
+		return
+	}
+
+	history, err := h.service.GetMemberRoleHistory(c.Request.Context(), id)
+	if err != nil {
+		if response.IsNotFound(err) {
+			response.Error(c, http.StatusNotFound, "Member not found")
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to retrieve member role history")
+		return
+	}
+
+	response.Success(c, history)
+}
+
+// GetUserMemberships lists everything a user belongs to, for support staff
+// @Summary Get a user's memberships
+// @Description Aggregate a user's organization memberships, team memberships and role assignments at every scope. Requires users.read
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param userId path int true "User ID"
+// @Success 200 {object} response.Response{data=UserMembershipsResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /v1/admin/users/{userId}/memberships [get]
+func (h *handler) GetUserMemberships(c *gin.Context) {
+	userID, err := params.PathID(c, "userId")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	memberships, err := h.service.GetUserMemberships(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.Error(c, http.StatusNotFound, "User not found")
+			return
+		}
+		if response.Canceled(c, err) {
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to retrieve memberships")
+		return
+	}
+
+	response.Success(c, memberships)
+}
+
+// memberErrorStatus maps member service errors to HTTP status codes: the typed error's
+// status, or 400 for anything else
+func memberErrorStatus(err error) int {
+	return response.StatusFromError(err, http.StatusBadRequest)
+}
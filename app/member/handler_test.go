@@ -0,0 +1,126 @@
+package member
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/app/authorization"
+	"github.com/llamacto/llama-gin-kit/pkg/authctx"
+)
+
+// roleLevels is an authorization.Repository covering CheckCanGrantRole: roles have fixed
+// levels and nobody is a global admin; other methods are left to the embedded nil interface
+// and panic if called
+type roleLevels struct {
+	authorization.Repository
+	roles  map[uint]int // Level by role ID
+	actors map[uint]int // Highest level held in the organization by user ID
+}
+
+func (r *roleLevels) GetRoleByID(ctx context.Context, id uint) (*authorization.Role, error) {
+	return &authorization.Role{ID: id, Level: r.roles[id]}, nil
+}
+
+func (r *roleLevels) UserHasRole(ctx context.Context, userID uint, roleName string) (bool, error) {
+	return false, nil
+}
+
+func (r *roleLevels) GetUserMaxRoleLevelInOrganization(ctx context.Context, userID, organizationID uint) (int, bool, error) {
+	level, ok := r.actors[userID]
+	return level, ok, nil
+}
+
+func TestMembersCannotBeGrantedRolesAboveTheActor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const (
+		actorID = 9
+		viewer  = 1 // Role IDs
+		editor  = 2
+		owner   = 3
+	)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{name: "add at the actor's level", method: http.MethodPost, path: "/members", body: `{"user_id":5,"organization_id":3,"role_id":2}`, want: http.StatusOK},
+		{name: "add above the actor's level", method: http.MethodPost, path: "/members", body: `{"user_id":5,"organization_id":3,"role_id":3}`, want: http.StatusForbidden},
+		{name: "promote to the actor's level", method: http.MethodPut, path: "/members/1", body: `{"role_id":2}`, want: http.StatusOK},
+		{name: "promote above the actor's level", method: http.MethodPut, path: "/members/1", body: `{"role_id":3}`, want: http.StatusForbidden},
+		{name: "demote a superior", method: http.MethodPut, path: "/members/2", body: `{"role_id":1}`, want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memberRepository{members: map[uint]*Member{
+				1: {ID: 1, UserID: 2, OrganizationID: 3, RoleID: viewer},
+				2: {ID: 2, UserID: 4, OrganizationID: 3, RoleID: owner},
+			}}
+			authService := authorization.NewService(&roleLevels{
+				roles:  map[uint]int{viewer: 10, editor: 50, owner: 100},
+				actors: map[uint]int{actorID: 50},
+			})
+			h := NewHandler(NewService(repo, authService, nil))
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) { authctx.SetUserID(c, actorID) })
+			router.POST("/members", h.AddMember)
+			router.PUT("/members/:id", h.UpdateMember)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body)))
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if tt.want == http.StatusForbidden && (len(repo.members) != 2 || repo.updates != nil) {
+				t.Fatal("refused grant changed the membership")
+			}
+		})
+	}
+}
+
+func TestInvalidPathIDsAreBadRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// The handler has no service, so a request that gets past ID validation panics and
+	// the recovery middleware answers 500
+	h := NewHandler(nil)
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.GET("/members/:id", h.GetMember)
+	router.PUT("/members/:id", h.UpdateMember)
+	router.DELETE("/members/:id", h.RemoveMember)
+	router.GET("/members/:id/history", h.GetMemberRoleHistory)
+	router.GET("/org-members/:organization_id", h.GetMembersByOrganization)
+
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{method: http.MethodGet, path: "/members/0"},
+		{method: http.MethodGet, path: "/members/-1"},
+		{method: http.MethodPut, path: "/members/abc"},
+		{method: http.MethodDelete, path: "/members/0"},
+		{method: http.MethodGet, path: "/members/-2/history"},
+		{method: http.MethodGet, path: "/org-members/0"},
+		{method: http.MethodGet, path: "/org-members/-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusBadRequest, w.Body)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/llamacto/llama-gin-kit/app/organization"
 	"github.com/llamacto/llama-gin-kit/app/user"
+	"github.com/llamacto/llama-gin-kit/pkg/invariant"
 	"gorm.io/gorm"
 )
 
@@ -17,6 +18,7 @@ type Member struct {
 	UserID         uint           `gorm:"not null" json:"user_id"`
 	OrganizationID uint           `gorm:"not null" json:"organization_id"`
 	TeamID         *uint          `json:"team_id"`                 // Pointer to allow null
+	RoleID         uint           `gorm:"index" json:"role_id"`    // Member's role within the organization
 	Status         int            `gorm:"default:1" json:"status"` // 1: active, 0: pending, 2: disabled
 	JoinedAt       time.Time      `json:"joined_at"`
 	InvitedBy      uint           `json:"invited_by"` // User ID who invited this member
@@ -31,6 +33,30 @@ func (Member) TableName() string {
 	return "organization_members"
 }
 
+// BeforeSave rejects member rows without a user, organization or role
+func (m *Member) BeforeSave(tx *gorm.DB) error {
+	return invariant.Required(tx, "member",
+		invariant.Field{Column: "user_id", Value: m.UserID},
+		invariant.Field{Column: "organization_id", Value: m.OrganizationID},
+		invariant.Field{Column: "role_id", Value: m.RoleID},
+	)
+}
+
+// MemberRoleHistory records a change to a member's organization role
+type MemberRoleHistory struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+	MemberID  uint      `gorm:"not null;index" json:"member_id"`
+	OldRoleID uint      `json:"old_role_id"`
+	NewRoleID uint      `json:"new_role_id"`
+	ChangedBy uint      `gorm:"not null" json:"changed_by"` // User ID who made the change
+}
+
+// TableName specifies the database table name
+func (MemberRoleHistory) TableName() string {
+	return "member_role_history"
+}
+
 // MemberWithDetails combines member data with related entities for queries
 type MemberWithDetails struct {
 	ID               uint      `json:"id"`
@@ -1,20 +1,30 @@
 package member
 
 import (
+	"context"
+	"fmt"
+
+	"github.com/llamacto/llama-gin-kit/pkg/pagination"
+	"github.com/llamacto/llama-gin-kit/pkg/softdelete"
 	"gorm.io/gorm"
 )
 
 // Repository defines the interface for member data operations
 type Repository interface {
-	Create(member *Member) error
-	GetByID(id uint) (*Member, error)
-	GetByUserAndOrganization(userID, organizationID uint) (*Member, error)
-	GetByOrganizationID(organizationID uint, page, pageSize int) ([]MemberWithDetails, int64, error)
-	GetByTeamID(teamID uint, page, pageSize int) ([]MemberWithDetails, int64, error)
-	Update(id uint, updates map[string]interface{}) error
-	Delete(id uint) error
-	GetMemberStats(organizationID uint) (*MemberStatsResponse, error)
-	CheckMemberExists(userID, organizationID uint) (bool, error)
+	Create(ctx context.Context, member *Member) error
+	GetByID(ctx context.Context, id uint) (*Member, error)
+	GetDetailsByID(ctx context.Context, id uint) (*MemberWithDetails, error)
+	GetByUserAndOrganization(ctx context.Context, userID, organizationID uint) (*Member, error)
+	GetByOrganizationID(ctx context.Context, organizationID uint, page, pageSize int) ([]MemberWithDetails, int64, error)
+	GetByOrganizationIDAfter(ctx context.Context, organizationID uint, cursor *pagination.Cursor, size int) ([]MemberWithDetails, error)
+	GetByTeamID(ctx context.Context, teamID uint, page, pageSize int) ([]MemberWithDetails, int64, error)
+	GetByUserID(ctx context.Context, userID uint) ([]MemberWithDetails, error)
+	Update(ctx context.Context, id uint, updates map[string]interface{}) error
+	UpdateWithRoleChange(ctx context.Context, id uint, updates map[string]interface{}, history *MemberRoleHistory) error
+	GetRoleHistory(ctx context.Context, memberID uint) ([]MemberRoleHistory, error)
+	Delete(ctx context.Context, id uint) error
+	GetMemberStats(ctx context.Context, organizationID uint) (*MemberStatsResponse, error)
+	CheckMemberExists(ctx context.Context, userID, organizationID uint) (bool, error)
 }
 
 // repository implements the Repository interface
@@ -28,24 +38,40 @@ func NewRepository(db *gorm.DB) Repository {
 }
 
 // Create creates a new member
-func (r *repository) Create(member *Member) error {
-	return r.db.Create(member).Error
+func (r *repository) Create(ctx context.Context, member *Member) error {
+	return r.db.WithContext(ctx).Create(member).Error
 }
 
 // GetByID retrieves a member by its ID
-func (r *repository) GetByID(id uint) (*Member, error) {
+func (r *repository) GetByID(ctx context.Context, id uint) (*Member, error) {
 	var member Member
-	err := r.db.First(&member, id).Error
+	err := r.db.WithContext(ctx).First(&member, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &member, nil
 }
 
+// GetDetailsByID retrieves a member with user, organization, team and role details
+func (r *repository) GetDetailsByID(ctx context.Context, id uint) (*MemberWithDetails, error) {
+	var member MemberWithDetails
+	result := r.detailsQuery(ctx).
+		Where("om.id = ? AND om.deleted_at IS NULL", id).
+		Limit(1).
+		Scan(&member)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &member, nil
+}
+
 // GetByUserAndOrganization retrieves a member by user ID and organization ID
-func (r *repository) GetByUserAndOrganization(userID, organizationID uint) (*Member, error) {
+func (r *repository) GetByUserAndOrganization(ctx context.Context, userID, organizationID uint) (*Member, error) {
 	var member Member
-	err := r.db.Where("user_id = ? AND organization_id = ?", userID, organizationID).First(&member).Error
+	err := r.db.WithContext(ctx).Where("user_id = ? AND organization_id = ?", userID, organizationID).First(&member).Error
 	if err != nil {
 		return nil, err
 	}
@@ -53,61 +79,74 @@ func (r *repository) GetByUserAndOrganization(userID, organizationID uint) (*Mem
 }
 
 // GetByOrganizationID retrieves members by organization ID with pagination and detailed info
-func (r *repository) GetByOrganizationID(organizationID uint, page, pageSize int) ([]MemberWithDetails, int64, error) {
+func (r *repository) GetByOrganizationID(ctx context.Context, organizationID uint, page, pageSize int) ([]MemberWithDetails, int64, error) {
 	var members []MemberWithDetails
 	var total int64
 
 	// Count total records
-	err := r.db.Table("organization_members").
-		Where("organization_id = ? AND deleted_at IS NULL", organizationID).
-		Count(&total).Error
+	total, err := softdelete.Count(r.db.WithContext(ctx), "organization_members", "organization_id = ?", organizationID)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	// Get paginated results with joins
-	offset := (page - 1) * pageSize
-	err = r.db.Table("organization_members as om").
-		Select(`
-			om.id, om.user_id, om.organization_id, om.team_id, om.role_id,
-			om.status, om.joined_at, om.invited_by, om.created_at, om.updated_at,
-			u.name as user_name, u.email as user_email, u.nickname as user_nickname, u.avatar as user_avatar,
-			o.name as organization_name,
-			t.name as team_name,
-			r.name as role_name, r.display_name as role_display_name
-		`).
-		Joins("LEFT JOIN users u ON om.user_id = u.id").
-		Joins("LEFT JOIN organizations o ON om.organization_id = o.id").
-		Joins("LEFT JOIN teams t ON om.team_id = t.id").
-		Joins("LEFT JOIN organization_roles r ON om.role_id = r.id").
+	err = r.detailsQuery(ctx).
 		Where("om.organization_id = ? AND om.deleted_at IS NULL", organizationID).
-		Offset(offset).
-		Limit(pageSize).
+		Scopes(pagination.Paginate(page, pageSize)).
 		Scan(&members).Error
 
 	return members, total, err
 }
 
+// GetByOrganizationIDAfter retrieves up to size+1 members of an organization, newest first,
+// continuing after cursor (nil for the first page)
+func (r *repository) GetByOrganizationIDAfter(ctx context.Context, organizationID uint, cursor *pagination.Cursor, size int) ([]MemberWithDetails, error) {
+	var members []MemberWithDetails
+	err := r.detailsQuery(ctx).
+		Where("om.organization_id = ? AND om.deleted_at IS NULL", organizationID).
+		Scopes(pagination.CursorScope("om.", cursor, size)).
+		Scan(&members).Error
+	return members, err
+}
+
 // GetByTeamID retrieves members by team ID with pagination and detailed info
-func (r *repository) GetByTeamID(teamID uint, page, pageSize int) ([]MemberWithDetails, int64, error) {
+func (r *repository) GetByTeamID(ctx context.Context, teamID uint, page, pageSize int) ([]MemberWithDetails, int64, error) {
 	var members []MemberWithDetails
 	var total int64
 
 	// Count total records
-	err := r.db.Table("organization_members").
-		Where("team_id = ? AND deleted_at IS NULL", teamID).
-		Count(&total).Error
+	total, err := softdelete.Count(r.db.WithContext(ctx), "organization_members", "team_id = ?", teamID)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	// Get paginated results with joins
-	offset := (page - 1) * pageSize
-	err = r.db.Table("organization_members as om").
+	err = r.detailsQuery(ctx).
+		Where("om.team_id = ? AND om.deleted_at IS NULL", teamID).
+		Scopes(pagination.Paginate(page, pageSize)).
+		Scan(&members).Error
+
+	return members, total, err
+}
+
+// GetByUserID retrieves every organization membership of a user with detailed info,
+// ordered by organization
+func (r *repository) GetByUserID(ctx context.Context, userID uint) ([]MemberWithDetails, error) {
+	var members []MemberWithDetails
+	err := r.detailsQuery(ctx).
+		Where("om.user_id = ? AND om.deleted_at IS NULL", userID).
+		Order("om.organization_id ASC, om.id ASC").
+		Scan(&members).Error
+	return members, err
+}
+
+// detailsQuery builds the member query joined with its user, organization, team and role
+func (r *repository) detailsQuery(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Table("organization_members as om").
 		Select(`
 			om.id, om.user_id, om.organization_id, om.team_id, om.role_id,
 			om.status, om.joined_at, om.invited_by, om.created_at, om.updated_at,
-			u.name as user_name, u.email as user_email, u.nickname as user_nickname, u.avatar as user_avatar,
+			u.username as user_name, u.email as user_email, u.nickname as user_nickname, u.avatar as user_avatar,
 			o.name as organization_name,
 			t.name as team_name,
 			r.name as role_name, r.display_name as role_display_name
@@ -115,57 +154,70 @@ func (r *repository) GetByTeamID(teamID uint, page, pageSize int) ([]MemberWithD
 		Joins("LEFT JOIN users u ON om.user_id = u.id").
 		Joins("LEFT JOIN organizations o ON om.organization_id = o.id").
 		Joins("LEFT JOIN teams t ON om.team_id = t.id").
-		Joins("LEFT JOIN organization_roles r ON om.role_id = r.id").
-		Where("om.team_id = ? AND om.deleted_at IS NULL", teamID).
-		Offset(offset).
-		Limit(pageSize).
-		Scan(&members).Error
-
-	return members, total, err
+		Joins("LEFT JOIN roles r ON om.role_id = r.id")
 }
 
 // Update updates a member by ID
-func (r *repository) Update(id uint, updates map[string]interface{}) error {
-	return r.db.Model(&Member{}).Where("id = ?", id).Updates(updates).Error
+func (r *repository) Update(ctx context.Context, id uint, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&Member{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// UpdateWithRoleChange applies updates that change the member's role and records the change
+// in the same transaction. The update only matches while the member still holds history.OldRoleID,
+// so concurrent role changes cannot produce a misleading history row.
+func (r *repository) UpdateWithRoleChange(ctx context.Context, id uint, updates map[string]interface{}, history *MemberRoleHistory) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Member{}).Where("id = ? AND role_id = ?", id, history.OldRoleID).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("member role was changed concurrently")
+		}
+
+		history.MemberID = id
+		return tx.Create(history).Error
+	})
+}
+
+// GetRoleHistory retrieves a member's role changes, newest first
+func (r *repository) GetRoleHistory(ctx context.Context, memberID uint) ([]MemberRoleHistory, error) {
+	var history []MemberRoleHistory
+	err := r.db.WithContext(ctx).Where("member_id = ?", memberID).Order("created_at DESC, id DESC").Find(&history).Error
+	return history, err
 }
 
 // Delete soft deletes a member by ID
-func (r *repository) Delete(id uint) error {
-	return r.db.Delete(&Member{}, id).Error
+func (r *repository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&Member{}, id).Error
 }
 
 // GetMemberStats retrieves member statistics for an organization
-func (r *repository) GetMemberStats(organizationID uint) (*MemberStatsResponse, error) {
+func (r *repository) GetMemberStats(ctx context.Context, organizationID uint) (*MemberStatsResponse, error) {
 	stats := &MemberStatsResponse{}
+	db := r.db.WithContext(ctx)
+	var err error
 
 	// Total members
-	err := r.db.Table("organization_members").
-		Where("organization_id = ? AND deleted_at IS NULL", organizationID).
-		Count(&stats.TotalMembers).Error
+	stats.TotalMembers, err = softdelete.Count(db, "organization_members", "organization_id = ?", organizationID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Active members
-	err = r.db.Table("organization_members").
-		Where("organization_id = ? AND status = 1 AND deleted_at IS NULL", organizationID).
-		Count(&stats.ActiveMembers).Error
+	stats.ActiveMembers, err = softdelete.Count(db, "organization_members", "organization_id = ? AND status = 1", organizationID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Pending invites
-	err = r.db.Table("organization_invitations").
-		Where("organization_id = ? AND status = 0 AND deleted_at IS NULL", organizationID).
-		Count(&stats.PendingInvites).Error
+	stats.PendingInvites, err = softdelete.Count(db, "organization_invitations", "organization_id = ? AND status = 0", organizationID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Disabled members
-	err = r.db.Table("organization_members").
-		Where("organization_id = ? AND status = 2 AND deleted_at IS NULL", organizationID).
-		Count(&stats.DisabledMembers).Error
+	stats.DisabledMembers, err = softdelete.Count(db, "organization_members", "organization_id = ? AND status = 2", organizationID)
 	if err != nil {
 		return nil, err
 	}
@@ -174,10 +226,6 @@ func (r *repository) GetMemberStats(organizationID uint) (*MemberStatsResponse,
 }
 
 // CheckMemberExists checks if a user is already a member of the organization
-func (r *repository) CheckMemberExists(userID, organizationID uint) (bool, error) {
-	var count int64
-	err := r.db.Table("organization_members").
-		Where("user_id = ? AND organization_id = ? AND deleted_at IS NULL", userID, organizationID).
-		Count(&count).Error
-	return count > 0, err
+func (r *repository) CheckMemberExists(ctx context.Context, userID, organizationID uint) (bool, error) {
+	return softdelete.Exists(r.db.WithContext(ctx), "organization_members", "user_id = ? AND organization_id = ?", userID, organizationID)
 }
@@ -0,0 +1,38 @@
+package member
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/llamacto/llama-gin-kit/pkg/dbtest"
+	"github.com/llamacto/llama-gin-kit/pkg/invariant"
+)
+
+func TestCreateRejectsInvalidMembers(t *testing.T) {
+	tests := []struct {
+		name    string
+		member  Member
+		wantErr error
+	}{
+		{name: "valid member", member: Member{UserID: 1, OrganizationID: 2, RoleID: 3}},
+		{name: "without a role", member: Member{UserID: 1, OrganizationID: 2}, wantErr: invariant.ErrViolation},
+		{name: "without a user", member: Member{OrganizationID: 2, RoleID: 3}, wantErr: invariant.ErrViolation},
+		{name: "without an organization", member: Member{UserID: 1, RoleID: 3}, wantErr: invariant.ErrViolation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, db := dbtest.Open(t)
+
+			member := tt.member
+			err := NewRepository(gormDB).Create(context.Background(), &member)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Create() error = %v, want %v", err, tt.wantErr)
+			}
+			if _, inserted := db.Find(`INSERT INTO "organization_members"`); inserted != (tt.wantErr == nil) {
+				t.Fatalf("member inserted = %v, want %v", inserted, tt.wantErr == nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,424 @@
+package member
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/app/authorization"
+	"github.com/llamacto/llama-gin-kit/app/user"
+	apperrors "github.com/llamacto/llama-gin-kit/pkg/errors"
+	"github.com/llamacto/llama-gin-kit/pkg/events"
+	"github.com/llamacto/llama-gin-kit/pkg/pagination"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrAlreadyMember is returned when adding a user who is already in the organization
+	ErrAlreadyMember = apperrors.AlreadyExists("member_exists", "user is already a member of this organization")
+	// ErrMemberNotFound is returned when the member does not exist
+	ErrMemberNotFound = apperrors.NotFound("member_not_found", "member not found")
+	// ErrMemberAccessDenied is returned when the actor may not view the organization's members
+	ErrMemberAccessDenied = apperrors.Forbidden("member_access_denied", "insufficient permissions to view this organization's members")
+)
+
+// Service defines the interface for member business logic
+type Service interface {
+	AddMember(ctx context.Context, req *AddMemberRequest, actorID uint) (*MemberResponse, error)
+	UpdateMember(ctx context.Context, id uint, req *UpdateMemberRequest, actorID uint) (*MemberResponse, error)
+	RemoveMember(ctx context.Context, id uint, actorID uint) error
+	GetMemberRoleHistory(ctx context.Context, memberID uint) ([]MemberRoleHistoryResponse, error)
+	GetMember(ctx context.Context, id, actorID uint, expand Expand) (*MemberResponse, error)
+	GetMembersByOrganization(ctx context.Context, organizationID, actorID uint, page, pageSize int, expand Expand) (*MemberListResponse, error)
+	GetMembersByOrganizationAfter(ctx context.Context, organizationID, actorID uint, cursor *pagination.Cursor, pageSize int, expand Expand) (*MemberCursorListResponse, error)
+	GetUserMemberships(ctx context.Context, userID uint) (*UserMembershipsResponse, error)
+}
+
+// service implements the Service interface
+type service struct {
+	repo        Repository
+	authService authorization.Service
+	userRepo    user.UserRepository
+}
+
+// NewService creates a new member service instance
+func NewService(repo Repository, authService authorization.Service, userRepo user.UserRepository) Service {
+	return &service{
+		repo:        repo,
+		authService: authService,
+		userRepo:    userRepo,
+	}
+}
+
+// AddMember adds a user to an organization. The actor may only grant roles up to their own level.
+func (s *service) AddMember(ctx context.Context, req *AddMemberRequest, actorID uint) (*MemberResponse, error) {
+	exists, err := s.repo.CheckMemberExists(ctx, req.UserID, req.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check membership: %w", err)
+	}
+	if exists {
+		return nil, ErrAlreadyMember
+	}
+
+	if err := s.authService.CheckCanGrantRole(ctx, actorID, req.OrganizationID, req.RoleID); err != nil {
+		return nil, err
+	}
+
+	member := &Member{
+		UserID:         req.UserID,
+		OrganizationID: req.OrganizationID,
+		TeamID:         req.TeamID,
+		RoleID:         req.RoleID,
+		Status:         1,
+		JoinedAt:       time.Now(),
+		InvitedBy:      actorID,
+	}
+	if err := s.repo.Create(ctx, member); err != nil {
+		return nil, fmt.Errorf("failed to add member: %w", err)
+	}
+	events.Publish(member.OrganizationID, events.TypeMemberAdded, events.MemberData{
+		MemberID: member.ID,
+		UserID:   member.UserID,
+		RoleID:   member.RoleID,
+		ActorID:  actorID,
+	})
+	authorization.InvalidateOrganizationPermissions(ctx, member.OrganizationID, member.UserID)
+
+	return s.getMember(ctx, member.ID, Expand{})
+}
+
+// UpdateMember updates a member's team, role or status. Changing the role requires the actor
+// to outrank both the member's current role and the new one, so nobody can modify a superior.
+func (s *service) UpdateMember(ctx context.Context, id uint, req *UpdateMemberRequest, actorID uint) (*MemberResponse, error) {
+	member, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMemberNotFound
+		}
+		return nil, fmt.Errorf("failed to get member: %w", err)
+	}
+
+	updates := make(map[string]interface{})
+	var history *MemberRoleHistory
+
+	if req.RoleID != nil && *req.RoleID != member.RoleID {
+		if err := s.authService.CheckCanGrantRole(ctx, actorID, member.OrganizationID, member.RoleID); err != nil {
+			return nil, err
+		}
+		if err := s.authService.CheckCanGrantRole(ctx, actorID, member.OrganizationID, *req.RoleID); err != nil {
+			return nil, err
+		}
+		updates["role_id"] = *req.RoleID
+		history = &MemberRoleHistory{
+			OldRoleID: member.RoleID,
+			NewRoleID: *req.RoleID,
+			ChangedBy: actorID,
+		}
+	}
+	if req.TeamID != nil {
+		updates["team_id"] = *req.TeamID
+	}
+	if req.Status != nil {
+		updates["status"] = *req.Status
+	}
+
+	if len(updates) > 0 {
+		updates["updated_at"] = time.Now()
+		if history != nil {
+			err = s.repo.UpdateWithRoleChange(ctx, id, updates, history)
+		} else {
+			err = s.repo.Update(ctx, id, updates)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to update member: %w", err)
+		}
+		// Role and status decide the member's organization permissions
+		authorization.InvalidateOrganizationPermissions(ctx, member.OrganizationID, member.UserID)
+		if history != nil {
+			events.Publish(member.OrganizationID, events.TypeMemberRoleChanged, events.MemberData{
+				MemberID:  member.ID,
+				UserID:    member.UserID,
+				RoleID:    history.NewRoleID,
+				OldRoleID: history.OldRoleID,
+				ActorID:   actorID,
+			})
+		}
+	}
+
+	return s.getMember(ctx, id, Expand{})
+}
+
+// RemoveMember removes a member from their organization. Like a role change, it requires the
+// actor to outrank the member's role.
+func (s *service) RemoveMember(ctx context.Context, id uint, actorID uint) error {
+	member, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrMemberNotFound
+		}
+		return fmt.Errorf("failed to get member: %w", err)
+	}
+
+	if err := s.authService.CheckCanGrantRole(ctx, actorID, member.OrganizationID, member.RoleID); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+	authorization.InvalidateOrganizationPermissions(ctx, member.OrganizationID, member.UserID)
+	events.Publish(member.OrganizationID, events.TypeMemberRemoved, events.MemberData{
+		MemberID: member.ID,
+		UserID:   member.UserID,
+		RoleID:   member.RoleID,
+		ActorID:  actorID,
+	})
+	return nil
+}
+
+// GetMember retrieves a member by ID, embedding any expanded related entities. The actor must
+// belong to the member's organization or hold members.read in it.
+func (s *service) GetMember(ctx context.Context, id, actorID uint, expand Expand) (*MemberResponse, error) {
+	member, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMemberNotFound
+		}
+		return nil, fmt.Errorf("failed to get member: %w", err)
+	}
+	if err := s.requireMemberAccess(ctx, actorID, member.OrganizationID); err != nil {
+		return nil, err
+	}
+	return s.getMember(ctx, id, expand)
+}
+
+// getMember loads a member with its details and expansions without an access check
+func (s *service) getMember(ctx context.Context, id uint, expand Expand) (*MemberResponse, error) {
+	member, err := s.repo.GetDetailsByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get member: %w", err)
+	}
+
+	responses := []MemberResponse{ToMemberResponse(member)}
+	applyExpand(&responses[0], member, expand)
+	if expand.Inviter {
+		if err := s.attachInviters(ctx, responses); err != nil {
+			return nil, err
+		}
+	}
+	return &responses[0], nil
+}
+
+// GetMembersByOrganization retrieves members of an organization with pagination. The actor
+// must belong to the organization or hold members.read in it.
+func (s *service) GetMembersByOrganization(ctx context.Context, organizationID, actorID uint, page, pageSize int, expand Expand) (*MemberListResponse, error) {
+	if err := s.requireMemberAccess(ctx, actorID, organizationID); err != nil {
+		return nil, err
+	}
+	page, pageSize = pagination.Normalize(page, pageSize, pagination.DefaultPageSize)
+
+	members, total, err := s.repo.GetByOrganizationID(ctx, organizationID, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get members: %w", err)
+	}
+
+	responses, err := s.toExpandedResponses(ctx, members, expand)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MemberListResponse{
+		Members:    responses,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: pagination.TotalPages(total, pageSize),
+	}, nil
+}
+
+// GetMembersByOrganizationAfter retrieves a page of members, newest first, continuing after
+// cursor, with the same access rule as GetMembersByOrganization
+func (s *service) GetMembersByOrganizationAfter(ctx context.Context, organizationID, actorID uint, cursor *pagination.Cursor, pageSize int, expand Expand) (*MemberCursorListResponse, error) {
+	if err := s.requireMemberAccess(ctx, actorID, organizationID); err != nil {
+		return nil, err
+	}
+	_, pageSize = pagination.Normalize(1, pageSize, pagination.DefaultPageSize)
+
+	members, err := s.repo.GetByOrganizationIDAfter(ctx, organizationID, cursor, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get members: %w", err)
+	}
+
+	fetched := len(members)
+	if fetched > pageSize {
+		members = members[:pageSize]
+	}
+	var last pagination.Cursor
+	if len(members) > 0 {
+		last = pagination.Cursor{CreatedAt: members[len(members)-1].CreatedAt, ID: members[len(members)-1].ID}
+	}
+
+	responses, err := s.toExpandedResponses(ctx, members, expand)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MemberCursorListResponse{
+		Members:    responses,
+		PageSize:   pageSize,
+		NextCursor: pagination.NextCursor(fetched, pageSize, last),
+	}, nil
+}
+
+// requireMemberAccess returns ErrMemberAccessDenied unless the actor holds members.read in the
+// organization or is one of its members
+func (s *service) requireMemberAccess(ctx context.Context, actorID, organizationID uint) error {
+	allowed, err := s.authService.CheckOrganizationPermission(ctx, actorID, organizationID, "members.read")
+	if err != nil {
+		return fmt.Errorf("failed to check permissions: %w", err)
+	}
+	if allowed {
+		return nil
+	}
+	isMember, err := s.repo.CheckMemberExists(ctx, actorID, organizationID)
+	if err != nil {
+		return fmt.Errorf("failed to check membership: %w", err)
+	}
+	if !isMember {
+		return ErrMemberAccessDenied
+	}
+	return nil
+}
+
+// toExpandedResponses converts members to responses with the requested related entities embedded
+func (s *service) toExpandedResponses(ctx context.Context, members []MemberWithDetails, expand Expand) ([]MemberResponse, error) {
+	responses := make([]MemberResponse, 0, len(members))
+	for i := range members {
+		resp := ToMemberResponse(&members[i])
+		applyExpand(&resp, &members[i], expand)
+		responses = append(responses, resp)
+	}
+	if expand.Inviter {
+		if err := s.attachInviters(ctx, responses); err != nil {
+			return nil, err
+		}
+	}
+	return responses, nil
+}
+
+// attachInviters embeds the inviting user into each response, resolving all inviters in one query
+func (s *service) attachInviters(ctx context.Context, responses []MemberResponse) error {
+	ids := make([]uint, 0, len(responses))
+	for _, resp := range responses {
+		if resp.InvitedBy != 0 {
+			ids = append(ids, resp.InvitedBy)
+		}
+	}
+
+	inviters, err := s.userRepo.GetUsersByIDs(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to get inviters: %w", err)
+	}
+
+	for i := range responses {
+		if info, ok := inviters[responses[i].InvitedBy]; ok {
+			responses[i].Inviter = &MemberUserResponse{
+				ID:       info.ID,
+				Username: info.Username,
+				Email:    info.Email,
+				Nickname: info.Nickname,
+				Avatar:   info.Avatar,
+			}
+		}
+	}
+	return nil
+}
+
+// GetMemberRoleHistory retrieves a member's role changes, newest first
+func (s *service) GetMemberRoleHistory(ctx context.Context, memberID uint) ([]MemberRoleHistoryResponse, error) {
+	if _, err := s.repo.GetByID(ctx, memberID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("member not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get member: %w", err)
+	}
+
+	history, err := s.repo.GetRoleHistory(ctx, memberID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role history: %w", err)
+	}
+
+	responses := make([]MemberRoleHistoryResponse, 0, len(history))
+	for _, h := range history {
+		responses = append(responses, MemberRoleHistoryResponse{
+			ID:        h.ID,
+			MemberID:  h.MemberID,
+			OldRoleID: h.OldRoleID,
+			NewRoleID: h.NewRoleID,
+			ChangedBy: h.ChangedBy,
+			ChangedAt: h.CreatedAt,
+		})
+	}
+	return responses, nil
+}
+
+// GetUserMemberships aggregates every organization and team a user belongs to and the roles
+// they hold at each scope. It returns gorm.ErrRecordNotFound when the user does not exist.
+func (s *service) GetUserMemberships(ctx context.Context, userID uint) (*UserMembershipsResponse, error) {
+	if _, err := s.userRepo.FindByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	members, err := s.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get memberships: %w", err)
+	}
+
+	summary, err := s.authService.GetUserPermissionsSummary(ctx, userID, &authorization.PermissionsSummaryQuery{})
+	if err != nil {
+		return nil, err
+	}
+
+	return buildUserMemberships(userID, members, summary), nil
+}
+
+// buildUserMemberships combines a user's membership rows and role summary into one response.
+// Every list is non-nil so clients always receive arrays.
+func buildUserMemberships(userID uint, members []MemberWithDetails, summary *authorization.UserPermissionsSummary) *UserMembershipsResponse {
+	resp := &UserMembershipsResponse{
+		UserID:               userID,
+		Organizations:        make([]MemberResponse, 0, len(members)),
+		Teams:                []UserTeamMembership{},
+		GlobalRoles:          []authorization.UserRoleResponse{},
+		OrganizationRoles:    []authorization.OrganizationRoleResponse{},
+		TeamRoles:            []authorization.TeamRoleResponse{},
+		EffectivePermissions: []string{},
+	}
+
+	for i := range members {
+		m := &members[i]
+		resp.Organizations = append(resp.Organizations, ToMemberResponse(m))
+		if m.TeamID != nil {
+			team := UserTeamMembership{
+				TeamID:           *m.TeamID,
+				OrganizationID:   m.OrganizationID,
+				OrganizationName: m.OrganizationName,
+				MemberID:         m.ID,
+			}
+			if m.TeamName != nil {
+				team.TeamName = *m.TeamName
+			}
+			resp.Teams = append(resp.Teams, team)
+		}
+	}
+
+	if summary != nil {
+		resp.GlobalRoles = append(resp.GlobalRoles, summary.GlobalRoles...)
+		resp.OrganizationRoles = append(resp.OrganizationRoles, summary.OrganizationRoles...)
+		resp.TeamRoles = append(resp.TeamRoles, summary.TeamRoles...)
+		resp.EffectivePermissions = append(resp.EffectivePermissions, summary.EffectivePermissions...)
+	}
+
+	return resp
+}
@@ -0,0 +1,387 @@
+package member
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/llamacto/llama-gin-kit/app/authorization"
+	"github.com/llamacto/llama-gin-kit/app/user"
+	"github.com/llamacto/llama-gin-kit/pkg/dbtest"
+	"gorm.io/gorm"
+)
+
+// memberRepository is an in-memory Repository covering member updates; other methods are
+// left to the embedded nil interface and panic if called
+type memberRepository struct {
+	Repository
+	members map[uint]*Member
+	updates map[string]interface{}
+}
+
+func (r *memberRepository) GetByID(ctx context.Context, id uint) (*Member, error) {
+	m, ok := r.members[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return m, nil
+}
+
+func (r *memberRepository) Update(ctx context.Context, id uint, updates map[string]interface{}) error {
+	r.updates = updates
+	if teamID, ok := updates["team_id"].(uint); ok {
+		r.members[id].TeamID = &teamID
+	}
+	return nil
+}
+
+func (r *memberRepository) GetDetailsByID(ctx context.Context, id uint) (*MemberWithDetails, error) {
+	m := r.members[id]
+	return &MemberWithDetails{ID: m.ID, UserID: m.UserID, OrganizationID: m.OrganizationID, TeamID: m.TeamID, RoleID: m.RoleID}, nil
+}
+
+func (r *memberRepository) CheckMemberExists(ctx context.Context, userID, organizationID uint) (bool, error) {
+	for _, m := range r.members {
+		if m.UserID == userID && m.OrganizationID == organizationID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memberRepository) Create(ctx context.Context, member *Member) error {
+	member.ID = uint(len(r.members) + 1)
+	r.members[member.ID] = member
+	return nil
+}
+
+func (r *memberRepository) UpdateWithRoleChange(ctx context.Context, id uint, updates map[string]interface{}, history *MemberRoleHistory) error {
+	r.updates = updates
+	r.members[id].RoleID = history.NewRoleID
+	return nil
+}
+
+func TestUpdateMemberTeam(t *testing.T) {
+	teamID := uint(7)
+	status := 2
+
+	tests := []struct {
+		name     string
+		req      UpdateMemberRequest
+		wantTeam *uint
+	}{
+		{name: "team omitted", req: UpdateMemberRequest{Status: &status}},
+		{name: "team set", req: UpdateMemberRequest{TeamID: &teamID}, wantTeam: &teamID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memberRepository{members: map[uint]*Member{1: {ID: 1, UserID: 2, OrganizationID: 3, RoleID: 4}}}
+
+			resp, err := NewService(repo, nil, nil).UpdateMember(context.Background(), 1, &tt.req, 9)
+			if err != nil {
+				t.Fatalf("UpdateMember() error = %v", err)
+			}
+
+			stored, set := repo.updates["team_id"]
+			if set != (tt.req.TeamID != nil) {
+				t.Fatalf("team_id in updates = %v, want %v", set, tt.req.TeamID != nil)
+			}
+			if set {
+				if _, isValue := stored.(uint); !isValue {
+					t.Fatalf("team_id stored as %T, want the uint value", stored)
+				}
+			}
+			switch {
+			case tt.wantTeam == nil && resp.TeamID != nil:
+				t.Fatalf("TeamID = %d, want nil", *resp.TeamID)
+			case tt.wantTeam != nil && (resp.TeamID == nil || *resp.TeamID != *tt.wantTeam):
+				t.Fatalf("TeamID = %v, want %d", resp.TeamID, *tt.wantTeam)
+			}
+		})
+	}
+}
+
+// grantedPermissions is an authorization.Service granting each user the listed permissions in
+// one organization; other methods are left to the embedded nil interface and panic if called
+type grantedPermissions struct {
+	authorization.Service
+	organizationID uint
+	granted        map[uint][]string // Permission names by user ID
+}
+
+func (s *grantedPermissions) CheckOrganizationPermission(ctx context.Context, userID, organizationID uint, permission string) (bool, error) {
+	if organizationID != s.organizationID {
+		return false, nil
+	}
+	for _, name := range s.granted[userID] {
+		if name == permission {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// memberDB answers the member queries with member 1 of organization 3, user 2 holding role 4;
+// the membership check finds the actor when actorIsMember is set
+func memberDB(t *testing.T, actorIsMember bool) (*gorm.DB, *dbtest.DB) {
+	t.Helper()
+	db, mock := dbtest.Open(t)
+	count := int64(0)
+	if actorIsMember {
+		count = 1
+	}
+	mock.Returns(`FROM "organization_members" WHERE (user_id`, []string{"count"}, []driver.Value{count})
+	mock.Returns(`FROM "organization_members" WHERE organization_id`, []string{"count"}, []driver.Value{int64(1)})
+	mock.Returns(`FROM "organization_members" WHERE "organization_members"."id"`,
+		[]string{"id", "user_id", "organization_id", "role_id"},
+		[]driver.Value{int64(1), int64(2), int64(3), int64(4)})
+	mock.Returns("FROM organization_members as om",
+		[]string{"id", "user_id", "organization_id", "role_id", "user_name", "user_email", "role_name", "role_display_name"},
+		[]driver.Value{int64(1), int64(2), int64(3), int64(4), "ada", "ada@example.com", "editor", "Editor"})
+	return db, mock
+}
+
+func TestGetMemberExpandsUserAndRole(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		wantUser bool
+		wantRole bool
+	}{
+		{name: "no expansion"},
+		{name: "user", raw: "user", wantUser: true},
+		{name: "role", raw: "role", wantRole: true},
+		{name: "user and role", raw: "user, role", wantUser: true, wantRole: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expand, err := ParseExpand(tt.raw)
+			if err != nil {
+				t.Fatalf("ParseExpand(%q) error = %v", tt.raw, err)
+			}
+			db, _ := memberDB(t, true)
+			svc := NewService(NewRepository(db), &grantedPermissions{organizationID: 3}, nil)
+
+			for _, get := range []func() (*MemberResponse, error){
+				func() (*MemberResponse, error) { return svc.GetMember(context.Background(), 1, 9, expand) },
+				func() (*MemberResponse, error) {
+					list, err := svc.GetMembersByOrganization(context.Background(), 3, 9, 1, 20, expand)
+					if err != nil {
+						return nil, err
+					}
+					return &list.Members[0], nil
+				},
+			} {
+				resp, err := get()
+				if err != nil {
+					t.Fatalf("error = %v", err)
+				}
+				if (resp.User != nil) != tt.wantUser {
+					t.Fatalf("User = %+v, want embedded %v", resp.User, tt.wantUser)
+				}
+				if tt.wantUser && (resp.User.ID != 2 || resp.User.Username != "ada" || resp.User.Email != "ada@example.com") {
+					t.Fatalf("User = %+v", resp.User)
+				}
+				if (resp.Role != nil) != tt.wantRole {
+					t.Fatalf("Role = %+v, want embedded %v", resp.Role, tt.wantRole)
+				}
+				if tt.wantRole && (resp.Role.ID != 4 || resp.Role.Name != "editor" || resp.Role.DisplayName != "Editor") {
+					t.Fatalf("Role = %+v", resp.Role)
+				}
+			}
+		})
+	}
+
+	if _, err := ParseExpand("user,password"); err == nil {
+		t.Fatal("ParseExpand() accepted a field outside the allowlist")
+	}
+}
+
+func TestMemberReadsRequireOrganizationAccess(t *testing.T) {
+	const (
+		reader   = uint(1)
+		member   = uint(2)
+		outsider = uint(3)
+	)
+	expand := Expand{User: true, Role: true}
+
+	tests := []struct {
+		name     string
+		actorID  uint
+		isMember bool
+		wantErr  error
+	}{
+		{name: "members.read holder", actorID: reader},
+		{name: "member of the organization", actorID: member, isMember: true},
+		{name: "outsider", actorID: outsider, wantErr: ErrMemberAccessDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authService := &grantedPermissions{organizationID: 3, granted: map[uint][]string{reader: {"members.read"}}}
+
+			calls := map[string]func(Service) error{
+				"get member": func(svc Service) error {
+					_, err := svc.GetMember(context.Background(), 1, tt.actorID, expand)
+					return err
+				},
+				"list members": func(svc Service) error {
+					_, err := svc.GetMembersByOrganization(context.Background(), 3, tt.actorID, 1, 20, expand)
+					return err
+				},
+				"list members after cursor": func(svc Service) error {
+					_, err := svc.GetMembersByOrganizationAfter(context.Background(), 3, tt.actorID, nil, 20, expand)
+					return err
+				},
+			}
+			for name, call := range calls {
+				db, mock := memberDB(t, tt.isMember)
+				err := call(NewService(NewRepository(db), authService, nil))
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("%s: error = %v, want %v", name, err, tt.wantErr)
+				}
+				_, loaded := mock.Find("FROM organization_members as om")
+				if loaded != (tt.wantErr == nil) {
+					t.Fatalf("%s: details loaded = %v, want %v", name, loaded, tt.wantErr == nil)
+				}
+			}
+		})
+	}
+}
+
+// knownUsers is a user.UserRepository finding only the listed users; other methods are left
+// to the embedded nil interface and panic if called
+type knownUsers struct {
+	user.UserRepository
+	ids []uint
+}
+
+func (r *knownUsers) FindByID(ctx context.Context, id uint) (*user.UserInfo, error) {
+	for _, known := range r.ids {
+		if known == id {
+			return &user.UserInfo{ID: id}, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// roleSummaries is an authorization.Service returning a fixed permissions summary per user;
+// other methods are left to the embedded nil interface and panic if called
+type roleSummaries struct {
+	authorization.Service
+	summaries map[uint]*authorization.UserPermissionsSummary
+}
+
+func (s *roleSummaries) GetUserPermissionsSummary(ctx context.Context, userID uint, query *authorization.PermissionsSummaryQuery) (*authorization.UserPermissionsSummary, error) {
+	if summary, ok := s.summaries[userID]; ok {
+		return summary, nil
+	}
+	return &authorization.UserPermissionsSummary{UserID: userID}, nil
+}
+
+func TestGetUserMemberships(t *testing.T) {
+	const (
+		multiOrgUser = 2
+		loneUser     = 3
+		unknownUser  = 9
+	)
+	authService := &roleSummaries{summaries: map[uint]*authorization.UserPermissionsSummary{
+		multiOrgUser: {
+			UserID:               multiOrgUser,
+			GlobalRoles:          []authorization.UserRoleResponse{{RoleID: 1, RoleName: "support"}},
+			OrganizationRoles:    []authorization.OrganizationRoleResponse{{OrganizationID: 10, RoleID: 4}, {OrganizationID: 20, RoleID: 5}},
+			TeamRoles:            []authorization.TeamRoleResponse{{TeamID: 7, RoleID: 6}},
+			EffectivePermissions: []string{"members.read", "teams.read"},
+		},
+	}}
+
+	tests := []struct {
+		name      string
+		userID    uint
+		rows      [][]driver.Value
+		wantErr   error
+		wantOrgs  []uint
+		wantTeams []UserTeamMembership
+		wantPerms []string
+		wantRoles int // Organization roles in the aggregate
+	}{
+		{
+			name:   "user in several organizations",
+			userID: multiOrgUser,
+			rows: [][]driver.Value{
+				{int64(1), int64(multiOrgUser), int64(10), "Acme", int64(7), "Platform", int64(4), "editor"},
+				{int64(2), int64(multiOrgUser), int64(20), "Globex", nil, nil, int64(5), "viewer"},
+			},
+			wantOrgs:  []uint{10, 20},
+			wantTeams: []UserTeamMembership{{TeamID: 7, TeamName: "Platform", OrganizationID: 10, OrganizationName: "Acme", MemberID: 1}},
+			wantPerms: []string{"members.read", "teams.read"},
+			wantRoles: 2,
+		},
+		{
+			name:      "user without memberships",
+			userID:    loneUser,
+			wantOrgs:  []uint{},
+			wantTeams: []UserTeamMembership{},
+			wantPerms: []string{},
+		},
+		{
+			name:    "unknown user",
+			userID:  unknownUser,
+			wantErr: gorm.ErrRecordNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, db := dbtest.Open(t)
+			db.Returns("FROM organization_members as om",
+				[]string{"id", "user_id", "organization_id", "organization_name", "team_id", "team_name", "role_id", "role_name"},
+				tt.rows...)
+			svc := NewService(NewRepository(gormDB), authService, &knownUsers{ids: []uint{multiOrgUser, loneUser}})
+
+			got, err := svc.GetUserMemberships(context.Background(), tt.userID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetUserMemberships() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if _, ok := db.Find("FROM organization_members as om"); ok {
+					t.Fatal("memberships were queried for an unknown user")
+				}
+				return
+			}
+
+			stmt, ok := db.Find("FROM organization_members as om")
+			if !ok || stmt.Args[0] != int64(tt.userID) {
+				t.Fatalf("membership query = %+v, want it scoped to user %d", stmt, tt.userID)
+			}
+
+			if got.UserID != tt.userID {
+				t.Fatalf("UserID = %d, want %d", got.UserID, tt.userID)
+			}
+			orgs := []uint{}
+			for _, m := range got.Organizations {
+				orgs = append(orgs, m.OrganizationID)
+			}
+			if !reflect.DeepEqual(orgs, tt.wantOrgs) {
+				t.Fatalf("organizations = %v, want %v", orgs, tt.wantOrgs)
+			}
+			if !reflect.DeepEqual(got.Teams, tt.wantTeams) {
+				t.Fatalf("teams = %+v, want %+v", got.Teams, tt.wantTeams)
+			}
+			if !reflect.DeepEqual(got.EffectivePermissions, tt.wantPerms) {
+				t.Fatalf("effective permissions = %v, want %v", got.EffectivePermissions, tt.wantPerms)
+			}
+			if len(got.OrganizationRoles) != tt.wantRoles {
+				t.Fatalf("organization roles = %+v, want %d", got.OrganizationRoles, tt.wantRoles)
+			}
+			// Lists are always arrays so clients never see null
+			if got.GlobalRoles == nil || got.OrganizationRoles == nil || got.TeamRoles == nil {
+				t.Fatalf("role lists = %+v, want non-nil slices", got)
+			}
+		})
+	}
+}
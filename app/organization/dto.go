@@ -2,6 +2,8 @@ package organization
 
 import (
 	"time"
+
+	"github.com/llamacto/llama-gin-kit/pkg/pagination"
 )
 
 // CreateOrganizationRequest represents the request to create an organization
@@ -12,6 +14,10 @@ type CreateOrganizationRequest struct {
 	Logo        string `json:"logo"`
 	Website     string `json:"website"`
 	Settings    string `json:"settings,omitempty"`
+
+	CreateDefaultTeam bool `json:"create_default_team"` // Create a "general" team containing the creator
+
+	InheritTeamPermissions bool `json:"inherit_team_permissions"` // Let team roles apply to descendant teams
 }
 
 // UpdateOrganizationRequest represents the request to update an organization
@@ -22,6 +28,29 @@ type UpdateOrganizationRequest struct {
 	Website     string `json:"website"`
 	Settings    string `json:"settings,omitempty"`
 	Status      *int   `json:"status,omitempty"`
+
+	InheritTeamPermissions *bool `json:"inherit_team_permissions,omitempty"`
+}
+
+// ListQuery represents filter, sort and pagination parameters for listing organizations
+type ListQuery struct {
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"page_size,default=10"`
+	Search   string `form:"search"` // Case-insensitive match against name and display_name
+	Status   *int   `form:"status"`
+	OrderBy  string `form:"order_by,default=created_at"` // id, name, display_name, status, created_at or updated_at
+	Order    string `form:"order,default=desc"`
+}
+
+// organizationSort lists the columns organizations may be sorted by
+var organizationSort = pagination.Sort{
+	Columns: []string{"id", "name", "display_name", "status", "created_at", "updated_at"},
+	Default: "created_at",
+}
+
+// Normalize replaces a disallowed order_by or order with the default sort
+func (q *ListQuery) Normalize() {
+	q.OrderBy, q.Order = organizationSort.Normalize(q.OrderBy, q.Order)
 }
 
 // OrganizationResponse represents the organization data in responses
@@ -32,10 +61,13 @@ type OrganizationResponse struct {
 	Description string    `json:"description"`
 	Logo        string    `json:"logo"`
 	Website     string    `json:"website"`
+	OwnerID     uint      `json:"owner_id"`
 	Settings    string    `json:"settings,omitempty"`
 	Status      int       `json:"status"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+
+	InheritTeamPermissions bool `json:"inherit_team_permissions"`
 }
 
 // OrganizationStatsResponse represents organization statistics
@@ -185,3 +217,39 @@ type PaginationResponse struct {
 	Size  int         `json:"size"`
 	Data  interface{} `json:"data"`
 }
+
+// CursorPaginationResponse represents a page in cursor pagination mode.
+// NextCursor is empty once the last item has been returned.
+type CursorPaginationResponse struct {
+	Size       int         `json:"size"`
+	NextCursor string      `json:"next_cursor"`
+	Data       interface{} `json:"data"`
+}
+
+// toOrganizationResponse converts an Organization model to an OrganizationResponse
+func toOrganizationResponse(org *Organization) OrganizationResponse {
+	return OrganizationResponse{
+		ID:          org.ID,
+		Name:        org.Name,
+		DisplayName: org.DisplayName,
+		Description: org.Description,
+		Logo:        org.Logo,
+		Website:     org.Website,
+		OwnerID:     org.OwnerID,
+		Settings:    org.Settings,
+		Status:      org.Status,
+		CreatedAt:   org.CreatedAt,
+		UpdatedAt:   org.UpdatedAt,
+
+		InheritTeamPermissions: org.InheritTeamPermissions,
+	}
+}
+
+// toOrganizationResponses converts a list of Organization models to responses
+func toOrganizationResponses(orgs []*Organization) []OrganizationResponse {
+	responses := make([]OrganizationResponse, 0, len(orgs))
+	for _, org := range orgs {
+		responses = append(responses, toOrganizationResponse(org))
+	}
+	return responses
+}
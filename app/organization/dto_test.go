@@ -0,0 +1,72 @@
+package organization
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestToOrganizationResponse(t *testing.T) {
+	created := time.Date(2025, 7, 1, 9, 30, 0, 0, time.UTC)
+	org := &Organization{
+		ID:                     1,
+		CreatedAt:              created,
+		UpdatedAt:              created.Add(time.Hour),
+		Name:                   "acme",
+		DisplayName:            "Acme",
+		Description:            "Widgets",
+		Logo:                   "https://example.com/logo.png",
+		Website:                "https://example.com",
+		OwnerID:                9,
+		Settings:               `{"features":{"sso":true}}`,
+		Status:                 1,
+		InheritTeamPermissions: true,
+	}
+
+	want := OrganizationResponse{
+		ID:                     1,
+		Name:                   "acme",
+		DisplayName:            "Acme",
+		Description:            "Widgets",
+		Logo:                   "https://example.com/logo.png",
+		Website:                "https://example.com",
+		OwnerID:                9,
+		Settings:               `{"features":{"sso":true}}`,
+		Status:                 1,
+		CreatedAt:              created,
+		UpdatedAt:              created.Add(time.Hour),
+		InheritTeamPermissions: true,
+	}
+	if got := toOrganizationResponse(org); got != want {
+		t.Fatalf("toOrganizationResponse() = %+v, want %+v", got, want)
+	}
+}
+
+func TestToOrganizationResponses(t *testing.T) {
+	tests := []struct {
+		name     string
+		orgs     []*Organization
+		wantJSON string
+	}{
+		{name: "nil list", wantJSON: "[]"},
+		{name: "empty list", orgs: []*Organization{}, wantJSON: "[]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(toOrganizationResponses(tt.orgs))
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			if string(data) != tt.wantJSON {
+				t.Fatalf("toOrganizationResponses() JSON = %s, want %s", data, tt.wantJSON)
+			}
+		})
+	}
+
+	orgs := []*Organization{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}
+	got := toOrganizationResponses(orgs)
+	if len(got) != 2 || got[0].ID != 1 || got[1].Name != "b" {
+		t.Fatalf("toOrganizationResponses() = %+v, want both organizations in order", got)
+	}
+}
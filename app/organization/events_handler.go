@@ -0,0 +1,135 @@
+package organization
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/pkg/events"
+	"github.com/llamacto/llama-gin-kit/pkg/logger"
+	"github.com/llamacto/llama-gin-kit/pkg/params"
+	"github.com/llamacto/llama-gin-kit/pkg/response"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// eventsHeartbeatInterval is how often an idle events connection is sent a heartbeat, so
+	// proxies keep it open and dead clients are noticed
+	eventsHeartbeatInterval = 30 * time.Second
+	// eventsWriteTimeout bounds how long a single message may take to send
+	eventsWriteTimeout = 10 * time.Second
+)
+
+// heartbeat is the message sent on idle events connections
+var heartbeat = map[string]string{"type": "heartbeat"}
+
+// EventsHandler streams organization events over WebSocket
+type EventsHandler struct {
+	hub          *events.Hub
+	allowOrigins []string
+}
+
+// NewEventsHandler creates an events handler reading from hub. Browser connections must come
+// from one of allowOrigins, or any origin when it contains "*".
+func NewEventsHandler(hub *events.Hub, allowOrigins []string) *EventsHandler {
+	return &EventsHandler{hub: hub, allowOrigins: allowOrigins}
+}
+
+// StreamEvents upgrades the request to a WebSocket and pushes the organization's events
+// @Summary Stream organization events
+// @Description Push member added/removed/role-changed and invitation accepted/expired events for the organization over a WebSocket. Each message is an events.Event; idle connections receive {"type":"heartbeat"}. Browsers may pass the access token as the access_token query parameter. To resume after a reconnect, pass the last received event ID as last_event_id; recent missed events are replayed first.
+// @Tags organizations
+// @Param id path int true "Organization ID"
+// @Param last_event_id query int false "ID of the last event received before reconnecting"
+// @Param access_token query string false "Access token, for clients that cannot set the Authorization header"
+// @Success 101 {object} events.Event
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /v1/ws/organizations/{id}/events [get]
+func (h *EventsHandler) StreamEvents(c *gin.Context) {
+	orgID, err := params.PathID(c, "id")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid organization ID")
+		return
+	}
+
+	var afterID uint64
+	if raw := c.Query("last_event_id"); raw != "" {
+		afterID, err = strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid last_event_id")
+			return
+		}
+	}
+
+	server := websocket.Server{
+		Handshake: h.checkOrigin,
+		Handler: func(conn *websocket.Conn) {
+			h.stream(conn, orgID, afterID)
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// checkOrigin accepts clients without an Origin header, which are not browsers, and browsers
+// on an allowed origin
+func (h *EventsHandler) checkOrigin(config *websocket.Config, req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	for _, allowed := range h.allowOrigins {
+		if allowed == "*" || allowed == origin {
+			return nil
+		}
+	}
+	return fmt.Errorf("origin %q not allowed", origin)
+}
+
+// stream sends the organization's events to conn until the client disconnects, a send
+// fails, or the subscription is dropped for falling behind. Clients reconnect with the last
+// event ID they received to resume.
+func (h *EventsHandler) stream(conn *websocket.Conn, orgID uint, afterID uint64) {
+	defer conn.Close()
+
+	sub := h.hub.Subscribe(orgID, afterID)
+	defer sub.Close()
+
+	// Clients send nothing; reading only detects the disconnect
+	disconnected := make(chan struct{})
+	go func() {
+		defer close(disconnected)
+		var discard []byte
+		for websocket.Message.Receive(conn, &discard) == nil {
+		}
+	}()
+
+	ticker := time.NewTicker(eventsHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		var msg interface{}
+		select {
+		case <-disconnected:
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				logger.Warn("Closing events connection for organization %d: client fell behind", orgID)
+				return
+			}
+			msg = event
+		case <-ticker.C:
+			msg = heartbeat
+		}
+
+		if err := conn.SetWriteDeadline(time.Now().Add(eventsWriteTimeout)); err != nil {
+			return
+		}
+		if err := websocket.JSON.Send(conn, msg); err != nil {
+			return
+		}
+	}
+}
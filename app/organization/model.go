@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/llamacto/llama-gin-kit/pkg/invariant"
+	"github.com/llamacto/llama-gin-kit/pkg/settings"
 	"gorm.io/gorm"
 )
 
@@ -36,6 +38,12 @@ func (j *JSONString) Scan(value interface{}) error {
 	return nil
 }
 
+// Default team created for new organizations when requested
+const (
+	DefaultTeamName        = "general"
+	DefaultTeamDisplayName = "General"
+)
+
 // Organization represents the organization model
 type Organization struct {
 	ID          uint           `gorm:"primaryKey" json:"id"`
@@ -47,8 +55,16 @@ type Organization struct {
 	Description string         `gorm:"size:500" json:"description"`
 	Logo        string         `gorm:"size:255" json:"logo"`
 	Website     string         `gorm:"size:255" json:"website"`
-	// Settings    *string        `gorm:"type:json" json:"settings,omitempty"` // JSON settings for organization - temporarily disabled
-	Status int `gorm:"default:1" json:"status"` // 1: active, 0: disabled
+	OwnerID     uint           `gorm:"index" json:"owner_id"`                           // User who created the organization
+	Settings    string         `gorm:"type:json;not null;default:'{}'" json:"settings"` // JSON object checked against SettingsSchema
+	Status      int            `gorm:"default:1" json:"status"`                         // 1: active, 0: disabled
+
+	InheritTeamPermissions bool `gorm:"not null;default:false" json:"inherit_team_permissions"` // Team roles also apply to descendant teams
+}
+
+// SettingsSchema validates the organization settings keys that have a known shape
+var SettingsSchema = settings.Schema{
+	"features": settings.Flags(), // Feature flags enabled or disabled for the organization
 }
 
 // TableName specifies the database table name
@@ -56,6 +72,29 @@ func (Organization) TableName() string {
 	return "organizations"
 }
 
+// GetSettings parses the organization's settings document
+func (o *Organization) GetSettings() (settings.Settings, error) {
+	return settings.Parse(o.Settings)
+}
+
+// SetSetting stores value under key in the organization's settings, rejecting values that
+// do not match SettingsSchema. The change is saved with the organization.
+func (o *Organization) SetSetting(key string, value interface{}) error {
+	updated, err := SettingsSchema.SetValidated("settings", o.Settings, key, value)
+	if err != nil {
+		return err
+	}
+	o.Settings = updated
+	return nil
+}
+
+// BeforeSave rejects organization rows without a name
+func (o *Organization) BeforeSave(tx *gorm.DB) error {
+	return invariant.Required(tx, "organization",
+		invariant.Field{Column: "name", Value: o.Name},
+	)
+}
+
 // OrganizationStats includes organization data with statistics
 type OrganizationStats struct {
 	Organization Organization `json:"organization"`
@@ -63,3 +102,24 @@ type OrganizationStats struct {
 	TeamCount    int64        `json:"team_count"`
 	RoleCount    int64        `json:"role_count"`
 }
+
+// DeletionSummary reports how many records were removed with an organization
+type DeletionSummary struct {
+	OrganizationID    uint  `json:"organization_id"`
+	Hard              bool  `json:"hard"`
+	Teams             int64 `json:"teams"`
+	Members           int64 `json:"members"`
+	Invitations       int64 `json:"invitations"`
+	OrganizationRoles int64 `json:"organization_roles"`
+}
+
+// MemberExportRow is a flattened organization member used for roster exports
+type MemberExportRow struct {
+	ID       uint      `json:"id"`
+	UserID   uint      `json:"user_id"`
+	Username string    `json:"username"`
+	Email    string    `json:"email"`
+	RoleName string    `json:"role_name"`
+	Status   int       `json:"status"`
+	JoinedAt time.Time `json:"joined_at"`
+}
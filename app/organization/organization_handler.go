@@ -1,10 +1,17 @@
 package organization
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/pkg/authctx"
+	"github.com/llamacto/llama-gin-kit/pkg/i18n"
+	"github.com/llamacto/llama-gin-kit/pkg/logger"
+	"github.com/llamacto/llama-gin-kit/pkg/pagination"
+	"github.com/llamacto/llama-gin-kit/pkg/params"
+	"github.com/llamacto/llama-gin-kit/pkg/response"
 )
 
 // Handler struct for organization operations
@@ -17,18 +24,29 @@ func NewHandler(service Service) *Handler {
 	return &Handler{service: service}
 }
 
-// CreateOrganization creates a new organization without settings
+// CreateOrganization creates a new organization
+// @Summary Create an organization
+// @Description Create an organization owned by the current user, optionally with a default team
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param request body CreateOrganizationRequest true "Organization creation request"
+// @Success 201 {object} response.Response{data=OrganizationResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations [post]
 func (h *Handler) CreateOrganization(c *gin.Context) {
 	var req CreateOrganizationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		response.Error(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := authctx.UserID(c)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		response.ErrorKey(c, http.StatusUnauthorized, i18n.KeyUnauthorized)
 		return
 	}
 
@@ -38,124 +56,155 @@ func (h *Handler) CreateOrganization(c *gin.Context) {
 		Description: req.Description,
 		Logo:        req.Logo,
 		Website:     req.Website,
+		Settings:    req.Settings,
 		Status:      1, // Active
-	}
 
-	if err := h.service.CreateOrganization(c.Request.Context(), org, userID.(uint)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+		InheritTeamPermissions: req.InheritTeamPermissions,
 	}
 
-	// Convert to response format (without settings)
-	response := gin.H{
-		"id":           org.ID,
-		"name":         org.Name,
-		"display_name": org.DisplayName,
-		"description":  org.Description,
-		"logo":         org.Logo,
-		"website":      org.Website,
-		"status":       org.Status,
-		"created_at":   org.CreatedAt,
-		"updated_at":   org.UpdatedAt,
+	opts := CreateOptions{CreateDefaultTeam: req.CreateDefaultTeam}
+	if err := h.service.CreateOrganization(c.Request.Context(), org, userID, opts); err != nil {
+		respondOrganizationError(c, err)
+		return
 	}
 
-	c.JSON(http.StatusCreated, response)
+	response.Created(c, toOrganizationResponse(org))
 }
 
 // GetOrganization gets an organization by ID
+// @Summary Get organization by ID
+// @Tags organizations
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {object} response.Response{data=OrganizationResponse}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{id} [get]
 func (h *Handler) GetOrganization(c *gin.Context) {
 	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
+	id, err := params.ParseID(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID format"})
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidID)
 		return
 	}
 
-	org, err := h.service.GetOrganization(c.Request.Context(), uint(id))
+	org, err := h.service.GetOrganization(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "organization not found"})
+		respondOrganizationError(c, err)
 		return
 	}
 
-	response := gin.H{
-		"id":           org.ID,
-		"name":         org.Name,
-		"display_name": org.DisplayName,
-		"description":  org.Description,
-		"logo":         org.Logo,
-		"website":      org.Website,
-		"status":       org.Status,
-		"created_at":   org.CreatedAt,
-		"updated_at":   org.UpdatedAt,
-	}
-
-	c.JSON(http.StatusOK, response)
+	response.Success(c, toOrganizationResponse(org))
 }
 
-// ListOrganizations lists organizations with pagination
+// ListOrganizations lists organizations with filtering, sorting and pagination
+// @Summary List organizations
+// @Tags organizations
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(10)
+// @Param search query string false "Match against name and display name"
+// @Param status query int false "Filter by status"
+// @Param order_by query string false "Sort column (id, name, display_name, status, created_at, updated_at)" default(created_at)
+// @Param order query string false "Sort direction (asc or desc)" default(desc)
+// @Param cursor query string false "Opaque cursor from next_cursor; pass it empty to start cursor pagination (newest first, default sort only)"
+// @Success 200 {object} response.Response{data=PaginationResponse}
+// @Success 200 {object} response.Response{data=CursorPaginationResponse}
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations [get]
 func (h *Handler) ListOrganizations(c *gin.Context) {
-	pageStr := c.DefaultQuery("page", "1")
-	sizeStr := c.DefaultQuery("size", "10")
-
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page < 1 {
-		page = 1
-	}
-
-	size, err := strconv.Atoi(sizeStr)
-	if err != nil || size < 1 {
-		size = 10
+	var query ListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidQueryParams)
+		return
 	}
+	query.Normalize()
+	// Parse also honours the legacy "size" parameter and caps the page size
+	query.Page, query.PageSize = pagination.Parse(c, 10)
 
-	orgs, total, err := h.service.ListOrganizations(c.Request.Context(), page, size)
+	cursor, cursorMode, err := pagination.ParseCursor(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	var responses []gin.H
-	for _, org := range orgs {
-		responses = append(responses, gin.H{
-			"id":           org.ID,
-			"name":         org.Name,
-			"display_name": org.DisplayName,
-			"description":  org.Description,
-			"logo":         org.Logo,
-			"website":      org.Website,
-			"status":       org.Status,
-			"created_at":   org.CreatedAt,
-			"updated_at":   org.UpdatedAt,
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidParam, pagination.CursorParam)
+		return
+	}
+	if cursorMode {
+		if query.OrderBy != "created_at" || query.Order != pagination.SortDesc {
+			response.ErrorKey(c, http.StatusBadRequest, i18n.KeyCursorOrderUnsupported)
+			return
+		}
+		orgs, next, err := h.service.ListOrganizationsAfter(c.Request.Context(), &query, cursor)
+		if err != nil {
+			if response.Canceled(c, err) {
+				return
+			}
+			response.Error(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		response.Success(c, CursorPaginationResponse{
+			Size:       query.PageSize,
+			NextCursor: next,
+			Data:       toOrganizationResponses(orgs),
 		})
+		return
 	}
 
-	response := gin.H{
-		"total": total,
-		"page":  page,
-		"size":  size,
-		"data":  responses,
+	orgs, total, err := h.service.ListOrganizations(c.Request.Context(), &query)
+	if err != nil {
+		if response.Canceled(c, err) {
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	pagination.SetHeaders(c, total, query.Page, query.PageSize)
+	response.Success(c, PaginationResponse{
+		Total: total,
+		Page:  query.Page,
+		Size:  query.PageSize,
+		Data:  toOrganizationResponses(orgs),
+	})
 }
 
 // UpdateOrganization updates an organization
+// @Summary Update an organization
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param request body UpdateOrganizationRequest true "Organization update request"
+// @Success 200 {object} response.Response{data=OrganizationResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{id} [put]
 func (h *Handler) UpdateOrganization(c *gin.Context) {
 	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
+	id, err := params.ParseID(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID format"})
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidID)
+		return
+	}
+
+	userID, exists := authctx.UserID(c)
+	if !exists {
+		response.ErrorKey(c, http.StatusUnauthorized, i18n.KeyUnauthorized)
 		return
 	}
 
 	var req UpdateOrganizationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		response.Error(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	org, err := h.service.GetOrganization(c.Request.Context(), uint(id))
+	org, err := h.service.GetOrganization(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "organization not found"})
+		respondOrganizationError(c, err)
 		return
 	}
 
@@ -172,75 +221,143 @@ func (h *Handler) UpdateOrganization(c *gin.Context) {
 	if req.Website != "" {
 		org.Website = req.Website
 	}
+	if req.Settings != "" {
+		org.Settings = req.Settings
+	}
 	if req.Status != nil {
 		org.Status = *req.Status
 	}
-
-	if err := h.service.UpdateOrganization(c.Request.Context(), org); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+	if req.InheritTeamPermissions != nil {
+		org.InheritTeamPermissions = *req.InheritTeamPermissions
 	}
 
-	response := gin.H{
-		"id":           org.ID,
-		"name":         org.Name,
-		"display_name": org.DisplayName,
-		"description":  org.Description,
-		"logo":         org.Logo,
-		"website":      org.Website,
-		"status":       org.Status,
-		"created_at":   org.CreatedAt,
-		"updated_at":   org.UpdatedAt,
+	if err := h.service.UpdateOrganization(c.Request.Context(), org, userID); err != nil {
+		respondOrganizationError(c, err)
+		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	response.Success(c, toOrganizationResponse(org))
 }
 
-// DeleteOrganization deletes an organization
+// DeleteOrganization deletes an organization and its teams, members, invitations and roles.
+// Pass hard=true to remove the records permanently instead of soft-deleting them.
+// @Summary Delete an organization
+// @Description Delete an organization and its dependent records. Only the owner may delete it.
+// @Tags organizations
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param hard query bool false "Permanently delete instead of soft-deleting" default(false)
+// @Success 200 {object} response.Response{data=DeletionSummary}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{id} [delete]
 func (h *Handler) DeleteOrganization(c *gin.Context) {
 	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
+	id, err := params.ParseID(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID format"})
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidID)
 		return
 	}
 
-	if err := h.service.DeleteOrganization(c.Request.Context(), uint(id)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	userID, exists := authctx.UserID(c)
+	if !exists {
+		response.ErrorKey(c, http.StatusUnauthorized, i18n.KeyUnauthorized)
 		return
 	}
 
-	c.JSON(http.StatusNoContent, nil)
+	hard, _ := strconv.ParseBool(c.DefaultQuery("hard", "false"))
+
+	summary, err := h.service.DeleteOrganization(c.Request.Context(), id, userID, hard)
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	response.Success(c, summary)
 }
 
 // GetMyOrganizations gets organizations for the current user
+// @Summary List the current user's organizations
+// @Tags organizations
+// @Produce json
+// @Success 200 {object} response.Response{data=[]OrganizationResponse}
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/me [get]
 func (h *Handler) GetMyOrganizations(c *gin.Context) {
-	userID, exists := c.Get("userID")
+	userID, exists := authctx.UserID(c)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		response.ErrorKey(c, http.StatusUnauthorized, i18n.KeyUnauthorized)
 		return
 	}
 
-	orgs, err := h.service.GetUserOrganizations(c.Request.Context(), userID.(uint))
+	orgs, err := h.service.GetUserOrganizations(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	var responses []gin.H
-	for _, org := range orgs {
-		responses = append(responses, gin.H{
-			"id":           org.ID,
-			"name":         org.Name,
-			"display_name": org.DisplayName,
-			"description":  org.Description,
-			"logo":         org.Logo,
-			"website":      org.Website,
-			"status":       org.Status,
-			"created_at":   org.CreatedAt,
-			"updated_at":   org.UpdatedAt,
-		})
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, toOrganizationResponses(orgs))
+}
+
+// ExportMembers streams the organization's member roster as CSV or JSON
+// @Summary Export organization members
+// @Description Download the member roster as a file. Requires members.read in the organization. Errors use the shared response envelope.
+// @Tags organizations
+// @Produce text/csv,json
+// @Param id path int true "Organization ID"
+// @Param format query string false "Export format (csv or json)" default(csv)
+// @Success 200 {file} file
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{id}/members/export [get]
+func (h *Handler) ExportMembers(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := params.ParseID(idStr)
+	if err != nil {
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidID)
+		return
 	}
 
-	c.JSON(http.StatusOK, responses)
+	format := c.DefaultQuery("format", ExportFormatCSV)
+	contentType := "text/csv"
+	switch format {
+	case ExportFormatCSV:
+	case ExportFormatJSON:
+		contentType = "application/json"
+	default:
+		response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidExportFormat)
+		return
+	}
+
+	if _, err := h.service.GetOrganization(c.Request.Context(), id); err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("organization-%d-members.%s", id, format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	// Headers are already sent, so a failure part-way can only be logged
+	if err := h.service.ExportMembers(c.Request.Context(), id, format, c.Writer); err != nil {
+		logger.Error("Failed to export organization members", err)
+	}
+}
+
+// respondOrganizationError writes the status of typed and not-found errors, logging and
+// writing 500 for anything else
+func respondOrganizationError(c *gin.Context, err error) {
+	if status := response.StatusFromError(err, http.StatusInternalServerError); status != http.StatusInternalServerError {
+		response.ErrorFrom(c, status, err)
+		return
+	}
+	logger.Error("Organization request failed", err)
+	response.Error(c, http.StatusInternalServerError, err.Error())
 }
@@ -0,0 +1,139 @@
+package organization
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/app/authorization"
+	"github.com/llamacto/llama-gin-kit/pkg/authctx"
+	"gorm.io/gorm"
+)
+
+// organizationRepository is an in-memory Repository covering lookups and updates by ID; other
+// methods are left to the embedded nil interface and panic if called
+type organizationRepository struct {
+	Repository
+	err     error
+	ownerID uint
+	updated []*Organization
+}
+
+func (r *organizationRepository) GetOrganization(ctx context.Context, id uint) (*Organization, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &Organization{ID: id, Name: "acme", OwnerID: r.ownerID}, nil
+}
+
+func (r *organizationRepository) UpdateOrganization(ctx context.Context, org *Organization) error {
+	r.updated = append(r.updated, org)
+	return nil
+}
+
+// grantedAccess is an authorization.Service answering global role and organization permission
+// checks from fixed sets; other methods are left to the embedded nil interface and panic if called
+type grantedAccess struct {
+	authorization.Service
+	roles       map[uint]string
+	permissions map[uint]string
+}
+
+func (s *grantedAccess) HasRole(ctx context.Context, userID uint, roleName string) (bool, error) {
+	return s.roles[userID] == roleName, nil
+}
+
+func (s *grantedAccess) CheckOrganizationPermission(ctx context.Context, userID, organizationID uint, permission string) (bool, error) {
+	return s.permissions[userID] == permission, nil
+}
+
+func TestGetOrganizationStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		path    string
+		repoErr error
+		want    int
+	}{
+		{name: "existing organization", path: "/organizations/1", want: http.StatusOK},
+		{name: "missing organization", path: "/organizations/1", repoErr: gorm.ErrRecordNotFound, want: http.StatusNotFound},
+		{name: "wrapped missing organization", path: "/organizations/1", repoErr: fmt.Errorf("query: %w", gorm.ErrRecordNotFound), want: http.StatusNotFound},
+		{name: "database failure", path: "/organizations/1", repoErr: errors.New("connection refused"), want: http.StatusInternalServerError},
+		{name: "invalid ID", path: "/organizations/abc", want: http.StatusBadRequest},
+		{name: "zero ID", path: "/organizations/0", want: http.StatusBadRequest},
+		{name: "negative ID", path: "/organizations/-1", want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(&service{repo: &organizationRepository{err: tt.repoErr}})
+			r := gin.New()
+			r.GET("/organizations/:id", h.GetOrganization)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+func TestUpdateOrganizationRequiresOwnerOrPermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const (
+		ownerID  = 1
+		editorID = 2
+		adminID  = 3
+		readerID = 4
+	)
+	authService := &grantedAccess{
+		roles:       map[uint]string{adminID: "admin"},
+		permissions: map[uint]string{editorID: "organizations.update", readerID: "organizations.read"},
+	}
+
+	tests := []struct {
+		name   string
+		userID uint
+		want   int
+	}{
+		{name: "owner", userID: ownerID, want: http.StatusOK},
+		{name: "member with organizations.update", userID: editorID, want: http.StatusOK},
+		{name: "global admin", userID: adminID, want: http.StatusOK},
+		{name: "member without organizations.update", userID: readerID, want: http.StatusForbidden},
+		{name: "unauthenticated", want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &organizationRepository{ownerID: ownerID}
+			h := NewHandler(&service{repo: repo, authService: authService})
+			r := gin.New()
+			if tt.userID != 0 {
+				r.Use(func(c *gin.Context) { authctx.SetUserID(c, tt.userID) })
+			}
+			r.PUT("/organizations/:id", h.UpdateOrganization)
+
+			body := strings.NewReader(`{"display_name":"Acme Inc"}`)
+			req := httptest.NewRequest(http.MethodPut, "/organizations/7", body)
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if wrote := len(repo.updated) > 0; wrote != (tt.want == http.StatusOK) {
+				t.Fatalf("update written = %v for status %d", wrote, w.Code)
+			}
+		})
+	}
+}
@@ -3,18 +3,24 @@ package organization
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/llamacto/llama-gin-kit/pkg/pagination"
 	"gorm.io/gorm"
 )
 
 // Repository interface for organization data access
 type Repository interface {
 	CreateOrganization(ctx context.Context, org *Organization) error
+	CreateOrganizationWithDefaultTeam(ctx context.Context, org *Organization, creatorID uint) error
 	UpdateOrganization(ctx context.Context, org *Organization) error
 	DeleteOrganization(ctx context.Context, id uint) error
+	DeleteOrganizationCascade(ctx context.Context, id uint, hard bool) (*DeletionSummary, error)
 	GetOrganization(ctx context.Context, id uint) (*Organization, error)
-	ListOrganizations(ctx context.Context, page, pageSize int) ([]*Organization, int64, error)
+	ListOrganizations(ctx context.Context, query *ListQuery) ([]*Organization, int64, error)
+	ListOrganizationsAfter(ctx context.Context, query *ListQuery, cursor *pagination.Cursor) ([]*Organization, error)
 	GetOrganizationsByUserID(ctx context.Context, userID uint) ([]*Organization, error)
+	ListMembersForExport(ctx context.Context, orgID, afterID uint, limit int) ([]MemberExportRow, error)
 }
 
 // repository implementation of Repository
@@ -38,6 +44,67 @@ func (r *repository) CreateOrganization(ctx context.Context, org *Organization)
 	return err
 }
 
+// CreateOrganizationWithDefaultTeam creates an organization together with its default team and
+// puts the creator in that team, all in one transaction. Existing rows are reused, so repeating
+// the team and membership steps is safe.
+func (r *repository) CreateOrganizationWithDefaultTeam(ctx context.Context, org *Organization, creatorID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(org).Error; err != nil {
+			return err
+		}
+
+		var teamID uint
+		if err := tx.Raw(
+			"SELECT id FROM teams WHERE organization_id = ? AND name = ? AND deleted_at IS NULL LIMIT 1",
+			org.ID, DefaultTeamName,
+		).Scan(&teamID).Error; err != nil {
+			return err
+		}
+		if teamID == 0 {
+			now := time.Now()
+			if err := tx.Raw(
+				`INSERT INTO teams (name, display_name, organization_id, status, created_at, updated_at)
+				VALUES (?, ?, ?, 1, ?, ?) RETURNING id`,
+				DefaultTeamName, DefaultTeamDisplayName, org.ID, now, now,
+			).Scan(&teamID).Error; err != nil {
+				return fmt.Errorf("failed to create default team: %w", err)
+			}
+		}
+
+		var existing struct {
+			ID     uint
+			TeamID *uint
+		}
+		if err := tx.Raw(
+			"SELECT id, team_id FROM organization_members WHERE user_id = ? AND organization_id = ? AND deleted_at IS NULL LIMIT 1",
+			creatorID, org.ID,
+		).Scan(&existing).Error; err != nil {
+			return err
+		}
+
+		switch {
+		case existing.ID == 0:
+			now := time.Now()
+			if err := tx.Exec(
+				`INSERT INTO organization_members (user_id, organization_id, team_id, status, joined_at, invited_by, created_at, updated_at)
+				VALUES (?, ?, ?, 1, ?, ?, ?, ?)`,
+				creatorID, org.ID, teamID, now, creatorID, now, now,
+			).Error; err != nil {
+				return fmt.Errorf("failed to add creator to default team: %w", err)
+			}
+		case existing.TeamID == nil:
+			if err := tx.Exec(
+				"UPDATE organization_members SET team_id = ?, updated_at = ? WHERE id = ?",
+				teamID, time.Now(), existing.ID,
+			).Error; err != nil {
+				return fmt.Errorf("failed to add creator to default team: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
 // UpdateOrganization updates an existing organization
 func (r *repository) UpdateOrganization(ctx context.Context, org *Organization) error {
 	return r.db.WithContext(ctx).Save(org).Error
@@ -45,7 +112,60 @@ func (r *repository) UpdateOrganization(ctx context.Context, org *Organization)
 
 // DeleteOrganization removes an organization by ID
 func (r *repository) DeleteOrganization(ctx context.Context, id uint) error {
-	return r.db.WithContext(ctx).Delete(&Organization{}, id).Error
+	result := r.db.WithContext(ctx).Delete(&Organization{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// organizationChildTables lists the tables holding organization-scoped records
+var organizationChildTables = []string{"teams", "organization_members", "organization_invitations", "organization_roles"}
+
+// DeleteOrganizationCascade removes an organization and everything scoped to it in one
+// transaction. Records are soft-deleted unless hard is set. A missing or already deleted
+// organization returns gorm.ErrRecordNotFound.
+func (r *repository) DeleteOrganizationCascade(ctx context.Context, id uint, hard bool) (*DeletionSummary, error) {
+	summary := &DeletionSummary{OrganizationID: id, Hard: hard}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var org Organization
+		if err := tx.First(&org, id).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		counts := make([]int64, len(organizationChildTables))
+		for i, table := range organizationChildTables {
+			var result *gorm.DB
+			if hard {
+				result = tx.Exec("DELETE FROM "+table+" WHERE organization_id = ?", id)
+			} else {
+				result = tx.Table(table).
+					Where("organization_id = ? AND deleted_at IS NULL", id).
+					Update("deleted_at", now)
+			}
+			if result.Error != nil {
+				return fmt.Errorf("failed to delete %s: %w", table, result.Error)
+			}
+			counts[i] = result.RowsAffected
+		}
+		summary.Teams, summary.Members = counts[0], counts[1]
+		summary.Invitations, summary.OrganizationRoles = counts[2], counts[3]
+
+		db := tx
+		if hard {
+			db = tx.Unscoped()
+		}
+		return db.Delete(&Organization{}, id).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return summary, nil
 }
 
 // GetOrganization retrieves an organization by ID
@@ -57,24 +177,58 @@ func (r *repository) GetOrganization(ctx context.Context, id uint) (*Organizatio
 	return &org, nil
 }
 
-// ListOrganizations retrieves organizations with pagination
-func (r *repository) ListOrganizations(ctx context.Context, page, pageSize int) ([]*Organization, int64, error) {
+// ListOrganizations retrieves organizations with filtering, sorting and pagination.
+// The total counts only the organizations matching the filters.
+func (r *repository) ListOrganizations(ctx context.Context, query *ListQuery) ([]*Organization, int64, error) {
 	var orgs []*Organization
 	var total int64
 
-	offset := (page - 1) * pageSize
+	db := r.db.WithContext(ctx).Model(&Organization{}).Scopes(organizationFilters(query))
 
-	if err := r.db.WithContext(ctx).Model(&Organization{}).Count(&total).Error; err != nil {
+	if err := db.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	if err := r.db.WithContext(ctx).Offset(offset).Limit(pageSize).Find(&orgs).Error; err != nil {
+	err := db.Scopes(pagination.Paginate(query.Page, query.PageSize)).
+		Order(organizationOrderClause(query)).
+		Order("id DESC").
+		Find(&orgs).Error
+	if err != nil {
 		return nil, 0, err
 	}
 
 	return orgs, total, nil
 }
 
+// ListOrganizationsAfter retrieves up to PageSize+1 organizations matching the filters,
+// newest first, continuing after cursor (nil for the first page). Sorting options are ignored.
+func (r *repository) ListOrganizationsAfter(ctx context.Context, query *ListQuery, cursor *pagination.Cursor) ([]*Organization, error) {
+	var orgs []*Organization
+	err := r.db.WithContext(ctx).Model(&Organization{}).
+		Scopes(organizationFilters(query), pagination.CursorScope("", cursor, query.PageSize)).
+		Find(&orgs).Error
+	return orgs, err
+}
+
+// organizationFilters returns a scope applying the list query's search and status filters
+func organizationFilters(query *ListQuery) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if query.Search != "" {
+			search := "%" + query.Search + "%"
+			db = db.Where("name ILIKE ? OR display_name ILIKE ?", search, search)
+		}
+		if query.Status != nil {
+			db = db.Where("status = ?", *query.Status)
+		}
+		return db
+	}
+}
+
+// organizationOrderClause builds a safe ORDER BY clause from the list query
+func organizationOrderClause(query *ListQuery) string {
+	return organizationSort.Clause("", query.OrderBy, query.Order)
+}
+
 // GetOrganizationsByUserID retrieves all organizations for a user
 func (r *repository) GetOrganizationsByUserID(ctx context.Context, userID uint) ([]*Organization, error) {
 	var orgs []*Organization
@@ -89,3 +243,26 @@ func (r *repository) GetOrganizationsByUserID(ctx context.Context, userID uint)
 	}
 	return orgs, nil
 }
+
+// ListMembersForExport retrieves a batch of organization members ordered by ID,
+// starting after afterID. Keyset pagination keeps large exports cheap.
+func (r *repository) ListMembersForExport(ctx context.Context, orgID, afterID uint, limit int) ([]MemberExportRow, error) {
+	var rows []MemberExportRow
+
+	err := r.db.WithContext(ctx).Table("organization_members AS om").
+		Select(`
+			om.id, om.user_id, om.status, om.joined_at,
+			u.username, u.email,
+			ro.name AS role_name
+		`).
+		Joins("LEFT JOIN users u ON om.user_id = u.id").
+		Joins("LEFT JOIN roles ro ON om.role_id = ro.id").
+		Where("om.organization_id = ? AND om.deleted_at IS NULL AND om.id > ?", orgID, afterID).
+		Order("om.id ASC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
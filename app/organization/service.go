@@ -2,61 +2,196 @@ package organization
 
 import (
 	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
 
+	"github.com/llamacto/llama-gin-kit/app/authorization"
 	"github.com/llamacto/llama-gin-kit/app/user"
+	apperrors "github.com/llamacto/llama-gin-kit/pkg/errors"
+	"github.com/llamacto/llama-gin-kit/pkg/i18n"
+	"github.com/llamacto/llama-gin-kit/pkg/pagination"
+	"github.com/llamacto/llama-gin-kit/pkg/softdelete"
+	"github.com/llamacto/llama-gin-kit/pkg/validator"
 	"gorm.io/gorm"
 )
 
+var (
+	// ErrNotOrganizationOwner is returned when a user other than the owner or an admin deletes an organization
+	ErrNotOrganizationOwner = apperrors.Forbidden(i18n.KeyNotOrganizationOwner, "only the organization owner can delete it")
+	// ErrOrganizationUpdateForbidden is returned when a user other than the owner, an admin or a
+	// member holding organizations.update changes an organization
+	ErrOrganizationUpdateForbidden = apperrors.Forbidden(i18n.KeyOrganizationUpdateForbidden, "only the organization owner or a member with organizations.update can change it")
+	// ErrOrganizationNotFound is returned when the organization does not exist
+	ErrOrganizationNotFound = apperrors.NotFound(i18n.KeyOrganizationNotFound, "organization not found")
+)
+
 // Service interface for organization business logic
 type Service interface {
-	CreateOrganization(ctx context.Context, org *Organization, userID uint) error
-	UpdateOrganization(ctx context.Context, org *Organization) error
-	DeleteOrganization(ctx context.Context, id uint) error
+	CreateOrganization(ctx context.Context, org *Organization, userID uint, opts CreateOptions) error
+	UpdateOrganization(ctx context.Context, org *Organization, userID uint) error
+	DeleteOrganization(ctx context.Context, id, userID uint, hard bool) (*DeletionSummary, error)
 	GetOrganization(ctx context.Context, id uint) (*Organization, error)
-	ListOrganizations(ctx context.Context, page, pageSize int) ([]*Organization, int64, error)
+	ListOrganizations(ctx context.Context, query *ListQuery) ([]*Organization, int64, error)
+	ListOrganizationsAfter(ctx context.Context, query *ListQuery, cursor *pagination.Cursor) ([]*Organization, string, error)
 	GetUserOrganizations(ctx context.Context, userID uint) ([]*Organization, error)
 	GetOrganizationStats(ctx context.Context, id uint) (*OrganizationStats, error)
+	ExportMembers(ctx context.Context, orgID uint, format string, w io.Writer) error
 }
 
+// Supported member export formats
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatJSON = "json"
+)
+
+// exportBatchSize is the number of members loaded per query during an export
+const exportBatchSize = 500
+
 // service implementation of Service
 type service struct {
 	repo        Repository
 	userService user.UserService
+	authService authorization.Service
 	db          *gorm.DB
 }
 
 // NewService creates a new organization service
-func NewService(repo Repository, userService user.UserService, db *gorm.DB) Service {
+func NewService(repo Repository, userService user.UserService, authService authorization.Service, db *gorm.DB) Service {
 	return &service{
 		repo:        repo,
 		userService: userService,
+		authService: authService,
 		db:          db,
 	}
 }
 
-// CreateOrganization adds a new organization
-func (s *service) CreateOrganization(ctx context.Context, org *Organization, userID uint) error {
+// CreateOptions controls optional setup performed when an organization is created
+type CreateOptions struct {
+	CreateDefaultTeam bool // Also create the "general" team and add the creator to it
+}
+
+// CreateOrganization adds a new organization owned by userID
+func (s *service) CreateOrganization(ctx context.Context, org *Organization, userID uint, opts CreateOptions) error {
+	if err := validator.CheckReservedName(org.Name); err != nil {
+		return err
+	}
+	if err := normalizeSettings(org); err != nil {
+		return err
+	}
+
+	org.OwnerID = userID
+	if opts.CreateDefaultTeam {
+		return s.repo.CreateOrganizationWithDefaultTeam(ctx, org, userID)
+	}
 	return s.repo.CreateOrganization(ctx, org)
 }
 
-// UpdateOrganization updates an existing organization
-func (s *service) UpdateOrganization(ctx context.Context, org *Organization) error {
+// UpdateOrganization updates an existing organization. Only the owner, a global admin or a
+// member holding organizations.update may change an organization.
+func (s *service) UpdateOrganization(ctx context.Context, org *Organization, userID uint) error {
+	if org.OwnerID != userID {
+		allowed, err := s.isGlobalAdmin(ctx, userID)
+		if err == nil && !allowed {
+			allowed, err = s.authService.CheckOrganizationPermission(ctx, userID, org.ID, "organizations.update")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to check permissions: %w", err)
+		}
+		if !allowed {
+			return ErrOrganizationUpdateForbidden
+		}
+	}
+
+	if err := normalizeSettings(org); err != nil {
+		return err
+	}
 	return s.repo.UpdateOrganization(ctx, org)
 }
 
-// DeleteOrganization removes an organization by ID
-func (s *service) DeleteOrganization(ctx context.Context, id uint) error {
-	return s.repo.DeleteOrganization(ctx, id)
+// normalizeSettings rejects settings that are not a JSON object or fail SettingsSchema with a
+// *settings.InvalidError, and stores them in canonical form
+func normalizeSettings(org *Organization) error {
+	normalized, err := SettingsSchema.Normalize("settings", org.Settings)
+	if err != nil {
+		return err
+	}
+	org.Settings = normalized
+	return nil
+}
+
+// DeleteOrganization removes an organization with its teams, members, invitations and
+// organization roles. Only the owner or a global admin may delete an organization.
+func (s *service) DeleteOrganization(ctx context.Context, id, userID uint, hard bool) (*DeletionSummary, error) {
+	org, err := s.GetOrganization(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if org.OwnerID != userID {
+		isAdmin, err := s.isGlobalAdmin(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check permissions: %w", err)
+		}
+		if !isAdmin {
+			return nil, ErrNotOrganizationOwner
+		}
+	}
+
+	summary, err := s.repo.DeleteOrganizationCascade(ctx, id, hard)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete organization: %w", err)
+	}
+	authorization.InvalidateOrganizationPermissions(ctx, id)
+	return summary, nil
 }
 
-// GetOrganization retrieves an organization by ID
+// isGlobalAdmin reports whether the user holds a global admin role
+func (s *service) isGlobalAdmin(ctx context.Context, userID uint) (bool, error) {
+	for _, role := range []string{"super_admin", "admin"} {
+		has, err := s.authService.HasRole(ctx, userID, role)
+		if err != nil || has {
+			return has, err
+		}
+	}
+	return false, nil
+}
+
+// GetOrganization retrieves an organization by ID, returning ErrOrganizationNotFound when it does not exist
 func (s *service) GetOrganization(ctx context.Context, id uint) (*Organization, error) {
-	return s.repo.GetOrganization(ctx, id)
+	org, err := s.repo.GetOrganization(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrOrganizationNotFound
+	}
+	return org, err
+}
+
+// ListOrganizations retrieves organizations matching the query with pagination
+func (s *service) ListOrganizations(ctx context.Context, query *ListQuery) ([]*Organization, int64, error) {
+	return s.repo.ListOrganizations(ctx, query)
 }
 
-// ListOrganizations retrieves organizations with pagination
-func (s *service) ListOrganizations(ctx context.Context, page, pageSize int) ([]*Organization, int64, error) {
-	return s.repo.ListOrganizations(ctx, page, pageSize)
+// ListOrganizationsAfter retrieves a page of organizations matching the query, newest first,
+// continuing after cursor. It also returns the cursor for the next page, empty at the end of the list.
+func (s *service) ListOrganizationsAfter(ctx context.Context, query *ListQuery, cursor *pagination.Cursor) ([]*Organization, string, error) {
+	orgs, err := s.repo.ListOrganizationsAfter(ctx, query, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	fetched := len(orgs)
+	if fetched > query.PageSize {
+		orgs = orgs[:query.PageSize]
+	}
+	var last pagination.Cursor
+	if len(orgs) > 0 {
+		last = pagination.Cursor{CreatedAt: orgs[len(orgs)-1].CreatedAt, ID: orgs[len(orgs)-1].ID}
+	}
+	return orgs, pagination.NextCursor(fetched, query.PageSize, last), nil
 }
 
 // GetUserOrganizations retrieves all organizations for a user
@@ -66,7 +201,7 @@ func (s *service) GetUserOrganizations(ctx context.Context, userID uint) ([]*Org
 
 // GetOrganizationStats retrieves organization statistics
 func (s *service) GetOrganizationStats(ctx context.Context, id uint) (*OrganizationStats, error) {
-	org, err := s.repo.GetOrganization(ctx, id)
+	org, err := s.GetOrganization(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -75,29 +210,125 @@ func (s *service) GetOrganizationStats(ctx context.Context, id uint) (*Organizat
 		Organization: *org,
 	}
 
+	db := s.db.WithContext(ctx)
+
 	// Get member count
-	err = s.db.Table("organization_members").
-		Where("organization_id = ? AND deleted_at IS NULL", id).
-		Count(&stats.MemberCount).Error
+	stats.MemberCount, err = softdelete.Count(db, "organization_members", "organization_id = ?", id)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get team count
-	err = s.db.Table("teams").
-		Where("organization_id = ? AND deleted_at IS NULL", id).
-		Count(&stats.TeamCount).Error
+	stats.TeamCount, err = softdelete.Count(db, "teams", "organization_id = ?", id)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get role count
-	err = s.db.Table("organization_roles").
-		Where("organization_id = ? AND deleted_at IS NULL", id).
-		Count(&stats.RoleCount).Error
+	stats.RoleCount, err = softdelete.Count(db, "organization_roles", "organization_id = ?", id)
 	if err != nil {
 		return nil, err
 	}
 
 	return stats, nil
 }
+
+// ExportMembers writes every member of an organization to w as CSV or JSON.
+// Members are read in batches so large organizations are never held in memory at once.
+func (s *service) ExportMembers(ctx context.Context, orgID uint, format string, w io.Writer) error {
+	switch format {
+	case ExportFormatCSV:
+		return s.exportMembersCSV(ctx, orgID, w)
+	case ExportFormatJSON:
+		return s.exportMembersJSON(ctx, orgID, w)
+	default:
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// exportMembersCSV streams members as CSV with a header row
+func (s *service) exportMembersCSV(ctx context.Context, orgID uint, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"id", "user_id", "username", "email", "role", "status", "joined_at"}); err != nil {
+		return err
+	}
+
+	err := s.eachMemberBatch(ctx, orgID, func(rows []MemberExportRow) error {
+		for _, row := range rows {
+			record := []string{
+				strconv.FormatUint(uint64(row.ID), 10),
+				strconv.FormatUint(uint64(row.UserID), 10),
+				row.Username,
+				row.Email,
+				row.RoleName,
+				strconv.Itoa(row.Status),
+				row.JoinedAt.Format(time.RFC3339),
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// exportMembersJSON streams members as a JSON array
+func (s *service) exportMembersJSON(ctx context.Context, orgID uint, w io.Writer) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+
+	first := true
+	err := s.eachMemberBatch(ctx, orgID, func(rows []MemberExportRow) error {
+		for _, row := range rows {
+			data, err := json.Marshal(row)
+			if err != nil {
+				return err
+			}
+			if !first {
+				if _, err := io.WriteString(w, ","); err != nil {
+					return err
+				}
+			}
+			first = false
+			if _, err := w.Write(data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "]")
+	return err
+}
+
+// eachMemberBatch walks an organization's members in ID order, one batch at a time
+func (s *service) eachMemberBatch(ctx context.Context, orgID uint, fn func([]MemberExportRow) error) error {
+	var afterID uint
+	for {
+		rows, err := s.repo.ListMembersForExport(ctx, orgID, afterID, exportBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to load members: %w", err)
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		if err := fn(rows); err != nil {
+			return err
+		}
+		if len(rows) < exportBatchSize {
+			return nil
+		}
+		afterID = rows[len(rows)-1].ID
+	}
+}
@@ -0,0 +1,85 @@
+package organization
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/llamacto/llama-gin-kit/pkg/dbtest"
+)
+
+func TestCreateOrganizationDefaultTeam(t *testing.T) {
+	const (
+		orgID     = 5
+		teamID    = 8
+		creatorID = 3
+	)
+
+	tests := []struct {
+		name           string
+		enabled        bool
+		teamExists     bool
+		memberTeamID   driver.Value // Team of the creator's existing membership; NULL when nil
+		member         bool         // Whether the creator is already a member
+		wantTeam       bool
+		wantMember     bool
+		wantAssignTeam bool
+	}{
+		{name: "disabled"},
+		{name: "enabled", enabled: true, wantTeam: true, wantMember: true},
+		{name: "default team and membership exist", enabled: true, teamExists: true, member: true, memberTeamID: int64(teamID)},
+		{name: "membership without a team", enabled: true, teamExists: true, member: true, wantAssignTeam: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, db := dbtest.Open(t)
+			db.Returns(`INSERT INTO "organizations"`, []string{"id"}, []driver.Value{int64(orgID)})
+			db.Returns(`INSERT INTO teams`, []string{"id"}, []driver.Value{int64(teamID)})
+			if tt.teamExists {
+				db.Returns(`SELECT id FROM teams`, []string{"id"}, []driver.Value{int64(teamID)})
+			}
+			if tt.member {
+				db.Returns(`SELECT id, team_id FROM organization_members`, []string{"id", "team_id"}, []driver.Value{int64(11), tt.memberTeamID})
+			}
+
+			org := &Organization{Name: "acme", DisplayName: "Acme"}
+			s := &service{repo: NewRepository(gormDB)}
+			if err := s.CreateOrganization(context.Background(), org, creatorID, CreateOptions{CreateDefaultTeam: tt.enabled}); err != nil {
+				t.Fatalf("CreateOrganization() error = %v", err)
+			}
+			if org.ID != orgID || org.OwnerID != creatorID {
+				t.Fatalf("organization = %+v, want ID %d owned by %d", org, orgID, creatorID)
+			}
+
+			team, created := db.Find(`INSERT INTO teams`)
+			if created != tt.wantTeam {
+				t.Fatalf("default team created = %v, want %v", created, tt.wantTeam)
+			}
+			if created && (team.Args[0] != DefaultTeamName || team.Args[2] != int64(orgID)) {
+				t.Fatalf("default team inserted with %v, want %q in organization %d", team.Args, DefaultTeamName, orgID)
+			}
+
+			member, added := db.Find(`INSERT INTO organization_members`)
+			if added != tt.wantMember {
+				t.Fatalf("creator membership created = %v, want %v", added, tt.wantMember)
+			}
+			// Args: user_id, organization_id, team_id, ...
+			if added && (member.Args[0] != int64(creatorID) || member.Args[1] != int64(orgID) || member.Args[2] != int64(teamID)) {
+				t.Fatalf("creator membership inserted with %v, want user %d in team %d of organization %d", member.Args, creatorID, teamID, orgID)
+			}
+
+			assign, assigned := db.Find(`UPDATE organization_members SET team_id`)
+			if assigned != tt.wantAssignTeam {
+				t.Fatalf("existing membership moved to the default team = %v, want %v", assigned, tt.wantAssignTeam)
+			}
+			if assigned && assign.Args[0] != int64(teamID) {
+				t.Fatalf("existing membership moved to team %v, want %d", assign.Args[0], teamID)
+			}
+
+			if _, inTx := db.Find("BEGIN"); tt.enabled && !inTx {
+				t.Fatal("default team setup ran outside a transaction")
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package orgdomain
+
+import "time"
+
+// CreateDomainRequest represents the request payload for claiming a domain
+type CreateDomainRequest struct {
+	Domain   string `json:"domain" binding:"required,max=253"`
+	RoleID   uint   `json:"role_id" binding:"required"`
+	AutoJoin *bool  `json:"auto_join"` // Optional, defaults to true when APP_REQUIRE_APPROVAL is on
+}
+
+// UpdateDomainRequest represents the request payload for changing a domain's join settings
+type UpdateDomainRequest struct {
+	RoleID   *uint `json:"role_id"`
+	AutoJoin *bool `json:"auto_join"`
+}
+
+// VerificationRecord is the DNS record an organization publishes to prove it owns a domain
+type VerificationRecord struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// DomainResponse represents the response structure for domain data
+type DomainResponse struct {
+	ID             uint       `json:"id"`
+	OrganizationID uint       `json:"organization_id"`
+	Domain         string     `json:"domain"`
+	RoleID         uint       `json:"role_id"`
+	AutoJoin       bool       `json:"auto_join"`
+	Verified       bool       `json:"verified"`
+	VerifiedAt     *time.Time `json:"verified_at"`
+	CreatedBy      uint       `json:"created_by"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	// Present until the domain is verified
+	VerificationRecord *VerificationRecord `json:"verification_record,omitempty"`
+}
+
+// toResponse converts a domain to its response form
+func toResponse(d *Domain) DomainResponse {
+	resp := DomainResponse{
+		ID:             d.ID,
+		OrganizationID: d.OrganizationID,
+		Domain:         d.Domain,
+		RoleID:         d.RoleID,
+		AutoJoin:       d.AutoJoin,
+		Verified:       d.Verified(),
+		VerifiedAt:     d.VerifiedAt,
+		CreatedBy:      d.CreatedBy,
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
+	}
+	if !d.Verified() {
+		resp.VerificationRecord = &VerificationRecord{
+			Type:  "TXT",
+			Name:  d.Domain,
+			Value: VerificationPrefix + d.VerificationToken,
+		}
+	}
+	return resp
+}
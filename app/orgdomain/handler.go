@@ -0,0 +1,222 @@
+package orgdomain
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/pkg/authctx"
+	"github.com/llamacto/llama-gin-kit/pkg/params"
+	"github.com/llamacto/llama-gin-kit/pkg/response"
+)
+
+// Handler defines the interface for organization domain HTTP handlers
+type Handler interface {
+	ListDomains(c *gin.Context)
+	AddDomain(c *gin.Context)
+	VerifyDomain(c *gin.Context)
+	UpdateDomain(c *gin.Context)
+	DeleteDomain(c *gin.Context)
+}
+
+// handler implements the Handler interface
+type handler struct {
+	service Service
+}
+
+// NewHandler creates a new organization domain handler instance
+func NewHandler(service Service) Handler {
+	return &handler{service: service}
+}
+
+// ListDomains lists an organization's email domains
+// @Summary List organization domains
+// @Description List the email domains claimed by an organization, with the DNS record still needed for unverified ones
+// @Tags organization-domains
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {object} response.Response{data=[]DomainResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /v1/organizations/{id}/domains [get]
+func (h *handler) ListDomains(c *gin.Context) {
+	orgID, ok := parseID(c, "id", "organization")
+	if !ok {
+		return
+	}
+
+	domains, err := h.service.ListDomains(c.Request.Context(), orgID)
+	if err != nil {
+		if response.Canceled(c, err) {
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, domains)
+}
+
+// AddDomain claims an email domain for an organization
+// @Summary Add organization domain
+// @Description Claim an email domain. Publish the returned TXT record, then call verify. Once verified and auto_join is on, users with an address at the domain join the organization with role_id when an admin approves their account. auto_join can only be turned on when APP_REQUIRE_APPROVAL is set. A domain can only be verified by one organization
+// @Tags organization-domains
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param request body CreateDomainRequest true "Domain claim"
+// @Success 201 {object} response.Response{data=DomainResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /v1/organizations/{id}/domains [post]
+func (h *handler) AddDomain(c *gin.Context) {
+	orgID, ok := parseID(c, "id", "organization")
+	if !ok {
+		return
+	}
+
+	var req CreateDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	userID, ok := authctx.RequireUserID(c)
+	if !ok {
+		return
+	}
+
+	domain, err := h.service.AddDomain(c.Request.Context(), orgID, &req, userID)
+	if err != nil {
+		response.ErrorFrom(c, domainErrorStatus(err), err)
+		return
+	}
+
+	response.Created(c, domain)
+}
+
+// VerifyDomain checks the DNS record for a claimed domain
+// @Summary Verify organization domain
+// @Description Look up the domain's TXT records and mark the claim verified when the verification record is present
+// @Tags organization-domains
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param domain_id path int true "Domain ID"
+// @Success 200 {object} response.Response{data=DomainResponse}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Router /v1/organizations/{id}/domains/{domain_id}/verify [post]
+func (h *handler) VerifyDomain(c *gin.Context) {
+	orgID, ok := parseID(c, "id", "organization")
+	if !ok {
+		return
+	}
+	id, ok := parseID(c, "domain_id", "domain")
+	if !ok {
+		return
+	}
+
+	domain, err := h.service.VerifyDomain(c.Request.Context(), orgID, id)
+	if err != nil {
+		response.ErrorFrom(c, domainErrorStatus(err), err)
+		return
+	}
+
+	response.Success(c, domain)
+}
+
+// UpdateDomain changes how users join through a domain
+// @Summary Update organization domain
+// @Description Change the role given to users joining through the domain, or turn auto-join on or off. Turning auto-join on requires APP_REQUIRE_APPROVAL
+// @Tags organization-domains
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param domain_id path int true "Domain ID"
+// @Param request body UpdateDomainRequest true "Domain settings"
+// @Success 200 {object} response.Response{data=DomainResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /v1/organizations/{id}/domains/{domain_id} [put]
+func (h *handler) UpdateDomain(c *gin.Context) {
+	orgID, ok := parseID(c, "id", "organization")
+	if !ok {
+		return
+	}
+	id, ok := parseID(c, "domain_id", "domain")
+	if !ok {
+		return
+	}
+
+	var req UpdateDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	userID, ok := authctx.RequireUserID(c)
+	if !ok {
+		return
+	}
+
+	domain, err := h.service.UpdateDomain(c.Request.Context(), orgID, id, &req, userID)
+	if err != nil {
+		response.ErrorFrom(c, domainErrorStatus(err), err)
+		return
+	}
+
+	response.Success(c, domain)
+}
+
+// DeleteDomain removes a domain claim
+// @Summary Delete organization domain
+// @Description Remove a domain claim. Existing members stay; new signups no longer auto-join
+// @Tags organization-domains
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param domain_id path int true "Domain ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /v1/organizations/{id}/domains/{domain_id} [delete]
+func (h *handler) DeleteDomain(c *gin.Context) {
+	orgID, ok := parseID(c, "id", "organization")
+	if !ok {
+		return
+	}
+	id, ok := parseID(c, "domain_id", "domain")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteDomain(c.Request.Context(), orgID, id); err != nil {
+		response.ErrorFrom(c, domainErrorStatus(err), err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// domainErrorStatus maps service errors to HTTP status codes: 422 for a failed
+// verification and the typed error's status otherwise
+func domainErrorStatus(err error) int {
+	if errors.Is(err, ErrVerificationFailed) {
+		return http.StatusUnprocessableEntity
+	}
+	return response.StatusFromError(err, http.StatusInternalServerError)
+}
+
+// parseID reads a positive ID path parameter, writing an error response when it is invalid
+func parseID(c *gin.Context, param, label string) (uint, bool) {
+	id, err := params.PathID(c, param)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid "+label+" ID")
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,46 @@
+package orgdomain
+
+import (
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/pkg/invariant"
+	"gorm.io/gorm"
+)
+
+// Domain is an email domain claimed by an organization. Once verified, users with an
+// address at the domain join the organization automatically when an admin approves
+// their account and AutoJoin is set. Only one organization can hold a verified claim on
+// a domain at a time.
+type Domain struct {
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+	OrganizationID uint           `gorm:"not null;index" json:"organization_id"`
+	// Lower-case domain without a trailing dot, e.g. "acme.com"
+	Domain            string     `gorm:"size:253;not null;index;uniqueIndex:idx_organization_domains_verified,where:verified_at IS NOT NULL AND deleted_at IS NULL" json:"domain"`
+	RoleID            uint       `gorm:"not null" json:"role_id"` // Role given to members who join through the domain
+	AutoJoin          bool       `gorm:"not null" json:"auto_join"`
+	VerificationToken string     `gorm:"size:64;not null" json:"-"`
+	VerifiedAt        *time.Time `json:"verified_at"`
+	CreatedBy         uint       `json:"created_by"`
+}
+
+// TableName specifies the database table name
+func (Domain) TableName() string {
+	return "organization_domains"
+}
+
+// BeforeSave rejects domain rows without an organization, domain or role
+func (d *Domain) BeforeSave(tx *gorm.DB) error {
+	return invariant.Required(tx, "organization domain",
+		invariant.Field{Column: "organization_id", Value: d.OrganizationID},
+		invariant.Field{Column: "domain", Value: d.Domain},
+		invariant.Field{Column: "role_id", Value: d.RoleID},
+	)
+}
+
+// Verified reports whether ownership of the domain has been proven
+func (d *Domain) Verified() bool {
+	return d.VerifiedAt != nil
+}
@@ -0,0 +1,146 @@
+package orgdomain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/llamacto/llama-gin-kit/app/member"
+	"github.com/llamacto/llama-gin-kit/pkg/softdelete"
+	"gorm.io/gorm"
+)
+
+// uniqueViolation is the PostgreSQL error code for a unique constraint violation
+const uniqueViolation = "23505"
+
+// Repository defines the interface for organization domain data operations
+type Repository interface {
+	Create(ctx context.Context, domain *Domain) error
+	GetByID(ctx context.Context, organizationID, id uint) (*Domain, error)
+	GetByOrganizationAndDomain(ctx context.Context, organizationID uint, domain string) (*Domain, error)
+	ListByOrganization(ctx context.Context, organizationID uint) ([]Domain, error)
+	GetVerified(ctx context.Context, domain string) (*Domain, error)
+	MarkVerified(ctx context.Context, id uint, verifiedAt time.Time) error
+	Update(ctx context.Context, id uint, updates map[string]interface{}) error
+	Delete(ctx context.Context, id uint) error
+	OrganizationExists(ctx context.Context, organizationID uint) (bool, error)
+	AddMember(ctx context.Context, userID, organizationID, roleID uint) (bool, error)
+}
+
+// repository implements the Repository interface
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new organization domain repository instance
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// Create creates a new domain claim
+func (r *repository) Create(ctx context.Context, domain *Domain) error {
+	return r.db.WithContext(ctx).Create(domain).Error
+}
+
+// GetByID retrieves a domain claim belonging to the organization
+func (r *repository) GetByID(ctx context.Context, organizationID, id uint) (*Domain, error) {
+	var domain Domain
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND organization_id = ?", id, organizationID).
+		First(&domain).Error
+	if err != nil {
+		return nil, err
+	}
+	return &domain, nil
+}
+
+// GetByOrganizationAndDomain retrieves the organization's claim on a domain
+func (r *repository) GetByOrganizationAndDomain(ctx context.Context, organizationID uint, domain string) (*Domain, error) {
+	var d Domain
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND domain = ?", organizationID, domain).
+		First(&d).Error
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// ListByOrganization retrieves all domain claims of an organization
+func (r *repository) ListByOrganization(ctx context.Context, organizationID uint) ([]Domain, error) {
+	var domains []Domain
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ?", organizationID).
+		Order("domain ASC").
+		Find(&domains).Error
+	return domains, err
+}
+
+// GetVerified retrieves the verified claim on a domain held by an active, non-deleted organization
+func (r *repository) GetVerified(ctx context.Context, domain string) (*Domain, error) {
+	var d Domain
+	err := r.db.WithContext(ctx).
+		Joins("JOIN organizations o ON o.id = organization_domains.organization_id AND o.deleted_at IS NULL AND o.status = 1").
+		Where("organization_domains.domain = ? AND organization_domains.verified_at IS NOT NULL", domain).
+		First(&d).Error
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// MarkVerified records a successful verification. It returns ErrDomainClaimed if another
+// organization verified the domain first.
+func (r *repository) MarkVerified(ctx context.Context, id uint, verifiedAt time.Time) error {
+	err := r.db.WithContext(ctx).Model(&Domain{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"verified_at": verifiedAt, "updated_at": verifiedAt}).Error
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return ErrDomainClaimed
+	}
+	return err
+}
+
+// Update updates a domain claim's settings
+func (r *repository) Update(ctx context.Context, id uint, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&Domain{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// Delete soft-deletes a domain claim
+func (r *repository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&Domain{}, id).Error
+}
+
+// OrganizationExists checks whether a non-deleted organization exists
+func (r *repository) OrganizationExists(ctx context.Context, organizationID uint) (bool, error) {
+	return softdelete.Exists(r.db.WithContext(ctx), "organizations", "id = ?", organizationID)
+}
+
+// AddMember adds the user to the organization with the role unless they are already a
+// member. It reports whether a membership was created.
+func (r *repository) AddMember(ctx context.Context, userID, organizationID, roleID uint) (bool, error) {
+	created := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		err := tx.Model(&member.Member{}).
+			Where("user_id = ? AND organization_id = ?", userID, organizationID).
+			Count(&count).Error
+		if err != nil || count > 0 {
+			return err
+		}
+
+		err = tx.Create(&member.Member{
+			UserID:         userID,
+			OrganizationID: organizationID,
+			RoleID:         roleID,
+			Status:         1,
+			JoinedAt:       time.Now(),
+		}).Error
+		created = err == nil
+		return err
+	})
+	return created, err
+}
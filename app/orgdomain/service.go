@@ -0,0 +1,377 @@
+package orgdomain
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/app/authorization"
+	"github.com/llamacto/llama-gin-kit/app/user"
+	"github.com/llamacto/llama-gin-kit/config"
+	apperrors "github.com/llamacto/llama-gin-kit/pkg/errors"
+	"github.com/llamacto/llama-gin-kit/pkg/events"
+	"github.com/llamacto/llama-gin-kit/pkg/i18n"
+	"github.com/llamacto/llama-gin-kit/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// VerificationPrefix starts the TXT record value that proves ownership of a domain
+const VerificationPrefix = "llama-gin-kit-verification="
+
+var (
+	// ErrInvalidDomain is returned for values that are not a valid DNS domain name
+	ErrInvalidDomain = apperrors.Validation("invalid_domain", "invalid domain")
+	// ErrPublicDomain is returned when claiming a domain shared by unrelated users, such as gmail.com
+	ErrPublicDomain = apperrors.Validation("public_domain", "public email domains cannot be claimed")
+	// ErrDomainNotFound is returned when the organization has no claim with the given ID
+	ErrDomainNotFound = apperrors.NotFound("domain_not_found", "domain not found")
+	// ErrDomainExists is returned when the organization has already claimed the domain
+	ErrDomainExists = apperrors.AlreadyExists("domain_exists", "domain already added to this organization")
+	// ErrDomainClaimed is returned when another organization has verified the domain
+	ErrDomainClaimed = apperrors.Conflict("domain_claimed", "domain is verified by another organization")
+	// ErrVerificationFailed is returned when the verification TXT record is missing
+	ErrVerificationFailed = apperrors.Validation("domain_verification_failed", "verification TXT record not found")
+	// ErrAutoJoinRequiresApproval is returned when enabling auto-join while new accounts are
+	// active at signup, since signing up does not prove the user owns the email address
+	ErrAutoJoinRequiresApproval = apperrors.Validation("auto_join_requires_approval", "auto-join requires APP_REQUIRE_APPROVAL")
+	// ErrOrganizationNotFound is returned when the organization does not exist
+	ErrOrganizationNotFound = apperrors.NotFound(i18n.KeyOrganizationNotFound, "organization not found")
+)
+
+// publicEmailDomains are shared mailbox providers no organization may claim
+var publicEmailDomains = map[string]bool{
+	"gmail.com":      true,
+	"googlemail.com": true,
+	"outlook.com":    true,
+	"hotmail.com":    true,
+	"live.com":       true,
+	"yahoo.com":      true,
+	"icloud.com":     true,
+	"me.com":         true,
+	"aol.com":        true,
+	"proton.me":      true,
+	"protonmail.com": true,
+	"qq.com":         true,
+	"163.com":        true,
+	"126.com":        true,
+}
+
+// lookupTXT resolves TXT records; replaced in tests
+var lookupTXT = net.DefaultResolver.LookupTXT
+
+// Service defines the interface for organization domain business logic
+type Service interface {
+	AddDomain(ctx context.Context, organizationID uint, req *CreateDomainRequest, actorID uint) (*DomainResponse, error)
+	VerifyDomain(ctx context.Context, organizationID, id uint) (*DomainResponse, error)
+	UpdateDomain(ctx context.Context, organizationID, id uint, req *UpdateDomainRequest, actorID uint) (*DomainResponse, error)
+	DeleteDomain(ctx context.Context, organizationID, id uint) error
+	ListDomains(ctx context.Context, organizationID uint) ([]DomainResponse, error)
+	AutoJoin(ctx context.Context, userID uint, email string) (uint, error)
+}
+
+// service implements the Service interface
+type service struct {
+	repo        Repository
+	authService authorization.Service
+}
+
+// NewService creates a new organization domain service instance
+func NewService(repo Repository, authService authorization.Service) Service {
+	return &service{
+		repo:        repo,
+		authService: authService,
+	}
+}
+
+// AddDomain claims a domain for the organization. The claim stays inactive until
+// VerifyDomain finds the returned TXT record.
+func (s *service) AddDomain(ctx context.Context, organizationID uint, req *CreateDomainRequest, actorID uint) (*DomainResponse, error) {
+	domain, err := normalizeDomain(req.Domain)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := s.repo.OrganizationExists(ctx, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check organization: %w", err)
+	}
+	if !exists {
+		return nil, ErrOrganizationNotFound
+	}
+	if err := s.authService.CheckCanGrantRole(ctx, actorID, organizationID, req.RoleID); err != nil {
+		return nil, err
+	}
+	autoJoin := approvalRequired()
+	if req.AutoJoin != nil {
+		if *req.AutoJoin && !autoJoin {
+			return nil, ErrAutoJoinRequiresApproval
+		}
+		autoJoin = *req.AutoJoin
+	}
+
+	if _, err := s.repo.GetByOrganizationAndDomain(ctx, organizationID, domain); err == nil {
+		return nil, ErrDomainExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to check domain: %w", err)
+	}
+	if err := s.checkUnclaimed(ctx, domain, organizationID); err != nil {
+		return nil, err
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Domain{
+		OrganizationID:    organizationID,
+		Domain:            domain,
+		RoleID:            req.RoleID,
+		AutoJoin:          autoJoin,
+		VerificationToken: token,
+		CreatedBy:         actorID,
+	}
+	if err := s.repo.Create(ctx, d); err != nil {
+		return nil, fmt.Errorf("failed to add domain: %w", err)
+	}
+
+	resp := toResponse(d)
+	return &resp, nil
+}
+
+// VerifyDomain checks the domain's DNS for the verification TXT record and marks the
+// claim verified when it is present
+func (s *service) VerifyDomain(ctx context.Context, organizationID, id uint) (*DomainResponse, error) {
+	d, err := s.getDomain(ctx, organizationID, id)
+	if err != nil {
+		return nil, err
+	}
+	if d.Verified() {
+		resp := toResponse(d)
+		return &resp, nil
+	}
+	if err := s.checkUnclaimed(ctx, d.Domain, organizationID); err != nil {
+		return nil, err
+	}
+
+	records, err := lookupTXT(ctx, d.Domain)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return nil, ErrVerificationFailed
+		}
+		return nil, fmt.Errorf("failed to look up TXT records: %w", err)
+	}
+	if !containsRecord(records, VerificationPrefix+d.VerificationToken) {
+		return nil, ErrVerificationFailed
+	}
+
+	now := time.Now()
+	if err := s.repo.MarkVerified(ctx, d.ID, now); err != nil {
+		if errors.Is(err, ErrDomainClaimed) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to verify domain: %w", err)
+	}
+	d.VerifiedAt = &now
+	d.UpdatedAt = now
+
+	resp := toResponse(d)
+	return &resp, nil
+}
+
+// UpdateDomain changes the role or auto-join setting of a claim
+func (s *service) UpdateDomain(ctx context.Context, organizationID, id uint, req *UpdateDomainRequest, actorID uint) (*DomainResponse, error) {
+	d, err := s.getDomain(ctx, organizationID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if req.RoleID != nil && *req.RoleID != d.RoleID {
+		if err := s.authService.CheckCanGrantRole(ctx, actorID, organizationID, *req.RoleID); err != nil {
+			return nil, err
+		}
+		updates["role_id"] = *req.RoleID
+		d.RoleID = *req.RoleID
+	}
+	if req.AutoJoin != nil && *req.AutoJoin != d.AutoJoin {
+		if *req.AutoJoin && !approvalRequired() {
+			return nil, ErrAutoJoinRequiresApproval
+		}
+		updates["auto_join"] = *req.AutoJoin
+		d.AutoJoin = *req.AutoJoin
+	}
+	if len(updates) > 0 {
+		if err := s.repo.Update(ctx, d.ID, updates); err != nil {
+			return nil, fmt.Errorf("failed to update domain: %w", err)
+		}
+	}
+
+	resp := toResponse(d)
+	return &resp, nil
+}
+
+// DeleteDomain removes a claim, stopping auto-join and freeing the domain for other organizations
+func (s *service) DeleteDomain(ctx context.Context, organizationID, id uint) error {
+	d, err := s.getDomain(ctx, organizationID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, d.ID); err != nil {
+		return fmt.Errorf("failed to delete domain: %w", err)
+	}
+	return nil
+}
+
+// ListDomains lists the organization's domain claims
+func (s *service) ListDomains(ctx context.Context, organizationID uint) ([]DomainResponse, error) {
+	domains, err := s.repo.ListByOrganization(ctx, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list domains: %w", err)
+	}
+
+	responses := make([]DomainResponse, 0, len(domains))
+	for i := range domains {
+		responses = append(responses, toResponse(&domains[i]))
+	}
+	return responses, nil
+}
+
+// AutoJoin adds the user to the organization holding a verified auto-join claim on
+// their email domain. It returns the joined organization's ID, or zero when no claim
+// matches or the user is already a member.
+func (s *service) AutoJoin(ctx context.Context, userID uint, email string) (uint, error) {
+	domain, ok := emailDomain(email)
+	if !ok {
+		return 0, nil
+	}
+
+	d, err := s.repo.GetVerified(ctx, domain)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to look up domain: %w", err)
+	}
+	if !d.AutoJoin {
+		return 0, nil
+	}
+
+	created, err := s.repo.AddMember(ctx, userID, d.OrganizationID, d.RoleID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add member: %w", err)
+	}
+	if !created {
+		return 0, nil
+	}
+	authorization.InvalidateOrganizationPermissions(ctx, d.OrganizationID, userID)
+	events.Publish(d.OrganizationID, events.TypeMemberAdded, events.MemberData{UserID: userID, RoleID: d.RoleID})
+	return d.OrganizationID, nil
+}
+
+// AutoJoinHook returns a user approval hook that applies domain auto-join. It runs only
+// once an admin has approved the account, never at signup, so an unproven address cannot
+// join. Failures are logged so they never block approval.
+func AutoJoinHook(s Service) user.ApprovalHook {
+	return func(ctx context.Context, u *user.User) {
+		orgID, err := s.AutoJoin(ctx, u.ID, u.Email)
+		if err != nil {
+			logger.Error("Failed to apply domain auto-join", err)
+			return
+		}
+		if orgID != 0 {
+			logger.Info("User joined organization by email domain", fmt.Sprintf("user_id: %d", u.ID), fmt.Sprintf("organization_id: %d", orgID))
+		}
+	}
+}
+
+// getDomain loads one of the organization's claims, mapping a missing row to ErrDomainNotFound
+func (s *service) getDomain(ctx context.Context, organizationID, id uint) (*Domain, error) {
+	d, err := s.repo.GetByID(ctx, organizationID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDomainNotFound
+		}
+		return nil, fmt.Errorf("failed to get domain: %w", err)
+	}
+	return d, nil
+}
+
+// checkUnclaimed returns ErrDomainClaimed when an organization other than organizationID
+// holds a verified claim on domain
+func (s *service) checkUnclaimed(ctx context.Context, domain string, organizationID uint) error {
+	existing, err := s.repo.GetVerified(ctx, domain)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to check domain: %w", err)
+	}
+	if existing.OrganizationID != organizationID {
+		return ErrDomainClaimed
+	}
+	return nil
+}
+
+// approvalRequired reports whether new accounts wait for admin approval, the only point at
+// which an email address is vouched for and auto-join may run
+func approvalRequired() bool {
+	return config.GlobalConfig != nil && config.GlobalConfig.App.RequireApproval
+}
+
+// normalizeDomain lower-cases a domain name and checks that it is a claimable DNS name
+func normalizeDomain(raw string) (string, error) {
+	domain := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(raw)), ".")
+	if len(domain) == 0 || len(domain) > 253 || !strings.Contains(domain, ".") {
+		return "", ErrInvalidDomain
+	}
+	for _, label := range strings.Split(domain, ".") {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return "", ErrInvalidDomain
+		}
+		for _, r := range label {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+				return "", ErrInvalidDomain
+			}
+		}
+	}
+	if publicEmailDomains[domain] {
+		return "", ErrPublicDomain
+	}
+	return domain, nil
+}
+
+// emailDomain returns the normalized domain of an email address
+func emailDomain(email string) (string, bool) {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return "", false
+	}
+	domain, err := normalizeDomain(email[at+1:])
+	return domain, err == nil
+}
+
+// containsRecord reports whether any TXT record equals want
+func containsRecord(records []string, want string) bool {
+	for _, record := range records {
+		if strings.TrimSpace(record) == want {
+			return true
+		}
+	}
+	return false
+}
+
+// generateToken returns a random hex verification token
+func generateToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate verification token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
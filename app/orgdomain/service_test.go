@@ -0,0 +1,405 @@
+package orgdomain
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/app/authorization"
+	"github.com/llamacto/llama-gin-kit/app/user"
+	"github.com/llamacto/llama-gin-kit/config"
+	"github.com/llamacto/llama-gin-kit/pkg/dbtest"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// domainStore is an in-memory Repository; methods the tests do not reach are left to the
+// embedded nil interface and panic if called
+type domainStore struct {
+	Repository
+	domains []Domain
+	members map[[2]uint]uint // {userID, organizationID} -> roleID
+}
+
+func (s *domainStore) Create(ctx context.Context, d *Domain) error {
+	d.ID = uint(len(s.domains) + 1)
+	s.domains = append(s.domains, *d)
+	return nil
+}
+
+func (s *domainStore) GetByID(ctx context.Context, organizationID, id uint) (*Domain, error) {
+	for i := range s.domains {
+		if d := s.domains[i]; d.ID == id && d.OrganizationID == organizationID {
+			return &d, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (s *domainStore) GetByOrganizationAndDomain(ctx context.Context, organizationID uint, domain string) (*Domain, error) {
+	for _, d := range s.domains {
+		if d.OrganizationID == organizationID && d.Domain == domain {
+			return &d, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (s *domainStore) GetVerified(ctx context.Context, domain string) (*Domain, error) {
+	for _, d := range s.domains {
+		if d.Domain == domain && d.Verified() {
+			return &d, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (s *domainStore) MarkVerified(ctx context.Context, id uint, verifiedAt time.Time) error {
+	for i := range s.domains {
+		if s.domains[i].ID == id {
+			s.domains[i].VerifiedAt = &verifiedAt
+		}
+	}
+	return nil
+}
+
+func (s *domainStore) Update(ctx context.Context, id uint, updates map[string]interface{}) error {
+	for i := range s.domains {
+		if s.domains[i].ID != id {
+			continue
+		}
+		if autoJoin, ok := updates["auto_join"].(bool); ok {
+			s.domains[i].AutoJoin = autoJoin
+		}
+		if roleID, ok := updates["role_id"].(uint); ok {
+			s.domains[i].RoleID = roleID
+		}
+	}
+	return nil
+}
+
+func (s *domainStore) OrganizationExists(ctx context.Context, organizationID uint) (bool, error) {
+	return true, nil
+}
+
+func (s *domainStore) AddMember(ctx context.Context, userID, organizationID, roleID uint) (bool, error) {
+	key := [2]uint{userID, organizationID}
+	if _, ok := s.members[key]; ok {
+		return false, nil
+	}
+	if s.members == nil {
+		s.members = make(map[[2]uint]uint)
+	}
+	s.members[key] = roleID
+	return true, nil
+}
+
+// grantAnyRole is an authorization.Service that lets every actor grant every role
+type grantAnyRole struct {
+	authorization.Service
+}
+
+func (grantAnyRole) CheckCanGrantRole(ctx context.Context, userID, organizationID, roleID uint) error {
+	return nil
+}
+
+// verifiedAt is a fixed verification time for seeded claims
+var verifiedAt = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// useApproval installs a config with APP_REQUIRE_APPROVAL set as given, restoring the
+// previous config when the test ends
+func useApproval(t *testing.T, requireApproval bool) {
+	t.Helper()
+	saved := config.GlobalConfig
+	t.Cleanup(func() { config.GlobalConfig = saved })
+	config.GlobalConfig = &config.Config{
+		App: config.AppConfig{BcryptCost: bcrypt.MinCost, RequireApproval: requireApproval},
+	}
+}
+
+func TestAutoJoin(t *testing.T) {
+	const (
+		acmeID   = 1
+		memberID = 4
+	)
+
+	tests := []struct {
+		name      string
+		email     string
+		claims    []Domain
+		members   map[[2]uint]uint
+		wantOrg   uint
+		wantRoles map[[2]uint]uint
+	}{
+		{
+			name:      "matching verified domain",
+			email:     "alice@acme.com",
+			claims:    []Domain{{ID: 1, OrganizationID: acmeID, Domain: "acme.com", RoleID: memberID, AutoJoin: true, VerifiedAt: &verifiedAt}},
+			wantOrg:   acmeID,
+			wantRoles: map[[2]uint]uint{{7, acmeID}: memberID},
+		},
+		{
+			name:    "domain match ignores case",
+			email:   "Alice@ACME.com",
+			claims:  []Domain{{ID: 1, OrganizationID: acmeID, Domain: "acme.com", RoleID: memberID, AutoJoin: true, VerifiedAt: &verifiedAt}},
+			wantOrg: acmeID,
+		},
+		{
+			name:   "non-matching domain",
+			email:  "bob@example.com",
+			claims: []Domain{{ID: 1, OrganizationID: acmeID, Domain: "acme.com", RoleID: memberID, AutoJoin: true, VerifiedAt: &verifiedAt}},
+		},
+		{
+			name:   "subdomain does not match",
+			email:  "bob@mail.acme.com",
+			claims: []Domain{{ID: 1, OrganizationID: acmeID, Domain: "acme.com", RoleID: memberID, AutoJoin: true, VerifiedAt: &verifiedAt}},
+		},
+		{
+			name:   "unverified claim",
+			email:  "alice@acme.com",
+			claims: []Domain{{ID: 1, OrganizationID: acmeID, Domain: "acme.com", RoleID: memberID, AutoJoin: true}},
+		},
+		{
+			name:   "auto-join turned off",
+			email:  "alice@acme.com",
+			claims: []Domain{{ID: 1, OrganizationID: acmeID, Domain: "acme.com", RoleID: memberID, VerifiedAt: &verifiedAt}},
+		},
+		{
+			name:      "already a member",
+			email:     "alice@acme.com",
+			claims:    []Domain{{ID: 1, OrganizationID: acmeID, Domain: "acme.com", RoleID: memberID, AutoJoin: true, VerifiedAt: &verifiedAt}},
+			members:   map[[2]uint]uint{{7, acmeID}: 2},
+			wantRoles: map[[2]uint]uint{{7, acmeID}: 2},
+		},
+		{
+			name:  "no email domain",
+			email: "alice",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &domainStore{domains: tt.claims, members: tt.members}
+			svc := NewService(repo, grantAnyRole{})
+
+			orgID, err := svc.AutoJoin(context.Background(), 7, tt.email)
+			if err != nil {
+				t.Fatalf("AutoJoin() error = %v", err)
+			}
+			if orgID != tt.wantOrg {
+				t.Fatalf("AutoJoin() = %d, want %d", orgID, tt.wantOrg)
+			}
+			if tt.wantOrg == 0 && tt.members == nil && len(repo.members) != 0 {
+				t.Fatalf("members = %v, want none", repo.members)
+			}
+			for key, role := range tt.wantRoles {
+				if repo.members[key] != role {
+					t.Fatalf("member %v has role %d, want %d", key, repo.members[key], role)
+				}
+			}
+		})
+	}
+}
+
+func TestConflictingDomainClaims(t *testing.T) {
+	const (
+		acmeID  = 1
+		otherID = 2
+	)
+	saved := lookupTXT
+	t.Cleanup(func() { lookupTXT = saved })
+
+	tests := []struct {
+		name    string
+		claims  []Domain
+		run     func(Service) error
+		wantErr error
+	}{
+		{
+			name:   "adding a domain another organization verified",
+			claims: []Domain{{ID: 1, OrganizationID: acmeID, Domain: "acme.com", RoleID: 4, VerifiedAt: &verifiedAt}},
+			run: func(s Service) error {
+				_, err := s.AddDomain(context.Background(), otherID, &CreateDomainRequest{Domain: "ACME.com", RoleID: 4}, 9)
+				return err
+			},
+			wantErr: ErrDomainClaimed,
+		},
+		{
+			name:   "adding a domain the organization already claimed",
+			claims: []Domain{{ID: 1, OrganizationID: acmeID, Domain: "acme.com", RoleID: 4}},
+			run: func(s Service) error {
+				_, err := s.AddDomain(context.Background(), acmeID, &CreateDomainRequest{Domain: "acme.com", RoleID: 4}, 9)
+				return err
+			},
+			wantErr: ErrDomainExists,
+		},
+		{
+			name:   "adding an unverified domain another organization claimed",
+			claims: []Domain{{ID: 1, OrganizationID: acmeID, Domain: "acme.com", RoleID: 4}},
+			run: func(s Service) error {
+				_, err := s.AddDomain(context.Background(), otherID, &CreateDomainRequest{Domain: "acme.com", RoleID: 4}, 9)
+				return err
+			},
+		},
+		{
+			name: "adding a public mailbox domain",
+			run: func(s Service) error {
+				_, err := s.AddDomain(context.Background(), acmeID, &CreateDomainRequest{Domain: "gmail.com", RoleID: 4}, 9)
+				return err
+			},
+			wantErr: ErrPublicDomain,
+		},
+		{
+			name: "verifying after another organization verified first",
+			claims: []Domain{
+				{ID: 1, OrganizationID: acmeID, Domain: "acme.com", RoleID: 4, VerifiedAt: &verifiedAt},
+				{ID: 2, OrganizationID: otherID, Domain: "acme.com", RoleID: 4, VerificationToken: "tok"},
+			},
+			run: func(s Service) error {
+				_, err := s.VerifyDomain(context.Background(), otherID, 2)
+				return err
+			},
+			wantErr: ErrDomainClaimed,
+		},
+		{
+			name:   "verifying with the TXT record present",
+			claims: []Domain{{ID: 1, OrganizationID: acmeID, Domain: "acme.com", RoleID: 4, VerificationToken: "tok"}},
+			run: func(s Service) error {
+				d, err := s.VerifyDomain(context.Background(), acmeID, 1)
+				if err == nil && d.VerifiedAt == nil {
+					return errors.New("VerifyDomain() left the domain unverified")
+				}
+				return err
+			},
+		},
+		{
+			name:   "verifying without the TXT record",
+			claims: []Domain{{ID: 1, OrganizationID: acmeID, Domain: "acme.com", RoleID: 4, VerificationToken: "other"}},
+			run: func(s Service) error {
+				_, err := s.VerifyDomain(context.Background(), acmeID, 1)
+				return err
+			},
+			wantErr: ErrVerificationFailed,
+		},
+	}
+
+	lookupTXT = func(ctx context.Context, name string) ([]string, error) {
+		return []string{"v=spf1 -all", VerificationPrefix + "tok"}, nil
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewService(&domainStore{domains: tt.claims}, grantAnyRole{})
+			if err := tt.run(svc); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAutoJoinRequiresApproval(t *testing.T) {
+	on, off := true, false
+	acme := []Domain{{ID: 1, OrganizationID: 1, Domain: "acme.com", RoleID: 4, VerifiedAt: &verifiedAt}}
+
+	tests := []struct {
+		name            string
+		requireApproval bool
+		claims          []Domain
+		run             func(Service) (*DomainResponse, error)
+		wantAutoJoin    bool
+		wantErr         error
+	}{
+		{
+			name:            "adding defaults to auto-join with approval on",
+			requireApproval: true,
+			run: func(s Service) (*DomainResponse, error) {
+				return s.AddDomain(context.Background(), 1, &CreateDomainRequest{Domain: "acme.com", RoleID: 4}, 9)
+			},
+			wantAutoJoin: true,
+		},
+		{
+			name: "adding defaults to no auto-join with approval off",
+			run: func(s Service) (*DomainResponse, error) {
+				return s.AddDomain(context.Background(), 1, &CreateDomainRequest{Domain: "acme.com", RoleID: 4}, 9)
+			},
+		},
+		{
+			name: "adding with auto-join and approval off",
+			run: func(s Service) (*DomainResponse, error) {
+				return s.AddDomain(context.Background(), 1, &CreateDomainRequest{Domain: "acme.com", RoleID: 4, AutoJoin: &on}, 9)
+			},
+			wantErr: ErrAutoJoinRequiresApproval,
+		},
+		{
+			name:   "turning auto-join on with approval off",
+			claims: acme,
+			run: func(s Service) (*DomainResponse, error) {
+				return s.UpdateDomain(context.Background(), 1, 1, &UpdateDomainRequest{AutoJoin: &on}, 9)
+			},
+			wantErr: ErrAutoJoinRequiresApproval,
+		},
+		{
+			name:            "turning auto-join on with approval on",
+			requireApproval: true,
+			claims:          acme,
+			run: func(s Service) (*DomainResponse, error) {
+				return s.UpdateDomain(context.Background(), 1, 1, &UpdateDomainRequest{AutoJoin: &on}, 9)
+			},
+			wantAutoJoin: true,
+		},
+		{
+			name:   "turning auto-join off with approval off",
+			claims: []Domain{{ID: 1, OrganizationID: 1, Domain: "acme.com", RoleID: 4, AutoJoin: true, VerifiedAt: &verifiedAt}},
+			run: func(s Service) (*DomainResponse, error) {
+				return s.UpdateDomain(context.Background(), 1, 1, &UpdateDomainRequest{AutoJoin: &off}, 9)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useApproval(t, tt.requireApproval)
+			svc := NewService(&domainStore{domains: tt.claims}, grantAnyRole{})
+
+			d, err := tt.run(svc)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && d.AutoJoin != tt.wantAutoJoin {
+				t.Fatalf("auto_join = %v, want %v", d.AutoJoin, tt.wantAutoJoin)
+			}
+		})
+	}
+}
+
+func TestAutoJoinWaitsForApproval(t *testing.T) {
+	useApproval(t, true)
+	repo := &domainStore{domains: []Domain{{ID: 1, OrganizationID: 1, Domain: "acme.com", RoleID: 4, AutoJoin: true, VerifiedAt: &verifiedAt}}}
+	hook := AutoJoinHook(NewService(repo, grantAnyRole{}))
+
+	// Signing up does not prove the address, so the user stays out of the organization
+	gormDB, db := dbtest.Open(t)
+	db.Returns(`INSERT INTO "users"`, []string{"id"}, []driver.Value{int64(7)})
+	users := user.NewUserService(user.NewUserRepository(gormDB))
+	users.OnApproved(hook)
+	if _, err := users.Register(context.Background(), &user.UserRegisterRequest{Username: "alice", Email: "alice@acme.com", Password: "secret"}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if len(repo.members) != 0 {
+		t.Fatalf("members after signup = %v, want none", repo.members)
+	}
+
+	// Approval vouches for the address and applies auto-join
+	gormDB, db = dbtest.Open(t)
+	db.Returns(`FROM "users" WHERE "users"."id"`, []string{"id", "username", "email", "status"},
+		[]driver.Value{int64(7), "alice", "alice@acme.com", int64(user.UserStatusPending)})
+	users = user.NewUserService(user.NewUserRepository(gormDB))
+	users.OnApproved(hook)
+	if _, err := users.ApproveUser(context.Background(), 7); err != nil {
+		t.Fatalf("ApproveUser() error = %v", err)
+	}
+	if role, ok := repo.members[[2]uint{7, 1}]; !ok || role != 4 {
+		t.Fatalf("members after approval = %v, want user 7 in organization 1 with role 4", repo.members)
+	}
+}
@@ -7,7 +7,7 @@ type CreateTeamRequest struct {
 	Description    string `json:"description" binding:"max=500"`
 	OrganizationID uint   `json:"organization_id" binding:"required"`
 	ParentTeamID   *uint  `json:"parent_team_id"`
-	// Settings       string `json:"settings"` // Temporarily disabled
+	Settings       string `json:"settings"` // JSON object; omitted means {}
 }
 
 // UpdateTeamRequest represents the request payload for updating a team
@@ -16,8 +16,8 @@ type UpdateTeamRequest struct {
 	DisplayName  string `json:"display_name" binding:"max=100"`
 	Description  string `json:"description" binding:"max=500"`
 	ParentTeamID *uint  `json:"parent_team_id"`
-	// Settings     string `json:"settings"` // Temporarily disabled
-	Status *int `json:"status"`
+	Settings     string `json:"settings"` // Replaces the whole document when set
+	Status       *int   `json:"status"`
 }
 
 // TeamResponse represents the response structure for team data
@@ -28,11 +28,11 @@ type TeamResponse struct {
 	Description    string `json:"description"`
 	OrganizationID uint   `json:"organization_id"`
 	ParentTeamID   *uint  `json:"parent_team_id"`
-	// Settings       string `json:"settings"` // Temporarily disabled
-	Status      int    `json:"status"`
-	MemberCount int64  `json:"member_count"`
-	CreatedAt   string `json:"created_at"`
-	UpdatedAt   string `json:"updated_at"`
+	Settings       string `json:"settings"`
+	Status         int    `json:"status"`
+	MemberCount    int64  `json:"member_count"`
+	CreatedAt      string `json:"created_at"`
+	UpdatedAt      string `json:"updated_at"`
 }
 
 // TeamListResponse represents the response structure for team list
@@ -50,3 +50,9 @@ type TeamHierarchyResponse struct {
 	Parent   *TeamResponse  `json:"parent,omitempty"`
 	Children []TeamResponse `json:"children,omitempty"`
 }
+
+// TeamTreeNode represents a team and its descendants in an organization's team tree
+type TeamTreeNode struct {
+	TeamResponse
+	Children []*TeamTreeNode `json:"children"`
+}
@@ -5,6 +5,9 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/pkg/authctx"
+	"github.com/llamacto/llama-gin-kit/pkg/pagination"
+	"github.com/llamacto/llama-gin-kit/pkg/params"
 	"github.com/llamacto/llama-gin-kit/pkg/response"
 )
 
@@ -16,6 +19,8 @@ type Handler interface {
 	UpdateTeam(c *gin.Context)
 	DeleteTeam(c *gin.Context)
 	GetTeamHierarchy(c *gin.Context)
+	GetTeamTree(c *gin.Context)
+	GetUserTeams(c *gin.Context)
 }
 
 // handler implements the Handler interface
@@ -47,21 +52,15 @@ func (h *handler) CreateTeam(c *gin.Context) {
 	}
 
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := authctx.UserID(c)
 	if !exists {
 		response.Error(c, http.StatusUnauthorized, "User not authenticated")
 		return
 	}
 
-	userIDUint, ok := userID.(uint)
-	if !ok {
-		response.Error(c, http.StatusInternalServerError, "Invalid user ID format")
-		return
-	}
-
-	team, err := h.service.CreateTeam(&req, userIDUint)
+	team, err := h.service.CreateTeam(c.Request.Context(), &req, userID)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, "Failed to create team")
+		respondTeamError(c, err, "Failed to create team")
 		return
 	}
 
@@ -80,16 +79,15 @@ func (h *handler) CreateTeam(c *gin.Context) {
 // @Failure 404 {object} response.Response
 // @Router /api/v1/teams/{id} [get]
 func (h *handler) GetTeam(c *gin.Context) {
-	idParam := c.Param("id")
-	id, err := strconv.ParseUint(idParam, 10, 32)
+	id, err := params.PathID(c, "id")
 	if err != nil {
 		response.Error(c, http.StatusBadRequest, "Invalid team ID")
 		return
 	}
 
-	team, err := h.service.GetTeamByID(uint(id))
+	team, err := h.service.GetTeamByID(c.Request.Context(), id)
 	if err != nil {
-		response.Error(c, http.StatusNotFound, "Team not found")
+		respondTeamError(c, err, "Failed to retrieve team")
 		return
 	}
 
@@ -110,23 +108,24 @@ func (h *handler) GetTeam(c *gin.Context) {
 // @Failure 500 {object} response.Response
 // @Router /api/v1/organizations/{organization_id}/teams [get]
 func (h *handler) GetTeamsByOrganization(c *gin.Context) {
-	orgIDParam := c.Param("organization_id")
-	organizationID, err := strconv.ParseUint(orgIDParam, 10, 32)
+	organizationID, err := params.PathID(c, "organization_id")
 	if err != nil {
 		response.Error(c, http.StatusBadRequest, "Invalid organization ID")
 		return
 	}
 
-	// Parse pagination parameters
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	page, pageSize := pagination.Parse(c, pagination.DefaultPageSize)
 
-	teams, err := h.service.GetTeamsByOrganization(uint(organizationID), page, pageSize)
+	teams, err := h.service.GetTeamsByOrganization(c.Request.Context(), organizationID, page, pageSize)
 	if err != nil {
+		if response.Canceled(c, err) {
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, "Failed to retrieve teams")
 		return
 	}
 
+	pagination.SetHeaders(c, teams.Total, teams.Page, teams.PageSize)
 	response.Success(c, teams)
 }
 
@@ -144,8 +143,7 @@ func (h *handler) GetTeamsByOrganization(c *gin.Context) {
 // @Failure 500 {object} response.Response
 // @Router /api/v1/teams/{id} [put]
 func (h *handler) UpdateTeam(c *gin.Context) {
-	idParam := c.Param("id")
-	id, err := strconv.ParseUint(idParam, 10, 32)
+	id, err := params.PathID(c, "id")
 	if err != nil {
 		response.Error(c, http.StatusBadRequest, "Invalid team ID")
 		return
@@ -157,9 +155,9 @@ func (h *handler) UpdateTeam(c *gin.Context) {
 		return
 	}
 
-	team, err := h.service.UpdateTeam(uint(id), &req)
+	team, err := h.service.UpdateTeam(c.Request.Context(), id, &req)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, "Failed to update team")
+		respondTeamError(c, err, "Failed to update team")
 		return
 	}
 
@@ -168,27 +166,31 @@ func (h *handler) UpdateTeam(c *gin.Context) {
 
 // DeleteTeam deletes a team
 // @Summary Delete team
-// @Description Delete a team
+// @Description Delete a team. Teams with members or child teams are rejected unless force is set,
+// @Description which moves members to the organization level and reparents child teams
 // @Tags teams
 // @Accept json
 // @Produce json
 // @Param id path int true "Team ID"
+// @Param force query bool false "Delete even if the team has members or child teams"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
 // @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/teams/{id} [delete]
 func (h *handler) DeleteTeam(c *gin.Context) {
-	idParam := c.Param("id")
-	id, err := strconv.ParseUint(idParam, 10, 32)
+	id, err := params.PathID(c, "id")
 	if err != nil {
 		response.Error(c, http.StatusBadRequest, "Invalid team ID")
 		return
 	}
 
-	err = h.service.DeleteTeam(uint(id))
+	force, _ := strconv.ParseBool(c.DefaultQuery("force", "false"))
+
+	err = h.service.DeleteTeam(c.Request.Context(), id, force)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, "Failed to delete team")
+		respondTeamError(c, err, "Failed to delete team")
 		return
 	}
 
@@ -207,14 +209,13 @@ func (h *handler) DeleteTeam(c *gin.Context) {
 // @Failure 404 {object} response.Response
 // @Router /api/v1/teams/{id}/hierarchy [get]
 func (h *handler) GetTeamHierarchy(c *gin.Context) {
-	idParam := c.Param("id")
-	id, err := strconv.ParseUint(idParam, 10, 32)
+	id, err := params.PathID(c, "id")
 	if err != nil {
 		response.Error(c, http.StatusBadRequest, "Invalid team ID")
 		return
 	}
 
-	hierarchy, err := h.service.GetTeamHierarchy(uint(id))
+	hierarchy, err := h.service.GetTeamHierarchy(c.Request.Context(), id)
 	if err != nil {
 		response.Error(c, http.StatusNotFound, "Team hierarchy not found")
 		return
@@ -222,3 +223,78 @@ func (h *handler) GetTeamHierarchy(c *gin.Context) {
 
 	response.Success(c, hierarchy)
 }
+
+// GetTeamTree retrieves all teams of an organization as a nested tree
+// @Summary Get organization team tree
+// @Description Get every team in an organization nested under its parent. Teams without a parent are roots. Requires teams.read in the organization
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {object} response.Response{data=[]TeamTreeNode}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{id}/teams/tree [get]
+func (h *handler) GetTeamTree(c *gin.Context) {
+	organizationID, err := params.PathID(c, "id")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid organization ID")
+		return
+	}
+
+	tree, err := h.service.GetTeamTree(c.Request.Context(), organizationID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to retrieve team tree")
+		return
+	}
+
+	response.Success(c, tree)
+}
+
+// GetUserTeams retrieves the teams a user belongs to within an organization
+// @Summary Get user teams
+// @Description Get the teams a user belongs to through active membership in an organization. Listing another user's teams requires members.read in the organization
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param userId path int true "User ID"
+// @Success 200 {object} response.Response{data=[]TeamResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/organizations/{id}/users/{userId}/teams [get]
+func (h *handler) GetUserTeams(c *gin.Context) {
+	organizationID, err := params.PathID(c, "id")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid organization ID")
+		return
+	}
+
+	userID, err := params.PathID(c, "userId")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	teams, err := h.service.GetUserTeams(c.Request.Context(), userID, organizationID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to retrieve user teams")
+		return
+	}
+
+	response.Success(c, teams)
+}
+
+// respondTeamError writes the status and code of typed errors, and 500 with message for
+// anything else
+func respondTeamError(c *gin.Context, err error, message string) {
+	if status := response.StatusFromError(err, http.StatusInternalServerError); status != http.StatusInternalServerError {
+		response.ErrorFrom(c, status, err)
+		return
+	}
+	response.Error(c, http.StatusInternalServerError, message)
+}
@@ -0,0 +1,121 @@
+package team
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/pkg/pagination"
+)
+
+// pagedTeams is a Service listing a fixed number of teams per organization; other methods are
+// left to the embedded nil interface and panic if called
+type pagedTeams struct {
+	Service
+	total int64
+}
+
+func (s *pagedTeams) GetTeamsByOrganization(ctx context.Context, organizationID uint, page, pageSize int) (*TeamListResponse, error) {
+	return &TeamListResponse{
+		Total:      s.total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: int((s.total + int64(pageSize) - 1) / int64(pageSize)),
+	}, nil
+}
+
+func TestInvalidPathIDsAreBadRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// The handler has no service, so a request that gets past ID validation panics and
+	// the recovery middleware answers 500
+	h := NewHandler(nil)
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.GET("/teams/:id", h.GetTeam)
+	router.PUT("/teams/:id", h.UpdateTeam)
+	router.DELETE("/teams/:id", h.DeleteTeam)
+	router.GET("/teams/:id/hierarchy", h.GetTeamHierarchy)
+	router.GET("/org-teams/:organization_id", h.GetTeamsByOrganization)
+	router.GET("/organizations/:id/teams/tree", h.GetTeamTree)
+	router.GET("/organizations/:id/users/:userId/teams", h.GetUserTeams)
+
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{method: http.MethodGet, path: "/teams/0"},
+		{method: http.MethodGet, path: "/teams/-1"},
+		{method: http.MethodPut, path: "/teams/abc"},
+		{method: http.MethodDelete, path: "/teams/0"},
+		{method: http.MethodGet, path: "/teams/-3/hierarchy"},
+		{method: http.MethodGet, path: "/org-teams/0"},
+		{method: http.MethodGet, path: "/organizations/-1/teams/tree"},
+		{method: http.MethodGet, path: "/organizations/1/users/0/teams"},
+		{method: http.MethodGet, path: "/organizations/0/users/1/teams"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusBadRequest, w.Body)
+			}
+		})
+	}
+}
+
+func TestListHeadersMatchBodyTotal(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		total    int64
+		query    string
+		wantNext bool
+	}{
+		{name: "first of several pages", total: 45, query: "?page=1&page_size=20", wantNext: true},
+		{name: "last page", total: 45, query: "?page=3&page_size=20", wantNext: false},
+		{name: "no teams", total: 0, query: "", wantNext: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/organizations/:organization_id/teams", NewHandler(&pagedTeams{total: tt.total}).GetTeamsByOrganization)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/organizations/7/teams"+tt.query, nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+			}
+
+			var body struct {
+				Data TeamListResponse `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if got, want := w.Header().Get(pagination.TotalCountHeader), strconv.FormatInt(body.Data.Total, 10); got != want {
+				t.Fatalf("%s = %q, body total = %s", pagination.TotalCountHeader, got, want)
+			}
+			if body.Data.Total != tt.total {
+				t.Fatalf("body total = %d, want %d", body.Data.Total, tt.total)
+			}
+
+			link := w.Header().Get(pagination.LinkHeader)
+			if !strings.Contains(link, `rel="first"`) || !strings.Contains(link, `rel="last"`) {
+				t.Fatalf("%s = %q, want first and last links", pagination.LinkHeader, link)
+			}
+			if got := strings.Contains(link, `rel="next"`); got != tt.wantNext {
+				t.Fatalf("%s = %q, has next = %v, want %v", pagination.LinkHeader, link, got, tt.wantNext)
+			}
+		})
+	}
+}
@@ -5,6 +5,8 @@ import (
 
 	"github.com/llamacto/llama-gin-kit/app/member"
 	"github.com/llamacto/llama-gin-kit/app/organization"
+	"github.com/llamacto/llama-gin-kit/pkg/invariant"
+	"github.com/llamacto/llama-gin-kit/pkg/settings"
 	"gorm.io/gorm"
 )
 
@@ -18,9 +20,9 @@ type Team struct {
 	DisplayName    string         `gorm:"size:100" json:"display_name"`
 	Description    string         `gorm:"size:500" json:"description"`
 	OrganizationID uint           `gorm:"not null" json:"organization_id"`
-	ParentTeamID   *uint          `json:"parent_team_id"` // For hierarchical team structure
-	// Settings       string         `gorm:"type:json;default:'{}'" json:"settings"` // Temporarily disabled
-	Status int `gorm:"default:1" json:"status"` // 1: active, 0: disabled
+	ParentTeamID   *uint          `json:"parent_team_id"`                                  // For hierarchical team structure
+	Settings       string         `gorm:"type:json;not null;default:'{}'" json:"settings"` // JSON object checked against SettingsSchema
+	Status         int            `gorm:"default:1" json:"status"`                         // 1: active, 0: disabled
 
 	// Relationships
 	Organization organization.Organization `gorm:"foreignKey:OrganizationID"`
@@ -28,11 +30,40 @@ type Team struct {
 	Members      []member.Member           `gorm:"foreignKey:TeamID"`
 }
 
+// SettingsSchema validates the team settings keys that have a known shape
+var SettingsSchema = settings.Schema{
+	"features": settings.Flags(), // Feature flags enabled or disabled for the team
+}
+
 // TableName specifies the database table name
 func (Team) TableName() string {
 	return "teams"
 }
 
+// GetSettings parses the team's settings document
+func (t *Team) GetSettings() (settings.Settings, error) {
+	return settings.Parse(t.Settings)
+}
+
+// SetSetting stores value under key in the team's settings, rejecting values that do not
+// match SettingsSchema. The change is saved with the team.
+func (t *Team) SetSetting(key string, value interface{}) error {
+	updated, err := SettingsSchema.SetValidated("settings", t.Settings, key, value)
+	if err != nil {
+		return err
+	}
+	t.Settings = updated
+	return nil
+}
+
+// BeforeSave rejects team rows without a name or organization
+func (t *Team) BeforeSave(tx *gorm.DB) error {
+	return invariant.Required(tx, "team",
+		invariant.Field{Column: "name", Value: t.Name},
+		invariant.Field{Column: "organization_id", Value: t.OrganizationID},
+	)
+}
+
 // TeamWithStats includes team data with member statistics
 type TeamWithStats struct {
 	Team        Team  `json:"team"`
@@ -1,20 +1,27 @@
 package team
 
 import (
+	"context"
+	"github.com/llamacto/llama-gin-kit/pkg/pagination"
+	"github.com/llamacto/llama-gin-kit/pkg/softdelete"
 	"gorm.io/gorm"
 )
 
 // Repository defines the interface for team data operations
 type Repository interface {
-	Create(team *Team) error
-	GetByID(id uint) (*Team, error)
-	GetByOrganizationID(organizationID uint, page, pageSize int) ([]Team, int64, error)
-	GetByParentTeamID(parentTeamID uint) ([]Team, error)
-	Update(id uint, updates map[string]interface{}) error
-	Delete(id uint) error
-	GetHierarchy(teamID uint) (*TeamHierarchy, error)
-	GetTeamStats(teamID uint) (*TeamWithStats, error)
-	CheckNameExists(name string, organizationID uint, excludeID *uint) (bool, error)
+	Create(ctx context.Context, team *Team) error
+	GetByID(ctx context.Context, id uint) (*Team, error)
+	GetByOrganizationID(ctx context.Context, organizationID uint, page, pageSize int) ([]Team, int64, error)
+	GetByParentTeamID(ctx context.Context, parentTeamID uint) ([]Team, error)
+	GetAllByOrganizationID(ctx context.Context, organizationID uint) ([]Team, error)
+	CountMembersByTeam(ctx context.Context, organizationID uint) (map[uint]int64, error)
+	GetUserTeams(ctx context.Context, userID, organizationID uint) ([]Team, error)
+	Update(ctx context.Context, id uint, updates map[string]interface{}) error
+	Delete(ctx context.Context, id uint) error
+	DeleteWithDependents(ctx context.Context, id uint, force bool) error
+	GetHierarchy(ctx context.Context, teamID uint) (*TeamHierarchy, error)
+	GetTeamStats(ctx context.Context, teamID uint) (*TeamWithStats, error)
+	CheckNameExists(ctx context.Context, name string, organizationID uint, excludeID *uint) (bool, error)
 }
 
 // repository implements the Repository interface
@@ -28,14 +35,14 @@ func NewRepository(db *gorm.DB) Repository {
 }
 
 // Create creates a new team
-func (r *repository) Create(team *Team) error {
-	return r.db.Create(team).Error
+func (r *repository) Create(ctx context.Context, team *Team) error {
+	return r.db.WithContext(ctx).Create(team).Error
 }
 
 // GetByID retrieves a team by its ID
-func (r *repository) GetByID(id uint) (*Team, error) {
+func (r *repository) GetByID(ctx context.Context, id uint) (*Team, error) {
 	var team Team
-	err := r.db.First(&team, id).Error
+	err := r.db.WithContext(ctx).First(&team, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -43,11 +50,11 @@ func (r *repository) GetByID(id uint) (*Team, error) {
 }
 
 // GetByOrganizationID retrieves teams by organization ID with pagination
-func (r *repository) GetByOrganizationID(organizationID uint, page, pageSize int) ([]Team, int64, error) {
+func (r *repository) GetByOrganizationID(ctx context.Context, organizationID uint, page, pageSize int) ([]Team, int64, error) {
 	var teams []Team
 	var total int64
 
-	query := r.db.Where("organization_id = ?", organizationID)
+	query := r.db.WithContext(ctx).Where("organization_id = ?", organizationID)
 
 	// Count total records
 	err := query.Model(&Team{}).Count(&total).Error
@@ -56,8 +63,7 @@ func (r *repository) GetByOrganizationID(organizationID uint, page, pageSize int
 	}
 
 	// Get paginated results
-	offset := (page - 1) * pageSize
-	err = query.Offset(offset).Limit(pageSize).Find(&teams).Error
+	err = query.Scopes(pagination.Paginate(page, pageSize)).Find(&teams).Error
 	if err != nil {
 		return nil, 0, err
 	}
@@ -66,26 +72,110 @@ func (r *repository) GetByOrganizationID(organizationID uint, page, pageSize int
 }
 
 // GetByParentTeamID retrieves teams by parent team ID
-func (r *repository) GetByParentTeamID(parentTeamID uint) ([]Team, error) {
+func (r *repository) GetByParentTeamID(ctx context.Context, parentTeamID uint) ([]Team, error) {
 	var teams []Team
-	err := r.db.Where("parent_team_id = ?", parentTeamID).Find(&teams).Error
+	err := r.db.WithContext(ctx).Where("parent_team_id = ?", parentTeamID).Find(&teams).Error
+	return teams, err
+}
+
+// GetAllByOrganizationID retrieves every team in an organization, ordered by name
+func (r *repository) GetAllByOrganizationID(ctx context.Context, organizationID uint) ([]Team, error) {
+	var teams []Team
+	err := r.db.WithContext(ctx).Where("organization_id = ?", organizationID).Order("name ASC").Find(&teams).Error
+	return teams, err
+}
+
+// CountMembersByTeam returns the number of members in each team of an organization
+func (r *repository) CountMembersByTeam(ctx context.Context, organizationID uint) (map[uint]int64, error) {
+	var rows []struct {
+		TeamID uint
+		Count  int64
+	}
+	err := r.db.WithContext(ctx).Table("organization_members").
+		Select("team_id, COUNT(*) AS count").
+		Where("organization_id = ? AND team_id IS NOT NULL AND deleted_at IS NULL", organizationID).
+		Group("team_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.TeamID] = row.Count
+	}
+	return counts, nil
+}
+
+// GetUserTeams retrieves the teams a user belongs to through active memberships in an organization
+func (r *repository) GetUserTeams(ctx context.Context, userID, organizationID uint) ([]Team, error) {
+	var teams []Team
+	err := r.db.WithContext(ctx).
+		Joins("JOIN organization_members om ON om.team_id = teams.id AND om.deleted_at IS NULL").
+		Where("om.user_id = ? AND om.organization_id = ? AND om.status = 1", userID, organizationID).
+		Where("teams.organization_id = ?", organizationID).
+		Distinct("teams.*").
+		Order("teams.name ASC").
+		Find(&teams).Error
 	return teams, err
 }
 
 // Update updates a team by ID
-func (r *repository) Update(id uint, updates map[string]interface{}) error {
-	return r.db.Model(&Team{}).Where("id = ?", id).Updates(updates).Error
+func (r *repository) Update(ctx context.Context, id uint, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&Team{}).Where("id = ?", id).Updates(updates).Error
 }
 
 // Delete soft deletes a team by ID
-func (r *repository) Delete(id uint) error {
-	return r.db.Delete(&Team{}, id).Error
+func (r *repository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&Team{}, id).Error
+}
+
+// DeleteWithDependents deletes a team inside a transaction. Without force it fails with a
+// *TeamNotEmptyError when the team still has members or child teams; with force, members move
+// to the organization level and child teams are reparented to the deleted team's parent.
+func (r *repository) DeleteWithDependents(ctx context.Context, id uint, force bool) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var team Team
+		if err := tx.First(&team, id).Error; err != nil {
+			return err
+		}
+
+		memberCount, err := softdelete.Count(tx, "organization_members", "team_id = ?", id)
+		if err != nil {
+			return err
+		}
+		var childCount int64
+		if err := tx.Model(&Team{}).Where("parent_team_id = ?", id).Count(&childCount).Error; err != nil {
+			return err
+		}
+
+		if !force && (memberCount > 0 || childCount > 0) {
+			return &TeamNotEmptyError{MemberCount: memberCount, ChildCount: childCount}
+		}
+
+		if memberCount > 0 {
+			if err := tx.Table("organization_members").
+				Where("team_id = ? AND deleted_at IS NULL", id).
+				Update("team_id", nil).Error; err != nil {
+				return err
+			}
+		}
+		if childCount > 0 {
+			if err := tx.Model(&Team{}).
+				Where("parent_team_id = ?", id).
+				Update("parent_team_id", team.ParentTeamID).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Delete(&Team{}, id).Error
+	})
 }
 
 // GetHierarchy retrieves team hierarchy (parent and children)
-func (r *repository) GetHierarchy(teamID uint) (*TeamHierarchy, error) {
+func (r *repository) GetHierarchy(ctx context.Context, teamID uint) (*TeamHierarchy, error) {
 	var team Team
-	err := r.db.First(&team, teamID).Error
+	err := r.db.WithContext(ctx).First(&team, teamID).Error
 	if err != nil {
 		return nil, err
 	}
@@ -97,14 +187,14 @@ func (r *repository) GetHierarchy(teamID uint) (*TeamHierarchy, error) {
 	// Get parent team if exists
 	if team.ParentTeamID != nil {
 		var parent Team
-		if err := r.db.First(&parent, *team.ParentTeamID).Error; err == nil {
+		if err := r.db.WithContext(ctx).First(&parent, *team.ParentTeamID).Error; err == nil {
 			hierarchy.Parent = &parent
 		}
 	}
 
 	// Get children teams
 	var children []Team
-	if err := r.db.Where("parent_team_id = ?", teamID).Find(&children).Error; err == nil {
+	if err := r.db.WithContext(ctx).Where("parent_team_id = ?", teamID).Find(&children).Error; err == nil {
 		hierarchy.Children = children
 	}
 
@@ -112,17 +202,14 @@ func (r *repository) GetHierarchy(teamID uint) (*TeamHierarchy, error) {
 }
 
 // GetTeamStats retrieves team with member count statistics
-func (r *repository) GetTeamStats(teamID uint) (*TeamWithStats, error) {
+func (r *repository) GetTeamStats(ctx context.Context, teamID uint) (*TeamWithStats, error) {
 	var team Team
-	err := r.db.First(&team, teamID).Error
+	err := r.db.WithContext(ctx).First(&team, teamID).Error
 	if err != nil {
 		return nil, err
 	}
 
-	var memberCount int64
-	err = r.db.Table("organization_members").
-		Where("team_id = ? AND deleted_at IS NULL", teamID).
-		Count(&memberCount).Error
+	memberCount, err := softdelete.Count(r.db.WithContext(ctx), "organization_members", "team_id = ?", teamID)
 	if err != nil {
 		return nil, err
 	}
@@ -134,8 +221,8 @@ func (r *repository) GetTeamStats(teamID uint) (*TeamWithStats, error) {
 }
 
 // CheckNameExists checks if a team name already exists in the organization
-func (r *repository) CheckNameExists(name string, organizationID uint, excludeID *uint) (bool, error) {
-	query := r.db.Where("name = ? AND organization_id = ?", name, organizationID)
+func (r *repository) CheckNameExists(ctx context.Context, name string, organizationID uint, excludeID *uint) (bool, error) {
+	query := r.db.WithContext(ctx).Where("name = ? AND organization_id = ?", name, organizationID)
 	if excludeID != nil {
 		query = query.Where("id != ?", *excludeID)
 	}
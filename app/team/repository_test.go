@@ -0,0 +1,85 @@
+package team
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/llamacto/llama-gin-kit/pkg/dbtest"
+)
+
+func TestDeleteTeamWithDependents(t *testing.T) {
+	// Team 2 sits under root team 1 and has its own child team 3
+	const (
+		rootID  = 1
+		teamID  = 2
+		members = 4
+	)
+
+	tests := []struct {
+		name         string
+		members      int64
+		children     int64
+		force        bool
+		missing      bool
+		wantErr      error
+		wantNotEmpty *TeamNotEmptyError
+		wantDeleted  bool
+	}{
+		{name: "empty team", wantDeleted: true},
+		{name: "team with members", members: members, wantNotEmpty: &TeamNotEmptyError{MemberCount: members}},
+		{name: "team with a child team", children: 1, wantNotEmpty: &TeamNotEmptyError{ChildCount: 1}},
+		{name: "forced with members and a child team", members: members, children: 1, force: true, wantDeleted: true},
+		{name: "missing team", missing: true, wantErr: ErrTeamNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, db := dbtest.Open(t)
+			if !tt.missing {
+				db.Returns(`FROM "teams" WHERE "teams"."id"`,
+					[]string{"id", "organization_id", "parent_team_id"},
+					[]driver.Value{int64(teamID), int64(9), int64(rootID)})
+			}
+			db.Returns(`FROM "organization_members"`, []string{"count"}, []driver.Value{tt.members})
+			db.Returns(`FROM "teams" WHERE parent_team_id`, []string{"count"}, []driver.Value{tt.children})
+
+			err := NewService(NewRepository(gormDB)).DeleteTeam(context.Background(), teamID, tt.force)
+
+			var notEmpty *TeamNotEmptyError
+			switch {
+			case tt.wantNotEmpty != nil:
+				if !errors.As(err, &notEmpty) || *notEmpty != *tt.wantNotEmpty {
+					t.Fatalf("DeleteTeam() error = %v, want %+v", err, tt.wantNotEmpty)
+				}
+			case !errors.Is(err, tt.wantErr):
+				t.Fatalf("DeleteTeam() error = %v, want %v", err, tt.wantErr)
+			}
+
+			_, deleted := db.Find(`UPDATE "teams" SET "deleted_at"`)
+			if deleted != tt.wantDeleted {
+				t.Fatalf("team soft-deleted = %v, want %v", deleted, tt.wantDeleted)
+			}
+			if _, committed := db.Find("COMMIT"); committed != tt.wantDeleted {
+				t.Fatalf("committed = %v, want %v", committed, tt.wantDeleted)
+			}
+
+			moved, ok := db.Find(`UPDATE "organization_members" SET "team_id"=$1`)
+			if ok != (tt.force && tt.members > 0) {
+				t.Fatalf("members moved to the organization level = %v, want %v", ok, tt.force && tt.members > 0)
+			}
+			if ok && moved.Args[0] != nil {
+				t.Fatalf("members moved to team %v, want NULL", moved.Args[0])
+			}
+
+			reparented, ok := db.Find(`UPDATE "teams" SET "parent_team_id"=$1`)
+			if ok != (tt.force && tt.children > 0) {
+				t.Fatalf("child teams reparented = %v, want %v", ok, tt.force && tt.children > 0)
+			}
+			if ok && reparented.Args[0] != int64(rootID) {
+				t.Fatalf("child teams moved under %v, want the deleted team's parent %d", reparented.Args[0], rootID)
+			}
+		})
+	}
+}
@@ -1,19 +1,57 @@
 package team
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
+
+	apperrors "github.com/llamacto/llama-gin-kit/pkg/errors"
+	"github.com/llamacto/llama-gin-kit/pkg/pagination"
+	"github.com/llamacto/llama-gin-kit/pkg/validator"
+	"gorm.io/gorm"
+)
+
+// maxTeamTreeDepth caps how deep GetTeamTree nests teams, guarding against cycles in stored data
+const maxTeamTreeDepth = 32
+
+var (
+	// ErrTeamNotFound is returned when the team does not exist
+	ErrTeamNotFound = apperrors.NotFound("team_not_found", "team not found")
+	// ErrTeamNameExists is returned when another team in the organization has the name
+	ErrTeamNameExists = apperrors.AlreadyExists("team_name_exists", "team name '%s' already exists in this organization")
+	// ErrInvalidParentTeam is returned when the requested parent team cannot parent the team;
+	// its argument says why
+	ErrInvalidParentTeam = apperrors.Validation("invalid_parent_team", "invalid parent team: %s")
+
+	errTeamNotEmpty = apperrors.Conflict("team_not_empty", "team is not empty")
 )
 
+// TeamNotEmptyError is returned when deleting a team that still has members or child teams
+// without forcing the deletion
+type TeamNotEmptyError struct {
+	MemberCount int64
+	ChildCount  int64
+}
+
+func (e *TeamNotEmptyError) Error() string {
+	return fmt.Sprintf("team has %d members and %d child teams; retry with force=true to reassign them", e.MemberCount, e.ChildCount)
+}
+
+// Unwrap classifies the error as a conflict with its code
+func (e *TeamNotEmptyError) Unwrap() error { return errTeamNotEmpty }
+
 // Service defines the interface for team business logic
 type Service interface {
-	CreateTeam(req *CreateTeamRequest, createdBy uint) (*TeamResponse, error)
-	GetTeamByID(id uint) (*TeamResponse, error)
-	GetTeamsByOrganization(organizationID uint, page, pageSize int) (*TeamListResponse, error)
-	UpdateTeam(id uint, req *UpdateTeamRequest) (*TeamResponse, error)
-	DeleteTeam(id uint) error
-	GetTeamHierarchy(teamID uint) (*TeamHierarchyResponse, error)
-	GetTeamStats(teamID uint) (*TeamWithStats, error)
+	CreateTeam(ctx context.Context, req *CreateTeamRequest, createdBy uint) (*TeamResponse, error)
+	GetTeamByID(ctx context.Context, id uint) (*TeamResponse, error)
+	GetTeamsByOrganization(ctx context.Context, organizationID uint, page, pageSize int) (*TeamListResponse, error)
+	UpdateTeam(ctx context.Context, id uint, req *UpdateTeamRequest) (*TeamResponse, error)
+	DeleteTeam(ctx context.Context, id uint, force bool) error
+	GetTeamHierarchy(ctx context.Context, teamID uint) (*TeamHierarchyResponse, error)
+	GetTeamTree(ctx context.Context, organizationID uint) ([]*TeamTreeNode, error)
+	GetUserTeams(ctx context.Context, userID, organizationID uint) ([]TeamResponse, error)
+	GetTeamStats(ctx context.Context, teamID uint) (*TeamWithStats, error)
 }
 
 // service implements the Service interface
@@ -27,14 +65,28 @@ func NewService(repo Repository) Service {
 }
 
 // CreateTeam creates a new team
-func (s *service) CreateTeam(req *CreateTeamRequest, createdBy uint) (*TeamResponse, error) {
+func (s *service) CreateTeam(ctx context.Context, req *CreateTeamRequest, createdBy uint) (*TeamResponse, error) {
+	if err := validator.CheckReservedName(req.Name); err != nil {
+		return nil, err
+	}
+	teamSettings, err := SettingsSchema.Normalize("settings", req.Settings)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if team name already exists in the organization
-	exists, err := s.repo.CheckNameExists(req.Name, req.OrganizationID, nil)
+	exists, err := s.repo.CheckNameExists(ctx, req.Name, req.OrganizationID, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check team name existence: %w", err)
 	}
 	if exists {
-		return nil, fmt.Errorf("team name '%s' already exists in this organization", req.Name)
+		return nil, ErrTeamNameExists.WithArgs(req.Name)
+	}
+
+	if req.ParentTeamID != nil {
+		if err := s.validateParent(ctx, nil, req.OrganizationID, *req.ParentTeamID); err != nil {
+			return nil, err
+		}
 	}
 
 	// Create team model
@@ -43,15 +95,15 @@ func (s *service) CreateTeam(req *CreateTeamRequest, createdBy uint) (*TeamRespo
 		DisplayName:    req.DisplayName,
 		Description:    req.Description,
 		OrganizationID: req.OrganizationID,
-		ParentTeamID:   req.ParentTeamID,
-		// Settings:       req.Settings, // Temporarily disabled
-		Status:    1, // Active by default
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ParentTeamID:   copyUintPtr(req.ParentTeamID),
+		Settings:       teamSettings,
+		Status:         1, // Active by default
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
 	}
 
 	// Save to database
-	err = s.repo.Create(team)
+	err = s.repo.Create(ctx, team)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create team: %w", err)
 	}
@@ -60,14 +112,17 @@ func (s *service) CreateTeam(req *CreateTeamRequest, createdBy uint) (*TeamRespo
 }
 
 // GetTeamByID retrieves a team by its ID
-func (s *service) GetTeamByID(id uint) (*TeamResponse, error) {
-	team, err := s.repo.GetByID(id)
+func (s *service) GetTeamByID(ctx context.Context, id uint) (*TeamResponse, error) {
+	team, err := s.repo.GetByID(ctx, id)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTeamNotFound
+		}
 		return nil, fmt.Errorf("failed to get team: %w", err)
 	}
 
 	// Get team stats
-	stats, err := s.repo.GetTeamStats(id)
+	stats, err := s.repo.GetTeamStats(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get team stats: %w", err)
 	}
@@ -76,15 +131,10 @@ func (s *service) GetTeamByID(id uint) (*TeamResponse, error) {
 }
 
 // GetTeamsByOrganization retrieves teams by organization ID with pagination
-func (s *service) GetTeamsByOrganization(organizationID uint, page, pageSize int) (*TeamListResponse, error) {
-	if page <= 0 {
-		page = 1
-	}
-	if pageSize <= 0 || pageSize > 100 {
-		pageSize = 20
-	}
+func (s *service) GetTeamsByOrganization(ctx context.Context, organizationID uint, page, pageSize int) (*TeamListResponse, error) {
+	page, pageSize = pagination.Normalize(page, pageSize, pagination.DefaultPageSize)
 
-	teams, total, err := s.repo.GetByOrganizationID(organizationID, page, pageSize)
+	teams, total, err := s.repo.GetByOrganizationID(ctx, organizationID, page, pageSize)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get teams: %w", err)
 	}
@@ -93,7 +143,7 @@ func (s *service) GetTeamsByOrganization(organizationID uint, page, pageSize int
 	var teamResponses []TeamResponse
 	for _, team := range teams {
 		// Get member count for each team
-		stats, err := s.repo.GetTeamStats(team.ID)
+		stats, err := s.repo.GetTeamStats(ctx, team.ID)
 		memberCount := int64(0)
 		if err == nil && stats != nil {
 			memberCount = stats.MemberCount
@@ -102,36 +152,41 @@ func (s *service) GetTeamsByOrganization(organizationID uint, page, pageSize int
 		teamResponses = append(teamResponses, *s.convertToTeamResponse(&team, memberCount))
 	}
 
-	totalPages := int((total + int64(pageSize) - 1) / int64(pageSize))
-
 	return &TeamListResponse{
 		Teams:      teamResponses,
 		Total:      total,
 		Page:       page,
 		PageSize:   pageSize,
-		TotalPages: totalPages,
+		TotalPages: pagination.TotalPages(total, pageSize),
 	}, nil
 }
 
 // UpdateTeam updates a team
-func (s *service) UpdateTeam(id uint, req *UpdateTeamRequest) (*TeamResponse, error) {
+func (s *service) UpdateTeam(ctx context.Context, id uint, req *UpdateTeamRequest) (*TeamResponse, error) {
 	// Check if team exists
-	team, err := s.repo.GetByID(id)
+	team, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("team not found: %w", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTeamNotFound
+		}
+		return nil, fmt.Errorf("failed to get team: %w", err)
 	}
 
 	// Prepare updates
 	updates := make(map[string]interface{})
 
 	if req.Name != "" {
+		if err := validator.CheckReservedName(req.Name); err != nil {
+			return nil, err
+		}
+
 		// Check if new name already exists (excluding current team)
-		exists, err := s.repo.CheckNameExists(req.Name, team.OrganizationID, &id)
+		exists, err := s.repo.CheckNameExists(ctx, req.Name, team.OrganizationID, &id)
 		if err != nil {
 			return nil, fmt.Errorf("failed to check team name existence: %w", err)
 		}
 		if exists {
-			return nil, fmt.Errorf("team name '%s' already exists in this organization", req.Name)
+			return nil, ErrTeamNameExists.WithArgs(req.Name)
 		}
 		updates["name"] = req.Name
 	}
@@ -143,11 +198,19 @@ func (s *service) UpdateTeam(id uint, req *UpdateTeamRequest) (*TeamResponse, er
 		updates["description"] = req.Description
 	}
 	if req.ParentTeamID != nil {
-		updates["parent_team_id"] = req.ParentTeamID
+		parentTeamID := *req.ParentTeamID
+		if err := s.validateParent(ctx, &id, team.OrganizationID, parentTeamID); err != nil {
+			return nil, err
+		}
+		updates["parent_team_id"] = parentTeamID
+	}
+	if req.Settings != "" {
+		teamSettings, err := SettingsSchema.Normalize("settings", req.Settings)
+		if err != nil {
+			return nil, err
+		}
+		updates["settings"] = teamSettings
 	}
-	// if req.Settings != "" {
-	//	updates["settings"] = req.Settings
-	// } // Temporarily disabled
 	if req.Status != nil {
 		updates["status"] = *req.Status
 	}
@@ -155,35 +218,27 @@ func (s *service) UpdateTeam(id uint, req *UpdateTeamRequest) (*TeamResponse, er
 	updates["updated_at"] = time.Now()
 
 	// Update team
-	err = s.repo.Update(id, updates)
+	err = s.repo.Update(ctx, id, updates)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update team: %w", err)
 	}
 
 	// Return updated team
-	return s.GetTeamByID(id)
+	return s.GetTeamByID(ctx, id)
 }
 
-// DeleteTeam deletes a team
-func (s *service) DeleteTeam(id uint) error {
-	// Check if team exists
-	_, err := s.repo.GetByID(id)
-	if err != nil {
-		return fmt.Errorf("team not found: %w", err)
-	}
-
-	// Check if team has children (prevent deletion if has children)
-	children, err := s.repo.GetByParentTeamID(id)
-	if err != nil {
-		return fmt.Errorf("failed to check team children: %w", err)
-	}
-	if len(children) > 0 {
-		return fmt.Errorf("cannot delete team with child teams")
-	}
-
-	// Delete team
-	err = s.repo.Delete(id)
+// DeleteTeam deletes a team. Teams with members or child teams are only deleted when force
+// is set, in which case members move to the organization level and children to the team's parent.
+func (s *service) DeleteTeam(ctx context.Context, id uint, force bool) error {
+	err := s.repo.DeleteWithDependents(ctx, id, force)
 	if err != nil {
+		var notEmpty *TeamNotEmptyError
+		if errors.As(err, &notEmpty) {
+			return err
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTeamNotFound
+		}
 		return fmt.Errorf("failed to delete team: %w", err)
 	}
 
@@ -191,8 +246,8 @@ func (s *service) DeleteTeam(id uint) error {
 }
 
 // GetTeamHierarchy retrieves team hierarchy
-func (s *service) GetTeamHierarchy(teamID uint) (*TeamHierarchyResponse, error) {
-	hierarchy, err := s.repo.GetHierarchy(teamID)
+func (s *service) GetTeamHierarchy(ctx context.Context, teamID uint) (*TeamHierarchyResponse, error) {
+	hierarchy, err := s.repo.GetHierarchy(ctx, teamID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get team hierarchy: %w", err)
 	}
@@ -215,9 +270,117 @@ func (s *service) GetTeamHierarchy(teamID uint) (*TeamHierarchyResponse, error)
 	return response, nil
 }
 
+// GetTeamTree returns every team in an organization as a nested tree. Teams without a parent,
+// or whose parent is missing, are returned as roots.
+func (s *service) GetTeamTree(ctx context.Context, organizationID uint) ([]*TeamTreeNode, error) {
+	teams, err := s.repo.GetAllByOrganizationID(ctx, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get teams: %w", err)
+	}
+
+	memberCounts, err := s.repo.CountMembersByTeam(ctx, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count team members: %w", err)
+	}
+
+	nodes := make(map[uint]*TeamTreeNode, len(teams))
+	for i := range teams {
+		nodes[teams[i].ID] = &TeamTreeNode{
+			TeamResponse: *s.convertToTeamResponse(&teams[i], memberCounts[teams[i].ID]),
+			Children:     []*TeamTreeNode{},
+		}
+	}
+
+	children := make(map[uint][]*TeamTreeNode)
+	roots := []*TeamTreeNode{}
+	for _, team := range teams {
+		node := nodes[team.ID]
+		if team.ParentTeamID != nil {
+			if _, ok := nodes[*team.ParentTeamID]; ok {
+				children[*team.ParentTeamID] = append(children[*team.ParentTeamID], node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+
+	// Attach children from the roots down so teams caught in a cycle are never reached
+	var attach func(node *TeamTreeNode, depth int)
+	attach = func(node *TeamTreeNode, depth int) {
+		if depth >= maxTeamTreeDepth {
+			return
+		}
+		for _, child := range children[node.ID] {
+			node.Children = append(node.Children, child)
+			attach(child, depth+1)
+		}
+	}
+	for _, root := range roots {
+		attach(root, 1)
+	}
+
+	return roots, nil
+}
+
+// GetUserTeams retrieves the teams a user actively belongs to within an organization
+func (s *service) GetUserTeams(ctx context.Context, userID, organizationID uint) ([]TeamResponse, error) {
+	teams, err := s.repo.GetUserTeams(ctx, userID, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user teams: %w", err)
+	}
+
+	responses := make([]TeamResponse, 0, len(teams))
+	for i := range teams {
+		responses = append(responses, *s.convertToTeamResponse(&teams[i], 0))
+	}
+	return responses, nil
+}
+
 // GetTeamStats retrieves team statistics
-func (s *service) GetTeamStats(teamID uint) (*TeamWithStats, error) {
-	return s.repo.GetTeamStats(teamID)
+func (s *service) GetTeamStats(ctx context.Context, teamID uint) (*TeamWithStats, error) {
+	return s.repo.GetTeamStats(ctx, teamID)
+}
+
+// validateParent checks that parentID can parent a team in organizationID. For an existing
+// team (teamID set) it walks the parent's ancestor chain and rejects any loop back to the team.
+func (s *service) validateParent(ctx context.Context, teamID *uint, organizationID, parentID uint) error {
+	if teamID != nil && *teamID == parentID {
+		return ErrInvalidParentTeam.WithArgs("a team cannot be its own parent")
+	}
+
+	parent, err := s.repo.GetByID(ctx, parentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidParentTeam.WithArgs(fmt.Sprintf("team %d not found", parentID))
+		}
+		return fmt.Errorf("failed to get parent team: %w", err)
+	}
+	if parent.OrganizationID != organizationID {
+		return ErrInvalidParentTeam.WithArgs(fmt.Sprintf("'%s' belongs to a different organization", parent.Name))
+	}
+
+	if teamID == nil {
+		return nil
+	}
+
+	visited := map[uint]bool{parent.ID: true}
+	for ancestor := parent; ancestor.ParentTeamID != nil; {
+		if *ancestor.ParentTeamID == *teamID {
+			return ErrInvalidParentTeam.WithArgs(fmt.Sprintf("'%s' is a descendant of this team", parent.Name))
+		}
+		if visited[*ancestor.ParentTeamID] {
+			return ErrInvalidParentTeam.WithArgs(fmt.Sprintf("the hierarchy above '%s' already contains a cycle", parent.Name))
+		}
+		visited[*ancestor.ParentTeamID] = true
+
+		next, err := s.repo.GetByID(ctx, *ancestor.ParentTeamID)
+		if err != nil {
+			return fmt.Errorf("failed to get ancestor team: %w", err)
+		}
+		ancestor = next
+	}
+
+	return nil
 }
 
 // convertToTeamResponse converts Team model to TeamResponse
@@ -228,11 +391,21 @@ func (s *service) convertToTeamResponse(team *Team, memberCount int64) *TeamResp
 		DisplayName:    team.DisplayName,
 		Description:    team.Description,
 		OrganizationID: team.OrganizationID,
-		ParentTeamID:   team.ParentTeamID,
-		// Settings:       team.Settings, // Temporarily disabled
-		Status:      team.Status,
-		MemberCount: memberCount,
-		CreatedAt:   team.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:   team.UpdatedAt.Format(time.RFC3339),
+		ParentTeamID:   copyUintPtr(team.ParentTeamID),
+		Settings:       team.Settings,
+		Status:         team.Status,
+		MemberCount:    memberCount,
+		CreatedAt:      team.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      team.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// copyUintPtr returns a copy of an optional ID so responses and updates
+// never share a pointer with the request or model it came from
+func copyUintPtr(p *uint) *uint {
+	if p == nil {
+		return nil
 	}
+	v := *p
+	return &v
 }
@@ -0,0 +1,149 @@
+package team
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/pkg/validator"
+	"gorm.io/gorm"
+)
+
+func TestConvertToTeamResponse(t *testing.T) {
+	parentID := uint(3)
+	created := time.Date(2025, 7, 1, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		parentID   *uint
+		wantParent *uint
+	}{
+		{name: "root team"},
+		{name: "child team", parentID: &parentID, wantParent: &parentID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			team := &Team{
+				ID:             5,
+				CreatedAt:      created,
+				UpdatedAt:      created,
+				Name:           "backend",
+				OrganizationID: 2,
+				ParentTeamID:   tt.parentID,
+				Status:         1,
+			}
+
+			resp := (&service{}).convertToTeamResponse(team, 4)
+
+			if resp.ID != 5 || resp.OrganizationID != 2 || resp.MemberCount != 4 || resp.CreatedAt != "2025-07-01T09:30:00Z" {
+				t.Fatalf("convertToTeamResponse() = %+v", resp)
+			}
+			switch {
+			case tt.wantParent == nil && resp.ParentTeamID != nil:
+				t.Fatalf("ParentTeamID = %d, want nil", *resp.ParentTeamID)
+			case tt.wantParent != nil && (resp.ParentTeamID == nil || *resp.ParentTeamID != *tt.wantParent):
+				t.Fatalf("ParentTeamID = %v, want %d", resp.ParentTeamID, *tt.wantParent)
+			case resp.ParentTeamID != nil && resp.ParentTeamID == team.ParentTeamID:
+				t.Fatal("ParentTeamID aliases the model")
+			}
+		})
+	}
+}
+
+// teamRepository is an in-memory Repository covering team updates; other methods are left
+// to the embedded nil interface and panic if called
+type teamRepository struct {
+	Repository
+	teams   map[uint]*Team
+	updates map[string]interface{}
+}
+
+func (r *teamRepository) GetByID(ctx context.Context, id uint) (*Team, error) {
+	team, ok := r.teams[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return team, nil
+}
+
+func (r *teamRepository) Update(ctx context.Context, id uint, updates map[string]interface{}) error {
+	r.updates = updates
+	if parentID, ok := updates["parent_team_id"].(uint); ok {
+		r.teams[id].ParentTeamID = &parentID
+	}
+	return nil
+}
+
+func (r *teamRepository) GetTeamStats(ctx context.Context, teamID uint) (*TeamWithStats, error) {
+	return &TeamWithStats{}, nil
+}
+
+func TestUpdateTeamParent(t *testing.T) {
+	rootID := uint(1)
+	otherID := uint(2)
+	selfID := uint(3)
+
+	tests := []struct {
+		name       string
+		parentID   *uint
+		wantParent *uint
+		wantErr    error
+	}{
+		{name: "parent omitted keeps the current parent", wantParent: &rootID},
+		{name: "new parent", parentID: &otherID, wantParent: &otherID},
+		{name: "own parent", parentID: &selfID, wantErr: ErrInvalidParentTeam},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := rootID
+			repo := &teamRepository{teams: map[uint]*Team{
+				rootID:  {ID: rootID, OrganizationID: 1},
+				otherID: {ID: otherID, OrganizationID: 1},
+				selfID:  {ID: selfID, OrganizationID: 1, ParentTeamID: &parent},
+			}}
+			req := &UpdateTeamRequest{ParentTeamID: tt.parentID}
+
+			resp, err := NewService(repo).UpdateTeam(context.Background(), selfID, req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateTeam() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			stored, set := repo.updates["parent_team_id"]
+			if set != (tt.parentID != nil) {
+				t.Fatalf("parent_team_id in updates = %v, want %v", set, tt.parentID != nil)
+			}
+			if set {
+				if _, isValue := stored.(uint); !isValue {
+					t.Fatalf("parent_team_id stored as %T, want the uint value", stored)
+				}
+			}
+			if resp.ParentTeamID == nil || *resp.ParentTeamID != *tt.wantParent {
+				t.Fatalf("ParentTeamID = %v, want %d", resp.ParentTeamID, *tt.wantParent)
+			}
+			if tt.parentID != nil && resp.ParentTeamID == tt.parentID {
+				t.Fatal("response ParentTeamID aliases the request")
+			}
+		})
+	}
+}
+
+func TestReservedTeamNames(t *testing.T) {
+	repo := &teamRepository{teams: map[uint]*Team{1: {ID: 1, OrganizationID: 1, Name: "platform"}}}
+	s := NewService(repo)
+
+	if _, err := s.CreateTeam(context.Background(), &CreateTeamRequest{Name: "Settings", OrganizationID: 1}, 1); !errors.Is(err, validator.ErrReservedName) {
+		t.Fatalf("CreateTeam() error = %v, want %v", err, validator.ErrReservedName)
+	}
+	if _, err := s.UpdateTeam(context.Background(), 1, &UpdateTeamRequest{Name: "Ad_Min"}); !errors.Is(err, validator.ErrReservedName) {
+		t.Fatalf("UpdateTeam() error = %v, want %v", err, validator.ErrReservedName)
+	}
+	if repo.updates != nil {
+		t.Fatalf("reserved rename was stored: %v", repo.updates)
+	}
+}
@@ -0,0 +1,151 @@
+package tts
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// MaxTextLength is the longest input, in characters, accepted for generation or translation.
+// It matches the provider's limit so over-long requests are rejected before any paid call.
+const MaxTextLength = 4096
+
+// Voices lists the voices accepted by GenerateRequest
+var Voices = []string{"alloy", "echo", "fable", "onyx", "nova", "shimmer"}
+
+// DefaultVoice is used when a generate request does not name one
+const DefaultVoice = "alloy"
+
+// Languages maps the supported language codes to the names sent to the provider
+var Languages = map[string]string{
+	"en": "English",
+	"zh": "Chinese",
+	"ja": "Japanese",
+	"ko": "Korean",
+	"es": "Spanish",
+	"fr": "French",
+	"de": "German",
+}
+
+// GenerateRequest represents the request to synthesize speech
+type GenerateRequest struct {
+	Text     string `json:"text" binding:"required,max=4096"`
+	Voice    string `json:"voice" binding:"omitempty,oneof=alloy echo fable onyx nova shimmer"`
+	Language string `json:"language" binding:"omitempty,oneof=en zh ja ko es fr de"` // Language of the text, informational for now
+}
+
+// TranslateRequest represents the request to translate text into another language
+type TranslateRequest struct {
+	Text           string `json:"text" binding:"required,max=4096"`
+	TargetLanguage string `json:"target_language" binding:"required,oneof=en zh ja ko es fr de"`
+}
+
+// TranslateResponse carries the translated text
+type TranslateResponse struct {
+	Text           string `json:"text"`
+	TargetLanguage string `json:"target_language"`
+}
+
+// FieldError describes why a single request field failed validation
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors converts binding errors into per-field messages.
+// It returns nil when err is not a validation error, e.g. malformed JSON.
+func ValidationErrors(err error) []FieldError {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return nil
+	}
+
+	fields := make([]FieldError, 0, len(errs))
+	for _, fe := range errs {
+		field := fieldName(fe.Field())
+		var message string
+		switch fe.Tag() {
+		case "required":
+			message = field + " is required"
+		case "max":
+			message = fmt.Sprintf("%s must be at most %s characters", field, fe.Param())
+		case "oneof":
+			message = fmt.Sprintf("%s must be one of: %s", field, strings.ReplaceAll(fe.Param(), " ", ", "))
+		default:
+			message = field + " is invalid"
+		}
+		fields = append(fields, FieldError{Field: field, Message: message})
+	}
+	return fields
+}
+
+// fieldName maps a struct field name to its JSON name
+func fieldName(name string) string {
+	switch name {
+	case "TargetLanguage":
+		return "target_language"
+	default:
+		return strings.ToLower(name)
+	}
+}
+
+// JobResponse represents a generation job in API responses
+type JobResponse struct {
+	ID          uint       `json:"id"`
+	Status      string     `json:"status"` // queued, processing, completed or failed
+	Progress    int        `json:"progress"`
+	Voice       string     `json:"voice"`
+	Language    string     `json:"language,omitempty"`
+	AudioURL    string     `json:"audio_url,omitempty"` // Signed download link once completed, see AudioLinkResponse
+	Error       string     `json:"error,omitempty"`
+	Attempts    int        `json:"attempts"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// AudioLinkResponse is a signed link to a completed job's audio
+type AudioLinkResponse struct {
+	URL       string    `json:"url"`        // Path of the download, with its expiry and signature
+	ExpiresAt time.Time `json:"expires_at"` // The link is refused after this time
+}
+
+// Finished reports whether the job has completed or failed
+func (r JobResponse) Finished() bool {
+	return r.Status == statusText(StatusCompleted) || r.Status == statusText(StatusFailed)
+}
+
+// statusText returns the API name of a job status
+func statusText(status int) string {
+	switch status {
+	case StatusQueued:
+		return "queued"
+	case StatusProcessing:
+		return "processing"
+	case StatusCompleted:
+		return "completed"
+	case StatusFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// toJobResponse converts a Job to a JobResponse
+func toJobResponse(job *Job) JobResponse {
+	return JobResponse{
+		ID:          job.ID,
+		Status:      statusText(job.Status),
+		Progress:    job.Progress,
+		Voice:       job.Voice,
+		Language:    job.Language,
+		Error:       job.Error,
+		Attempts:    job.Attempts,
+		CreatedAt:   job.CreatedAt,
+		UpdatedAt:   job.UpdatedAt,
+		CompletedAt: job.CompletedAt,
+	}
+}
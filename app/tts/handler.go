@@ -0,0 +1,353 @@
+package tts
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/pkg/authctx"
+	"github.com/llamacto/llama-gin-kit/pkg/i18n"
+	"github.com/llamacto/llama-gin-kit/pkg/logger"
+	"github.com/llamacto/llama-gin-kit/pkg/params"
+	"github.com/llamacto/llama-gin-kit/pkg/response"
+)
+
+// streamPollInterval is how often a progress stream rechecks its job, and sends a heartbeat
+// when nothing changed
+const streamPollInterval = 15 * time.Second
+
+// Handler defines the interface for TTS HTTP handlers
+type Handler interface {
+	Generate(c *gin.Context)
+	GetJob(c *gin.Context)
+	RetryJob(c *gin.Context)
+	AudioLink(c *gin.Context)
+	DownloadAudio(c *gin.Context)
+	StreamJob(c *gin.Context)
+	Translate(c *gin.Context)
+}
+
+// handler implements the Handler interface
+type handler struct {
+	service Service
+}
+
+// NewHandler creates a new TTS handler instance
+func NewHandler(service Service) Handler {
+	return &handler{service: service}
+}
+
+// Generate queues a speech generation job
+// @Summary Generate speech
+// @Description Queue synthesis of speech from text and return the job. Text is limited to 4096 characters. Poll GET /v1/tts/jobs/{id} or follow GET /v1/tts/jobs/{id}/stream, then fetch a download link from GET /v1/tts/jobs/{id}/download once completed. When the queue is full the job is recorded as failed and 503 returned
+// @Tags tts
+// @Accept json
+// @Produce json
+// @Param request body GenerateRequest true "Text, voice and language"
+// @Success 202 {object} response.Response{data=JobResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 422 {object} response.Response{data=[]FieldError}
+// @Failure 429 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 503 {object} response.Response
+// @Router /v1/tts/generate [post]
+func (h *handler) Generate(c *gin.Context) {
+	var req GenerateRequest
+	if !bindRequest(c, &req) {
+		return
+	}
+
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.ErrorKey(c, http.StatusUnauthorized, i18n.KeyUnauthorized)
+		return
+	}
+
+	job, err := h.service.Generate(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondTTSError(c, err, "Failed to queue speech generation")
+		return
+	}
+
+	response.Accepted(c, job)
+}
+
+// GetJob reports a generation job's status
+// @Summary Get generation job
+// @Description Get a generation job's status and progress, with a signed audio link once completed or the error once failed
+// @Tags tts
+// @Produce json
+// @Param id path int true "Job ID"
+// @Success 200 {object} response.Response{data=JobResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /v1/tts/jobs/{id} [get]
+func (h *handler) GetJob(c *gin.Context) {
+	id, userID, ok := jobParams(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.service.GetJob(c.Request.Context(), id, userID)
+	if err != nil {
+		respondTTSError(c, err, "Failed to get generation job")
+		return
+	}
+
+	response.Success(c, job)
+}
+
+// RetryJob queues a failed generation job again
+// @Summary Retry generation job
+// @Description Queue a failed generation job again. Only failed jobs can be retried
+// @Tags tts
+// @Produce json
+// @Param id path int true "Job ID"
+// @Success 202 {object} response.Response{data=JobResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 503 {object} response.Response
+// @Router /v1/tts/jobs/{id}/retry [post]
+func (h *handler) RetryJob(c *gin.Context) {
+	id, userID, ok := jobParams(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.service.RetryJob(c.Request.Context(), id, userID)
+	if err != nil {
+		respondTTSError(c, err, "Failed to retry generation job")
+		return
+	}
+
+	response.Accepted(c, job)
+}
+
+// AudioLink returns a signed download link for a completed job's audio
+// @Summary Get audio download link
+// @Description Get a signed link to the MP3 of one of your completed jobs. The link works without authentication until expires_at
+// @Tags tts
+// @Produce json
+// @Param id path int true "Job ID"
+// @Success 200 {object} response.Response{data=AudioLinkResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 503 {object} response.Response
+// @Router /v1/tts/jobs/{id}/download [get]
+func (h *handler) AudioLink(c *gin.Context) {
+	id, userID, ok := jobParams(c)
+	if !ok {
+		return
+	}
+
+	link, err := h.service.AudioLink(c.Request.Context(), id, userID)
+	if err != nil {
+		respondTTSError(c, err, "Failed to create download link")
+		return
+	}
+
+	response.Success(c, link)
+}
+
+// DownloadAudio serves a job's MP3 to a request carrying a valid signed link. It must be
+// mounted behind the SignedURL middleware, which stands in for authentication.
+// @Summary Download audio
+// @Description Download a completed job's MP3 through a link from GET /v1/tts/jobs/{id}/download
+// @Tags tts
+// @Produce audio/mpeg
+// @Param id path int true "Job ID"
+// @Param expires query int true "Link expiry as a Unix time"
+// @Param signature query string true "Link signature"
+// @Success 200 {file} file
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} response.Response
+// @Router /v1/tts/jobs/{id}/audio [get]
+func (h *handler) DownloadAudio(c *gin.Context) {
+	id, err := params.PathID(c, "id")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	audio, size, err := h.service.OpenAudio(c.Request.Context(), id)
+	if err != nil {
+		respondTTSError(c, err, "Failed to download audio")
+		return
+	}
+	defer audio.Close()
+
+	c.DataFromReader(http.StatusOK, size, "audio/mpeg", audio, map[string]string{
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="tts-%d.mp3"`, id),
+		"Cache-Control":       "private, no-store",
+	})
+}
+
+// StreamJob streams a generation job's progress as Server-Sent Events
+// @Summary Stream generation progress
+// @Description Stream a generation job's state as Server-Sent Events. The first event is the current state; later events are named queued, processing, progress, completed or failed and carry the job, with a signed audio link once completed. The stream closes after completed or failed
+// @Tags tts
+// @Produce text/event-stream
+// @Param id path int true "Job ID"
+// @Success 200 {object} JobEvent
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /v1/tts/jobs/{id}/stream [get]
+func (h *handler) StreamJob(c *gin.Context) {
+	id, userID, ok := jobParams(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	job, events, stop, err := h.service.WatchJob(ctx, id, userID)
+	if err != nil {
+		respondTTSError(c, err, "Failed to get generation job")
+		return
+	}
+	defer stop()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Stop nginx from buffering the stream
+	c.Status(http.StatusOK)
+
+	send := func(job JobResponse) bool {
+		c.SSEvent(eventForJob(job), job)
+		c.Writer.Flush()
+		return !job.Finished()
+	}
+	if !send(*job) {
+		return
+	}
+
+	// Jobs run on the instance that created them, so a stream served elsewhere only
+	// sees their progress by polling
+	ticker := time.NewTicker(streamPollInterval)
+	defer ticker.Stop()
+
+	last := *job
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-events:
+			last = event.Job
+			if !send(last) {
+				return
+			}
+		case <-ticker.C:
+			current, err := h.service.GetJob(ctx, id, userID)
+			if err != nil {
+				logger.Error("Failed to poll generation job", err)
+				return
+			}
+			if current.Status != last.Status || current.Progress != last.Progress {
+				last = *current
+				if !send(last) {
+					return
+				}
+				continue
+			}
+			// A comment line keeps proxies from closing an idle stream
+			if _, err := c.Writer.WriteString(": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// Translate translates text into a supported language
+// @Summary Translate text
+// @Description Translate text into one of the supported languages. Text is limited to 4096 characters
+// @Tags tts
+// @Accept json
+// @Produce json
+// @Param request body TranslateRequest true "Text and target language"
+// @Success 200 {object} response.Response{data=TranslateResponse}
+// @Failure 400 {object} response.Response
+// @Failure 422 {object} response.Response{data=[]FieldError}
+// @Failure 502 {object} response.Response
+// @Router /v1/tts/translate [post]
+func (h *handler) Translate(c *gin.Context) {
+	var req TranslateRequest
+	if !bindRequest(c, &req) {
+		return
+	}
+
+	result, err := h.service.Translate(c.Request.Context(), &req)
+	if err != nil {
+		respondTTSError(c, err, "Failed to translate text")
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// jobParams reads the job ID path parameter and the authenticated user, writing an error
+// response when either is missing
+func jobParams(c *gin.Context) (uint, uint, bool) {
+	id, err := params.PathID(c, "id")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid job ID")
+		return 0, 0, false
+	}
+
+	userID, ok := authctx.UserID(c)
+	if !ok {
+		response.ErrorKey(c, http.StatusUnauthorized, i18n.KeyUnauthorized)
+		return 0, 0, false
+	}
+	return id, userID, true
+}
+
+// bindRequest binds the JSON body, answering 422 with per-field messages for validation
+// failures and 400 for malformed JSON
+func bindRequest(c *gin.Context, req interface{}) bool {
+	err := c.ShouldBindJSON(req)
+	if err == nil {
+		return true
+	}
+
+	if fields := ValidationErrors(err); fields != nil {
+		c.JSON(http.StatusUnprocessableEntity, response.Response{
+			Code:    http.StatusUnprocessableEntity,
+			Message: "validation failed",
+			Data:    fields,
+		})
+		return false
+	}
+
+	response.ErrorKey(c, http.StatusBadRequest, i18n.KeyInvalidRequestPayload)
+	return false
+}
+
+// respondTTSError writes 422 for input errors, 503 when the queue is full or downloads are
+// not configured, the status of typed errors such as a missing job, and 502 for provider
+// and storage failures
+func respondTTSError(c *gin.Context, err error, message string) {
+	if errors.Is(err, ErrTextTooLong) || errors.Is(err, ErrUnsupportedLanguage) {
+		response.Error(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if errors.Is(err, ErrQueueFull) || errors.Is(err, ErrDownloadsUnavailable) {
+		response.Error(c, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	if status := response.StatusFromError(err, http.StatusBadGateway); status != http.StatusBadGateway {
+		response.ErrorFrom(c, status, err)
+		return
+	}
+	logger.Error(message, err)
+	response.Error(c, http.StatusBadGateway, message)
+}
@@ -0,0 +1,97 @@
+package tts
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/pkg/authctx"
+)
+
+// recordingService is a Service that counts generate and translate calls; other methods are
+// left to the embedded nil interface and panic if called
+type recordingService struct {
+	Service
+	calls int
+}
+
+func (s *recordingService) Generate(ctx context.Context, userID uint, req *GenerateRequest) (*JobResponse, error) {
+	s.calls++
+	return &JobResponse{ID: 1, Status: statusText(StatusQueued), Voice: req.Voice}, nil
+}
+
+func (s *recordingService) Translate(ctx context.Context, req *TranslateRequest) (*TranslateResponse, error) {
+	s.calls++
+	return &TranslateResponse{Text: req.Text, TargetLanguage: req.TargetLanguage}, nil
+}
+
+func TestRequestValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	body := func(fields map[string]string) string {
+		data, _ := json.Marshal(fields)
+		return string(data)
+	}
+	longest := strings.Repeat("语", MaxTextLength) // Limits count characters, not bytes
+	tooLong := strings.Repeat("a", MaxTextLength+1)
+
+	tests := []struct {
+		name      string
+		path      string
+		body      string
+		want      int
+		wantField string
+	}{
+		{name: "generate", path: "/generate", body: body(map[string]string{"text": "hello", "voice": "nova", "language": "en"}), want: http.StatusAccepted},
+		{name: "generate with the default voice", path: "/generate", body: body(map[string]string{"text": "hello"}), want: http.StatusAccepted},
+		{name: "generate longest text", path: "/generate", body: body(map[string]string{"text": longest}), want: http.StatusAccepted},
+		{name: "generate over-length text", path: "/generate", body: body(map[string]string{"text": tooLong}), want: http.StatusUnprocessableEntity, wantField: "text"},
+		{name: "generate without text", path: "/generate", body: body(map[string]string{"voice": "nova"}), want: http.StatusUnprocessableEntity, wantField: "text"},
+		{name: "generate invalid voice", path: "/generate", body: body(map[string]string{"text": "hello", "voice": "robot"}), want: http.StatusUnprocessableEntity, wantField: "voice"},
+		{name: "generate unsupported language", path: "/generate", body: body(map[string]string{"text": "hello", "language": "xx"}), want: http.StatusUnprocessableEntity, wantField: "language"},
+		{name: "generate malformed JSON", path: "/generate", body: `{"text":`, want: http.StatusBadRequest},
+		{name: "translate", path: "/translate", body: body(map[string]string{"text": "hello", "target_language": "zh"}), want: http.StatusOK},
+		{name: "translate over-length text", path: "/translate", body: body(map[string]string{"text": tooLong, "target_language": "zh"}), want: http.StatusUnprocessableEntity, wantField: "text"},
+		{name: "translate unsupported language", path: "/translate", body: body(map[string]string{"text": "hello", "target_language": "xx"}), want: http.StatusUnprocessableEntity, wantField: "target_language"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &recordingService{}
+			h := NewHandler(svc)
+			r := gin.New()
+			r.Use(func(c *gin.Context) { authctx.SetUserID(c, 1) })
+			r.POST("/generate", h.Generate)
+			r.POST("/translate", h.Translate)
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if accepted := tt.want < 300; (svc.calls > 0) != accepted {
+				t.Fatalf("service called %d times for status %d", svc.calls, w.Code)
+			}
+			if tt.wantField == "" {
+				return
+			}
+
+			var resp struct {
+				Data []FieldError `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if len(resp.Data) != 1 || resp.Data[0].Field != tt.wantField {
+				t.Fatalf("field errors = %+v, want one for %s", resp.Data, tt.wantField)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package tts
+
+import (
+	"time"
+)
+
+// Job is an asynchronous speech generation request and its outcome
+type Job struct {
+	ID          uint       `gorm:"primarykey" json:"id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	UserID      uint       `gorm:"not null;index" json:"user_id"` // The user who requested the generation
+	Text        string     `gorm:"type:text;not null" json:"-"`
+	Voice       string     `gorm:"size:20;not null" json:"voice"`
+	Language    string     `gorm:"size:10" json:"language"`
+	Status      int        `gorm:"default:0;not null" json:"status"`   // 0: queued, 1: processing, 2: completed, 3: failed
+	Progress    int        `gorm:"default:0;not null" json:"progress"` // Percent complete
+	AudioKey    string     `gorm:"size:500" json:"-"`                  // Storage key of the MP3, served only through signed links
+	Error       string     `gorm:"size:500" json:"error"`              // Why the job failed
+	Attempts    int        `gorm:"default:1;not null" json:"attempts"`
+	CompletedAt *time.Time `json:"completed_at"` // When the job completed or failed
+}
+
+// Job statuses
+const (
+	StatusQueued     = 0
+	StatusProcessing = 1
+	StatusCompleted  = 2
+	StatusFailed     = 3
+)
+
+// TableName specifies the database table name
+func (Job) TableName() string {
+	return "tts_jobs"
+}
+
+// Finished reports whether the job has completed or failed
+func (j *Job) Finished() bool {
+	return j.Status == StatusCompleted || j.Status == StatusFailed
+}
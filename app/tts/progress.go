@@ -0,0 +1,84 @@
+package tts
+
+import (
+	"sync"
+)
+
+// Job event names sent on the progress stream
+const (
+	EventQueued     = "queued"
+	EventProcessing = "processing"
+	EventProgress   = "progress"
+	EventCompleted  = "completed"
+	EventFailed     = "failed"
+)
+
+// JobEvent is a change in a job's state. Job is the complete state after the change, so a
+// client that misses intermediate events still ends up with the current one.
+type JobEvent struct {
+	Event string      `json:"event"`
+	Job   JobResponse `json:"job"`
+}
+
+// progressBroker delivers job events to the clients watching each job. It is shared by every
+// service instance in the process, since jobs run on whichever instance created them.
+type progressBroker struct {
+	mu       sync.Mutex
+	watchers map[uint]map[chan JobEvent]struct{}
+}
+
+var sharedProgressBroker = &progressBroker{watchers: make(map[uint]map[chan JobEvent]struct{})}
+
+// watch registers a watcher for the job's events. The channel holds only the latest
+// undelivered event. Call the returned function to unregister.
+func (b *progressBroker) watch(jobID uint) (<-chan JobEvent, func()) {
+	ch := make(chan JobEvent, 1)
+
+	b.mu.Lock()
+	if b.watchers[jobID] == nil {
+		b.watchers[jobID] = make(map[chan JobEvent]struct{})
+	}
+	b.watchers[jobID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.watchers[jobID], ch)
+		if len(b.watchers[jobID]) == 0 {
+			delete(b.watchers, jobID)
+		}
+	}
+}
+
+// publish sends the event to the job's watchers without blocking. A watcher that has not
+// taken the previous event gets this one in its place, since it supersedes it.
+func (b *progressBroker) publish(jobID uint, event JobEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.watchers[jobID] {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- event
+	}
+}
+
+// eventForJob names the event reporting the job's current state
+func eventForJob(job JobResponse) string {
+	switch job.Status {
+	case statusText(StatusProcessing):
+		if job.Progress > 0 {
+			return EventProgress
+		}
+		return EventProcessing
+	case statusText(StatusCompleted):
+		return EventCompleted
+	case statusText(StatusFailed):
+		return EventFailed
+	default:
+		return EventQueued
+	}
+}
@@ -0,0 +1,30 @@
+package tts
+
+// workerPool runs queued jobs on a fixed number of goroutines. The queue is bounded so a
+// burst of requests is refused instead of piling up unbounded work.
+type workerPool struct {
+	queue chan uint
+}
+
+// newWorkerPool starts workers goroutines calling run for each queued job ID
+func newWorkerPool(workers, queueSize int, run func(jobID uint)) *workerPool {
+	p := &workerPool{queue: make(chan uint, queueSize)}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for jobID := range p.queue {
+				run(jobID)
+			}
+		}()
+	}
+	return p
+}
+
+// enqueue adds the job to the queue, reporting false when the queue is full
+func (p *workerPool) enqueue(jobID uint) bool {
+	select {
+	case p.queue <- jobID:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,91 @@
+package tts
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Repository defines the interface for TTS job data operations
+type Repository interface {
+	Create(ctx context.Context, job *Job) error
+	GetByID(ctx context.Context, id uint) (*Job, error)
+	Update(ctx context.Context, id uint, updates map[string]interface{}) error
+	Claim(ctx context.Context, id uint) (bool, error)
+	Requeue(ctx context.Context, id uint) (bool, error)
+	ListIDsByStatus(ctx context.Context, status int) ([]uint, error)
+	FailStale(ctx context.Context, before time.Time, reason string) (int64, error)
+}
+
+// repository implements the Repository interface
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new TTS job repository instance
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// Create creates a new job
+func (r *repository) Create(ctx context.Context, job *Job) error {
+	return r.db.WithContext(ctx).Create(job).Error
+}
+
+// GetByID retrieves a job by ID
+func (r *repository) GetByID(ctx context.Context, id uint) (*Job, error) {
+	var job Job
+	if err := r.db.WithContext(ctx).First(&job, id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Update updates a job's fields
+func (r *repository) Update(ctx context.Context, id uint, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&Job{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// Claim moves a queued job to processing, reporting whether this call did so. Only one
+// worker, on any instance, can claim a job.
+func (r *repository) Claim(ctx context.Context, id uint) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&Job{}).
+		Where("id = ? AND status = ?", id, StatusQueued).
+		Update("status", StatusProcessing)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Requeue resets a failed job to queued for another attempt, reporting whether it was failed
+func (r *repository) Requeue(ctx context.Context, id uint) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&Job{}).
+		Where("id = ? AND status = ?", id, StatusFailed).
+		Updates(map[string]interface{}{
+			"status":       StatusQueued,
+			"progress":     0,
+			"error":        "",
+			"completed_at": nil,
+			"attempts":     gorm.Expr("attempts + 1"),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListIDsByStatus returns the IDs of jobs with the status, oldest first
+func (r *repository) ListIDsByStatus(ctx context.Context, status int) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).Model(&Job{}).Where("status = ?", status).Order("id").Pluck("id", &ids).Error
+	return ids, err
+}
+
+// FailStale fails processing jobs not updated since before, which no live worker can still
+// be running, and returns how many it failed
+func (r *repository) FailStale(ctx context.Context, before time.Time, reason string) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&Job{}).
+		Where("status = ? AND updated_at < ?", StatusProcessing, before).
+		Updates(map[string]interface{}{
+			"status":       StatusFailed,
+			"error":        reason,
+			"completed_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
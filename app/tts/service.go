@@ -0,0 +1,408 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/llamacto/llama-gin-kit/config"
+	apperrors "github.com/llamacto/llama-gin-kit/pkg/errors"
+	"github.com/llamacto/llama-gin-kit/pkg/logger"
+	"github.com/llamacto/llama-gin-kit/pkg/openai"
+	"github.com/llamacto/llama-gin-kit/pkg/signedurl"
+	"github.com/llamacto/llama-gin-kit/pkg/storage"
+	"gorm.io/gorm"
+)
+
+// ErrTextTooLong is returned when the input exceeds MaxTextLength
+var ErrTextTooLong = fmt.Errorf("text must be at most %d characters", MaxTextLength)
+
+// ErrUnsupportedLanguage is returned for a target language outside Languages
+var ErrUnsupportedLanguage = errors.New("unsupported language")
+
+// ErrJobNotFound is returned when the job does not exist or belongs to another user
+var ErrJobNotFound = apperrors.NotFound("tts_job_not_found", "generation job not found")
+
+// ErrJobNotRetryable is returned when retrying a job that has not failed
+var ErrJobNotRetryable = apperrors.Conflict("tts_job_not_retryable", "only failed jobs can be retried")
+
+// ErrAudioNotReady is returned when asking for the audio of a job that has not completed
+var ErrAudioNotReady = apperrors.Conflict("tts_audio_not_ready", "audio is only available once the job completes")
+
+// ErrDownloadsUnavailable is returned when no download signing secret is configured
+var ErrDownloadsUnavailable = errors.New("audio downloads are not configured")
+
+// ErrQueueFull is returned when the generation queue has no room for another job. The job
+// is recorded as failed, so it can be retried once the queue drains.
+var ErrQueueFull = errors.New("generation queue is full")
+
+const (
+	// jobTimeout bounds how long a job may run, across all of its chunks and the upload
+	jobTimeout = 10 * time.Minute
+	// chunkLength is the most characters synthesized per provider call. Text is split into
+	// chunks so long inputs report progress as each chunk finishes.
+	chunkLength = 500
+	// maxErrorLength is the most characters of a failure kept on the job
+	maxErrorLength = 500
+)
+
+// AudioPath is the route a job's audio is downloaded from with a signed link
+const AudioPath = "/v1/tts/jobs/%d/audio"
+
+// Store keeps generated audio, which is only served through signed links;
+// storage.R2Client implements it
+type Store interface {
+	UploadFile(ctx context.Context, key string, reader io.Reader, contentType string) error
+	OpenFile(ctx context.Context, key string) (io.ReadCloser, int64, error)
+}
+
+// Service defines the interface for TTS business logic
+type Service interface {
+	Generate(ctx context.Context, userID uint, req *GenerateRequest) (*JobResponse, error)
+	GetJob(ctx context.Context, id, userID uint) (*JobResponse, error)
+	RetryJob(ctx context.Context, id, userID uint) (*JobResponse, error)
+	AudioLink(ctx context.Context, id, userID uint) (*AudioLinkResponse, error)
+	OpenAudio(ctx context.Context, id uint) (io.ReadCloser, int64, error)
+	ResumeJobs(ctx context.Context) error
+	WatchJob(ctx context.Context, id, userID uint) (*JobResponse, <-chan JobEvent, func(), error)
+	Translate(ctx context.Context, req *TranslateRequest) (*TranslateResponse, error)
+}
+
+// service implements the Service interface
+type service struct {
+	repo        Repository
+	store       Store
+	signer      *signedurl.Signer
+	downloadTTL time.Duration
+	pool        *workerPool
+}
+
+// NewService creates a new TTS service instance and starts its generation workers. Audio
+// download links are signed with signer; without one, audio can't be downloaded.
+func NewService(repo Repository, store Store, signer *signedurl.Signer, cfg config.TTSConfig) Service {
+	s := &service{repo: repo, store: store, signer: signer, downloadTTL: cfg.DownloadTTL}
+	s.pool = newWorkerPool(cfg.Workers, cfg.QueueSize, s.process)
+	return s
+}
+
+// Generate records a generation job for the request's text and queues it for a worker. The
+// MP3 is uploaded to storage when done and its URL set on the job.
+func (s *service) Generate(ctx context.Context, userID uint, req *GenerateRequest) (*JobResponse, error) {
+	// Checked here as well as in binding so no caller can reach the paid provider with over-long text
+	if utf8.RuneCountInString(req.Text) > MaxTextLength {
+		return nil, ErrTextTooLong
+	}
+
+	voice := req.Voice
+	if voice == "" {
+		voice = DefaultVoice
+	}
+
+	job := &Job{
+		UserID:   userID,
+		Text:     req.Text,
+		Voice:    voice,
+		Language: req.Language,
+		Status:   StatusQueued,
+		Attempts: 1,
+	}
+	if err := s.repo.Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create job: %w", err)
+	}
+
+	if err := s.enqueue(job); err != nil {
+		return nil, err
+	}
+	resp := s.jobResponse(job)
+	return &resp, nil
+}
+
+// RetryJob queues one of the user's failed jobs again
+func (s *service) RetryJob(ctx context.Context, id, userID uint) (*JobResponse, error) {
+	job, err := s.getOwnedJob(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != StatusFailed {
+		return nil, ErrJobNotRetryable
+	}
+
+	requeued, err := s.repo.Requeue(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to requeue job: %w", err)
+	}
+	if !requeued {
+		// Retried concurrently
+		return nil, ErrJobNotRetryable
+	}
+
+	job, err = s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	sharedProgressBroker.publish(job.ID, JobEvent{Event: EventQueued, Job: s.jobResponse(job)})
+	if err := s.enqueue(job); err != nil {
+		return nil, err
+	}
+	resp := s.jobResponse(job)
+	return &resp, nil
+}
+
+// ResumeJobs queues the jobs left waiting by a previous run of the server, and fails jobs
+// whose processing was cut off, which can then be retried. Jobs are claimed before they
+// run, so instances resuming the same jobs don't generate them twice.
+func (s *service) ResumeJobs(ctx context.Context) error {
+	if _, err := s.repo.FailStale(ctx, time.Now().Add(-jobTimeout), "interrupted before completion"); err != nil {
+		return fmt.Errorf("failed to fail interrupted jobs: %w", err)
+	}
+
+	ids, err := s.repo.ListIDsByStatus(ctx, StatusQueued)
+	if err != nil {
+		return fmt.Errorf("failed to list queued jobs: %w", err)
+	}
+	for _, id := range ids {
+		if !s.pool.enqueue(id) {
+			// The rest wait for the next restart or a retry
+			logger.Warn("TTS queue full, %d queued jobs not resumed", len(ids))
+			break
+		}
+	}
+	return nil
+}
+
+// enqueue hands the job to the workers, failing it when the queue is full
+func (s *service) enqueue(job *Job) error {
+	if s.pool.enqueue(job.ID) {
+		return nil
+	}
+	s.fail(job, ErrQueueFull)
+	return ErrQueueFull
+}
+
+// GetJob retrieves one of the user's jobs
+func (s *service) GetJob(ctx context.Context, id, userID uint) (*JobResponse, error) {
+	job, err := s.getOwnedJob(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	resp := s.jobResponse(job)
+	return &resp, nil
+}
+
+// AudioLink returns a signed, expiring link to one of the user's completed jobs' audio
+func (s *service) AudioLink(ctx context.Context, id, userID uint) (*AudioLinkResponse, error) {
+	job, err := s.getOwnedJob(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.audioLink(job)
+}
+
+// audioLink signs a link to the job's audio, valid for the download TTL
+func (s *service) audioLink(job *Job) (*AudioLinkResponse, error) {
+	if job.Status != StatusCompleted || job.AudioKey == "" {
+		return nil, ErrAudioNotReady
+	}
+	if s.signer == nil {
+		return nil, ErrDownloadsUnavailable
+	}
+
+	expiresAt := time.Now().Add(s.downloadTTL)
+	return &AudioLinkResponse{
+		URL:       s.signer.SignURLUntil(fmt.Sprintf(AudioPath, job.ID), strconv.FormatUint(uint64(job.ID), 10), expiresAt),
+		ExpiresAt: expiresAt.Truncate(time.Second),
+	}, nil
+}
+
+// jobResponse converts a job for its owner, adding a signed audio link once it completed
+func (s *service) jobResponse(job *Job) JobResponse {
+	resp := toJobResponse(job)
+	if link, err := s.audioLink(job); err == nil {
+		resp.AudioURL = link.URL
+	}
+	return resp
+}
+
+// OpenAudio streams a completed job's audio and its size. It does not check ownership, so
+// callers must have verified a signed link for the job first.
+func (s *service) OpenAudio(ctx context.Context, id uint) (io.ReadCloser, int64, error) {
+	job, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, 0, ErrJobNotFound
+		}
+		return nil, 0, fmt.Errorf("failed to get job: %w", err)
+	}
+	if job.AudioKey == "" {
+		return nil, 0, ErrJobNotFound
+	}
+
+	reader, size, err := s.store.OpenFile(ctx, job.AudioKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrFileNotFound) {
+			return nil, 0, ErrJobNotFound
+		}
+		return nil, 0, fmt.Errorf("failed to open audio: %w", err)
+	}
+	return reader, size, nil
+}
+
+// WatchJob returns one of the user's jobs along with a channel of its later events. Call the
+// returned function to stop watching.
+func (s *service) WatchJob(ctx context.Context, id, userID uint) (*JobResponse, <-chan JobEvent, func(), error) {
+	// Watch before reading so no event between the read and the watch is missed
+	events, stop := sharedProgressBroker.watch(id)
+
+	job, err := s.getOwnedJob(ctx, id, userID)
+	if err != nil {
+		stop()
+		return nil, nil, nil, err
+	}
+	resp := s.jobResponse(job)
+	return &resp, events, stop, nil
+}
+
+// getOwnedJob loads the job, reporting another user's job as not found
+func (s *service) getOwnedJob(ctx context.Context, id, userID uint) (*Job, error) {
+	job, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrJobNotFound
+		}
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	if job.UserID != userID {
+		return nil, ErrJobNotFound
+	}
+	return job, nil
+}
+
+// process claims the queued job and runs it to completion, recording a failure on the job.
+// Workers call it; a job another worker or instance already claimed is skipped.
+func (s *service) process(jobID uint) {
+	ctx, cancel := context.WithTimeout(context.Background(), jobTimeout)
+	defer cancel()
+
+	claimed, err := s.repo.Claim(ctx, jobID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to claim TTS job %d", jobID), err)
+		return
+	}
+	if !claimed {
+		return
+	}
+	job, err := s.repo.GetByID(ctx, jobID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to load TTS job %d", jobID), err)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			s.fail(job, fmt.Errorf("panic: %v", r))
+		}
+	}()
+
+	sharedProgressBroker.publish(job.ID, JobEvent{Event: EventProcessing, Job: s.jobResponse(job)})
+	if err := s.run(ctx, job); err != nil {
+		s.fail(job, err)
+	}
+}
+
+// run synthesizes the job's text chunk by chunk, reporting progress after each, then
+// uploads the audio. The upload counts as one more step, so progress reaches 100 only
+// once the audio is stored.
+func (s *service) run(ctx context.Context, job *Job) error {
+	chunks := splitText(job.Text, chunkLength)
+	var audio bytes.Buffer
+	for i, chunk := range chunks {
+		data, err := openai.GenerateSpeech(ctx, chunk, job.Voice)
+		if err != nil {
+			return fmt.Errorf("failed to generate speech: %w", err)
+		}
+		// MP3 streams can be joined frame by frame, so the parts play back as one file
+		audio.Write(data)
+
+		job.Progress = (i + 1) * 100 / (len(chunks) + 1)
+		if err := s.setState(ctx, job, EventProgress, map[string]interface{}{"progress": job.Progress}); err != nil {
+			return err
+		}
+	}
+
+	key := fmt.Sprintf("tts/%d/%s.mp3", job.UserID, uuid.New().String())
+	if err := s.store.UploadFile(ctx, key, &audio, "audio/mpeg"); err != nil {
+		return fmt.Errorf("failed to upload audio: %w", err)
+	}
+
+	now := time.Now()
+	job.Status = StatusCompleted
+	job.Progress = 100
+	job.AudioKey = key
+	job.CompletedAt = &now
+	return s.setState(ctx, job, EventCompleted, map[string]interface{}{
+		"status":       job.Status,
+		"progress":     job.Progress,
+		"audio_key":    job.AudioKey,
+		"completed_at": job.CompletedAt,
+	})
+}
+
+// fail marks the job failed with err. It uses its own context, since err may be the job's
+// context expiring.
+func (s *service) fail(job *Job, err error) {
+	logger.Error(fmt.Sprintf("TTS job %d failed", job.ID), err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	message := err.Error()
+	if utf8.RuneCountInString(message) > maxErrorLength {
+		message = string([]rune(message)[:maxErrorLength])
+	}
+	now := time.Now()
+	job.Status = StatusFailed
+	job.Error = message
+	job.CompletedAt = &now
+	if err := s.setState(ctx, job, EventFailed, map[string]interface{}{
+		"status":       job.Status,
+		"error":        job.Error,
+		"completed_at": job.CompletedAt,
+	}); err != nil {
+		logger.Error(fmt.Sprintf("Failed to record TTS job %d failure", job.ID), err)
+	}
+}
+
+// setState stores updates, which callers have already applied to job, and sends the job's
+// new state to its watchers. Watchers own the job, so the completed state carries a signed
+// audio link.
+func (s *service) setState(ctx context.Context, job *Job, event string, updates map[string]interface{}) error {
+	job.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, job.ID, updates); err != nil {
+		return fmt.Errorf("failed to update job: %w", err)
+	}
+	sharedProgressBroker.publish(job.ID, JobEvent{Event: event, Job: s.jobResponse(job)})
+	return nil
+}
+
+// Translate translates the request's text into the target language
+func (s *service) Translate(ctx context.Context, req *TranslateRequest) (*TranslateResponse, error) {
+	if utf8.RuneCountInString(req.Text) > MaxTextLength {
+		return nil, ErrTextTooLong
+	}
+	language, ok := Languages[req.TargetLanguage]
+	if !ok {
+		return nil, ErrUnsupportedLanguage
+	}
+
+	text, err := openai.Translate(ctx, req.Text, language)
+	if err != nil {
+		return nil, fmt.Errorf("failed to translate text: %w", err)
+	}
+	return &TranslateResponse{Text: text, TargetLanguage: req.TargetLanguage}, nil
+}
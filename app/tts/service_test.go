@@ -0,0 +1,254 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/config"
+	"github.com/llamacto/llama-gin-kit/pkg/signedurl"
+	"github.com/llamacto/llama-gin-kit/pkg/storage"
+	"gorm.io/gorm"
+)
+
+// memoryRepository is an in-memory Repository
+type memoryRepository struct {
+	jobs map[uint]*Job
+}
+
+func (r *memoryRepository) Create(ctx context.Context, job *Job) error {
+	job.ID = uint(len(r.jobs) + 1)
+	r.jobs[job.ID] = job
+	return nil
+}
+
+func (r *memoryRepository) GetByID(ctx context.Context, id uint) (*Job, error) {
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *job
+	return &copied, nil
+}
+
+func (r *memoryRepository) Update(ctx context.Context, id uint, updates map[string]interface{}) error {
+	return nil
+}
+
+func (r *memoryRepository) Claim(ctx context.Context, id uint) (bool, error) { return false, nil }
+
+func (r *memoryRepository) Requeue(ctx context.Context, id uint) (bool, error) {
+	job, ok := r.jobs[id]
+	if !ok || job.Status != StatusFailed {
+		return false, nil
+	}
+	job.Status = StatusQueued
+	job.Attempts++
+	return true, nil
+}
+
+func (r *memoryRepository) ListIDsByStatus(ctx context.Context, status int) ([]uint, error) {
+	return nil, nil
+}
+
+func (r *memoryRepository) FailStale(ctx context.Context, before time.Time, reason string) (int64, error) {
+	return 0, nil
+}
+
+// memoryStore is an in-memory Store
+type memoryStore map[string]string
+
+func (s memoryStore) UploadFile(ctx context.Context, key string, reader io.Reader, contentType string) error {
+	data, err := io.ReadAll(reader)
+	s[key] = string(data)
+	return err
+}
+
+func (s memoryStore) OpenFile(ctx context.Context, key string) (io.ReadCloser, int64, error) {
+	data, ok := s[key]
+	if !ok {
+		return nil, 0, storage.ErrFileNotFound
+	}
+	return io.NopCloser(strings.NewReader(data)), int64(len(data)), nil
+}
+
+// newTestService returns a service without workers, so queued jobs stay queued
+func newTestService(t *testing.T, signer *signedurl.Signer, jobs ...*Job) (*service, *memoryRepository) {
+	t.Helper()
+	repo := &memoryRepository{jobs: make(map[uint]*Job)}
+	for _, job := range jobs {
+		repo.jobs[job.ID] = job
+	}
+	store := memoryStore{"tts/1/done.mp3": "mp3 data"}
+	svc := NewService(repo, store, signer, config.TTSConfig{QueueSize: 10, DownloadTTL: time.Minute})
+	return svc.(*service), repo
+}
+
+func TestAudioLink(t *testing.T) {
+	signer, _ := signedurl.NewSigner("test-secret")
+	completed := &Job{ID: 1, UserID: 1, Status: StatusCompleted, AudioKey: "tts/1/done.mp3"}
+	processing := &Job{ID: 2, UserID: 1, Status: StatusProcessing}
+
+	tests := []struct {
+		name    string
+		signer  *signedurl.Signer
+		jobID   uint
+		userID  uint
+		wantErr error
+	}{
+		{"owner of a completed job", signer, 1, 1, nil},
+		{"another user", signer, 1, 2, ErrJobNotFound},
+		{"job not completed", signer, 2, 1, ErrAudioNotReady},
+		{"missing job", signer, 9, 1, ErrJobNotFound},
+		{"no signing secret", nil, 1, 1, ErrDownloadsUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _ := newTestService(t, tt.signer, completed, processing)
+
+			link, err := svc.AudioLink(context.Background(), tt.jobID, tt.userID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			verifyAudioURL(t, signer, link.URL, "1")
+			if until := time.Until(link.ExpiresAt); until <= 0 || until > time.Minute {
+				t.Errorf("expires in %s, want within the download TTL", until)
+			}
+		})
+	}
+}
+
+func TestOpenAudio(t *testing.T) {
+	svc, _ := newTestService(t, nil,
+		&Job{ID: 1, UserID: 1, Status: StatusCompleted, AudioKey: "tts/1/done.mp3"},
+		&Job{ID: 2, UserID: 1, Status: StatusProcessing},
+		&Job{ID: 3, UserID: 1, Status: StatusCompleted, AudioKey: "tts/1/deleted.mp3"},
+	)
+
+	tests := []struct {
+		name    string
+		jobID   uint
+		want    string
+		wantErr error
+	}{
+		{"stored audio", 1, "mp3 data", nil},
+		{"no audio yet", 2, "", ErrJobNotFound},
+		{"audio missing from storage", 3, "", ErrJobNotFound},
+		{"missing job", 9, "", ErrJobNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, size, err := svc.OpenAudio(context.Background(), tt.jobID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer reader.Close()
+			data, _ := io.ReadAll(reader)
+			if string(data) != tt.want || size != int64(len(tt.want)) {
+				t.Errorf("audio = %q (%d bytes), want %q", data, size, tt.want)
+			}
+		})
+	}
+}
+
+// verifyAudioURL checks that audioURL is a valid signed link to the job's audio
+func verifyAudioURL(t *testing.T, signer *signedurl.Signer, audioURL string, jobID string) {
+	t.Helper()
+	parsed, err := url.Parse(audioURL)
+	if err != nil {
+		t.Fatalf("parse audio url: %v", err)
+	}
+	if parsed.Path != "/v1/tts/jobs/"+jobID+"/audio" {
+		t.Errorf("audio url path = %q", parsed.Path)
+	}
+	query := parsed.Query()
+	if err := signer.Verify(jobID, query.Get(signedurl.ExpiresParam), query.Get(signedurl.SignatureParam), time.Now()); err != nil {
+		t.Errorf("audio url does not verify: %v", err)
+	}
+}
+
+func TestWatchJobCompletedEventCarriesSignedLink(t *testing.T) {
+	signer, _ := signedurl.NewSigner("test-secret")
+	job := &Job{ID: 1, UserID: 1, Status: StatusProcessing, Progress: 50}
+	svc, _ := newTestService(t, signer, job)
+
+	current, events, stop, err := svc.WatchJob(context.Background(), 1, 1)
+	if err != nil {
+		t.Fatalf("WatchJob: %v", err)
+	}
+	defer stop()
+	if current.AudioURL != "" {
+		t.Errorf("unfinished job has audio url %q", current.AudioURL)
+	}
+
+	job.Status = StatusCompleted
+	job.Progress = 100
+	job.AudioKey = "tts/1/done.mp3"
+	if err := svc.setState(context.Background(), job, EventCompleted, nil); err != nil {
+		t.Fatalf("setState: %v", err)
+	}
+
+	select {
+	case event := <-events:
+		if event.Event != EventCompleted {
+			t.Fatalf("event = %q, want %q", event.Event, EventCompleted)
+		}
+		if strings.Contains(event.Job.AudioURL, job.AudioKey) {
+			t.Errorf("event exposes the storage key: %q", event.Job.AudioURL)
+		}
+		verifyAudioURL(t, signer, event.Job.AudioURL, "1")
+	case <-time.After(time.Second):
+		t.Fatal("no completed event")
+	}
+}
+
+func TestJobStatusCarriesSignedLink(t *testing.T) {
+	signer, _ := signedurl.NewSigner("test-secret")
+	svc, _ := newTestService(t, signer,
+		&Job{ID: 1, UserID: 1, Status: StatusCompleted, Progress: 100, AudioKey: "tts/1/done.mp3"},
+		&Job{ID: 2, UserID: 1, Status: StatusFailed, Error: "provider error"},
+	)
+
+	completed, err := svc.GetJob(context.Background(), 1, 1)
+	if err != nil {
+		t.Fatalf("GetJob: %v", err)
+	}
+	verifyAudioURL(t, signer, completed.AudioURL, "1")
+
+	if _, err := svc.GetJob(context.Background(), 1, 2); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("GetJob by another user = %v, want %v", err, ErrJobNotFound)
+	}
+
+	retried, err := svc.RetryJob(context.Background(), 2, 1)
+	if err != nil {
+		t.Fatalf("RetryJob: %v", err)
+	}
+	if retried.Status != "queued" || retried.AudioURL != "" {
+		t.Errorf("retried job = %+v, want queued without audio", retried)
+	}
+}
+
+func TestGenerateRejectsOverLengthTextBeforeQueueing(t *testing.T) {
+	svc, repo := newTestService(t, nil)
+
+	_, err := svc.Generate(context.Background(), 1, &GenerateRequest{Text: strings.Repeat("a", MaxTextLength+1)})
+	if !errors.Is(err, ErrTextTooLong) {
+		t.Fatalf("Generate() error = %v, want ErrTextTooLong", err)
+	}
+	if len(repo.jobs) != 0 {
+		t.Fatalf("over-length request created %d jobs", len(repo.jobs))
+	}
+}
@@ -0,0 +1,67 @@
+package tts
+
+import (
+	"strings"
+	"unicode"
+)
+
+// splitText splits text into chunks of at most maxLen characters. Chunks end at sentence
+// boundaries where possible, then at whitespace, so each synthesizes with natural pauses.
+func splitText(text string, maxLen int) []string {
+	var chunks []string
+	var current []rune
+	flush := func() {
+		if chunk := strings.TrimSpace(string(current)); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		current = current[:0]
+	}
+
+	for _, sentence := range splitSentences(text) {
+		runes := []rune(sentence)
+		if len(current)+len(runes) > maxLen {
+			flush()
+		}
+		for len(runes) > maxLen {
+			cut := lastSpace(runes[:maxLen])
+			if cut <= 0 {
+				cut = maxLen
+			}
+			current = append(current, runes[:cut]...)
+			flush()
+			runes = runes[cut:]
+		}
+		current = append(current, runes...)
+	}
+	flush()
+	return chunks
+}
+
+// splitSentences splits text after each sentence-ending mark or line break, keeping the
+// separators so the pieces join back into text
+func splitSentences(text string) []string {
+	var sentences []string
+	start := 0
+	for i, r := range text {
+		switch r {
+		case '.', '!', '?', '\n', '。', '！', '？':
+			end := i + len(string(r))
+			sentences = append(sentences, text[start:end])
+			start = end
+		}
+	}
+	if start < len(text) {
+		sentences = append(sentences, text[start:])
+	}
+	return sentences
+}
+
+// lastSpace returns the index of the last whitespace rune, or -1 when there is none
+func lastSpace(runes []rune) int {
+	for i := len(runes) - 1; i >= 0; i-- {
+		if unicode.IsSpace(runes[i]) {
+			return i
+		}
+	}
+	return -1
+}
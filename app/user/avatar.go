@@ -0,0 +1,75 @@
+package user
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/llamacto/llama-gin-kit/pkg/imaging"
+	"github.com/llamacto/llama-gin-kit/pkg/storage"
+)
+
+const (
+	// defaultAvatarMaxDimension is used when no upload config is loaded
+	defaultAvatarMaxDimension = 512
+	// maxAvatarSourcePixels bounds the decoded size of an upload so a small, highly
+	// compressed file can't expand into gigabytes of pixels
+	maxAvatarSourcePixels = 40_000_000
+	avatarJPEGQuality     = 90
+)
+
+// avatarTypes are the image formats that can be decoded and re-encoded as avatars
+var avatarTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// processAvatar validates an avatar image, scales it down so neither side exceeds maxDim
+// and re-encodes it. Every upload is re-encoded, even one already within bounds, so only
+// pixels are stored: EXIF metadata such as GPS location and any data appended after the
+// image are dropped. JPEGs stay JPEG and everything else becomes PNG. It returns the data
+// to store and its content type.
+func processAvatar(data []byte, contentType string, maxDim int) ([]byte, string, error) {
+	if !avatarTypes[contentType] {
+		return nil, "", fmt.Errorf("%w: avatar must be a JPEG, PNG or GIF image", storage.ErrUnsupportedMediaType)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: invalid image: %v", storage.ErrUnsupportedMediaType, err)
+	}
+	if cfg.Width*cfg.Height > maxAvatarSourcePixels {
+		return nil, "", fmt.Errorf("%w: image dimensions are too large", storage.ErrFileTooLarge)
+	}
+
+	var img image.Image
+	switch contentType {
+	case "image/jpeg":
+		img, err = jpeg.Decode(bytes.NewReader(data))
+	case "image/png":
+		img, err = png.Decode(bytes.NewReader(data))
+	default:
+		// Only the first frame of an animated GIF is kept
+		img, err = gif.Decode(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: invalid image: %v", storage.ErrUnsupportedMediaType, err)
+	}
+	img = imaging.Fit(img, maxDim)
+
+	var buf bytes.Buffer
+	if contentType == "image/jpeg" {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: avatarJPEGQuality})
+	} else {
+		contentType = "image/png"
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode avatar: %w", err)
+	}
+	return buf.Bytes(), contentType, nil
+}
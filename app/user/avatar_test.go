@@ -0,0 +1,171 @@
+package user
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/llamacto/llama-gin-kit/pkg/storage"
+)
+
+// secretMarker stands in for private data, such as a GPS location, carried beside the pixels
+const secretMarker = "GPS 51.5007N 0.1246W"
+
+// encodeTestImage returns a w x h image in the given format
+func encodeTestImage(t *testing.T, format string, w, h int) []byte {
+	t.Helper()
+	img := image.NewPaletted(image.Rect(0, 0, w, h), color.Palette{color.White, color.Black})
+	for x := 0; x < w; x += 2 {
+		img.SetColorIndex(x, 0, 1)
+	}
+
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, nil)
+	case "png":
+		err = png.Encode(&buf, img)
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatalf("encode %s: %v", format, err)
+	}
+	return buf.Bytes()
+}
+
+// withEXIF inserts an APP1 EXIF segment holding secretMarker after the JPEG start marker
+func withEXIF(data []byte) []byte {
+	payload := append([]byte("Exif\x00\x00"), secretMarker...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	out := append([]byte(nil), data[:2]...)
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+// withPNGSize rewrites the width and height in a PNG header, keeping its checksum valid
+func withPNGSize(data []byte, w, h uint32) []byte {
+	out := append([]byte(nil), data...)
+	// Signature (8), IHDR length (4) and type (4) precede the width and height
+	binary.BigEndian.PutUint32(out[16:], w)
+	binary.BigEndian.PutUint32(out[20:], h)
+	binary.BigEndian.PutUint32(out[29:], crc32.ChecksumIEEE(out[12:29]))
+	return out
+}
+
+func TestProcessAvatar(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      []byte
+		typ       string
+		wantType  string
+		wantW     int
+		wantH     int
+		wantErr   error
+		forbidden string // Bytes that must not survive into the stored avatar
+	}{
+		{
+			name:      "JPEG within bounds loses EXIF",
+			data:      withEXIF(encodeTestImage(t, "jpeg", 64, 32)),
+			typ:       "image/jpeg",
+			wantType:  "image/jpeg",
+			wantW:     64,
+			wantH:     32,
+			forbidden: secretMarker,
+		},
+		{
+			name:      "PNG within bounds loses appended data",
+			data:      append(encodeTestImage(t, "png", 64, 32), secretMarker...),
+			typ:       "image/png",
+			wantType:  "image/png",
+			wantW:     64,
+			wantH:     32,
+			forbidden: secretMarker,
+		},
+		{
+			name:     "GIF becomes PNG",
+			data:     encodeTestImage(t, "gif", 16, 16),
+			typ:      "image/gif",
+			wantType: "image/png",
+			wantW:    16,
+			wantH:    16,
+		},
+		{
+			name:     "wide PNG is scaled down",
+			data:     encodeTestImage(t, "png", 1024, 256),
+			typ:      "image/png",
+			wantType: "image/png",
+			wantW:    128,
+			wantH:    32,
+		},
+		{
+			name:     "tall JPEG is scaled down",
+			data:     encodeTestImage(t, "jpeg", 200, 800),
+			typ:      "image/jpeg",
+			wantType: "image/jpeg",
+			wantW:    32,
+			wantH:    128,
+		},
+		{
+			name:    "too many pixels",
+			data:    withPNGSize(encodeTestImage(t, "png", 8, 8), 8000, 8000),
+			typ:     "image/png",
+			wantErr: storage.ErrFileTooLarge,
+		},
+		{
+			name:    "unsupported type",
+			data:    encodeTestImage(t, "png", 8, 8),
+			typ:     "image/webp",
+			wantErr: storage.ErrUnsupportedMediaType,
+		},
+		{
+			name:    "not an image",
+			data:    []byte("not an image"),
+			typ:     "image/png",
+			wantErr: storage.ErrUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, typ, err := processAvatar(tt.data, tt.typ, 128)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("processAvatar() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if typ != tt.wantType {
+				t.Fatalf("content type = %s, want %s", typ, tt.wantType)
+			}
+			cfg, format, err := image.DecodeConfig(bytes.NewReader(out))
+			if err != nil {
+				t.Fatalf("stored avatar does not decode: %v", err)
+			}
+			if "image/"+format != tt.wantType {
+				t.Fatalf("stored avatar is %s, want %s", format, tt.wantType)
+			}
+			if cfg.Width != tt.wantW || cfg.Height != tt.wantH {
+				t.Fatalf("stored avatar is %dx%d, want %dx%d", cfg.Width, cfg.Height, tt.wantW, tt.wantH)
+			}
+			if bytes.Equal(out, tt.data) {
+				t.Fatal("avatar was stored as uploaded, want it re-encoded")
+			}
+			if tt.forbidden != "" && bytes.Contains(out, []byte(tt.forbidden)) {
+				t.Fatalf("stored avatar still contains %q", tt.forbidden)
+			}
+		})
+	}
+}
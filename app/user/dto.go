@@ -15,10 +15,37 @@ type UserLoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
-// UserLoginResponse 用户登录响应
+// UserLoginResponse 用户登录响应。开启两步验证的账户只返回 pending_token，
+// 需携带验证码调用 /login/two-factor 换取 token
 type UserLoginResponse struct {
-	Token string `json:"token"`
-	User  *User  `json:"user"`
+	Token             string `json:"token,omitempty"`
+	RefreshToken      string `json:"refresh_token,omitempty"`
+	User              *User  `json:"user,omitempty"`
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	PendingToken      string `json:"pending_token,omitempty"`
+}
+
+// UserRefreshRequest 用 refresh token 换取新 token 的请求
+type UserRefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// UserTwoFactorLoginRequest 两步验证登录请求，code 为验证器中的 6 位验证码或一个恢复码
+type UserTwoFactorLoginRequest struct {
+	PendingToken string `json:"pending_token" binding:"required"`
+	Code         string `json:"code" binding:"required,max=32"`
+}
+
+// TwoFactorEnrollment 开启两步验证的返回信息。恢复码只在此时返回一次
+type TwoFactorEnrollment struct {
+	Secret          string   `json:"secret"`
+	ProvisioningURI string   `json:"provisioning_uri"` // otpauth:// 地址，可生成二维码供验证器扫描
+	RecoveryCodes   []string `json:"recovery_codes"`
+}
+
+// TwoFactorConfirmRequest 确认两步验证请求
+type TwoFactorConfirmRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
 }
 
 // UserUpdateRequest 用户信息更新请求
@@ -39,3 +66,9 @@ type UserChangePasswordRequest struct {
 type UserPasswordResetRequest struct {
 	Email string `json:"email" binding:"required,email"`
 }
+
+// UserPasswordResetConfirmRequest 确认重置密码请求
+type UserPasswordResetConfirmRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6,max=50"`
+}
@@ -1,11 +1,23 @@
 package user
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/llamacto/llama-gin-kit/config"
+	"github.com/llamacto/llama-gin-kit/pkg/authctx"
+	apperrors "github.com/llamacto/llama-gin-kit/pkg/errors"
+	"github.com/llamacto/llama-gin-kit/pkg/jwt"
 	"github.com/llamacto/llama-gin-kit/pkg/logger"
+	"github.com/llamacto/llama-gin-kit/pkg/pagination"
+	"github.com/llamacto/llama-gin-kit/pkg/params"
+	"github.com/llamacto/llama-gin-kit/pkg/response"
+	"github.com/llamacto/llama-gin-kit/pkg/storage"
 )
 
 // UserHandler 用户处理器
@@ -36,9 +48,9 @@ func (h *UserHandler) Register(c *gin.Context) {
 		return
 	}
 
-	user, err := h.service.Register(&req)
+	user, err := h.service.Register(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeUserError(c, err, http.StatusBadRequest)
 		return
 	}
 
@@ -47,12 +59,12 @@ func (h *UserHandler) Register(c *gin.Context) {
 
 // Login 用户登录
 // @Summary 用户登录
-// @Description 用户登录并获取令牌
+// @Description 用户登录并获取令牌。开启两步验证的账户返回 two_factor_required 和有效期 5 分钟的 pending_token，需调用 /login/two-factor 完成登录
 // @Tags 用户
 // @Accept json
 // @Produce json
 // @Param body body UserLoginRequest true "登录信息"
-// @Success 200 {object} User
+// @Success 200 {object} UserLoginResponse
 // @Router /users/login [post]
 func (h *UserHandler) Login(c *gin.Context) {
 	var req UserLoginRequest
@@ -61,15 +73,137 @@ func (h *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.service.Login(&req)
+	resp, err := h.service.Login(c.Request.Context(), &req, c.ClientIP())
+	if err != nil {
+		if errors.Is(err, jwt.ErrNotInitialized) {
+			logger.Error("登录失败，JWT 服务未初始化:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "认证服务不可用", "code": jwt.ErrCodeNotInitialized})
+			return
+		}
+		writeUserError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// LoginTwoFactor 两步验证登录
+// @Summary 两步验证登录
+// @Description 使用登录返回的 pending_token 和验证器中的 6 位验证码（或一个恢复码）换取登录令牌，恢复码使用后失效
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Param body body UserTwoFactorLoginRequest true "pending token 与验证码"
+// @Success 200 {object} UserLoginResponse
+// @Failure 400 {object} map[string]string "验证码无效或登录已过期"
+// @Router /login/two-factor [post]
+func (h *UserHandler) LoginTwoFactor(c *gin.Context) {
+	var req UserTwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.service.CompleteTwoFactorLogin(c.Request.Context(), &req, c.ClientIP())
 	if err != nil {
+		writeTwoFactorError(c, err, "两步验证登录失败:")
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Refresh 刷新登录令牌
+// @Summary 刷新登录令牌
+// @Description 使用登录返回的 refresh_token 换取新的 token 和 refresh_token。access token 不能用于刷新
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Param body body UserRefreshRequest true "refresh token"
+// @Success 200 {object} UserLoginResponse
+// @Failure 400 {object} map[string]string "refresh token 无效或已过期"
+// @Failure 403 {object} map[string]string "账户被禁用或待审核"
+// @Router /login/refresh [post]
+func (h *UserHandler) Refresh(c *gin.Context) {
+	var req UserRefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	resp, err := h.service.RefreshLogin(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, jwt.ErrNotInitialized) {
+			logger.Error("刷新令牌失败，JWT 服务未初始化:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "认证服务不可用", "code": jwt.ErrCodeNotInitialized})
+			return
+		}
+		writeUserError(c, err, http.StatusInternalServerError)
+		return
+	}
+
 	c.JSON(http.StatusOK, resp)
 }
 
+// EnableTwoFactor 开启两步验证
+// @Summary 开启两步验证
+// @Description 为当前用户生成 TOTP 密钥、otpauth:// 地址（可生成二维码）和 10 个恢复码。恢复码只返回这一次。调用 /users/two-factor/confirm 提交验证码后生效
+// @Tags 用户
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} TwoFactorEnrollment
+// @Failure 409 {object} map[string]string "已开启两步验证"
+// @Failure 503 {object} map[string]string "未配置 APP_SECRET"
+// @Router /users/two-factor [post]
+func (h *UserHandler) EnableTwoFactor(c *gin.Context) {
+	userID, exists := authctx.UserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权访问"})
+		return
+	}
+
+	enrollment, err := h.service.EnableTwoFactor(c.Request.Context(), userID)
+	if err != nil {
+		writeTwoFactorError(c, err, "开启两步验证失败:")
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// ConfirmTwoFactor 确认两步验证
+// @Summary 确认两步验证
+// @Description 提交验证器中的 6 位验证码，校验通过后当前用户登录时需要两步验证
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body TwoFactorConfirmRequest true "验证码"
+// @Success 200 {string} string "两步验证已开启"
+// @Failure 400 {object} map[string]string "验证码无效"
+// @Failure 409 {object} map[string]string "未开始或已开启两步验证"
+// @Router /users/two-factor/confirm [post]
+func (h *UserHandler) ConfirmTwoFactor(c *gin.Context) {
+	userID, exists := authctx.UserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权访问"})
+		return
+	}
+
+	var req TwoFactorConfirmRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	if err := h.service.ConfirmTwoFactor(c.Request.Context(), userID, req.Code); err != nil {
+		writeTwoFactorError(c, err, "确认两步验证失败:")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "两步验证已开启"})
+}
+
 // UpdateProfile 更新用户信息
 // @Summary 更新用户信息
 // @Description 更新当前用户的个人资料
@@ -80,12 +214,11 @@ func (h *UserHandler) Login(c *gin.Context) {
 // @Success 200 {object} User
 // @Router /users/profile [put]
 func (h *UserHandler) UpdateProfile(c *gin.Context) {
-	userIDVal, exists := c.Get("userID")
+	userID, exists := authctx.UserID(c)
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权访问"})
 		return
 	}
-	userID := userIDVal.(uint)
 
 	var req UserUpdateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -93,12 +226,106 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 		return
 	}
 
-	user, err := h.service.UpdateProfile(userID, &req)
+	user, err := h.service.UpdateProfile(c.Request.Context(), userID, &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeUserError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// UploadAvatar 上传头像
+// @Summary 上传头像
+// @Description 上传当前用户的头像图片（JPEG、PNG 或 GIF），文件类型以内容检测结果为准。超过最大边长的图片会被等比缩小，所有图片都会重新编码以去除 EXIF 等附加数据（GIF 保存为 PNG），旧头像会从存储中删除
+// @Tags 用户
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "头像图片"
+// @Success 200 {object} User "更新后的用户信息，avatar 为新头像地址"
+// @Failure 413 {object} map[string]string "文件过大"
+// @Failure 415 {object} map[string]string "不支持的文件类型"
+// @Router /users/avatar [post]
+func (h *UserHandler) UploadAvatar(c *gin.Context) {
+	userID, exists := authctx.UserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权访问"})
+		return
+	}
+
+	store := storage.GetR2Storage()
+	if store == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "存储服务未配置"})
+		return
+	}
+	limits := config.GlobalConfig.Upload
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limits.MaxSize+storage.MultipartOverhead)
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": storage.ErrFileTooLarge.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请上传头像文件"})
+		return
+	}
+	defer file.Close()
+
+	data, err := storage.ReadUpload(file, limits.MaxSize)
+	if err != nil {
+		c.JSON(storage.UploadErrorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	contentType, err := storage.ValidateUpload(data, header.Header.Get("Content-Type"), limits)
+	if err == nil {
+		maxDim := limits.AvatarMaxDimension
+		if maxDim <= 0 {
+			maxDim = defaultAvatarMaxDimension
+		}
+		data, contentType, err = processAvatar(data, contentType, maxDim)
+	}
+	if err != nil {
+		c.JSON(storage.UploadErrorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	current, err := h.service.GetProfile(c.Request.Context(), userID)
+	if err != nil {
+		writeUserError(c, err, http.StatusInternalServerError)
+		return
+	}
+	oldAvatar := current.Avatar
+
+	fileName := fmt.Sprintf("avatars/%d/%s%s", userID, uuid.New().String(), storage.ExtensionForType(contentType))
+	avatarURL, err := store.UploadFile(data, fileName, contentType)
+	if err != nil {
+		logger.Error("上传头像失败:", err)
+		c.JSON(storage.UploadErrorStatus(err, http.StatusInternalServerError), gin.H{"error": "上传头像失败"})
+		return
+	}
+
+	user, err := h.service.UpdateProfile(c.Request.Context(), userID, &UserUpdateRequest{Avatar: avatarURL})
+	if err != nil {
+		// The profile still points at the old avatar, so the new object is orphaned
+		if delErr := store.DeleteFile(fileName); delErr != nil {
+			logger.Error("删除未使用的头像失败:", delErr)
+		}
+		writeUserError(c, err, http.StatusBadRequest)
 		return
 	}
 
+	// Only objects under this user's avatar prefix are removed; external avatar URLs are left alone
+	if oldAvatar != "" && oldAvatar != avatarURL {
+		if key, ok := store.KeyFromURL(oldAvatar); ok && strings.HasPrefix(key, fmt.Sprintf("avatars/%d/", userID)) {
+			if err := store.DeleteFile(key); err != nil {
+				logger.Error("删除旧头像失败:", err)
+			}
+		}
+	}
+
 	c.JSON(http.StatusOK, user)
 }
 
@@ -112,12 +339,11 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 // @Success 200 {string} string "密码修改成功"
 // @Router /users/password [put]
 func (h *UserHandler) ChangePassword(c *gin.Context) {
-	userIDVal, exists := c.Get("userID")
+	userID, exists := authctx.UserID(c)
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权访问"})
 		return
 	}
-	userID := userIDVal.(uint)
 
 	var req UserChangePasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -125,23 +351,24 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.ChangePassword(userID, &req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := h.service.ChangePassword(c.Request.Context(), userID, &req); err != nil {
+		writeUserError(c, err, http.StatusBadRequest)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "密码修改成功"})
 }
 
-// ResetPassword 重置密码
-// @Summary 重置密码
-// @Description 通过邮箱重置用户密码
+// ResetPassword 申请重置密码
+// @Summary 申请重置密码
+// @Description 向邮箱发送一次性密码重置链接。无论邮箱是否注册都返回 200，避免泄露账户是否存在
 // @Tags 用户
 // @Accept json
 // @Produce json
 // @Param body body UserPasswordResetRequest true "邮箱信息"
-// @Success 200 {string} string "重置密码邮件已发送"
-// @Router /users/password/reset [post]
+// @Success 200 {string} string "如果该邮箱已注册，重置链接已发送"
+// @Failure 400 {string} string "无效的请求参数"
+// @Router /password/reset [post]
 func (h *UserHandler) ResetPassword(c *gin.Context) {
 	var req UserPasswordResetRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -149,17 +376,46 @@ func (h *UserHandler) ResetPassword(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.ResetPassword(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := h.service.RequestPasswordReset(c.Request.Context(), req.Email); err != nil {
+		logger.Error("申请重置密码失败:", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "如果该邮箱已注册，密码重置链接已发送"})
+}
+
+// ConfirmPasswordReset 确认重置密码
+// @Summary 确认重置密码
+// @Description 使用邮件中的重置令牌设置新密码，令牌仅可使用一次
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Param body body UserPasswordResetConfirmRequest true "重置令牌与新密码"
+// @Success 200 {string} string "密码重置成功"
+// @Failure 400 {string} string "重置链接无效或已过期"
+// @Router /password/reset/confirm [post]
+func (h *UserHandler) ConfirmPasswordReset(c *gin.Context) {
+	var req UserPasswordResetConfirmRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "密码重置邮件已发送"})
+	if err := h.service.ConfirmPasswordReset(c.Request.Context(), req.Token, req.NewPassword); err != nil {
+		if errors.Is(err, ErrInvalidResetToken) {
+			writeUserError(c, err, http.StatusBadRequest)
+			return
+		}
+		logger.Error("重置密码失败:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "重置密码失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "密码重置成功"})
 }
 
 // GetProfile 获取用户个人资料
 // @Summary 获取用户个人资料
-// @Description 获取当前登录用户的个人资料
+// @Description 获取当前登录用户的个人资料，包含最后登录时间 last_login 与登录 IP last_login_ip
 // @Tags 用户
 // @Accept json
 // @Produce json
@@ -167,21 +423,15 @@ func (h *UserHandler) ResetPassword(c *gin.Context) {
 // @Success 200 {object} User
 // @Router /users/profile [get]
 func (h *UserHandler) GetProfile(c *gin.Context) {
-	userIDVal, exists := c.Get("userID")
+	userID, exists := authctx.UserID(c)
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权访问"})
 		return
 	}
 
-	userID, ok := userIDVal.(uint)
-	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "用户ID类型错误"})
-		return
-	}
-
-	user, err := h.service.GetProfile(userID)
+	user, err := h.service.GetProfile(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeUserError(c, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -195,14 +445,13 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 // @Success 200 {string} string "账户已删除"
 // @Router /users/account [delete]
 func (h *UserHandler) DeleteAccount(c *gin.Context) {
-	userIDVal, exists := c.Get("userID")
+	userID, exists := authctx.UserID(c)
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权访问"})
 		return
 	}
-	userID := userIDVal.(uint)
 
-	if err := h.service.DeleteAccount(userID); err != nil {
+	if err := h.service.DeleteAccount(c.Request.Context(), userID); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -219,14 +468,13 @@ func (h *UserHandler) DeleteAccount(c *gin.Context) {
 // @Success 200 {object} User
 // @Router /users/{id} [get]
 func (h *UserHandler) Get(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 64)
+	id, err := params.PathID(c, "id")
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
 		return
 	}
 
-	user, err := h.service.GetByID(uint(id))
+	user, err := h.service.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
@@ -235,6 +483,37 @@ func (h *UserHandler) Get(c *gin.Context) {
 	c.JSON(http.StatusOK, user)
 }
 
+// Approve 审核通过用户
+// @Summary 审核通过用户
+// @Description 管理员激活待审核的用户账号，仅在开启 APP_REQUIRE_APPROVAL 时需要
+// @Tags 用户
+// @Produce json
+// @Param id path int true "用户ID"
+// @Success 200 {object} User
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /users/{id}/approve [post]
+func (h *UserHandler) Approve(c *gin.Context) {
+	id, err := params.PathID(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	user, err := h.service.ApproveUser(c.Request.Context(), id)
+	if err != nil {
+		if _, typed := apperrors.KindOf(err); typed {
+			writeUserError(c, err, http.StatusInternalServerError)
+			return
+		}
+		logger.Error("审核用户失败:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "审核用户失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
 // List 获取用户列表
 // @Summary 获取用户列表
 // @Description 分页获取用户列表
@@ -250,32 +529,35 @@ func (h *UserHandler) List(c *gin.Context) {
 
 	users, total, err := h.service.List(c.Request.Context(), page, pageSize)
 	if err != nil {
+		if response.Canceled(c, err) {
+			return
+		}
 		logger.Error("获取用户列表失败:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取用户列表失败"})
 		return
 	}
 
+	pagination.SetHeaders(c, total, page, pageSize)
 	c.JSON(http.StatusOK, gin.H{"total": total, "list": users})
 }
 
 // GetUserInfo 获取用户信息
 // @Summary 获取用户信息
 // @Description 根据用户ID获取用户详细信息
-// @Tags user
+// @Tags 用户
 // @Accept json
 // @Produce json
 // @Param id path int true "用户ID"
 // @Success 200 {object} UserInfo
 // @Router /users/info/{id} [get]
 func (h *UserHandler) GetUserInfo(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 64)
+	id, err := params.PathID(c, "id")
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
 		return
 	}
 
-	userInfo, err := h.service.GetUserByID(uint(id))
+	userInfo, err := h.service.GetUserByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
@@ -283,3 +565,70 @@ func (h *UserHandler) GetUserInfo(c *gin.Context) {
 
 	c.JSON(http.StatusOK, userInfo)
 }
+
+// maxBatchUserIDs limits how many users GetUsersByIDs resolves per request
+const maxBatchUserIDs = 100
+
+// GetUsersByIDs 批量获取用户信息
+// @Summary 批量获取用户信息
+// @Description 管理员根据逗号分隔的用户ID列表一次性获取多个用户信息，最多100个
+// @Tags 用户
+// @Produce json
+// @Param ids query string true "用户ID列表，例如 1,2,3"
+// @Success 200 {object} map[string]UserInfo
+// @Router /users/batch [get]
+func (h *UserHandler) GetUsersByIDs(c *gin.Context) {
+	var ids []uint
+	for _, part := range strings.Split(c.Query("ids"), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := params.ParseID(part)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID: " + part})
+			return
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) > maxBatchUserIDs {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Too many user IDs, maximum is " + strconv.Itoa(maxBatchUserIDs)})
+		return
+	}
+
+	users, err := h.service.GetUsersByIDs(c.Request.Context(), ids)
+	if err != nil {
+		logger.Error("批量获取用户信息失败:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "批量获取用户信息失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// writeTwoFactorError 输出两步验证错误：类型错误按其状态码返回，服务不可用返回 503，其余记录日志后返回 500
+func writeTwoFactorError(c *gin.Context, err error, logMessage string) {
+	if _, typed := apperrors.KindOf(err); typed {
+		writeUserError(c, err, http.StatusInternalServerError)
+		return
+	}
+	switch {
+	case errors.Is(err, jwt.ErrNotInitialized):
+		logger.Error(logMessage, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "认证服务不可用", "code": jwt.ErrCodeNotInitialized})
+	case errors.Is(err, ErrTwoFactorUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "两步验证未启用"})
+	default:
+		logger.Error(logMessage, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "两步验证失败"})
+	}
+}
+
+// writeUserError 按错误类型选择状态码（未分类错误使用 fallback），类型错误附带错误码
+func writeUserError(c *gin.Context, err error, fallback int) {
+	body := gin.H{"error": err.Error()}
+	if code := apperrors.CodeOf(err); code != "" {
+		body["code"] = code
+	}
+	c.JSON(response.StatusFromError(err, fallback), body)
+}
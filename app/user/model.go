@@ -3,6 +3,7 @@ package user
 import (
 	"time"
 
+	"github.com/llamacto/llama-gin-kit/pkg/invariant"
 	"gorm.io/gorm"
 )
 
@@ -19,15 +20,39 @@ type User struct {
 	Avatar    string         `gorm:"size:255" json:"avatar"`
 	Phone     string         `gorm:"size:20" json:"phone"`
 	Bio       string         `gorm:"size:500" json:"bio"`
-	Status    int            `gorm:"default:1" json:"status"` // 1: active, 0: disabled
+	Status    int            `gorm:"default:1" json:"status"` // 1: active, 0: disabled, 2: pending approval
 	LastLogin *time.Time     `json:"last_login"`
+	// LastLoginIP is the client address of the sign-in recorded in LastLogin
+	LastLoginIP string `gorm:"size:45" json:"last_login_ip,omitempty"`
+	// TwoFactorEnabled requires a TOTP or recovery code after the password at login
+	TwoFactorEnabled bool `gorm:"not null;default:false" json:"two_factor_enabled"`
+	// TwoFactorSecret is the TOTP secret sealed with APP_SECRET, set at enrollment
+	TwoFactorSecret string `gorm:"size:255" json:"-"`
+	// TwoFactorLastStep is the time step of the last accepted TOTP code, so no code works twice
+	TwoFactorLastStep int64 `gorm:"not null;default:0" json:"-"`
 }
 
+// User statuses
+const (
+	UserStatusDisabled = 0
+	UserStatusActive   = 1
+	UserStatusPending  = 2
+)
+
 // TableName specifies the database table name
 func (User) TableName() string {
 	return "users"
 }
 
+// BeforeSave rejects user rows without a username, email or password hash
+func (u *User) BeforeSave(tx *gorm.DB) error {
+	return invariant.Required(tx, "user",
+		invariant.Field{Column: "username", Value: u.Username},
+		invariant.Field{Column: "email", Value: u.Email},
+		invariant.Field{Column: "password", Value: u.Password},
+	)
+}
+
 // UserInfo represents user information data transfer object
 type UserInfo struct {
 	ID        uint       `json:"id"`
@@ -40,3 +65,34 @@ type UserInfo struct {
 	Status    int        `json:"status"`
 	LastLogin *time.Time `json:"last_login"`
 }
+
+// PasswordResetToken is a single-use password reset credential. Only the
+// SHA-256 hash of the token is stored; the plaintext goes out in the email.
+type PasswordResetToken struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+}
+
+// TableName specifies the database table name
+func (PasswordResetToken) TableName() string {
+	return "password_reset_tokens"
+}
+
+// RecoveryCode is a single-use code that stands in for a TOTP code when the user has lost
+// their authenticator. Only the SHA-256 hash of the code is stored.
+type RecoveryCode struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+}
+
+// TableName specifies the database table name
+func (RecoveryCode) TableName() string {
+	return "user_recovery_codes"
+}
@@ -0,0 +1,25 @@
+package user
+
+import (
+	"github.com/llamacto/llama-gin-kit/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// HashPassword bcrypt-hashes a plaintext password using the configured cost factor
+func HashPassword(plaintext string) (string, error) {
+	cost := bcrypt.DefaultCost
+	if config.GlobalConfig != nil && config.GlobalConfig.App.BcryptCost != 0 {
+		cost = config.GlobalConfig.App.BcryptCost
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(plaintext), cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+// CheckPassword reports whether the plaintext matches the user's stored password hash
+func CheckPassword(user *User, plaintext string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(plaintext)) == nil
+}
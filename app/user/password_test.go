@@ -0,0 +1,69 @@
+package user
+
+import (
+	"testing"
+
+	"github.com/llamacto/llama-gin-kit/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHashPassword(t *testing.T) {
+	hashed, err := HashPassword("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+	if hashed == "correct horse battery staple" {
+		t.Fatal("HashPassword() returned the plaintext")
+	}
+
+	u := &User{Password: hashed}
+	tests := []struct {
+		name      string
+		plaintext string
+		want      bool
+	}{
+		{name: "original plaintext", plaintext: "correct horse battery staple", want: true},
+		{name: "wrong plaintext", plaintext: "correct horse battery stapler"},
+		{name: "empty plaintext"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CheckPassword(u, tt.plaintext); got != tt.want {
+				t.Fatalf("CheckPassword(%q) = %v, want %v", tt.plaintext, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHashPasswordUsesConfiguredCost(t *testing.T) {
+	saved := config.GlobalConfig
+	t.Cleanup(func() { config.GlobalConfig = saved })
+
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		want int
+	}{
+		{name: "no config", want: bcrypt.DefaultCost},
+		{name: "cost unset", cfg: &config.Config{}, want: bcrypt.DefaultCost},
+		{name: "configured cost", cfg: &config.Config{App: config.AppConfig{BcryptCost: bcrypt.MinCost}}, want: bcrypt.MinCost},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.GlobalConfig = tt.cfg
+			hashed, err := HashPassword("secret")
+			if err != nil {
+				t.Fatalf("HashPassword() error = %v", err)
+			}
+			cost, err := bcrypt.Cost([]byte(hashed))
+			if err != nil {
+				t.Fatalf("bcrypt.Cost() error = %v", err)
+			}
+			if cost != tt.want {
+				t.Fatalf("hash cost = %d, want %d", cost, tt.want)
+			}
+		})
+	}
+}
@@ -2,21 +2,37 @@ package user
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrResetTokenInvalid is returned when a reset token is unknown, expired or already used
+var ErrResetTokenInvalid = errors.New("password reset token is invalid or expired")
+
 // UserRepository interface for user data access
 type UserRepository interface {
 	Create(ctx context.Context, user *User) error
 	Update(ctx context.Context, user *User) error
+	UpdateColumns(ctx context.Context, id uint, columns map[string]interface{}) error
 	Delete(ctx context.Context, id uint) error
 	Get(ctx context.Context, id uint) (*User, error)
 	List(ctx context.Context, page, pageSize int) ([]*User, int64, error)
 	GetByUsername(ctx context.Context, username string) (*User, error)
 	GetByEmail(ctx context.Context, email string) (*User, error)
 	ExistsByEmail(ctx context.Context, email string) (bool, error)
-	FindByID(id uint) (*UserInfo, error)
+	FindByID(ctx context.Context, id uint) (*UserInfo, error)
+	GetUsersByIDs(ctx context.Context, ids []uint) (map[uint]UserInfo, error)
+	UpdateLastLogin(ctx context.Context, userID uint, at time.Time, ip string, staleBefore time.Time) (bool, error)
+	CreatePasswordResetToken(ctx context.Context, token *PasswordResetToken) error
+	ResetPasswordWithToken(ctx context.Context, tokenHash string, passwordHash string) error
+	InvalidatePasswordResetTokens(ctx context.Context, userID uint) error
+	StartTwoFactorEnrollment(ctx context.Context, userID uint, sealedSecret string, codeHashes []string) error
+	ActivateTwoFactor(ctx context.Context, userID uint) error
+	ClaimTwoFactorStep(ctx context.Context, userID uint, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, userID uint, codeHash string) (bool, error)
 }
 
 // UserRepositoryImpl implementation of UserRepository
@@ -39,6 +55,26 @@ func (r *UserRepositoryImpl) Update(ctx context.Context, user *User) error {
 	return r.db.WithContext(ctx).Save(user).Error
 }
 
+// UpdateColumns writes only the given columns of a user, plus updated_at. Unlike Update it
+// never writes back fields the caller did not change, so a stale copy cannot undo a
+// concurrent write such as a claimed TOTP step.
+func (r *UserRepositoryImpl) UpdateColumns(ctx context.Context, id uint, columns map[string]interface{}) error {
+	values := make(map[string]interface{}, len(columns)+1)
+	for column, value := range columns {
+		values[column] = value
+	}
+	values["updated_at"] = time.Now()
+
+	result := r.db.WithContext(ctx).Model(&User{}).Where("id = ?", id).UpdateColumns(values)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 // Delete removes a user by ID
 func (r *UserRepositoryImpl) Delete(ctx context.Context, id uint) error {
 	return r.db.WithContext(ctx).Delete(&User{}, id).Error
@@ -98,13 +134,154 @@ func (r *UserRepositoryImpl) ExistsByEmail(ctx context.Context, email string) (b
 }
 
 // FindByID retrieves user information by ID
-func (r *UserRepositoryImpl) FindByID(id uint) (*UserInfo, error) {
+func (r *UserRepositoryImpl) FindByID(ctx context.Context, id uint) (*UserInfo, error) {
 	var user User
-	if err := r.db.First(&user, "id = ?", id).Error; err != nil {
+	if err := r.db.WithContext(ctx).First(&user, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+
+	info := toUserInfo(&user)
+	return &info, nil
+}
+
+// GetUsersByIDs retrieves user information for several users with a single IN query.
+// Unknown IDs are absent from the result; empty input returns an empty map without querying.
+func (r *UserRepositoryImpl) GetUsersByIDs(ctx context.Context, ids []uint) (map[uint]UserInfo, error) {
+	result := make(map[uint]UserInfo, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	var users []User
+	if err := r.db.WithContext(ctx).Where("id IN ?", uniqueIDs(ids)).Find(&users).Error; err != nil {
 		return nil, err
 	}
 
-	return &UserInfo{
+	for i := range users {
+		result[users[i].ID] = toUserInfo(&users[i])
+	}
+	return result, nil
+}
+
+// UpdateLastLogin records a successful sign-in, but only when the stored last_login is
+// missing or older than staleBefore. It reports whether the row was written. The columns
+// are updated directly so a sign-in does not bump updated_at.
+func (r *UserRepositoryImpl) UpdateLastLogin(ctx context.Context, userID uint, at time.Time, ip string, staleBefore time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&User{}).
+		Where("id = ? AND (last_login IS NULL OR last_login < ?)", userID, staleBefore).
+		UpdateColumns(map[string]interface{}{"last_login": at, "last_login_ip": ip})
+	return result.RowsAffected > 0, result.Error
+}
+
+// CreatePasswordResetToken stores a new reset token and retires any earlier
+// unused tokens for the same user, so only the latest email link works
+func (r *UserRepositoryImpl) CreatePasswordResetToken(ctx context.Context, token *PasswordResetToken) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := invalidateResetTokens(tx, token.UserID, time.Now()); err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+// ResetPasswordWithToken consumes an unused, unexpired reset token and sets the
+// owner's password hash in one transaction. The token row is locked so two
+// concurrent requests cannot both use it.
+func (r *UserRepositoryImpl) ResetPasswordWithToken(ctx context.Context, tokenHash string, passwordHash string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		var token PasswordResetToken
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, now).
+			First(&token).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrResetTokenInvalid
+		}
+		if err != nil {
+			return err
+		}
+
+		result := tx.Model(&User{}).Where("id = ?", token.UserID).Update("password", passwordHash)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrResetTokenInvalid
+		}
+
+		return invalidateResetTokens(tx, token.UserID, now)
+	})
+}
+
+// InvalidatePasswordResetTokens marks all unused reset tokens of a user as used
+func (r *UserRepositoryImpl) InvalidatePasswordResetTokens(ctx context.Context, userID uint) error {
+	return invalidateResetTokens(r.db.WithContext(ctx), userID, time.Now())
+}
+
+// invalidateResetTokens marks the user's unused reset tokens as used at the given time
+func invalidateResetTokens(tx *gorm.DB, userID uint, at time.Time) error {
+	return tx.Model(&PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", at).Error
+}
+
+// StartTwoFactorEnrollment stores a new, not yet active TOTP secret for the user and replaces
+// their recovery codes, in one transaction
+func (r *UserRepositoryImpl) StartTwoFactorEnrollment(ctx context.Context, userID uint, sealedSecret string, codeHashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&User{}).Where("id = ?", userID).UpdateColumns(map[string]interface{}{
+			"two_factor_enabled":   false,
+			"two_factor_secret":    sealedSecret,
+			"two_factor_last_step": 0,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Where("user_id = ?", userID).Delete(&RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]RecoveryCode, 0, len(codeHashes))
+		for _, hash := range codeHashes {
+			codes = append(codes, RecoveryCode{UserID: userID, CodeHash: hash})
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// ActivateTwoFactor turns on two-factor authentication for the user's enrolled secret
+func (r *UserRepositoryImpl) ActivateTwoFactor(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Model(&User{}).
+		Where("id = ? AND two_factor_secret <> ''", userID).
+		UpdateColumn("two_factor_enabled", true).Error
+}
+
+// ClaimTwoFactorStep records step as the user's last accepted TOTP step. It reports false
+// when a code from that step or a later one was already accepted, so concurrent requests
+// cannot both use one code.
+func (r *UserRepositoryImpl) ClaimTwoFactorStep(ctx context.Context, userID uint, step int64) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&User{}).
+		Where("id = ? AND two_factor_last_step < ?", userID, step).
+		UpdateColumn("two_factor_last_step", step)
+	return result.RowsAffected > 0, result.Error
+}
+
+// UseRecoveryCode marks the user's unused recovery code with the given hash as used. It
+// reports false when there is no such code.
+func (r *UserRepositoryImpl) UseRecoveryCode(ctx context.Context, userID uint, codeHash string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+// toUserInfo converts a User to its public UserInfo
+func toUserInfo(user *User) UserInfo {
+	return UserInfo{
 		ID:        user.ID,
 		Username:  user.Username,
 		Email:     user.Email,
@@ -114,5 +291,18 @@ func (r *UserRepositoryImpl) FindByID(id uint) (*UserInfo, error) {
 		Bio:       user.Bio,
 		Status:    user.Status,
 		LastLogin: user.LastLogin,
-	}, nil
+	}
+}
+
+// uniqueIDs removes duplicate IDs while keeping their order
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
 }
@@ -0,0 +1,138 @@
+package user
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/llamacto/llama-gin-kit/pkg/dbtest"
+)
+
+func TestGetUsersByIDs(t *testing.T) {
+	tests := []struct {
+		name      string
+		ids       []uint
+		wantQuery bool
+		wantArgs  []driver.Value
+		wantUsers []string
+	}{
+		{name: "empty input"},
+		{
+			name:      "several users",
+			ids:       []uint{1, 2, 3},
+			wantQuery: true,
+			wantArgs:  []driver.Value{int64(1), int64(2), int64(3)},
+			wantUsers: []string{"ada", "grace"},
+		},
+		{
+			name:      "repeated IDs",
+			ids:       []uint{2, 1, 2},
+			wantQuery: true,
+			wantArgs:  []driver.Value{int64(2), int64(1)},
+			wantUsers: []string{"ada", "grace"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, db := dbtest.Open(t)
+			// User 3 does not exist
+			db.Returns(`FROM "users"`, []string{"id", "username", "email"},
+				[]driver.Value{int64(1), "ada", "ada@example.com"},
+				[]driver.Value{int64(2), "grace", "grace@example.com"})
+
+			users, err := NewUserRepository(gormDB).GetUsersByIDs(context.Background(), tt.ids)
+			if err != nil {
+				t.Fatalf("GetUsersByIDs() error = %v", err)
+			}
+
+			stmts := db.Statements()
+			if !tt.wantQuery {
+				if len(stmts) != 0 {
+					t.Fatalf("empty input sent %v", stmts)
+				}
+			} else {
+				if len(stmts) != 1 {
+					t.Fatalf("sent %d statements, want one IN query: %v", len(stmts), stmts)
+				}
+				if len(stmts[0].Args) != len(tt.wantArgs) {
+					t.Fatalf("query args = %v, want %v", stmts[0].Args, tt.wantArgs)
+				}
+				for i, arg := range tt.wantArgs {
+					if stmts[0].Args[i] != arg {
+						t.Fatalf("query args = %v, want %v", stmts[0].Args, tt.wantArgs)
+					}
+				}
+			}
+
+			if users == nil || len(users) != len(tt.wantUsers) {
+				t.Fatalf("GetUsersByIDs() = %v, want %v", users, tt.wantUsers)
+			}
+			for i, name := range tt.wantUsers {
+				if got := users[uint(i+1)]; got.Username != name {
+					t.Fatalf("user %d = %+v, want %s", i+1, got, name)
+				}
+			}
+		})
+	}
+}
+
+func TestSingleUseTwoFactorCodes(t *testing.T) {
+	tests := []struct {
+		name      string
+		fragment  string
+		affected  int64
+		use       func(UserRepository) (bool, error)
+		wantWhere string
+		wantOK    bool
+	}{
+		{
+			name:      "new TOTP step",
+			fragment:  `"two_factor_last_step"`,
+			affected:  1,
+			use:       func(r UserRepository) (bool, error) { return r.ClaimTwoFactorStep(context.Background(), 1, 42) },
+			wantWhere: "two_factor_last_step < $",
+			wantOK:    true,
+		},
+		{
+			name:      "TOTP step already claimed",
+			fragment:  `"two_factor_last_step"`,
+			use:       func(r UserRepository) (bool, error) { return r.ClaimTwoFactorStep(context.Background(), 1, 42) },
+			wantWhere: "two_factor_last_step < $",
+		},
+		{
+			name:      "unused recovery code",
+			fragment:  `"user_recovery_codes"`,
+			affected:  1,
+			use:       func(r UserRepository) (bool, error) { return r.UseRecoveryCode(context.Background(), 1, "hash") },
+			wantWhere: "used_at IS NULL",
+			wantOK:    true,
+		},
+		{
+			name:      "recovery code already used",
+			fragment:  `"user_recovery_codes"`,
+			use:       func(r UserRepository) (bool, error) { return r.UseRecoveryCode(context.Background(), 1, "hash") },
+			wantWhere: "used_at IS NULL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, db := dbtest.Open(t)
+			db.Affects(tt.fragment, tt.affected)
+
+			ok, err := tt.use(NewUserRepository(gormDB))
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if ok != tt.wantOK {
+				t.Fatalf("claimed = %v, want %v", ok, tt.wantOK)
+			}
+			update, found := db.Find(tt.fragment)
+			if !found || !strings.Contains(update.SQL, tt.wantWhere) {
+				t.Fatalf("statement = %q, want a conditional UPDATE with %q", update.SQL, tt.wantWhere)
+			}
+		})
+	}
+}
@@ -2,15 +2,20 @@ package user
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"net/url"
 	"time"
 
+	"github.com/llamacto/llama-gin-kit/config"
 	"github.com/llamacto/llama-gin-kit/pkg/email"
+	apperrors "github.com/llamacto/llama-gin-kit/pkg/errors"
 	"github.com/llamacto/llama-gin-kit/pkg/jwt"
 	"github.com/llamacto/llama-gin-kit/pkg/logger"
-	"github.com/llamacto/llama-gin-kit/pkg/utils"
-	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 // UserService User 服务接口
@@ -20,20 +25,53 @@ type UserService interface {
 	Delete(ctx context.Context, id uint) error
 	Get(ctx context.Context, id uint) (*User, error)
 	List(ctx context.Context, page, pageSize int) ([]*User, int64, error)
-	Register(req *UserRegisterRequest) (*User, error)
-	Login(req *UserLoginRequest) (*UserLoginResponse, error)
-	UpdateProfile(userID uint, req *UserUpdateRequest) (*User, error)
-	ChangePassword(userID uint, req *UserChangePasswordRequest) error
-	ResetPassword(req *UserPasswordResetRequest) error
-	GetProfile(userID uint) (*User, error)
-	DeleteAccount(userID uint) error
-	GetUserByID(id uint) (*UserInfo, error)
-	GetByID(id uint) (*User, error)
+	Register(ctx context.Context, req *UserRegisterRequest) (*User, error)
+	Login(ctx context.Context, req *UserLoginRequest, clientIP string) (*UserLoginResponse, error)
+	UpdateProfile(ctx context.Context, userID uint, req *UserUpdateRequest) (*User, error)
+	ChangePassword(ctx context.Context, userID uint, req *UserChangePasswordRequest) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ConfirmPasswordReset(ctx context.Context, token string, newPassword string) error
+	GetProfile(ctx context.Context, userID uint) (*User, error)
+	DeleteAccount(ctx context.Context, userID uint) error
+	GetUserByID(ctx context.Context, id uint) (*UserInfo, error)
+	GetUsersByIDs(ctx context.Context, ids []uint) (map[uint]UserInfo, error)
+	GetByID(ctx context.Context, id uint) (*User, error)
+	ApproveUser(ctx context.Context, id uint) (*User, error)
+	EnableTwoFactor(ctx context.Context, userID uint) (*TwoFactorEnrollment, error)
+	ConfirmTwoFactor(ctx context.Context, userID uint, code string) error
+	CompleteTwoFactorLogin(ctx context.Context, req *UserTwoFactorLoginRequest, clientIP string) (*UserLoginResponse, error)
+	RefreshLogin(ctx context.Context, refreshToken string) (*UserLoginResponse, error)
 }
 
+var (
+	// ErrAccountPending is returned by Login while the account awaits admin approval
+	ErrAccountPending = apperrors.Forbidden("account_pending", "账户待管理员审核")
+	// ErrAccountDisabled is returned by Login for disabled accounts
+	ErrAccountDisabled = apperrors.Forbidden("account_disabled", "账户已被禁用")
+	// ErrInvalidCredentials is returned by Login when the user does not exist or the password is wrong
+	ErrInvalidCredentials = apperrors.Validation("invalid_credentials", "用户名或密码错误")
+	// ErrInvalidRefreshToken is returned when the refresh token is invalid, expired or not a refresh token
+	ErrInvalidRefreshToken = apperrors.Validation("invalid_refresh_token", "登录已过期，请重新登录")
+	// ErrUserNotFound is returned when the target user does not exist
+	ErrUserNotFound = apperrors.NotFound("user_not_found", "用户不存在")
+	// ErrUserNotPending is returned when approving a user that is not pending approval
+	ErrUserNotPending = apperrors.Conflict("user_not_pending", "用户不在待审核状态")
+	// ErrEmailTaken is returned when registering with an email that is already in use
+	ErrEmailTaken = apperrors.AlreadyExists("email_taken", "邮箱已被注册")
+	// ErrWrongPassword is returned when changing the password with a wrong current password
+	ErrWrongPassword = apperrors.Validation("wrong_password", "原密码错误")
+	// ErrInvalidResetToken is returned when a password reset token is unknown, expired or already used
+	ErrInvalidResetToken = apperrors.Validation("invalid_reset_token", "重置链接无效或已过期")
+)
+
+// ApprovalHook 管理员审核通过用户后执行的回调。注册时不会执行：邮箱未经证实前，
+// 不能据此授予任何权限
+type ApprovalHook func(ctx context.Context, user *User)
+
 // UserServiceImpl User 服务实现
 type UserServiceImpl struct {
-	repo UserRepository
+	repo          UserRepository
+	approvalHooks []ApprovalHook
 }
 
 // NewUserService 创建 User 服务
@@ -41,6 +79,18 @@ func NewUserService(repo UserRepository) *UserServiceImpl {
 	return &UserServiceImpl{repo: repo}
 }
 
+// OnApproved 注册用户审核通过回调，需在处理请求前调用
+func (s *UserServiceImpl) OnApproved(hook ApprovalHook) {
+	s.approvalHooks = append(s.approvalHooks, hook)
+}
+
+// runApprovalHooks 依次执行用户审核通过回调
+func (s *UserServiceImpl) runApprovalHooks(ctx context.Context, user *User) {
+	for _, hook := range s.approvalHooks {
+		hook(ctx, user)
+	}
+}
+
 // Create 创建 User
 func (s *UserServiceImpl) Create(ctx context.Context, model *User) error {
 	return s.repo.Create(ctx, model)
@@ -67,20 +117,18 @@ func (s *UserServiceImpl) List(ctx context.Context, page, pageSize int) ([]*User
 }
 
 // Register 用户注册
-func (s *UserServiceImpl) Register(req *UserRegisterRequest) (*User, error) {
-	ctx := context.Background()
-
+func (s *UserServiceImpl) Register(ctx context.Context, req *UserRegisterRequest) (*User, error) {
 	// 检查邮箱是否已存在
 	exists, err := s.repo.ExistsByEmail(ctx, req.Email)
 	if err != nil {
 		return nil, err
 	}
 	if exists {
-		return nil, errors.New("邮箱已被注册")
+		return nil, ErrEmailTaken
 	}
 
 	// 加密密码
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := HashPassword(req.Password)
 	if err != nil {
 		return nil, fmt.Errorf("密码加密失败: %w", err)
 	}
@@ -88,10 +136,10 @@ func (s *UserServiceImpl) Register(req *UserRegisterRequest) (*User, error) {
 	user := &User{
 		Username: req.Username,
 		Email:    req.Email,
-		Password: string(hashedPassword),
+		Password: hashedPassword,
 		Nickname: req.Nickname,
 		Phone:    req.Phone,
-		Status:   1,
+		Status:   initialUserStatus(),
 	}
 
 	if err := s.repo.Create(ctx, user); err != nil {
@@ -106,69 +154,160 @@ func (s *UserServiceImpl) Register(req *UserRegisterRequest) (*User, error) {
 	return user, nil
 }
 
-// Login 用户登录
-func (s *UserServiceImpl) Login(req *UserLoginRequest) (*UserLoginResponse, error) {
-	ctx := context.Background()
-
+// Login 用户登录，成功后按 LastLoginInterval 节流记录最后登录时间和 IP。
+// 开启两步验证的账户在密码校验通过后只返回 pending token，由 CompleteTwoFactorLogin 完成登录。
+func (s *UserServiceImpl) Login(ctx context.Context, req *UserLoginRequest, clientIP string) (*UserLoginResponse, error) {
 	// Try to find user by username first
 	user, err := s.repo.GetByUsername(ctx, req.Username)
 	if err != nil {
 		// If not found by username, try email
 		user, err = s.repo.GetByEmail(ctx, req.Username)
 		if err != nil {
-			return nil, errors.New("用户名或密码错误")
+			return nil, ErrInvalidCredentials
 		}
 	}
 
-	if user.Status == 0 {
-		return nil, errors.New("账户已被禁用")
+	if !CheckPassword(user, req.Password) {
+		return nil, ErrInvalidCredentials
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		return nil, errors.New("用户名或密码错误")
+	if err := checkLoginStatus(user); err != nil {
+		return nil, err
 	}
 
-	// 生成 JWT token
+	if user.TwoFactorEnabled {
+		return pendingTwoFactorLogin(user)
+	}
+	return s.issueLogin(ctx, user, clientIP)
+}
+
+// checkLoginStatus 拒绝被禁用或待审核的账户登录
+func checkLoginStatus(user *User) error {
+	switch user.Status {
+	case UserStatusDisabled:
+		return ErrAccountDisabled
+	case UserStatusPending:
+		return ErrAccountPending
+	}
+	return nil
+}
+
+// RefreshLogin 用 refresh token 换取新的 access token 和 refresh token。
+// access token 和两步验证的 pending token 不能用于刷新，被禁用或待审核的账户也不能刷新。
+func (s *UserServiceImpl) RefreshLogin(ctx context.Context, refreshToken string) (*UserLoginResponse, error) {
+	claims, err := jwt.ParseTokenOfType(refreshToken, jwt.TokenTypeRefresh)
+	if err != nil {
+		if errors.Is(err, jwt.ErrNotInitialized) {
+			return nil, err
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.repo.Get(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("获取用户失败: %w", err)
+	}
+	if err := checkLoginStatus(user); err != nil {
+		return nil, err
+	}
+
+	return issueTokens(user)
+}
+
+// issueTokens 为用户生成 access token 和 refresh token
+func issueTokens(user *User) (*UserLoginResponse, error) {
 	token, err := jwt.GenerateToken(user.ID, user.Username)
 	if err != nil {
 		return nil, fmt.Errorf("生成 token 失败: %w", err)
 	}
+	refreshToken, err := jwt.GenerateRefreshToken(user.ID, user.Username)
+	if err != nil {
+		return nil, fmt.Errorf("生成 refresh token 失败: %w", err)
+	}
+	return &UserLoginResponse{Token: token, RefreshToken: refreshToken, User: user}, nil
+}
+
+// issueLogin 为已通过校验的用户生成 JWT token 并记录登录时间和 IP
+func (s *UserServiceImpl) issueLogin(ctx context.Context, user *User, clientIP string) (*UserLoginResponse, error) {
+	resp, err := issueTokens(user)
+	if err != nil {
+		return nil, err
+	}
 
 	now := time.Now()
-	user.LastLogin = &now
-	if err := s.repo.Update(ctx, user); err != nil {
+	updated, err := s.repo.UpdateLastLogin(ctx, user.ID, now, clientIP, now.Add(-lastLoginInterval()))
+	if err != nil {
 		logger.Error("更新用户最后登录时间失败:", err)
+	} else if updated {
+		user.LastLogin = &now
+		user.LastLoginIP = clientIP
 	}
 
-	return &UserLoginResponse{
-		Token: token,
-		User:  user,
-	}, nil
+	return resp, nil
 }
 
-// UpdateProfile 更新用户信息
-func (s *UserServiceImpl) UpdateProfile(userID uint, req *UserUpdateRequest) (*User, error) {
-	ctx := context.Background()
+// ApproveUser 审核通过待审核用户
+func (s *UserServiceImpl) ApproveUser(ctx context.Context, id uint) (*User, error) {
+	user, err := s.repo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("获取用户失败: %w", err)
+	}
+	if user.Status != UserStatusPending {
+		return nil, ErrUserNotPending
+	}
 
+	if err := s.repo.UpdateColumns(ctx, user.ID, map[string]interface{}{"status": UserStatusActive}); err != nil {
+		return nil, fmt.Errorf("审核用户失败: %w", err)
+	}
+	user.Status = UserStatusActive
+	s.runApprovalHooks(ctx, user)
+	return user, nil
+}
+
+// initialUserStatus 根据配置返回新注册用户的状态
+func initialUserStatus() int {
+	if config.GlobalConfig != nil && config.GlobalConfig.App.RequireApproval {
+		return UserStatusPending
+	}
+	return UserStatusActive
+}
+
+// UpdateProfile 更新用户信息
+func (s *UserServiceImpl) UpdateProfile(ctx context.Context, userID uint, req *UserUpdateRequest) (*User, error) {
 	user, err := s.repo.Get(ctx, userID)
 	if err != nil {
-		return nil, errors.New("用户不存在")
+		return nil, ErrUserNotFound
 	}
 
+	// 只写入请求中给出的字段
+	updates := map[string]interface{}{}
 	if req.Nickname != "" {
 		user.Nickname = req.Nickname
+		updates["nickname"] = req.Nickname
 	}
 	if req.Avatar != "" {
 		user.Avatar = req.Avatar
+		updates["avatar"] = req.Avatar
 	}
 	if req.Phone != "" {
 		user.Phone = req.Phone
+		updates["phone"] = req.Phone
 	}
 	if req.Bio != "" {
 		user.Bio = req.Bio
+		updates["bio"] = req.Bio
+	}
+	if len(updates) == 0 {
+		return user, nil
 	}
 
-	if err := s.repo.Update(ctx, user); err != nil {
+	if err := s.repo.UpdateColumns(ctx, user.ID, updates); err != nil {
 		return nil, fmt.Errorf("更新用户信息失败: %w", err)
 	}
 
@@ -176,74 +315,152 @@ func (s *UserServiceImpl) UpdateProfile(userID uint, req *UserUpdateRequest) (*U
 }
 
 // ChangePassword 修改密码
-func (s *UserServiceImpl) ChangePassword(userID uint, req *UserChangePasswordRequest) error {
-	ctx := context.Background()
-
+func (s *UserServiceImpl) ChangePassword(ctx context.Context, userID uint, req *UserChangePasswordRequest) error {
 	user, err := s.repo.Get(ctx, userID)
 	if err != nil {
-		return errors.New("用户不存在")
+		return ErrUserNotFound
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.OldPassword)); err != nil {
-		return errors.New("原密码错误")
+	if !CheckPassword(user, req.OldPassword) {
+		return ErrWrongPassword
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	hashedPassword, err := HashPassword(req.NewPassword)
 	if err != nil {
 		return fmt.Errorf("密码加密失败: %w", err)
 	}
 
-	user.Password = string(hashedPassword)
-	if err := s.repo.Update(ctx, user); err != nil {
+	if err := s.repo.UpdateColumns(ctx, user.ID, map[string]interface{}{"password": hashedPassword}); err != nil {
 		return fmt.Errorf("更新密码失败: %w", err)
 	}
 
+	// 密码已变更，之前发出的重置链接不再有效
+	if err := s.repo.InvalidatePasswordResetTokens(ctx, userID); err != nil {
+		logger.Error("使重置令牌失效失败:", err)
+	}
+
 	return nil
 }
 
-// ResetPassword 重置密码
-func (s *UserServiceImpl) ResetPassword(req *UserPasswordResetRequest) error {
-	ctx := context.Background()
+// RequestPasswordReset 为邮箱对应的账户生成一次性重置令牌并发送重置链接。
+// 邮箱不存在或账户不可用时同样返回 nil，避免泄露账户是否存在。
+func (s *UserServiceImpl) RequestPasswordReset(ctx context.Context, emailAddr string) error {
+	user, err := s.repo.GetByEmail(ctx, emailAddr)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("查询用户失败: %w", err)
+	}
+	if user.Status != UserStatusActive {
+		return nil
+	}
+
+	token, err := generateResetToken()
+	if err != nil {
+		return fmt.Errorf("生成重置令牌失败: %w", err)
+	}
 
-	user, err := s.repo.GetByEmail(ctx, req.Email)
+	ttl := passwordResetTTL()
+	if err := s.repo.CreatePasswordResetToken(ctx, &PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashResetToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}); err != nil {
+		return fmt.Errorf("保存重置令牌失败: %w", err)
+	}
+
+	resetURL, err := passwordResetLink(token)
 	if err != nil {
-		return errors.New("邮箱不存在")
+		return fmt.Errorf("生成重置链接失败: %w", err)
 	}
 
-	// 生成随机密码
-	newPassword := utils.GenerateRandomString(12)
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	// 异步发送，使响应时间与账户是否存在无关
+	go func(to string) {
+		if err := email.SendPasswordResetEmail(to, resetURL, ttl); err != nil {
+			logger.Error("发送重置密码邮件失败:", err)
+		}
+	}(user.Email)
+
+	return nil
+}
+
+// ConfirmPasswordReset 校验重置令牌并设置新密码，令牌使用后立即失效
+func (s *UserServiceImpl) ConfirmPasswordReset(ctx context.Context, token string, newPassword string) error {
+	hashedPassword, err := HashPassword(newPassword)
 	if err != nil {
 		return fmt.Errorf("密码加密失败: %w", err)
 	}
 
-	user.Password = string(hashedPassword)
-	if err := s.repo.Update(ctx, user); err != nil {
+	err = s.repo.ResetPasswordWithToken(ctx, hashResetToken(token), hashedPassword)
+	if errors.Is(err, ErrResetTokenInvalid) {
+		return ErrInvalidResetToken
+	}
+	if err != nil {
 		return fmt.Errorf("重置密码失败: %w", err)
 	}
+	return nil
+}
 
-	// 发送重置密码邮件
-	if err := email.SendPasswordResetEmail(user.Email, newPassword); err != nil {
-		logger.Error("发送重置密码邮件失败:", err)
-		return errors.New("发送重置密码邮件失败")
+// generateResetToken 生成随机的十六进制重置令牌
+func generateResetToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
 	}
+	return hex.EncodeToString(b), nil
+}
 
-	return nil
+// hashResetToken 返回令牌的 SHA-256 摘要，数据库只保存摘要
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// lastLoginInterval 返回最后登录时间的最小更新间隔
+func lastLoginInterval() time.Duration {
+	if config.GlobalConfig != nil {
+		return config.GlobalConfig.App.LastLoginInterval
+	}
+	return 5 * time.Minute
+}
+
+// passwordResetTTL 返回重置令牌的有效期
+func passwordResetTTL() time.Duration {
+	if config.GlobalConfig != nil && config.GlobalConfig.App.PasswordResetTTL > 0 {
+		return config.GlobalConfig.App.PasswordResetTTL
+	}
+	return 30 * time.Minute
+}
+
+// passwordResetLink 将令牌附加到配置的重置页面地址
+func passwordResetLink(token string) (string, error) {
+	base := "http://localhost:6066/reset-password"
+	if config.GlobalConfig != nil && config.GlobalConfig.App.PasswordResetURL != "" {
+		base = config.GlobalConfig.App.PasswordResetURL
+	}
+
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
 }
 
 // GetProfile 获取用户信息
-func (s *UserServiceImpl) GetProfile(userID uint) (*User, error) {
-	ctx := context.Background()
+func (s *UserServiceImpl) GetProfile(ctx context.Context, userID uint) (*User, error) {
 	user, err := s.repo.Get(ctx, userID)
 	if err != nil {
-		return nil, errors.New("用户不存在")
+		return nil, ErrUserNotFound
 	}
 	return user, nil
 }
 
 // DeleteAccount 删除账户
-func (s *UserServiceImpl) DeleteAccount(userID uint) error {
-	ctx := context.Background()
+func (s *UserServiceImpl) DeleteAccount(ctx context.Context, userID uint) error {
 	if err := s.repo.Delete(ctx, userID); err != nil {
 		return fmt.Errorf("删除账户失败: %w", err)
 	}
@@ -251,12 +468,16 @@ func (s *UserServiceImpl) DeleteAccount(userID uint) error {
 }
 
 // GetUserByID retrieves user information by ID.
-func (s *UserServiceImpl) GetUserByID(id uint) (*UserInfo, error) {
-	return s.repo.FindByID(id)
+func (s *UserServiceImpl) GetUserByID(ctx context.Context, id uint) (*UserInfo, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+// GetUsersByIDs retrieves user information for several users in one query, keyed by ID.
+func (s *UserServiceImpl) GetUsersByIDs(ctx context.Context, ids []uint) (map[uint]UserInfo, error) {
+	return s.repo.GetUsersByIDs(ctx, ids)
 }
 
 // GetByID retrieves a user by their ID.
-func (s *UserServiceImpl) GetByID(id uint) (*User, error) {
-	ctx := context.Background()
+func (s *UserServiceImpl) GetByID(ctx context.Context, id uint) (*User, error) {
 	return s.repo.Get(ctx, id)
 }
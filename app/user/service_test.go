@@ -0,0 +1,308 @@
+package user
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/config"
+	"github.com/llamacto/llama-gin-kit/pkg/dbtest"
+	"github.com/llamacto/llama-gin-kit/pkg/jwt"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// useLoginConfig installs a config with the given last-login interval and initializes jwt so
+// logins can issue tokens, restoring the previous config when the test ends
+func useLoginConfig(t *testing.T, interval time.Duration) {
+	t.Helper()
+	saved := config.GlobalConfig
+	t.Cleanup(func() { config.GlobalConfig = saved })
+
+	cfg := &config.Config{
+		App: config.AppConfig{BcryptCost: bcrypt.MinCost, LastLoginInterval: interval},
+		JWT: config.JWTConfig{Secret: strings.Repeat("s", jwt.MinSecretLength), ExpireDuration: time.Hour},
+	}
+	config.GlobalConfig = cfg
+	if !jwt.Initialized() {
+		if err := jwt.Init(cfg); err != nil {
+			t.Fatalf("jwt.Init() error = %v", err)
+		}
+	}
+}
+
+func TestLoginRecordsLastLogin(t *testing.T) {
+	const interval = 10 * time.Minute
+	useLoginConfig(t, interval)
+
+	hashed, err := HashPassword("secret")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+	previous := time.Date(2025, 7, 1, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		password   string
+		stale      bool // Whether the stored last_login is older than the interval
+		wantErr    error
+		wantUpdate bool
+		wantRecent bool
+	}{
+		{name: "successful login", password: "secret", stale: true, wantUpdate: true, wantRecent: true},
+		{name: "wrong password", password: "wrong", stale: true, wantErr: ErrInvalidCredentials},
+		{name: "login within the interval", password: "secret", wantUpdate: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, db := dbtest.Open(t)
+			db.Returns(`FROM "users" WHERE username`,
+				[]string{"id", "username", "password", "status", "last_login", "last_login_ip"},
+				[]driver.Value{int64(1), "ada", hashed, int64(UserStatusActive), previous, "10.0.0.1"})
+			updated := int64(0)
+			if tt.stale {
+				updated = 1
+			}
+			db.Affects(`UPDATE "users" SET "last_login"`, updated)
+
+			before := time.Now()
+			resp, err := NewUserService(NewUserRepository(gormDB)).Login(context.Background(),
+				&UserLoginRequest{Username: "ada", Password: tt.password}, "192.0.2.7")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Login() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil {
+				if _, err := jwt.ParseTokenOfType(resp.RefreshToken, jwt.TokenTypeRefresh); err != nil {
+					t.Fatalf("Login() refresh_token is not a refresh token: %v", err)
+				}
+			}
+
+			stmt, sent := db.Find(`UPDATE "users" SET "last_login"`)
+			if sent != tt.wantUpdate {
+				t.Fatalf("last_login update sent = %v, want %v", sent, tt.wantUpdate)
+			}
+			if !sent {
+				return
+			}
+			// Args: last_login, last_login_ip, id, staleBefore
+			staleBefore, _ := stmt.Args[3].(time.Time)
+			if lag := before.Add(-interval).Sub(staleBefore); lag > time.Second || lag < -time.Second {
+				t.Fatalf("update only replaces last_login older than %v, want about %v", staleBefore, before.Add(-interval))
+			}
+
+			got := resp.User
+			if tt.wantRecent {
+				if got.LastLogin == nil || got.LastLogin.Before(before) || got.LastLoginIP != "192.0.2.7" {
+					t.Fatalf("LastLogin = %v from %q, want this login from 192.0.2.7", got.LastLogin, got.LastLoginIP)
+				}
+				return
+			}
+			if got.LastLogin == nil || !got.LastLogin.Equal(previous) || got.LastLoginIP != "10.0.0.1" {
+				t.Fatalf("LastLogin = %v from %q, want the stored %v from 10.0.0.1", got.LastLogin, got.LastLoginIP, previous)
+			}
+		})
+	}
+}
+
+func TestRefreshLogin(t *testing.T) {
+	useLoginConfig(t, time.Minute)
+
+	token := func(tokenType string) string {
+		t.Helper()
+		tok, err := jwt.GenerateTokenWithOptions(1, "ada", jwt.TokenOptions{TokenType: tokenType})
+		if err != nil {
+			t.Fatalf("GenerateTokenWithOptions() error = %v", err)
+		}
+		return tok
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		missing bool // No user with the token's ID
+		status  int
+		wantErr error
+	}{
+		{name: "refresh token", token: token(jwt.TokenTypeRefresh), status: UserStatusActive},
+		{name: "access token", token: token(jwt.TokenTypeAccess), status: UserStatusActive, wantErr: ErrInvalidRefreshToken},
+		{name: "two-factor pending token", token: token(jwt.TokenTypeTwoFactor), status: UserStatusActive, wantErr: ErrInvalidRefreshToken},
+		{name: "malformed token", token: "not-a-token", status: UserStatusActive, wantErr: ErrInvalidRefreshToken},
+		{name: "deleted user", token: token(jwt.TokenTypeRefresh), missing: true, wantErr: ErrInvalidRefreshToken},
+		{name: "disabled user", token: token(jwt.TokenTypeRefresh), status: UserStatusDisabled, wantErr: ErrAccountDisabled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, db := dbtest.Open(t)
+			if !tt.missing {
+				db.Returns(`FROM "users" WHERE "users"."id"`,
+					[]string{"id", "username", "status"},
+					[]driver.Value{int64(1), "ada", int64(tt.status)})
+			}
+
+			resp, err := NewUserService(NewUserRepository(gormDB)).RefreshLogin(context.Background(), tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RefreshLogin() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if _, err := jwt.ParseTokenOfType(resp.Token, jwt.TokenTypeAccess); err != nil {
+				t.Fatalf("token is not an access token: %v", err)
+			}
+			if _, err := jwt.ParseTokenOfType(resp.RefreshToken, jwt.TokenTypeRefresh); err != nil {
+				t.Fatalf("refresh_token is not a refresh token: %v", err)
+			}
+		})
+	}
+}
+
+func TestAccountApproval(t *testing.T) {
+	tests := []struct {
+		name            string
+		requireApproval bool
+		wantStatus      int
+		wantLoginErr    error
+		wantApproveErr  error
+	}{
+		{name: "open signup", wantStatus: UserStatusActive, wantApproveErr: ErrUserNotPending},
+		{name: "approval required", requireApproval: true, wantStatus: UserStatusPending, wantLoginErr: ErrAccountPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useLoginConfig(t, time.Minute)
+			config.GlobalConfig.App.RequireApproval = tt.requireApproval
+			hashed, err := HashPassword("secret")
+			if err != nil {
+				t.Fatalf("HashPassword() error = %v", err)
+			}
+
+			// Register
+			gormDB, db := dbtest.Open(t)
+			db.Returns(`INSERT INTO "users"`, []string{"id"}, []driver.Value{int64(1)})
+			svc := NewUserService(NewUserRepository(gormDB))
+			approved := 0
+			svc.OnApproved(func(ctx context.Context, u *User) { approved++ })
+
+			registered, err := svc.Register(context.Background(), &UserRegisterRequest{Username: "ada", Email: "ada@example.com", Password: "secret"})
+			if err != nil {
+				t.Fatalf("Register() error = %v", err)
+			}
+			if registered.Status != tt.wantStatus {
+				t.Fatalf("registered status = %d, want %d", registered.Status, tt.wantStatus)
+			}
+			if approved != 0 {
+				t.Fatalf("approval hooks ran %d times at signup, want none", approved)
+			}
+
+			// Log in before approval
+			gormDB, db = dbtest.Open(t)
+			db.Returns(`FROM "users" WHERE username`, []string{"id", "username", "password", "status"},
+				[]driver.Value{int64(1), "ada", hashed, int64(registered.Status)})
+			_, err = NewUserService(NewUserRepository(gormDB)).Login(context.Background(), &UserLoginRequest{Username: "ada", Password: "secret"}, "192.0.2.7")
+			if !errors.Is(err, tt.wantLoginErr) {
+				t.Fatalf("Login() error = %v, want %v", err, tt.wantLoginErr)
+			}
+
+			// Approve
+			gormDB, db = dbtest.Open(t)
+			db.Returns(`FROM "users" WHERE "users"."id"`, []string{"id", "username", "email", "password", "status"},
+				[]driver.Value{int64(1), "ada", "ada@example.com", hashed, int64(registered.Status)})
+			svc = NewUserService(NewUserRepository(gormDB))
+			svc.OnApproved(func(ctx context.Context, u *User) { approved++ })
+
+			user, err := svc.ApproveUser(context.Background(), 1)
+			if !errors.Is(err, tt.wantApproveErr) {
+				t.Fatalf("ApproveUser() error = %v, want %v", err, tt.wantApproveErr)
+			}
+			if err != nil {
+				return
+			}
+			if user.Status != UserStatusActive || approved != 1 {
+				t.Fatalf("approved status = %d with %d approval hook runs, want active and one run", user.Status, approved)
+			}
+			if _, ok := db.Find(`UPDATE "users"`); !ok {
+				t.Fatal("approval was not saved")
+			}
+		})
+	}
+}
+
+func TestUpdatesWriteOnlyChangedColumns(t *testing.T) {
+	useLoginConfig(t, time.Minute)
+	hashed, err := HashPassword("secret")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		status      int
+		run         func(*UserServiceImpl) error
+		wantColumns []string
+	}{
+		{
+			name:   "approve",
+			status: UserStatusPending,
+			run: func(s *UserServiceImpl) error {
+				_, err := s.ApproveUser(context.Background(), 1)
+				return err
+			},
+			wantColumns: []string{`"status"`},
+		},
+		{
+			name:   "update profile",
+			status: UserStatusActive,
+			run: func(s *UserServiceImpl) error {
+				_, err := s.UpdateProfile(context.Background(), 1, &UserUpdateRequest{Nickname: "Ada", Bio: "Analyst"})
+				return err
+			},
+			wantColumns: []string{`"nickname"`, `"bio"`},
+		},
+		{
+			name:   "change password",
+			status: UserStatusActive,
+			run: func(s *UserServiceImpl) error {
+				return s.ChangePassword(context.Background(), 1, &UserChangePasswordRequest{OldPassword: "secret", NewPassword: "new-secret"})
+			},
+			wantColumns: []string{`"password"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, db := dbtest.Open(t)
+			db.Returns(`FROM "users" WHERE "users"."id"`,
+				[]string{"id", "username", "email", "password", "status", "two_factor_enabled", "two_factor_secret", "two_factor_last_step"},
+				[]driver.Value{int64(1), "ada", "ada@example.com", hashed, int64(tt.status), true, "sealed", int64(100)})
+
+			if err := tt.run(NewUserService(NewUserRepository(gormDB))); err != nil {
+				t.Fatalf("error = %v", err)
+			}
+
+			update, ok := db.Find(`UPDATE "users"`)
+			if !ok {
+				t.Fatal("no UPDATE sent")
+			}
+			set := update.SQL[:strings.Index(update.SQL, " WHERE ")]
+			for _, column := range tt.wantColumns {
+				if !strings.Contains(set, column) {
+					t.Fatalf("UPDATE %q does not set %s", set, column)
+				}
+			}
+			for _, column := range []string{"two_factor", `"username"`, `"email"`} {
+				if strings.Contains(set, column) {
+					t.Fatalf("UPDATE %q writes back unchanged %s", set, column)
+				}
+			}
+			if want := len(tt.wantColumns) + 1; strings.Count(set, "=") != want {
+				t.Fatalf("UPDATE %q sets %d columns, want %d including updated_at", set, strings.Count(set, "="), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/config"
+	apperrors "github.com/llamacto/llama-gin-kit/pkg/errors"
+	"github.com/llamacto/llama-gin-kit/pkg/jwt"
+	"github.com/llamacto/llama-gin-kit/pkg/secretbox"
+	"github.com/llamacto/llama-gin-kit/pkg/totp"
+	"gorm.io/gorm"
+)
+
+const (
+	// twoFactorPendingTTL 密码校验通过后提交验证码的时限
+	twoFactorPendingTTL = 5 * time.Minute
+	// recoveryCodeCount 每次开启两步验证生成的恢复码数量
+	recoveryCodeCount = 10
+)
+
+var (
+	// ErrTwoFactorEnabled is returned when enrolling an account that already has two-factor authentication on
+	ErrTwoFactorEnabled = apperrors.Conflict("two_factor_enabled", "已开启两步验证")
+	// ErrTwoFactorNotEnrolled is returned when confirming before EnableTwoFactor
+	ErrTwoFactorNotEnrolled = apperrors.Conflict("two_factor_not_enrolled", "请先开启两步验证")
+	// ErrInvalidTwoFactorCode is returned for a wrong, expired or already used TOTP or recovery code
+	ErrInvalidTwoFactorCode = apperrors.Validation("invalid_two_factor_code", "验证码无效")
+	// ErrInvalidPendingToken is returned when the pending login token is invalid or expired
+	ErrInvalidPendingToken = apperrors.Validation("invalid_pending_token", "登录已过期，请重新登录")
+	// ErrTwoFactorUnavailable is returned when APP_SECRET is not set, so secrets cannot be encrypted
+	ErrTwoFactorUnavailable = errors.New("two-factor authentication requires APP_SECRET")
+)
+
+// EnableTwoFactor 为用户生成新的 TOTP 密钥和恢复码。密钥以 APP_SECRET 加密保存，
+// 在 ConfirmTwoFactor 校验验证码之前不生效；重复调用会替换尚未确认的密钥和恢复码。
+func (s *UserServiceImpl) EnableTwoFactor(ctx context.Context, userID uint) (*TwoFactorEnrollment, error) {
+	box, err := twoFactorBox()
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("获取用户失败: %w", err)
+	}
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, fmt.Errorf("生成密钥失败: %w", err)
+	}
+	sealed, err := box.Seal(secret)
+	if err != nil {
+		return nil, fmt.Errorf("加密密钥失败: %w", err)
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, fmt.Errorf("生成恢复码失败: %w", err)
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	if err := s.repo.StartTwoFactorEnrollment(ctx, userID, sealed, hashes); err != nil {
+		return nil, fmt.Errorf("保存两步验证信息失败: %w", err)
+	}
+
+	return &TwoFactorEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(twoFactorIssuer(), user.Email, secret),
+		RecoveryCodes:   codes,
+	}, nil
+}
+
+// ConfirmTwoFactor 校验验证器生成的验证码，通过后开启两步验证
+func (s *UserServiceImpl) ConfirmTwoFactor(ctx context.Context, userID uint, code string) error {
+	user, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("获取用户失败: %w", err)
+	}
+	if user.TwoFactorEnabled {
+		return ErrTwoFactorEnabled
+	}
+	if user.TwoFactorSecret == "" {
+		return ErrTwoFactorNotEnrolled
+	}
+
+	if err := s.verifyTOTP(ctx, user, code); err != nil {
+		return err
+	}
+	if err := s.repo.ActivateTwoFactor(ctx, userID); err != nil {
+		return fmt.Errorf("开启两步验证失败: %w", err)
+	}
+	return nil
+}
+
+// CompleteTwoFactorLogin 用密码校验后得到的 pending token 和验证码换取登录 token。
+// 验证码可以是验证器中的 TOTP 验证码，也可以是一个未使用的恢复码，恢复码使用后失效。
+func (s *UserServiceImpl) CompleteTwoFactorLogin(ctx context.Context, req *UserTwoFactorLoginRequest, clientIP string) (*UserLoginResponse, error) {
+	claims, err := jwt.ParseTokenOfType(req.PendingToken, jwt.TokenTypeTwoFactor)
+	if err != nil {
+		if errors.Is(err, jwt.ErrNotInitialized) {
+			return nil, err
+		}
+		return nil, ErrInvalidPendingToken
+	}
+
+	user, err := s.repo.Get(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidPendingToken
+		}
+		return nil, fmt.Errorf("获取用户失败: %w", err)
+	}
+	if err := checkLoginStatus(user); err != nil {
+		return nil, err
+	}
+	if !user.TwoFactorEnabled {
+		return nil, ErrInvalidPendingToken
+	}
+
+	code := strings.TrimSpace(req.Code)
+	if isTOTPCode(code) {
+		err = s.verifyTOTP(ctx, user, code)
+	} else {
+		err = s.useRecoveryCode(ctx, user.ID, code)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issueLogin(ctx, user, clientIP)
+}
+
+// pendingTwoFactorLogin 返回密码校验已通过、等待验证码的登录响应
+func pendingTwoFactorLogin(user *User) (*UserLoginResponse, error) {
+	token, err := jwt.GenerateTokenWithOptions(user.ID, user.Username, jwt.TokenOptions{
+		TokenType: jwt.TokenTypeTwoFactor,
+		TTL:       twoFactorPendingTTL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("生成 token 失败: %w", err)
+	}
+	return &UserLoginResponse{TwoFactorRequired: true, PendingToken: token}, nil
+}
+
+// verifyTOTP 校验用户的 TOTP 验证码，同一时间步的验证码只能使用一次
+func (s *UserServiceImpl) verifyTOTP(ctx context.Context, user *User, code string) error {
+	box, err := twoFactorBox()
+	if err != nil {
+		return err
+	}
+	secret, err := box.Open(user.TwoFactorSecret)
+	if err != nil {
+		return fmt.Errorf("解密两步验证密钥失败: %w", err)
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+	claimed, err := s.repo.ClaimTwoFactorStep(ctx, user.ID, step)
+	if err != nil {
+		return fmt.Errorf("记录验证码失败: %w", err)
+	}
+	if !claimed {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// useRecoveryCode 消耗用户的一个恢复码
+func (s *UserServiceImpl) useRecoveryCode(ctx context.Context, userID uint, code string) error {
+	used, err := s.repo.UseRecoveryCode(ctx, userID, hashRecoveryCode(code))
+	if err != nil {
+		return fmt.Errorf("使用恢复码失败: %w", err)
+	}
+	if !used {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// twoFactorBox 返回加密两步验证密钥的 secretbox，未配置 APP_SECRET 时不可用
+func twoFactorBox() (*secretbox.Box, error) {
+	if config.GlobalConfig == nil || config.GlobalConfig.App.Secret == "" {
+		return nil, ErrTwoFactorUnavailable
+	}
+	return secretbox.New(config.GlobalConfig.App.Secret)
+}
+
+// twoFactorIssuer 返回验证器中显示的服务名称
+func twoFactorIssuer() string {
+	if config.GlobalConfig != nil && config.GlobalConfig.App.Name != "" {
+		return config.GlobalConfig.App.Name
+	}
+	return "Llama-Gin-Kit"
+}
+
+// isTOTPCode 判断输入是否为 6 位数字验证码，否则按恢复码处理
+func isTOTPCode(code string) bool {
+	if len(code) != totp.Digits {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// generateRecoveryCode 生成形如 xxxxx-xxxxx 的随机恢复码
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// hashRecoveryCode 返回规范化后恢复码的 SHA-256 摘要，忽略大小写、空格和连字符
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,143 @@
+package user
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/config"
+	"github.com/llamacto/llama-gin-kit/pkg/dbtest"
+	"github.com/llamacto/llama-gin-kit/pkg/jwt"
+	"github.com/llamacto/llama-gin-kit/pkg/secretbox"
+	"github.com/llamacto/llama-gin-kit/pkg/totp"
+)
+
+// twoFactorSecret is the TOTP secret of the enrolled test user
+const twoFactorSecret = "JBSWY3DPEHPK3PXP"
+
+// useTwoFactorConfig installs a login config with APP_SECRET set and returns the user's
+// secret sealed under it
+func useTwoFactorConfig(t *testing.T) string {
+	t.Helper()
+	useLoginConfig(t, time.Minute)
+	config.GlobalConfig.App.Secret = "app-secret"
+
+	box, err := secretbox.New(config.GlobalConfig.App.Secret)
+	if err != nil {
+		t.Fatalf("secretbox.New() error = %v", err)
+	}
+	sealed, err := box.Seal(twoFactorSecret)
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	return sealed
+}
+
+func TestLoginWithTwoFactorReturnsPendingToken(t *testing.T) {
+	sealed := useTwoFactorConfig(t)
+	hashed, err := HashPassword("secret")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+
+	gormDB, db := dbtest.Open(t)
+	db.Returns(`FROM "users" WHERE username`, []string{"id", "username", "password", "status", "two_factor_enabled", "two_factor_secret"},
+		[]driver.Value{int64(1), "ada", hashed, int64(UserStatusActive), true, sealed})
+
+	resp, err := NewUserService(NewUserRepository(gormDB)).Login(context.Background(), &UserLoginRequest{Username: "ada", Password: "secret"}, "192.0.2.7")
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if !resp.TwoFactorRequired || resp.Token != "" || resp.RefreshToken != "" {
+		t.Fatalf("Login() = %+v, want only a pending token", resp)
+	}
+	if _, err := jwt.ParseTokenOfType(resp.PendingToken, jwt.TokenTypeTwoFactor); err != nil {
+		t.Fatalf("pending_token is not a two-factor token: %v", err)
+	}
+	if _, err := jwt.ParseTokenOfType(resp.PendingToken, jwt.TokenTypeAccess); !errors.Is(err, jwt.ErrTokenTypeMismatch) {
+		t.Fatalf("pending_token as access token error = %v, want %v", err, jwt.ErrTokenTypeMismatch)
+	}
+	if _, ok := db.Find(`UPDATE "users"`); ok {
+		t.Fatal("login was recorded before the second factor")
+	}
+}
+
+func TestCompleteTwoFactorLogin(t *testing.T) {
+	sealed := useTwoFactorConfig(t)
+	pending, err := jwt.GenerateTokenWithOptions(1, "ada", jwt.TokenOptions{TokenType: jwt.TokenTypeTwoFactor, TTL: time.Minute})
+	if err != nil {
+		t.Fatalf("GenerateTokenWithOptions() error = %v", err)
+	}
+	access, err := jwt.GenerateToken(1, "ada")
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	step := totp.Step(time.Now())
+	code, err := totp.Code(twoFactorSecret, step)
+	if err != nil {
+		t.Fatalf("totp.Code() error = %v", err)
+	}
+	// wrong is a six digit code no step in the accepted window produces
+	wrong := "000000"
+	for _, candidate := range []string{"000000", "111111", "222222", "333333"} {
+		if _, ok := totp.Validate(twoFactorSecret, candidate, time.Now()); !ok {
+			wrong = candidate
+			break
+		}
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		code       string
+		enabled    bool
+		stepTaken  bool // Another request already claimed the code's step
+		codeUsed   bool // The recovery code was already used
+		wantErr    error
+		wantUpdate string
+	}{
+		{name: "authenticator code", token: pending, code: code, enabled: true, wantUpdate: `"two_factor_last_step"`},
+		{name: "reused authenticator code", token: pending, code: code, enabled: true, stepTaken: true, wantErr: ErrInvalidTwoFactorCode},
+		{name: "wrong authenticator code", token: pending, code: wrong, enabled: true, wantErr: ErrInvalidTwoFactorCode},
+		{name: "recovery code", token: pending, code: "ABCDE-FGHIJ", enabled: true, wantUpdate: `"user_recovery_codes"`},
+		{name: "used recovery code", token: pending, code: "abcde-fghij", enabled: true, codeUsed: true, wantErr: ErrInvalidTwoFactorCode},
+		{name: "access token as pending token", token: access, code: code, enabled: true, wantErr: ErrInvalidPendingToken},
+		{name: "two-factor turned off", token: pending, code: code, wantErr: ErrInvalidPendingToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, db := dbtest.Open(t)
+			db.Returns(`FROM "users" WHERE "users"."id"`, []string{"id", "username", "status", "two_factor_enabled", "two_factor_secret", "two_factor_last_step"},
+				[]driver.Value{int64(1), "ada", int64(UserStatusActive), tt.enabled, sealed, step - 5})
+			if tt.stepTaken {
+				db.Affects(`"two_factor_last_step"`, 0)
+			}
+			if tt.codeUsed {
+				db.Affects(`"user_recovery_codes"`, 0)
+			}
+
+			resp, err := NewUserService(NewUserRepository(gormDB)).CompleteTwoFactorLogin(context.Background(),
+				&UserTwoFactorLoginRequest{PendingToken: tt.token, Code: tt.code}, "192.0.2.7")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CompleteTwoFactorLogin() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if _, err := jwt.ParseTokenOfType(resp.Token, jwt.TokenTypeAccess); err != nil {
+				t.Fatalf("token is not an access token: %v", err)
+			}
+			update, ok := db.Find(tt.wantUpdate)
+			if !ok {
+				t.Fatalf("no statement containing %s was sent", tt.wantUpdate)
+			}
+			if tt.wantUpdate == `"user_recovery_codes"` && update.Args[2] != hashRecoveryCode("abcdefghij") {
+				t.Fatalf("recovery code args = %v, want the normalized code's hash", update.Args)
+			}
+		})
+	}
+}
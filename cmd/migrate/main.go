@@ -16,14 +16,8 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	// Initialize database connection
-	db, err := database.InitDB(cfg.Database)
-	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
-	}
-
-	// Run migrations using the new migration system
-	if err := database.RunMigrations(db); err != nil {
+	// Initialize database connection; InitDB applies every pending migration
+	if _, err := database.InitDB(cfg.Database); err != nil {
 		log.Fatalf("Migration failed: %v", err)
 	}
 
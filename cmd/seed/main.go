@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/llamacto/llama-gin-kit/app/authorization"
+	"github.com/llamacto/llama-gin-kit/app/organization"
+	"github.com/llamacto/llama-gin-kit/app/user"
+	"github.com/llamacto/llama-gin-kit/config"
+	"github.com/llamacto/llama-gin-kit/pkg/database"
+	"gorm.io/gorm"
+)
+
+// adminUsername is the username of the seeded admin account
+const adminUsername = "admin"
+
+// demoOrganizations are the sample organizations created by --demo-data, owned by the admin
+var demoOrganizations = []organization.Organization{
+	{Name: "acme", DisplayName: "Acme Inc.", Description: "Sample organization created by cmd/seed"},
+	{Name: "globex", DisplayName: "Globex Corporation", Description: "Sample organization created by cmd/seed"},
+}
+
+// main seeds the database with the data selected by flags. Every step is idempotent:
+// records that already exist are left untouched, so it is safe to run repeatedly. It is
+// never run by the server or by cmd/migrate.
+func main() {
+	roles := flag.Bool("roles", false, "Create the system roles and permissions and grant every permission to super_admin")
+	admin := flag.Bool("admin", false, "Create the admin user (password from APP_ADMIN_PASSWORD; random when empty in development, required otherwise) with the super_admin role")
+	adminEmail := flag.String("admin-email", "admin@example.com", "Email address of the admin user created by -admin")
+	demoData := flag.Bool("demo-data", false, "Create sample organizations owned by the admin user")
+	flag.Parse()
+
+	if !*roles && !*admin && !*demoData {
+		fmt.Println("Nothing to seed; choose at least one of -roles, -admin, -demo-data")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// InitDB applies pending schema migrations, so the tables exist before seeding
+	db, err := database.InitDB(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	ctx := context.Background()
+	authService := authorization.NewService(authorization.NewRepository(db))
+	userRepo := user.NewUserRepository(db)
+
+	if *roles {
+		if err := seedRoles(ctx, authService); err != nil {
+			log.Fatalf("Failed to seed roles: %v", err)
+		}
+	}
+	if *admin {
+		if err := seedAdmin(ctx, userRepo, authService, *adminEmail); err != nil {
+			log.Fatalf("Failed to seed admin user: %v", err)
+		}
+	}
+	if *demoData {
+		orgService := organization.NewService(organization.NewRepository(db), user.NewUserService(userRepo), authService, db)
+		if err := seedDemoData(ctx, db, userRepo, orgService); err != nil {
+			log.Fatalf("Failed to seed demo data: %v", err)
+		}
+	}
+
+	log.Println("Database seeding completed successfully")
+}
+
+// seedRoles creates the built-in roles and permissions and syncs super_admin's grants
+func seedRoles(ctx context.Context, authService authorization.Service) error {
+	result, err := authService.InitializeSystem(ctx, 0)
+	if err != nil {
+		return err
+	}
+	log.Printf("Roles: %d created, %d already present", len(result.RolesCreated), len(result.RolesExisting))
+	log.Printf("Permissions: %d created, %d already present", len(result.PermissionsCreated), len(result.PermissionsExisting))
+	log.Printf("Permissions granted to super_admin: %d", result.PermissionsGranted)
+	return nil
+}
+
+// seedAdmin creates the admin user when it does not exist and gives it the super_admin
+// role when that role has been seeded. An existing admin's password is never changed.
+func seedAdmin(ctx context.Context, userRepo user.UserRepository, authService authorization.Service, email string) error {
+	admin, err := userRepo.GetByUsername(ctx, adminUsername)
+	switch {
+	case err == nil:
+		log.Printf("Admin user %q already exists", adminUsername)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		password := config.GlobalConfig.App.AdminPassword
+		if password == "" {
+			if !config.GlobalConfig.Server.IsDevelopment() {
+				return errors.New("APP_ADMIN_PASSWORD is required to seed the admin user outside development")
+			}
+			password, err = generatePassword()
+			if err != nil {
+				return fmt.Errorf("failed to generate admin password: %w", err)
+			}
+			// Shown once on stderr and never logged; set APP_ADMIN_PASSWORD to choose one
+			fmt.Fprintf(os.Stderr, "Generated admin password: %s\n", password)
+		}
+
+		hashedPassword, err := user.HashPassword(password)
+		if err != nil {
+			return err
+		}
+
+		admin = &user.User{
+			Username: adminUsername,
+			Email:    email,
+			Password: hashedPassword,
+			Nickname: "Admin User",
+			Status:   1, // 1: active, 0: disabled
+		}
+		if err := userRepo.Create(ctx, admin); err != nil {
+			return fmt.Errorf("failed to create admin user: %w", err)
+		}
+		log.Printf("Created admin user %q <%s>", adminUsername, email)
+	default:
+		return fmt.Errorf("failed to look up admin user: %w", err)
+	}
+
+	return grantSuperAdmin(ctx, authService, admin.ID)
+}
+
+// generatePassword returns a random password with 128 bits of entropy from crypto/rand
+func generatePassword() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// grantSuperAdmin assigns the super_admin role to userID unless it is already held or
+// has not been seeded yet
+func grantSuperAdmin(ctx context.Context, authService authorization.Service, userID uint) error {
+	hasRole, err := authService.HasRole(ctx, userID, "super_admin")
+	if err != nil {
+		return err
+	}
+	if hasRole {
+		return nil
+	}
+
+	systemRoles, err := authService.ListSystemRoles(ctx)
+	if err != nil {
+		return err
+	}
+	for _, role := range systemRoles {
+		if role.Name == "super_admin" {
+			if err := authService.AssignRoleToUser(ctx, userID, role.ID, 0, nil); err != nil {
+				return fmt.Errorf("failed to assign super_admin: %w", err)
+			}
+			log.Printf("Assigned super_admin to admin user")
+			return nil
+		}
+	}
+
+	log.Printf("Role super_admin not found; run with -roles to create it")
+	return nil
+}
+
+// seedDemoData creates the sample organizations, each with its default team, skipping
+// any whose name is already taken
+func seedDemoData(ctx context.Context, db *gorm.DB, userRepo user.UserRepository, orgService organization.Service) error {
+	admin, err := userRepo.GetByUsername(ctx, adminUsername)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("admin user %q not found; run with -admin first", adminUsername)
+		}
+		return fmt.Errorf("failed to look up admin user: %w", err)
+	}
+
+	for _, demo := range demoOrganizations {
+		var count int64
+		if err := db.WithContext(ctx).Model(&organization.Organization{}).Where("name = ?", demo.Name).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			log.Printf("Organization %q already exists", demo.Name)
+			continue
+		}
+
+		org := demo
+		if err := orgService.CreateOrganization(ctx, &org, admin.ID, organization.CreateOptions{CreateDefaultTeam: true}); err != nil {
+			return fmt.Errorf("failed to create organization %q: %w", demo.Name, err)
+		}
+		log.Printf("Created organization %q", demo.Name)
+	}
+	return nil
+}
@@ -10,10 +10,16 @@ import (
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"github.com/llamacto/llama-gin-kit/app/authorization"
 	"github.com/llamacto/llama-gin-kit/config"
 	"github.com/llamacto/llama-gin-kit/pkg/database"
 	"github.com/llamacto/llama-gin-kit/pkg/email"
 	"github.com/llamacto/llama-gin-kit/pkg/jwt"
+	"github.com/llamacto/llama-gin-kit/pkg/logger"
+	"github.com/llamacto/llama-gin-kit/pkg/openai"
+	"github.com/llamacto/llama-gin-kit/pkg/redis"
+	"github.com/llamacto/llama-gin-kit/pkg/storage"
+	"github.com/llamacto/llama-gin-kit/pkg/validator"
 	"github.com/llamacto/llama-gin-kit/routes"
 )
 
@@ -34,11 +40,25 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	// Initialize the application and JSON access logs
+	if err := logger.Init(cfg.Log); err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+	defer logger.Sync()
+
+	if err := logger.InitAccess(cfg.Log); err != nil {
+		log.Printf("Warning: access log file unavailable, logging to stdout only: %v", err)
+	}
+
 	// Initialize JWT service
-	jwt.Init(cfg)
+	if err := jwt.Init(cfg); err != nil {
+		log.Fatalf("Failed to initialize JWT service: %v", err)
+	}
 
 	// Initialize email service
-	email.Init(cfg)
+	if err := email.Init(cfg); err != nil {
+		log.Fatalf("Failed to initialize email service: %v", err)
+	}
 
 	// Initialize database
 	_, err = database.InitDB(cfg.Database)
@@ -46,19 +66,49 @@ func main() {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 
+	// Initialize Redis (optional, rate limiting falls back to memory without it)
+	if err := redis.Init(cfg); err != nil {
+		log.Printf("Warning: redis unavailable, using in-memory fallbacks: %v", err)
+	}
+
+	// Select the permission cache before routes create the authorization services
+	authorization.ConfigurePermissionCache(cfg.Authorization.PermissionCache, redis.GetClient())
+
+	// Initialize OpenAI client (optional, TTS endpoints return 502 without it)
+	if cfg.OpenAI.APIKey != "" {
+		if err := openai.Init(cfg); err != nil {
+			log.Printf("Warning: OpenAI client unavailable: %v", err)
+		}
+	}
+
+	// Initialize R2 storage (optional, uploads are unavailable without it)
+	if err := storage.InitR2Storage(cfg); err != nil {
+		log.Printf("Warning: R2 storage unavailable, uploads disabled: %v", err)
+	}
+
+	// Register custom binding validators
+	if err := validator.Register(); err != nil {
+		log.Fatalf("Failed to register validators: %v", err)
+	}
+
 	// Set Gin mode
 	gin.SetMode(gin.DebugMode)
 
-	// Create Gin engine
-	r := gin.Default()
+	// Create Gin engine; logging and panic recovery are registered with the routes
+	r := gin.New()
 
 	// Enable CORS
 	corsConfig := cors.Config{
-		AllowOrigins:     []string{"http://localhost:3000", "http://localhost:3001"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
+		AllowMethods:     cfg.CORS.AllowMethods,
+		AllowHeaders:     cfg.CORS.AllowHeaders,
+		ExposeHeaders:    cfg.CORS.ExposeHeaders,
+		AllowCredentials: cfg.CORS.AllowCredentials,
+		MaxAge:           cfg.CORS.MaxAge,
+	}
+	if cfg.CORS.AllowAllOrigins() {
+		corsConfig.AllowAllOrigins = true
+	} else {
+		corsConfig.AllowOrigins = cfg.CORS.AllowOrigins
 	}
 	r.Use(cors.New(corsConfig))
 
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -13,15 +14,22 @@ import (
 var GlobalConfig *Config
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	JWT      JWTConfig
-	Log      LogConfig
-	OpenAI   OpenAIConfig
-	R2       R2Config
-	Email    EmailConfig
-	App      AppConfig
+	Server        ServerConfig
+	Database      DatabaseConfig
+	Redis         RedisConfig
+	JWT           JWTConfig
+	Log           LogConfig
+	OpenAI        OpenAIConfig
+	R2            R2Config
+	Email         EmailConfig
+	App           AppConfig
+	RateLimit     RateLimitConfig
+	CORS          CORSConfig
+	Upload        UploadConfig
+	Invitation    InvitationConfig
+	TTS           TTSConfig
+	Metrics       MetricsConfig
+	Authorization AuthorizationConfig
 }
 
 type ServerConfig struct {
@@ -30,6 +38,7 @@ type ServerConfig struct {
 	ReadTimeout    int    `json:"read_timeout"`
 	WriteTimeout   int    `json:"write_timeout"`
 	MaxHeaderBytes int    `json:"max_header_bytes"`
+	RequestTimeout int    `json:"request_timeout"` // Seconds a request may run before it is cancelled; 0 disables
 }
 
 type DatabaseConfig struct {
@@ -44,6 +53,12 @@ type DatabaseConfig struct {
 	MaxIdleConns    int    `json:"max_idle_conns"`
 	MaxOpenConns    int    `json:"max_open_conns"`
 	ConnMaxLifetime int    `json:"conn_max_lifetime"`
+
+	// Retrying the initial connection, so the app can start before the database is ready
+	ConnectMaxAttempts int           `json:"connect_max_attempts"` // 1 disables retries
+	ConnectBackoff     time.Duration `json:"connect_backoff"`      // Delay before the first retry, doubled after each attempt
+	ConnectMaxBackoff  time.Duration `json:"connect_max_backoff"`  // Upper bound on the delay between attempts
+	ConnectTimeout     time.Duration `json:"connect_timeout"`      // Give up once this much time has passed; 0 means no limit
 }
 
 type RedisConfig struct {
@@ -56,9 +71,16 @@ type RedisConfig struct {
 }
 
 type JWTConfig struct {
-	Secret         string        `json:"-"` // 敏感信息不序列化
-	ExpireDays     int           `json:"expire_days"`
-	ExpireDuration time.Duration `json:"-"`
+	Secret          string        `json:"-"` // 敏感信息不序列化
+	PreviousSecrets []string      `json:"-"` // Still accepted for verification while rotating Secret
+	ExpireDays      int           `json:"expire_days"`
+	ExpireDuration  time.Duration `json:"-"`
+	// RefreshExpireDuration is how long refresh tokens stay valid; ExpireDuration when zero
+	RefreshExpireDays     int           `json:"refresh_expire_days"`
+	RefreshExpireDuration time.Duration `json:"-"`
+	// RequireTokenType rejects tokens without a token_type claim instead of treating them as
+	// access tokens; enable once tokens issued before the claim existed have expired
+	RequireTokenType bool `json:"require_token_type"`
 }
 
 type LogConfig struct {
@@ -68,6 +90,7 @@ type LogConfig struct {
 	MaxAge     int    `json:"max_age"`
 	MaxBackups int    `json:"max_backups"`
 	Compress   bool   `json:"compress"`
+	Console    bool   `json:"console"` // Also write application logs to stdout
 }
 
 type OpenAIConfig struct {
@@ -85,6 +108,7 @@ type R2Config struct {
 }
 
 type EmailConfig struct {
+	Provider     string `json:"provider"` // "resend", "smtp" or "log"
 	Host         string `json:"host"`
 	Port         int    `json:"port"`
 	Username     string `json:"username"`
@@ -93,21 +117,88 @@ type EmailConfig struct {
 	ResendAPIKey string `json:"-"` // 敏感信息不序列化
 }
 
+type RateLimitConfig struct {
+	AuthLimit  int           `json:"auth_limit"`  // Max login/password reset attempts per key within AuthWindow
+	AuthWindow time.Duration `json:"auth_window"` // Sliding window for AuthLimit
+
+	// TTS generation uses token buckets so short bursts are absorbed but sustained load is capped
+	TTSUserPerMinute   float64 `json:"tts_user_per_minute"`   // Sustained generations per user per minute
+	TTSUserBurst       int     `json:"tts_user_burst"`        // Generations a user may make back to back
+	TTSGlobalPerMinute float64 `json:"tts_global_per_minute"` // Sustained generations across all users per minute
+	TTSGlobalBurst     int     `json:"tts_global_burst"`      // Generations allowed back to back across all users
+}
+
+type CORSConfig struct {
+	AllowOrigins     []string      `json:"allow_origins"` // "*" allows any origin and requires AllowCredentials to be false
+	AllowMethods     []string      `json:"allow_methods"`
+	AllowHeaders     []string      `json:"allow_headers"`
+	ExposeHeaders    []string      `json:"expose_headers"`
+	AllowCredentials bool          `json:"allow_credentials"`
+	MaxAge           time.Duration `json:"max_age"`
+}
+
+type UploadConfig struct {
+	MaxSize            int64    `json:"max_size"`             // Maximum upload size in bytes
+	AllowedMIMETypes   []string `json:"allowed_mime_types"`   // Accepted content types, checked against the sniffed type
+	AvatarMaxDimension int      `json:"avatar_max_dimension"` // Avatars wider or taller than this are scaled down, in pixels
+}
+
+type MetricsConfig struct {
+	Enabled bool   `json:"enabled"` // Serve Prometheus metrics at /metrics
+	Token   string `json:"-"`       // Bearer token required to scrape; without one only loopback clients may
+}
+
+type InvitationConfig struct {
+	// Hosts invitation redirect and callback URLs may point at; "*.example.com" matches any
+	// subdomain. An empty list rejects every URL of that kind.
+	RedirectAllowedHosts []string      `json:"redirect_allowed_hosts"`
+	CallbackAllowedHosts []string      `json:"callback_allowed_hosts"`
+	CallbackSecret       string        `json:"-"`                // Signs callback bodies when set
+	CallbackTimeout      time.Duration `json:"callback_timeout"` // Per-request timeout for status callbacks
+}
+
+type TTSConfig struct {
+	Workers        int           `json:"workers"`      // Generation jobs processed at once
+	QueueSize      int           `json:"queue_size"`   // Jobs that may wait for a worker before new ones are refused
+	DownloadSecret string        `json:"-"`            // Signs audio download links; downloads are unavailable while empty
+	DownloadTTL    time.Duration `json:"download_ttl"` // How long a signed audio download link stays valid
+}
+
+type AuthorizationConfig struct {
+	PermissionCache string `json:"permission_cache"` // Where resolved permissions are cached: "memory", "redis" or "off"
+}
+
 type AppConfig struct {
 	Name      string        `json:"name"`
 	Version   string        `json:"version"`
 	Secret    string        `json:"-"` // 敏感信息不序列化
 	JWTSecret string        `json:"-"` // 敏感信息不序列化
 	JWTExpire time.Duration `json:"jwt_expire"`
+
+	BcryptCost    int    `json:"bcrypt_cost"` // Cost factor for password hashing
+	AdminPassword string `json:"-"`           // Password for the seeded admin user; required outside development, random when empty in development
+
+	RequireApproval bool `json:"require_approval"` // New users stay pending until an admin approves them
+
+	PasswordResetURL string        `json:"password_reset_url"` // Page that receives ?token=... from reset emails
+	PasswordResetTTL time.Duration `json:"password_reset_ttl"` // How long a reset token stays valid
+
+	LastLoginInterval time.Duration `json:"last_login_interval"` // Minimum age of last_login before a sign-in rewrites it
+
+	ReservedNames []string `json:"reserved_names"` // Organization and team names nobody may take
 }
 
+// DefaultReservedNames is the APP_RESERVED_NAMES default: names that collide with routes or
+// invite impersonation
+const DefaultReservedNames = "admin,administrator,api,app,auth,billing,dashboard,help,login,logout,me,new,oauth,root,security,settings,signup,staff,support,system,www"
+
 // Load loads configuration from environment variables or .env file
 func Load() (*Config, error) {
 	// 确定当前环境模式
 	mode := os.Getenv("SERVER_MODE")
 
 	// 仅在开发环境尝试静默加载 .env 文件
-	if mode == "" || mode == "debug" || mode == "development" {
+	if isDevelopmentMode(mode) {
 		// 使用 Overload 可以确保即使找不到文件也不会产生警告
 		_ = godotenv.Overload()
 	} else {
@@ -162,6 +253,41 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 
+	// Load rate limit config
+	if err := loadRateLimitConfig(config); err != nil {
+		return nil, err
+	}
+
+	// Load CORS config
+	if err := loadCORSConfig(config); err != nil {
+		return nil, err
+	}
+
+	// Load upload config
+	if err := loadUploadConfig(config); err != nil {
+		return nil, err
+	}
+
+	// Load invitation config
+	if err := loadInvitationConfig(config); err != nil {
+		return nil, err
+	}
+
+	// Load TTS config
+	if err := loadTTSConfig(config); err != nil {
+		return nil, err
+	}
+
+	// Load metrics config
+	if err := loadMetricsConfig(config); err != nil {
+		return nil, err
+	}
+
+	// Load authorization config
+	if err := loadAuthorizationConfig(config); err != nil {
+		return nil, err
+	}
+
 	// Validate config
 	if err := validateConfig(config); err != nil {
 		return nil, err
@@ -192,12 +318,18 @@ func loadServerConfig(config *Config) error {
 		return fmt.Errorf("invalid SERVER_MAX_HEADER_BYTES: %v", err)
 	}
 
+	requestTimeout, err := strconv.Atoi(getEnv("SERVER_REQUEST_TIMEOUT", "30"))
+	if err != nil {
+		return fmt.Errorf("invalid SERVER_REQUEST_TIMEOUT: %v", err)
+	}
+
 	config.Server = ServerConfig{
 		Port:           port,
 		Mode:           getEnv("SERVER_MODE", "debug"),
 		ReadTimeout:    readTimeout,
 		WriteTimeout:   writeTimeout,
 		MaxHeaderBytes: maxHeaderBytes,
+		RequestTimeout: requestTimeout,
 	}
 
 	return nil
@@ -224,6 +356,26 @@ func loadDatabaseConfig(config *Config) error {
 		return fmt.Errorf("invalid DB_CONN_MAX_LIFETIME: %v", err)
 	}
 
+	connectMaxAttempts, err := strconv.Atoi(getEnv("DB_CONNECT_MAX_ATTEMPTS", "10"))
+	if err != nil || connectMaxAttempts < 1 {
+		return fmt.Errorf("invalid DB_CONNECT_MAX_ATTEMPTS: must be a positive integer")
+	}
+
+	connectBackoff, err := strconv.Atoi(getEnv("DB_CONNECT_BACKOFF", "1"))
+	if err != nil || connectBackoff < 0 {
+		return fmt.Errorf("invalid DB_CONNECT_BACKOFF: must be a non-negative number of seconds")
+	}
+
+	connectMaxBackoff, err := strconv.Atoi(getEnv("DB_CONNECT_MAX_BACKOFF", "30"))
+	if err != nil || connectMaxBackoff < 0 {
+		return fmt.Errorf("invalid DB_CONNECT_MAX_BACKOFF: must be a non-negative number of seconds")
+	}
+
+	connectTimeout, err := strconv.Atoi(getEnv("DB_CONNECT_TIMEOUT", "120"))
+	if err != nil || connectTimeout < 0 {
+		return fmt.Errorf("invalid DB_CONNECT_TIMEOUT: must be a non-negative number of seconds")
+	}
+
 	config.Database = DatabaseConfig{
 		Driver:          getEnv("DB_DRIVER", "postgres"),
 		Host:            getEnv("DB_HOST", "localhost"),
@@ -236,6 +388,11 @@ func loadDatabaseConfig(config *Config) error {
 		MaxIdleConns:    maxIdleConns,
 		MaxOpenConns:    maxOpenConns,
 		ConnMaxLifetime: connMaxLifetime,
+
+		ConnectMaxAttempts: connectMaxAttempts,
+		ConnectBackoff:     time.Duration(connectBackoff) * time.Second,
+		ConnectMaxBackoff:  time.Duration(connectMaxBackoff) * time.Second,
+		ConnectTimeout:     time.Duration(connectTimeout) * time.Second,
 	}
 
 	return nil
@@ -280,10 +437,24 @@ func loadJWTConfig(config *Config) error {
 		return fmt.Errorf("invalid JWT_EXPIRE_DAYS: %v", err)
 	}
 
+	refreshExpireDays, err := strconv.Atoi(getEnv("JWT_REFRESH_EXPIRE_DAYS", "30"))
+	if err != nil {
+		return fmt.Errorf("invalid JWT_REFRESH_EXPIRE_DAYS: %v", err)
+	}
+
+	requireTokenType, err := strconv.ParseBool(getEnv("JWT_REQUIRE_TOKEN_TYPE", "false"))
+	if err != nil {
+		return fmt.Errorf("invalid JWT_REQUIRE_TOKEN_TYPE: %v", err)
+	}
+
 	config.JWT = JWTConfig{
-		Secret:         getEnv("JWT_SECRET", ""),
-		ExpireDays:     expireDays,
-		ExpireDuration: time.Duration(expireDays) * 24 * time.Hour,
+		Secret:                getEnv("JWT_SECRET", ""),
+		PreviousSecrets:       splitEnvList(getEnv("JWT_PREVIOUS_SECRETS", "")),
+		ExpireDays:            expireDays,
+		ExpireDuration:        time.Duration(expireDays) * 24 * time.Hour,
+		RefreshExpireDays:     refreshExpireDays,
+		RefreshExpireDuration: time.Duration(refreshExpireDays) * 24 * time.Hour,
+		RequireTokenType:      requireTokenType,
 	}
 
 	return nil
@@ -310,6 +481,12 @@ func loadLogConfig(config *Config) error {
 		return fmt.Errorf("invalid LOG_COMPRESS: %v", err)
 	}
 
+	// Console output defaults to on in debug mode
+	console, err := strconv.ParseBool(getEnv("LOG_CONSOLE", strconv.FormatBool(getEnv("SERVER_MODE", "debug") == "debug")))
+	if err != nil {
+		return fmt.Errorf("invalid LOG_CONSOLE: %v", err)
+	}
+
 	config.Log = LogConfig{
 		Level:      getEnv("LOG_LEVEL", "debug"),
 		Filename:   getEnv("LOG_FILENAME", "logs/app.log"),
@@ -317,6 +494,7 @@ func loadLogConfig(config *Config) error {
 		MaxAge:     maxAge,
 		MaxBackups: maxBackups,
 		Compress:   compress,
+		Console:    console,
 	}
 
 	return nil
@@ -348,13 +526,27 @@ func loadEmailConfig(config *Config) error {
 		return fmt.Errorf("invalid EMAIL_PORT: %v", err)
 	}
 
+	resendAPIKey := getEnv("EMAIL_RESEND_API_KEY", "")
+	// Without an explicit provider, keep using Resend when it is configured and only log otherwise
+	defaultProvider := "log"
+	if resendAPIKey != "" {
+		defaultProvider = "resend"
+	}
+	provider := strings.ToLower(getEnv("EMAIL_PROVIDER", defaultProvider))
+	switch provider {
+	case "resend", "smtp", "log":
+	default:
+		return fmt.Errorf("invalid EMAIL_PROVIDER: %q (expected resend, smtp or log)", provider)
+	}
+
 	config.Email = EmailConfig{
+		Provider:     provider,
 		Host:         getEnv("EMAIL_HOST", "smtp.gmail.com"),
 		Port:         port,
 		Username:     getEnv("EMAIL_USERNAME", ""),
 		Password:     getEnv("EMAIL_PASSWORD", ""),
 		From:         getEnv("EMAIL_FROM", ""),
-		ResendAPIKey: getEnv("EMAIL_RESEND_API_KEY", ""),
+		ResendAPIKey: resendAPIKey,
 	}
 	return nil
 }
@@ -365,16 +557,243 @@ func loadAppConfig(config *Config) error {
 		return fmt.Errorf("invalid APP_JWT_EXPIRE_DAYS: %v", err)
 	}
 
+	bcryptCost, err := strconv.Atoi(getEnv("APP_BCRYPT_COST", "10"))
+	if err != nil {
+		return fmt.Errorf("invalid APP_BCRYPT_COST: %v", err)
+	}
+	if bcryptCost < 4 || bcryptCost > 31 {
+		return fmt.Errorf("APP_BCRYPT_COST must be between 4 and 31")
+	}
+
+	requireApproval, err := strconv.ParseBool(getEnv("APP_REQUIRE_APPROVAL", "false"))
+	if err != nil {
+		return fmt.Errorf("invalid APP_REQUIRE_APPROVAL: %v", err)
+	}
+
+	resetTTL, err := strconv.Atoi(getEnv("APP_PASSWORD_RESET_TTL", "30"))
+	if err != nil {
+		return fmt.Errorf("invalid APP_PASSWORD_RESET_TTL: %v", err)
+	}
+	if resetTTL <= 0 {
+		return fmt.Errorf("APP_PASSWORD_RESET_TTL must be positive")
+	}
+	lastLoginInterval, err := strconv.Atoi(getEnv("APP_LAST_LOGIN_INTERVAL", "300"))
+	if err != nil {
+		return fmt.Errorf("invalid APP_LAST_LOGIN_INTERVAL: %v", err)
+	}
+	if lastLoginInterval < 0 {
+		return fmt.Errorf("APP_LAST_LOGIN_INTERVAL must not be negative")
+	}
+
+	resetURL := getEnv("APP_PASSWORD_RESET_URL", "")
+	if resetURL == "" {
+		resetURL = strings.TrimRight(getEnv("APP_URL", "http://localhost:6066"), "/") + "/reset-password"
+	}
+
 	config.App = AppConfig{
-		Name:      getEnv("APP_NAME", "Llama-Gin-Kit"),
-		Version:   getEnv("APP_VERSION", "1.0.0"),
-		Secret:    getEnv("APP_SECRET", ""),
-		JWTSecret: getEnv("APP_JWT_SECRET", ""),
-		JWTExpire: time.Duration(expireDays) * 24 * time.Hour,
+		Name:          getEnv("APP_NAME", "Llama-Gin-Kit"),
+		Version:       getEnv("APP_VERSION", "1.0.0"),
+		Secret:        getEnv("APP_SECRET", ""),
+		JWTSecret:     getEnv("APP_JWT_SECRET", ""),
+		JWTExpire:     time.Duration(expireDays) * 24 * time.Hour,
+		BcryptCost:    bcryptCost,
+		AdminPassword: getEnv("APP_ADMIN_PASSWORD", ""),
+
+		RequireApproval: requireApproval,
+
+		PasswordResetURL: resetURL,
+		PasswordResetTTL: time.Duration(resetTTL) * time.Minute,
+
+		LastLoginInterval: time.Duration(lastLoginInterval) * time.Second,
+
+		ReservedNames: splitEnvList(getEnv("APP_RESERVED_NAMES", DefaultReservedNames)),
+	}
+	return nil
+}
+
+func loadRateLimitConfig(config *Config) error {
+	authLimit, err := strconv.Atoi(getEnv("RATE_LIMIT_AUTH_LIMIT", "5"))
+	if err != nil {
+		return fmt.Errorf("invalid RATE_LIMIT_AUTH_LIMIT: %v", err)
+	}
+
+	authWindow, err := strconv.Atoi(getEnv("RATE_LIMIT_AUTH_WINDOW", "60"))
+	if err != nil {
+		return fmt.Errorf("invalid RATE_LIMIT_AUTH_WINDOW: %v", err)
+	}
+
+	ttsUserPerMinute, err := strconv.ParseFloat(getEnv("RATE_LIMIT_TTS_USER_PER_MINUTE", "10"), 64)
+	if err != nil {
+		return fmt.Errorf("invalid RATE_LIMIT_TTS_USER_PER_MINUTE: %v", err)
+	}
+
+	ttsUserBurst, err := strconv.Atoi(getEnv("RATE_LIMIT_TTS_USER_BURST", "3"))
+	if err != nil {
+		return fmt.Errorf("invalid RATE_LIMIT_TTS_USER_BURST: %v", err)
+	}
+
+	ttsGlobalPerMinute, err := strconv.ParseFloat(getEnv("RATE_LIMIT_TTS_GLOBAL_PER_MINUTE", "120"), 64)
+	if err != nil {
+		return fmt.Errorf("invalid RATE_LIMIT_TTS_GLOBAL_PER_MINUTE: %v", err)
+	}
+
+	ttsGlobalBurst, err := strconv.Atoi(getEnv("RATE_LIMIT_TTS_GLOBAL_BURST", "20"))
+	if err != nil {
+		return fmt.Errorf("invalid RATE_LIMIT_TTS_GLOBAL_BURST: %v", err)
+	}
+
+	// A bucket that cannot hold a token would refuse every request
+	if ttsUserPerMinute > 0 && ttsUserBurst < 1 {
+		return fmt.Errorf("invalid RATE_LIMIT_TTS_USER_BURST: must be a positive integer")
+	}
+	if ttsGlobalPerMinute > 0 && ttsGlobalBurst < 1 {
+		return fmt.Errorf("invalid RATE_LIMIT_TTS_GLOBAL_BURST: must be a positive integer")
+	}
+
+	config.RateLimit = RateLimitConfig{
+		AuthLimit:          authLimit,
+		AuthWindow:         time.Duration(authWindow) * time.Second,
+		TTSUserPerMinute:   ttsUserPerMinute,
+		TTSUserBurst:       ttsUserBurst,
+		TTSGlobalPerMinute: ttsGlobalPerMinute,
+		TTSGlobalBurst:     ttsGlobalBurst,
+	}
+	return nil
+}
+
+func loadCORSConfig(config *Config) error {
+	allowCredentials, err := strconv.ParseBool(getEnv("CORS_ALLOW_CREDENTIALS", "true"))
+	if err != nil {
+		return fmt.Errorf("invalid CORS_ALLOW_CREDENTIALS: %v", err)
+	}
+
+	maxAge, err := strconv.Atoi(getEnv("CORS_MAX_AGE", "43200"))
+	if err != nil {
+		return fmt.Errorf("invalid CORS_MAX_AGE: %v", err)
+	}
+
+	config.CORS = CORSConfig{
+		AllowOrigins:     splitEnvList(getEnv("CORS_ALLOWED_ORIGINS", "http://localhost:3000,http://localhost:3001")),
+		AllowMethods:     splitEnvList(getEnv("CORS_ALLOWED_METHODS", "GET,POST,PUT,DELETE,OPTIONS")),
+		AllowHeaders:     splitEnvList(getEnv("CORS_ALLOWED_HEADERS", "Origin,Content-Type,Accept,Authorization,X-Request-ID")),
+		ExposeHeaders:    splitEnvList(getEnv("CORS_EXPOSE_HEADERS", "Content-Length,X-Request-ID,X-Total-Count,Link")),
+		AllowCredentials: allowCredentials,
+		MaxAge:           time.Duration(maxAge) * time.Second,
+	}
+	return nil
+}
+
+func loadUploadConfig(config *Config) error {
+	maxSize, err := strconv.ParseInt(getEnv("UPLOAD_MAX_SIZE", "5242880"), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid UPLOAD_MAX_SIZE: %v", err)
+	}
+	if maxSize <= 0 {
+		return fmt.Errorf("UPLOAD_MAX_SIZE must be positive")
+	}
+	avatarMaxDimension, err := strconv.Atoi(getEnv("UPLOAD_AVATAR_MAX_DIMENSION", "512"))
+	if err != nil {
+		return fmt.Errorf("invalid UPLOAD_AVATAR_MAX_DIMENSION: %v", err)
+	}
+	if avatarMaxDimension <= 0 {
+		return fmt.Errorf("UPLOAD_AVATAR_MAX_DIMENSION must be positive")
+	}
+
+	config.Upload = UploadConfig{
+		MaxSize:            maxSize,
+		AllowedMIMETypes:   splitEnvList(getEnv("UPLOAD_ALLOWED_MIME_TYPES", "image/jpeg,image/png,image/gif,image/webp,audio/mpeg,audio/wave")),
+		AvatarMaxDimension: avatarMaxDimension,
+	}
+	return nil
+}
+
+func loadInvitationConfig(config *Config) error {
+	timeout, err := strconv.Atoi(getEnv("INVITATION_CALLBACK_TIMEOUT", "10"))
+	if err != nil {
+		return fmt.Errorf("invalid INVITATION_CALLBACK_TIMEOUT: %v", err)
+	}
+	if timeout <= 0 {
+		return fmt.Errorf("INVITATION_CALLBACK_TIMEOUT must be positive")
+	}
+
+	config.Invitation = InvitationConfig{
+		RedirectAllowedHosts: splitEnvList(getEnv("INVITATION_REDIRECT_ALLOWED_HOSTS", "localhost")),
+		CallbackAllowedHosts: splitEnvList(getEnv("INVITATION_CALLBACK_ALLOWED_HOSTS", "")),
+		CallbackSecret:       getEnv("INVITATION_CALLBACK_SECRET", ""),
+		CallbackTimeout:      time.Duration(timeout) * time.Second,
+	}
+	return nil
+}
+
+func loadTTSConfig(config *Config) error {
+	workers, err := strconv.Atoi(getEnv("TTS_WORKERS", "2"))
+	if err != nil || workers <= 0 {
+		return fmt.Errorf("invalid TTS_WORKERS: must be a positive integer")
+	}
+	queueSize, err := strconv.Atoi(getEnv("TTS_QUEUE_SIZE", "100"))
+	if err != nil || queueSize <= 0 {
+		return fmt.Errorf("invalid TTS_QUEUE_SIZE: must be a positive integer")
+	}
+	downloadTTL, err := strconv.Atoi(getEnv("TTS_DOWNLOAD_TTL", "900"))
+	if err != nil || downloadTTL <= 0 {
+		return fmt.Errorf("invalid TTS_DOWNLOAD_TTL: must be a positive number of seconds")
+	}
+
+	config.TTS = TTSConfig{
+		Workers:        workers,
+		QueueSize:      queueSize,
+		DownloadSecret: getEnv("TTS_DOWNLOAD_SECRET", ""),
+		DownloadTTL:    time.Duration(downloadTTL) * time.Second,
+	}
+	return nil
+}
+
+func loadMetricsConfig(config *Config) error {
+	enabled, err := strconv.ParseBool(getEnv("METRICS_ENABLED", "false"))
+	if err != nil {
+		return fmt.Errorf("invalid METRICS_ENABLED: %v", err)
+	}
+
+	config.Metrics = MetricsConfig{
+		Enabled: enabled,
+		Token:   getEnv("METRICS_TOKEN", ""),
 	}
 	return nil
 }
 
+func loadAuthorizationConfig(config *Config) error {
+	permissionCache := getEnv("PERMISSION_CACHE", "memory")
+	switch permissionCache {
+	case "memory", "redis", "off":
+	default:
+		return fmt.Errorf("invalid PERMISSION_CACHE: must be memory, redis or off")
+	}
+
+	config.Authorization = AuthorizationConfig{
+		PermissionCache: permissionCache,
+	}
+	return nil
+}
+
+// IsDevelopment reports whether the server runs in a development mode (debug or development)
+func (c ServerConfig) IsDevelopment() bool {
+	return isDevelopmentMode(c.Mode)
+}
+
+func isDevelopmentMode(mode string) bool {
+	return mode == "" || mode == "debug" || mode == "development"
+}
+
+// AllowAllOrigins reports whether the origin list is the "*" wildcard
+func (c CORSConfig) AllowAllOrigins() bool {
+	for _, origin := range c.AllowOrigins {
+		if origin == "*" {
+			return true
+		}
+	}
+	return false
+}
+
 func validateConfig(config *Config) error {
 	// Validate required fields
 	if config.Database.Password == "" {
@@ -385,9 +804,34 @@ func validateConfig(config *Config) error {
 		return fmt.Errorf("JWT_SECRET is required")
 	}
 
+	if len(config.CORS.AllowOrigins) == 0 {
+		return fmt.Errorf("CORS_ALLOWED_ORIGINS must not be empty")
+	}
+
+	if config.CORS.AllowAllOrigins() {
+		// Browsers reject a wildcard origin on credentialed requests
+		if config.CORS.AllowCredentials {
+			return fmt.Errorf("CORS_ALLOWED_ORIGINS=* cannot be combined with CORS_ALLOW_CREDENTIALS=true")
+		}
+		if len(config.CORS.AllowOrigins) > 1 {
+			return fmt.Errorf("CORS_ALLOWED_ORIGINS=* cannot be combined with explicit origins")
+		}
+	}
+
 	return nil
 }
 
+// splitEnvList splits a comma-separated env value, trimming spaces and dropping empty entries
+func splitEnvList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func getEnv(key, defaultValue string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
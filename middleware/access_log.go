@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/pkg/authctx"
+	"github.com/llamacto/llama-gin-kit/pkg/logger"
+	"github.com/llamacto/llama-gin-kit/pkg/requestid"
+	"go.uber.org/zap"
+)
+
+// accessLogSkipPrefixes are paths too noisy to be worth an access log line
+var accessLogSkipPrefixes = []string{"/ping", "/swagger"}
+
+// AccessLog writes one JSON line per request with method, path, status, latency, client IP,
+// user ID and request ID. 5xx responses are logged at error level, everything else at info.
+// It should run after RequestID and before authentication so the user ID set later is seen.
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		for _, prefix := range accessLogSkipPrefixes {
+			if strings.HasPrefix(path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		fields := []zap.Field{
+			zap.String("request_id", requestid.Get(c)),
+			zap.String("method", c.Request.Method),
+			zap.String("path", path),
+			zap.Int("status", status),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("client_ip", c.ClientIP()),
+		}
+		if userID, ok := authctx.UserID(c); ok {
+			fields = append(fields, zap.Uint("user_id", userID))
+		}
+		if len(c.Errors) > 0 {
+			fields = append(fields, zap.String("errors", c.Errors.String()))
+		}
+
+		if status >= 500 {
+			logger.Access().Error("request", fields...)
+			return
+		}
+		logger.Access().Info("request", fields...)
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/llamacto/llama-gin-kit/app/apikey"
+	"github.com/llamacto/llama-gin-kit/pkg/authctx"
 )
 
 // APIKeyAuth is a middleware for API key authentication
@@ -41,8 +42,11 @@ func APIKeyAuth(apiKeyService apikey.Service) gin.HandlerFunc {
 		}
 		
 		// Store user ID and API key ID in context
-		c.Set("userID", apiKeyObj.UserID)
-		c.Set("apiKeyID", apiKeyObj.ID)
+		authctx.SetUserID(c, apiKeyObj.UserID)
+		authctx.SetAPIKeyID(c, apiKeyObj.ID)
+		authctx.SetAuthType(c, authctx.AuthTypeAPIKey)
+		authctx.SetAPIKeyScopes(c, apiKeyObj.ScopeList())
+		authctx.SetAPIKeyRateLimit(c, apiKeyObj.RateLimit)
 		
 		// If specific permissions are required, check them
 		if requiredPerms, exists := c.Get("requiredPermissions"); exists {
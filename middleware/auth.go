@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/llamacto/llama-gin-kit/config"
+	"github.com/llamacto/llama-gin-kit/pkg/authctx"
 	"github.com/llamacto/llama-gin-kit/pkg/logger"
 )
 
@@ -67,7 +68,7 @@ func JWT() gin.HandlerFunc {
 		}
 
 		// Store user information in context
-		c.Set("userID", claims.UserID)
+		authctx.SetUserID(c, claims.UserID)
 		c.Next()
 	}
 }
@@ -3,6 +3,7 @@ package middleware
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/llamacto/llama-gin-kit/app/apikey"
+	"github.com/llamacto/llama-gin-kit/pkg/authctx"
 	"github.com/llamacto/llama-gin-kit/pkg/middleware"
 )
 
@@ -23,9 +24,11 @@ func CombinedAuth(apiKeyService apikey.Service) gin.HandlerFunc {
 			apiKeyObj, err := apiKeyService.ValidateAPIKey(apiKeyHeader)
 			if err == nil {
 				// API key is valid, set user ID and API key ID in context
-				c.Set("userID", apiKeyObj.UserID)
-				c.Set("apiKeyID", apiKeyObj.ID)
-				c.Set("authType", "api_key")
+				authctx.SetUserID(c, apiKeyObj.UserID)
+				authctx.SetAPIKeyID(c, apiKeyObj.ID)
+				authctx.SetAuthType(c, authctx.AuthTypeAPIKey)
+				authctx.SetAPIKeyScopes(c, apiKeyObj.ScopeList())
+				authctx.SetAPIKeyRateLimit(c, apiKeyObj.RateLimit)
 				c.Next()
 				return
 			}
@@ -37,7 +40,7 @@ func CombinedAuth(apiKeyService apikey.Service) gin.HandlerFunc {
 		
 		// If JWT auth was successful, set authType to jwt
 		if !c.IsAborted() {
-			c.Set("authType", "jwt")
+			authctx.SetAuthType(c, authctx.AuthTypeJWT)
 		}
 	}
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/llamacto/llama-gin-kit/pkg/logger"
+	"github.com/llamacto/llama-gin-kit/pkg/requestid"
 )
 
 // Logger creates a middleware for logging HTTP requests
@@ -22,7 +23,8 @@ func Logger() gin.HandlerFunc {
 
 		// Log request details
 		logger.Info(fmt.Sprintf(
-			"Request: method=%s path=%s status=%d latency=%v",
+			"Request: request_id=%s method=%s path=%s status=%d latency=%v",
+			requestid.Get(c),
 			c.Request.Method,
 			c.Request.URL.Path,
 			c.Writer.Status(),
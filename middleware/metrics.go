@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/pkg/i18n"
+	"github.com/llamacto/llama-gin-kit/pkg/response"
+)
+
+// MetricsAuth guards the metrics endpoint. With a token, scrapers must send it as a bearer
+// token; without one only clients connecting from a loopback address are let through. The
+// peer address is used rather than forwarding headers, which clients can forge.
+func MetricsAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token != "" {
+			provided := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				response.ErrorKey(c, http.StatusUnauthorized, i18n.KeyUnauthorized)
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if ip := net.ParseIP(c.RemoteIP()); ip == nil || !ip.IsLoopback() {
+			response.Error(c, http.StatusForbidden, "Metrics are only available to local clients")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
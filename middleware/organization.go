@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/pkg/authctx"
+	"github.com/llamacto/llama-gin-kit/pkg/params"
+)
+
+// OrganizationContext resolves the organization a request is scoped to and stores it with
+// authctx.SetOrganizationID. The path parameter wins; when it is absent the token's active
+// organization is used. Requests with neither continue without an organization.
+func OrganizationContext(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if raw := c.Param(param); raw != "" {
+			id, err := params.ParseID(raw)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"code": 400,
+					"msg":  "Invalid organization ID",
+				})
+				c.Abort()
+				return
+			}
+			authctx.SetOrganizationID(c, id)
+			c.Next()
+			return
+		}
+
+		if orgID, ok := authctx.ActiveOrganizationID(c); ok {
+			authctx.SetOrganizationID(c, orgID)
+		}
+		c.Next()
+	}
+}
+
+// OrganizationID returns the organization resolved by OrganizationContext
+func OrganizationID(c *gin.Context) (uint, bool) {
+	id, ok := authctx.OrganizationID(c)
+	return id, ok && id != 0
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/app/authorization"
+)
+
+// permissionCacheKey is the gin context key holding the request's permission check results
+const permissionCacheKey = "middleware.permission_cache"
+
+// permissionCache memoizes permission decisions for one request
+type permissionCache struct {
+	mu      sync.Mutex
+	results map[string]bool
+}
+
+// HasPermission reports whether the user may perform action on resource. Results are
+// memoized on the gin context, so repeated checks within one request reach the authorization
+// service once. gin resets the context between requests, which clears the cache.
+// Errors are not cached.
+func HasPermission(c *gin.Context, authService authorization.Service, userID uint, resource, action string) (bool, error) {
+	cache := requestPermissionCache(c)
+	key := permissionCacheEntry(userID, resource, action)
+
+	cache.mu.Lock()
+	allowed, ok := cache.results[key]
+	cache.mu.Unlock()
+	if ok {
+		return allowed, nil
+	}
+
+	allowed, err := authService.CheckPermission(c.Request.Context(), userID, resource, action, &authorization.PolicyEnvironment{
+		Time: time.Now(),
+		IP:   net.ParseIP(c.ClientIP()),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	cache.mu.Lock()
+	cache.results[key] = allowed
+	cache.mu.Unlock()
+	return allowed, nil
+}
+
+// requestPermissionCache returns the request's cache, creating it on first use
+func requestPermissionCache(c *gin.Context) *permissionCache {
+	if value, ok := c.Get(permissionCacheKey); ok {
+		if cache, ok := value.(*permissionCache); ok {
+			return cache
+		}
+	}
+	cache := &permissionCache{results: make(map[string]bool)}
+	c.Set(permissionCacheKey, cache)
+	return cache
+}
+
+// permissionCacheEntry builds the cache key for one check. NUL separates the parts because
+// resources themselves contain colons, e.g. "dataset:1".
+func permissionCacheEntry(userID uint, resource, action string) string {
+	return fmt.Sprintf("%d\x00%s\x00%s", userID, resource, action)
+}
@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/app/authorization"
+)
+
+// countingAuthService counts permission checks; other methods are left to the embedded nil
+// interface and panic if called
+type countingAuthService struct {
+	authorization.Service
+	calls   int
+	allowed map[grant]bool
+	err     error
+}
+
+// grant is a resource and action the fake service allows
+type grant struct {
+	resource string
+	action   string
+}
+
+func (s *countingAuthService) CheckPermission(ctx context.Context, userID uint, resource, action string, env *authorization.PolicyEnvironment) (bool, error) {
+	s.calls++
+	if s.err != nil {
+		return false, s.err
+	}
+	return s.allowed[grant{resource, action}], nil
+}
+
+func TestHasPermissionMemoizesPerRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	type check struct {
+		userID   uint
+		resource string
+		action   string
+		want     bool
+	}
+
+	tests := []struct {
+		name      string
+		checks    []check
+		wantCalls int
+	}{
+		{
+			name: "repeated check",
+			checks: []check{
+				{userID: 1, resource: "dataset:1", action: "read", want: true},
+				{userID: 1, resource: "dataset:1", action: "read", want: true},
+				{userID: 1, resource: "dataset:1", action: "read", want: true},
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "denials are memoized too",
+			checks: []check{
+				{userID: 1, resource: "dataset:1", action: "delete"},
+				{userID: 1, resource: "dataset:1", action: "delete"},
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "different checks",
+			checks: []check{
+				{userID: 1, resource: "dataset:1", action: "read", want: true},
+				{userID: 1, resource: "dataset:1", action: "delete"},
+				{userID: 2, resource: "dataset:1", action: "read", want: true},
+				{userID: 1, resource: "dataset:1", action: "read", want: true},
+			},
+			wantCalls: 3,
+		},
+		{
+			name: "colons in resources do not collide",
+			checks: []check{
+				{userID: 1, resource: "dataset:1", action: "read", want: true},
+				{userID: 1, resource: "dataset", action: "1:read"},
+			},
+			wantCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &countingAuthService{allowed: map[grant]bool{{"dataset:1", "read"}: true}}
+			router := gin.New()
+			router.GET("/", func(c *gin.Context) {
+				for i, chk := range tt.checks {
+					allowed, err := HasPermission(c, svc, chk.userID, chk.resource, chk.action)
+					if err != nil || allowed != chk.want {
+						t.Errorf("check %d: HasPermission() = %v, %v; want %v", i, allowed, err, chk.want)
+					}
+				}
+			})
+
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+			if svc.calls != tt.wantCalls {
+				t.Fatalf("backend calls in one request = %d, want %d", svc.calls, tt.wantCalls)
+			}
+
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+			if svc.calls != 2*tt.wantCalls {
+				t.Fatalf("backend calls after a second request = %d, want %d", svc.calls, 2*tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestHasPermissionDoesNotCacheErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	svc := &countingAuthService{err: errors.New("database unavailable")}
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+	for i := 0; i < 2; i++ {
+		if _, err := HasPermission(c, svc, 1, "dataset:1", "read"); err == nil {
+			t.Fatalf("check %d: expected an error", i)
+		}
+	}
+	if svc.calls != 2 {
+		t.Fatalf("backend calls = %d, want 2", svc.calls)
+	}
+
+	svc.err = nil
+	svc.allowed = map[grant]bool{{"dataset:1", "read"}: true}
+	if allowed, err := HasPermission(c, svc, 1, "dataset:1", "read"); err != nil || !allowed {
+		t.Fatalf("HasPermission() after recovery = %v, %v; want true", allowed, err)
+	}
+}
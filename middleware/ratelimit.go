@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/config"
+	"github.com/llamacto/llama-gin-kit/pkg/authctx"
+	"github.com/llamacto/llama-gin-kit/pkg/ratelimit"
+	"github.com/llamacto/llama-gin-kit/pkg/redis"
+)
+
+// RateLimit is a middleware limiting requests per key within a sliding window.
+// keyFn extracts the key (e.g. client IP or email); an empty key skips limiting.
+func RateLimit(keyFn func(c *gin.Context) string, limit int, window time.Duration) gin.HandlerFunc {
+	limiter := ratelimit.NewLimiter(redis.GetClient())
+
+	return func(c *gin.Context) {
+		key := keyFn(c)
+		if c.IsAborted() {
+			return
+		}
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		allowed, retryAfter, err := limiter.Allow(c.Request.Context(), "ratelimit:"+c.FullPath()+":"+key, limit, window)
+		if err != nil {
+			// Don't lock users out because the limiter itself is broken
+			c.Next()
+			return
+		}
+
+		if !allowed {
+			abortTooManyRequests(c, retryAfter)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// TokenBucketLimit limits requests with a per-user bucket and a bucket shared by all users.
+// Users are identified by "userID", falling back to the client IP for anonymous requests.
+// Either bucket may be nil to skip that limit. A request refused by one bucket spends no
+// token from the other.
+func TokenBucketLimit(perUser, global *ratelimit.TokenBucket) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var key string
+		if perUser != nil {
+			key = ClientIPKey(c)
+			if userID, ok := authctx.UserID(c); ok {
+				key = fmt.Sprintf("user:%d", userID)
+			}
+			if allowed, retryAfter := perUser.Allow(key); !allowed {
+				abortTooManyRequests(c, retryAfter)
+				return
+			}
+		}
+
+		if global != nil {
+			if allowed, retryAfter := global.Allow("global"); !allowed {
+				// The request never ran, so it must not count against the user
+				if perUser != nil {
+					perUser.Return(key)
+				}
+				abortTooManyRequests(c, retryAfter)
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// TTSRateLimit limits TTS generation using the TTS token bucket settings.
+// A non-positive rate disables that limit.
+func TTSRateLimit(cfg config.RateLimitConfig) gin.HandlerFunc {
+	var perUser, global *ratelimit.TokenBucket
+	if cfg.TTSUserPerMinute > 0 {
+		perUser = ratelimit.NewTokenBucket(cfg.TTSUserPerMinute/60, cfg.TTSUserBurst)
+	}
+	if cfg.TTSGlobalPerMinute > 0 {
+		global = ratelimit.NewTokenBucket(cfg.TTSGlobalPerMinute/60, cfg.TTSGlobalBurst)
+	}
+	return TokenBucketLimit(perUser, global)
+}
+
+// APIKeyRateLimit enforces the per-minute quota of the API key a request authenticated with,
+// counting in Redis per key ID. Quota headers are set on every API-key request; JWT requests
+// and keys with a zero quota are not limited. Must run after CombinedAuth or APIKeyAuth.
+func APIKeyRateLimit() gin.HandlerFunc {
+	counter := ratelimit.NewQuotaCounter(redis.GetClient())
+
+	return func(c *gin.Context) {
+		if authctx.AuthType(c) != authctx.AuthTypeAPIKey {
+			c.Next()
+			return
+		}
+		limit := authctx.APIKeyRateLimit(c)
+		apiKeyID, ok := authctx.APIKeyID(c)
+		if limit <= 0 || !ok {
+			c.Next()
+			return
+		}
+
+		result, err := counter.Hit(c.Request.Context(), fmt.Sprintf("ratelimit:apikey:%d", apiKeyID), limit, time.Minute)
+		if err != nil {
+			// Don't lock integrations out because the counter itself is broken
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(result.ResetAt.Unix(), 10))
+		if !result.Allowed {
+			abortTooManyRequests(c, time.Until(result.ResetAt))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// abortTooManyRequests responds 429 with a Retry-After header rounded up to whole seconds
+func abortTooManyRequests(c *gin.Context, retryAfter time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"code": 429,
+		"msg":  "Too many requests, please try again later",
+	})
+	c.Abort()
+}
+
+// ClientIPKey keys rate limits by the client IP address
+func ClientIPKey(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// maxKeyedBodySize bounds the request body JSONFieldKey reads before the handler runs
+const maxKeyedBodySize = 64 << 10
+
+// JSONFieldKey keys rate limits by a string field of the JSON request body (e.g. "email").
+// The body is restored so handlers can still bind it; bodies over 64 KiB are rejected with 413.
+func JSONFieldKey(field string) func(c *gin.Context) string {
+	return func(c *gin.Context) string {
+		if c.Request.Body == nil {
+			return ""
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxKeyedBodySize))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+					"code": http.StatusRequestEntityTooLarge,
+					"msg":  "Request body too large",
+				})
+			}
+			return ""
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		var payload map[string]interface{}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return ""
+		}
+
+		value, ok := payload[field].(string)
+		if !ok || value == "" {
+			return ""
+		}
+		return field + ":" + strings.ToLower(strings.TrimSpace(value))
+	}
+}
@@ -0,0 +1,183 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/pkg/authctx"
+	"github.com/llamacto/llama-gin-kit/pkg/ratelimit"
+)
+
+func TestRateLimitJSONFieldKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{"keyed body reaches the handler", `{"email":"a@example.com"}`, http.StatusOK, `{"email":"a@example.com"}`},
+		{"body without the field", `{"other":1}`, http.StatusOK, `{"other":1}`},
+		{"oversized body", `{"email":"` + strings.Repeat("a", maxKeyedBodySize) + `"}`, http.StatusRequestEntityTooLarge, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.POST("/login", RateLimit(JSONFieldKey("email"), 10, time.Minute), func(c *gin.Context) {
+				body, _ := io.ReadAll(c.Request.Body)
+				c.String(http.StatusOK, string(body))
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(tt.body)))
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantCode)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("handler saw body %q, want %q", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestRateLimitRejectsOverLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/login", RateLimit(ClientIPKey, 2, time.Minute), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	codes := make([]int, 0, 3)
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/login", nil))
+		codes = append(codes, w.Code)
+		if w.Code == http.StatusTooManyRequests && w.Header().Get("Retry-After") == "" {
+			t.Error("429 response has no Retry-After header")
+		}
+	}
+
+	want := []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}
+	for i := range want {
+		if codes[i] != want[i] {
+			t.Fatalf("status codes = %v, want %v", codes, want)
+		}
+	}
+}
+
+func TestTokenBucketLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	type request struct {
+		userID uint // Zero for an anonymous request
+		want   int
+	}
+
+	tests := []struct {
+		name     string
+		perUser  *ratelimit.TokenBucket
+		global   *ratelimit.TokenBucket
+		requests []request
+		// wantUserToken lists per-user bucket keys that must still hold a token afterwards
+		wantUserToken []string
+	}{
+		{
+			name:    "per-user limit",
+			perUser: ratelimit.NewTokenBucket(0.001, 2),
+			requests: []request{
+				{userID: 1, want: http.StatusOK},
+				{userID: 1, want: http.StatusOK},
+				{userID: 1, want: http.StatusTooManyRequests},
+				{userID: 2, want: http.StatusOK},
+			},
+		},
+		{
+			name:    "anonymous requests share the client IP bucket",
+			perUser: ratelimit.NewTokenBucket(0.001, 1),
+			requests: []request{
+				{want: http.StatusOK},
+				{want: http.StatusTooManyRequests},
+				{userID: 1, want: http.StatusOK},
+			},
+		},
+		{
+			name:   "global limit",
+			global: ratelimit.NewTokenBucket(0.001, 2),
+			requests: []request{
+				{userID: 1, want: http.StatusOK},
+				{userID: 2, want: http.StatusOK},
+				{userID: 3, want: http.StatusTooManyRequests},
+			},
+		},
+		{
+			name:    "per-user rejection does not spend a global token",
+			perUser: ratelimit.NewTokenBucket(0.001, 1),
+			global:  ratelimit.NewTokenBucket(0.001, 2),
+			requests: []request{
+				{userID: 1, want: http.StatusOK},
+				{userID: 1, want: http.StatusTooManyRequests},
+				{userID: 1, want: http.StatusTooManyRequests},
+				{userID: 2, want: http.StatusOK},
+				{userID: 3, want: http.StatusTooManyRequests},
+			},
+		},
+		{
+			name:    "global rejection does not spend a per-user token",
+			perUser: ratelimit.NewTokenBucket(0.001, 1),
+			global:  ratelimit.NewTokenBucket(0.001, 1),
+			requests: []request{
+				{userID: 1, want: http.StatusOK},
+				{userID: 2, want: http.StatusTooManyRequests},
+			},
+			wantUserToken: []string{"user:2"},
+		},
+		{
+			name: "no limits",
+			requests: []request{
+				{userID: 1, want: http.StatusOK},
+				{userID: 1, want: http.StatusOK},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.POST("/tts/generate", func(c *gin.Context) {
+				if id, err := strconv.ParseUint(c.GetHeader("X-Test-User"), 10, 64); err == nil && id > 0 {
+					authctx.SetUserID(c, uint(id))
+				}
+			}, TokenBucketLimit(tt.perUser, tt.global), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			for i, req := range tt.requests {
+				r := httptest.NewRequest(http.MethodPost, "/tts/generate", nil)
+				r.Header.Set("X-Test-User", strconv.FormatUint(uint64(req.userID), 10))
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, r)
+
+				if w.Code != req.want {
+					t.Fatalf("request %d: status = %d, want %d", i, w.Code, req.want)
+				}
+				if w.Code == http.StatusTooManyRequests && w.Header().Get("Retry-After") == "" {
+					t.Fatalf("request %d: 429 without Retry-After", i)
+				}
+			}
+			for _, key := range tt.wantUserToken {
+				if allowed, _ := tt.perUser.Allow(key); !allowed {
+					t.Fatalf("per-user bucket %q has no token left", key)
+				}
+			}
+		})
+	}
+}
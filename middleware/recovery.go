@@ -7,6 +7,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/llamacto/llama-gin-kit/pkg/logger"
+	"github.com/llamacto/llama-gin-kit/pkg/requestid"
 	"github.com/llamacto/llama-gin-kit/pkg/response"
 )
 
@@ -15,9 +16,8 @@ func Recovery() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
-				// Log stack trace
-				logger.Error("Panic recovered", fmt.Errorf("%v", err))
-				logger.Debug("Stack trace", string(debug.Stack()))
+				// Log the request ID with the stack trace so a reported ID leads to this entry
+				logger.Error(fmt.Sprintf("Panic recovered request_id=%s\n%s", requestid.Get(c), debug.Stack()), fmt.Errorf("%v", err))
 
 				response.Error(c, http.StatusInternalServerError, "Internal server error")
 				c.Abort()
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/pkg/requestid"
+)
+
+// RequestID assigns every request a correlation ID. A well-formed incoming X-Request-ID is
+// reused, otherwise a UUID is generated. The ID is stored for handlers and logs and echoed
+// in the X-Request-ID response header.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		requestid.Set(c, id)
+		c.Header(requestid.Header, id)
+		c.Next()
+	}
+}
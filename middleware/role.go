@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/app/authorization"
+	"github.com/llamacto/llama-gin-kit/pkg/authctx"
+	"github.com/llamacto/llama-gin-kit/pkg/logger"
+	"github.com/llamacto/llama-gin-kit/pkg/params"
+)
+
+// SuperAdminRole is granted access to every role-protected route
+const SuperAdminRole = "super_admin"
+
+// RequireRole restricts a route to users holding the given global role.
+// Must run after an authentication middleware.
+func RequireRole(authService authorization.Service, role string) gin.HandlerFunc {
+	return RequireAnyRole(authService, role)
+}
+
+// RequireAnyRole restricts a route to users holding at least one of the given global roles,
+// checked with a single lookup. super_admin always passes.
+// Must run after an authentication middleware.
+func RequireAnyRole(authService authorization.Service, roles ...string) gin.HandlerFunc {
+	allowed := append(append([]string{}, roles...), SuperAdminRole)
+
+	return func(c *gin.Context) {
+		id, ok := authctx.UserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"code": 401,
+				"msg":  "User not authenticated",
+			})
+			c.Abort()
+			return
+		}
+
+		has, err := authService.HasAnyRole(c.Request.Context(), id, allowed...)
+		if err != nil {
+			logger.Error("Failed to check user role", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code": 500,
+				"msg":  "Failed to check permissions",
+			})
+			c.Abort()
+			return
+		}
+		if has {
+			c.Next()
+			return
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{
+			"code": 403,
+			"msg":  "Insufficient permissions",
+		})
+		c.Abort()
+	}
+}
+
+// RequirePermission restricts a route to users allowed to perform action on resource,
+// as decided by authorization policies and then role permissions.
+// Must run after an authentication middleware.
+func RequirePermission(authService authorization.Service, resource, action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := authctx.UserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"code": 401,
+				"msg":  "User not authenticated",
+			})
+			c.Abort()
+			return
+		}
+
+		allowed, err := HasPermission(c, authService, id, resource, action)
+		if err != nil {
+			logger.Error("Failed to check user permission", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code": 500,
+				"msg":  "Failed to check permissions",
+			})
+			c.Abort()
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{
+				"code": 403,
+				"msg":  "Insufficient permissions",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireOrganizationPermission restricts a route to users holding the named permission,
+// e.g. "members.update", through their roles in the request's organization.
+// Must run after an authentication middleware and OrganizationContext.
+func RequireOrganizationPermission(authService authorization.Service, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := authctx.UserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"code": 401,
+				"msg":  "User not authenticated",
+			})
+			c.Abort()
+			return
+		}
+		orgID, ok := OrganizationID(c)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": 400,
+				"msg":  "Organization is required",
+			})
+			c.Abort()
+			return
+		}
+
+		allowed, err := authService.CheckOrganizationPermission(c.Request.Context(), id, orgID, permission)
+		if err != nil {
+			logger.Error("Failed to check organization permission", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code": 500,
+				"msg":  "Failed to check permissions",
+			})
+			c.Abort()
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{
+				"code": 403,
+				"msg":  "Insufficient permissions",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireSelfOrOrganizationPermission lets users through to their own record, named by the
+// userParam path parameter, and otherwise behaves like RequireOrganizationPermission.
+// Must run after an authentication middleware and OrganizationContext.
+func RequireSelfOrOrganizationPermission(authService authorization.Service, userParam, permission string) gin.HandlerFunc {
+	requirePermission := RequireOrganizationPermission(authService, permission)
+	return func(c *gin.Context) {
+		if id, ok := authctx.UserID(c); ok {
+			if target, err := params.PathID(c, userParam); err == nil && target == id {
+				c.Next()
+				return
+			}
+		}
+		requirePermission(c)
+	}
+}
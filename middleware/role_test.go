@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/app/authorization"
+	"github.com/llamacto/llama-gin-kit/pkg/authctx"
+)
+
+// roleHolder answers global role checks from the roles one user holds; other methods are left
+// to the embedded nil interface and panic if called
+type roleHolder struct {
+	authorization.Service
+	roles []string
+	calls int
+	err   error
+}
+
+func (s *roleHolder) HasAnyRole(ctx context.Context, userID uint, roleNames ...string) (bool, error) {
+	s.calls++
+	if s.err != nil {
+		return false, s.err
+	}
+	for _, want := range roleNames {
+		for _, held := range s.roles {
+			if held == want {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func TestRequireAnyRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		anonymous   bool
+		roles       []string
+		err         error
+		want        int
+		wantLookups int
+	}{
+		{name: "holds one of the roles", roles: []string{"moderator"}, want: http.StatusOK, wantLookups: 1},
+		{name: "holds several of the roles", roles: []string{"admin", "moderator"}, want: http.StatusOK, wantLookups: 1},
+		{name: "holds none of the roles", roles: []string{"user"}, want: http.StatusForbidden, wantLookups: 1},
+		{name: "super admin", roles: []string{SuperAdminRole}, want: http.StatusOK, wantLookups: 1},
+		{name: "lookup failure", err: errors.New("connection refused"), want: http.StatusInternalServerError, wantLookups: 1},
+		{name: "unauthenticated", anonymous: true, want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authService := &roleHolder{roles: tt.roles, err: tt.err}
+			r := gin.New()
+			if !tt.anonymous {
+				r.Use(func(c *gin.Context) { authctx.SetUserID(c, 1) })
+			}
+			r.GET("/reports", RequireAnyRole(authService, "admin", "moderator"), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/reports", nil))
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if authService.calls != tt.wantLookups {
+				t.Fatalf("role lookups = %d, want %d", authService.calls, tt.wantLookups)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/pkg/authctx"
+)
+
+// RequireScope restricts API-key requests to keys granted scope, e.g. "organizations.read".
+// JWT-authenticated users are not scope-limited and always pass.
+// Must run after an authentication middleware.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authctx.AuthType(c) != authctx.AuthTypeAPIKey {
+			c.Next()
+			return
+		}
+
+		if slices.Contains(authctx.APIKeyScopes(c), scope) {
+			c.Next()
+			return
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{
+			"code": 403,
+			"msg":  "API key is missing required scope: " + scope,
+		})
+		c.Abort()
+	}
+}
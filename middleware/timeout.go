@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/pkg/i18n"
+	"github.com/llamacto/llama-gin-kit/pkg/response"
+)
+
+// Timeout gives every request a deadline d from now. Handlers see it through
+// c.Request.Context(), so database queries run with WithContext(ctx) are cancelled once it
+// passes. A request that has not started its response by the deadline gets 504 instead of
+// whatever the handler writes afterwards; a response already under way is left alone.
+// A non-positive d disables the timeout. Streaming requests, WebSocket upgrades and
+// Server-Sent Events, are long-lived by design and get no deadline.
+//
+// The handler is not run on a separate goroutine, so work that ignores the context still
+// runs to completion before the 504 is sent.
+func Timeout(d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if d <= 0 || isStreaming(c.Request) {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		tw := &timeoutWriter{ResponseWriter: c.Writer, ctx: ctx}
+		c.Writer = tw
+		c.Next()
+		c.Writer = tw.ResponseWriter
+
+		if tw.expired() {
+			response.ErrorKey(c, http.StatusGatewayTimeout, i18n.KeyRequestTimeout)
+			c.Abort()
+		}
+	}
+}
+
+// isStreaming reports whether the request opens a WebSocket or an event stream
+func isStreaming(req *http.Request) bool {
+	if strings.EqualFold(req.Header.Get("Upgrade"), "websocket") {
+		return true
+	}
+	return strings.Contains(req.Header.Get("Accept"), "text/event-stream")
+}
+
+// timeoutWriter discards what the handler writes once the deadline has passed, unless the
+// response had already been started
+type timeoutWriter struct {
+	gin.ResponseWriter
+	ctx      context.Context
+	timedOut bool
+}
+
+// expired reports whether the deadline passed before the response was started
+func (w *timeoutWriter) expired() bool {
+	if !w.timedOut && !w.ResponseWriter.Written() && errors.Is(w.ctx.Err(), context.DeadlineExceeded) {
+		w.timedOut = true
+	}
+	return w.timedOut
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	if !w.expired() {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	if w.expired() {
+		return len(data), nil
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	if w.expired() {
+		return len(s), nil
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *timeoutWriter) Flush() {
+	if !w.expired() {
+		w.ResponseWriter.Flush()
+	}
+}
@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/pkg/dbtest"
+	"gorm.io/gorm"
+)
+
+func TestTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// query runs a statement the database holds until the request context ends
+	query := func(c *gin.Context, gormDB *gorm.DB) error {
+		var n int
+		return gormDB.WithContext(c.Request.Context()).Raw("SELECT pg_sleep(60)").Scan(&n).Error
+	}
+
+	tests := []struct {
+		name       string
+		timeout    time.Duration
+		header     map[string]string
+		handler    func(c *gin.Context, gormDB *gorm.DB) error
+		wantStatus int
+		wantBody   string
+		wantErr    error // Error the handler's query must see
+	}{
+		{
+			name:    "fast handler",
+			timeout: time.Minute,
+			handler: func(c *gin.Context, gormDB *gorm.DB) error {
+				c.String(http.StatusOK, "done")
+				return nil
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "done",
+		},
+		{
+			name:    "deadline cancels the query and drops the late response",
+			timeout: 20 * time.Millisecond,
+			handler: func(c *gin.Context, gormDB *gorm.DB) error {
+				err := query(c, gormDB)
+				c.String(http.StatusOK, "late")
+				return err
+			},
+			wantStatus: http.StatusGatewayTimeout,
+			wantErr:    context.DeadlineExceeded,
+		},
+		{
+			name:    "response started before the deadline",
+			timeout: 20 * time.Millisecond,
+			handler: func(c *gin.Context, gormDB *gorm.DB) error {
+				c.Writer.WriteHeaderNow()
+				c.Writer.Flush()
+				err := query(c, gormDB)
+				_, _ = c.Writer.WriteString("partial")
+				return err
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "partial",
+			wantErr:    context.DeadlineExceeded,
+		},
+		{
+			name:       "WebSocket upgrade gets no deadline",
+			timeout:    20 * time.Millisecond,
+			header:     map[string]string{"Connection": "Upgrade", "Upgrade": "websocket"},
+			handler:    noDeadline,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "event stream gets no deadline",
+			timeout:    20 * time.Millisecond,
+			header:     map[string]string{"Accept": "text/event-stream"},
+			handler:    noDeadline,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "disabled",
+			handler:    noDeadline,
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, db := dbtest.Open(t)
+			started := db.Blocks("pg_sleep")
+
+			var handlerErr error
+			router := gin.New()
+			router.GET("/slow", Timeout(tt.timeout), func(c *gin.Context) {
+				handlerErr = tt.handler(c, gormDB)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/slow", nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Fatalf("body = %q, want %q", w.Body, tt.wantBody)
+			}
+			if strings.Contains(w.Body.String(), "late") {
+				t.Fatalf("body = %q contains the write made after the deadline", w.Body)
+			}
+			if !errors.Is(handlerErr, tt.wantErr) {
+				t.Fatalf("handler error = %v, want %v", handlerErr, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				select {
+				case <-started:
+				default:
+					t.Fatal("the query never reached the database")
+				}
+			}
+		})
+	}
+}
+
+// noDeadline is a handler answering 200 only when the request context has no deadline
+func noDeadline(c *gin.Context, gormDB *gorm.DB) error {
+	if deadline, ok := c.Request.Context().Deadline(); ok {
+		c.String(http.StatusInternalServerError, "deadline set to %s", deadline)
+		return nil
+	}
+	c.Status(http.StatusOK)
+	return nil
+}
@@ -0,0 +1,159 @@
+// Package authctx stores the authenticated caller on a request under typed, namespaced keys.
+package authctx
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/pkg/i18n"
+	"github.com/llamacto/llama-gin-kit/pkg/response"
+)
+
+// Key identifies a value set by the authentication middlewares.
+// As a distinct type it cannot collide with plain string keys in a context.Context.
+type Key string
+
+// Context keys
+const (
+	KeyUserID               Key = "authctx.user_id"
+	KeyUsername             Key = "authctx.username"
+	KeyActiveOrganizationID Key = "authctx.active_organization_id"
+	KeyOrganizationID       Key = "authctx.organization_id"
+	KeyAuthType             Key = "authctx.auth_type"
+	KeyAPIKeyID             Key = "authctx.api_key_id"
+	KeyAPIKeyScopes         Key = "authctx.api_key_scopes"
+	KeyAPIKeyRateLimit      Key = "authctx.api_key_rate_limit"
+)
+
+// Authentication types stored under KeyAuthType
+const (
+	AuthTypeJWT    = "jwt"
+	AuthTypeAPIKey = "api_key"
+)
+
+// legacyKeys are the plain string keys used before this package existed.
+// Getters fall back to them so values set by older middleware are still found.
+var legacyKeys = map[Key]string{
+	KeyUserID:               "userID",
+	KeyUsername:             "username",
+	KeyActiveOrganizationID: "activeOrganizationID",
+	KeyOrganizationID:       "organizationID",
+	KeyAuthType:             "authType",
+	KeyAPIKeyID:             "apiKeyID",
+}
+
+// SetUserID stores the authenticated user's ID
+func SetUserID(c *gin.Context, userID uint) { set(c, KeyUserID, userID) }
+
+// SetUsername stores the authenticated user's username
+func SetUsername(c *gin.Context, username string) { set(c, KeyUsername, username) }
+
+// SetActiveOrganizationID stores the organization the caller's token is scoped to
+func SetActiveOrganizationID(c *gin.Context, organizationID uint) {
+	set(c, KeyActiveOrganizationID, organizationID)
+}
+
+// SetOrganizationID stores the organization the request is scoped to
+func SetOrganizationID(c *gin.Context, organizationID uint) {
+	set(c, KeyOrganizationID, organizationID)
+}
+
+// SetAuthType stores how the caller authenticated, AuthTypeJWT or AuthTypeAPIKey
+func SetAuthType(c *gin.Context, authType string) { set(c, KeyAuthType, authType) }
+
+// SetAPIKeyID stores the ID of the API key the caller authenticated with
+func SetAPIKeyID(c *gin.Context, apiKeyID uint) { set(c, KeyAPIKeyID, apiKeyID) }
+
+// SetAPIKeyScopes stores the scopes granted to the API key the caller authenticated with
+func SetAPIKeyScopes(c *gin.Context, scopes []string) { set(c, KeyAPIKeyScopes, scopes) }
+
+// SetAPIKeyRateLimit stores the requests-per-minute quota of the API key the caller authenticated with
+func SetAPIKeyRateLimit(c *gin.Context, perMinute int) { set(c, KeyAPIKeyRateLimit, perMinute) }
+
+// UserID returns the authenticated user's ID
+func UserID(c *gin.Context) (uint, bool) { return getUint(c, KeyUserID) }
+
+// Username returns the authenticated user's username, or "" when unknown
+func Username(c *gin.Context) string { return getString(c, KeyUsername) }
+
+// ActiveOrganizationID returns the organization the caller's token is scoped to
+func ActiveOrganizationID(c *gin.Context) (uint, bool) { return getUint(c, KeyActiveOrganizationID) }
+
+// OrganizationID returns the organization the request is scoped to
+func OrganizationID(c *gin.Context) (uint, bool) { return getUint(c, KeyOrganizationID) }
+
+// AuthType returns how the caller authenticated, or "" when unknown
+func AuthType(c *gin.Context) string { return getString(c, KeyAuthType) }
+
+// APIKeyID returns the ID of the API key the caller authenticated with
+func APIKeyID(c *gin.Context) (uint, bool) { return getUint(c, KeyAPIKeyID) }
+
+// APIKeyScopes returns the scopes granted to the API key the caller authenticated with
+func APIKeyScopes(c *gin.Context) []string {
+	value, _ := get(c, KeyAPIKeyScopes)
+	scopes, _ := value.([]string)
+	return scopes
+}
+
+// APIKeyRateLimit returns the requests-per-minute quota of the caller's API key; 0 means unlimited
+func APIKeyRateLimit(c *gin.Context) int {
+	value, _ := get(c, KeyAPIKeyRateLimit)
+	perMinute, _ := value.(int)
+	return perMinute
+}
+
+// RequireUserID returns the authenticated user's ID for handlers behind an authentication
+// middleware. When there is none it writes a 401 response and returns false.
+func RequireUserID(c *gin.Context) (uint, bool) {
+	userID, ok := UserID(c)
+	if !ok {
+		response.ErrorKey(c, http.StatusUnauthorized, i18n.KeyUnauthorized)
+		return 0, false
+	}
+	return userID, true
+}
+
+// UserIDFromContext returns the authenticated user's ID from a request context
+func UserIDFromContext(ctx context.Context) (uint, bool) {
+	id, ok := ctx.Value(KeyUserID).(uint)
+	return id, ok
+}
+
+// OrganizationIDFromContext returns the organization the request is scoped to from a request context
+func OrganizationIDFromContext(ctx context.Context) (uint, bool) {
+	id, ok := ctx.Value(KeyOrganizationID).(uint)
+	return id, ok
+}
+
+// set stores value in the gin context and, when there is a request, in its context.Context
+// so code that only receives a context.Context can read it
+func set(c *gin.Context, key Key, value interface{}) {
+	c.Set(string(key), value)
+	if c.Request != nil {
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), key, value))
+	}
+}
+
+// get reads a value by its key, falling back to the legacy string key
+func get(c *gin.Context, key Key) (interface{}, bool) {
+	if value, ok := c.Get(string(key)); ok {
+		return value, true
+	}
+	return c.Get(legacyKeys[key])
+}
+
+func getUint(c *gin.Context, key Key) (uint, bool) {
+	value, ok := get(c, key)
+	if !ok {
+		return 0, false
+	}
+	id, ok := value.(uint)
+	return id, ok
+}
+
+func getString(c *gin.Context, key Key) string {
+	value, _ := get(c, key)
+	s, _ := value.(string)
+	return s
+}
@@ -0,0 +1,133 @@
+package authctx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newContext() *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/", nil)
+	return c
+}
+
+func TestUserID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		set    func(c *gin.Context)
+		wantID uint
+		wantOK bool
+	}{
+		{name: "typed setter", set: func(c *gin.Context) { SetUserID(c, 7) }, wantID: 7, wantOK: true},
+		{name: "legacy string key", set: func(c *gin.Context) { c.Set("userID", uint(8)) }, wantID: 8, wantOK: true},
+		{name: "typed key wins over legacy key", set: func(c *gin.Context) {
+			c.Set("userID", uint(8))
+			SetUserID(c, 7)
+		}, wantID: 7, wantOK: true},
+		{name: "unset", set: func(c *gin.Context) {}},
+		{name: "wrong type", set: func(c *gin.Context) { c.Set("userID", "7") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newContext()
+			tt.set(c)
+
+			id, ok := UserID(c)
+			if id != tt.wantID || ok != tt.wantOK {
+				t.Fatalf("UserID() = %d, %v, want %d, %v", id, ok, tt.wantID, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRequireUserID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		set        func(c *gin.Context)
+		wantID     uint
+		wantOK     bool
+		wantStatus int
+	}{
+		{name: "authenticated", set: func(c *gin.Context) { SetUserID(c, 7) }, wantID: 7, wantOK: true, wantStatus: http.StatusOK},
+		{name: "unauthenticated", set: func(c *gin.Context) {}, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/", nil)
+			tt.set(c)
+
+			id, ok := RequireUserID(c)
+			if id != tt.wantID || ok != tt.wantOK {
+				t.Fatalf("RequireUserID() = %d, %v, want %d, %v", id, ok, tt.wantID, tt.wantOK)
+			}
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestSettersReachRequestContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	c := newContext()
+	SetUserID(c, 7)
+	SetOrganizationID(c, 3)
+
+	ctx := c.Request.Context()
+	if id, ok := UserIDFromContext(ctx); !ok || id != 7 {
+		t.Fatalf("UserIDFromContext() = %d, %v, want 7, true", id, ok)
+	}
+	if id, ok := OrganizationIDFromContext(ctx); !ok || id != 3 {
+		t.Fatalf("OrganizationIDFromContext() = %d, %v, want 3, true", id, ok)
+	}
+
+	// A plain string key with the same text is a different key
+	shadowed := context.WithValue(context.Background(), string(KeyUserID), uint(9))
+	if id, ok := UserIDFromContext(shadowed); ok {
+		t.Fatalf("UserIDFromContext() read %d from a plain string key", id)
+	}
+}
+
+func TestAccessors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	c := newContext()
+	SetUsername(c, "ada")
+	SetActiveOrganizationID(c, 4)
+	SetAuthType(c, AuthTypeAPIKey)
+	SetAPIKeyID(c, 11)
+	SetAPIKeyScopes(c, []string{"users.read"})
+	SetAPIKeyRateLimit(c, 60)
+
+	if got := Username(c); got != "ada" {
+		t.Fatalf("Username() = %q, want ada", got)
+	}
+	if got, ok := ActiveOrganizationID(c); !ok || got != 4 {
+		t.Fatalf("ActiveOrganizationID() = %d, %v, want 4, true", got, ok)
+	}
+	if got := AuthType(c); got != AuthTypeAPIKey {
+		t.Fatalf("AuthType() = %q, want %q", got, AuthTypeAPIKey)
+	}
+	if got, ok := APIKeyID(c); !ok || got != 11 {
+		t.Fatalf("APIKeyID() = %d, %v, want 11, true", got, ok)
+	}
+	if got := APIKeyScopes(c); len(got) != 1 || got[0] != "users.read" {
+		t.Fatalf("APIKeyScopes() = %v, want [users.read]", got)
+	}
+	if got := APIKeyRateLimit(c); got != 60 {
+		t.Fatalf("APIKeyRateLimit() = %d, want 60", got)
+	}
+}
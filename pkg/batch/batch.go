@@ -0,0 +1,78 @@
+// Package batch records the per-item outcome of batch operations in one shape shared by
+// every batch endpoint, so clients can handle partial failures the same way everywhere.
+package batch
+
+// Item statuses
+const (
+	StatusSucceeded = "succeeded"
+	StatusSkipped   = "skipped" // Nothing to do, e.g. the role was already assigned
+	StatusFailed    = "failed"
+)
+
+// Failure codes shared across endpoints; endpoints may define more specific ones
+const (
+	CodeNotFound = "not_found"
+	CodeInternal = "internal_error"
+	CodeAborted  = "aborted" // Valid item not applied because an all-or-nothing batch failed
+)
+
+// Item is the outcome for one element of a batch request
+type Item struct {
+	ID      interface{} `json:"id"` // The identifier the caller sent, e.g. a user ID or an email
+	Status  string      `json:"status"`
+	Code    string      `json:"code,omitempty"`    // Machine-readable reason for skipped and failed items
+	Message string      `json:"message,omitempty"` // Human-readable detail for skipped and failed items
+}
+
+// Summary counts a batch's items by status
+type Summary struct {
+	Total     int `json:"total"`
+	Succeeded int `json:"succeeded"`
+	Skipped   int `json:"skipped"`
+	Failed    int `json:"failed"`
+}
+
+// Result collects the outcome of every item in a batch operation, in request order
+type Result struct {
+	Items   []Item  `json:"items"`
+	Summary Summary `json:"summary"`
+}
+
+// New returns an empty result with room for size items
+func New(size int) *Result {
+	return &Result{Items: make([]Item, 0, size)}
+}
+
+// Succeed records that the item was applied
+func (r *Result) Succeed(id interface{}) {
+	r.add(Item{ID: id, Status: StatusSucceeded})
+	r.Summary.Succeeded++
+}
+
+// Skip records that the item needed no change
+func (r *Result) Skip(id interface{}, code, message string) {
+	r.add(Item{ID: id, Status: StatusSkipped, Code: code, Message: message})
+	r.Summary.Skipped++
+}
+
+// Fail records that the item could not be applied
+func (r *Result) Fail(id interface{}, code, message string) {
+	r.add(Item{ID: id, Status: StatusFailed, Code: code, Message: message})
+	r.Summary.Failed++
+}
+
+// add appends an item and updates the total
+func (r *Result) add(item Item) {
+	r.Items = append(r.Items, item)
+	r.Summary.Total++
+}
+
+// AllFailed reports whether the batch had items and every one of them failed
+func (r *Result) AllFailed() bool {
+	return r.Summary.Total > 0 && r.Summary.Failed == r.Summary.Total
+}
+
+// PartiallyFailed reports whether some, but not all, items failed
+func (r *Result) PartiallyFailed() bool {
+	return r.Summary.Failed > 0 && r.Summary.Failed < r.Summary.Total
+}
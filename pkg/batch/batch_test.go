@@ -0,0 +1,95 @@
+package batch
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestResult(t *testing.T) {
+	tests := []struct {
+		name            string
+		record          func(*Result)
+		wantSummary     Summary
+		wantAllFailed   bool
+		wantPartialFail bool
+	}{
+		{
+			name:        "empty",
+			record:      func(*Result) {},
+			wantSummary: Summary{},
+		},
+		{
+			name: "all succeeded or skipped",
+			record: func(r *Result) {
+				r.Succeed(1)
+				r.Skip(2, "already_assigned", "already assigned")
+			},
+			wantSummary: Summary{Total: 2, Succeeded: 1, Skipped: 1},
+		},
+		{
+			name: "mixed",
+			record: func(r *Result) {
+				r.Succeed(1)
+				r.Skip(2, "already_assigned", "already assigned")
+				r.Fail(3, CodeNotFound, "user not found")
+			},
+			wantSummary:     Summary{Total: 3, Succeeded: 1, Skipped: 1, Failed: 1},
+			wantPartialFail: true,
+		},
+		{
+			name: "all failed",
+			record: func(r *Result) {
+				r.Fail(1, CodeNotFound, "user not found")
+				r.Fail(2, CodeInternal, "internal error")
+			},
+			wantSummary:   Summary{Total: 2, Failed: 2},
+			wantAllFailed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New(3)
+			tt.record(r)
+
+			if r.Summary != tt.wantSummary {
+				t.Fatalf("Summary = %+v, want %+v", r.Summary, tt.wantSummary)
+			}
+			if len(r.Items) != tt.wantSummary.Total {
+				t.Fatalf("len(Items) = %d, want %d", len(r.Items), tt.wantSummary.Total)
+			}
+			if got := r.AllFailed(); got != tt.wantAllFailed {
+				t.Fatalf("AllFailed() = %v, want %v", got, tt.wantAllFailed)
+			}
+			if got := r.PartiallyFailed(); got != tt.wantPartialFail {
+				t.Fatalf("PartiallyFailed() = %v, want %v", got, tt.wantPartialFail)
+			}
+		})
+	}
+}
+
+func TestResultJSON(t *testing.T) {
+	r := New(3)
+	r.Succeed(uint(1))
+	r.Skip("bob@example.com", "already_member", "already a member")
+	r.Fail(uint(3), CodeNotFound, "user not found")
+
+	got, err := json.Marshal(r)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	want := `{"items":[` +
+		`{"id":1,"status":"succeeded"},` +
+		`{"id":"bob@example.com","status":"skipped","code":"already_member","message":"already a member"},` +
+		`{"id":3,"status":"failed","code":"not_found","message":"user not found"}` +
+		`],"summary":{"total":3,"succeeded":1,"skipped":1,"failed":1}}`
+	if string(got) != want {
+		t.Fatalf("JSON = %s\nwant %s", got, want)
+	}
+
+	// An empty batch still has an items array, never null
+	got, _ = json.Marshal(New(0))
+	if want := `{"items":[],"summary":{"total":0,"succeeded":0,"skipped":0,"failed":0}}`; string(got) != want {
+		t.Fatalf("empty JSON = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/config"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// sleep waits between connection attempts; replaced in tests
+var sleep = time.Sleep
+
+// connect opens the connection pool and checks that the database answers
+func connect(cfg config.DatabaseConfig, dsn string, gormLogger logger.Interface) (*gorm.DB, error) {
+	db, err := gorm.Open(postgres.New(postgres.Config{
+		DSN:                  dsn,
+		PreferSimpleProtocol: true, // disables implicit prepared statement usage
+	}), &gorm.Config{
+		Logger: gormLogger,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database instance: %w", err)
+	}
+
+	// Set connection pool
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetConnMaxLifetime(0) // Disable connection max lifetime
+
+	// Check if we can connect to the database
+	if err := sqlDB.Ping(); err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return db, nil
+}
+
+// connectWithRetry calls connect until it succeeds, waiting between attempts with exponential
+// backoff. It gives up after cfg.ConnectMaxAttempts attempts, or when the next attempt would
+// start after cfg.ConnectTimeout, and returns the last connection error.
+func connectWithRetry(cfg config.DatabaseConfig, connect func() (*gorm.DB, error)) (*gorm.DB, error) {
+	start := time.Now()
+	delay := cfg.ConnectBackoff
+
+	for attempt := 1; ; attempt++ {
+		db, err := connect()
+		if err == nil {
+			if attempt > 1 {
+				log.Printf("Connected to database after %d attempts", attempt)
+			}
+			return db, nil
+		}
+
+		if attempt >= cfg.ConnectMaxAttempts {
+			return nil, fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+		if cfg.ConnectTimeout > 0 && time.Since(start)+delay > cfg.ConnectTimeout {
+			return nil, fmt.Errorf("giving up after %v and %d attempts: %w", time.Since(start).Round(time.Second), attempt, err)
+		}
+
+		log.Printf("Database not ready (attempt %d of %d): %v; retrying in %v", attempt, cfg.ConnectMaxAttempts, err, delay)
+		sleep(delay)
+
+		delay *= 2
+		if cfg.ConnectMaxBackoff > 0 && delay > cfg.ConnectMaxBackoff {
+			delay = cfg.ConnectMaxBackoff
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/pkg/metrics"
+	"gorm.io/gorm"
+)
+
+// SlowQueryThreshold is the duration above which a query is logged as slow and counted in
+// db_slow_queries_total
+const SlowQueryThreshold = time.Second
+
+var (
+	queriesTotal = metrics.NewCounterVec("db_queries_total",
+		"Database queries executed, by operation and table.", "operation", "table")
+	queryErrorsTotal = metrics.NewCounterVec("db_query_errors_total",
+		"Database queries that failed, by operation and table. Record-not-found is not an error.", "operation", "table")
+	slowQueriesTotal = metrics.NewCounterVec("db_slow_queries_total",
+		"Database queries slower than the slow query threshold, by operation and table.", "operation", "table")
+	queryDuration = metrics.NewHistogramVec("db_query_duration_seconds",
+		"Database query duration in seconds, by operation.", metrics.DefaultBuckets, "operation")
+)
+
+// queryStartKey is the statement instance key holding a query's start time
+const queryStartKey = "metrics:query_start"
+
+// QueryMetrics is a GORM plugin recording the count, duration and errors of every query
+type QueryMetrics struct {
+	SlowThreshold time.Duration
+}
+
+// Name implements gorm.Plugin
+func (p *QueryMetrics) Name() string {
+	return "query_metrics"
+}
+
+// Initialize implements gorm.Plugin by wrapping each callback chain with timing callbacks
+func (p *QueryMetrics) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	chains := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", callbacks.Create().Before("*").Register, callbacks.Create().After("*").Register},
+		{"query", callbacks.Query().Before("*").Register, callbacks.Query().After("*").Register},
+		{"update", callbacks.Update().Before("*").Register, callbacks.Update().After("*").Register},
+		{"delete", callbacks.Delete().Before("*").Register, callbacks.Delete().After("*").Register},
+		{"row", callbacks.Row().Before("*").Register, callbacks.Row().After("*").Register},
+		{"raw", callbacks.Raw().Before("*").Register, callbacks.Raw().After("*").Register},
+	}
+	for _, chain := range chains {
+		if err := chain.before("metrics:before_"+chain.operation, startQuery); err != nil {
+			return err
+		}
+		if err := chain.after("metrics:after_"+chain.operation, p.finishQuery(chain.operation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startQuery records when the statement started
+func startQuery(db *gorm.DB) {
+	db.InstanceSet(queryStartKey, time.Now())
+}
+
+// finishQuery returns a callback recording the statement's metrics under operation
+func (p *QueryMetrics) finishQuery(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(queryStartKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+		elapsed := time.Since(start)
+
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+
+		queriesTotal.Inc(operation, table)
+		queryDuration.Observe(elapsed.Seconds(), operation)
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			queryErrorsTotal.Inc(operation, table)
+		}
+		if p.SlowThreshold > 0 && elapsed > p.SlowThreshold {
+			slowQueriesTotal.Inc(operation, table)
+		}
+	}
+}
@@ -1,43 +1,71 @@
 package database
 
 import (
+	"fmt"
 	"log"
+	"sort"
 	"time"
 
 	"github.com/go-gormigrate/gormigrate/v2"
-	"github.com/llamacto/llama-gin-kit/app/user"
+	"github.com/llamacto/llama-gin-kit/pkg/database/migrations"
 	"gorm.io/gorm"
 )
 
-// RunMigrations runs all migrations for the application
-func RunMigrations(db *gorm.DB) error {
-	log.Println("Starting database migrations")
-	startTime := time.Now()
-
-	// Collect all migrations from different modules
-	allMigrations := []*gormigrate.Migration{}
+// migrationGroups lists the migrations of every module. A new module adds its group here;
+// the order of groups does not matter because Migrations merges them by ID.
+var migrationGroups = []struct {
+	module     string
+	migrations func() []*gormigrate.Migration
+}{
+	{"core", migrations.CoreMigrations},
+	{"user", migrations.UserMigrations},
+	{"authorization", migrations.AuthorizationMigrations},
+	{"organization", migrations.OrganizationMigrations},
+	{"apikey", migrations.APIKeyMigrations},
+	{"tts", migrations.TTSMigrations},
+}
 
-	// Add user migrations
-	userMigrations := getUserMigrations()
-	allMigrations = append(allMigrations, userMigrations...)
+// Migrations returns the migrations of every module in apply order, sorted by ID. IDs
+// start with the date the migration was written, so this is the order they were added in,
+// and rolling back walks the same list in reverse. An empty or duplicate ID is an error.
+func Migrations() ([]*gormigrate.Migration, error) {
+	var all []*gormigrate.Migration
+	owners := make(map[string]string)
+	for _, group := range migrationGroups {
+		for _, m := range group.migrations() {
+			if m.ID == "" {
+				return nil, fmt.Errorf("%s migration has an empty ID", group.module)
+			}
+			if owner, ok := owners[m.ID]; ok {
+				return nil, fmt.Errorf("migration ID %q is used by both %s and %s", m.ID, owner, group.module)
+			}
+			owners[m.ID] = group.module
+			all = append(all, m)
+		}
+	}
 
-	// Add API key migrations (temporarily disabled)
-	// apiKeyMigration := migrations.CreateAPIKeysTable()
-	// allMigrations = append(allMigrations, apiKeyMigration)
+	sort.SliceStable(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+	return all, nil
+}
 
-	// Add organization migrations
-	// orgMigrations := organization.GetMigrations()
-	// allMigrations = append(allMigrations, orgMigrations...)
+// newMigrator returns a migrator over every module's migrations
+func newMigrator(db *gorm.DB) (*gormigrate.Gormigrate, error) {
+	all, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	return gormigrate.New(db, gormigrate.DefaultOptions, all), nil
+}
 
-	// Initialize the migrator with all collected migrations
-	m := gormigrate.New(db, &gormigrate.Options{
-		TableName:      "migrations",
-		IDColumnName:   "id",
-		IDColumnSize:   255,
-		UseTransaction: true,
-	}, allMigrations)
+// RunMigrations applies every pending migration
+func RunMigrations(db *gorm.DB) error {
+	log.Println("Starting database migrations")
+	startTime := time.Now()
 
-	// Execute migrations
+	m, err := newMigrator(db)
+	if err != nil {
+		return err
+	}
 	if err := m.Migrate(); err != nil {
 		log.Printf("Migration failed: %v", err)
 		return err
@@ -46,44 +74,3 @@ func RunMigrations(db *gorm.DB) error {
 	log.Printf("Migration completed successfully in %v", time.Since(startTime))
 	return nil
 }
-
-// getUserMigrations returns migrations for the user module
-func getUserMigrations() []*gormigrate.Migration {
-	return []*gormigrate.Migration{
-		{
-			ID: "202506180_create_users",
-			Migrate: func(db *gorm.DB) error {
-				return db.AutoMigrate(&user.User{})
-			},
-			Rollback: func(db *gorm.DB) error {
-				return db.Migrator().DropTable("users")
-			},
-		},
-		{
-			ID: "202506181_create_default_users",
-			Migrate: func(db *gorm.DB) error {
-				// Create a default admin user if none exists
-				var count int64
-				db.Model(&user.User{}).Count(&count)
-
-				if count == 0 {
-					adminUser := &user.User{
-						Username: "admin",
-						Email:    "admin@example.com",
-						Password: "hashed_password_here", // In a real app, this should be properly hashed
-						Nickname: "Admin User",
-						Status:   1, // 1: active, 0: disabled
-					}
-
-					result := db.Create(adminUser)
-					return result.Error
-				}
-
-				return nil
-			},
-			Rollback: func(db *gorm.DB) error {
-				return db.Where("username = ?", "admin").Delete(&user.User{}).Error
-			},
-		},
-	}
-}
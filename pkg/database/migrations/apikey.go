@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/llamacto/llama-gin-kit/app/apikey"
+	"gorm.io/gorm"
+)
+
+// APIKeyMigrations returns the migrations of the API key module. The api_keys table itself
+// is created by the initial schema in CoreMigrations.
+func APIKeyMigrations() []*gormigrate.Migration {
+	return []*gormigrate.Migration{
+		{
+			ID: "20250629_api_key_scopes",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&apikey.APIKey{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropColumn(&apikey.APIKey{}, "scopes")
+			},
+		},
+		{
+			ID: "20250701_api_key_rate_limit",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&apikey.APIKey{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropColumn(&apikey.APIKey{}, "rate_limit")
+			},
+		},
+	}
+}
@@ -0,0 +1,95 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/llamacto/llama-gin-kit/app/authorization"
+	"gorm.io/gorm"
+)
+
+// AuthorizationMigrations returns the migrations of the authorization module
+func AuthorizationMigrations() []*gormigrate.Migration {
+	return []*gormigrate.Migration{
+		{
+			ID: "20250621_authorization_schema",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(
+					&authorization.Role{},
+					&authorization.Permission{},
+					&authorization.UserRole{},
+					&authorization.OrganizationRole{},
+					&authorization.TeamRole{},
+					&authorization.Policy{},
+				)
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable(
+					&authorization.Policy{},
+					&authorization.TeamRole{},
+					&authorization.OrganizationRole{},
+					&authorization.UserRole{},
+					"role_permissions",
+					&authorization.Permission{},
+					&authorization.Role{},
+				)
+			},
+		},
+		{
+			ID: "20250622_auth_audit_logs",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&authorization.AuthAuditLog{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable(&authorization.AuthAuditLog{})
+			},
+		},
+		{
+			ID: "20250624_auth_audit_logs_target_index",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&authorization.AuthAuditLog{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropIndex(&authorization.AuthAuditLog{}, "idx_auth_audit_logs_target")
+			},
+		},
+		{
+			ID: "20250628_policy_conditions",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&authorization.Policy{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				for _, column := range []string{"conditions", "priority", "status"} {
+					if err := tx.Migrator().DropColumn(&authorization.Policy{}, column); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			ID: "20250706_auth_audit_logs_organization",
+			Migrate: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&authorization.AuthAuditLog{}); err != nil {
+					return err
+				}
+				// Attribute existing entries to their organization where the entry identifies it
+				backfill := []string{
+					`UPDATE auth_audit_logs SET organization_id = target_id
+					WHERE organization_id IS NULL AND target_type = 'organization'`,
+					`UPDATE auth_audit_logs SET organization_id = (diff->>'organization_id')::bigint
+					WHERE organization_id IS NULL AND diff->>'organization_id' IS NOT NULL`,
+					`UPDATE auth_audit_logs l SET organization_id = t.organization_id FROM teams t
+					WHERE l.organization_id IS NULL AND l.diff->>'team_id' IS NOT NULL AND t.id = (l.diff->>'team_id')::bigint`,
+				}
+				for _, stmt := range backfill {
+					if err := tx.Exec(stmt).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropColumn(&authorization.AuthAuditLog{}, "organization_id")
+			},
+		},
+	}
+}
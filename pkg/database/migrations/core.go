@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/llamacto/llama-gin-kit/app/apikey"
+	"github.com/llamacto/llama-gin-kit/app/member"
+	"github.com/llamacto/llama-gin-kit/app/organization"
+	"github.com/llamacto/llama-gin-kit/app/team"
+	"github.com/llamacto/llama-gin-kit/app/user"
+	"gorm.io/gorm"
+)
+
+// CoreMigrations returns the initial schema shared by several modules, created together
+// before migrations were split by module
+func CoreMigrations() []*gormigrate.Migration {
+	return []*gormigrate.Migration{
+		{
+			ID: "20250620_initial_schema",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(
+					&user.User{},
+					&organization.Organization{},
+					&team.Team{},
+					&apikey.APIKey{},
+					&member.Member{},
+				)
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable(
+					&member.Member{},
+					&apikey.APIKey{},
+					&team.Team{},
+					&organization.Organization{},
+					&user.User{},
+				)
+			},
+		},
+	}
+}
@@ -0,0 +1,106 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/llamacto/llama-gin-kit/app/invitation"
+	"github.com/llamacto/llama-gin-kit/app/member"
+	"github.com/llamacto/llama-gin-kit/app/organization"
+	"github.com/llamacto/llama-gin-kit/app/orgdomain"
+	"github.com/llamacto/llama-gin-kit/app/team"
+	"gorm.io/gorm"
+)
+
+// OrganizationMigrations returns the migrations of the organization module and the
+// modules built on it: members, teams, invitations and domains
+func OrganizationMigrations() []*gormigrate.Migration {
+	return []*gormigrate.Migration{
+		{
+			ID: "20250623_member_role_id",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&member.Member{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropColumn(&member.Member{}, "role_id")
+			},
+		},
+		{
+			ID: "20250625_organization_invitations",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&invitation.Invitation{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable(&invitation.Invitation{})
+			},
+		},
+		{
+			ID: "20250626_member_role_history",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&member.MemberRoleHistory{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable(&member.MemberRoleHistory{})
+			},
+		},
+		{
+			ID: "20250627_organization_owner",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&organization.Organization{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropColumn(&organization.Organization{}, "owner_id")
+			},
+		},
+		{
+			ID: "20250630_invitation_callbacks",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&invitation.Invitation{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				for _, column := range []string{"redirect_url", "callback_url"} {
+					if err := tx.Migrator().DropColumn(&invitation.Invitation{}, column); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			ID: "20250702_organization_domains",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&orgdomain.Domain{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable(&orgdomain.Domain{})
+			},
+		},
+		{
+			ID: "20250704_organization_inherit_team_permissions",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&organization.Organization{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropColumn(&organization.Organization{}, "inherit_team_permissions")
+			},
+		},
+		{
+			ID: "20250707_organization_team_settings",
+			Migrate: func(tx *gorm.DB) error {
+				for _, model := range []interface{}{&organization.Organization{}, &team.Team{}} {
+					if tx.Migrator().HasColumn(model, "Settings") {
+						continue
+					}
+					if err := tx.Migrator().AddColumn(model, "Settings"); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Rollback: func(tx *gorm.DB) error {
+				if err := tx.Migrator().DropColumn(&team.Team{}, "settings"); err != nil {
+					return err
+				}
+				return tx.Migrator().DropColumn(&organization.Organization{}, "settings")
+			},
+		},
+	}
+}
@@ -2,18 +2,55 @@ package migrations
 
 import (
 	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/llamacto/llama-gin-kit/app/tts"
 	"gorm.io/gorm"
 )
 
-// TTS migrations for TTS module
-var TTSMigrations = []*gormigrate.Migration{
-	{
-		ID: "202504200001_add_voice_to_tts_history",
-		Migrate: func(tx *gorm.DB) error {
-			return tx.Exec("ALTER TABLE tts_audio_history ADD COLUMN voice VARCHAR(50) DEFAULT 'alloy'").Error
+// TTSMigrations returns the migrations of the TTS module
+func TTSMigrations() []*gormigrate.Migration {
+	return []*gormigrate.Migration{
+		{
+			ID: "20250708_tts_jobs",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&tts.Job{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable(&tts.Job{})
+			},
 		},
-		Rollback: func(tx *gorm.DB) error {
-			return tx.Exec("ALTER TABLE tts_audio_history DROP COLUMN voice").Error
+		{
+			ID: "20250709_tts_job_attempts",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&tts.Job{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropColumn(&tts.Job{}, "attempts")
+			},
 		},
-	},
+		{
+			// Audio is served through signed links, so jobs keep the storage key rather than a public URL
+			ID: "20250710_tts_job_audio_key",
+			Migrate: func(tx *gorm.DB) error {
+				if !tx.Migrator().HasColumn(&tts.Job{}, "audio_key") {
+					if err := tx.Migrator().AddColumn(&tts.Job{}, "AudioKey"); err != nil {
+						return err
+					}
+				}
+				if !tx.Migrator().HasColumn(&tts.Job{}, "audio_url") {
+					return nil
+				}
+				if err := tx.Exec(`UPDATE tts_jobs SET audio_key = substring(audio_url from 'tts/[0-9]+/[^/]+\.mp3$')
+					WHERE audio_url <> ''`).Error; err != nil {
+					return err
+				}
+				return tx.Migrator().DropColumn(&tts.Job{}, "audio_url")
+			},
+			Rollback: func(tx *gorm.DB) error {
+				if err := tx.Exec(`ALTER TABLE tts_jobs ADD COLUMN IF NOT EXISTS audio_url VARCHAR(500)`).Error; err != nil {
+					return err
+				}
+				return tx.Migrator().DropColumn(&tts.Job{}, "audio_key")
+			},
+		},
+	}
 }
@@ -0,0 +1,57 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/llamacto/llama-gin-kit/app/user"
+	"gorm.io/gorm"
+)
+
+// UserMigrations returns the migrations of the user module
+func UserMigrations() []*gormigrate.Migration {
+	return []*gormigrate.Migration{
+		{
+			ID: "202506180_create_users",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&user.User{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable("users")
+			},
+		},
+		{
+			ID: "20250703_password_reset_tokens",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&user.PasswordResetToken{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable(&user.PasswordResetToken{})
+			},
+		},
+		{
+			ID: "20250705_user_last_login_ip",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&user.User{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropColumn(&user.User{}, "last_login_ip")
+			},
+		},
+		{
+			ID: "20250710_user_two_factor",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&user.User{}, &user.RecoveryCode{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				if err := tx.Migrator().DropTable(&user.RecoveryCode{}); err != nil {
+					return err
+				}
+				for _, column := range []string{"two_factor_enabled", "two_factor_secret", "two_factor_last_step"} {
+					if err := tx.Migrator().DropColumn(&user.User{}, column); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+	}
+}
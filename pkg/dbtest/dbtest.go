@@ -0,0 +1,230 @@
+// Package dbtest opens GORM sessions on an in-process database/sql driver, so repositories
+// can be tested without a Postgres server. The driver records every statement it receives
+// and answers from rules the test registers: queries return no rows and execs affect one row
+// unless a rule says otherwise. SQL is generated by the real Postgres dialector, so tests see
+// the same statements the server would.
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Statement is one query or exec sent to the database. Transaction control is recorded as
+// the statements BEGIN, COMMIT and ROLLBACK.
+type Statement struct {
+	SQL  string
+	Args []driver.Value
+}
+
+// rule scripts the answer to statements whose SQL contains fragment
+type rule struct {
+	fragment string
+	columns  []string
+	rows     [][]driver.Value
+	affected int64
+	err      error
+	started  chan struct{}
+}
+
+// DB is the database behind a session opened by Open
+type DB struct {
+	mu         sync.Mutex
+	statements []Statement
+	rules      []*rule
+}
+
+// Open returns a GORM session backed by a new DB, closed when the test ends
+func Open(t testing.TB) (*gorm.DB, *DB) {
+	t.Helper()
+
+	d := &DB{}
+	sqlDB := sql.OpenDB(connector{db: d})
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	return db, d
+}
+
+// Returns makes queries containing fragment return the given rows
+func (d *DB) Returns(fragment string, columns []string, rows ...[]driver.Value) {
+	d.addRule(&rule{fragment: fragment, columns: columns, rows: rows})
+}
+
+// Affects makes execs containing fragment report n affected rows
+func (d *DB) Affects(fragment string, n int64) {
+	d.addRule(&rule{fragment: fragment, affected: n})
+}
+
+// Fails makes statements containing fragment return err
+func (d *DB) Fails(fragment string, err error) {
+	d.addRule(&rule{fragment: fragment, err: err})
+}
+
+// Blocks makes statements containing fragment wait until their context ends and return its
+// error. The returned channel is closed when the first such statement starts.
+func (d *DB) Blocks(fragment string) <-chan struct{} {
+	started := make(chan struct{})
+	d.addRule(&rule{fragment: fragment, started: started})
+	return started
+}
+
+// Statements returns every statement received so far, in order
+func (d *DB) Statements() []Statement {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]Statement(nil), d.statements...)
+}
+
+// Find returns the first statement whose SQL contains fragment
+func (d *DB) Find(fragment string) (Statement, bool) {
+	for _, stmt := range d.Statements() {
+		if strings.Contains(stmt.SQL, fragment) {
+			return stmt, true
+		}
+	}
+	return Statement{}, false
+}
+
+func (d *DB) addRule(r *rule) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.rules = append(d.rules, r)
+}
+
+// receive records a statement and returns the first rule matching it, if any
+func (d *DB) receive(query string, args []driver.NamedValue) *rule {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.statements = append(d.statements, Statement{SQL: query, Args: values})
+	for _, r := range d.rules {
+		if strings.Contains(query, r.fragment) {
+			return r
+		}
+	}
+	return nil
+}
+
+// wait applies a blocking rule: it reports the start and waits for ctx to end
+func (r *rule) wait(ctx context.Context) error {
+	if r.started == nil {
+		return r.err
+	}
+	select {
+	case <-r.started:
+	default:
+		close(r.started)
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+type connector struct {
+	db *DB
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) { return &conn{db: c.db}, nil }
+func (c connector) Driver() driver.Driver                        { return testDriver{} }
+
+type testDriver struct{}
+
+func (testDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("dbtest: use sql.OpenDB with a connector")
+}
+
+type conn struct {
+	db *DB
+}
+
+func (c *conn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("dbtest: prepared statements are not supported")
+}
+
+func (c *conn) Close() error { return nil }
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(ctx context.Context, _ driver.TxOptions) (driver.Tx, error) {
+	if r := c.db.receive("BEGIN", nil); r != nil {
+		if err := r.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return tx{db: c.db}, nil
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	r := c.db.receive(query, args)
+	if r == nil {
+		return &rows{}, nil
+	}
+	if err := r.wait(ctx); err != nil {
+		return nil, err
+	}
+	return &rows{columns: r.columns, values: r.rows}, nil
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	r := c.db.receive(query, args)
+	if r == nil {
+		return driver.RowsAffected(1), nil
+	}
+	if err := r.wait(ctx); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(r.affected), nil
+}
+
+type tx struct {
+	db *DB
+}
+
+func (t tx) Commit() error {
+	t.db.receive("COMMIT", nil)
+	return nil
+}
+
+func (t tx) Rollback() error {
+	t.db.receive("ROLLBACK", nil)
+	return nil
+}
+
+type rows struct {
+	columns []string
+	values  [][]driver.Value
+	next    int
+}
+
+func (r *rows) Columns() []string { return r.columns }
+func (r *rows) Close() error      { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.next >= len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.next])
+	r.next++
+	return nil
+}
@@ -0,0 +1,30 @@
+package email
+
+import (
+	"fmt"
+
+	"github.com/llamacto/llama-gin-kit/pkg/logger"
+)
+
+// LogSender writes emails to the application log instead of sending them, so local
+// development works without real credentials
+type LogSender struct{}
+
+// NewLogSender creates a logging sender
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+// Send implements EmailSender
+func (s *LogSender) Send(to []string, subject, htmlBody, textBody string) error {
+	body := textBody
+	if body == "" {
+		body = htmlBody
+	}
+	logger.Info("Email not sent (log provider)",
+		fmt.Sprintf("to: %v", to),
+		fmt.Sprintf("subject: %s", subject),
+		fmt.Sprintf("body:\n%s", body),
+	)
+	return nil
+}
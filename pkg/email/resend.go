@@ -0,0 +1,136 @@
+package email
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/config"
+	"github.com/llamacto/llama-gin-kit/pkg/logger"
+)
+
+const resendEndpoint = "https://api.resend.com/emails"
+
+type EmailRequest struct {
+	From    string   `json:"from"`
+	To      []string `json:"to"`
+	Subject string   `json:"subject"`
+	Html    string   `json:"html"`
+	Text    string   `json:"text,omitempty"`
+}
+
+type EmailResponse struct {
+	ID      string `json:"id"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Created string `json:"created"`
+	Error   string `json:"error"`
+}
+
+// ResendSender sends email through the Resend HTTP API
+type ResendSender struct {
+	from   string
+	apiKey string
+	client *http.Client
+}
+
+// NewResendSender creates a Resend sender
+func NewResendSender(cfg config.EmailConfig) (*ResendSender, error) {
+	if cfg.ResendAPIKey == "" {
+		return nil, fmt.Errorf("EMAIL_RESEND_API_KEY is required for the resend email provider")
+	}
+	return &ResendSender{
+		from:   cfg.From,
+		apiKey: cfg.ResendAPIKey,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Send implements EmailSender
+func (s *ResendSender) Send(to []string, subject, htmlBody, textBody string) error {
+	logger.Info("Preparing to send email",
+		fmt.Sprintf("from: %s", s.from),
+		fmt.Sprintf("to: %v", to),
+		fmt.Sprintf("subject: %s", subject),
+	)
+
+	reqBody := EmailRequest{
+		From:    s.from,
+		To:      to,
+		Subject: subject,
+		Html:    htmlBody,
+		Text:    textBody,
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		logger.Error("Failed to serialize request", err)
+		return fmt.Errorf("failed to marshal email request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", resendEndpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		logger.Error("Failed to create request", err)
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		logger.Error("Failed to send request", err)
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logger.Error("Failed to read response", err)
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusForbidden {
+		var resendError struct {
+			Name       string `json:"name"`
+			Message    string `json:"message"`
+			StatusCode int    `json:"statusCode"`
+		}
+		if err := json.Unmarshal(body, &resendError); err != nil {
+			logger.Error("Failed to parse error response", err)
+			return fmt.Errorf("failed to unmarshal error response: %w", err)
+		}
+		logger.Error("Resend API error", fmt.Errorf("%s: %s (status %d)",
+			resendError.Name,
+			resendError.Message,
+			resendError.StatusCode,
+		))
+		if resendError.Name == "validation_error" && strings.Contains(resendError.Message, "domain is not verified") {
+			return fmt.Errorf("recipient domain not verified, please contact admin to add domain verification")
+		}
+		return fmt.Errorf("Resend API error: %s", resendError.Message)
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		logger.Error("Email sending failed", fmt.Errorf("status code %d, response: %s", resp.StatusCode, string(body)))
+		return fmt.Errorf("failed to send email: status code %d, response: %s", resp.StatusCode, string(body))
+	}
+
+	var emailResp EmailResponse
+	if err := json.Unmarshal(body, &emailResp); err != nil {
+		logger.Error("Failed to parse response", err)
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if emailResp.Error != "" {
+		logger.Error("Email service error", fmt.Errorf("%s", emailResp.Error))
+		return fmt.Errorf("email service error: %s", emailResp.Error)
+	}
+
+	logger.Info("Email sent successfully", fmt.Sprintf("ID: %s", emailResp.ID))
+	return nil
+}
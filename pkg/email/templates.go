@@ -0,0 +1,264 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/config"
+)
+
+// Template names registered by default
+const (
+	TemplateInvitation    = "invitation"
+	TemplatePasswordReset = "password_reset"
+	TemplateVerification  = "verification"
+	TemplateWelcome       = "welcome"
+)
+
+const defaultAppName = "Llama Gin Kit"
+
+//go:embed templates/*
+var templateFS embed.FS
+
+// Rendered is an email body ready to Send
+type Rendered struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Registry holds named email templates rendered inside a shared layout.
+//
+// Each template is an HTML source defining "content" and a plain-text source
+// defining "subject" and "content". The layouts wrap "content" for their format.
+// Templates may call appName for the configured application name, and referencing
+// a key missing from map data is an error rather than an empty string.
+type Registry struct {
+	mu         sync.RWMutex
+	layoutHTML *htmltemplate.Template
+	layoutText *texttemplate.Template
+	templates  map[string]*registeredTemplate
+}
+
+type registeredTemplate struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+// templateFuncs are available to every layout and template
+var templateFuncs = map[string]any{
+	"appName": appName,
+}
+
+// NewRegistry creates a registry whose templates are wrapped in the given layouts.
+// Both layouts must define "layout" and call {{template "content" .}}.
+func NewRegistry(layoutHTML, layoutText string) (*Registry, error) {
+	html, err := htmltemplate.New("layout.html").Funcs(templateFuncs).Option("missingkey=error").Parse(layoutHTML)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML layout: %w", err)
+	}
+	text, err := texttemplate.New("layout.txt").Funcs(templateFuncs).Option("missingkey=error").Parse(layoutText)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse text layout: %w", err)
+	}
+	return &Registry{
+		layoutHTML: html,
+		layoutText: text,
+		templates:  make(map[string]*registeredTemplate),
+	}, nil
+}
+
+// LoadRegistry builds a registry from layout.html and layout.txt in fsys plus one
+// template per <name>.html and <name>.txt pair
+func LoadRegistry(fsys fs.FS) (*Registry, error) {
+	read := func(name string) (string, error) {
+		data, err := fs.ReadFile(fsys, name)
+		return string(data), err
+	}
+
+	layoutHTML, err := read("layout.html")
+	if err != nil {
+		return nil, err
+	}
+	layoutText, err := read("layout.txt")
+	if err != nil {
+		return nil, err
+	}
+	registry, err := NewRegistry(layoutHTML, layoutText)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := fs.Glob(fsys, "*.html")
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		name := strings.TrimSuffix(path.Base(file), ".html")
+		if name == "layout" {
+			continue
+		}
+		htmlSource, err := read(file)
+		if err != nil {
+			return nil, err
+		}
+		textSource, err := read(name + ".txt")
+		if err != nil {
+			return nil, fmt.Errorf("email template %q has no plain-text version: %w", name, err)
+		}
+		if err := registry.Register(name, htmlSource, textSource); err != nil {
+			return nil, err
+		}
+	}
+	return registry, nil
+}
+
+// Register adds or replaces the template called name
+func (r *Registry) Register(name, htmlSource, textSource string) error {
+	html, err := htmltemplate.Must(r.layoutHTML.Clone()).Parse(htmlSource)
+	if err != nil {
+		return fmt.Errorf("failed to parse HTML for email template %q: %w", name, err)
+	}
+	text, err := texttemplate.Must(r.layoutText.Clone()).Parse(textSource)
+	if err != nil {
+		return fmt.Errorf("failed to parse text for email template %q: %w", name, err)
+	}
+	if html.Lookup("content") == nil || text.Lookup("content") == nil {
+		return fmt.Errorf("email template %q must define \"content\" in both HTML and text", name)
+	}
+	if text.Lookup("subject") == nil {
+		return fmt.Errorf("email template %q must define \"subject\" in its text version", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.templates[name] = &registeredTemplate{html: html, text: text}
+	return nil
+}
+
+// Render renders the template called name with data
+func (r *Registry) Render(name string, data any) (*Rendered, error) {
+	r.mu.RLock()
+	tmpl, ok := r.templates[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown email template %q", name)
+	}
+
+	var subject, html, text bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, fmt.Errorf("failed to render subject of email template %q: %w", name, err)
+	}
+	if err := tmpl.html.ExecuteTemplate(&html, "layout", data); err != nil {
+		return nil, fmt.Errorf("failed to render email template %q: %w", name, err)
+	}
+	if err := tmpl.text.ExecuteTemplate(&text, "layout", data); err != nil {
+		return nil, fmt.Errorf("failed to render email template %q: %w", name, err)
+	}
+
+	return &Rendered{
+		// Subjects become a header, so collapse any line breaks from the data
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		HTML:    html.String(),
+		Text:    text.String(),
+	}, nil
+}
+
+// Names returns the registered template names in sorted order
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.templates))
+	for name := range r.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// defaultRegistry holds the templates embedded from the templates directory
+var defaultRegistry = mustLoadDefaultRegistry()
+
+func mustLoadDefaultRegistry() *Registry {
+	sub, err := fs.Sub(templateFS, "templates")
+	if err != nil {
+		panic(err)
+	}
+	registry, err := LoadRegistry(sub)
+	if err != nil {
+		panic(fmt.Sprintf("email: failed to load templates: %v", err))
+	}
+	return registry
+}
+
+// Register adds or replaces a template in the default registry
+func Register(name, htmlSource, textSource string) error {
+	return defaultRegistry.Register(name, htmlSource, textSource)
+}
+
+// Render renders a template from the default registry
+func Render(name string, data any) (*Rendered, error) {
+	return defaultRegistry.Render(name, data)
+}
+
+// TemplateNames returns the templates in the default registry
+func TemplateNames() []string {
+	return defaultRegistry.Names()
+}
+
+// SendTemplate renders a template from the default registry and sends it to one recipient
+func SendTemplate(to string, name string, data any) error {
+	rendered, err := Render(name, data)
+	if err != nil {
+		return err
+	}
+	return Send([]string{to}, rendered.Subject, rendered.HTML, rendered.Text)
+}
+
+// appName returns the configured application name for branding
+func appName() string {
+	if config.GlobalConfig != nil && config.GlobalConfig.App.Name != "" {
+		return config.GlobalConfig.App.Name
+	}
+	return defaultAppName
+}
+
+// SendPasswordResetEmail sends a password reset link that stays valid for expiresIn
+func SendPasswordResetEmail(to string, resetURL string, expiresIn time.Duration) error {
+	return SendTemplate(to, TemplatePasswordReset, map[string]any{
+		"ResetURL":         resetURL,
+		"ExpiresInMinutes": int(expiresIn / time.Minute),
+	})
+}
+
+// SendWelcomeEmail sends a welcome email
+func SendWelcomeEmail(to string, username string) error {
+	return SendTemplate(to, TemplateWelcome, map[string]any{"Username": username})
+}
+
+// SendInvitationEmail sends an organization invitation containing the acceptance token
+func SendInvitationEmail(to string, organizationName string, token string, expiresAt time.Time) error {
+	return SendTemplate(to, TemplateInvitation, map[string]any{
+		"OrganizationName": organizationName,
+		"Token":            token,
+		"ExpiresAt":        expiresAt.Format("2006-01-02 15:04 MST"),
+	})
+}
+
+// SendVerificationEmail sends a link the user opens to confirm their email address
+func SendVerificationEmail(to string, username string, verifyURL string) error {
+	return SendTemplate(to, TemplateVerification, map[string]any{
+		"Username":  username,
+		"VerifyURL": verifyURL,
+	})
+}
@@ -0,0 +1,188 @@
+package email
+
+import (
+	"strings"
+	"testing"
+)
+
+// sampleData holds the variables each default template is rendered with
+var sampleData = map[string]map[string]any{
+	TemplateInvitation: {
+		"OrganizationName": "Acme",
+		"Token":            "invite-token",
+		"ExpiresAt":        "2026-01-02 15:04 UTC",
+	},
+	TemplatePasswordReset: {
+		"ResetURL":         "https://example.com/reset?token=abc",
+		"ExpiresInMinutes": 30,
+	},
+	TemplateVerification: {
+		"Username":  "alice",
+		"VerifyURL": "https://example.com/verify?token=abc",
+	},
+	TemplateWelcome: {
+		"Username": "alice",
+	},
+}
+
+func TestDefaultTemplatesRender(t *testing.T) {
+	names := TemplateNames()
+	for _, want := range []string{TemplateInvitation, TemplatePasswordReset, TemplateVerification, TemplateWelcome} {
+		if !contains(names, want) {
+			t.Fatalf("TemplateNames() = %v, missing %q", names, want)
+		}
+	}
+
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			data, ok := sampleData[name]
+			if !ok {
+				t.Fatalf("no sample data for template %q; add it to sampleData", name)
+			}
+
+			rendered, err := Render(name, data)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if rendered.Subject == "" || strings.Contains(rendered.Subject, "\n") {
+				t.Fatalf("Subject = %q, want a single non-empty line", rendered.Subject)
+			}
+			if !strings.Contains(rendered.HTML, "<html>") || !strings.Contains(rendered.HTML, defaultAppName) {
+				t.Fatalf("HTML is not wrapped in the layout:\n%s", rendered.HTML)
+			}
+			if !strings.Contains(rendered.Text, defaultAppName) || strings.Contains(rendered.Text, "<") {
+				t.Fatalf("Text is not a plain-text layout:\n%s", rendered.Text)
+			}
+			for key, value := range data {
+				if s, ok := value.(string); ok && !strings.Contains(rendered.Text, s) {
+					t.Fatalf("Text does not include %s = %q:\n%s", key, s, rendered.Text)
+				}
+			}
+
+			// Every variable is required, so dropping any one of them must fail
+			for key := range data {
+				partial := make(map[string]any, len(data))
+				for k, v := range data {
+					if k != key {
+						partial[k] = v
+					}
+				}
+				if _, err := Render(name, partial); err == nil {
+					t.Fatalf("Render() without %s succeeded", key)
+				}
+			}
+		})
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	newRegistry := func(t *testing.T) *Registry {
+		t.Helper()
+		r, err := NewRegistry(
+			`{{define "layout"}}<main>{{template "content" .}}</main>{{end}}`,
+			`{{define "layout"}}{{template "content" .}}{{end}}`,
+		)
+		if err != nil {
+			t.Fatalf("NewRegistry() error = %v", err)
+		}
+		return r
+	}
+
+	tests := []struct {
+		name        string
+		html        string
+		text        string
+		data        any
+		wantErr     bool
+		wantRegErr  bool
+		wantSubject string
+		wantHTML    string
+		wantText    string
+	}{
+		{
+			name:        "renders subject, html and text",
+			html:        `{{define "content"}}<p>Hi {{.Name}}</p>{{end}}`,
+			text:        "{{define \"subject\"}}Hello\n\t{{.Name}}{{end}}{{define \"content\"}}Hi {{.Name}}{{end}}",
+			data:        map[string]any{"Name": "Bob"},
+			wantSubject: "Hello Bob",
+			wantHTML:    "<main><p>Hi Bob</p></main>",
+			wantText:    "Hi Bob",
+		},
+		{
+			name:        "escapes html but not text",
+			html:        `{{define "content"}}{{.Name}}{{end}}`,
+			text:        `{{define "subject"}}s{{end}}{{define "content"}}{{.Name}}{{end}}`,
+			data:        map[string]any{"Name": "<b>"},
+			wantSubject: "s",
+			wantHTML:    "<main>&lt;b&gt;</main>",
+			wantText:    "<b>",
+		},
+		{
+			name:    "missing variable",
+			html:    `{{define "content"}}{{.Name}}{{end}}`,
+			text:    `{{define "subject"}}s{{end}}{{define "content"}}{{.Name}}{{end}}`,
+			data:    map[string]any{},
+			wantErr: true,
+		},
+		{
+			name:       "no subject",
+			html:       `{{define "content"}}x{{end}}`,
+			text:       `{{define "content"}}x{{end}}`,
+			wantRegErr: true,
+		},
+		{
+			name:       "no html content",
+			html:       `<p>x</p>`,
+			text:       `{{define "subject"}}s{{end}}{{define "content"}}x{{end}}`,
+			wantRegErr: true,
+		},
+		{
+			name:       "parse error",
+			html:       `{{define "content"}}{{.Name{{end}}`,
+			text:       `{{define "subject"}}s{{end}}{{define "content"}}x{{end}}`,
+			wantRegErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRegistry(t)
+			err := r.Register("test", tt.html, tt.text)
+			if (err != nil) != tt.wantRegErr {
+				t.Fatalf("Register() error = %v, wantErr %v", err, tt.wantRegErr)
+			}
+			if tt.wantRegErr {
+				if len(r.Names()) != 0 {
+					t.Fatalf("Names() = %v after a failed Register", r.Names())
+				}
+				return
+			}
+
+			rendered, err := r.Render("test", tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Render() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if rendered.Subject != tt.wantSubject || rendered.HTML != tt.wantHTML || rendered.Text != tt.wantText {
+				t.Fatalf("Render() = %+v, want subject %q, html %q, text %q", rendered, tt.wantSubject, tt.wantHTML, tt.wantText)
+			}
+		})
+	}
+
+	t.Run("unknown template", func(t *testing.T) {
+		if _, err := newRegistry(t).Render("missing", nil); err == nil {
+			t.Fatal("Render() of an unregistered template succeeded")
+		}
+	})
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
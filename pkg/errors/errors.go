@@ -0,0 +1,110 @@
+// Package errors defines the typed errors services return, so handlers can choose an HTTP
+// status with errors.Is or errors.As instead of matching messages. Every error has a Kind,
+// which decides the status, and a machine-readable Code, which is sent to clients and
+// doubles as the i18n catalog key for the message.
+//
+// Import it under an alias, e.g. apperrors, when the standard errors package is also needed.
+package errors
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kind classifies an error by how the client should react to it
+type Kind string
+
+// Error kinds
+const (
+	KindNotFound      Kind = "not_found"      // The resource does not exist
+	KindAlreadyExists Kind = "already_exists" // Creating the resource would duplicate an existing one
+	KindForbidden     Kind = "forbidden"      // The caller may not perform the operation
+	KindValidation    Kind = "validation"     // The request is malformed or breaks a business rule
+	KindConflict      Kind = "conflict"       // The resource is not in a state that allows the operation
+)
+
+// Sentinels matching any error of their kind with errors.Is
+var (
+	ErrNotFound      = &Error{Kind: KindNotFound, Code: string(KindNotFound), Message: "not found"}
+	ErrAlreadyExists = &Error{Kind: KindAlreadyExists, Code: string(KindAlreadyExists), Message: "already exists"}
+	ErrForbidden     = &Error{Kind: KindForbidden, Code: string(KindForbidden), Message: "forbidden"}
+	ErrValidation    = &Error{Kind: KindValidation, Code: string(KindValidation), Message: "validation failed"}
+	ErrConflict      = &Error{Kind: KindConflict, Code: string(KindConflict), Message: "conflict"}
+)
+
+// Error is a classified error with a machine-readable code
+type Error struct {
+	Kind    Kind
+	Code    string        // Stable identifier for clients, also the i18n catalog key
+	Message string        // English message, used when the catalog has no entry for Code
+	Args    []interface{} // fmt arguments for the translated message
+}
+
+// New returns an error of the given kind. Like errors.New, each call returns a distinct value.
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+// NotFound returns a KindNotFound error
+func NotFound(code, message string) *Error { return New(KindNotFound, code, message) }
+
+// AlreadyExists returns a KindAlreadyExists error
+func AlreadyExists(code, message string) *Error { return New(KindAlreadyExists, code, message) }
+
+// Forbidden returns a KindForbidden error
+func Forbidden(code, message string) *Error { return New(KindForbidden, code, message) }
+
+// Validation returns a KindValidation error
+func Validation(code, message string) *Error { return New(KindValidation, code, message) }
+
+// Conflict returns a KindConflict error
+func Conflict(code, message string) *Error { return New(KindConflict, code, message) }
+
+// WithArgs returns a copy of the error carrying fmt arguments for its messages. The copy
+// still matches the original with errors.Is.
+func (e *Error) WithArgs(args ...interface{}) *Error {
+	copied := *e
+	copied.Args = args
+	return &copied
+}
+
+func (e *Error) Error() string {
+	if len(e.Args) > 0 {
+		return fmt.Sprintf(e.Message, e.Args...)
+	}
+	return e.Message
+}
+
+// Is matches errors with the same kind and code, and a kind sentinel such as ErrNotFound
+// matches every error of its kind
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok || t.Kind != e.Kind {
+		return false
+	}
+	return t.Code == e.Code || t.Code == string(t.Kind)
+}
+
+// MessageKey implements i18n.Localizable
+func (e *Error) MessageKey() string { return e.Code }
+
+// MessageArgs supplies Args to the translated message
+func (e *Error) MessageArgs() []interface{} { return e.Args }
+
+// KindOf returns the kind of the first *Error in err's chain
+func KindOf(err error) (Kind, bool) {
+	var typed *Error
+	if !errors.As(err, &typed) {
+		return "", false
+	}
+	return typed.Kind, true
+}
+
+// CodeOf returns the code of the first *Error in err's chain, or "" when there is none
+func CodeOf(err error) string {
+	var typed *Error
+	if !errors.As(err, &typed) {
+		return ""
+	}
+	return typed.Code
+}
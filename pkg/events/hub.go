@@ -0,0 +1,211 @@
+// Package events fans out organization events, such as a member joining or an invitation
+// being accepted, to subscribers of that organization. Services publish an event after the
+// change it describes is committed; the WebSocket endpoint subscribes on behalf of clients.
+//
+// The hub lives in process memory, so subscribers only see events published by the same
+// server instance.
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Event types
+const (
+	TypeMemberAdded        = "member.added"
+	TypeMemberRemoved      = "member.removed"
+	TypeMemberRoleChanged  = "member.role_changed"
+	TypeInvitationAccepted = "invitation.accepted"
+	TypeInvitationExpired  = "invitation.expired"
+)
+
+const (
+	// DefaultHistorySize is how many recent events per organization are kept for clients
+	// that reconnect and ask for what they missed
+	DefaultHistorySize = 100
+	// DefaultBufferSize is how many undelivered events a subscriber may fall behind by
+	// before it is dropped
+	DefaultBufferSize = 64
+)
+
+// Event is a change within an organization
+type Event struct {
+	ID             uint64      `json:"id"` // Increases per organization, for resuming after a reconnect
+	Type           string      `json:"type"`
+	OrganizationID uint        `json:"organization_id"`
+	Data           interface{} `json:"data,omitempty"`
+	CreatedAt      time.Time   `json:"created_at"`
+}
+
+// MemberData is the payload of member events
+type MemberData struct {
+	MemberID  uint `json:"member_id,omitempty"`
+	UserID    uint `json:"user_id"`
+	RoleID    uint `json:"role_id,omitempty"`
+	OldRoleID uint `json:"old_role_id,omitempty"` // Set on member.role_changed
+	ActorID   uint `json:"actor_id,omitempty"`    // Zero when the change was not made by a user
+}
+
+// InvitationData is the payload of invitation events
+type InvitationData struct {
+	InvitationID uint   `json:"invitation_id"`
+	Email        string `json:"email"`
+	RoleID       uint   `json:"role_id"`
+	UserID       uint   `json:"user_id,omitempty"` // The accepting user, set on invitation.accepted
+}
+
+// Hub keeps per-organization subscriber registries and recent event history
+type Hub struct {
+	mu          sync.Mutex
+	orgs        map[uint]*organization
+	historySize int
+	bufferSize  int
+}
+
+// organization is the registry and history of one organization
+type organization struct {
+	lastID      uint64
+	history     []Event // Oldest first, at most historySize events
+	subscribers map[*Subscription]struct{}
+}
+
+// Subscription receives an organization's events until it is closed
+type Subscription struct {
+	hub            *Hub
+	organizationID uint
+	events         chan Event
+	closeOnce      sync.Once
+}
+
+// NewHub creates a hub keeping historySize events per organization and buffering up to
+// bufferSize events per subscriber
+func NewHub(historySize, bufferSize int) *Hub {
+	return &Hub{
+		orgs:        make(map[uint]*organization),
+		historySize: historySize,
+		bufferSize:  bufferSize,
+	}
+}
+
+var defaultHub = NewHub(DefaultHistorySize, DefaultBufferSize)
+
+// Default returns the process-wide hub services publish to
+func Default() *Hub {
+	return defaultHub
+}
+
+// Publish sends an event to the organization's subscribers on the default hub
+func Publish(organizationID uint, eventType string, data interface{}) {
+	defaultHub.Publish(organizationID, eventType, data)
+}
+
+// Publish records an event in the organization's history and delivers it to every
+// subscriber. A subscriber whose buffer is full is closed rather than blocking the
+// publisher; its client can reconnect and resume from the last event it received.
+func (h *Hub) Publish(organizationID uint, eventType string, data interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	org := h.organization(organizationID)
+	org.lastID++
+	event := Event{
+		ID:             org.lastID,
+		Type:           eventType,
+		OrganizationID: organizationID,
+		Data:           data,
+		CreatedAt:      time.Now(),
+	}
+
+	org.history = append(org.history, event)
+	if len(org.history) > h.historySize {
+		org.history = org.history[len(org.history)-h.historySize:]
+	}
+
+	for sub := range org.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			h.remove(sub)
+		}
+	}
+}
+
+// Subscribe registers a subscriber for the organization's events. Events recorded after
+// afterID that are still in the history are queued first, so a reconnecting client that
+// passes the last ID it saw misses nothing unless it fell more than the history behind;
+// pass 0 to receive only new events.
+func (h *Hub) Subscribe(organizationID uint, afterID uint64) *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	org := h.organization(organizationID)
+	var missed []Event
+	if afterID > 0 {
+		for _, event := range org.history {
+			if event.ID > afterID {
+				missed = append(missed, event)
+			}
+		}
+	}
+
+	sub := &Subscription{
+		hub:            h,
+		organizationID: organizationID,
+		events:         make(chan Event, h.bufferSize+len(missed)),
+	}
+	for _, event := range missed {
+		sub.events <- event
+	}
+	org.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// Subscribers returns the number of open subscriptions for the organization
+func (h *Hub) Subscribers(organizationID uint) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if org, ok := h.orgs[organizationID]; ok {
+		return len(org.subscribers)
+	}
+	return 0
+}
+
+// organization returns the organization's entry, creating it if needed. Callers hold mu.
+func (h *Hub) organization(organizationID uint) *organization {
+	org, ok := h.orgs[organizationID]
+	if !ok {
+		org = &organization{subscribers: make(map[*Subscription]struct{})}
+		h.orgs[organizationID] = org
+	}
+	return org
+}
+
+// remove unregisters sub and closes its channel. Callers hold mu.
+func (h *Hub) remove(sub *Subscription) {
+	org, ok := h.orgs[sub.organizationID]
+	if !ok {
+		return
+	}
+	if _, ok := org.subscribers[sub]; !ok {
+		return
+	}
+	delete(org.subscribers, sub)
+	close(sub.events)
+}
+
+// Events returns the channel events are delivered on. It is closed when the subscription
+// is closed or dropped for falling behind.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Close unregisters the subscription. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.closeOnce.Do(func() {
+		s.hub.mu.Lock()
+		defer s.hub.mu.Unlock()
+		s.hub.remove(s)
+	})
+}
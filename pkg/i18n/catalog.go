@@ -0,0 +1,118 @@
+package i18n
+
+// Message keys shared by handlers and service errors
+const (
+	KeyInvalidRequestPayload = "invalid_request_payload"
+	KeyInvalidQueryParams    = "invalid_query_parameters"
+	KeyInvalidID             = "invalid_id"
+	KeyInvalidUserID         = "invalid_user_id"
+	KeyInvalidRoleID         = "invalid_role_id"
+	KeyInvalidParam          = "invalid_param"
+	KeyUnauthorized          = "unauthorized"
+	KeyInternalError         = "internal_error"
+	KeyRequestTimeout        = "request_timeout"
+	KeyBatchPartiallyFailed  = "batch_partially_failed"
+	KeyBatchFailed           = "batch_failed"
+	KeyReservedName          = "reserved_name"
+	KeyInvalidSettings       = "invalid_settings"
+
+	KeyOrganizationNotFound               = "organization_not_found"
+	KeyNotOrganizationOwner               = "not_organization_owner"
+	KeyOrganizationUpdateForbidden        = "organization_update_forbidden"
+	KeyInvalidExportFormat                = "invalid_export_format"
+	KeyInvalidDateRange                   = "invalid_date_range"
+	KeyCursorOrderUnsupported             = "cursor_order_unsupported"
+	KeyNotOrganizationMember              = "not_organization_member"
+	KeyRoleNotFound                       = "role_not_found"
+	KeyRoleAlreadyAssigned                = "role_already_assigned"
+	KeyRoleNotGrantable                   = "role_not_grantable"
+	KeyRoleAssignmentNotFound             = "role_assignment_not_found"
+	KeyOrganizationRoleAssignmentNotFound = "organization_role_assignment_not_found"
+	KeyTeamRoleAssignmentNotFound         = "team_role_assignment_not_found"
+	KeyExpirationInPast                   = "expiration_in_past"
+	KeySystemRoleProtected                = "system_role_protected"
+	KeyRoleInUse                          = "role_in_use"
+	KeyDeletedRoleNotFound                = "deleted_role_not_found"
+	KeyPermissionsNotFound                = "permissions_not_found"
+	KeyUsersNotFound                      = "users_not_found"
+	KeyPolicyNotFound                     = "policy_not_found"
+	KeyInvalidPolicyConditions            = "invalid_policy_conditions"
+)
+
+// catalogs maps language codes to their messages. English must contain every key.
+var catalogs = map[string]map[string]string{
+	"en": {
+		KeyInvalidRequestPayload: "Invalid request payload",
+		KeyInvalidQueryParams:    "Invalid query parameters",
+		KeyInvalidID:             "invalid ID format",
+		KeyInvalidUserID:         "Invalid user ID",
+		KeyInvalidRoleID:         "Invalid role ID",
+		KeyInvalidParam:          "Invalid %s",
+		KeyUnauthorized:          "User not authenticated",
+		KeyInternalError:         "Internal server error",
+		KeyRequestTimeout:        "Request timed out",
+		KeyBatchPartiallyFailed:  "Some items could not be processed",
+		KeyBatchFailed:           "No items could be processed",
+		KeyReservedName:          "this name is reserved, please choose another",
+		KeyInvalidSettings:       "invalid %s: %s",
+
+		KeyOrganizationNotFound:               "organization not found",
+		KeyNotOrganizationOwner:               "only the organization owner can delete it",
+		KeyOrganizationUpdateForbidden:        "only the organization owner or a member with organizations.update can change it",
+		KeyInvalidExportFormat:                "format must be csv or json",
+		KeyInvalidDateRange:                   "to must not be before from",
+		KeyCursorOrderUnsupported:             "cursor pagination only supports order_by=created_at and order=desc",
+		KeyNotOrganizationMember:              "user is not a member of this organization",
+		KeyRoleNotFound:                       "role not found",
+		KeyRoleAlreadyAssigned:                "role already assigned",
+		KeyRoleNotGrantable:                   "insufficient privileges to grant this role",
+		KeyRoleAssignmentNotFound:             "role assignment not found",
+		KeyOrganizationRoleAssignmentNotFound: "organization role assignment not found",
+		KeyTeamRoleAssignmentNotFound:         "team role assignment not found",
+		KeyExpirationInPast:                   "expiration must be in the future",
+		KeySystemRoleProtected:                "system roles cannot be deleted",
+		KeyRoleInUse:                          "role is still held by organization members",
+		KeyDeletedRoleNotFound:                "deleted role not found",
+		KeyPermissionsNotFound:                "permissions not found: %s",
+		KeyUsersNotFound:                      "users not found: %s",
+		KeyPolicyNotFound:                     "policy not found",
+		KeyInvalidPolicyConditions:            "conditions must be a JSON object keyed by a known condition type",
+	},
+	"zh": {
+		KeyInvalidRequestPayload: "无效的请求参数",
+		KeyInvalidQueryParams:    "无效的查询参数",
+		KeyInvalidID:             "无效的ID格式",
+		KeyInvalidUserID:         "无效的用户ID",
+		KeyInvalidRoleID:         "无效的角色ID",
+		KeyInvalidParam:          "无效的参数: %s",
+		KeyUnauthorized:          "未授权访问",
+		KeyInternalError:         "服务器内部错误",
+		KeyRequestTimeout:        "请求超时",
+		KeyBatchPartiallyFailed:  "部分项目处理失败",
+		KeyBatchFailed:           "所有项目均处理失败",
+		KeyReservedName:          "该名称为保留名称，请换一个",
+		KeyInvalidSettings:       "%s 无效：%s",
+
+		KeyOrganizationNotFound:               "组织不存在",
+		KeyNotOrganizationOwner:               "只有组织所有者可以删除该组织",
+		KeyOrganizationUpdateForbidden:        "只有组织所有者或拥有 organizations.update 权限的成员可以修改该组织",
+		KeyInvalidExportFormat:                "导出格式必须为 csv 或 json",
+		KeyInvalidDateRange:                   "结束时间不能早于开始时间",
+		KeyCursorOrderUnsupported:             "游标分页仅支持 order_by=created_at 与 order=desc",
+		KeyNotOrganizationMember:              "用户不是该组织的成员",
+		KeyRoleNotFound:                       "角色不存在",
+		KeyRoleAlreadyAssigned:                "角色已分配",
+		KeyRoleNotGrantable:                   "权限不足，无法授予该角色",
+		KeyRoleAssignmentNotFound:             "角色分配不存在",
+		KeyOrganizationRoleAssignmentNotFound: "组织角色分配不存在",
+		KeyTeamRoleAssignmentNotFound:         "团队角色分配不存在",
+		KeyExpirationInPast:                   "过期时间必须晚于当前时间",
+		KeySystemRoleProtected:                "系统角色不能删除",
+		KeyRoleInUse:                          "角色仍被组织成员使用",
+		KeyDeletedRoleNotFound:                "已删除的角色不存在",
+		KeyPermissionsNotFound:                "权限不存在: %s",
+		KeyUsersNotFound:                      "用户不存在: %s",
+		KeyPolicyNotFound:                     "策略不存在",
+		KeyInvalidPolicyConditions:            "条件必须是以已知条件类型为键的 JSON 对象",
+	},
+}
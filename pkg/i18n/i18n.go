@@ -0,0 +1,124 @@
+// Package i18n translates user-facing error messages.
+//
+// Messages are referenced by key and looked up in a per-language catalog. English is
+// the default and the fallback for keys missing from another catalog; unknown keys are
+// returned unchanged so a missing entry never hides the message entirely.
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLanguage is used when no requested language is supported
+const DefaultLanguage = "en"
+
+// Localizable is implemented by errors whose message can be translated
+type Localizable interface {
+	error
+	MessageKey() string
+}
+
+// localizableWithArgs is implemented by errors whose translated message takes fmt arguments
+type localizableWithArgs interface {
+	MessageArgs() []interface{}
+}
+
+// keyedError is an error with a fixed English message and a catalog key
+type keyedError struct {
+	key     string
+	message string
+}
+
+// NewError returns an error with the given English message that translates via key.
+// Like errors.New, each call returns a distinct value usable with errors.Is.
+func NewError(key, message string) error {
+	return &keyedError{key: key, message: message}
+}
+
+func (e *keyedError) Error() string { return e.message }
+
+// MessageKey implements Localizable
+func (e *keyedError) MessageKey() string { return e.key }
+
+// Translate returns the message for key in lang, falling back to English and then to the key itself
+func Translate(lang, key string, args ...interface{}) string {
+	message, ok := catalogs[lang][key]
+	if !ok {
+		message, ok = catalogs[DefaultLanguage][key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// TranslateError returns the translated message for a Localizable error anywhere in err's chain,
+// or err.Error() when there is none or the catalog has no message for its key
+func TranslateError(lang string, err error) string {
+	var localizable Localizable
+	if !errors.As(err, &localizable) || !hasKey(localizable.MessageKey()) {
+		return err.Error()
+	}
+
+	var args []interface{}
+	if withArgs, ok := localizable.(localizableWithArgs); ok {
+		args = withArgs.MessageArgs()
+	}
+	return Translate(lang, localizable.MessageKey(), args...)
+}
+
+// hasKey reports whether the default catalog has a message for key
+func hasKey(key string) bool {
+	_, ok := catalogs[DefaultLanguage][key]
+	return ok
+}
+
+// Languages returns the supported language codes
+func Languages() []string {
+	languages := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// ParseAcceptLanguage picks the supported language with the highest quality from an
+// Accept-Language header, matching on the primary subtag ("zh-CN" selects "zh")
+func ParseAcceptLanguage(header string) string {
+	best, bestQuality := DefaultLanguage, 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, quality := parseLanguageRange(part)
+		if _, ok := catalogs[tag]; ok && quality > bestQuality {
+			best, bestQuality = tag, quality
+		}
+	}
+	return best
+}
+
+// parseLanguageRange returns the lower-cased primary subtag and quality of one Accept-Language entry
+func parseLanguageRange(part string) (string, float64) {
+	fields := strings.Split(strings.TrimSpace(part), ";")
+	tag := strings.ToLower(strings.TrimSpace(fields[0]))
+	if i := strings.IndexByte(tag, '-'); i >= 0 {
+		tag = tag[:i]
+	}
+
+	quality := 1.0
+	for _, param := range fields[1:] {
+		param = strings.TrimSpace(param)
+		if strings.HasPrefix(param, "q=") {
+			if q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+				quality = q
+			}
+		}
+	}
+	return tag, quality
+}
@@ -0,0 +1,109 @@
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		name string
+		lang string
+		key  string
+		args []interface{}
+		want string
+	}{
+		{name: "english", lang: "en", key: KeyRoleNotFound, want: "role not found"},
+		{name: "supported language", lang: "zh", key: KeyRoleNotFound, want: "角色不存在"},
+		{name: "unsupported language falls back to english", lang: "fr", key: KeyRoleNotFound, want: "role not found"},
+		{name: "arguments", lang: "zh", key: KeyInvalidParam, args: []interface{}{"role_id"}, want: "无效的参数: role_id"},
+		{name: "unknown key", lang: "zh", key: "no_such_key", want: "no_such_key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Translate(tt.lang, tt.key, tt.args...); got != tt.want {
+				t.Fatalf("Translate(%q, %q) = %q, want %q", tt.lang, tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+// argsError is a localizable error with message arguments
+type argsError struct {
+	key  string
+	args []interface{}
+}
+
+func (e *argsError) Error() string              { return "english " + e.key }
+func (e *argsError) MessageKey() string         { return e.key }
+func (e *argsError) MessageArgs() []interface{} { return e.args }
+
+func TestTranslateError(t *testing.T) {
+	errRoleNotFound := NewError(KeyRoleNotFound, "role not found")
+
+	tests := []struct {
+		name string
+		lang string
+		err  error
+		want string
+	}{
+		{name: "keyed error", lang: "zh", err: errRoleNotFound, want: "角色不存在"},
+		{name: "wrapped keyed error", lang: "zh", err: fmt.Errorf("get role: %w", errRoleNotFound), want: "角色不存在"},
+		{name: "unsupported language", lang: "de", err: errRoleNotFound, want: "role not found"},
+		{name: "error with arguments", lang: "zh", err: &argsError{key: KeyInvalidParam, args: []interface{}{"page"}}, want: "无效的参数: page"},
+		{name: "plain error", lang: "zh", err: errors.New("boom"), want: "boom"},
+		{name: "key missing from the catalog", lang: "zh", err: NewError("no_such_key", "raw message"), want: "raw message"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TranslateError(tt.lang, tt.err); got != tt.want {
+				t.Fatalf("TranslateError(%q) = %q, want %q", tt.lang, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "", want: "en"},
+		{header: "zh", want: "zh"},
+		{header: "zh-CN,zh;q=0.9", want: "zh"},
+		{header: "ZH-tw", want: "zh"},
+		{header: "fr-FR,fr;q=0.9", want: "en"},
+		{header: "fr;q=1.0, zh;q=0.5", want: "zh"},
+		{header: "en;q=0.8, zh;q=0.9", want: "zh"},
+		{header: "zh;q=0.5, en", want: "en"},
+		{header: "zh;q=0", want: "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := ParseAcceptLanguage(tt.header); got != tt.want {
+				t.Fatalf("ParseAcceptLanguage(%q) = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCatalogsMatchEnglish(t *testing.T) {
+	english := catalogs[DefaultLanguage]
+	for _, lang := range Languages() {
+		for key, message := range catalogs[lang] {
+			base, ok := english[key]
+			if !ok {
+				t.Errorf("%s: key %q is missing from the English catalog", lang, key)
+				continue
+			}
+			if got, want := strings.Count(message, "%"), strings.Count(base, "%"); got != want {
+				t.Errorf("%s: %q has %d format verbs, English has %d", lang, key, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// Package imaging scales decoded images without dependencies beyond the standard library.
+package imaging
+
+import (
+	"image"
+	"image/color"
+)
+
+// Fit returns img scaled down so neither side exceeds maxDim, keeping the aspect ratio.
+// Images already within bounds are returned unchanged. Each output pixel averages the
+// source pixels it covers, which avoids the aliasing of nearest-neighbour sampling.
+func Fit(img image.Image, maxDim int) image.Image {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	if maxDim <= 0 || (srcW <= maxDim && srcH <= maxDim) {
+		return img
+	}
+
+	dstW, dstH := maxDim, maxDim
+	if srcW > srcH {
+		dstH = max(1, srcH*maxDim/srcW)
+	} else {
+		dstW = max(1, srcW*maxDim/srcH)
+	}
+	return resizeBox(img, dstW, dstH)
+}
+
+// resizeBox downscales img to w x h by averaging the source box behind each output pixel
+func resizeBox(img image.Image, w, h int) *image.NRGBA {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		y0 := bounds.Min.Y + y*srcH/h
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*srcH/h)
+		for x := 0; x < w; x++ {
+			x0 := bounds.Min.X + x*srcW/w
+			x1 := max(x0+1, bounds.Min.X+(x+1)*srcW/w)
+
+			// Sum premultiplied values so transparent pixels don't darken their neighbours
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+
+			c := color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(a / n)}
+			dst.Set(x, y, c)
+		}
+	}
+	return dst
+}
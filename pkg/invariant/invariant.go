@@ -0,0 +1,75 @@
+// Package invariant checks model invariants from GORM hooks so invalid rows cannot be written.
+package invariant
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrViolation is wrapped by every invariant error
+var ErrViolation = errors.New("model invariant violated")
+
+// Error reports which field of which model broke an invariant
+type Error struct {
+	Model  string
+	Column string
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("invalid %s: %s %s", e.Model, e.Column, e.Reason)
+}
+
+// Unwrap lets callers match any invariant error with errors.Is(err, ErrViolation)
+func (e *Error) Unwrap() error { return ErrViolation }
+
+// Field is a required column and its value on the model being written
+type Field struct {
+	Column string
+	Value  interface{}
+}
+
+// Required rejects the write when any field is zero or a blank string.
+// For Update and Updates with a map the model does not hold the new values, so only the
+// columns present in the map are checked.
+func Required(tx *gorm.DB, model string, fields ...Field) error {
+	updates, isMapUpdate := tx.Statement.Dest.(map[string]interface{})
+
+	for _, field := range fields {
+		value := field.Value
+		if isMapUpdate {
+			v, ok := updates[field.Column]
+			if !ok {
+				continue
+			}
+			value = v
+		}
+		if isBlank(value) {
+			return &Error{Model: model, Column: field.Column, Reason: "is required"}
+		}
+	}
+	return nil
+}
+
+// isBlank reports whether value is nil, its type's zero value, or a whitespace-only string
+func isBlank(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	if s, ok := value.(string); ok {
+		return strings.TrimSpace(s) == ""
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return true
+		}
+		v = v.Elem()
+	}
+	return v.IsZero()
+}
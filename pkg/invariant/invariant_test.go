@@ -0,0 +1,94 @@
+package invariant
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/llamacto/llama-gin-kit/pkg/dbtest"
+	"gorm.io/gorm"
+)
+
+// widget is a model whose name and owner are required
+type widget struct {
+	ID      uint
+	Name    string
+	OwnerID uint
+}
+
+func (w *widget) BeforeSave(tx *gorm.DB) error {
+	return Required(tx, "widget",
+		Field{Column: "name", Value: w.Name},
+		Field{Column: "owner_id", Value: w.OwnerID},
+	)
+}
+
+func TestRequired(t *testing.T) {
+	tests := []struct {
+		name       string
+		write      func(*gorm.DB) error
+		wantColumn string // Column reported as violated; empty when the write is allowed
+	}{
+		{
+			name:  "create valid row",
+			write: func(db *gorm.DB) error { return db.Create(&widget{Name: "gear", OwnerID: 1}).Error },
+		},
+		{
+			name:       "create without name",
+			write:      func(db *gorm.DB) error { return db.Create(&widget{OwnerID: 1}).Error },
+			wantColumn: "name",
+		},
+		{
+			name:       "create with blank name",
+			write:      func(db *gorm.DB) error { return db.Create(&widget{Name: "  ", OwnerID: 1}).Error },
+			wantColumn: "name",
+		},
+		{
+			name:       "create without owner",
+			write:      func(db *gorm.DB) error { return db.Create(&widget{Name: "gear"}).Error },
+			wantColumn: "owner_id",
+		},
+		{
+			name:       "save without name",
+			write:      func(db *gorm.DB) error { return db.Save(&widget{ID: 1, OwnerID: 1}).Error },
+			wantColumn: "name",
+		},
+		{
+			name: "partial update leaving required columns alone",
+			write: func(db *gorm.DB) error {
+				return db.Model(&widget{ID: 1}).Updates(map[string]interface{}{"owner_id": 2}).Error
+			},
+		},
+		{
+			name: "partial update clearing a required column",
+			write: func(db *gorm.DB) error {
+				return db.Model(&widget{ID: 1}).Updates(map[string]interface{}{"name": ""}).Error
+			},
+			wantColumn: "name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB, db := dbtest.Open(t)
+
+			err := tt.write(gormDB.WithContext(context.Background()))
+
+			var violation *Error
+			if tt.wantColumn == "" {
+				if err != nil {
+					t.Fatalf("write error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrViolation) || !errors.As(err, &violation) || violation.Column != tt.wantColumn {
+				t.Fatalf("write error = %v, want a violation on %s", err, tt.wantColumn)
+			}
+			for _, stmt := range db.Statements() {
+				if stmt.SQL != "BEGIN" && stmt.SQL != "ROLLBACK" {
+					t.Fatalf("rejected write still sent %q", stmt.SQL)
+				}
+			}
+		})
+	}
+}
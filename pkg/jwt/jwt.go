@@ -1,8 +1,10 @@
 package jwt
 
 import (
+	"errors"
 	"fmt"
 	"time"
+	"unicode/utf8"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/llamacto/llama-gin-kit/config"
@@ -12,30 +14,155 @@ var (
 	cfg *config.Config
 )
 
-// Init 初始化 JWT 服务
-func Init(c *config.Config) {
+// Limits on extra claims, which travel in every request header
+const (
+	MaxExtraClaims        = 8
+	MaxExtraClaimKeyLen   = 32
+	MaxExtraClaimValueLen = 128
+)
+
+// MinSecretLength is the shortest signing secret Init accepts, in bytes
+const MinSecretLength = 32
+
+// ErrCodeNotInitialized is the stable error code returned to clients when tokens are
+// used before Init, so the misconfiguration can be told apart from a bad token
+const ErrCodeNotInitialized = "jwt_not_initialized"
+
+// Token types carried in the token_type claim. Access tokens authenticate API requests;
+// refresh tokens may only be exchanged for new access tokens. Two-factor tokens prove the
+// password was checked and may only be exchanged, with a one-time code, for an access token.
+const (
+	TokenTypeAccess    = "access"
+	TokenTypeRefresh   = "refresh"
+	TokenTypeTwoFactor = "two_factor"
+)
+
+var (
+	// ErrInvalidExtraClaims is returned when extra claims exceed the limits or use an empty key
+	ErrInvalidExtraClaims = errors.New("invalid extra claims")
+	// ErrNotInitialized is returned when tokens are generated or parsed before Init
+	ErrNotInitialized = errors.New("jwt service not initialized")
+	// ErrTokenTypeMismatch is matched by every *TokenTypeError
+	ErrTokenTypeMismatch = errors.New("token type mismatch")
+)
+
+// TokenTypeError is returned by ParseTokenOfType when a valid token has the wrong type,
+// such as a refresh token presented as an access token
+type TokenTypeError struct {
+	Expected string
+	Actual   string // Empty for tokens issued without a token_type claim
+}
+
+func (e *TokenTypeError) Error() string {
+	actual := e.Actual
+	if actual == "" {
+		actual = "untyped"
+	}
+	return fmt.Sprintf("token type mismatch: expected %s token, got %s", e.Expected, actual)
+}
+
+func (e *TokenTypeError) Unwrap() error { return ErrTokenTypeMismatch }
+
+// Init 初始化 JWT 服务，校验签名密钥和有效期，配置无效时返回错误且不启用服务
+func Init(c *config.Config) error {
+	if c == nil {
+		return fmt.Errorf("jwt: config is nil")
+	}
+	if len(c.JWT.Secret) < MinSecretLength {
+		return fmt.Errorf("jwt: JWT_SECRET must be at least %d bytes", MinSecretLength)
+	}
+	if c.JWT.ExpireDuration <= 0 {
+		return fmt.Errorf("jwt: JWT_EXPIRE_DAYS must be positive")
+	}
+	if c.JWT.RefreshExpireDuration < 0 {
+		return fmt.Errorf("jwt: JWT_REFRESH_EXPIRE_DAYS must not be negative")
+	}
 	cfg = c
+	return nil
+}
+
+// Initialized reports whether Init has completed successfully
+func Initialized() bool {
+	return cfg != nil
 }
 
 // Claims 自定义的 JWT Claims
 type Claims struct {
 	UserID   uint   `json:"user_id"`
 	Username string `json:"username"`
+	// ActiveOrganizationID is the organization the token is scoped to, zero when unset
+	ActiveOrganizationID uint `json:"active_organization_id,omitempty"`
+	// Extra carries optional application context such as a default organization or tenant
+	Extra map[string]string `json:"ext,omitempty"`
+	// TokenType is TokenTypeAccess or TokenTypeRefresh, empty on tokens issued before it existed
+	TokenType string `json:"token_type,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// TokenOptions customizes a generated token
+type TokenOptions struct {
+	ActiveOrganizationID uint
+	ExtraClaims          map[string]string
+	TokenType            string        // TokenTypeAccess when empty
+	TTL                  time.Duration // How long the token is valid; JWT_EXPIRE_DAYS when zero
+}
+
 // GenerateToken 生成 JWT token
 func GenerateToken(userID uint, username string) (string, error) {
+	return GenerateTokenWithOptions(userID, username, TokenOptions{})
+}
+
+// GenerateRefreshToken 生成只能用于换取新 access token 的 refresh token，
+// 有效期为 JWT_REFRESH_EXPIRE_DAYS，未配置时与 access token 相同
+func GenerateRefreshToken(userID uint, username string) (string, error) {
 	if cfg == nil {
-		return "", fmt.Errorf("jwt service not initialized")
+		return "", ErrNotInitialized
+	}
+	return GenerateTokenWithOptions(userID, username, TokenOptions{
+		TokenType: TokenTypeRefresh,
+		TTL:       cfg.JWT.RefreshExpireDuration,
+	})
+}
+
+// GenerateTokenForOrg 生成绑定当前组织的 JWT token
+func GenerateTokenForOrg(userID uint, username string, organizationID uint) (string, error) {
+	return GenerateTokenWithOptions(userID, username, TokenOptions{ActiveOrganizationID: organizationID})
+}
+
+// GenerateTokenWithOptions 生成带有额外 claims 的 JWT token
+func GenerateTokenWithOptions(userID uint, username string, opts TokenOptions) (string, error) {
+	if cfg == nil {
+		return "", ErrNotInitialized
+	}
+
+	if err := validateExtraClaims(opts.ExtraClaims); err != nil {
+		return "", err
+	}
+
+	tokenType := opts.TokenType
+	if tokenType == "" {
+		tokenType = TokenTypeAccess
+	}
+	switch tokenType {
+	case TokenTypeAccess, TokenTypeRefresh, TokenTypeTwoFactor:
+	default:
+		return "", fmt.Errorf("unknown token type %q", tokenType)
+	}
+
+	ttl := opts.TTL
+	if ttl <= 0 {
+		ttl = cfg.JWT.ExpireDuration
 	}
 
 	now := time.Now()
 	claims := Claims{
-		UserID:   userID,
-		Username: username,
+		UserID:               userID,
+		Username:             username,
+		ActiveOrganizationID: opts.ActiveOrganizationID,
+		Extra:                copyExtraClaims(opts.ExtraClaims),
+		TokenType:            tokenType,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(now.Add(cfg.JWT.ExpireDuration)),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 		},
@@ -48,14 +175,63 @@ func GenerateToken(userID uint, username string) (string, error) {
 // ParseToken 解析 JWT token
 func ParseToken(tokenString string) (*Claims, error) {
 	if cfg == nil {
-		return nil, fmt.Errorf("jwt service not initialized")
+		return nil, ErrNotInitialized
+	}
+
+	// Try the primary secret first, then any previous secrets still inside the rotation window
+	var lastErr error
+	for _, secret := range verificationSecrets() {
+		claims, err := parseWithSecret(tokenString, secret)
+		if err == nil {
+			return claims, nil
+		}
+		lastErr = err
+		if !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+			break
+		}
+	}
+
+	return nil, lastErr
+}
+
+// ParseTokenOfType parses a token like ParseToken and also checks its token_type claim,
+// returning a *TokenTypeError when it is not expectedType. Tokens without the claim count as
+// access tokens unless JWT_REQUIRE_TOKEN_TYPE is set.
+func ParseTokenOfType(tokenString, expectedType string) (*Claims, error) {
+	claims, err := ParseToken(tokenString)
+	if err != nil {
+		return nil, err
 	}
 
+	actual := claims.TokenType
+	if actual == "" && !cfg.JWT.RequireTokenType {
+		actual = TokenTypeAccess
+	}
+	if actual != expectedType {
+		return nil, &TokenTypeError{Expected: expectedType, Actual: claims.TokenType}
+	}
+	return claims, nil
+}
+
+// verificationSecrets returns the primary secret followed by previous ones
+func verificationSecrets() []string {
+	secrets := make([]string, 0, 1+len(cfg.JWT.PreviousSecrets))
+	secrets = append(secrets, cfg.JWT.Secret)
+	for _, secret := range cfg.JWT.PreviousSecrets {
+		if secret != "" && secret != cfg.JWT.Secret {
+			secrets = append(secrets, secret)
+		}
+	}
+	return secrets
+}
+
+// parseWithSecret parses and validates a token signed with secret
+func parseWithSecret(tokenString, secret string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(cfg.JWT.Secret), nil
+		return []byte(secret), nil
 	})
 
 	if err != nil {
@@ -68,3 +244,40 @@ func ParseToken(tokenString string) (*Claims, error) {
 
 	return nil, fmt.Errorf("invalid token")
 }
+
+// ExtraClaim returns the extra claim stored under key
+func (c *Claims) ExtraClaim(key string) (string, bool) {
+	value, ok := c.Extra[key]
+	return value, ok
+}
+
+// validateExtraClaims enforces the size limits on extra claims
+func validateExtraClaims(extra map[string]string) error {
+	if len(extra) > MaxExtraClaims {
+		return fmt.Errorf("%w: at most %d allowed", ErrInvalidExtraClaims, MaxExtraClaims)
+	}
+	for key, value := range extra {
+		if key == "" {
+			return fmt.Errorf("%w: empty key", ErrInvalidExtraClaims)
+		}
+		if utf8.RuneCountInString(key) > MaxExtraClaimKeyLen {
+			return fmt.Errorf("%w: key %q exceeds %d characters", ErrInvalidExtraClaims, key, MaxExtraClaimKeyLen)
+		}
+		if utf8.RuneCountInString(value) > MaxExtraClaimValueLen {
+			return fmt.Errorf("%w: value for %q exceeds %d characters", ErrInvalidExtraClaims, key, MaxExtraClaimValueLen)
+		}
+	}
+	return nil
+}
+
+// copyExtraClaims copies extra so later changes by the caller don't leak into the token
+func copyExtraClaims(extra map[string]string) map[string]string {
+	if len(extra) == 0 {
+		return nil
+	}
+	copied := make(map[string]string, len(extra))
+	for key, value := range extra {
+		copied[key] = value
+	}
+	return copied
+}
@@ -0,0 +1,286 @@
+package jwt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/llamacto/llama-gin-kit/config"
+)
+
+var (
+	oldSecret = strings.Repeat("o", MinSecretLength)
+	newSecret = strings.Repeat("n", MinSecretLength)
+)
+
+// useConfig initialises the package with the given secrets for the rest of the test
+func useConfig(t *testing.T, secret string, previous ...string) {
+	t.Helper()
+	useJWTConfig(t, config.JWTConfig{Secret: secret, PreviousSecrets: previous})
+}
+
+// useJWTConfig initialises the package with c and a one hour expiry for the rest of the test
+func useJWTConfig(t *testing.T, c config.JWTConfig) {
+	t.Helper()
+	saved := cfg
+	t.Cleanup(func() { cfg = saved })
+
+	c.ExpireDuration = time.Hour
+	if err := Init(&config.Config{JWT: c}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+}
+
+// untypedToken signs a token without a token_type claim, as issued before the claim existed
+func untypedToken(t *testing.T) string {
+	t.Helper()
+	now := time.Now()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		UserID: 7,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}).SignedString([]byte(newSecret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+func TestParseTokenDuringRotation(t *testing.T) {
+	tests := []struct {
+		name     string
+		signedBy string
+		previous []string
+		wantErr  error
+	}{
+		{name: "token from the current secret", signedBy: newSecret},
+		{name: "old token during the overlap", signedBy: oldSecret, previous: []string{oldSecret}},
+		{name: "old token after the overlap", signedBy: oldSecret, wantErr: jwt.ErrTokenSignatureInvalid},
+		{name: "token from an unknown secret", signedBy: strings.Repeat("x", MinSecretLength), previous: []string{oldSecret}, wantErr: jwt.ErrTokenSignatureInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfig(t, tt.signedBy)
+			token, err := GenerateToken(7, "dev")
+			if err != nil {
+				t.Fatalf("GenerateToken() error = %v", err)
+			}
+
+			useConfig(t, newSecret, tt.previous...)
+			claims, err := ParseToken(token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseToken() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && claims.UserID != 7 {
+				t.Fatalf("ParseToken() user = %d, want 7", claims.UserID)
+			}
+		})
+	}
+}
+
+func TestGenerateTokenSignsWithPrimarySecret(t *testing.T) {
+	useConfig(t, newSecret, oldSecret)
+	token, err := GenerateToken(7, "dev")
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+
+	if _, err := parseWithSecret(token, newSecret); err != nil {
+		t.Fatalf("token does not verify with the primary secret: %v", err)
+	}
+	if _, err := parseWithSecret(token, oldSecret); !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+		t.Fatalf("token verifies with a previous secret: %v", err)
+	}
+}
+
+func TestParseTokenRejectsExpiredOldToken(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		UserID: 7,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(past),
+			IssuedAt:  jwt.NewNumericDate(past.Add(-time.Hour)),
+		},
+	}).SignedString([]byte(oldSecret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+
+	useConfig(t, newSecret, oldSecret)
+	if _, err := ParseToken(token); !errors.Is(err, jwt.ErrTokenExpired) {
+		t.Fatalf("ParseToken() error = %v, want %v", err, jwt.ErrTokenExpired)
+	}
+}
+
+func TestParseTokenOfType(t *testing.T) {
+	tests := []struct {
+		name        string
+		tokenType   string // Empty signs an untyped token
+		requireType bool
+		expected    string
+		wantErr     error
+	}{
+		{name: "access token as access", tokenType: TokenTypeAccess, expected: TokenTypeAccess},
+		{name: "refresh token as refresh", tokenType: TokenTypeRefresh, expected: TokenTypeRefresh},
+		{name: "refresh token as access", tokenType: TokenTypeRefresh, expected: TokenTypeAccess, wantErr: ErrTokenTypeMismatch},
+		{name: "access token as refresh", tokenType: TokenTypeAccess, expected: TokenTypeRefresh, wantErr: ErrTokenTypeMismatch},
+		{name: "untyped token counts as access", expected: TokenTypeAccess},
+		{name: "untyped token is not a refresh token", expected: TokenTypeRefresh, wantErr: ErrTokenTypeMismatch},
+		{name: "untyped token when types are required", requireType: true, expected: TokenTypeAccess, wantErr: ErrTokenTypeMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useJWTConfig(t, config.JWTConfig{Secret: newSecret, RequireTokenType: tt.requireType})
+
+			token := untypedToken(t)
+			if tt.tokenType != "" {
+				var err error
+				token, err = GenerateTokenWithOptions(7, "dev", TokenOptions{TokenType: tt.tokenType})
+				if err != nil {
+					t.Fatalf("GenerateTokenWithOptions() error = %v", err)
+				}
+			}
+
+			claims, err := ParseTokenOfType(token, tt.expected)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseTokenOfType() error = %v, want %v", err, tt.wantErr)
+			}
+			var typeErr *TokenTypeError
+			if tt.wantErr != nil && (!errors.As(err, &typeErr) || typeErr.Expected != tt.expected || typeErr.Actual != tt.tokenType) {
+				t.Fatalf("ParseTokenOfType() error = %#v, want expected %q and actual %q", err, tt.expected, tt.tokenType)
+			}
+			if err == nil && claims.UserID != 7 {
+				t.Fatalf("ParseTokenOfType() user = %d, want 7", claims.UserID)
+			}
+		})
+	}
+}
+
+func TestGenerateTokenRejectsUnknownType(t *testing.T) {
+	useConfig(t, newSecret)
+	if _, err := GenerateTokenWithOptions(7, "dev", TokenOptions{TokenType: "session"}); err == nil {
+		t.Fatal("GenerateTokenWithOptions() accepted an unknown token type")
+	}
+}
+
+func TestInit(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *config.Config
+		wantErr bool
+	}{
+		{name: "nil config", wantErr: true},
+		{name: "short secret", cfg: &config.Config{JWT: config.JWTConfig{Secret: "short", ExpireDuration: time.Hour}}, wantErr: true},
+		{name: "no expiry", cfg: &config.Config{JWT: config.JWTConfig{Secret: newSecret}}, wantErr: true},
+		{name: "valid", cfg: &config.Config{JWT: config.JWTConfig{Secret: newSecret, ExpireDuration: time.Hour}}},
+	}
+
+	saved := cfg
+	t.Cleanup(func() { cfg = saved })
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Init(tt.cfg); (err != nil) != tt.wantErr {
+				t.Fatalf("Init() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtraClaims(t *testing.T) {
+	tooMany := make(map[string]string, MaxExtraClaims+1)
+	for i := 0; i <= MaxExtraClaims; i++ {
+		tooMany[strings.Repeat("k", i+1)] = "v"
+	}
+
+	tests := []struct {
+		name    string
+		extra   map[string]string
+		wantErr error
+	}{
+		{name: "no extra claims"},
+		{name: "default organization and tenant", extra: map[string]string{"default_org_id": "42", "tenant": "acme"}},
+		{name: "longest key and value", extra: map[string]string{strings.Repeat("k", MaxExtraClaimKeyLen): strings.Repeat("v", MaxExtraClaimValueLen)}},
+		{name: "too many claims", extra: tooMany, wantErr: ErrInvalidExtraClaims},
+		{name: "empty key", extra: map[string]string{"": "v"}, wantErr: ErrInvalidExtraClaims},
+		{name: "key too long", extra: map[string]string{strings.Repeat("k", MaxExtraClaimKeyLen+1): "v"}, wantErr: ErrInvalidExtraClaims},
+		{name: "value too long", extra: map[string]string{"tenant": strings.Repeat("v", MaxExtraClaimValueLen+1)}, wantErr: ErrInvalidExtraClaims},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfig(t, newSecret)
+
+			token, err := GenerateTokenWithOptions(7, "dev", TokenOptions{ExtraClaims: tt.extra})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GenerateTokenWithOptions() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			claims, err := ParseToken(token)
+			if err != nil {
+				t.Fatalf("ParseToken() error = %v", err)
+			}
+			if len(claims.Extra) != len(tt.extra) {
+				t.Fatalf("Extra = %v, want %v", claims.Extra, tt.extra)
+			}
+			for key, value := range tt.extra {
+				if claims.Extra[key] != value {
+					t.Fatalf("Extra[%q] = %q, want %q", key, claims.Extra[key], value)
+				}
+			}
+		})
+	}
+}
+
+func TestUninitialized(t *testing.T) {
+	saved := cfg
+	cfg = nil
+	t.Cleanup(func() { cfg = saved })
+
+	if Initialized() {
+		t.Fatal("Initialized() = true before Init")
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{name: "GenerateToken", call: func() error { _, err := GenerateToken(7, "dev"); return err }},
+		{name: "GenerateRefreshToken", call: func() error { _, err := GenerateRefreshToken(7, "dev"); return err }},
+		{name: "GenerateTokenForOrg", call: func() error { _, err := GenerateTokenForOrg(7, "dev", 3); return err }},
+		{name: "ParseToken", call: func() error { _, err := ParseToken("token"); return err }},
+		{name: "ParseTokenOfType", call: func() error { _, err := ParseTokenOfType("token", TokenTypeAccess); return err }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, ErrNotInitialized) {
+				t.Fatalf("%s() error = %v, want %v", tt.name, err, ErrNotInitialized)
+			}
+		})
+	}
+}
+
+func TestFailedInitLeavesServiceUninitialized(t *testing.T) {
+	saved := cfg
+	cfg = nil
+	t.Cleanup(func() { cfg = saved })
+
+	if err := Init(&config.Config{JWT: config.JWTConfig{Secret: "short", ExpireDuration: time.Hour}}); err == nil {
+		t.Fatal("Init() accepted a short secret")
+	}
+	if Initialized() {
+		t.Fatal("Initialized() = true after a failed Init")
+	}
+	if _, err := GenerateToken(7, "dev"); !errors.Is(err, ErrNotInitialized) {
+		t.Fatalf("GenerateToken() error = %v, want %v", err, ErrNotInitialized)
+	}
+}
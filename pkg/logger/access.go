@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"os"
+
+	"github.com/llamacto/llama-gin-kit/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var access *zap.Logger
+
+// InitAccess builds the JSON access logger from the log configuration. Lines go to stdout
+// and, when cfg.Filename is set, to the same rotating file as the application logger.
+// cfg.Level sets the minimum level.
+func InitAccess(cfg config.LogConfig) error {
+	level, err := parseLevel(cfg.Level)
+	if err != nil {
+		return err
+	}
+
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	encoderConfig.MessageKey = "msg"
+	encoder := zapcore.NewJSONEncoder(encoderConfig)
+
+	cores := []zapcore.Core{zapcore.NewCore(encoder, zapcore.Lock(os.Stdout), level)}
+	if cfg.Filename != "" {
+		file, err := openRotatingFile(cfg)
+		if err != nil {
+			return err
+		}
+		cores = append(cores, zapcore.NewCore(encoder, file, level))
+	}
+
+	// Every request is logged, without sampling, callers or stack traces
+	access = zap.New(zapcore.NewTee(cores...))
+	return nil
+}
+
+// Access returns the access logger, falling back to JSON on stdout when InitAccess was not called
+func Access() *zap.Logger {
+	if access == nil {
+		if err := InitAccess(config.LogConfig{}); err != nil {
+			panic("failed to initialize access logger: " + err.Error())
+		}
+	}
+	return access
+}
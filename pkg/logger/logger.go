@@ -1,33 +1,76 @@
 package logger
 
 import (
+	"fmt"
 	"os"
 
+	"github.com/llamacto/llama-gin-kit/config"
+
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
 var log *zap.Logger
 
-// Init initializes the logger
-func Init() {
-	config := zap.NewDevelopmentConfig()
-	config.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
-	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-	config.OutputPaths = []string{"stdout"}
-	config.ErrorOutputPaths = []string{"stderr"}
+// Init builds the application logger from the log configuration. Entries at or above
+// cfg.Level are written as JSON to cfg.Filename, rotated per MaxSize, MaxAge, MaxBackups
+// and Compress, and echoed in color to stdout when cfg.Console is set.
+func Init(cfg config.LogConfig) error {
+	level, err := parseLevel(cfg.Level)
+	if err != nil {
+		return err
+	}
 
-	var err error
-	log, err = config.Build()
+	var cores []zapcore.Core
+	if cfg.Filename != "" {
+		file, err := openRotatingFile(cfg)
+		if err != nil {
+			return err
+		}
+		encoderConfig := zap.NewProductionEncoderConfig()
+		encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+		cores = append(cores, zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), file, level))
+	}
+	if cfg.Console || len(cores) == 0 {
+		cores = append(cores, zapcore.NewCore(zapcore.NewConsoleEncoder(consoleEncoderConfig()), zapcore.Lock(os.Stdout), level))
+	}
+
+	log = zap.New(zapcore.NewTee(cores...), zap.AddCaller(), zap.AddCallerSkip(1), zap.AddStacktrace(zapcore.ErrorLevel))
+	return nil
+}
+
+// initDefault sets up a debug-level console logger for use before Init is called
+func initDefault() {
+	log = zap.New(
+		zapcore.NewCore(zapcore.NewConsoleEncoder(consoleEncoderConfig()), zapcore.Lock(os.Stdout), zapcore.DebugLevel),
+		zap.AddCaller(), zap.AddCallerSkip(1), zap.AddStacktrace(zapcore.ErrorLevel),
+	)
+}
+
+// consoleEncoderConfig is the human-readable encoding used for stdout
+func consoleEncoderConfig() zapcore.EncoderConfig {
+	encoderConfig := zap.NewDevelopmentEncoderConfig()
+	encoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	return encoderConfig
+}
+
+// parseLevel parses a level name, defaulting to info when empty
+func parseLevel(name string) (zapcore.Level, error) {
+	if name == "" {
+		return zapcore.InfoLevel, nil
+	}
+	level, err := zapcore.ParseLevel(name)
 	if err != nil {
-		panic("failed to initialize logger: " + err.Error())
+		return level, fmt.Errorf("invalid log level %q: %w", name, err)
 	}
+	return level, nil
 }
 
 // Error logs an error message
 func Error(msg string, err error) {
 	if log == nil {
-		Init()
+		initDefault()
 	}
 	log.Error(msg, zap.Error(err))
 }
@@ -35,7 +78,7 @@ func Error(msg string, err error) {
 // Info logs an info message
 func Info(msg string, args ...interface{}) {
 	if log == nil {
-		Init()
+		initDefault()
 	}
 	log.Sugar().Infof(msg, args...)
 }
@@ -43,7 +86,7 @@ func Info(msg string, args ...interface{}) {
 // Debug logs a debug message
 func Debug(msg string, args ...interface{}) {
 	if log == nil {
-		Init()
+		initDefault()
 	}
 	log.Sugar().Debugf(msg, args...)
 }
@@ -51,7 +94,7 @@ func Debug(msg string, args ...interface{}) {
 // Warn logs a warning message
 func Warn(msg string, args ...interface{}) {
 	if log == nil {
-		Init()
+		initDefault()
 	}
 	log.Sugar().Warnf(msg, args...)
 }
@@ -59,7 +102,7 @@ func Warn(msg string, args ...interface{}) {
 // Fatal logs a fatal message and exits the program
 func Fatal(msg string, args ...interface{}) {
 	if log == nil {
-		Init()
+		initDefault()
 	}
 	log.Sugar().Fatalf(msg, args...)
 	os.Exit(1)
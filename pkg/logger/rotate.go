@@ -0,0 +1,278 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/config"
+)
+
+const (
+	// defaultMaxSize is the rotation size in megabytes when LogConfig.MaxSize is not set
+	defaultMaxSize = 100
+	// backupTimeFormat is embedded in rotated file names, e.g. app-2025-06-28T10-04-05.000.log
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+	compressSuffix   = ".gz"
+)
+
+// rotatingFile is a zapcore.WriteSyncer that appends to a file and rotates it once it
+// grows past maxSize. Old backups are removed by age and count and optionally gzipped.
+type rotatingFile struct {
+	filename   string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+	compress   bool
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+
+	millMu sync.Mutex // Serializes backup cleanup and compression
+}
+
+var (
+	rotatingFilesMu sync.Mutex
+	rotatingFiles   = map[string]*rotatingFile{}
+)
+
+// openRotatingFile returns the writer for cfg.Filename, sharing one writer per file so the
+// application and access loggers never rotate the same file independently
+func openRotatingFile(cfg config.LogConfig) (*rotatingFile, error) {
+	rotatingFilesMu.Lock()
+	defer rotatingFilesMu.Unlock()
+
+	if existing, ok := rotatingFiles[cfg.Filename]; ok {
+		return existing, nil
+	}
+
+	maxSize := cfg.MaxSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxSize
+	}
+	r := &rotatingFile{
+		filename:   cfg.Filename,
+		maxSize:    int64(maxSize) * 1024 * 1024,
+		maxAge:     time.Duration(cfg.MaxAge) * 24 * time.Hour,
+		maxBackups: cfg.MaxBackups,
+		compress:   cfg.Compress,
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	rotatingFiles[cfg.Filename] = r
+	return r, nil
+}
+
+// Write appends p to the current file, rotating first if p would push it past maxSize
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+	if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Sync flushes the current file to disk
+func (r *rotatingFile) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+	return r.file.Sync()
+}
+
+// open opens the log file for appending, creating its directory if missing
+func (r *rotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(r.filename), 0o755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(r.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+// rotate moves the current file aside under a timestamped name and starts a new one
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+	r.file = nil
+
+	if err := os.Rename(r.filename, r.backupName(time.Now())); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	if err := r.open(); err != nil {
+		return err
+	}
+
+	go r.mill()
+	return nil
+}
+
+// backupName returns the rotated file name for t, e.g. logs/app-2025-06-28T10-04-05.000.log
+func (r *rotatingFile) backupName(t time.Time) string {
+	prefix, ext := r.nameParts()
+	return filepath.Join(filepath.Dir(r.filename), prefix+t.Format(backupTimeFormat)+ext)
+}
+
+// nameParts splits the base file name into the backup prefix and extension
+func (r *rotatingFile) nameParts() (prefix, ext string) {
+	base := filepath.Base(r.filename)
+	ext = filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "-", ext
+}
+
+// logBackup is a rotated file and the time it was rotated
+type logBackup struct {
+	path       string
+	rotatedAt  time.Time
+	compressed bool
+}
+
+// mill compresses new backups and removes those beyond maxBackups or older than maxAge
+func (r *rotatingFile) mill() {
+	r.millMu.Lock()
+	defer r.millMu.Unlock()
+
+	backups, err := r.backups()
+	if err != nil {
+		Error("failed to list log backups", err)
+		return
+	}
+
+	var remove []logBackup
+	if r.maxBackups > 0 && len(backups) > r.maxBackups {
+		remove = append(remove, backups[r.maxBackups:]...)
+		backups = backups[:r.maxBackups]
+	}
+	if r.maxAge > 0 {
+		cutoff := time.Now().Add(-r.maxAge)
+		kept := backups[:0]
+		for _, b := range backups {
+			if b.rotatedAt.Before(cutoff) {
+				remove = append(remove, b)
+			} else {
+				kept = append(kept, b)
+			}
+		}
+		backups = kept
+	}
+
+	for _, b := range remove {
+		if err := os.Remove(b.path); err != nil && !os.IsNotExist(err) {
+			Error("failed to remove log backup", err)
+		}
+	}
+
+	if r.compress {
+		for _, b := range backups {
+			if b.compressed {
+				continue
+			}
+			if err := compressFile(b.path); err != nil {
+				Error("failed to compress log backup", err)
+			}
+		}
+	}
+}
+
+// backups lists rotated files next to the log file, newest first
+func (r *rotatingFile) backups() ([]logBackup, error) {
+	dir := filepath.Dir(r.filename)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, ext := r.nameParts()
+	var backups []logBackup
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		compressed := strings.HasSuffix(name, ext+compressSuffix)
+		stamp, ok := strings.CutPrefix(strings.TrimSuffix(name, compressSuffix), prefix)
+		if !ok {
+			continue
+		}
+		stamp, ok = strings.CutSuffix(stamp, ext)
+		if !ok {
+			continue
+		}
+		rotatedAt, err := time.ParseInLocation(backupTimeFormat, stamp, time.Local)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, logBackup{path: filepath.Join(dir, name), rotatedAt: rotatedAt, compressed: compressed})
+	}
+
+	sort.Slice(backups, func(i, j int) bool { return backups[i].rotatedAt.After(backups[j].rotatedAt) })
+	return backups, nil
+}
+
+// compressFile gzips path into path.gz and removes the original
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+compressSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		dst.Close()
+		os.Remove(path + compressSuffix)
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		os.Remove(path + compressSuffix)
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(path + compressSuffix)
+		return err
+	}
+
+	src.Close()
+	return os.Remove(path)
+}
@@ -1,11 +1,14 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/pkg/authctx"
 	"github.com/llamacto/llama-gin-kit/pkg/jwt"
+	"github.com/llamacto/llama-gin-kit/pkg/logger"
 )
 
 // JWTAuth is a JWT authentication middleware
@@ -26,8 +29,15 @@ func JWTAuth() gin.HandlerFunc {
 			return
 		}
 
-		// Parse token
-		claims, err := jwt.ParseToken(parts[1])
+		// Parse token; refresh tokens cannot authenticate requests
+		claims, err := jwt.ParseTokenOfType(parts[1], jwt.TokenTypeAccess)
+		if errors.Is(err, jwt.ErrNotInitialized) {
+			// A server misconfiguration, not a client error
+			logger.Error("JWT middleware used before jwt.Init", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Authentication service unavailable", "code": jwt.ErrCodeNotInitialized})
+			c.Abort()
+			return
+		}
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			c.Abort()
@@ -35,9 +45,26 @@ func JWTAuth() gin.HandlerFunc {
 		}
 
 		// Store user information in context
-		c.Set("userID", claims.UserID)
-		c.Set("username", claims.Username)
+		authctx.SetUserID(c, claims.UserID)
+		authctx.SetUsername(c, claims.Username)
+		if claims.ActiveOrganizationID != 0 {
+			authctx.SetActiveOrganizationID(c, claims.ActiveOrganizationID)
+		}
+
+		c.Next()
+	}
+}
 
+// QueryToken lets clients that cannot set headers, such as browser WebSockets, pass the
+// access token in the named query parameter. It fills in the Authorization header for
+// JWTAuth when the request has none, so it must run before JWTAuth.
+func QueryToken(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if token := c.Query(param); token != "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
 		c.Next()
 	}
 }
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/config"
+	"github.com/llamacto/llama-gin-kit/pkg/jwt"
+)
+
+func TestJWTAuthBeforeInit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	if jwt.Initialized() {
+		t.Skip("jwt was initialised by another test in this package")
+	}
+
+	router := gin.New()
+	router.GET("/me", JWTAuth(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name     string
+		header   string
+		want     int
+		wantCode string
+	}{
+		{name: "bearer token", header: "Bearer some.token.value", want: http.StatusInternalServerError, wantCode: jwt.ErrCodeNotInitialized},
+		{name: "missing header", header: "", want: http.StatusUnauthorized},
+		{name: "malformed header", header: "Token abc", want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			var body struct {
+				Code string `json:"code"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if body.Code != tt.wantCode {
+				t.Fatalf("code = %q, want %q", body.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestJWTAuthAcceptsOnlyAccessTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	if !jwt.Initialized() {
+		if err := jwt.Init(&config.Config{JWT: config.JWTConfig{Secret: strings.Repeat("s", jwt.MinSecretLength), ExpireDuration: time.Hour}}); err != nil {
+			t.Fatalf("jwt.Init() error = %v", err)
+		}
+	}
+
+	router := gin.New()
+	router.GET("/me", JWTAuth(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name      string
+		tokenType string
+		want      int
+	}{
+		{name: "access token", tokenType: jwt.TokenTypeAccess, want: http.StatusOK},
+		{name: "refresh token", tokenType: jwt.TokenTypeRefresh, want: http.StatusUnauthorized},
+		{name: "two-factor pending token", tokenType: jwt.TokenTypeTwoFactor, want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := jwt.GenerateTokenWithOptions(7, "ada", jwt.TokenOptions{TokenType: tt.tokenType})
+			if err != nil {
+				t.Fatalf("GenerateTokenWithOptions() error = %v", err)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/pkg/signedurl"
+)
+
+// SignedURL rejects requests whose expires/signature query parameters are not a valid
+// signature for the given path parameter. It replaces authentication on public download
+// routes, so resources cannot be fetched by guessing IDs.
+func SignedURL(signer *signedurl.Signer, param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := signer.Verify(
+			c.Param(param),
+			c.Query(signedurl.ExpiresParam),
+			c.Query(signedurl.SignatureParam),
+			time.Now(),
+		)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/pkg/signedurl"
+)
+
+func TestSignedURL(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	signer, _ := signedurl.NewSigner("test-secret")
+
+	router := gin.New()
+	router.GET("/audio/:id", SignedURL(signer, "id"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name string
+		url  string
+		want int
+	}{
+		{"valid signed url", signer.SignURL("/audio/1", "1", time.Minute), http.StatusOK},
+		{"expired signed url", signer.SignURLUntil("/audio/1", "1", time.Now().Add(-time.Minute)), http.StatusForbidden},
+		{"unsigned request", "/audio/1", http.StatusForbidden},
+		{"signature for another id", signer.SignURL("/audio/2", "1", time.Minute), http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package openai
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/sashabaranov/go-openai"
 	"github.com/llamacto/llama-gin-kit/config"
@@ -10,6 +11,9 @@ import (
 
 var client *openai.Client
 
+// ErrNotInitialized is returned when the client is used before Init
+var ErrNotInitialized = errors.New("openai client not initialized")
+
 // Init initializes the OpenAI client
 func Init(cfg *config.Config) error {
 	client = openai.NewClient(cfg.OpenAI.APIKey)
@@ -23,11 +27,21 @@ func GetClient() *openai.Client {
 
 // GenerateAudio generates audio from text using OpenAI's TTS API
 func GenerateAudio(ctx context.Context, text string) ([]byte, error) {
+	return GenerateSpeech(ctx, text, string(openai.VoiceAlloy))
+}
+
+// GenerateSpeech generates MP3 audio from text with the given voice using OpenAI's TTS API
+func GenerateSpeech(ctx context.Context, text, voice string) ([]byte, error) {
+	if client == nil {
+		return nil, ErrNotInitialized
+	}
+
 	// Create audio file
 	req := openai.CreateSpeechRequest{
-		Model: openai.TTSModel1,
-		Input: text,
-		Voice: openai.VoiceAlloy,
+		Model:          openai.TTSModel1,
+		Input:          text,
+		Voice:          openai.SpeechVoice(voice),
+		ResponseFormat: openai.SpeechResponseFormatMp3,
 	}
 
 	// Get audio data from OpenAI
@@ -44,3 +58,29 @@ func GenerateAudio(ctx context.Context, text string) ([]byte, error) {
 
 	return data, nil
 }
+
+// Translate translates text into the named target language using a chat completion
+func Translate(ctx context.Context, text, targetLanguage string) (string, error) {
+	if client == nil {
+		return "", ErrNotInitialized
+	}
+
+	resp, err := client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model: openai.GPT4oMini,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: "Translate the user's text into " + targetLanguage + ". Reply with the translation only.",
+			},
+			{Role: openai.ChatMessageRoleUser, Content: text},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to translate text: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("translation returned no choices")
+	}
+
+	return resp.Choices[0].Message.Content, nil
+}
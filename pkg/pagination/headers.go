@@ -0,0 +1,59 @@
+package pagination
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// TotalCountHeader carries the total number of items across all pages
+	TotalCountHeader = "X-Total-Count"
+	// LinkHeader carries RFC 8288 links to the first, previous, next and last pages
+	LinkHeader = "Link"
+)
+
+// SetHeaders writes X-Total-Count and a Link header for a page of a list response, so
+// clients can paginate from headers as well as from the body total. Links reuse the
+// request URL with "page" and "page_size" replaced; other query parameters are kept.
+func SetHeaders(c *gin.Context, total int64, page, size int) {
+	c.Header(TotalCountHeader, strconv.FormatInt(total, 10))
+	if link := linkHeader(c.Request.URL, total, page, size); link != "" {
+		c.Header(LinkHeader, link)
+	}
+}
+
+// linkHeader builds the Link value for page of size items out of total
+func linkHeader(u *url.URL, total int64, page, size int) string {
+	if u == nil || size < 1 {
+		return ""
+	}
+	last := TotalPages(total, size)
+	if last < 1 {
+		last = 1
+	}
+
+	links := []string{pageLink(u, 1, size, "first")}
+	if page > 1 {
+		links = append(links, pageLink(u, min(page-1, last), size, "prev"))
+	}
+	if page < last {
+		links = append(links, pageLink(u, page+1, size, "next"))
+	}
+	links = append(links, pageLink(u, last, size, "last"))
+	return strings.Join(links, ", ")
+}
+
+// pageLink formats one Link entry pointing at page
+func pageLink(u *url.URL, page, size int, rel string) string {
+	query := u.Query()
+	query.Del("size") // Legacy alias of page_size
+	query.Set("page", strconv.Itoa(page))
+	query.Set("page_size", strconv.Itoa(size))
+
+	target := url.URL{Path: u.Path, RawQuery: query.Encode()}
+	return fmt.Sprintf("<%s>; rel=%q", target.String(), rel)
+}
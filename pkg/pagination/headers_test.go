@@ -0,0 +1,94 @@
+package pagination
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSetHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name      string
+		target    string
+		total     int64
+		page      int
+		size      int
+		wantTotal string
+		wantLink  string
+	}{
+		{
+			name:      "first page",
+			target:    "/teams?page=1&page_size=20",
+			total:     45,
+			page:      1,
+			size:      20,
+			wantTotal: "45",
+			wantLink:  `</teams?page=1&page_size=20>; rel="first", </teams?page=2&page_size=20>; rel="next", </teams?page=3&page_size=20>; rel="last"`,
+		},
+		{
+			name:      "middle page",
+			target:    "/teams?page=2&page_size=20",
+			total:     45,
+			page:      2,
+			size:      20,
+			wantTotal: "45",
+			wantLink:  `</teams?page=1&page_size=20>; rel="first", </teams?page=1&page_size=20>; rel="prev", </teams?page=3&page_size=20>; rel="next", </teams?page=3&page_size=20>; rel="last"`,
+		},
+		{
+			name:      "last page",
+			target:    "/teams?page=3&page_size=20",
+			total:     45,
+			page:      3,
+			size:      20,
+			wantTotal: "45",
+			wantLink:  `</teams?page=1&page_size=20>; rel="first", </teams?page=2&page_size=20>; rel="prev", </teams?page=3&page_size=20>; rel="last"`,
+		},
+		{
+			name:      "page past the end",
+			target:    "/teams?page=9&page_size=20",
+			total:     45,
+			page:      9,
+			size:      20,
+			wantTotal: "45",
+			wantLink:  `</teams?page=1&page_size=20>; rel="first", </teams?page=3&page_size=20>; rel="prev", </teams?page=3&page_size=20>; rel="last"`,
+		},
+		{
+			name:      "empty list",
+			target:    "/teams",
+			total:     0,
+			page:      1,
+			size:      20,
+			wantTotal: "0",
+			wantLink:  `</teams?page=1&page_size=20>; rel="first", </teams?page=1&page_size=20>; rel="last"`,
+		},
+		{
+			name:      "other query parameters are kept and the legacy size alias dropped",
+			target:    "/users?size=10&status=1",
+			total:     15,
+			page:      1,
+			size:      10,
+			wantTotal: "15",
+			wantLink:  `</users?page=1&page_size=10&status=1>; rel="first", </users?page=2&page_size=10&status=1>; rel="next", </users?page=2&page_size=10&status=1>; rel="last"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", tt.target, nil)
+
+			SetHeaders(c, tt.total, tt.page, tt.size)
+
+			if got := w.Header().Get(TotalCountHeader); got != tt.wantTotal {
+				t.Fatalf("%s = %q, want %q", TotalCountHeader, got, tt.wantTotal)
+			}
+			if got := w.Header().Get(LinkHeader); got != tt.wantLink {
+				t.Fatalf("%s = %s\nwant %s", LinkHeader, got, tt.wantLink)
+			}
+		})
+	}
+}
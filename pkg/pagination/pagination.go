@@ -0,0 +1,64 @@
+// Package pagination parses and applies page-based pagination consistently across handlers.
+package pagination
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultPageSize is used when a request omits the page size or sends an invalid one
+	DefaultPageSize = 20
+	// MaxPageSize caps the page size so a single request cannot load an unbounded result set
+	MaxPageSize = 100
+)
+
+// Parse reads "page" and "page_size" from the query string, falling back to the legacy
+// "size" parameter, and returns values clamped by Normalize
+func Parse(c *gin.Context, defaultSize int) (page, size int) {
+	page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+
+	sizeParam := c.Query("page_size")
+	if sizeParam == "" {
+		sizeParam = c.Query("size")
+	}
+	size, _ = strconv.Atoi(sizeParam)
+
+	return Normalize(page, size, defaultSize)
+}
+
+// Normalize returns a page of at least 1 and a size between 1 and MaxPageSize.
+// Invalid sizes fall back to defaultSize; sizes above the cap are reduced to MaxPageSize.
+func Normalize(page, size, defaultSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if defaultSize < 1 || defaultSize > MaxPageSize {
+		defaultSize = DefaultPageSize
+	}
+	if size < 1 {
+		size = defaultSize
+	}
+	if size > MaxPageSize {
+		size = MaxPageSize
+	}
+	return page, size
+}
+
+// Paginate returns a GORM scope applying the offset and limit for a page
+func Paginate(page, size int) func(db *gorm.DB) *gorm.DB {
+	page, size = Normalize(page, size, DefaultPageSize)
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Offset((page - 1) * size).Limit(size)
+	}
+}
+
+// TotalPages returns the number of pages needed for total items
+func TotalPages(total int64, size int) int {
+	if size < 1 {
+		return 0
+	}
+	return int((total + int64(size) - 1) / int64(size))
+}
@@ -0,0 +1,41 @@
+package pagination
+
+import "strings"
+
+// Sort directions accepted by list endpoints
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+// Sort is the allowlist of ORDER BY columns for one resource and its default column
+type Sort struct {
+	Columns []string
+	Default string
+}
+
+// Normalize returns orderBy when it is an allowed column and the default column otherwise,
+// and "asc" or "desc" for order in any case, falling back to "desc". Invalid input therefore
+// gets the default ordering instead of an error and never reaches SQL.
+func (s Sort) Normalize(orderBy, order string) (string, string) {
+	column := s.Default
+	for _, allowed := range s.Columns {
+		if orderBy == allowed {
+			column = orderBy
+			break
+		}
+	}
+
+	direction := SortDesc
+	if strings.EqualFold(order, SortAsc) {
+		direction = SortAsc
+	}
+	return column, direction
+}
+
+// Clause returns the ORDER BY clause for the normalized sort, qualifying the column with prefix
+// (e.g. "i." for an aliased table)
+func (s Sort) Clause(prefix, orderBy, order string) string {
+	column, direction := s.Normalize(orderBy, order)
+	return prefix + column + " " + strings.ToUpper(direction)
+}
@@ -0,0 +1,59 @@
+package pagination
+
+import "testing"
+
+func TestSortNormalize(t *testing.T) {
+	sort := Sort{Columns: []string{"id", "name", "created_at"}, Default: "created_at"}
+
+	tests := []struct {
+		name          string
+		orderBy       string
+		order         string
+		wantColumn    string
+		wantDirection string
+	}{
+		{name: "allowed column and direction", orderBy: "name", order: "asc", wantColumn: "name", wantDirection: "asc"},
+		{name: "direction in any case", orderBy: "id", order: "DESC", wantColumn: "id", wantDirection: "desc"},
+		{name: "mixed case ascending", orderBy: "id", order: "AsC", wantColumn: "id", wantDirection: "asc"},
+		{name: "invalid direction", orderBy: "name", order: "sideways", wantColumn: "name", wantDirection: "desc"},
+		{name: "injection-shaped direction", orderBy: "name", order: "asc; DROP TABLE roles", wantColumn: "name", wantDirection: "desc"},
+		{name: "disallowed column", orderBy: "password", order: "asc", wantColumn: "created_at", wantDirection: "asc"},
+		{name: "injection-shaped column", orderBy: "id; DROP TABLE roles", order: "asc", wantColumn: "created_at", wantDirection: "asc"},
+		{name: "column matching is case-sensitive", orderBy: "NAME", order: "asc", wantColumn: "created_at", wantDirection: "asc"},
+		{name: "empty values", wantColumn: "created_at", wantDirection: "desc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			column, direction := sort.Normalize(tt.orderBy, tt.order)
+			if column != tt.wantColumn || direction != tt.wantDirection {
+				t.Fatalf("Normalize(%q, %q) = %q, %q; want %q, %q",
+					tt.orderBy, tt.order, column, direction, tt.wantColumn, tt.wantDirection)
+			}
+		})
+	}
+}
+
+func TestSortClause(t *testing.T) {
+	sort := Sort{Columns: []string{"id", "created_at"}, Default: "created_at"}
+
+	tests := []struct {
+		prefix  string
+		orderBy string
+		order   string
+		want    string
+	}{
+		{orderBy: "id", order: "asc", want: "id ASC"},
+		{prefix: "i.", orderBy: "id", order: "desc", want: "i.id DESC"},
+		{prefix: "i.", orderBy: "1=1--", order: "asc", want: "i.created_at ASC"},
+		{orderBy: "id", order: "random()", want: "id DESC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := sort.Clause(tt.prefix, tt.orderBy, tt.order); got != tt.want {
+				t.Fatalf("Clause(%q, %q, %q) = %q, want %q", tt.prefix, tt.orderBy, tt.order, got, tt.want)
+			}
+		})
+	}
+}
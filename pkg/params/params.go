@@ -0,0 +1,27 @@
+// Package params parses numeric identifiers from request path parameters.
+package params
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrInvalidID is returned for IDs that are not positive integers
+var ErrInvalidID = errors.New("id must be a positive integer")
+
+// ParseID parses raw as a database ID. Zero, negative, non-numeric and out-of-range values
+// all return ErrInvalidID, since no row is ever stored with them.
+func ParseID(raw string) (uint, error) {
+	id, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil || id == 0 {
+		return 0, ErrInvalidID
+	}
+	return uint(id), nil
+}
+
+// PathID parses the named path parameter with ParseID
+func PathID(c *gin.Context, name string) (uint, error) {
+	return ParseID(c.Param(name))
+}
@@ -0,0 +1,73 @@
+package params
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseID(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    uint
+		wantErr bool
+	}{
+		{raw: "1", want: 1},
+		{raw: "42", want: 42},
+		{raw: "4294967295", want: 4294967295},
+		{raw: "0", wantErr: true},
+		{raw: "-1", wantErr: true},
+		{raw: "", wantErr: true},
+		{raw: "abc", wantErr: true},
+		{raw: "1.5", wantErr: true},
+		{raw: "+1", wantErr: true},
+		{raw: " 1", wantErr: true},
+		{raw: "4294967296", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			id, err := ParseID(tt.raw)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidID) {
+					t.Fatalf("ParseID(%q) error = %v, want %v", tt.raw, err, ErrInvalidID)
+				}
+				return
+			}
+			if err != nil || id != tt.want {
+				t.Fatalf("ParseID(%q) = %d, %v; want %d", tt.raw, id, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		path    string
+		want    uint
+		wantErr bool
+	}{
+		{path: "/items/7", want: 7},
+		{path: "/items/0", wantErr: true},
+		{path: "/items/-3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			var id uint
+			var err error
+			r := gin.New()
+			r.GET("/items/:id", func(c *gin.Context) { id, err = PathID(c, "id") })
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if (err != nil) != tt.wantErr || id != tt.want {
+				t.Fatalf("PathID() = %d, %v; want %d (error %v)", id, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock is a settable time source for TokenBucket
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func TestTokenBucket(t *testing.T) {
+	type step struct {
+		advance   time.Duration
+		key       string
+		give      bool // Return a token for key instead of taking one
+		want      bool
+		wantRetry time.Duration
+	}
+
+	tests := []struct {
+		name  string
+		rate  float64
+		burst int
+		steps []step
+	}{
+		{
+			name:  "burst then reject",
+			rate:  1,
+			burst: 2,
+			steps: []step{
+				{key: "a", want: true},
+				{key: "a", want: true},
+				{key: "a", want: false, wantRetry: time.Second},
+			},
+		},
+		{
+			name:  "refills at the rate",
+			rate:  2,
+			burst: 1,
+			steps: []step{
+				{key: "a", want: true},
+				{key: "a", want: false, wantRetry: 500 * time.Millisecond},
+				{advance: 250 * time.Millisecond, key: "a", want: false, wantRetry: 250 * time.Millisecond},
+				{advance: 250 * time.Millisecond, key: "a", want: true},
+			},
+		},
+		{
+			name:  "refill is capped at the burst",
+			rate:  1,
+			burst: 1,
+			steps: []step{
+				{key: "a", want: true},
+				{advance: time.Hour, key: "a", want: true},
+				{key: "a", want: false, wantRetry: time.Second},
+			},
+		},
+		{
+			name:  "keys have separate buckets",
+			rate:  1,
+			burst: 1,
+			steps: []step{
+				{key: "a", want: true},
+				{key: "b", want: true},
+				{key: "a", want: false, wantRetry: time.Second},
+			},
+		},
+		{
+			name:  "returned token can be taken again",
+			rate:  1,
+			burst: 1,
+			steps: []step{
+				{key: "a", want: true},
+				{key: "a", give: true},
+				{key: "a", want: true},
+				{key: "a", want: false, wantRetry: time.Second},
+			},
+		},
+		{
+			name:  "returning never exceeds the burst",
+			rate:  1,
+			burst: 1,
+			steps: []step{
+				{key: "a", want: true},
+				{key: "a", give: true},
+				{key: "a", give: true},
+				{key: "a", want: true},
+				{key: "a", want: false, wantRetry: time.Second},
+			},
+		},
+		{
+			name:  "burst below one holds one token",
+			rate:  1,
+			burst: 0,
+			steps: []step{
+				{key: "a", want: true},
+				{key: "a", want: false, wantRetry: time.Second},
+			},
+		},
+		{
+			name:  "zero rate never refills",
+			rate:  0,
+			burst: 1,
+			steps: []step{
+				{key: "a", want: true},
+				{advance: time.Hour, key: "a", want: false},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+			limiter := NewTokenBucket(tt.rate, tt.burst)
+			limiter.now = clock.Now
+
+			for i, s := range tt.steps {
+				clock.now = clock.now.Add(s.advance)
+				if s.give {
+					limiter.Return(s.key)
+					continue
+				}
+				allowed, retry := limiter.Allow(s.key)
+				if allowed != s.want || retry != s.wantRetry {
+					t.Fatalf("step %d: Allow(%q) = %v, %v; want %v, %v", i, s.key, allowed, retry, s.want, s.wantRetry)
+				}
+			}
+		})
+	}
+}
+
+func TestTokenBucketSweepsFullBuckets(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	limiter := NewTokenBucket(1, 1)
+	limiter.now = clock.Now
+
+	limiter.Allow("idle")
+	clock.now = clock.now.Add(time.Minute)
+	for i := 0; i < 999; i++ {
+		limiter.Allow("busy")
+	}
+
+	if _, ok := limiter.buckets["idle"]; ok {
+		t.Fatal("full bucket was not swept")
+	}
+	if _, ok := limiter.buckets["busy"]; !ok {
+		t.Fatal("empty bucket was swept")
+	}
+}
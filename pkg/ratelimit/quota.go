@@ -0,0 +1,142 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/pkg/logger"
+	"github.com/llamacto/llama-gin-kit/pkg/redis"
+)
+
+// QuotaResult describes a key's standing in the current quota window
+type QuotaResult struct {
+	Allowed   bool
+	Remaining int       // Requests left in the window after this one
+	ResetAt   time.Time // When the window ends and the count starts over
+}
+
+// QuotaCounter counts events per key in fixed windows, e.g. requests per minute
+type QuotaCounter interface {
+	// Hit records an event for key and reports whether it is within limit for the current window
+	Hit(ctx context.Context, key string, limit int, window time.Duration) (QuotaResult, error)
+}
+
+// NewQuotaCounter returns a Redis backed counter when a client is available,
+// falling back to an in-memory counter otherwise
+func NewQuotaCounter(client *redis.Client) QuotaCounter {
+	memory := NewMemoryQuotaCounter()
+	if client == nil {
+		return memory
+	}
+	return &fallbackQuotaCounter{
+		primary:  NewRedisQuotaCounter(client),
+		fallback: memory,
+	}
+}
+
+// windowBounds returns the start and end of the fixed window containing now
+func windowBounds(now time.Time, window time.Duration) (time.Time, time.Time) {
+	start := now.Truncate(window)
+	return start, start.Add(window)
+}
+
+// quotaResult builds the result for count events against limit
+func quotaResult(count int64, limit int, resetAt time.Time) QuotaResult {
+	remaining := int64(limit) - count
+	if remaining < 0 {
+		remaining = 0
+	}
+	return QuotaResult{Allowed: count <= int64(limit), Remaining: int(remaining), ResetAt: resetAt}
+}
+
+// MemoryQuotaCounter is a process-local fixed window counter
+type MemoryQuotaCounter struct {
+	mu      sync.Mutex
+	windows map[string]*quotaWindow
+	calls   int
+}
+
+type quotaWindow struct {
+	resetAt time.Time
+	count   int64
+}
+
+// NewMemoryQuotaCounter creates a new in-memory quota counter
+func NewMemoryQuotaCounter() *MemoryQuotaCounter {
+	return &MemoryQuotaCounter{windows: make(map[string]*quotaWindow)}
+}
+
+// Hit implements QuotaCounter
+func (q *MemoryQuotaCounter) Hit(ctx context.Context, key string, limit int, window time.Duration) (QuotaResult, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	_, resetAt := windowBounds(now, window)
+
+	// Periodically drop finished windows so the map doesn't grow without bound
+	q.calls++
+	if q.calls%1000 == 0 {
+		for k, w := range q.windows {
+			if !now.Before(w.resetAt) {
+				delete(q.windows, k)
+			}
+		}
+	}
+
+	w, ok := q.windows[key]
+	if !ok || !w.resetAt.Equal(resetAt) {
+		w = &quotaWindow{resetAt: resetAt}
+		q.windows[key] = w
+	}
+	w.count++
+	return quotaResult(w.count, limit, resetAt), nil
+}
+
+// RedisQuotaCounter is a fixed window counter shared across instances via Redis INCR
+type RedisQuotaCounter struct {
+	client *redis.Client
+}
+
+// NewRedisQuotaCounter creates a new Redis backed quota counter
+func NewRedisQuotaCounter(client *redis.Client) *RedisQuotaCounter {
+	return &RedisQuotaCounter{client: client}
+}
+
+// Hit implements QuotaCounter
+func (q *RedisQuotaCounter) Hit(ctx context.Context, key string, limit int, window time.Duration) (QuotaResult, error) {
+	start, resetAt := windowBounds(time.Now(), window)
+	windowKey := fmt.Sprintf("%s:%d", key, start.UnixMilli())
+
+	reply, err := q.client.Do(ctx, "INCR", windowKey)
+	if err != nil {
+		return QuotaResult{}, err
+	}
+	count, _ := reply.(int64)
+
+	if count == 1 {
+		// Keep the key a little past the window so clock skew between instances can't reset it early
+		if _, err := q.client.Do(ctx, "PEXPIRE", windowKey, (2 * window).Milliseconds()); err != nil {
+			return QuotaResult{}, err
+		}
+	}
+	return quotaResult(count, limit, resetAt), nil
+}
+
+// fallbackQuotaCounter uses the primary counter and degrades to the fallback when it errors
+type fallbackQuotaCounter struct {
+	primary  QuotaCounter
+	fallback QuotaCounter
+}
+
+// Hit implements QuotaCounter
+func (q *fallbackQuotaCounter) Hit(ctx context.Context, key string, limit int, window time.Duration) (QuotaResult, error) {
+	result, err := q.primary.Hit(ctx, key, limit, window)
+	if err != nil {
+		logger.Warn("Quota counter backend unavailable, using in-memory counter: %v", err)
+		return q.fallback.Hit(ctx, key, limit, window)
+	}
+	return result, nil
+}
@@ -0,0 +1,146 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/llamacto/llama-gin-kit/pkg/logger"
+	"github.com/llamacto/llama-gin-kit/pkg/redis"
+)
+
+// Limiter limits the number of events per key within a sliding window
+type Limiter interface {
+	// Allow records an event for key and reports whether it is within the limit.
+	// When the limit is exceeded it returns the duration until the next event would be allowed.
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error)
+}
+
+// NewLimiter returns a Redis backed limiter when a client is available,
+// falling back to an in-memory limiter otherwise
+func NewLimiter(client *redis.Client) Limiter {
+	memory := NewMemoryLimiter()
+	if client == nil {
+		return memory
+	}
+	return &fallbackLimiter{
+		primary:  NewRedisLimiter(client),
+		fallback: memory,
+	}
+}
+
+// MemoryLimiter is a process-local sliding window limiter
+type MemoryLimiter struct {
+	mu     sync.Mutex
+	events map[string][]time.Time
+	calls  int
+}
+
+// NewMemoryLimiter creates a new in-memory limiter
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{events: make(map[string][]time.Time)}
+}
+
+// Allow implements Limiter
+func (l *MemoryLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	events := prune(l.events[key], now.Add(-window))
+
+	// Periodically drop idle keys so the map doesn't grow without bound
+	l.calls++
+	if l.calls%1000 == 0 {
+		l.sweep(now, window)
+	}
+
+	if len(events) >= limit {
+		l.events[key] = events
+		return false, events[0].Add(window).Sub(now), nil
+	}
+
+	l.events[key] = append(events, now)
+	return true, 0, nil
+}
+
+func (l *MemoryLimiter) sweep(now time.Time, window time.Duration) {
+	for key, events := range l.events {
+		if len(events) == 0 || now.Sub(events[len(events)-1]) > window {
+			delete(l.events, key)
+		}
+	}
+}
+
+// prune drops events that happened before the cutoff
+func prune(events []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(events) && !events[i].After(cutoff) {
+		i++
+	}
+	return events[i:]
+}
+
+// slidingWindowScript prunes the window, then records the event when there is room for it.
+// It returns {1, 0} when allowed and {0, retry after in ms} otherwise, so concurrent callers
+// can't all pass on the same count.
+const slidingWindowScript = `
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], 0, now - window)
+if redis.call('ZCARD', KEYS[1]) >= tonumber(ARGV[3]) then
+	local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+	local retry = window
+	if oldest[2] then retry = tonumber(oldest[2]) + window - now end
+	return {0, retry}
+end
+redis.call('ZADD', KEYS[1], now, ARGV[4])
+redis.call('PEXPIRE', KEYS[1], window)
+return {1, 0}
+`
+
+// RedisLimiter is a sliding window limiter shared across instances via Redis sorted sets
+type RedisLimiter struct {
+	client *redis.Client
+}
+
+// NewRedisLimiter creates a new Redis backed limiter
+func NewRedisLimiter(client *redis.Client) *RedisLimiter {
+	return &RedisLimiter{client: client}
+}
+
+// Allow implements Limiter
+func (l *RedisLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	nowMs := time.Now().UnixMilli()
+	member := fmt.Sprintf("%d-%s", nowMs, uuid.NewString())
+
+	reply, err := l.client.Do(ctx, "EVAL", slidingWindowScript, 1, key, nowMs, window.Milliseconds(), limit, member)
+	if err != nil {
+		return false, 0, err
+	}
+	items, ok := reply.([]interface{})
+	if !ok || len(items) != 2 {
+		return false, 0, fmt.Errorf("ratelimit: unexpected reply %v", reply)
+	}
+	allowed, _ := items[0].(int64)
+	retryAfter, _ := items[1].(int64)
+	return allowed == 1, time.Duration(retryAfter) * time.Millisecond, nil
+}
+
+// fallbackLimiter uses the primary limiter and degrades to the fallback when it errors
+type fallbackLimiter struct {
+	primary  Limiter
+	fallback Limiter
+}
+
+// Allow implements Limiter
+func (l *fallbackLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	allowed, retryAfter, err := l.primary.Allow(ctx, key, limit, window)
+	if err != nil {
+		logger.Warn("Rate limiter backend unavailable, using in-memory limiter: %v", err)
+		return l.fallback.Allow(ctx, key, limit, window)
+	}
+	return allowed, retryAfter, nil
+}
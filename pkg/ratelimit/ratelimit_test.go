@@ -0,0 +1,123 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/llamacto/llama-gin-kit/config"
+	"github.com/llamacto/llama-gin-kit/pkg/redis"
+)
+
+func TestMemoryLimiterAllow(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   int
+		calls   int
+		allowed int
+	}{
+		{"under the limit", 3, 2, 2},
+		{"at the limit", 3, 3, 3},
+		{"over the limit", 3, 5, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewMemoryLimiter()
+			allowed := 0
+			var lastRetry time.Duration
+			for i := 0; i < tt.calls; i++ {
+				ok, retryAfter, err := limiter.Allow(context.Background(), "key", tt.limit, time.Minute)
+				if err != nil {
+					t.Fatalf("Allow: %v", err)
+				}
+				if ok {
+					allowed++
+				} else {
+					lastRetry = retryAfter
+				}
+			}
+			if allowed != tt.allowed {
+				t.Errorf("allowed %d calls, want %d", allowed, tt.allowed)
+			}
+			if tt.calls > tt.limit && (lastRetry <= 0 || lastRetry > time.Minute) {
+				t.Errorf("retry after = %s, want within the window", lastRetry)
+			}
+		})
+	}
+}
+
+func TestMemoryLimiterKeysAreIndependent(t *testing.T) {
+	limiter := NewMemoryLimiter()
+	ctx := context.Background()
+
+	if ok, _, _ := limiter.Allow(ctx, "a", 1, time.Minute); !ok {
+		t.Fatal("first call for a was rejected")
+	}
+	if ok, _, _ := limiter.Allow(ctx, "b", 1, time.Minute); !ok {
+		t.Error("first call for b was rejected after a used its limit")
+	}
+}
+
+// newTestRedisClient connects to the Redis server at REDIS_TEST_ADDR, skipping the test when unset
+func newTestRedisClient(t *testing.T) *redis.Client {
+	t.Helper()
+	addr := os.Getenv("REDIS_TEST_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_TEST_ADDR not set")
+	}
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatalf("invalid REDIS_TEST_ADDR: %v", err)
+	}
+	port, _ := strconv.Atoi(portStr)
+
+	client := redis.NewClient(config.RedisConfig{Host: host, Port: port, PoolSize: 8})
+	t.Cleanup(func() { client.Close() })
+	if err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("ping redis: %v", err)
+	}
+	return client
+}
+
+func TestRedisLimiterConcurrentAllow(t *testing.T) {
+	limiter := NewRedisLimiter(newTestRedisClient(t))
+	key := "ratelimit:test:" + uuid.NewString()
+	const limit, callers = 5, 50
+
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, _, err := limiter.Allow(context.Background(), key, limit, time.Minute)
+			if err != nil {
+				t.Errorf("Allow: %v", err)
+				return
+			}
+			if ok {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := allowed.Load(); got != limit {
+		t.Errorf("allowed %d concurrent calls, want %d", got, limit)
+	}
+
+	ok, retryAfter, err := limiter.Allow(context.Background(), key, limit, time.Minute)
+	if err != nil || ok {
+		t.Fatalf("Allow after the limit = %v, %v", ok, err)
+	}
+	if retryAfter <= 0 || retryAfter > time.Minute {
+		t.Errorf("retry after = %s, want within the window", retryAfter)
+	}
+}
@@ -0,0 +1,299 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/llamacto/llama-gin-kit/config"
+)
+
+// defaultTimeout bounds a single command when the context carries no deadline
+const defaultTimeout = 3 * time.Second
+
+// ErrNil is returned when Redis replies with a nil bulk string or array
+var ErrNil = errors.New("redis: nil reply")
+
+// Client is a minimal Redis client speaking the RESP protocol over a small connection pool
+type Client struct {
+	addr     string
+	password string
+	db       int
+
+	// slots holds one token per connection that may be open, idle holds the connections not in use
+	slots chan struct{}
+	idle  chan *conn
+}
+
+// conn is a single connection to Redis
+type conn struct {
+	net.Conn
+	rd *bufio.Reader
+}
+
+// defaultPoolSize is used when the configured pool size is not positive
+const defaultPoolSize = 10
+
+var client *Client
+
+// Init initializes the Redis client and verifies connectivity
+func Init(cfg *config.Config) error {
+	if cfg.Redis.Host == "" {
+		return fmt.Errorf("missing required Redis configuration")
+	}
+
+	c := NewClient(cfg.Redis)
+	if err := c.Ping(context.Background()); err != nil {
+		return fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	client = c
+	return nil
+}
+
+// GetClient returns the Redis client instance, or nil when Redis is not available
+func GetClient() *Client {
+	return client
+}
+
+// NewClient creates a new Redis client holding at most cfg.PoolSize connections,
+// which are opened lazily
+func NewClient(cfg config.RedisConfig) *Client {
+	size := cfg.PoolSize
+	if size <= 0 {
+		size = defaultPoolSize
+	}
+	return &Client{
+		addr:     net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		password: cfg.Password,
+		db:       cfg.DB,
+		slots:    make(chan struct{}, size),
+		idle:     make(chan *conn, size),
+	}
+}
+
+// Ping checks the connection to Redis
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.Do(ctx, "PING")
+	return err
+}
+
+// Do sends a command and returns its reply, waiting for a free connection when all are busy.
+// Replies are decoded as string, int64, []interface{} or an error for Redis error replies.
+func (c *Client) Do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	select {
+	case c.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-c.slots }()
+
+	cn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := cn.roundTrip(ctx, args...)
+	if err != nil {
+		var redisErr Error
+		if !errors.As(err, &redisErr) {
+			// The connection state is unknown after an I/O or protocol error, don't reuse it
+			cn.Close()
+			return nil, err
+		}
+	}
+	c.put(cn)
+	return reply, err
+}
+
+// Close closes the idle connections of the pool
+func (c *Client) Close() error {
+	var firstErr error
+	for {
+		select {
+		case cn := <-c.idle:
+			if err := cn.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		default:
+			return firstErr
+		}
+	}
+}
+
+// get returns an idle connection or opens a new one
+func (c *Client) get(ctx context.Context) (*conn, error) {
+	select {
+	case cn := <-c.idle:
+		return cn, nil
+	default:
+		return c.dial(ctx)
+	}
+}
+
+// put returns a healthy connection to the idle set
+func (c *Client) put(cn *conn) {
+	select {
+	case c.idle <- cn:
+	default:
+		cn.Close()
+	}
+}
+
+func (c *Client) dial(ctx context.Context) (*conn, error) {
+	dialer := net.Dialer{Timeout: defaultTimeout}
+	nc, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return nil, err
+	}
+	cn := &conn{Conn: nc, rd: bufio.NewReader(nc)}
+
+	if c.password != "" {
+		if _, err := cn.roundTrip(ctx, "AUTH", c.password); err != nil {
+			cn.Close()
+			return nil, fmt.Errorf("redis auth failed: %w", err)
+		}
+	}
+	if c.db != 0 {
+		if _, err := cn.roundTrip(ctx, "SELECT", c.db); err != nil {
+			cn.Close()
+			return nil, fmt.Errorf("redis select db failed: %w", err)
+		}
+	}
+	return cn, nil
+}
+
+func (cn *conn) roundTrip(ctx context.Context, args ...interface{}) (interface{}, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultTimeout)
+	}
+	if err := cn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	if _, err := cn.Write(encodeCommand(args)); err != nil {
+		return nil, err
+	}
+	return readReply(cn.rd)
+}
+
+// Error represents an error reply returned by the Redis server
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+// encodeCommand serializes a command as a RESP array of bulk strings
+func encodeCommand(args []interface{}) []byte {
+	buf := make([]byte, 0, 64)
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)), 10)
+	buf = append(buf, '\r', '\n')
+
+	for _, arg := range args {
+		var s string
+		switch v := arg.(type) {
+		case string:
+			s = v
+		case []byte:
+			s = string(v)
+		case int:
+			s = strconv.Itoa(v)
+		case int64:
+			s = strconv.FormatInt(v, 10)
+		case uint:
+			s = strconv.FormatUint(uint64(v), 10)
+		case float64:
+			s = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			s = fmt.Sprint(v)
+		}
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(s)), 10)
+		buf = append(buf, '\r', '\n')
+		buf = append(buf, s...)
+		buf = append(buf, '\r', '\n')
+	}
+	return buf
+}
+
+// readReply decodes a single RESP reply
+func readReply(rd *bufio.Reader) (interface{}, error) {
+	line, err := readLine(rd)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, fmt.Errorf("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, Error(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, ErrNil
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(rd, data); err != nil {
+			return nil, err
+		}
+		return string(data[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, ErrNil
+		}
+		// Read every item even after an error reply so the connection stays in sync
+		items := make([]interface{}, n)
+		var itemErr error
+		for i := range items {
+			item, err := readReply(rd)
+			if err != nil && !errors.Is(err, ErrNil) {
+				var redisErr Error
+				if !errors.As(err, &redisErr) {
+					return nil, err
+				}
+				if itemErr == nil {
+					itemErr = err
+				}
+			}
+			items[i] = item
+		}
+		if itemErr != nil {
+			return nil, itemErr
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply type %q", line[0])
+	}
+}
+
+func readLine(rd *bufio.Reader) (string, error) {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", fmt.Errorf("redis: malformed reply line")
+	}
+	return line[:len(line)-2], nil
+}
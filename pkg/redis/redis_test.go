@@ -0,0 +1,51 @@
+package redis
+
+import (
+	"bufio"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadReply(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    interface{}
+		wantErr error
+	}{
+		{"simple string", "+OK\r\n", "OK", nil},
+		{"error", "-ERR boom\r\n", nil, Error("ERR boom")},
+		{"integer", ":42\r\n", int64(42), nil},
+		{"bulk string", "$5\r\nhello\r\n", "hello", nil},
+		{"nil bulk string", "$-1\r\n", nil, ErrNil},
+		{"array", "*2\r\n:1\r\n$1\r\na\r\n", []interface{}{int64(1), "a"}, nil},
+		{"array with nil", "*2\r\n$-1\r\n:1\r\n", []interface{}{nil, int64(1)}, nil},
+		{"array with error", "*3\r\n:1\r\n-ERR nested\r\n:3\r\n", nil, Error("ERR nested")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readReply(bufio.NewReader(strings.NewReader(tt.input)))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("reply = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadReplyDrainsArrayAfterNestedError(t *testing.T) {
+	rd := bufio.NewReader(strings.NewReader("*2\r\n-ERR first\r\n$3\r\nend\r\n+NEXT\r\n"))
+
+	if _, err := readReply(rd); !errors.Is(err, Error("ERR first")) {
+		t.Fatalf("err = %v, want the nested error", err)
+	}
+	got, err := readReply(rd)
+	if err != nil || got != "NEXT" {
+		t.Errorf("next reply = %#v, %v, want \"NEXT\"", got, err)
+	}
+}
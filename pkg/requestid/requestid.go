@@ -0,0 +1,58 @@
+// Package requestid stores the correlation ID of a request so logs and error responses can reference it.
+package requestid
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Header is the HTTP header carrying the request ID in both directions
+const Header = "X-Request-ID"
+
+// maxLength bounds incoming IDs so clients cannot flood logs through the header
+const maxLength = 128
+
+type contextKey struct{}
+
+// ginKey is the gin context key holding the request ID
+const ginKey = "requestid.id"
+
+// Set stores the request ID in the gin context and the request's context.Context
+func Set(c *gin.Context, id string) {
+	c.Set(ginKey, id)
+	if c.Request != nil {
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), contextKey{}, id))
+	}
+}
+
+// Get returns the request ID, or "" when the RequestID middleware has not run
+func Get(c *gin.Context) string {
+	return c.GetString(ginKey)
+}
+
+// FromContext returns the request ID from a request context, or "" when there is none
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// New generates a request ID
+func New() string {
+	return uuid.NewString()
+}
+
+// Valid reports whether an incoming ID is safe to reuse: non-empty, bounded in length
+// and made only of visible ASCII characters, so it cannot break log lines
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
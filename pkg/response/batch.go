@@ -0,0 +1,32 @@
+package response
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/pkg/batch"
+	"github.com/llamacto/llama-gin-kit/pkg/i18n"
+	"github.com/llamacto/llama-gin-kit/pkg/requestid"
+)
+
+// Batch 批量操作响应：全部成功（含跳过）返回 200，部分失败返回 207，全部失败返回 400，
+// 三种情况的 data 均为完整的 batch.Result
+func Batch(c *gin.Context, result *batch.Result) {
+	switch {
+	case result.AllFailed():
+		c.JSON(http.StatusBadRequest, Response{
+			Code:      http.StatusBadRequest,
+			Message:   i18n.Translate(Language(c), i18n.KeyBatchFailed),
+			Data:      result,
+			RequestID: requestid.Get(c),
+		})
+	case result.PartiallyFailed():
+		c.JSON(http.StatusMultiStatus, Response{
+			Code:    http.StatusMultiStatus,
+			Message: i18n.Translate(Language(c), i18n.KeyBatchPartiallyFailed),
+			Data:    result,
+		})
+	default:
+		Success(c, result)
+	}
+}
@@ -0,0 +1,91 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/pkg/batch"
+)
+
+func TestBatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		record      func(*batch.Result)
+		wantStatus  int
+		wantCode    int
+		wantMessage string
+	}{
+		{
+			name: "all succeeded",
+			record: func(r *batch.Result) {
+				r.Succeed(1)
+				r.Skip(2, "already_assigned", "already assigned")
+			},
+			wantStatus:  http.StatusOK,
+			wantCode:    0,
+			wantMessage: "success",
+		},
+		{
+			name: "mixed success and failure",
+			record: func(r *batch.Result) {
+				r.Succeed(1)
+				r.Fail(2, batch.CodeNotFound, "user not found")
+			},
+			wantStatus:  http.StatusMultiStatus,
+			wantCode:    http.StatusMultiStatus,
+			wantMessage: "Some items could not be processed",
+		},
+		{
+			name: "all failed",
+			record: func(r *batch.Result) {
+				r.Fail(1, batch.CodeNotFound, "user not found")
+				r.Fail(2, batch.CodeNotFound, "user not found")
+			},
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    http.StatusBadRequest,
+			wantMessage: "No items could be processed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := batch.New(2)
+			tt.record(result)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+			Batch(c, result)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			var body struct {
+				Code    int          `json:"code"`
+				Message string       `json:"message"`
+				Data    batch.Result `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if body.Code != tt.wantCode || body.Message != tt.wantMessage {
+				t.Fatalf("code, message = %d, %q, want %d, %q", body.Code, body.Message, tt.wantCode, tt.wantMessage)
+			}
+			// Every status carries the full per-item result
+			if body.Data.Summary != result.Summary || len(body.Data.Items) != len(result.Items) {
+				t.Fatalf("data = %+v, want %+v", body.Data, *result)
+			}
+			for i, item := range body.Data.Items {
+				want := result.Items[i]
+				if item.Status != want.Status || item.Code != want.Code || item.Message != want.Message {
+					t.Fatalf("item %d = %+v, want %+v", i, item, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package response
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/llamacto/llama-gin-kit/pkg/errors"
+	"github.com/llamacto/llama-gin-kit/pkg/i18n"
+	"github.com/llamacto/llama-gin-kit/pkg/invariant"
+
+	"gorm.io/gorm"
+)
+
+// IsNotFound reports whether err, or any error it wraps, is gorm.ErrRecordNotFound or a
+// typed not-found error
+func IsNotFound(err error) bool {
+	return errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, apperrors.ErrNotFound)
+}
+
+// kindStatus maps typed error kinds to HTTP status codes
+var kindStatus = map[apperrors.Kind]int{
+	apperrors.KindNotFound:      http.StatusNotFound,
+	apperrors.KindAlreadyExists: http.StatusConflict,
+	apperrors.KindForbidden:     http.StatusForbidden,
+	apperrors.KindValidation:    http.StatusBadRequest,
+	apperrors.KindConflict:      http.StatusConflict,
+}
+
+// StatusFromError returns the status for a typed error's kind, 404 for missing records,
+// 400 for rows rejected by model invariants and fallback for anything else
+func StatusFromError(err error, fallback int) int {
+	if kind, ok := apperrors.KindOf(err); ok {
+		if status, ok := kindStatus[kind]; ok {
+			return status
+		}
+	}
+	if IsNotFound(err) {
+		return http.StatusNotFound
+	}
+	if errors.Is(err, invariant.ErrViolation) {
+		return http.StatusBadRequest
+	}
+	return fallback
+}
+
+// StatusClientClosedRequest is the non-standard status recorded for requests whose client
+// disconnected before the response was written
+const StatusClientClosedRequest = 499
+
+// Canceled handles errors caused by the request context ending and reports whether err was
+// one, in which case the handler should return. A client that disconnected gets no body,
+// since nobody is left to read it; a request past its deadline gets 504.
+func Canceled(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, context.Canceled):
+		c.AbortWithStatus(StatusClientClosedRequest)
+		return true
+	case errors.Is(err, context.DeadlineExceeded):
+		ErrorKey(c, http.StatusGatewayTimeout, i18n.KeyRequestTimeout)
+		c.Abort()
+		return true
+	}
+	return false
+}
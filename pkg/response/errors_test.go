@@ -0,0 +1,72 @@
+package response
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/llamacto/llama-gin-kit/pkg/errors"
+	"github.com/llamacto/llama-gin-kit/pkg/invariant"
+	"gorm.io/gorm"
+)
+
+func TestStatusFromError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "missing record", err: gorm.ErrRecordNotFound, want: http.StatusNotFound},
+		{name: "wrapped missing record", err: fmt.Errorf("failed to get role: %w", gorm.ErrRecordNotFound), want: http.StatusNotFound},
+		{name: "typed not found", err: apperrors.NotFound("role_not_found", "role not found"), want: http.StatusNotFound},
+		{name: "typed already exists", err: apperrors.AlreadyExists("name_taken", "name taken"), want: http.StatusConflict},
+		{name: "typed forbidden", err: apperrors.Forbidden("forbidden", "forbidden"), want: http.StatusForbidden},
+		{name: "typed validation", err: apperrors.Validation("invalid", "invalid"), want: http.StatusBadRequest},
+		{name: "typed conflict", err: apperrors.Conflict("in_use", "in use"), want: http.StatusConflict},
+		{name: "invariant violation", err: fmt.Errorf("save: %w", invariant.ErrViolation), want: http.StatusBadRequest},
+		{name: "database failure", err: errors.New("connection refused"), want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StatusFromError(tt.err, http.StatusInternalServerError); got != tt.want {
+				t.Fatalf("StatusFromError(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanceled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		err        error
+		want       bool
+		wantStatus int
+	}{
+		{name: "client disconnected", err: context.Canceled, want: true, wantStatus: StatusClientClosedRequest},
+		{name: "deadline passed", err: fmt.Errorf("query: %w", context.DeadlineExceeded), want: true, wantStatus: http.StatusGatewayTimeout},
+		{name: "other error", err: gorm.ErrRecordNotFound, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+			if got := Canceled(c, tt.err); got != tt.want {
+				t.Fatalf("Canceled() = %v, want %v", got, tt.want)
+			}
+			c.Writer.WriteHeaderNow()
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
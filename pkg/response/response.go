@@ -1,14 +1,21 @@
 package response
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
+	apperrors "github.com/llamacto/llama-gin-kit/pkg/errors"
+	"github.com/llamacto/llama-gin-kit/pkg/i18n"
+	"github.com/llamacto/llama-gin-kit/pkg/requestid"
 )
 
 // Response 统一响应结构
 type Response struct {
-	Code    int         `json:"code"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
+	Code      int         `json:"code"`
+	Message   string      `json:"message"`
+	ErrorCode string      `json:"error_code,omitempty"` // 机器可读的错误码，来自 pkg/errors 类型错误
+	Data      interface{} `json:"data,omitempty"`
+	RequestID string      `json:"request_id,omitempty"` // 仅错误响应携带，用于关联服务端日志
 }
 
 // Success 成功响应
@@ -20,10 +27,55 @@ func Success(c *gin.Context, data interface{}) {
 	})
 }
 
-// Error 错误响应
+// Created 创建成功响应，返回 201
+func Created(c *gin.Context, data interface{}) {
+	c.JSON(http.StatusCreated, Response{
+		Code:    0,
+		Message: "success",
+		Data:    data,
+	})
+}
+
+// Accepted 已受理响应，返回 202，用于异步处理的任务
+func Accepted(c *gin.Context, data interface{}) {
+	c.JSON(http.StatusAccepted, Response{
+		Code:    0,
+		Message: "success",
+		Data:    data,
+	})
+}
+
+// Error 错误响应，附带请求 ID 便于定位日志
 func Error(c *gin.Context, code int, message string) {
 	c.JSON(code, Response{
-		Code:    code,
-		Message: message,
+		Code:      code,
+		Message:   message,
+		RequestID: requestid.Get(c),
 	})
 }
+
+// ErrorKey 本地化错误响应，按 Accept-Language 翻译消息键，默认英文
+func ErrorKey(c *gin.Context, code int, key string, args ...interface{}) {
+	Error(c, code, i18n.Translate(Language(c), key, args...))
+}
+
+// ErrorFrom 错误对象响应，可本地化的错误按 Accept-Language 翻译，其余使用 err.Error()；
+// 类型错误的错误码写入 error_code
+func ErrorFrom(c *gin.Context, code int, err error) {
+	c.JSON(code, Response{
+		Code:      code,
+		Message:   i18n.TranslateError(Language(c), err),
+		ErrorCode: apperrors.CodeOf(err),
+		RequestID: requestid.Get(c),
+	})
+}
+
+// FromError 按错误类型选择状态码（见 StatusFromError，未分类错误为 500）并返回错误响应
+func FromError(c *gin.Context, err error) {
+	ErrorFrom(c, StatusFromError(err, http.StatusInternalServerError), err)
+}
+
+// Language 返回请求 Accept-Language 中支持的语言，默认英文
+func Language(c *gin.Context) string {
+	return i18n.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+}
@@ -0,0 +1,76 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/pkg/i18n"
+)
+
+func TestLocalizedErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	errRoleNotFound := i18n.NewError(i18n.KeyRoleNotFound, "role not found")
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		respond        func(c *gin.Context)
+		want           string
+	}{
+		{
+			name:           "key in a supported language",
+			acceptLanguage: "zh-CN,zh;q=0.9,en;q=0.8",
+			respond:        func(c *gin.Context) { ErrorKey(c, http.StatusNotFound, i18n.KeyRoleNotFound) },
+			want:           "角色不存在",
+		},
+		{
+			name:           "key in an unsupported language",
+			acceptLanguage: "fr-FR",
+			respond:        func(c *gin.Context) { ErrorKey(c, http.StatusNotFound, i18n.KeyRoleNotFound) },
+			want:           "role not found",
+		},
+		{
+			name:    "key without Accept-Language",
+			respond: func(c *gin.Context) { ErrorKey(c, http.StatusNotFound, i18n.KeyRoleNotFound) },
+			want:    "role not found",
+		},
+		{
+			name:           "localizable error",
+			acceptLanguage: "zh",
+			respond:        func(c *gin.Context) { ErrorFrom(c, http.StatusNotFound, errRoleNotFound) },
+			want:           "角色不存在",
+		},
+		{
+			name:           "plain error keeps its text",
+			acceptLanguage: "zh",
+			respond:        func(c *gin.Context) { ErrorFrom(c, http.StatusNotFound, errors.New("role 7 not found")) },
+			want:           "role 7 not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.acceptLanguage != "" {
+				c.Request.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+
+			tt.respond(c)
+
+			var body Response
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if w.Code != http.StatusNotFound || body.Message != tt.want {
+				t.Fatalf("response = %d %q, want %d %q", w.Code, body.Message, http.StatusNotFound, tt.want)
+			}
+		})
+	}
+}
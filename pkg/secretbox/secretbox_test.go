@@ -0,0 +1,88 @@
+package secretbox
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func TestSealOpen(t *testing.T) {
+	box, err := New("app-secret")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	sealed, err := box.Seal("JBSWY3DPEHPK3PXP")
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	raw, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		t.Fatalf("decode sealed value: %v", err)
+	}
+	other, err := New("other-secret")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// tamper flips one bit of the sealed bytes at i, counted from the end when negative
+	tamper := func(i int) string {
+		b := append([]byte(nil), raw...)
+		if i < 0 {
+			i += len(b)
+		}
+		b[i] ^= 0x01
+		return base64.StdEncoding.EncodeToString(b)
+	}
+
+	tests := []struct {
+		name    string
+		box     *Box
+		sealed  string
+		want    string
+		wantErr error
+	}{
+		{name: "round trip", box: box, sealed: sealed, want: "JBSWY3DPEHPK3PXP"},
+		{name: "tampered nonce", box: box, sealed: tamper(0), wantErr: ErrInvalidCiphertext},
+		{name: "tampered ciphertext", box: box, sealed: tamper(box.aead.NonceSize()), wantErr: ErrInvalidCiphertext},
+		{name: "tampered tag", box: box, sealed: tamper(-1), wantErr: ErrInvalidCiphertext},
+		{name: "another secret", box: other, sealed: sealed, wantErr: ErrInvalidCiphertext},
+		{name: "truncated", box: box, sealed: base64.StdEncoding.EncodeToString(raw[:4]), wantErr: ErrInvalidCiphertext},
+		{name: "not base64", box: box, sealed: "%%%", wantErr: ErrInvalidCiphertext},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.box.Open(tt.sealed)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Open() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("Open() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSealUsesFreshNonce(t *testing.T) {
+	box, err := New("app-secret")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	first, err := box.Seal("secret")
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	second, err := box.Seal("secret")
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if first == second {
+		t.Fatal("sealing the same value twice gave the same output")
+	}
+}
+
+func TestNewRejectsEmptySecret(t *testing.T) {
+	if _, err := New(""); err == nil {
+		t.Fatal("New(\"\") succeeded, want an error")
+	}
+}
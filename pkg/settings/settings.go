@@ -0,0 +1,195 @@
+// Package settings parses, validates and edits the free-form JSON settings documents stored
+// on organizations and teams. A document is a JSON object keyed by setting name; a Schema
+// may restrict the values of individual keys, while unknown keys accept any JSON value.
+package settings
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	apperrors "github.com/llamacto/llama-gin-kit/pkg/errors"
+	"github.com/llamacto/llama-gin-kit/pkg/i18n"
+)
+
+// Empty is the stored form of a document without settings
+const Empty = "{}"
+
+// Settings is a parsed settings document
+type Settings map[string]json.RawMessage
+
+// Validator checks the value stored under one settings key, returning a short reason when it is invalid
+type Validator func(value json.RawMessage) error
+
+// Schema maps settings keys to the validator for their value
+type Schema map[string]Validator
+
+// InvalidError reports a settings document that is not a JSON object, or a key whose value
+// fails its schema. Field names the request field, e.g. "settings" or "settings.features".
+type InvalidError struct {
+	Field  string
+	Reason string
+}
+
+func (e *InvalidError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Reason)
+}
+
+// MessageKey implements i18n.Localizable
+func (e *InvalidError) MessageKey() string { return i18n.KeyInvalidSettings }
+
+// MessageArgs supplies the field and reason to the translated message
+func (e *InvalidError) MessageArgs() []interface{} { return []interface{}{e.Field, e.Reason} }
+
+// Unwrap classifies the error as a validation error with its code
+func (e *InvalidError) Unwrap() error { return errInvalid }
+
+// errInvalid is the typed error every InvalidError unwraps to
+var errInvalid = apperrors.Validation(i18n.KeyInvalidSettings, "invalid settings")
+
+// Parse decodes a stored settings document. An empty string is an empty document.
+func Parse(raw string) (Settings, error) {
+	settings := Settings{}
+	if raw == "" {
+		return settings, nil
+	}
+
+	trimmed := bytes.TrimSpace([]byte(raw))
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil, fmt.Errorf("must be a JSON object")
+	}
+	if err := json.Unmarshal(trimmed, &settings); err != nil {
+		return nil, fmt.Errorf("malformed JSON: %v", err)
+	}
+	if settings == nil {
+		settings = Settings{}
+	}
+	return settings, nil
+}
+
+// Normalize validates raw against the schema and returns it in canonical form, with "" becoming
+// Empty. Errors are *InvalidError values naming field, or field.key for a rejected key.
+func (s Schema) Normalize(field, raw string) (string, error) {
+	settings, err := Parse(raw)
+	if err != nil {
+		return "", &InvalidError{Field: field, Reason: err.Error()}
+	}
+	if err := s.Validate(field, settings); err != nil {
+		return "", err
+	}
+	return settings.String(), nil
+}
+
+// Validate checks every key of settings that has a validator in the schema, in key order
+func (s Schema) Validate(field string, settings Settings) error {
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := s.validateKey(field, key, settings[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s Schema) validateKey(field, key string, value json.RawMessage) error {
+	validate, ok := s[key]
+	if !ok {
+		return nil
+	}
+	if err := validate(value); err != nil {
+		return &InvalidError{Field: field + "." + key, Reason: err.Error()}
+	}
+	return nil
+}
+
+// Get decodes the value stored under key into dst and reports whether the key was present
+func (s Settings) Get(key string, dst interface{}) (bool, error) {
+	value, ok := s[key]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(value, dst); err != nil {
+		return true, fmt.Errorf("failed to decode setting %q: %w", key, err)
+	}
+	return true, nil
+}
+
+// Set stores value under key, replacing any previous value
+func (s Settings) Set(key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode setting %q: %w", key, err)
+	}
+	s[key] = data
+	return nil
+}
+
+// String returns the document in its stored form, with keys sorted
+func (s Settings) String() string {
+	if len(s) == 0 {
+		return Empty
+	}
+	data, err := json.Marshal(map[string]json.RawMessage(s))
+	if err != nil {
+		// Values were decoded from or encoded to valid JSON, so this cannot happen
+		return Empty
+	}
+	return string(data)
+}
+
+// SetValidated stores value under key in the raw document after checking it against the
+// schema, and returns the updated document
+func (s Schema) SetValidated(field, raw, key string, value interface{}) (string, error) {
+	settings, err := Parse(raw)
+	if err != nil {
+		return "", &InvalidError{Field: field, Reason: err.Error()}
+	}
+	if err := settings.Set(key, value); err != nil {
+		return "", err
+	}
+	if err := s.validateKey(field, key, settings[key]); err != nil {
+		return "", err
+	}
+	return settings.String(), nil
+}
+
+// Flags returns a validator accepting an object of boolean flags. When allowed is not empty,
+// only those flag names are accepted.
+func Flags(allowed ...string) Validator {
+	return func(value json.RawMessage) error {
+		var flags map[string]bool
+		if err := json.Unmarshal(value, &flags); err != nil || flags == nil {
+			return fmt.Errorf("must be an object of boolean flags")
+		}
+		if len(allowed) == 0 {
+			return nil
+		}
+
+		names := make([]string, 0, len(flags))
+		for name := range flags {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if !contains(allowed, name) {
+				return fmt.Errorf("unknown flag %q", name)
+			}
+		}
+		return nil
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
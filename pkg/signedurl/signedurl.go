@@ -0,0 +1,86 @@
+// Package signedurl creates and verifies expiring HMAC signatures for download links.
+//
+// A signature covers a resource ID and a Unix expiry time, so a link cannot be reused
+// for another resource or past its expiry. Links should only be generated after the
+// caller has checked that the requesting user owns the resource.
+package signedurl
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Query parameters carrying the signature
+const (
+	ExpiresParam   = "expires"
+	SignatureParam = "signature"
+)
+
+var (
+	// ErrMissingSignature is returned when a request has no signature or expiry
+	ErrMissingSignature = errors.New("missing signature")
+	// ErrExpired is returned when a signed URL is past its expiry time
+	ErrExpired = errors.New("signed url has expired")
+	// ErrInvalidSignature is returned when the signature does not match the resource and expiry
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// Signer signs and verifies resource IDs with a shared secret
+type Signer struct {
+	secret []byte
+}
+
+// NewSigner creates a signer. An empty secret is rejected so links are never signed with a known key.
+func NewSigner(secret string) (*Signer, error) {
+	if secret == "" {
+		return nil, errors.New("signing secret must not be empty")
+	}
+	return &Signer{secret: []byte(secret)}, nil
+}
+
+// Sign returns the hex-encoded HMAC-SHA256 of the resource ID and expiry
+func (s *Signer) Sign(id string, expiresAt int64) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(id + ":" + strconv.FormatInt(expiresAt, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignURL appends an expiry and signature for id to path, valid for ttl
+func (s *Signer) SignURL(path, id string, ttl time.Duration) string {
+	return s.SignURLUntil(path, id, time.Now().Add(ttl))
+}
+
+// SignURLUntil appends an expiry and signature for id to path, valid until expiresAt
+// truncated to the second
+func (s *Signer) SignURLUntil(path, id string, expiresAt time.Time) string {
+	query := url.Values{}
+	query.Set(ExpiresParam, strconv.FormatInt(expiresAt.Unix(), 10))
+	query.Set(SignatureParam, s.Sign(id, expiresAt.Unix()))
+	return path + "?" + query.Encode()
+}
+
+// Verify checks the raw expiry and signature query values for id at the given time
+func (s *Signer) Verify(id, expires, signature string, now time.Time) error {
+	if expires == "" || signature == "" {
+		return ErrMissingSignature
+	}
+
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	expected := s.Sign(id, expiresAt)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	if now.Unix() > expiresAt {
+		return ErrExpired
+	}
+	return nil
+}
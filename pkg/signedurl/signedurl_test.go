@@ -0,0 +1,71 @@
+package signedurl
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	signer, err := NewSigner("test-secret")
+	if err != nil {
+		t.Fatalf("NewSigner: %v", err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+	expires := now.Add(time.Minute).Unix()
+	valid := signer.Sign("42", expires)
+
+	tests := []struct {
+		name      string
+		id        string
+		expires   string
+		signature string
+		now       time.Time
+		want      error
+	}{
+		{"valid", "42", strconv.FormatInt(expires, 10), valid, now, nil},
+		{"valid at expiry", "42", strconv.FormatInt(expires, 10), valid, time.Unix(expires, 0), nil},
+		{"expired", "42", strconv.FormatInt(expires, 10), valid, time.Unix(expires+1, 0), ErrExpired},
+		{"unsigned", "42", "", "", now, ErrMissingSignature},
+		{"missing expiry", "42", "", valid, now, ErrMissingSignature},
+		{"other resource", "43", strconv.FormatInt(expires, 10), valid, now, ErrInvalidSignature},
+		{"extended expiry", "42", strconv.FormatInt(expires+3600, 10), valid, now, ErrInvalidSignature},
+		{"malformed expiry", "42", "soon", valid, now, ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := signer.Verify(tt.id, tt.expires, tt.signature, tt.now); !errors.Is(err, tt.want) {
+				t.Errorf("Verify = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignURLUntil(t *testing.T) {
+	signer, _ := NewSigner("test-secret")
+	expiresAt := time.Now().Add(time.Hour)
+
+	link, err := url.Parse(signer.SignURLUntil("/v1/tts/jobs/7/audio", "7", expiresAt))
+	if err != nil {
+		t.Fatalf("parse signed url: %v", err)
+	}
+	if link.Path != "/v1/tts/jobs/7/audio" {
+		t.Errorf("path = %q", link.Path)
+	}
+	query := link.Query()
+	if err := signer.Verify("7", query.Get(ExpiresParam), query.Get(SignatureParam), time.Now()); err != nil {
+		t.Errorf("Verify signed url: %v", err)
+	}
+	if query.Get(ExpiresParam) != strconv.FormatInt(expiresAt.Unix(), 10) {
+		t.Errorf("expires = %s, want %d", query.Get(ExpiresParam), expiresAt.Unix())
+	}
+}
+
+func TestNewSignerRejectsEmptySecret(t *testing.T) {
+	if _, err := NewSigner(""); err == nil {
+		t.Error("NewSigner accepted an empty secret")
+	}
+}
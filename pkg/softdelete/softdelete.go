@@ -0,0 +1,44 @@
+// Package softdelete filters out soft-deleted rows for queries that name a table instead of
+// a model. GORM adds "deleted_at IS NULL" only when the statement's model has a
+// gorm.DeletedAt field, so queries built with Table("...") must add it themselves; these
+// helpers make sure every such count does so the same way.
+package softdelete
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// NotDeleted returns a scope keeping only rows of table, or of the alias it is queried as,
+// that have not been soft-deleted. The column is qualified so joins cannot make it ambiguous.
+func NotDeleted(table string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(alias(table) + ".deleted_at IS NULL")
+	}
+}
+
+// Count returns the number of rows of table matching query and args, excluding soft-deleted
+// ones. table may include an alias, e.g. "organization_members om".
+func Count(db *gorm.DB, table string, query interface{}, args ...interface{}) (int64, error) {
+	var count int64
+	err := db.Table(table).Scopes(NotDeleted(table)).Where(query, args...).Count(&count).Error
+	return count, err
+}
+
+// Exists reports whether table has at least one row matching query and args that has not
+// been soft-deleted
+func Exists(db *gorm.DB, table string, query interface{}, args ...interface{}) (bool, error) {
+	count, err := Count(db, table, query, args...)
+	return count > 0, err
+}
+
+// alias returns the name table is referred to by in a query: the alias in
+// "organization_members om" or "organization_members AS om", otherwise the table itself
+func alias(table string) string {
+	fields := strings.Fields(table)
+	if len(fields) == 0 {
+		return table
+	}
+	return fields[len(fields)-1]
+}
@@ -0,0 +1,97 @@
+package softdelete
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunDB returns a Postgres session that builds statements without a server and reports
+// the SQL of the last query through sql
+func dryRunDB(t *testing.T, sql *string) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("open dry-run db: %v", err)
+	}
+	err = db.Callback().Query().After("gorm:query").Register("softdelete_test:capture", func(tx *gorm.DB) {
+		*sql = tx.Statement.SQL.String()
+	})
+	if err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+	return db
+}
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		name  string
+		table string
+		query string
+		want  string
+	}{
+		{
+			name:  "plain table",
+			table: "organization_members",
+			query: "role_id = ?",
+			want:  `SELECT count(*) FROM "organization_members" WHERE role_id = $1 AND organization_members.deleted_at IS NULL`,
+		},
+		{
+			name:  "aliased table",
+			table: "organization_members om",
+			query: "om.role_id = ?",
+			want:  `SELECT count(*) FROM organization_members om WHERE om.role_id = $1 AND om.deleted_at IS NULL`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sql string
+			if _, err := Count(dryRunDB(t, &sql), tt.table, tt.query, 1); err != nil {
+				t.Fatalf("Count() error = %v", err)
+			}
+			if sql != tt.want {
+				t.Fatalf("Count() SQL = %q, want %q", sql, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlias(t *testing.T) {
+	tests := []struct {
+		table string
+		want  string
+	}{
+		{table: "organization_members", want: "organization_members"},
+		{table: "organization_members om", want: "om"},
+		{table: "organization_members AS om", want: "om"},
+		{table: "  teams  ", want: "teams"},
+		{table: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.table, func(t *testing.T) {
+			if got := alias(tt.table); got != tt.want {
+				t.Fatalf("alias(%q) = %q, want %q", tt.table, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotDeletedQualifiesColumn(t *testing.T) {
+	var sql string
+	var ids []uint
+	dryRunDB(t, &sql).Table("user_roles ur").
+		Joins("JOIN roles r ON r.id = ur.role_id").
+		Scopes(NotDeleted("ur")).
+		Pluck("ur.user_id", &ids)
+
+	if !strings.Contains(sql, "ur.deleted_at IS NULL") {
+		t.Fatalf("query %q does not filter ur.deleted_at", sql)
+	}
+}
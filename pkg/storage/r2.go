@@ -2,17 +2,22 @@ package storage
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"net/url"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/google/uuid"
 	"github.com/llamacto/llama-gin-kit/config"
 )
@@ -23,6 +28,7 @@ type R2Storage struct {
 	bucket       string
 	publicURL    string
 	publicDomain string
+	upload       config.UploadConfig
 }
 
 var r2Storage *R2Storage
@@ -33,41 +39,55 @@ func InitR2Storage(cfg *config.Config) error {
 		return fmt.Errorf("missing required R2 configuration")
 	}
 
-	// Create an AWS session
-	sess, err := session.NewSession(&aws.Config{
-		Credentials:      credentials.NewStaticCredentials(cfg.R2.AccessKeyID, cfg.R2.SecretAccessKey, ""),
-		Endpoint:         aws.String(cfg.R2.Endpoint),
-		Region:           aws.String(cfg.R2.Region),
-		S3ForcePathStyle: aws.Bool(true),
-	})
+	client, err := newS3Client(cfg.R2)
 	if err != nil {
-		return fmt.Errorf("failed to create R2 session: %w", err)
+		return err
 	}
 
-	// Create S3 service client
 	r2Storage = &R2Storage{
-		client:       s3.New(sess),
+		client:       client,
 		bucket:       cfg.R2.Bucket,
 		publicURL:    cfg.R2.PublicURL,
 		publicDomain: cfg.R2.PublicDomain,
+		upload:       cfg.Upload,
 	}
 
 	fmt.Printf("Bucket: %s\n", r2Storage.bucket)
 	fmt.Printf("Public URL: %s\n", r2Storage.publicURL)
 	fmt.Printf("Public Domain: %s\n", r2Storage.publicDomain)
-	fmt.Printf("Endpoint: %s\n", *sess.Config.Endpoint)
-	fmt.Printf("Region: %s\n", *sess.Config.Region)
+	fmt.Printf("Endpoint: %s\n", *client.Config.Endpoint)
+	fmt.Printf("Region: %s\n", *client.Config.Region)
 
 	return nil
 }
 
+// newS3Client creates an S3 API client for the R2 endpoint
+func newS3Client(cfg config.R2Config) (*s3.S3, error) {
+	sess, err := session.NewSession(&aws.Config{
+		Credentials:      credentials.NewStaticCredentials(cfg.AccessKeyID, cfg.SecretAccessKey, ""),
+		Endpoint:         aws.String(cfg.Endpoint),
+		Region:           aws.String(cfg.Region),
+		S3ForcePathStyle: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create R2 session: %w", err)
+	}
+	return s3.New(sess), nil
+}
+
 // GetR2Storage returns the R2 storage instance
 func GetR2Storage() *R2Storage {
 	return r2Storage
 }
 
-// UploadFile uploads a file to R2 storage
+// UploadFile uploads a file to R2 storage. The content is validated against the upload
+// limits and stored with its sniffed content type rather than the declared one.
 func (s *R2Storage) UploadFile(data []byte, fileName string, contentType string) (string, error) {
+	contentType, err := ValidateUpload(data, contentType, s.upload)
+	if err != nil {
+		return "", err
+	}
+
 	// Generate a unique file name if not provided
 	if fileName == "" {
 		ext := filepath.Ext(fileName)
@@ -88,7 +108,7 @@ func (s *R2Storage) UploadFile(data []byte, fileName string, contentType string)
 	}
 
 	// Upload the file
-	_, err := s.client.PutObject(params)
+	_, err = s.client.PutObject(params)
 	if err != nil {
 		return "", fmt.Errorf("failed to upload file to R2: %w", err)
 	}
@@ -118,6 +138,32 @@ func (s *R2Storage) GetFileURL(fileName string) string {
 	return fmt.Sprintf("https://%s.%s/%s", s.bucket, strings.TrimPrefix(strings.TrimPrefix(s.client.Endpoint, "https://"), "http://"), url.PathEscape(fileName))
 }
 
+// KeyFromURL returns the object key behind a URL produced by UploadFile or GetFileURL.
+// ok is false when the URL doesn't point into this bucket.
+func (s *R2Storage) KeyFromURL(fileURL string) (key string, ok bool) {
+	var prefixes []string
+	if s.publicDomain != "" {
+		prefixes = append(prefixes, fmt.Sprintf("https://%s/", s.publicDomain))
+	}
+	if s.publicURL != "" {
+		prefixes = append(prefixes, strings.TrimRight(s.publicURL, "/")+"/")
+	}
+	prefixes = append(prefixes, fmt.Sprintf("https://%s.%s/", s.bucket, strings.TrimPrefix(strings.TrimPrefix(s.client.Endpoint, "https://"), "http://")))
+
+	for _, prefix := range prefixes {
+		escaped, found := strings.CutPrefix(fileURL, prefix)
+		if !found || escaped == "" {
+			continue
+		}
+		key, err := url.PathUnescape(escaped)
+		if err != nil {
+			return "", false
+		}
+		return key, true
+	}
+	return "", false
+}
+
 // GetPresignedURL returns a presigned URL for a file
 func (s *R2Storage) GetPresignedURL(fileName string, expires time.Duration) (string, error) {
 	req, _ := s.client.GetObjectRequest(&s3.GetObjectInput{
@@ -162,26 +208,120 @@ func (s *R2Storage) DownloadFile(fileName string) ([]byte, error) {
 	return io.ReadAll(result.Body)
 }
 
+// presignedUploadExpiry is how long a URL from GeneratePresignedURL accepts uploads
+const presignedUploadExpiry = 15 * time.Minute
+
 // R2Client represents an R2 storage client
 type R2Client struct {
-	cfg *config.Config
+	cfg    *config.Config
+	client *s3.S3
+	err    error // Set when the client could not be created; returned by every call
 }
 
-// NewR2Client creates a new R2 client
+// NewR2Client creates a new R2 client. Missing R2 configuration is reported by the
+// first call rather than here, so callers can construct it unconditionally.
 func NewR2Client(cfg *config.Config) *R2Client {
-	return &R2Client{
-		cfg: cfg,
+	c := &R2Client{cfg: cfg}
+	if cfg.R2.AccessKeyID == "" || cfg.R2.SecretAccessKey == "" || cfg.R2.Endpoint == "" || cfg.R2.Bucket == "" {
+		c.err = ErrStorageNotConfigured
+		return c
+	}
+	c.client, c.err = newS3Client(cfg.R2)
+	return c
+}
+
+// UploadFile streams reader to key in the bucket with the given content type
+func (c *R2Client) UploadFile(ctx context.Context, key string, reader io.Reader, contentType string) error {
+	if c.err != nil {
+		return c.err
+	}
+
+	uploader := s3manager.NewUploaderWithClient(c.client)
+	_, err := uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:      aws.String(c.cfg.R2.Bucket),
+		Key:         aws.String(key),
+		Body:        reader,
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload file to R2: %w", err)
+	}
+	return nil
+}
+
+// PublicURL returns the public URL for key, preferring R2Config.PublicDomain over PublicURL
+func (c *R2Client) PublicURL(key string) string {
+	escaped := (&url.URL{Path: key}).EscapedPath()
+	if c.cfg.R2.PublicDomain != "" {
+		return fmt.Sprintf("https://%s/%s", strings.TrimRight(c.cfg.R2.PublicDomain, "/"), escaped)
+	}
+	if c.cfg.R2.PublicURL != "" {
+		return fmt.Sprintf("%s/%s", strings.TrimRight(c.cfg.R2.PublicURL, "/"), escaped)
+	}
+	endpoint := strings.TrimPrefix(strings.TrimPrefix(c.cfg.R2.Endpoint, "https://"), "http://")
+	return fmt.Sprintf("https://%s.%s/%s", c.cfg.R2.Bucket, endpoint, escaped)
+}
+
+// OpenFile streams the object stored at key along with its size, or -1 when unknown.
+// The caller closes the reader.
+func (c *R2Client) OpenFile(ctx context.Context, key string) (io.ReadCloser, int64, error) {
+	if c.err != nil {
+		return nil, 0, c.err
+	}
+
+	result, err := c.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.cfg.R2.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var reqErr awserr.RequestFailure
+		if errors.As(err, &reqErr) && reqErr.StatusCode() == http.StatusNotFound {
+			return nil, 0, ErrFileNotFound
+		}
+		return nil, 0, fmt.Errorf("failed to download file from R2: %w", err)
 	}
+
+	size := int64(-1)
+	if result.ContentLength != nil {
+		size = *result.ContentLength
+	}
+	return result.Body, size, nil
 }
 
 // FileExists checks if a file exists in R2
 func (c *R2Client) FileExists(key string) (bool, error) {
-	// Implementation here
-	return false, nil
+	if c.err != nil {
+		return false, c.err
+	}
+
+	_, err := c.client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(c.cfg.R2.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var reqErr awserr.RequestFailure
+		if errors.As(err, &reqErr) && reqErr.StatusCode() == http.StatusNotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check file in R2: %w", err)
+	}
+	return true, nil
 }
 
 // GeneratePresignedURL generates a presigned URL for uploading a file
 func (c *R2Client) GeneratePresignedURL(key string, contentType string) (string, error) {
-	// Implementation here
-	return "", nil
+	if c.err != nil {
+		return "", c.err
+	}
+
+	req, _ := c.client.PutObjectRequest(&s3.PutObjectInput{
+		Bucket:      aws.String(c.cfg.R2.Bucket),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+	})
+	urlStr, err := req.Presign(presignedUploadExpiry)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate presigned URL: %w", err)
+	}
+	return urlStr, nil
 }
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/llamacto/llama-gin-kit/config"
+)
+
+var (
+	// ErrFileTooLarge is returned when an upload exceeds the configured maximum size
+	ErrFileTooLarge = errors.New("file exceeds the maximum upload size")
+	// ErrUnsupportedMediaType is returned when an upload's content type is not allowed
+	ErrUnsupportedMediaType = errors.New("unsupported file type")
+	// ErrStorageNotConfigured is returned when R2 credentials or bucket are missing
+	ErrStorageNotConfigured = errors.New("file storage is not configured")
+	// ErrFileNotFound is returned when no object is stored at the requested key
+	ErrFileNotFound = errors.New("file not found")
+)
+
+// MultipartOverhead is the allowance for multipart boundaries and headers on top of the
+// maximum file size when capping a request body
+const MultipartOverhead = 64 << 10
+
+// extensions maps allowed content types to the file extension used for stored objects
+var extensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+	"audio/mpeg": ".mp3",
+	"audio/wave": ".wav",
+}
+
+// ReadUpload reads at most maxSize bytes from r and returns ErrFileTooLarge if more remain
+func ReadUpload(r io.Reader, maxSize int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, ErrFileTooLarge
+	}
+	return data, nil
+}
+
+// ValidateUpload checks data against the upload limits and returns its sniffed content type.
+// The declared type is only trusted when it agrees with the sniffed one.
+func ValidateUpload(data []byte, declaredType string, limits config.UploadConfig) (string, error) {
+	if limits.MaxSize > 0 && int64(len(data)) > limits.MaxSize {
+		return "", ErrFileTooLarge
+	}
+
+	detected := mediaType(http.DetectContentType(data))
+	if !isAllowedType(detected, limits.AllowedMIMETypes) {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedMediaType, detected)
+	}
+	if declaredType != "" && mediaType(declaredType) != detected {
+		return "", fmt.Errorf("%w: declared %s but content is %s", ErrUnsupportedMediaType, mediaType(declaredType), detected)
+	}
+	return detected, nil
+}
+
+// ExtensionForType returns the file extension for a content type, or ".bin" when unknown
+func ExtensionForType(contentType string) string {
+	if ext, ok := extensions[mediaType(contentType)]; ok {
+		return ext
+	}
+	return ".bin"
+}
+
+// UploadErrorStatus returns 413 for oversized uploads, 415 for disallowed types, 503 when storage
+// is not configured and fallback otherwise
+func UploadErrorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, ErrFileTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, ErrUnsupportedMediaType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, ErrStorageNotConfigured):
+		return http.StatusServiceUnavailable
+	}
+	return fallback
+}
+
+// mediaType strips parameters such as charset and normalizes case
+func mediaType(contentType string) string {
+	if parsed, _, err := mime.ParseMediaType(contentType); err == nil {
+		return parsed
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+// isAllowedType reports whether contentType is in the allowlist; an empty allowlist allows nothing
+func isAllowedType(contentType string, allowed []string) bool {
+	for _, t := range allowed {
+		if mediaType(t) == contentType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/llamacto/llama-gin-kit/config"
+)
+
+var (
+	pngData  = append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 56)...)
+	jpegData = append([]byte("\xff\xd8\xff\xe0"), make([]byte, 60)...)
+	htmlData = []byte("<!DOCTYPE html><html><body><script>alert(1)</script></body></html>")
+)
+
+func TestValidateUpload(t *testing.T) {
+	limits := config.UploadConfig{
+		MaxSize:          128,
+		AllowedMIMETypes: []string{"image/png", "image/jpeg"},
+	}
+
+	tests := []struct {
+		name     string
+		data     []byte
+		declared string
+		want     string
+		wantErr  error
+	}{
+		{name: "png", data: pngData, declared: "image/png", want: "image/png"},
+		{name: "jpeg without declared type", data: jpegData, want: "image/jpeg"},
+		{name: "declared type with parameters", data: pngData, declared: "Image/PNG; name=a.png", want: "image/png"},
+		{name: "oversized", data: append(pngData, make([]byte, 128)...), declared: "image/png", wantErr: ErrFileTooLarge},
+		{name: "disallowed type", data: []byte("just some text"), declared: "text/plain", wantErr: ErrUnsupportedMediaType},
+		{name: "html declared as png", data: htmlData, declared: "image/png", wantErr: ErrUnsupportedMediaType},
+		{name: "jpeg declared as png", data: jpegData, declared: "image/png", wantErr: ErrUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateUpload(tt.data, tt.declared, limits)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ValidateUpload() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("ValidateUpload() type = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateUploadEmptyAllowlist(t *testing.T) {
+	_, err := ValidateUpload(pngData, "image/png", config.UploadConfig{MaxSize: 128})
+	if !errors.Is(err, ErrUnsupportedMediaType) {
+		t.Fatalf("ValidateUpload() error = %v, want %v", err, ErrUnsupportedMediaType)
+	}
+}
+
+func TestReadUpload(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		wantErr error
+	}{
+		{name: "under the limit", size: 10},
+		{name: "at the limit", size: 16},
+		{name: "over the limit", size: 17, wantErr: ErrFileTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ReadUpload(strings.NewReader(strings.Repeat("a", tt.size)), 16)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReadUpload() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && !bytes.Equal(data, bytes.Repeat([]byte("a"), tt.size)) {
+				t.Fatalf("ReadUpload() read %d bytes, want %d", len(data), tt.size)
+			}
+		})
+	}
+}
+
+func TestUploadErrorStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{err: ErrFileTooLarge, want: http.StatusRequestEntityTooLarge},
+		{err: fmt.Errorf("%w: text/plain", ErrUnsupportedMediaType), want: http.StatusUnsupportedMediaType},
+		{err: ErrStorageNotConfigured, want: http.StatusServiceUnavailable},
+		{err: errors.New("network down"), want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			if got := UploadErrorStatus(tt.err, http.StatusInternalServerError); got != tt.want {
+				t.Fatalf("UploadErrorStatus() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtensionForType(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        string
+	}{
+		{contentType: "image/png", want: ".png"},
+		{contentType: "image/jpeg; charset=binary", want: ".jpg"},
+		{contentType: "application/octet-stream", want: ".bin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			if got := ExtensionForType(tt.contentType); got != tt.want {
+				t.Fatalf("ExtensionForType(%q) = %q, want %q", tt.contentType, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package validator
+
+import (
+	"strings"
+
+	"github.com/llamacto/llama-gin-kit/config"
+	apperrors "github.com/llamacto/llama-gin-kit/pkg/errors"
+	"github.com/llamacto/llama-gin-kit/pkg/i18n"
+)
+
+// ErrReservedName is returned for organization and team names on the reserved-name list
+var ErrReservedName = apperrors.Validation(i18n.KeyReservedName, "this name is reserved, please choose another")
+
+// NormalizeName reduces a name to its slug form: lowercase letters and digits, with every
+// other run of characters collapsed to a single hyphen and no leading or trailing hyphen.
+// "  Team_Settings! " becomes "team-settings".
+func NormalizeName(name string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if pendingHyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingHyphen = false
+			b.WriteRune(r)
+			continue
+		}
+		pendingHyphen = true
+	}
+	return b.String()
+}
+
+// IsReservedName reports whether name matches one of reserved once both are in slug form.
+// Separators are ignored as well, so "Ad-Min" and "ad_min" match "admin".
+func IsReservedName(name string, reserved []string) bool {
+	key := strings.ReplaceAll(NormalizeName(name), "-", "")
+	if key == "" {
+		return false
+	}
+	for _, r := range reserved {
+		if key == strings.ReplaceAll(NormalizeName(r), "-", "") {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckReservedName returns ErrReservedName when name is on the configured reserved-name
+// list (APP_RESERVED_NAMES), or on the default list before config is loaded
+func CheckReservedName(name string) error {
+	reserved := strings.Split(config.DefaultReservedNames, ",")
+	if config.GlobalConfig != nil {
+		reserved = config.GlobalConfig.App.ReservedNames
+	}
+	if IsReservedName(name, reserved) {
+		return ErrReservedName
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package validator
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/llamacto/llama-gin-kit/config"
+	"github.com/llamacto/llama-gin-kit/pkg/response"
+)
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "admin", want: "admin"},
+		{name: "  Team_Settings! ", want: "team-settings"},
+		{name: "Acme   Corp", want: "acme-corp"},
+		{name: "--api--", want: "api"},
+		{name: "v2.0", want: "v2-0"},
+		{name: "***", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeName(tt.name); got != tt.want {
+				t.Fatalf("NormalizeName(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckReservedName(t *testing.T) {
+	saved := config.GlobalConfig
+	t.Cleanup(func() { config.GlobalConfig = saved })
+
+	tests := []struct {
+		name     string
+		reserved []string // nil uses the default list
+		input    string
+		wantErr  bool
+	}{
+		{name: "reserved", input: "admin", wantErr: true},
+		{name: "upper case", input: "ADMIN", wantErr: true},
+		{name: "padded", input: "  Settings  ", wantErr: true},
+		{name: "separators", input: "Ad-Min", wantErr: true},
+		{name: "underscores", input: "ad_min", wantErr: true},
+		{name: "punctuation", input: "api!", wantErr: true},
+		{name: "containing a reserved word", input: "admin-team", wantErr: false},
+		{name: "ordinary name", input: "Acme Corp", wantErr: false},
+		{name: "no letters or digits", input: "---", wantErr: false},
+		{name: "configured list", reserved: []string{"acme"}, input: "ACME", wantErr: true},
+		{name: "configured list replaces the default", reserved: []string{"acme"}, input: "admin", wantErr: false},
+		{name: "configured entry in another form", reserved: []string{"Help Desk"}, input: "help_desk", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.GlobalConfig = nil
+			if tt.reserved != nil {
+				config.GlobalConfig = &config.Config{App: config.AppConfig{ReservedNames: tt.reserved}}
+			}
+
+			err := CheckReservedName(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckReservedName(%q) error = %v, want error %v", tt.input, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrReservedName) {
+				t.Fatalf("CheckReservedName(%q) error = %v, want %v", tt.input, err, ErrReservedName)
+			}
+		})
+	}
+}
+
+func TestReservedNameIsBadRequest(t *testing.T) {
+	if got := response.StatusFromError(ErrReservedName, http.StatusInternalServerError); got != http.StatusBadRequest {
+		t.Fatalf("StatusFromError(ErrReservedName) = %d, want %d", got, http.StatusBadRequest)
+	}
+}
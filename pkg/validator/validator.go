@@ -0,0 +1,88 @@
+// Package validator registers the project's custom validation tags with gin's binding engine.
+//
+// Available tags:
+//
+//	slug     lowercase letters and digits separated by single hyphens, e.g. "acme-corp"
+//	password at least 8 characters with an uppercase letter, a lowercase letter and a digit
+//	permname a permission name in "resource.action" form, e.g. "users.create"
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"unicode"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// MinPasswordLength is the minimum length accepted by the password tag
+const MinPasswordLength = 8
+
+var (
+	slugRegex     = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+	permNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_]*\.[a-z][a-z0-9_]*$`)
+)
+
+// Register adds the custom tags to gin's default validator. Call it once at startup,
+// before any request is bound.
+func Register() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return fmt.Errorf("unsupported validator engine %T", binding.Validator.Engine())
+	}
+
+	validators := map[string]validator.Func{
+		"slug":     validateSlug,
+		"password": validatePassword,
+		"permname": validatePermName,
+	}
+	for tag, fn := range validators {
+		if err := v.RegisterValidation(tag, fn); err != nil {
+			return fmt.Errorf("failed to register %s validator: %w", tag, err)
+		}
+	}
+	return nil
+}
+
+// IsSlug reports whether s is a valid slug
+func IsSlug(s string) bool {
+	return slugRegex.MatchString(s)
+}
+
+// IsStrongPassword reports whether s satisfies the password policy
+func IsStrongPassword(s string) bool {
+	if len(s) < MinPasswordLength {
+		return false
+	}
+
+	var hasUpper, hasLower, hasDigit bool
+	for _, r := range s {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	return hasUpper && hasLower && hasDigit
+}
+
+// IsPermName reports whether s is a permission name in "resource.action" form
+func IsPermName(s string) bool {
+	return permNameRegex.MatchString(s)
+}
+
+func validateSlug(fl validator.FieldLevel) bool {
+	return IsSlug(fl.Field().String())
+}
+
+func validatePassword(fl validator.FieldLevel) bool {
+	return IsStrongPassword(fl.Field().String())
+}
+
+func validatePermName(fl validator.FieldLevel) bool {
+	return IsPermName(fl.Field().String())
+}
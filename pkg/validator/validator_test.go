@@ -0,0 +1,63 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+func TestCustomTags(t *testing.T) {
+	if err := Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	type slugForm struct {
+		Value string `binding:"slug"`
+	}
+	type passwordForm struct {
+		Value string `binding:"password"`
+	}
+	type permNameForm struct {
+		Value string `binding:"permname"`
+	}
+
+	tests := []struct {
+		name  string
+		form  interface{}
+		valid bool
+	}{
+		{name: "slug", form: slugForm{"acme-corp"}, valid: true},
+		{name: "slug with digits", form: slugForm{"team-42"}, valid: true},
+		{name: "single word slug", form: slugForm{"acme"}, valid: true},
+		{name: "slug with uppercase", form: slugForm{"Acme-Corp"}},
+		{name: "slug with double hyphen", form: slugForm{"acme--corp"}},
+		{name: "slug with leading hyphen", form: slugForm{"-acme"}},
+		{name: "slug with trailing hyphen", form: slugForm{"acme-"}},
+		{name: "slug with space", form: slugForm{"acme corp"}},
+		{name: "empty slug", form: slugForm{""}},
+
+		{name: "strong password", form: passwordForm{"Secret123"}, valid: true},
+		{name: "password with symbols", form: passwordForm{"S3cret!pass"}, valid: true},
+		{name: "short password", form: passwordForm{"Sec123"}},
+		{name: "password without uppercase", form: passwordForm{"secret123"}},
+		{name: "password without lowercase", form: passwordForm{"SECRET123"}},
+		{name: "password without digit", form: passwordForm{"SecretPass"}},
+
+		{name: "permission name", form: permNameForm{"users.create"}, valid: true},
+		{name: "permission name with underscores", form: permNameForm{"api_keys.read_all"}, valid: true},
+		{name: "permission name without action", form: permNameForm{"users"}},
+		{name: "permission name with three parts", form: permNameForm{"users.create.all"}},
+		{name: "permission name with uppercase", form: permNameForm{"Users.create"}},
+		{name: "permission name starting with a digit", form: permNameForm{"1users.create"}},
+		{name: "permission name with hyphen", form: permNameForm{"api-keys.read"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := binding.Validator.ValidateStruct(tt.form)
+			if (err == nil) != tt.valid {
+				t.Fatalf("ValidateStruct(%+v) error = %v, want valid %v", tt.form, err, tt.valid)
+			}
+		})
+	}
+}
@@ -4,6 +4,10 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/config"
+	"github.com/llamacto/llama-gin-kit/middleware"
+	"github.com/llamacto/llama-gin-kit/pkg/jwt"
+	"github.com/llamacto/llama-gin-kit/pkg/metrics"
 	v1 "github.com/llamacto/llama-gin-kit/routes/v1"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -37,10 +41,22 @@ type Links struct {
 }
 
 // RegisterRoutes registers all routes
+// jwt.Init must have succeeded first; authenticated routes can't work without it.
 func RegisterRoutes(r *gin.Engine) {
-	// Global middleware
-	r.Use(gin.Logger())
-	r.Use(gin.Recovery())
+	if !jwt.Initialized() {
+		panic("routes: jwt.Init must be called before RegisterRoutes")
+	}
+
+	// Global middleware. RequestID runs first so the access log, recovery and error responses can use the ID.
+	r.Use(middleware.RequestID())
+	r.Use(middleware.AccessLog())
+	r.Use(middleware.Recovery())
+	r.Use(middleware.Timeout(requestTimeout()))
+
+	// Prometheus metrics, only when enabled and never without a guard
+	if cfg := config.GlobalConfig; cfg != nil && cfg.Metrics.Enabled {
+		r.GET("/metrics", middleware.MetricsAuth(cfg.Metrics.Token), gin.WrapH(metrics.Handler()))
+	}
 
 	// Swagger documentation
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -83,7 +99,7 @@ func RegisterRoutes(r *gin.Engine) {
 			},
 			Links: Links{
 				Documentation: "/swagger/index.html",
-				Health:        "/v1/health/status",
+				Health:        "/v1/health",
 				Swagger:       "/swagger/*any",
 			},
 		}
@@ -95,7 +111,7 @@ func RegisterRoutes(r *gin.Engine) {
 		})
 	})
 
-	// Legacy ping endpoint
+	// Legacy ping endpoint, kept as a cheap liveness probe
 	r.GET("/ping", func(c *gin.Context) {
 		c.JSON(200, gin.H{
 			"message": "pong",
@@ -109,3 +125,11 @@ func RegisterRoutes(r *gin.Engine) {
 	// API v2 routes will be added when needed
 	// v2Group := r.Group("/v2")
 }
+
+// requestTimeout returns the configured per-request deadline, or 0 (disabled) before config is loaded
+func requestTimeout() time.Duration {
+	if config.GlobalConfig == nil {
+		return 0
+	}
+	return time.Duration(config.GlobalConfig.Server.RequestTimeout) * time.Second
+}
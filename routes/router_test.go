@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/pkg/jwt"
+)
+
+func TestRegisterRoutesRequiresJWT(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	if jwt.Initialized() {
+		t.Skip("jwt was initialised by another test in this package")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("RegisterRoutes() did not panic before jwt.Init")
+		}
+	}()
+	RegisterRoutes(gin.New())
+}
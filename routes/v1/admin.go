@@ -0,0 +1,25 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/app/authorization"
+	"github.com/llamacto/llama-gin-kit/app/member"
+	"github.com/llamacto/llama-gin-kit/app/user"
+	"github.com/llamacto/llama-gin-kit/middleware"
+	"github.com/llamacto/llama-gin-kit/pkg/database"
+	pkgmiddleware "github.com/llamacto/llama-gin-kit/pkg/middleware"
+)
+
+// AdminRoutes sets up cross-organization support routes
+func AdminRoutes(router *gin.RouterGroup) {
+	// Initialize admin dependencies
+	authService := authorization.NewService(authorization.NewRepository(database.DB))
+	memberService := member.NewService(member.NewRepository(database.DB), authService, user.NewUserRepository(database.DB))
+	memberHandler := member.NewHandler(memberService)
+
+	admin := router.Group("/admin")
+	admin.Use(pkgmiddleware.JWTAuth())
+	{
+		admin.GET("/users/:userId/memberships", middleware.RequirePermission(authService, "users", "read"), memberHandler.GetUserMemberships) // User's orgs, teams and roles
+	}
+}
@@ -3,11 +3,13 @@ package v1
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/llamacto/llama-gin-kit/app/apikey"
+	"github.com/llamacto/llama-gin-kit/app/authorization"
+	apikeyMiddleware "github.com/llamacto/llama-gin-kit/middleware"
 	"github.com/llamacto/llama-gin-kit/pkg/middleware"
 )
 
 // RegisterAPIKeyRoutes registers routes related to API key management
-func RegisterAPIKeyRoutes(v1 *gin.RouterGroup, apiKeyService apikey.Service) {
+func RegisterAPIKeyRoutes(v1 *gin.RouterGroup, apiKeyService apikey.Service, authService authorization.Service) {
 	// Create API key handler
 	handler := apikey.NewAPIKeyHandler(apiKeyService)
 
@@ -20,5 +22,10 @@ func RegisterAPIKeyRoutes(v1 *gin.RouterGroup, apiKeyService apikey.Service) {
 		apikeyGroup.GET("/:id", handler.Get)
 		apikeyGroup.PUT("/:id", handler.Update)
 		apikeyGroup.DELETE("/:id", handler.Delete)
+
+		// Offboarding: revoke every key of a user or of an organization's members at once
+		requireAdmin := apikeyMiddleware.RequireRole(authService, "admin")
+		apikeyGroup.POST("/revoke/user/:user_id", requireAdmin, handler.RevokeForUser)
+		apikeyGroup.POST("/revoke/organization/:organization_id", requireAdmin, handler.RevokeForOrganization)
 	}
 }
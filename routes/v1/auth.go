@@ -0,0 +1,76 @@
+package v1
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/app/authorization"
+	"github.com/llamacto/llama-gin-kit/middleware"
+	"github.com/llamacto/llama-gin-kit/pkg/database"
+	"github.com/llamacto/llama-gin-kit/pkg/logger"
+	pkgmiddleware "github.com/llamacto/llama-gin-kit/pkg/middleware"
+)
+
+// AuthRoutes sets up authorization (roles and permissions) routes
+func AuthRoutes(router *gin.RouterGroup) {
+	// Initialize authorization dependencies
+	authRepo := authorization.NewRepository(database.DB)
+	authService := authorization.NewService(authRepo)
+	go func() {
+		if err := authService.WarmCache(context.Background()); err != nil {
+			logger.Error("Failed to warm the role permission cache", err)
+		}
+	}()
+	authHandler := authorization.NewHandler(authService)
+
+	// Organization audit export, for members holding audit_logs.read in the organization
+	orgAudit := router.Group("/organizations/:id/audit")
+	orgAudit.Use(
+		pkgmiddleware.JWTAuth(),
+		middleware.OrganizationContext("id"),
+		middleware.RequireOrganizationPermission(authService, "audit_logs.read"),
+	)
+	{
+		orgAudit.GET("/export", authHandler.ExportOrganizationAuditLogs) // Stream audit events for a date range
+	}
+
+	auth := router.Group("/auth")
+	auth.Use(pkgmiddleware.JWTAuth())
+	{
+		auth.GET("/roles", authHandler.ListRoles)                          // List custom roles
+		auth.GET("/roles/system", authHandler.ListSystemRoles)             // List built-in roles
+		auth.GET("/permissions", authHandler.ListPermissions)              // List custom permissions
+		auth.GET("/permissions/system", authHandler.ListSystemPermissions) // List built-in permissions
+		auth.GET("/permissions/catalog", authHandler.GetPermissionCatalog) // Active permissions grouped by category
+		auth.POST("/permissions/resolve", authHandler.ResolvePermissions)  // Map permission names to IDs and back
+		auth.POST("/switch-org", authHandler.SwitchOrganization)           // Issue a token for another organization
+		auth.POST("/initialize", authHandler.InitializeSystem)             // Bootstrap built-in roles and permissions
+
+		// Assignment changes and the audit trail are restricted to admins
+		admin := auth.Group("")
+		admin.Use(middleware.RequireRole(authService, "admin"))
+		{
+			admin.GET("/users/:id/permissions", authHandler.GetUserPermissionsSummary)                                        // User roles and effective permissions
+			admin.GET("/roles/deleted", authHandler.ListDeletedRoles)                                                         // List soft-deleted roles
+			admin.DELETE("/roles/:id", authHandler.DeleteRole)                                                                // Soft-delete role
+			admin.POST("/roles/:id/restore", authHandler.RestoreRole)                                                         // Restore soft-deleted role
+			admin.POST("/users/:id/roles", authHandler.AssignRoleToUser)                                                      // Assign role to user
+			admin.POST("/users/:id/roles/batch", authHandler.AssignRolesToUser)                                               // Assign several roles to user
+			admin.DELETE("/users/:id/roles/:roleId", authHandler.RemoveRoleFromUser)                                          // Remove role from user
+			admin.POST("/roles/:id/permissions", authHandler.AssignPermissionsToRole)                                         // Grant permissions to role
+			admin.DELETE("/roles/:id/permissions", authHandler.RemovePermissionsFromRole)                                     // Revoke permissions from role
+			admin.POST("/roles/:id/permissions/preview", authHandler.PreviewRolePermissionChange)                             // Users a permission edit would affect
+			admin.POST("/organizations/:orgId/bulk-assign-role", authHandler.BulkAssignOrganizationRole)                      // Grant org role to many users
+			admin.PUT("/organizations/:orgId/users/:userId/roles/:roleId/deactivate", authHandler.DeactivateOrganizationRole) // Deactivate org role
+			admin.PUT("/organizations/:orgId/users/:userId/roles/:roleId/reactivate", authHandler.ReactivateOrganizationRole) // Reactivate org role
+			admin.PUT("/teams/:teamId/users/:userId/roles/:roleId/deactivate", authHandler.DeactivateTeamRole)                // Deactivate team role
+			admin.PUT("/teams/:teamId/users/:userId/roles/:roleId/reactivate", authHandler.ReactivateTeamRole)                // Reactivate team role
+			admin.GET("/policies", authHandler.ListPolicies)                                                                  // List policies
+			admin.POST("/policies", authHandler.CreatePolicy)                                                                 // Create policy
+			admin.GET("/policies/:id", authHandler.GetPolicy)                                                                 // Get policy
+			admin.PUT("/policies/:id", authHandler.UpdatePolicy)                                                              // Update policy
+			admin.DELETE("/policies/:id", authHandler.DeletePolicy)                                                           // Delete policy
+			admin.GET("/audit-logs", authHandler.ListAuditLogs)                                                               // List audit logs
+		}
+	}
+}
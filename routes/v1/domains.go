@@ -0,0 +1,28 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/app/authorization"
+	"github.com/llamacto/llama-gin-kit/app/orgdomain"
+	"github.com/llamacto/llama-gin-kit/middleware"
+	pkgmiddleware "github.com/llamacto/llama-gin-kit/pkg/middleware"
+)
+
+// OrganizationDomainRoutes sets up routes for managing an organization's auto-join email domains
+func OrganizationDomainRoutes(router *gin.RouterGroup, service orgdomain.Service, authService authorization.Service) {
+	handler := orgdomain.NewHandler(service)
+
+	domains := router.Group("/organizations/:id/domains")
+	domains.Use(
+		pkgmiddleware.JWTAuth(),
+		middleware.OrganizationContext("id"),
+		middleware.RequireOrganizationPermission(authService, "organizations.update"),
+	)
+	{
+		domains.GET("", handler.ListDomains)
+		domains.POST("", handler.AddDomain)
+		domains.POST("/:domain_id/verify", handler.VerifyDomain)
+		domains.PUT("/:domain_id", handler.UpdateDomain)
+		domains.DELETE("/:domain_id", handler.DeleteDomain)
+	}
+}
@@ -0,0 +1,27 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/app/authorization"
+	"github.com/llamacto/llama-gin-kit/app/organization"
+	"github.com/llamacto/llama-gin-kit/config"
+	"github.com/llamacto/llama-gin-kit/middleware"
+	"github.com/llamacto/llama-gin-kit/pkg/events"
+	pkgmiddleware "github.com/llamacto/llama-gin-kit/pkg/middleware"
+)
+
+// EventRoutes sets up the WebSocket routes pushing organization events
+func EventRoutes(router *gin.RouterGroup, authService authorization.Service) {
+	handler := organization.NewEventsHandler(events.Default(), config.GlobalConfig.CORS.AllowOrigins)
+
+	ws := router.Group("/ws/organizations/:id")
+	ws.Use(
+		pkgmiddleware.QueryToken("access_token"),
+		pkgmiddleware.JWTAuth(),
+		middleware.OrganizationContext("id"),
+		middleware.RequireOrganizationPermission(authService, "members.read"),
+	)
+	{
+		ws.GET("/events", handler.StreamEvents)
+	}
+}
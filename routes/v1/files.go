@@ -0,0 +1,23 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/app/file"
+	"github.com/llamacto/llama-gin-kit/config"
+	pkgmiddleware "github.com/llamacto/llama-gin-kit/pkg/middleware"
+	"github.com/llamacto/llama-gin-kit/pkg/storage"
+)
+
+// FileRoutes sets up file upload routes backed by R2 storage
+func FileRoutes(router *gin.RouterGroup) {
+	// Initialize file dependencies; without R2 configuration uploads return 503
+	limits := config.GlobalConfig.Upload
+	fileService := file.NewService(storage.NewR2Client(config.GlobalConfig), limits)
+	fileHandler := file.NewHandler(fileService, limits.MaxSize)
+
+	files := router.Group("/files")
+	files.Use(pkgmiddleware.JWTAuth())
+	{
+		files.POST("/upload", fileHandler.Upload) // Upload a file
+	}
+}
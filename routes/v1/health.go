@@ -1,15 +1,35 @@
 package v1
 
 import (
+	"context"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/config"
+	"github.com/llamacto/llama-gin-kit/pkg/database"
+	"github.com/llamacto/llama-gin-kit/pkg/redis"
 )
 
+// startTime records when the process started, for reporting uptime
+var startTime = time.Now()
+
+// healthCheckTimeout bounds each dependency check so the probe cannot hang
+const healthCheckTimeout = 2 * time.Second
+
+// DependencyStatus reports the health of a single dependency
+type DependencyStatus struct {
+	Status string `json:"status"` // "up" or "down"
+	Error  string `json:"error,omitempty"`
+}
+
 // RegisterHealthRoutes registers health check routes
 func RegisterHealthRoutes(v1 *gin.RouterGroup) {
 	health := v1.Group("/health")
 	{
+		// Readiness probe: verifies the database and, if configured, Redis
+		health.GET("", readinessCheck)
+
 		health.GET("/ping", func(c *gin.Context) {
 			c.JSON(http.StatusOK, gin.H{
 				"message": "pong",
@@ -25,3 +45,63 @@ func RegisterHealthRoutes(v1 *gin.RouterGroup) {
 		})
 	}
 }
+
+// readinessCheck pings every dependency and responds 503 if any of them is down
+func readinessCheck(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), healthCheckTimeout)
+	defer cancel()
+
+	dependencies := map[string]DependencyStatus{
+		"database": checkDatabase(ctx),
+	}
+	if config.GlobalConfig.Redis.Host != "" {
+		dependencies["redis"] = checkRedis(ctx)
+	}
+
+	status := "ok"
+	code := http.StatusOK
+	for _, dep := range dependencies {
+		if dep.Status != "up" {
+			status = "unavailable"
+			code = http.StatusServiceUnavailable
+			break
+		}
+	}
+
+	c.JSON(code, gin.H{
+		"status":       status,
+		"version":      config.GlobalConfig.App.Version,
+		"uptime":       time.Since(startTime).Round(time.Second).String(),
+		"dependencies": dependencies,
+	})
+}
+
+// checkDatabase pings the underlying SQL connection
+func checkDatabase(ctx context.Context) DependencyStatus {
+	if database.DB == nil {
+		return DependencyStatus{Status: "down", Error: "database not initialized"}
+	}
+
+	sqlDB, err := database.DB.DB()
+	if err != nil {
+		return DependencyStatus{Status: "down", Error: err.Error()}
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return DependencyStatus{Status: "down", Error: err.Error()}
+	}
+	return DependencyStatus{Status: "up"}
+}
+
+// checkRedis pings Redis, using a short-lived client if startup could not connect
+func checkRedis(ctx context.Context) DependencyStatus {
+	client := redis.GetClient()
+	if client == nil {
+		client = redis.NewClient(config.GlobalConfig.Redis)
+		defer client.Close()
+	}
+
+	if err := client.Ping(ctx); err != nil {
+		return DependencyStatus{Status: "down", Error: err.Error()}
+	}
+	return DependencyStatus{Status: "up"}
+}
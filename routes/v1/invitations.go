@@ -0,0 +1,34 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/app/authorization"
+	"github.com/llamacto/llama-gin-kit/app/invitation"
+	"github.com/llamacto/llama-gin-kit/app/user"
+	"github.com/llamacto/llama-gin-kit/pkg/database"
+	pkgmiddleware "github.com/llamacto/llama-gin-kit/pkg/middleware"
+)
+
+// InvitationRoutes sets up organization invitation routes
+func InvitationRoutes(router *gin.RouterGroup) {
+	// Initialize invitation dependencies
+	authService := authorization.NewService(authorization.NewRepository(database.DB))
+	invitationRepo := invitation.NewRepository(database.DB)
+	invitationService := invitation.NewService(invitationRepo, user.NewUserRepository(database.DB), authService)
+	invitationHandler := invitation.NewHandler(invitationService)
+
+	invitations := router.Group("/invitations")
+	invitations.Use(pkgmiddleware.JWTAuth())
+	{
+		invitations.POST("", invitationHandler.InviteMember)            // Invite member
+		invitations.POST("/accept", invitationHandler.AcceptInvitation) // Accept invitation
+		invitations.DELETE("/:id", invitationHandler.CancelInvitation)  // Cancel invitation
+	}
+
+	// Organization-specific invitation routes, kept separate like org-teams to avoid route conflicts
+	orgInvitations := router.Group("/org-invitations")
+	orgInvitations.Use(pkgmiddleware.JWTAuth())
+	{
+		orgInvitations.GET("/:organization_id", invitationHandler.ListInvitations) // List organization invitations
+	}
+}
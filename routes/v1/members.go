@@ -0,0 +1,36 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/app/authorization"
+	"github.com/llamacto/llama-gin-kit/app/member"
+	"github.com/llamacto/llama-gin-kit/app/user"
+	"github.com/llamacto/llama-gin-kit/pkg/database"
+	pkgmiddleware "github.com/llamacto/llama-gin-kit/pkg/middleware"
+)
+
+// MemberRoutes sets up organization member routes
+func MemberRoutes(router *gin.RouterGroup) {
+	// Initialize member dependencies
+	authService := authorization.NewService(authorization.NewRepository(database.DB))
+	memberRepo := member.NewRepository(database.DB)
+	memberService := member.NewService(memberRepo, authService, user.NewUserRepository(database.DB))
+	memberHandler := member.NewHandler(memberService)
+
+	members := router.Group("/members")
+	members.Use(pkgmiddleware.JWTAuth())
+	{
+		members.POST("", memberHandler.AddMember)                       // Add member
+		members.GET("/:id", memberHandler.GetMember)                    // Get member by ID
+		members.PUT("/:id", memberHandler.UpdateMember)                 // Update member
+		members.DELETE("/:id", memberHandler.RemoveMember)              // Remove member
+		members.GET("/:id/history", memberHandler.GetMemberRoleHistory) // Get member role history
+	}
+
+	// Organization-specific member routes, kept separate like org-teams to avoid route conflicts
+	orgMembers := router.Group("/org-members")
+	orgMembers.Use(pkgmiddleware.JWTAuth())
+	{
+		orgMembers.GET("/:organization_id", memberHandler.GetMembersByOrganization) // Get organization members
+	}
+}
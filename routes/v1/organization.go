@@ -3,22 +3,32 @@ package v1
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/llamacto/llama-gin-kit/app/apikey"
+	"github.com/llamacto/llama-gin-kit/app/authorization"
 	"github.com/llamacto/llama-gin-kit/app/organization"
 	apikeyMiddleware "github.com/llamacto/llama-gin-kit/middleware"
 )
 
 // RegisterOrganizationRoutes registers organization routes
-func RegisterOrganizationRoutes(router *gin.RouterGroup, handler *organization.Handler, apiKeyService apikey.Service) {
+func RegisterOrganizationRoutes(router *gin.RouterGroup, handler *organization.Handler, apiKeyService apikey.Service, authService authorization.Service) {
 	// Routes that require authentication
 	authRouter := router.Group("")
-	authRouter.Use(apikeyMiddleware.CombinedAuth(apiKeyService))
+	authRouter.Use(apikeyMiddleware.CombinedAuth(apiKeyService), apikeyMiddleware.APIKeyRateLimit())
 
 	// Organization endpoints - only core organization functionality
 	orgRouter := authRouter.Group("/organizations")
-	orgRouter.POST("", handler.CreateOrganization)
-	orgRouter.GET("", handler.ListOrganizations)
-	orgRouter.GET("/me", handler.GetMyOrganizations)
-	orgRouter.GET("/:id", handler.GetOrganization)
-	orgRouter.PUT("/:id", handler.UpdateOrganization)
-	orgRouter.DELETE("/:id", handler.DeleteOrganization)
+	// API keys additionally need the matching scope; JWT users are not scope-limited
+	orgRouter.POST("", apikeyMiddleware.RequireScope("organizations.create"), handler.CreateOrganization)
+	orgRouter.GET("", apikeyMiddleware.RequireScope("organizations.read"), handler.ListOrganizations)
+	orgRouter.GET("/me", apikeyMiddleware.RequireScope("organizations.read"), handler.GetMyOrganizations)
+	orgRouter.GET("/:id", apikeyMiddleware.RequireScope("organizations.read"), handler.GetOrganization)
+	orgRouter.PUT("/:id", apikeyMiddleware.RequireScope("organizations.update"), handler.UpdateOrganization)
+	orgRouter.DELETE("/:id", apikeyMiddleware.RequireScope("organizations.delete"), handler.DeleteOrganization)
+
+	// The roster holds member emails, so exporting it needs members.read in the organization
+	orgRouter.GET("/:id/members/export",
+		apikeyMiddleware.RequireScope("members.read"),
+		apikeyMiddleware.OrganizationContext("id"),
+		apikeyMiddleware.RequireOrganizationPermission(authService, "members.read"),
+		handler.ExportMembers,
+	)
 }
@@ -0,0 +1,118 @@
+package v1
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/app/authorization"
+	"github.com/llamacto/llama-gin-kit/app/organization"
+	"github.com/llamacto/llama-gin-kit/config"
+	"github.com/llamacto/llama-gin-kit/pkg/jwt"
+)
+
+// grant is a permission a user holds in an organization
+type grant struct {
+	userID         uint
+	organizationID uint
+	permission     string
+}
+
+// grantedPermissions is an authorization.Service answering organization permission checks
+// from a fixed list; other methods are left to the embedded nil interface and panic if called
+type grantedPermissions struct {
+	authorization.Service
+	grants []grant
+}
+
+func (s *grantedPermissions) CheckOrganizationPermission(ctx context.Context, userID, organizationID uint, permission string) (bool, error) {
+	for _, g := range s.grants {
+		if g == (grant{userID, organizationID, permission}) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// organizationService is an organization.Service that finds every organization and exports an
+// empty roster; other methods are left to the embedded nil interface and panic if called
+type organizationService struct {
+	organization.Service
+}
+
+func (s *organizationService) GetOrganization(ctx context.Context, id uint) (*organization.Organization, error) {
+	return &organization.Organization{ID: id}, nil
+}
+
+func (s *organizationService) ExportMembers(ctx context.Context, orgID uint, format string, w io.Writer) error {
+	_, err := io.WriteString(w, "id,username,email\n")
+	return err
+}
+
+// serveAs sends a request authenticated as userID with a freshly signed access token
+func serveAs(t *testing.T, router http.Handler, method, path string, userID uint) *httptest.ResponseRecorder {
+	t.Helper()
+	if !jwt.Initialized() {
+		err := jwt.Init(&config.Config{JWT: config.JWTConfig{
+			Secret:         strings.Repeat("s", jwt.MinSecretLength),
+			ExpireDuration: time.Hour,
+		}})
+		if err != nil {
+			t.Fatalf("jwt.Init() error = %v", err)
+		}
+	}
+	token, err := jwt.GenerateToken(userID, "tester")
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestExportMembersRequiresMembersRead(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const (
+		orgID    = 5
+		memberID = 1
+		otherID  = 2
+	)
+	authService := &grantedPermissions{grants: []grant{
+		{memberID, orgID, "members.read"},
+		{otherID, orgID + 1, "members.read"},
+	}}
+
+	engine := gin.New()
+	RegisterOrganizationRoutes(engine.Group("/v1"), organization.NewHandler(&organizationService{}), nil, authService)
+
+	tests := []struct {
+		name   string
+		userID uint
+		want   int
+	}{
+		{name: "member with members.read", userID: memberID, want: http.StatusOK},
+		{name: "non-member", userID: 3, want: http.StatusForbidden},
+		{name: "member of another organization", userID: otherID, want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveAs(t, engine, http.MethodGet, "/v1/organizations/5/members/export", tt.userID)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if tt.want != http.StatusOK && strings.Contains(w.Body.String(), "email") {
+				t.Fatalf("refused export leaked the roster: %s", w.Body)
+			}
+		})
+	}
+}
@@ -6,9 +6,14 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/llamacto/llama-gin-kit/app/apikey"
+	"github.com/llamacto/llama-gin-kit/app/authorization"
 	"github.com/llamacto/llama-gin-kit/app/organization"
+	"github.com/llamacto/llama-gin-kit/app/orgdomain"
+	"github.com/llamacto/llama-gin-kit/app/team"
 	"github.com/llamacto/llama-gin-kit/app/user"
+	"github.com/llamacto/llama-gin-kit/config"
 	"github.com/llamacto/llama-gin-kit/middleware"
+	"github.com/llamacto/llama-gin-kit/pkg/authctx"
 	"github.com/llamacto/llama-gin-kit/pkg/database"
 	pkgmiddleware "github.com/llamacto/llama-gin-kit/pkg/middleware"
 )
@@ -28,12 +33,45 @@ func RegisterRoutes(engine *gin.Engine, v1 *gin.RouterGroup) {
 	userRepo := user.NewUserRepository(db)
 	userService := user.NewUserService(userRepo)
 	userHandler := user.NewUserHandler(userService)
+	authService := authorization.NewService(authorization.NewRepository(db))
+
+	// Approved users with an address at a verified organization domain join that organization
+	domainService := orgdomain.NewService(orgdomain.NewRepository(db), authService)
+	userService.OnApproved(orgdomain.AutoJoinHook(domainService))
+
+	// Throttle credential endpoints per client IP and per account
+	authLimit := config.GlobalConfig.RateLimit.AuthLimit
+	authWindow := config.GlobalConfig.RateLimit.AuthWindow
 
 	// Register user routes
 	// Public auth routes
-	v1.POST("/register", userHandler.Register)
-	v1.POST("/login", userHandler.Login)
-	v1.POST("/password/reset", userHandler.ResetPassword)
+	v1.POST("/register",
+		middleware.RateLimit(middleware.ClientIPKey, authLimit, authWindow),
+		userHandler.Register,
+	)
+	v1.POST("/login",
+		middleware.RateLimit(middleware.ClientIPKey, authLimit, authWindow),
+		middleware.RateLimit(middleware.JSONFieldKey("username"), authLimit, authWindow),
+		userHandler.Login,
+	)
+	v1.POST("/login/two-factor",
+		middleware.RateLimit(middleware.ClientIPKey, authLimit, authWindow),
+		middleware.RateLimit(middleware.JSONFieldKey("pending_token"), authLimit, authWindow),
+		userHandler.LoginTwoFactor,
+	)
+	v1.POST("/login/refresh",
+		middleware.RateLimit(middleware.ClientIPKey, authLimit, authWindow),
+		userHandler.Refresh,
+	)
+	v1.POST("/password/reset",
+		middleware.RateLimit(middleware.ClientIPKey, authLimit, authWindow),
+		middleware.RateLimit(middleware.JSONFieldKey("email"), authLimit, authWindow),
+		userHandler.ResetPassword,
+	)
+	v1.POST("/password/reset/confirm",
+		middleware.RateLimit(middleware.ClientIPKey, authLimit, authWindow),
+		userHandler.ConfirmPasswordReset,
+	)
 
 	// Protected user routes
 	userGroup := v1.Group("/users")
@@ -41,13 +79,18 @@ func RegisterRoutes(engine *gin.Engine, v1 *gin.RouterGroup) {
 	{
 		userGroup.GET("/profile", userHandler.GetProfile)
 		userGroup.PUT("/profile", userHandler.UpdateProfile)
+		userGroup.POST("/avatar", userHandler.UploadAvatar)
 		userGroup.PUT("/password", userHandler.ChangePassword)
 		userGroup.DELETE("/account", userHandler.DeleteAccount)
+		userGroup.POST("/two-factor", userHandler.EnableTwoFactor)
+		userGroup.POST("/two-factor/confirm", userHandler.ConfirmTwoFactor)
 
 		// Admin routes
 		userGroup.GET("", userHandler.List)
+		userGroup.GET("/batch", middleware.RequireRole(authService, "admin"), userHandler.GetUsersByIDs)
 		userGroup.GET("/:id", userHandler.Get)
 		userGroup.GET("/:id/info", userHandler.GetUserInfo)
+		userGroup.POST("/:id/approve", middleware.RequireRole(authService, "admin"), userHandler.Approve)
 	}
 
 	// Initialize API key module
@@ -55,26 +98,53 @@ func RegisterRoutes(engine *gin.Engine, v1 *gin.RouterGroup) {
 	apiKeyService := apikey.NewAPIKeyService(apiKeyRepo)
 
 	// Register API key routes
-	RegisterAPIKeyRoutes(v1, apiKeyService)
+	RegisterAPIKeyRoutes(v1, apiKeyService, authService)
 
 	// Initialize organization module
 	orgRepo := organization.NewRepository(db)
-	orgService := organization.NewService(orgRepo, userService, db)
+	orgService := organization.NewService(orgRepo, userService, authService, db)
 	orgHandler := organization.NewHandler(orgService)
 
 	// Register organization routes
-	RegisterOrganizationRoutes(v1, orgHandler, apiKeyService)
+	RegisterOrganizationRoutes(v1, orgHandler, apiKeyService, authService)
+
+	// Register organization domain routes
+	OrganizationDomainRoutes(v1, domainService, authService)
+
+	// Register organization event stream routes
+	EventRoutes(v1, authService)
+
+	// Initialize team module
+	teamHandler := team.NewHandler(team.NewService(team.NewRepository(db)))
 
 	// Register team routes
-	TeamRoutes(v1)
+	TeamRoutes(v1, teamHandler, authService)
+
+	// Register member routes
+	MemberRoutes(v1)
+
+	// Register invitation routes
+	InvitationRoutes(v1)
+
+	// Register authorization routes
+	AuthRoutes(v1)
+
+	// Register admin support routes
+	AdminRoutes(v1)
+
+	// Register TTS routes
+	TTSRoutes(v1, apiKeyService)
+
+	// Register file routes
+	FileRoutes(v1)
 
 	// Example of a route that accepts either JWT or API key authentication
 	// 使用CombinedAuth中间件，支持JWT和API key双重认证
 	combinedAuthMiddleware := middleware.CombinedAuth(apiKeyService)
-	v1.GET("/protected", combinedAuthMiddleware, func(c *gin.Context) {
+	v1.GET("/protected", combinedAuthMiddleware, middleware.APIKeyRateLimit(), func(c *gin.Context) {
 		// 获取认证类型
-		authType := c.GetString("authType")
-		userID := c.GetUint("userID")
+		authType := authctx.AuthType(c)
+		userID, _ := authctx.UserID(c)
 
 		c.JSON(http.StatusOK, gin.H{
 			"message":   "认证成功",
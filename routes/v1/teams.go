@@ -2,18 +2,14 @@ package v1
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/app/authorization"
 	"github.com/llamacto/llama-gin-kit/app/team"
-	"github.com/llamacto/llama-gin-kit/pkg/database"
+	"github.com/llamacto/llama-gin-kit/middleware"
 	pkgmiddleware "github.com/llamacto/llama-gin-kit/pkg/middleware"
 )
 
 // TeamRoutes sets up team-related routes
-func TeamRoutes(router *gin.RouterGroup) {
-	// Initialize team dependencies
-	teamRepo := team.NewRepository(database.DB)
-	teamService := team.NewService(teamRepo)
-	teamHandler := team.NewHandler(teamService)
-
+func TeamRoutes(router *gin.RouterGroup, teamHandler team.Handler, authService authorization.Service) {
 	// Team routes group
 	teams := router.Group("/teams")
 	teams.Use(pkgmiddleware.JWTAuth()) // Require authentication for all team operations
@@ -31,4 +27,20 @@ func TeamRoutes(router *gin.RouterGroup) {
 	{
 		orgTeams.GET("/:organization_id", teamHandler.GetTeamsByOrganization) // Get organization teams
 	}
+
+	// Team views nested under an organization
+	orgScoped := router.Group("/organizations/:id")
+	orgScoped.Use(pkgmiddleware.JWTAuth(), middleware.OrganizationContext("id"))
+	{
+		// The tree lists every team, so it needs teams.read in the organization
+		orgScoped.GET("/teams/tree",
+			middleware.RequireOrganizationPermission(authService, "teams.read"),
+			teamHandler.GetTeamTree,
+		)
+		// Users may list their own teams; anyone else's need members.read
+		orgScoped.GET("/users/:userId/teams",
+			middleware.RequireSelfOrOrganizationPermission(authService, "userId", "members.read"),
+			teamHandler.GetUserTeams,
+		)
+	}
 }
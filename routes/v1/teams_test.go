@@ -0,0 +1,97 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/app/team"
+)
+
+// teamService is a team.Service returning empty team views; other methods are left to the
+// embedded nil interface and panic if called
+type teamService struct {
+	team.Service
+}
+
+func (s *teamService) GetTeamTree(ctx context.Context, organizationID uint) ([]*team.TeamTreeNode, error) {
+	return []*team.TeamTreeNode{}, nil
+}
+
+func (s *teamService) GetUserTeams(ctx context.Context, userID, organizationID uint) ([]team.TeamResponse, error) {
+	return []team.TeamResponse{}, nil
+}
+
+func TestTeamTreeRequiresTeamsRead(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const (
+		orgID    = 5
+		memberID = 1
+		otherID  = 2
+	)
+	authService := &grantedPermissions{grants: []grant{
+		{memberID, orgID, "teams.read"},
+		{otherID, orgID + 1, "teams.read"},
+	}}
+
+	engine := gin.New()
+	TeamRoutes(engine.Group("/v1"), team.NewHandler(&teamService{}), authService)
+
+	tests := []struct {
+		name   string
+		userID uint
+		want   int
+	}{
+		{name: "member with teams.read", userID: memberID, want: http.StatusOK},
+		{name: "non-member", userID: 3, want: http.StatusForbidden},
+		{name: "member of another organization", userID: otherID, want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveAs(t, engine, http.MethodGet, "/v1/organizations/5/teams/tree", tt.userID)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+func TestUserTeamsRequireSelfOrMembersRead(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const (
+		orgID    = 5
+		readerID = 1
+		userID   = 2
+	)
+	authService := &grantedPermissions{grants: []grant{{readerID, orgID, "members.read"}}}
+
+	engine := gin.New()
+	TeamRoutes(engine.Group("/v1"), team.NewHandler(&teamService{}), authService)
+
+	tests := []struct {
+		name   string
+		actor  uint
+		target uint
+		want   int
+	}{
+		{name: "own teams", actor: userID, target: userID, want: http.StatusOK},
+		{name: "another user's teams with members.read", actor: readerID, target: userID, want: http.StatusOK},
+		{name: "another user's teams without members.read", actor: userID, target: readerID, want: http.StatusForbidden},
+		{name: "outsider", actor: 3, target: userID, want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := fmt.Sprintf("/v1/organizations/%d/users/%d/teams", orgID, tt.target)
+			w := serveAs(t, engine, http.MethodGet, path, tt.actor)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package v1
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llamacto/llama-gin-kit/app/apikey"
+	"github.com/llamacto/llama-gin-kit/app/tts"
+	"github.com/llamacto/llama-gin-kit/config"
+	"github.com/llamacto/llama-gin-kit/middleware"
+	"github.com/llamacto/llama-gin-kit/pkg/database"
+	"github.com/llamacto/llama-gin-kit/pkg/logger"
+	pkgmiddleware "github.com/llamacto/llama-gin-kit/pkg/middleware"
+	"github.com/llamacto/llama-gin-kit/pkg/signedurl"
+	"github.com/llamacto/llama-gin-kit/pkg/storage"
+)
+
+// TTSRoutes sets up text-to-speech routes, reachable with a JWT or an API key holding the tts scopes
+func TTSRoutes(router *gin.RouterGroup, apiKeyService apikey.Service) {
+	// Initialize TTS dependencies; generated audio is uploaded to R2 storage and only served
+	// through signed, expiring links
+	signer, err := signedurl.NewSigner(config.GlobalConfig.TTS.DownloadSecret)
+	if err != nil {
+		logger.Warn("TTS_DOWNLOAD_SECRET is not set, generated audio can't be downloaded")
+	}
+	ttsService := tts.NewService(tts.NewRepository(database.DB), storage.NewR2Client(config.GlobalConfig), signer, config.GlobalConfig.TTS)
+	go func() {
+		if err := ttsService.ResumeJobs(context.Background()); err != nil {
+			logger.Error("Failed to resume TTS jobs", err)
+		}
+	}()
+	ttsHandler := tts.NewHandler(ttsService)
+
+	// One limiter for every route that starts a paid generation, so they share its buckets
+	generationLimit := middleware.TTSRateLimit(config.GlobalConfig.RateLimit)
+
+	group := router.Group("/tts")
+	group.Use(middleware.CombinedAuth(apiKeyService), middleware.APIKeyRateLimit())
+	{
+		group.POST("/generate", middleware.RequireScope("tts.generate"), generationLimit, ttsHandler.Generate)       // Queue speech generation
+		group.POST("/translate", middleware.RequireScope("tts.translate"), ttsHandler.Translate)                     // Translate text
+		group.GET("/jobs/:id", middleware.RequireScope("tts.generate"), ttsHandler.GetJob)                           // Get generation job status
+		group.POST("/jobs/:id/retry", middleware.RequireScope("tts.generate"), generationLimit, ttsHandler.RetryJob) // Retry a failed job
+		group.GET("/jobs/:id/download", middleware.RequireScope("tts.generate"), ttsHandler.AudioLink)               // Get a signed audio download link
+		group.GET("/jobs/:id/stream", middleware.RequireScope("tts.generate"), ttsHandler.StreamJob)                 // Stream generation progress
+	}
+
+	// The signature stands in for authentication, so links can be handed to players and browsers
+	if signer != nil {
+		router.GET("/tts/jobs/:id/audio", pkgmiddleware.SignedURL(signer, "id"), ttsHandler.DownloadAudio)
+	}
+}